@@ -0,0 +1,122 @@
+// (c) 2019-2020, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+// Package acceptors fans out the events of containers being accepted to
+// external sinks, such as webhooks.
+package acceptors
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/ava-labs/avalanchego/database"
+	"github.com/ava-labs/avalanchego/database/prefixdb"
+	"github.com/ava-labs/avalanchego/snow/triggers"
+	"github.com/ava-labs/avalanchego/utils/logging"
+	"github.com/ava-labs/avalanchego/utils/wrappers"
+)
+
+const (
+	acceptorIdentifierPrefix = "acceptor"
+
+	// Bounds of the delay before an event that a sink failed to receive is
+	// delivered again. The delay doubles after each failure.
+	minRetryDelay = time.Second
+	maxRetryDelay = time.Minute
+
+	// Maximum number of events queued per sink. Once exceeded, the sink's
+	// events are dropped until its queue drains.
+	maxQueuedEvents = 1 << 16
+)
+
+var errUnhealthyAcceptors = errors.New("acceptors are unhealthy")
+
+// Acceptors delivers the accepted container events of a dispatcher to a set of
+// sinks
+type Acceptors struct {
+	log        logging.Logger
+	events     *triggers.EventDispatcher
+	forwarders map[string]*forwarder
+}
+
+// New returns acceptors that deliver the accepted container events of
+// [events] to [sinks]. Each sink's undelivered events are queued in [db] under
+// the sink's name, so a sink should keep its name across restarts.
+func New(
+	log logging.Logger,
+	db database.Database,
+	events *triggers.EventDispatcher,
+	sinks map[string]Sink,
+	registerer prometheus.Registerer,
+) (*Acceptors, error) {
+	m := &metrics{}
+	if err := m.initialize(registerer); err != nil {
+		return nil, err
+	}
+
+	a := &Acceptors{
+		log:        log,
+		events:     events,
+		forwarders: make(map[string]*forwarder, len(sinks)),
+	}
+	for name, sink := range sinks {
+		queue := prefixdb.New([]byte(name), db)
+		f, err := newForwarder(name, log, m, sink, queue, maxQueuedEvents, minRetryDelay, maxRetryDelay)
+		if err != nil {
+			_ = a.Shutdown()
+			return nil, fmt.Errorf("couldn't load the queue of acceptor %s: %w", name, err)
+		}
+		if err := events.Register(acceptorIdentifier(name), f); err != nil {
+			_ = a.Shutdown()
+			return nil, err
+		}
+		a.forwarders[name] = f
+		go log.RecoverAndPanic(f.dispatch)
+
+		log.Info("created acceptor %s", name)
+	}
+	return a, nil
+}
+
+// HealthCheck reports the number of queued and dropped events of each sink.
+// Returns an error if a sink's queue is full, or if a sink's delivered events
+// can't be dequeued.
+func (a *Acceptors) HealthCheck() (interface{}, error) {
+	details := make(map[string]interface{}, len(a.forwarders))
+	numUnhealthy := 0
+	for name, f := range a.forwarders {
+		fDetails, err := f.health()
+		if err != nil {
+			numUnhealthy++
+			fDetails = map[string]interface{}{
+				"details": fDetails,
+				"error":   err.Error(),
+			}
+		}
+		details[name] = fDetails
+	}
+	if numUnhealthy > 0 {
+		return details, fmt.Errorf("%w: %d of %d", errUnhealthyAcceptors, numUnhealthy, len(a.forwarders))
+	}
+	return details, nil
+}
+
+// Shutdown stops delivering events. Events that haven't been delivered are
+// delivered once the acceptors are created again.
+func (a *Acceptors) Shutdown() error {
+	a.log.Info("shutting down acceptors")
+
+	errs := wrappers.Errs{}
+	for name, f := range a.forwarders {
+		errs.Add(a.events.Deregister(acceptorIdentifier(name)))
+		f.close()
+	}
+	return errs.Err
+}
+
+func acceptorIdentifier(name string) string {
+	return fmt.Sprintf("%s-%s", acceptorIdentifierPrefix, name)
+}
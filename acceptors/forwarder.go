@@ -0,0 +1,290 @@
+// (c) 2019-2020, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package acceptors
+
+import (
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/ava-labs/avalanchego/database"
+	"github.com/ava-labs/avalanchego/ids"
+	"github.com/ava-labs/avalanchego/snow"
+	"github.com/ava-labs/avalanchego/utils/logging"
+	"github.com/ava-labs/avalanchego/utils/timer"
+)
+
+var errQueueFull = errors.New("queue is full")
+
+// forwarder queues the accepted container events of a sink, and delivers them
+// to the sink in the order they were accepted.
+//
+// Events are queued in a database before they're delivered, and are only
+// removed from it once the sink has received them. So, every event is
+// delivered at least once, even if the node restarts or the sink is
+// unavailable for a while. To bound the queue, events accepted while
+// [maxQueued] events are queued are dropped.
+type forwarder struct {
+	name    string
+	log     logging.Logger
+	metrics *metrics
+	sink    Sink
+	clock   timer.Clock
+
+	// Sequence number --> JSON encoding of the event
+	queue     database.Database
+	maxQueued int
+
+	minRetryDelay, maxRetryDelay time.Duration
+
+	lock sync.Mutex
+	// Sequence number of the next event to be queued
+	nextSeq uint64
+	// Number of events in [queue]
+	numQueued int
+	// Number of events dropped since the forwarder was created
+	numDropped uint64
+	// Error of the last attempt to remove a delivered event from [queue], or
+	// nil if it succeeded
+	dequeueErr error
+	// Signalled when an event is queued, or the forwarder is closed
+	cond   *sync.Cond
+	closed bool
+
+	// Closed once the forwarder is closed
+	quit chan struct{}
+	// Closed once the forwarder's dispatch loop has returned
+	done chan struct{}
+}
+
+func newForwarder(
+	name string,
+	log logging.Logger,
+	metrics *metrics,
+	sink Sink,
+	queue database.Database,
+	maxQueued int,
+	minRetryDelay,
+	maxRetryDelay time.Duration,
+) (*forwarder, error) {
+	f := &forwarder{
+		name:          name,
+		log:           log,
+		metrics:       metrics,
+		sink:          sink,
+		queue:         queue,
+		maxQueued:     maxQueued,
+		minRetryDelay: minRetryDelay,
+		maxRetryDelay: maxRetryDelay,
+		quit:          make(chan struct{}),
+		done:          make(chan struct{}),
+	}
+	f.cond = sync.NewCond(&f.lock)
+
+	// Resume numbering after the events that were queued, but not delivered,
+	// before the node last stopped
+	iter := queue.NewIterator()
+	defer iter.Release()
+	for iter.Next() {
+		f.nextSeq = binary.BigEndian.Uint64(iter.Key()) + 1
+		f.numQueued++
+	}
+	f.metrics.numQueued.Add(float64(f.numQueued))
+	return f, iter.Error()
+}
+
+// Accept implements the triggers.Acceptor interface. The event is queued, and
+// delivered to the sink in the background. If the queue is full, the event is
+// dropped.
+func (f *forwarder) Accept(ctx *snow.Context, containerID ids.ID, container []byte) error {
+	event, err := NewEvent(ctx.ChainID, containerID, container, f.clock.Time())
+	if err != nil {
+		return err
+	}
+	eventBytes, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+
+	f.lock.Lock()
+	defer f.lock.Unlock()
+
+	if f.numQueued >= f.maxQueued {
+		f.log.Debug("dropping %s as the queue of acceptor %s is full", containerID, f.name)
+		f.numDropped++
+		f.metrics.numDropped.Inc()
+		return nil
+	}
+	if err := f.queue.Put(seqKey(f.nextSeq), eventBytes); err != nil {
+		return err
+	}
+	f.nextSeq++
+	f.numQueued++
+	f.metrics.numQueued.Inc()
+	f.cond.Signal()
+	return nil
+}
+
+// dispatch delivers the queued events until the forwarder is closed
+func (f *forwarder) dispatch() {
+	defer close(f.done)
+
+	for {
+		key, event, ok := f.next()
+		if !ok {
+			return
+		}
+
+		delivered := f.retry(
+			func() error { return f.sink.Deliver(event) },
+			func(err error, delay time.Duration) {
+				f.log.Debug("failed to deliver %s to acceptor %s, retrying in %s: %s", event.ContainerID, f.name, delay, err)
+			},
+		)
+		if !delivered {
+			return
+		}
+
+		// If the event can't be dequeued, it would be delivered again, so the
+		// dequeue is retried rather than moving on to the next event
+		dequeued := f.retry(
+			func() error { return f.dequeue(key) },
+			func(err error, delay time.Duration) {
+				f.log.Error("failed to dequeue %s from acceptor %s, retrying in %s: %s", event.ContainerID, f.name, delay, err)
+			},
+		)
+		if !dequeued {
+			return
+		}
+	}
+}
+
+// retry calls [attempt] until it succeeds, waiting twice as long after each
+// failure, up to [maxRetryDelay]. [onFailure] is called with the error of each
+// failed attempt and the delay before the next one. Returns false if the
+// forwarder was closed first.
+func (f *forwarder) retry(attempt func() error, onFailure func(err error, delay time.Duration)) bool {
+	delay := f.minRetryDelay
+	for {
+		err := attempt()
+		if err == nil {
+			return true
+		}
+		onFailure(err, delay)
+		if !f.sleep(delay) {
+			return false
+		}
+		if delay *= 2; delay > f.maxRetryDelay {
+			delay = f.maxRetryDelay
+		}
+	}
+}
+
+// dequeue removes the event at [key] from the queue
+func (f *forwarder) dequeue(key []byte) error {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+
+	f.dequeueErr = f.queue.Delete(key)
+	if f.dequeueErr != nil {
+		f.metrics.numDequeueFailures.Inc()
+		return f.dequeueErr
+	}
+	f.numQueued--
+	f.metrics.numQueued.Dec()
+	return nil
+}
+
+// next blocks until there's a queued event, and returns it along with its key.
+// Returns false if the forwarder was closed.
+func (f *forwarder) next() ([]byte, *Event, bool) {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+
+	for !f.closed {
+		iter := f.queue.NewIterator()
+		hasNext := iter.Next()
+		// The iterator's key and value may be reused once it's released
+		key := append([]byte(nil), iter.Key()...)
+		value := append([]byte(nil), iter.Value()...)
+		err := iter.Error()
+		iter.Release()
+		if err != nil {
+			f.log.Error("failed to read the queue of acceptor %s: %s", f.name, err)
+			return nil, nil, false
+		}
+		if !hasNext {
+			f.cond.Wait()
+			continue
+		}
+
+		event := &Event{}
+		if err := json.Unmarshal(value, event); err != nil {
+			f.log.Error("dropping malformed event from the queue of acceptor %s: %s", f.name, err)
+			if err := f.queue.Delete(key); err != nil {
+				return nil, nil, false
+			}
+			f.numQueued--
+			f.metrics.numQueued.Dec()
+			continue
+		}
+		return key, event, true
+	}
+	return nil, nil, false
+}
+
+// health returns the number of queued and dropped events. Returns an error if
+// the queue is full, or if the last delivered event couldn't be dequeued.
+func (f *forwarder) health() (interface{}, error) {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+
+	details := map[string]interface{}{
+		"queued":  f.numQueued,
+		"dropped": f.numDropped,
+	}
+	switch {
+	case f.dequeueErr != nil:
+		return details, fmt.Errorf("couldn't dequeue a delivered event: %w", f.dequeueErr)
+	case f.numQueued >= f.maxQueued:
+		return details, fmt.Errorf("%w with %d events", errQueueFull, f.numQueued)
+	default:
+		return details, nil
+	}
+}
+
+// sleep for [delay], or until the forwarder is closed. Returns false if the
+// forwarder was closed.
+func (f *forwarder) sleep(delay time.Duration) bool {
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+
+	select {
+	case <-timer.C:
+		return true
+	case <-f.quit:
+		return false
+	}
+}
+
+// close the forwarder and wait for its dispatch loop to return. Events that
+// haven't been delivered remain queued.
+func (f *forwarder) close() {
+	f.lock.Lock()
+	f.closed = true
+	f.cond.Broadcast()
+	f.lock.Unlock()
+
+	close(f.quit)
+	<-f.done
+}
+
+func seqKey(seq uint64) []byte {
+	key := make([]byte, 8)
+	binary.BigEndian.PutUint64(key, seq)
+	return key
+}
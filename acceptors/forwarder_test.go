@@ -0,0 +1,252 @@
+// (c) 2019-2020, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package acceptors
+
+import (
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/ava-labs/avalanchego/database"
+	"github.com/ava-labs/avalanchego/database/memdb"
+	"github.com/ava-labs/avalanchego/ids"
+	"github.com/ava-labs/avalanchego/snow"
+	"github.com/ava-labs/avalanchego/utils/logging"
+)
+
+var errSinkUnavailable = errors.New("sink unavailable")
+
+// testSink records the events delivered to it, and fails deliveries while
+// it's unavailable
+type testSink struct {
+	lock        sync.Mutex
+	unavailable bool
+	attempts    int
+	delivered   []ids.ID
+	received    chan struct{}
+}
+
+func newTestSink() *testSink {
+	return &testSink{received: make(chan struct{}, 100)}
+}
+
+func (s *testSink) Deliver(event *Event) error {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	s.attempts++
+	if s.unavailable {
+		return errSinkUnavailable
+	}
+	s.delivered = append(s.delivered, event.ContainerID)
+	s.received <- struct{}{}
+	return nil
+}
+
+func (s *testSink) setUnavailable(unavailable bool) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	s.unavailable = unavailable
+}
+
+func (s *testSink) getDelivered() []ids.ID {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	return append([]ids.ID(nil), s.delivered...)
+}
+
+func (s *testSink) awaitDeliveries(t *testing.T, n int) {
+	for i := 0; i < n; i++ {
+		select {
+		case <-s.received:
+		case <-time.After(5 * time.Second):
+			t.Fatalf("timed out waiting for delivery %d of %d", i+1, n)
+		}
+	}
+}
+
+func newTestForwarder(t *testing.T, sink Sink, queue database.Database, maxQueued int) *forwarder {
+	m := &metrics{}
+	if err := m.initialize(prometheus.NewRegistry()); err != nil {
+		t.Fatal(err)
+	}
+	f, err := newForwarder("test", logging.NoLog{}, m, sink, queue, maxQueued, time.Millisecond, 10*time.Millisecond)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return f
+}
+
+func startForwarder(t *testing.T, sink Sink, queue database.Database) *forwarder {
+	f := newTestForwarder(t, sink, queue, maxQueuedEvents)
+	go f.dispatch()
+	return f
+}
+
+func TestForwarderDeliversInOrder(t *testing.T) {
+	assert := assert.New(t)
+
+	sink := newTestSink()
+	f := startForwarder(t, sink, memdb.New())
+	defer f.close()
+
+	ctx := snow.DefaultContextTest()
+	containerIDs := []ids.ID{ids.GenerateTestID(), ids.GenerateTestID(), ids.GenerateTestID()}
+	for _, containerID := range containerIDs {
+		assert.NoError(f.Accept(ctx, containerID, containerID[:]))
+	}
+
+	sink.awaitDeliveries(t, len(containerIDs))
+	assert.Equal(containerIDs, sink.getDelivered())
+}
+
+func TestForwarderRetriesFailedDeliveries(t *testing.T) {
+	assert := assert.New(t)
+
+	sink := newTestSink()
+	sink.setUnavailable(true)
+	f := startForwarder(t, sink, memdb.New())
+	defer f.close()
+
+	containerID := ids.GenerateTestID()
+	assert.NoError(f.Accept(snow.DefaultContextTest(), containerID, containerID[:]))
+
+	// Wait for a few failed attempts before the sink becomes available
+	for {
+		sink.lock.Lock()
+		attempts := sink.attempts
+		sink.lock.Unlock()
+		if attempts >= 3 {
+			break
+		}
+		time.Sleep(time.Millisecond)
+	}
+	sink.setUnavailable(false)
+
+	sink.awaitDeliveries(t, 1)
+	assert.Equal([]ids.ID{containerID}, sink.getDelivered())
+}
+
+func TestForwarderRedeliversAfterRestart(t *testing.T) {
+	assert := assert.New(t)
+
+	queue := memdb.New()
+	sink := newTestSink()
+	sink.setUnavailable(true)
+	f := startForwarder(t, sink, queue)
+
+	ctx := snow.DefaultContextTest()
+	containerIDs := []ids.ID{ids.GenerateTestID(), ids.GenerateTestID()}
+	for _, containerID := range containerIDs {
+		assert.NoError(f.Accept(ctx, containerID, containerID[:]))
+	}
+	f.close()
+	assert.Empty(sink.getDelivered())
+
+	// The events queued before the restart are delivered before new ones
+	sink.setUnavailable(false)
+	f = startForwarder(t, sink, queue)
+	defer f.close()
+
+	containerID := ids.GenerateTestID()
+	assert.NoError(f.Accept(ctx, containerID, containerID[:]))
+	containerIDs = append(containerIDs, containerID)
+
+	sink.awaitDeliveries(t, len(containerIDs))
+	assert.Equal(containerIDs, sink.getDelivered())
+}
+
+func TestForwarderDropsEventsWhenFull(t *testing.T) {
+	assert := assert.New(t)
+
+	sink := newTestSink()
+	f := newTestForwarder(t, sink, memdb.New(), 2)
+
+	// The sink isn't dispatched to, so the queue fills up
+	ctx := snow.DefaultContextTest()
+	containerIDs := []ids.ID{ids.GenerateTestID(), ids.GenerateTestID(), ids.GenerateTestID()}
+	for _, containerID := range containerIDs {
+		assert.NoError(f.Accept(ctx, containerID, containerID[:]))
+	}
+	assert.Equal(float64(2), testutil.ToFloat64(f.metrics.numQueued))
+	assert.Equal(float64(1), testutil.ToFloat64(f.metrics.numDropped))
+	_, err := f.health()
+	assert.True(errors.Is(err, errQueueFull))
+
+	// Once the queue drains, events are queued again
+	go f.dispatch()
+	defer f.close()
+	sink.awaitDeliveries(t, 2)
+	assert.Equal(containerIDs[:2], sink.getDelivered())
+
+	containerID := ids.GenerateTestID()
+	assert.NoError(f.Accept(ctx, containerID, containerID[:]))
+	sink.awaitDeliveries(t, 1)
+	assert.Equal([]ids.ID{containerIDs[0], containerIDs[1], containerID}, sink.getDelivered())
+}
+
+// failingDeleteDB fails to delete keys while [fail] is set
+type failingDeleteDB struct {
+	database.Database
+
+	lock sync.Mutex
+	fail bool
+}
+
+func (db *failingDeleteDB) Delete(key []byte) error {
+	db.lock.Lock()
+	defer db.lock.Unlock()
+
+	if db.fail {
+		return errSinkUnavailable
+	}
+	return db.Database.Delete(key)
+}
+
+func (db *failingDeleteDB) setFail(fail bool) {
+	db.lock.Lock()
+	defer db.lock.Unlock()
+
+	db.fail = fail
+}
+
+func TestForwarderRetriesFailedDequeues(t *testing.T) {
+	assert := assert.New(t)
+
+	queue := &failingDeleteDB{Database: memdb.New(), fail: true}
+	sink := newTestSink()
+	f := startForwarder(t, sink, queue)
+	defer f.close()
+
+	ctx := snow.DefaultContextTest()
+	containerIDs := []ids.ID{ids.GenerateTestID(), ids.GenerateTestID()}
+	for _, containerID := range containerIDs {
+		assert.NoError(f.Accept(ctx, containerID, containerID[:]))
+	}
+	sink.awaitDeliveries(t, 1)
+
+	// The failure is surfaced while the delivered event can't be dequeued
+	for testutil.ToFloat64(f.metrics.numDequeueFailures) == 0 {
+		time.Sleep(time.Millisecond)
+	}
+	_, err := f.health()
+	assert.Error(err)
+
+	// The dispatcher keeps running once dequeues succeed again
+	queue.setFail(false)
+	sink.awaitDeliveries(t, 1)
+	assert.Equal(containerIDs, sink.getDelivered())
+	for testutil.ToFloat64(f.metrics.numQueued) != 0 {
+		time.Sleep(time.Millisecond)
+	}
+	_, err = f.health()
+	assert.NoError(err)
+}
@@ -0,0 +1,42 @@
+// (c) 2019-2020, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package acceptors
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/ava-labs/avalanchego/utils/constants"
+	"github.com/ava-labs/avalanchego/utils/wrappers"
+)
+
+type metrics struct {
+	numQueued                      prometheus.Gauge
+	numDropped, numDequeueFailures prometheus.Counter
+}
+
+func (m *metrics) initialize(registerer prometheus.Registerer) error {
+	m.numQueued = prometheus.NewGauge(prometheus.GaugeOpts{
+		Namespace: constants.PlatformName,
+		Name:      "acceptor_events_queued",
+		Help:      "Number of accepted container events queued for delivery to the acceptors",
+	})
+	m.numDropped = prometheus.NewCounter(prometheus.CounterOpts{
+		Namespace: constants.PlatformName,
+		Name:      "acceptor_events_dropped",
+		Help:      "Number of accepted container events dropped because an acceptor's queue was full",
+	})
+	m.numDequeueFailures = prometheus.NewCounter(prometheus.CounterOpts{
+		Namespace: constants.PlatformName,
+		Name:      "acceptor_dequeue_failures",
+		Help:      "Number of times a delivered event couldn't be removed from an acceptor's queue",
+	})
+
+	errs := wrappers.Errs{}
+	errs.Add(
+		registerer.Register(m.numQueued),
+		registerer.Register(m.numDropped),
+		registerer.Register(m.numDequeueFailures),
+	)
+	return errs.Err
+}
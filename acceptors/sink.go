@@ -0,0 +1,86 @@
+// (c) 2019-2020, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package acceptors
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"time"
+
+	"github.com/ava-labs/avalanchego/ids"
+	"github.com/ava-labs/avalanchego/utils/formatting"
+)
+
+// Event is delivered to sinks when a container is accepted
+type Event struct {
+	ChainID     ids.ID `json:"chainID"`
+	ContainerID ids.ID `json:"containerID"`
+	// Hex encoding of the container
+	Container string `json:"container"`
+	// Unix time, in seconds, at which the container was accepted
+	Timestamp int64 `json:"timestamp"`
+}
+
+// NewEvent returns the event of [container] being accepted on [chainID]
+func NewEvent(chainID, containerID ids.ID, container []byte, timestamp time.Time) (*Event, error) {
+	containerStr, err := formatting.Encode(formatting.Hex, container)
+	if err != nil {
+		return nil, err
+	}
+	return &Event{
+		ChainID:     chainID,
+		ContainerID: containerID,
+		Container:   containerStr,
+		Timestamp:   timestamp.Unix(),
+	}, nil
+}
+
+// Sink is an external endpoint that accepted container events are delivered
+// to. Events may be delivered more than once, so sinks should de-duplicate
+// them by their chain and container IDs.
+type Sink interface {
+	// Deliver [event] to the sink. If an error is returned, the event is
+	// delivered again later.
+	Deliver(event *Event) error
+}
+
+// WebhookSink delivers each event as the JSON body of a POST request to a URL.
+// Any response status other than 2xx is treated as a failed delivery.
+type WebhookSink struct {
+	url    string
+	client http.Client
+}
+
+// NewWebhookSink returns a sink that POSTs events to [url], giving up on each
+// request after [timeout]
+func NewWebhookSink(url string, timeout time.Duration) *WebhookSink {
+	return &WebhookSink{
+		url:    url,
+		client: http.Client{Timeout: timeout},
+	}
+}
+
+// Deliver implements the Sink interface
+func (s *WebhookSink) Deliver(event *Event) error {
+	body, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+	resp, err := s.client.Post(s.url, "application/json", bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	// Drain the body so that the connection can be reused
+	_, _ = io.Copy(ioutil.Discard, resp.Body)
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("webhook %s responded with %s", s.url, resp.Status)
+	}
+	return nil
+}
@@ -0,0 +1,48 @@
+// (c) 2019-2020, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package acceptors
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/ava-labs/avalanchego/ids"
+)
+
+func TestWebhookSinkDeliver(t *testing.T) {
+	assert := assert.New(t)
+
+	event, err := NewEvent(ids.GenerateTestID(), ids.GenerateTestID(), []byte{1, 2, 3}, time.Unix(1337, 0))
+	assert.NoError(err)
+
+	received := &Event{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(http.MethodPost, r.Method)
+		assert.NoError(json.NewDecoder(r.Body).Decode(received))
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	sink := NewWebhookSink(server.URL, time.Second)
+	assert.NoError(sink.Deliver(event))
+	assert.Equal(event, received)
+}
+
+func TestWebhookSinkDeliverErrorStatus(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer server.Close()
+
+	event, err := NewEvent(ids.GenerateTestID(), ids.GenerateTestID(), nil, time.Unix(0, 0))
+	assert.NoError(t, err)
+
+	sink := NewWebhookSink(server.URL, time.Second)
+	assert.Error(t, sink.Deliver(event))
+}
@@ -79,6 +79,51 @@ func (c *Client) GetChainAliases(chain string) ([]string, error) {
 	return res.Aliases, err
 }
 
+// CompactDB ...
+func (c *Client) CompactDB(chain string) (bool, error) {
+	res := &api.SuccessResponse{}
+	err := c.requester.SendRequest("compactDB", &ChainArgs{
+		Chain: chain,
+	}, res)
+	return res.Success, err
+}
+
+// FlushCaches ...
+func (c *Client) FlushCaches(chain string) (bool, error) {
+	res := &api.SuccessResponse{}
+	err := c.requester.SendRequest("flushCaches", &ChainArgs{
+		Chain: chain,
+	}, res)
+	return res.Success, err
+}
+
+// GetDBSpaceUsage ...
+func (c *Client) GetDBSpaceUsage(chain string) (*GetDBSpaceUsageReply, error) {
+	res := &GetDBSpaceUsageReply{}
+	err := c.requester.SendRequest("getDBSpaceUsage", &ChainArgs{
+		Chain: chain,
+	}, res)
+	return res, err
+}
+
+// DisableChain ...
+func (c *Client) DisableChain(chain string) (bool, error) {
+	res := &api.SuccessResponse{}
+	err := c.requester.SendRequest("disableChain", &ChainArgs{
+		Chain: chain,
+	}, res)
+	return res.Success, err
+}
+
+// EnableChain ...
+func (c *Client) EnableChain(chain string) (bool, error) {
+	res := &api.SuccessResponse{}
+	err := c.requester.SendRequest("enableChain", &ChainArgs{
+		Chain: chain,
+	}, res)
+	return res.Success, err
+}
+
 // Stacktrace ...
 func (c *Client) Stacktrace() (bool, error) {
 	res := &api.SuccessResponse{}
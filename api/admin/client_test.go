@@ -11,6 +11,8 @@ import (
 
 	"github.com/ava-labs/avalanchego/api"
 	"github.com/ava-labs/avalanchego/utils/rpc"
+
+	cjson "github.com/ava-labs/avalanchego/utils/json"
 )
 
 // SuccessResponseTest defines the expected result of an API call that returns SuccessResponse
@@ -61,6 +63,9 @@ func (mc *mockClient) SendRequest(method string, params interface{}, reply inter
 	case *GetChainAliasesReply:
 		response := mc.response.(*GetChainAliasesReply)
 		*p = *response
+	case *GetDBSpaceUsageReply:
+		response := mc.response.(*GetDBSpaceUsageReply)
+		*p = *response
 	default:
 		panic("illegal type")
 	}
@@ -221,3 +226,102 @@ func TestStacktrace(t *testing.T) {
 		}
 	}
 }
+
+func TestCompactDB(t *testing.T) {
+	tests := GetSuccessResponseTests()
+
+	for _, test := range tests {
+		mockClient := Client{requester: NewMockClient(api.SuccessResponse{Success: test.Success}, test.Err)}
+		success, err := mockClient.CompactDB("chain")
+		// if there is error as expected, the test passes
+		if err != nil && test.Err != nil {
+			continue
+		}
+		if err != nil {
+			t.Fatalf("Unexepcted error: %s", err)
+		}
+		if success != test.Success {
+			t.Fatalf("Expected success response to be: %v, but found: %v", test.Success, success)
+		}
+	}
+}
+
+func TestFlushCaches(t *testing.T) {
+	tests := GetSuccessResponseTests()
+
+	for _, test := range tests {
+		mockClient := Client{requester: NewMockClient(api.SuccessResponse{Success: test.Success}, test.Err)}
+		success, err := mockClient.FlushCaches("chain")
+		// if there is error as expected, the test passes
+		if err != nil && test.Err != nil {
+			continue
+		}
+		if err != nil {
+			t.Fatalf("Unexepcted error: %s", err)
+		}
+		if success != test.Success {
+			t.Fatalf("Expected success response to be: %v, but found: %v", test.Success, success)
+		}
+	}
+}
+
+func TestGetDBSpaceUsage(t *testing.T) {
+	t.Run("successful", func(t *testing.T) {
+		expectedReply := &GetDBSpaceUsageReply{
+			Databases: map[string]cjson.Uint64{"vm": 10, "bs": 5},
+			Total:     15,
+		}
+		mockClient := Client{requester: NewMockClient(expectedReply, nil)}
+
+		reply, err := mockClient.GetDBSpaceUsage("chain")
+
+		assert.NoError(t, err)
+		assert.Equal(t, expectedReply, reply)
+	})
+
+	t.Run("failure", func(t *testing.T) {
+		mockClient := Client{requester: NewMockClient(&GetDBSpaceUsageReply{}, errors.New("some error"))}
+
+		_, err := mockClient.GetDBSpaceUsage("chain")
+
+		assert.EqualError(t, err, "some error")
+	})
+}
+
+func TestDisableChain(t *testing.T) {
+	tests := GetSuccessResponseTests()
+
+	for _, test := range tests {
+		mockClient := Client{requester: NewMockClient(api.SuccessResponse{Success: test.Success}, test.Err)}
+		success, err := mockClient.DisableChain("chain")
+		// if there is error as expected, the test passes
+		if err != nil && test.Err != nil {
+			continue
+		}
+		if err != nil {
+			t.Fatalf("Unexepcted error: %s", err)
+		}
+		if success != test.Success {
+			t.Fatalf("Expected success response to be: %v, but found: %v", test.Success, success)
+		}
+	}
+}
+
+func TestEnableChain(t *testing.T) {
+	tests := GetSuccessResponseTests()
+
+	for _, test := range tests {
+		mockClient := Client{requester: NewMockClient(api.SuccessResponse{Success: test.Success}, test.Err)}
+		success, err := mockClient.EnableChain("chain")
+		// if there is error as expected, the test passes
+		if err != nil && test.Err != nil {
+			continue
+		}
+		if err != nil {
+			t.Fatalf("Unexepcted error: %s", err)
+		}
+		if success != test.Success {
+			t.Fatalf("Expected success response to be: %v, but found: %v", test.Success, success)
+		}
+	}
+}
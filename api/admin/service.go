@@ -153,6 +153,105 @@ func (service *Admin) GetChainAliases(r *http.Request, args *GetChainAliasesArgs
 	return nil
 }
 
+// ChainArgs are the arguments for calls that operate on a single chain
+type ChainArgs struct {
+	Chain string `json:"chain"`
+}
+
+// CompactDB compacts the databases of the chain
+func (service *Admin) CompactDB(_ *http.Request, args *ChainArgs, reply *api.SuccessResponse) error {
+	service.log.Info("Admin: CompactDB called with Chain: %s", args.Chain)
+
+	chainID, err := service.chainManager.Lookup(args.Chain)
+	if err != nil {
+		return err
+	}
+	if err := service.chainManager.CompactDB(chainID); err != nil {
+		return err
+	}
+
+	reply.Success = true
+	return nil
+}
+
+// FlushCaches drops the in-memory caches of the chain
+func (service *Admin) FlushCaches(_ *http.Request, args *ChainArgs, reply *api.SuccessResponse) error {
+	service.log.Info("Admin: FlushCaches called with Chain: %s", args.Chain)
+
+	chainID, err := service.chainManager.Lookup(args.Chain)
+	if err != nil {
+		return err
+	}
+	if err := service.chainManager.FlushCaches(chainID); err != nil {
+		return err
+	}
+
+	reply.Success = true
+	return nil
+}
+
+// GetDBSpaceUsageReply is the number of bytes stored by the chain
+type GetDBSpaceUsageReply struct {
+	// Name of the database's prefix --> number of key and value bytes stored
+	Databases map[string]cjson.Uint64 `json:"databases"`
+	Total     cjson.Uint64            `json:"total"`
+}
+
+// GetDBSpaceUsage returns the number of bytes stored in each of the databases
+// of the chain
+func (service *Admin) GetDBSpaceUsage(_ *http.Request, args *ChainArgs, reply *GetDBSpaceUsageReply) error {
+	service.log.Info("Admin: GetDBSpaceUsage called with Chain: %s", args.Chain)
+
+	chainID, err := service.chainManager.Lookup(args.Chain)
+	if err != nil {
+		return err
+	}
+	usage, err := service.chainManager.DBSpaceUsage(chainID)
+	if err != nil {
+		return err
+	}
+
+	reply.Databases = make(map[string]cjson.Uint64, len(usage))
+	for name, size := range usage {
+		reply.Databases[name] = cjson.Uint64(size)
+		reply.Total += cjson.Uint64(size)
+	}
+	return nil
+}
+
+// DisableChain stops the chain from participating in consensus. Requests from
+// peers are answered with empty responses.
+func (service *Admin) DisableChain(_ *http.Request, args *ChainArgs, reply *api.SuccessResponse) error {
+	service.log.Info("Admin: DisableChain called with Chain: %s", args.Chain)
+
+	chainID, err := service.chainManager.Lookup(args.Chain)
+	if err != nil {
+		return err
+	}
+	if err := service.chainManager.DisableChain(chainID); err != nil {
+		return err
+	}
+
+	reply.Success = true
+	return nil
+}
+
+// EnableChain resumes running consensus on a chain that was disabled
+func (service *Admin) EnableChain(_ *http.Request, args *ChainArgs, reply *api.SuccessResponse) error {
+	service.log.Info("Admin: EnableChain called with Chain: %s", args.Chain)
+
+	chainID, err := service.chainManager.Lookup(args.Chain)
+	if err != nil {
+		return err
+	}
+	if err := service.chainManager.EnableChain(chainID); err != nil {
+		return err
+	}
+
+	reply.Success = true
+	return nil
+}
+
 // Stacktrace returns the current global stacktrace
 func (service *Admin) Stacktrace(_ *http.Request, _ *struct{}, reply *api.SuccessResponse) error {
 	service.log.Info("Admin: Stacktrace called")
@@ -68,6 +68,15 @@ func (c *Client) IsBootstrapped(chain string) (bool, error) {
 	return res.IsBootstrapped, err
 }
 
+// GetChainDependencies ...
+func (c *Client) GetChainDependencies(chain string) (*GetChainDependenciesResponse, error) {
+	res := &GetChainDependenciesResponse{}
+	err := c.requester.SendRequest("getChainDependencies", &GetChainDependenciesArgs{
+		Chain: chain,
+	}, res)
+	return res, err
+}
+
 // GetTxFee ...
 func (c *Client) GetTxFee() (*GetTxFeeResponse, error) {
 	res := &GetTxFeeResponse{}
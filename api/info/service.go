@@ -191,6 +191,53 @@ func (service *Info) IsBootstrapped(_ *http.Request, args *IsBootstrappedArgs, r
 	return nil
 }
 
+// GetChainDependenciesArgs are the arguments for calling GetChainDependencies
+type GetChainDependenciesArgs struct {
+	// Alias of the chain
+	// Can also be the string representation of the chain's ID
+	Chain string `json:"chain"`
+}
+
+// GetChainDependenciesResponse are the results from calling
+// GetChainDependencies
+type GetChainDependenciesResponse struct {
+	// True iff the chain has been created
+	Created bool `json:"created"`
+	// True iff the chain has been created and is done bootstrapping
+	IsBootstrapped bool `json:"isBootstrapped"`
+	// IDs of the chains that must finish bootstrapping before this chain is
+	// created
+	WaitingOn []string `json:"waitingOn"`
+}
+
+// GetChainDependencies returns whether [args.Chain] has been created, whether
+// it is done bootstrapping, and the chains it is waiting on before it can be
+// created
+func (service *Info) GetChainDependencies(_ *http.Request, args *GetChainDependenciesArgs, reply *GetChainDependenciesResponse) error {
+	service.log.Info("Info: GetChainDependencies called with chain: %s", args.Chain)
+	if args.Chain == "" {
+		return fmt.Errorf("argument 'chain' not given")
+	}
+	// A chain that is waiting on its dependencies hasn't been aliased yet, so
+	// fall back to parsing the argument as a chain ID.
+	chainID, err := service.chainManager.Lookup(args.Chain)
+	if err != nil {
+		chainID, err = ids.FromString(args.Chain)
+		if err != nil {
+			return fmt.Errorf("there is no chain with alias/ID '%s'", args.Chain)
+		}
+	}
+
+	status := service.chainManager.DependencyStatus(chainID)
+	reply.Created = status.Created
+	reply.IsBootstrapped = status.Bootstrapped
+	reply.WaitingOn = make([]string, len(status.WaitingOn))
+	for i, dep := range status.WaitingOn {
+		reply.WaitingOn[i] = dep.String()
+	}
+	return nil
+}
+
 // GetTxFeeResponse ...
 type GetTxFeeResponse struct {
 	CreationTxFee json.Uint64 `json:"creationTxFee"`
@@ -0,0 +1,142 @@
+// (c) 2019-2020, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package metrics
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/ava-labs/avalanchego/utils/logging"
+	"github.com/ava-labs/avalanchego/utils/perms"
+	"github.com/ava-labs/avalanchego/utils/timer"
+)
+
+const (
+	snapshotPrefix    = "metrics-"
+	snapshotExtension = ".json"
+)
+
+var errNoSnapshots = errors.New("the number of metrics snapshots to keep must be positive")
+
+// snapshot is the content of a metrics snapshot file
+type snapshot struct {
+	Time     time.Time   `json:"time"`
+	Families interface{} `json:"families"`
+}
+
+// Snapshotter periodically writes the metrics gathered from a registry to
+// disk, so that they can be analyzed after an incident even if they weren't
+// being scraped at the time. Only the most recent snapshots are kept.
+type Snapshotter struct {
+	log          logging.Logger
+	gatherer     prometheus.Gatherer
+	dir          string
+	maxSnapshots int
+	clock        timer.Clock
+
+	lock sync.Mutex
+	// Paths of the snapshots in [dir], oldest first
+	snapshots []string
+
+	repeater *timer.Repeater
+}
+
+// NewSnapshotter returns a snapshotter that writes the metrics of [gatherer]
+// to [dir] every [frequency], keeping the latest [maxSnapshots] snapshots.
+// Snapshots already in [dir], such as from before a restart, count towards
+// [maxSnapshots].
+func NewSnapshotter(
+	log logging.Logger,
+	gatherer prometheus.Gatherer,
+	dir string,
+	frequency time.Duration,
+	maxSnapshots int,
+) (*Snapshotter, error) {
+	if maxSnapshots <= 0 {
+		return nil, errNoSnapshots
+	}
+	if err := os.MkdirAll(dir, perms.ReadWriteExecute); err != nil {
+		return nil, fmt.Errorf("couldn't create metrics snapshot directory: %w", err)
+	}
+	files, err := ioutil.ReadDir(dir)
+	if err != nil {
+		return nil, fmt.Errorf("couldn't read metrics snapshot directory: %w", err)
+	}
+
+	s := &Snapshotter{
+		log:          log,
+		gatherer:     gatherer,
+		dir:          dir,
+		maxSnapshots: maxSnapshots,
+	}
+	// Snapshot names are zero padded timestamps, so sorting them by name
+	// sorts them by age
+	for _, file := range files {
+		name := file.Name()
+		if !file.IsDir() && strings.HasPrefix(name, snapshotPrefix) && strings.HasSuffix(name, snapshotExtension) {
+			s.snapshots = append(s.snapshots, filepath.Join(dir, name))
+		}
+	}
+	sort.Strings(s.snapshots)
+	s.repeater = timer.NewRepeater(func() {
+		if err := s.Snapshot(); err != nil {
+			s.log.Warn("couldn't snapshot metrics: %s", err)
+		}
+	}, frequency)
+	return s, nil
+}
+
+// Dispatch writes snapshots until Stop is called
+func (s *Snapshotter) Dispatch() { s.repeater.Dispatch() }
+
+// Stop writing snapshots. A final snapshot is written, so the metrics at the
+// time of shutdown are recorded. Assumes Dispatch was called.
+func (s *Snapshotter) Stop() error {
+	s.repeater.Stop()
+	return s.Snapshot()
+}
+
+// Snapshot writes the current metrics to disk, and removes the oldest
+// snapshots that are beyond the number to keep
+func (s *Snapshotter) Snapshot() error {
+	families, err := s.gatherer.Gather()
+	if err != nil {
+		return fmt.Errorf("couldn't gather metrics: %w", err)
+	}
+	now := s.clock.Time()
+	bytes, err := json.Marshal(snapshot{
+		Time:     now,
+		Families: families,
+	})
+	if err != nil {
+		return err
+	}
+
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	path := filepath.Join(s.dir, fmt.Sprintf("%s%020d%s", snapshotPrefix, now.UnixNano(), snapshotExtension))
+	if err := perms.WriteFile(path, bytes, perms.ReadWrite); err != nil {
+		return fmt.Errorf("couldn't write metrics snapshot: %w", err)
+	}
+	s.snapshots = append(s.snapshots, path)
+
+	for len(s.snapshots) > s.maxSnapshots {
+		if err := os.Remove(s.snapshots[0]); err != nil && !os.IsNotExist(err) {
+			return fmt.Errorf("couldn't remove metrics snapshot: %w", err)
+		}
+		s.snapshots = s.snapshots[1:]
+	}
+	return nil
+}
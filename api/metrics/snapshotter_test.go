@@ -0,0 +1,86 @@
+// (c) 2019-2020, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package metrics
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/ava-labs/avalanchego/utils/logging"
+)
+
+func snapshotNames(t *testing.T, dir string) []string {
+	files, err := ioutil.ReadDir(dir)
+	assert.NoError(t, err)
+	names := []string(nil)
+	for _, file := range files {
+		names = append(names, file.Name())
+	}
+	return names
+}
+
+func TestSnapshotterKeepsLatestSnapshots(t *testing.T) {
+	dir, err := ioutil.TempDir("", "metrics-snapshots")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	registry := prometheus.NewRegistry()
+	counter := prometheus.NewCounter(prometheus.CounterOpts{Name: "test_counter"})
+	assert.NoError(t, registry.Register(counter))
+
+	s, err := NewSnapshotter(logging.NoLog{}, registry, dir, time.Minute, 2)
+	assert.NoError(t, err)
+	for i := int64(1); i <= 3; i++ {
+		s.clock.Set(time.Unix(i, 0))
+		counter.Inc()
+		assert.NoError(t, s.Snapshot())
+	}
+
+	names := snapshotNames(t, dir)
+	assert.Equal(t, []string{
+		"metrics-00000000002000000000.json",
+		"metrics-00000000003000000000.json",
+	}, names)
+
+	bytes, err := ioutil.ReadFile(filepath.Join(dir, names[1]))
+	assert.NoError(t, err)
+	snap := struct {
+		Time     time.Time `json:"time"`
+		Families []struct {
+			Name   string `json:"name"`
+			Metric []struct {
+				Counter struct {
+					Value float64 `json:"value"`
+				} `json:"counter"`
+			} `json:"metric"`
+		} `json:"families"`
+	}{}
+	assert.NoError(t, json.Unmarshal(bytes, &snap))
+	assert.True(t, snap.Time.Equal(time.Unix(3, 0)))
+	assert.Len(t, snap.Families, 1)
+	assert.Equal(t, "test_counter", snap.Families[0].Name)
+	assert.Equal(t, float64(3), snap.Families[0].Metric[0].Counter.Value)
+
+	// Snapshots from before a restart count towards the number kept
+	s, err = NewSnapshotter(logging.NoLog{}, registry, dir, time.Minute, 2)
+	assert.NoError(t, err)
+	s.clock.Set(time.Unix(4, 0))
+	assert.NoError(t, s.Snapshot())
+	assert.Equal(t, []string{
+		"metrics-00000000003000000000.json",
+		"metrics-00000000004000000000.json",
+	}, snapshotNames(t, dir))
+}
+
+func TestSnapshotterRequiresSnapshots(t *testing.T) {
+	_, err := NewSnapshotter(logging.NoLog{}, prometheus.NewRegistry(), "", time.Minute, 0)
+	assert.Equal(t, errNoSnapshots, err)
+}
@@ -0,0 +1,243 @@
+// (c) 2019-2020, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package atomic
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+
+	"github.com/ava-labs/avalanchego/database"
+	"github.com/ava-labs/avalanchego/database/prefixdb"
+	"github.com/ava-labs/avalanchego/ids"
+	"github.com/ava-labs/avalanchego/utils/wrappers"
+)
+
+var (
+	smallerMessagePrefix = []byte{4}
+	largerMessagePrefix  = []byte{5}
+
+	queuePrefix  = []byte{0}
+	nextNonceKey = []byte{1}
+
+	errUnknownSourceChain = errors.New("no acceptance checker for source chain")
+	errMalformedNonce     = errors.New("malformed message nonce")
+)
+
+// AcceptanceChecker is implemented by VMs whose messages can be verified by
+// the chains they're sent to
+type AcceptanceChecker interface {
+	// IsAccepted returns true if the container [containerID] was accepted.
+	// This is called by other chains, without this chain's context lock
+	// held, so it must be safe to call concurrently.
+	IsAccepted(containerID ids.ID) (bool, error)
+}
+
+// Message is a message sent from one chain to another chain on this node
+type Message struct {
+	// Chain that sent this message
+	SourceChainID ids.ID
+	// Position of this message in the messages sent from [SourceChainID] to
+	// the destination chain
+	Nonce uint64
+	// ID of the accepted container, on the source chain, that sent this
+	// message
+	AcceptanceID ids.ID
+	// Body of this message
+	Payload []byte
+}
+
+type dbMessage struct {
+	AcceptanceID ids.ID `serialize:"true"`
+	Payload      []byte `serialize:"true"`
+}
+
+// MessageBus lets a chain send messages to, and receive messages from, other
+// chains on this node. Messages are stored in shared memory, so they persist
+// until they're consumed, and are delivered in the order they were sent.
+type MessageBus interface {
+	// Send [payload] to [destinationChainID], atomically with [batches].
+	// [acceptanceID] is the container whose acceptance sent the message, so
+	// this should be called when that container is accepted.
+	Send(destinationChainID, acceptanceID ids.ID, payload []byte, batches ...database.Batch) error
+
+	// Messages returns up to [limit] of the oldest messages that
+	// [sourceChainID] has sent to this chain and that haven't been consumed.
+	// Only messages whose containers the source chain has accepted are
+	// returned.
+	Messages(sourceChainID ids.ID, limit int) ([]*Message, error)
+
+	// Consume every message from [sourceChainID] with a nonce less than
+	// [nonce], atomically with [batches]
+	Consume(sourceChainID ids.ID, nonce uint64, batches ...database.Batch) error
+
+	// Subscribe to messages from [sourceChainID]. The returned channel is
+	// signaled when a message is sent, until the returned function is called.
+	Subscribe(sourceChainID ids.ID) (<-chan struct{}, func())
+}
+
+// messageBus provides the API for a blockchain to send messages to, and
+// receive messages from, other blockchains
+type messageBus struct {
+	m           *Memory
+	thisChainID ids.ID
+}
+
+func (mb *messageBus) Send(destinationChainID, acceptanceID ids.ID, payload []byte, batches ...database.Batch) error {
+	sharedID := mb.m.sharedID(destinationChainID, mb.thisChainID)
+	vdb, db := mb.m.GetDatabase(sharedID)
+	defer mb.m.ReleaseDatabase(sharedID)
+
+	// Messages are written to the destination chain's side
+	var msgDB database.Database
+	if bytes.Compare(mb.thisChainID[:], destinationChainID[:]) == -1 {
+		msgDB = prefixdb.New(largerMessagePrefix, db)
+	} else {
+		msgDB = prefixdb.New(smallerMessagePrefix, db)
+	}
+
+	nonce, err := getNonce(msgDB)
+	if err != nil {
+		return err
+	}
+	msgBytes, err := mb.m.codec.Marshal(codecVersion, &dbMessage{
+		AcceptanceID: acceptanceID,
+		Payload:      payload,
+	})
+	if err != nil {
+		return err
+	}
+	if err := prefixdb.New(queuePrefix, msgDB).Put(nonceToBytes(nonce), msgBytes); err != nil {
+		return err
+	}
+	if err := msgDB.Put(nextNonceKey, nonceToBytes(nonce+1)); err != nil {
+		return err
+	}
+
+	myBatch, err := vdb.CommitBatch()
+	if err != nil {
+		return err
+	}
+	if err := WriteAll(myBatch, batches...); err != nil {
+		return err
+	}
+
+	mb.m.notify(mb.thisChainID, destinationChainID)
+	return nil
+}
+
+func (mb *messageBus) Messages(sourceChainID ids.ID, limit int) ([]*Message, error) {
+	checker, ok := mb.m.acceptanceChecker(sourceChainID)
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", errUnknownSourceChain, sourceChainID)
+	}
+
+	sharedID := mb.m.sharedID(sourceChainID, mb.thisChainID)
+	_, db := mb.m.GetDatabase(sharedID)
+	defer mb.m.ReleaseDatabase(sharedID)
+
+	iter := prefixdb.New(queuePrefix, mb.queueDB(sourceChainID, db)).NewIterator()
+	defer iter.Release()
+
+	msgs := []*Message(nil)
+	for len(msgs) < limit && iter.Next() {
+		nonce, err := nonceFromBytes(iter.Key())
+		if err != nil {
+			return nil, err
+		}
+		msg := dbMessage{}
+		if _, err := mb.m.codec.Unmarshal(iter.Value(), &msg); err != nil {
+			return nil, err
+		}
+
+		// A message is only delivered once its container is accepted. Later
+		// messages are withheld so that messages are delivered in order.
+		accepted, err := checker.IsAccepted(msg.AcceptanceID)
+		if err != nil {
+			return nil, err
+		}
+		if !accepted {
+			mb.m.log.Debug("withholding message %d from %s since %s isn't accepted", nonce, sourceChainID, msg.AcceptanceID)
+			break
+		}
+
+		msgs = append(msgs, &Message{
+			SourceChainID: sourceChainID,
+			Nonce:         nonce,
+			AcceptanceID:  msg.AcceptanceID,
+			Payload:       msg.Payload,
+		})
+	}
+	return msgs, iter.Error()
+}
+
+func (mb *messageBus) Consume(sourceChainID ids.ID, nonce uint64, batches ...database.Batch) error {
+	sharedID := mb.m.sharedID(sourceChainID, mb.thisChainID)
+	vdb, db := mb.m.GetDatabase(sharedID)
+	defer mb.m.ReleaseDatabase(sharedID)
+
+	queueDB := prefixdb.New(queuePrefix, mb.queueDB(sourceChainID, db))
+	iter := queueDB.NewIterator()
+	defer iter.Release()
+
+	for iter.Next() {
+		msgNonce, err := nonceFromBytes(iter.Key())
+		if err != nil {
+			return err
+		}
+		if msgNonce >= nonce {
+			break
+		}
+		if err := queueDB.Delete(iter.Key()); err != nil {
+			return err
+		}
+	}
+	if err := iter.Error(); err != nil {
+		return err
+	}
+
+	myBatch, err := vdb.CommitBatch()
+	if err != nil {
+		return err
+	}
+	return WriteAll(myBatch, batches...)
+}
+
+func (mb *messageBus) Subscribe(sourceChainID ids.ID) (<-chan struct{}, func()) {
+	return mb.m.subscribe(sourceChainID, mb.thisChainID)
+}
+
+// queueDB returns the database of this chain's side of the messages shared
+// with [peerChainID]
+func (mb *messageBus) queueDB(peerChainID ids.ID, db database.Database) database.Database {
+	if bytes.Compare(mb.thisChainID[:], peerChainID[:]) == -1 {
+		return prefixdb.New(smallerMessagePrefix, db)
+	}
+	return prefixdb.New(largerMessagePrefix, db)
+}
+
+func getNonce(db database.KeyValueReader) (uint64, error) {
+	nonceBytes, err := db.Get(nextNonceKey)
+	if err == database.ErrNotFound {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, err
+	}
+	return nonceFromBytes(nonceBytes)
+}
+
+func nonceToBytes(nonce uint64) []byte {
+	nonceBytes := make([]byte, wrappers.LongLen)
+	binary.BigEndian.PutUint64(nonceBytes, nonce)
+	return nonceBytes
+}
+
+func nonceFromBytes(nonceBytes []byte) (uint64, error) {
+	if len(nonceBytes) != wrappers.LongLen {
+		return 0, fmt.Errorf("%w: %d bytes", errMalformedNonce, len(nonceBytes))
+	}
+	return binary.BigEndian.Uint64(nonceBytes), nil
+}
@@ -0,0 +1,125 @@
+// (c) 2019-2020, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package atomic
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/ava-labs/avalanchego/database/memdb"
+	"github.com/ava-labs/avalanchego/ids"
+	"github.com/ava-labs/avalanchego/utils/logging"
+)
+
+type testAcceptanceChecker struct {
+	accepted ids.Set
+}
+
+func (c *testAcceptanceChecker) IsAccepted(containerID ids.ID) (bool, error) {
+	return c.accepted.Contains(containerID), nil
+}
+
+func TestMessageBus(t *testing.T) {
+	assert := assert.New(t)
+
+	m := Memory{}
+	err := m.Initialize(logging.NoLog{}, memdb.New())
+	assert.NoError(err)
+
+	chainID0 := ids.GenerateTestID()
+	chainID1 := ids.GenerateTestID()
+
+	checker := &testAcceptanceChecker{}
+	m.RegisterAcceptanceChecker(chainID0, checker)
+
+	mb0 := m.NewMessageBus(chainID0)
+	mb1 := m.NewMessageBus(chainID1)
+
+	notify, unsubscribe := mb1.Subscribe(chainID0)
+	defer unsubscribe()
+
+	containerID0 := ids.GenerateTestID()
+	containerID1 := ids.GenerateTestID()
+	assert.NoError(mb0.Send(chainID1, containerID0, []byte{0}))
+	assert.NoError(mb0.Send(chainID1, containerID1, []byte{1}))
+
+	select {
+	case <-notify:
+	default:
+		t.Fatal("subscription should have been notified")
+	}
+
+	msgs, err := mb1.Messages(chainID0, 10)
+	assert.NoError(err)
+	assert.Empty(msgs, "messages from containers that aren't accepted shouldn't be delivered")
+
+	// Messages are delivered in order, so the second message is withheld
+	// until the first is delivered
+	checker.accepted.Add(containerID1)
+	msgs, err = mb1.Messages(chainID0, 10)
+	assert.NoError(err)
+	assert.Empty(msgs)
+
+	checker.accepted.Add(containerID0)
+	msgs, err = mb1.Messages(chainID0, 10)
+	assert.NoError(err)
+	assert.Len(msgs, 2)
+	for i, msg := range msgs {
+		assert.Equal(chainID0, msg.SourceChainID)
+		assert.Equal(uint64(i), msg.Nonce)
+		assert.Equal([]byte{byte(i)}, msg.Payload)
+	}
+
+	// Messages are only delivered to their destination
+	msgs, err = mb0.Messages(chainID0, 10)
+	assert.NoError(err)
+	assert.Empty(msgs)
+
+	assert.NoError(mb1.Consume(chainID0, 1))
+	msgs, err = mb1.Messages(chainID0, 10)
+	assert.NoError(err)
+	assert.Len(msgs, 1)
+	assert.Equal(uint64(1), msgs[0].Nonce)
+	assert.Equal(containerID1, msgs[0].AcceptanceID)
+
+	// Nonces keep increasing after messages are consumed
+	assert.NoError(mb1.Consume(chainID0, 2))
+	assert.NoError(mb0.Send(chainID1, containerID0, []byte{2}))
+	msgs, err = mb1.Messages(chainID0, 10)
+	assert.NoError(err)
+	assert.Len(msgs, 1)
+	assert.Equal(uint64(2), msgs[0].Nonce)
+}
+
+func TestMessageBusUnknownSourceChain(t *testing.T) {
+	m := Memory{}
+	err := m.Initialize(logging.NoLog{}, memdb.New())
+	assert.NoError(t, err)
+
+	mb := m.NewMessageBus(ids.GenerateTestID())
+	_, err = mb.Messages(ids.GenerateTestID(), 1)
+	assert.True(t, errors.Is(err, errUnknownSourceChain))
+}
+
+func TestMessageBusUnsubscribe(t *testing.T) {
+	m := Memory{}
+	err := m.Initialize(logging.NoLog{}, memdb.New())
+	assert.NoError(t, err)
+
+	chainID0 := ids.GenerateTestID()
+	chainID1 := ids.GenerateTestID()
+
+	notify, unsubscribe := m.NewMessageBus(chainID1).Subscribe(chainID0)
+	unsubscribe()
+	assert.Empty(t, m.subscriptions)
+
+	assert.NoError(t, m.NewMessageBus(chainID0).Send(chainID1, ids.GenerateTestID(), nil))
+	select {
+	case <-notify:
+		t.Fatal("subscription shouldn't be notified after unsubscribing")
+	default:
+	}
+}
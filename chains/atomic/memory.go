@@ -33,6 +33,16 @@ type Memory struct {
 	codec codec.Manager
 	locks map[ids.ID]*rcLock
 	db    database.Database
+
+	// Chain ID --> checker of the containers that chain accepted
+	checkers map[ids.ID]AcceptanceChecker
+	// Destination chain ID --> source chain ID --> subscriptions to messages
+	// sent from the source chain to the destination chain
+	subscriptions map[ids.ID]map[ids.ID]map[*subscription]struct{}
+}
+
+type subscription struct {
+	notify chan struct{}
 }
 
 // Initialize the SharedMemory
@@ -47,6 +57,8 @@ func (m *Memory) Initialize(log logging.Logger, db database.Database) error {
 	m.codec = manager
 	m.locks = make(map[ids.ID]*rcLock)
 	m.db = db
+	m.checkers = make(map[ids.ID]AcceptanceChecker)
+	m.subscriptions = make(map[ids.ID]map[ids.ID]map[*subscription]struct{})
 	return nil
 }
 
@@ -58,6 +70,80 @@ func (m *Memory) NewSharedMemory(id ids.ID) SharedMemory {
 	}
 }
 
+// NewMessageBus returns a new MessageBus
+func (m *Memory) NewMessageBus(id ids.ID) MessageBus {
+	return &messageBus{
+		m:           m,
+		thisChainID: id,
+	}
+}
+
+// RegisterAcceptanceChecker registers the checker that verifies that the
+// messages sent from chain [id] were sent by accepted containers
+func (m *Memory) RegisterAcceptanceChecker(id ids.ID, checker AcceptanceChecker) {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	m.checkers[id] = checker
+}
+
+func (m *Memory) acceptanceChecker(id ids.ID) (AcceptanceChecker, bool) {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	checker, ok := m.checkers[id]
+	return checker, ok
+}
+
+func (m *Memory) subscribe(sourceChainID, destinationChainID ids.ID) (<-chan struct{}, func()) {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	sources, ok := m.subscriptions[destinationChainID]
+	if !ok {
+		sources = make(map[ids.ID]map[*subscription]struct{})
+		m.subscriptions[destinationChainID] = sources
+	}
+	subs, ok := sources[sourceChainID]
+	if !ok {
+		subs = make(map[*subscription]struct{})
+		sources[sourceChainID] = subs
+	}
+	sub := &subscription{notify: make(chan struct{}, 1)}
+	subs[sub] = struct{}{}
+
+	return sub.notify, func() { m.unsubscribe(sourceChainID, destinationChainID, sub) }
+}
+
+func (m *Memory) unsubscribe(sourceChainID, destinationChainID ids.ID, sub *subscription) {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	sources := m.subscriptions[destinationChainID]
+	subs := sources[sourceChainID]
+	delete(subs, sub)
+	if len(subs) == 0 {
+		delete(sources, sourceChainID)
+	}
+	if len(sources) == 0 {
+		delete(m.subscriptions, destinationChainID)
+	}
+}
+
+// notify the subscriptions to messages sent from [sourceChainID] to
+// [destinationChainID] that a message was sent
+func (m *Memory) notify(sourceChainID, destinationChainID ids.ID) {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	for sub := range m.subscriptions[destinationChainID][sourceChainID] {
+		select {
+		case sub.notify <- struct{}{}:
+		default:
+		}
+	}
+}
+
 // GetDatabase returns and locks the provided DB
 func (m *Memory) GetDatabase(sharedID ids.ID) (*versiondb.Database, database.Database) {
 	lock := m.makeLock(sharedID)
@@ -0,0 +1,115 @@
+// (c) 2019-2020, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package chains
+
+import (
+	"sync"
+
+	"github.com/ava-labs/avalanchego/ids"
+)
+
+// DependencyStatus describes the bootstrapping dependencies of a chain
+type DependencyStatus struct {
+	// True iff the chain has been created
+	Created bool
+	// True iff the chain has been created and is done bootstrapping
+	Bootstrapped bool
+	// IDs of the chains that must finish bootstrapping before this chain is
+	// created
+	WaitingOn []ids.ID
+}
+
+// dependencies keeps track of chains whose creation is blocked until other
+// chains have finished bootstrapping.
+type dependencies struct {
+	lock sync.Mutex
+
+	// IDs of the chains that have finished bootstrapping
+	bootstrapped ids.Set
+
+	// Chains that are waiting to be created, in the order they were blocked
+	blocked []ChainParameters
+
+	// Key: ID of a chain that is waiting to be created
+	// Value: IDs of the chains it is still waiting on
+	waitingOn map[ids.ID]ids.Set
+}
+
+// block marks [chainParams] as waiting on the chains in [deps]. Returns false
+// if all of [deps] have already finished bootstrapping, in which case the chain
+// can be created immediately.
+func (d *dependencies) block(chainParams ChainParameters, deps []ids.ID) bool {
+	d.lock.Lock()
+	defer d.lock.Unlock()
+
+	if _, isBlocked := d.waitingOn[chainParams.ID]; isBlocked {
+		return true
+	}
+
+	waitingOn := ids.Set{}
+	for _, dep := range deps {
+		if dep != chainParams.ID && !d.bootstrapped.Contains(dep) {
+			waitingOn.Add(dep)
+		}
+	}
+	if waitingOn.Len() == 0 {
+		return false
+	}
+
+	if d.waitingOn == nil {
+		d.waitingOn = make(map[ids.ID]ids.Set)
+	}
+	d.waitingOn[chainParams.ID] = waitingOn
+	d.blocked = append(d.blocked, chainParams)
+	return true
+}
+
+// markBootstrapped registers that [chainID] has finished bootstrapping and
+// returns the chains that are no longer waiting on any other chain. The
+// returned chains are in the order they were blocked.
+func (d *dependencies) markBootstrapped(chainID ids.ID) []ChainParameters {
+	d.lock.Lock()
+	defer d.lock.Unlock()
+
+	if d.bootstrapped.Contains(chainID) {
+		return nil
+	}
+	d.bootstrapped.Add(chainID)
+
+	unblocked := []ChainParameters(nil)
+	stillBlocked := d.blocked[:0]
+	for _, chainParams := range d.blocked {
+		waitingOn := d.waitingOn[chainParams.ID]
+		waitingOn.Remove(chainID)
+		if waitingOn.Len() > 0 {
+			stillBlocked = append(stillBlocked, chainParams)
+			continue
+		}
+		delete(d.waitingOn, chainParams.ID)
+		unblocked = append(unblocked, chainParams)
+	}
+	d.blocked = stillBlocked
+	return unblocked
+}
+
+// isBootstrapped returns true iff [chainID] has finished bootstrapping
+func (d *dependencies) isBootstrapped(chainID ids.ID) bool {
+	d.lock.Lock()
+	defer d.lock.Unlock()
+
+	return d.bootstrapped.Contains(chainID)
+}
+
+// blockedOn returns the IDs of the chains that [chainID] is waiting on. Returns
+// false if [chainID] isn't waiting to be created.
+func (d *dependencies) blockedOn(chainID ids.ID) ([]ids.ID, bool) {
+	d.lock.Lock()
+	defer d.lock.Unlock()
+
+	waitingOn, blocked := d.waitingOn[chainID]
+	if !blocked {
+		return nil, false
+	}
+	return waitingOn.List(), true
+}
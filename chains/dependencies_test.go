@@ -0,0 +1,48 @@
+// (c) 2019-2020, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package chains
+
+import (
+	"testing"
+
+	"github.com/ava-labs/avalanchego/ids"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDependencies(t *testing.T) {
+	assert := assert.New(t)
+	d := dependencies{}
+
+	pChainID := ids.GenerateTestID()
+	chainID0 := ids.GenerateTestID()
+	chainID1 := ids.GenerateTestID()
+	chainID2 := ids.GenerateTestID()
+
+	assert.True(d.block(ChainParameters{ID: chainID0}, []ids.ID{pChainID}), "A chain with an unbootstrapped dependency should be blocked")
+	assert.True(d.block(ChainParameters{ID: chainID1}, []ids.ID{pChainID, chainID0}), "A chain with unbootstrapped dependencies should be blocked")
+	assert.True(d.block(ChainParameters{ID: chainID2}, []ids.ID{pChainID}), "A chain with an unbootstrapped dependency should be blocked")
+
+	waitingOn, blocked := d.blockedOn(chainID1)
+	assert.True(blocked)
+	assert.Len(waitingOn, 2)
+
+	unblocked := d.markBootstrapped(pChainID)
+	assert.Len(unblocked, 2, "Chains only waiting on the bootstrapped chain should be unblocked")
+	assert.Equal(chainID0, unblocked[0].ID, "Unblocked chains should be returned in the order they were blocked")
+	assert.Equal(chainID2, unblocked[1].ID, "Unblocked chains should be returned in the order they were blocked")
+
+	waitingOn, blocked = d.blockedOn(chainID1)
+	assert.True(blocked)
+	assert.Equal([]ids.ID{chainID0}, waitingOn)
+
+	assert.Empty(d.markBootstrapped(pChainID), "Marking a chain as bootstrapped twice shouldn't unblock any chains")
+
+	unblocked = d.markBootstrapped(chainID0)
+	assert.Len(unblocked, 1)
+	assert.Equal(chainID1, unblocked[0].ID)
+
+	_, blocked = d.blockedOn(chainID1)
+	assert.False(blocked, "An unblocked chain shouldn't be waiting on any chains")
+	assert.False(d.block(ChainParameters{ID: ids.GenerateTestID()}, []ids.ID{pChainID, chainID0}), "A chain with only bootstrapped dependencies shouldn't be blocked")
+}
@@ -6,6 +6,8 @@ package chains
 import (
 	"errors"
 	"fmt"
+	"sort"
+	"strings"
 	"sync"
 	"time"
 
@@ -25,12 +27,15 @@ import (
 	"github.com/ava-labs/avalanchego/snow/engine/common"
 	"github.com/ava-labs/avalanchego/snow/engine/common/queue"
 	"github.com/ava-labs/avalanchego/snow/engine/snowman/block"
+	"github.com/ava-labs/avalanchego/snow/networking/benchlist"
 	"github.com/ava-labs/avalanchego/snow/networking/router"
 	"github.com/ava-labs/avalanchego/snow/networking/sender"
 	"github.com/ava-labs/avalanchego/snow/networking/timeout"
+	"github.com/ava-labs/avalanchego/snow/networking/tracker"
 	"github.com/ava-labs/avalanchego/snow/triggers"
 	"github.com/ava-labs/avalanchego/snow/validators"
 	"github.com/ava-labs/avalanchego/utils/constants"
+	"github.com/ava-labs/avalanchego/utils/hashing"
 	"github.com/ava-labs/avalanchego/utils/logging"
 	"github.com/ava-labs/avalanchego/vms"
 
@@ -41,12 +46,23 @@ import (
 	smcon "github.com/ava-labs/avalanchego/snow/consensus/snowman"
 	smeng "github.com/ava-labs/avalanchego/snow/engine/snowman"
 	smbootstrap "github.com/ava-labs/avalanchego/snow/engine/snowman/bootstrap"
+
+	dbmanager "github.com/ava-labs/avalanchego/database/manager"
 )
 
 const (
 	defaultChannelSize = 1024
 )
 
+var (
+	errUnknownChain       = errors.New("unknown chain ID")
+	errMixedHashFunctions = errors.New("chain was created with a different hash function")
+	errRolledBackDBs      = errors.New("chain databases were rolled back, so the chain isn't started")
+
+	hashFunctionPrefix = []byte("hash_function")
+	hashFunctionKey    = []byte("name")
+)
+
 // Manager manages the chains running on this node.
 // It can:
 //   * Create a chain
@@ -86,6 +102,28 @@ type Manager interface {
 	// Returns true iff the chain with the given ID exists and is finished bootstrapping
 	IsBootstrapped(ids.ID) bool
 
+	// Returns whether the chain with the given ID has been created, whether it
+	// is finished bootstrapping, and which chains it is waiting on before it
+	// can be created
+	DependencyStatus(ids.ID) DependencyStatus
+
+	// Compacts each of the databases of the chain with the given ID
+	CompactDB(ids.ID) error
+
+	// Drops the in-memory caches of the chain with the given ID
+	FlushCaches(ids.ID) error
+
+	// Returns the number of bytes stored in each of the databases of the chain
+	// with the given ID
+	DBSpaceUsage(ids.ID) (map[string]uint64, error)
+
+	// Stops the chain with the given ID from participating in consensus.
+	// Requests from peers are answered with empty responses.
+	DisableChain(ids.ID) error
+
+	// Resumes running consensus on the chain with the given ID
+	EnableChain(ids.ID) error
+
 	Shutdown()
 }
 
@@ -97,7 +135,17 @@ type ChainParameters struct {
 	VMAlias     string   // The ID of the vm this chain is running
 	FxAliases   []string // The IDs of the feature extensions this chain is running
 
+	// IDs of the chains that must finish bootstrapping before this chain is
+	// created. Chains created with CreateChain always wait on the Platform
+	// Chain, which provides the validator sets.
+	Dependencies []ids.ID
+
 	CustomBeacons validators.Set // Should only be set if the default beacons can't be used.
+
+	// Name of the hash function this chain uses. If empty, the chain's entry
+	// in ManagerConfig.ChainHashFunctions is used, and if it has none,
+	// hashing.Default is used. Must not change once the chain has been created.
+	HashFunction string
 }
 
 type chain struct {
@@ -107,6 +155,12 @@ type chain struct {
 	Ctx     *snow.Context
 	VM      interface{}
 	Beacons validators.Set
+	Sender  common.Sender
+
+	// Name of the database's prefix --> database
+	DBs map[string]database.Database
+	// Components of the chain whose caches can be flushed
+	CacheFlushers []common.CacheFlusher
 }
 
 // ManagerConfig ...
@@ -116,6 +170,7 @@ type ManagerConfig struct {
 	MaxNonStakerPendingMsgs   uint32
 	StakerMSGPortion          float64
 	StakerCPUPortion          float64
+	ParseCostTracker          tracker.CostTracker // Charges peers for the containers they send
 	Log                       logging.Logger
 	LogFactory                logging.Factory
 	VMManager                 vms.Manager // Manage mappings from vm ID --> vm
@@ -138,9 +193,40 @@ type ManagerConfig struct {
 	CriticalChains            ids.Set          // Chains that can't exit gracefully
 	WhitelistedSubnets        ids.Set          // Subnets to validate
 	TimeoutManager            *timeout.Manager // Manages request timeouts when sending messages to other validators
+	Benchlist                 benchlist.Manager
 	HealthService             health.Service
-	RetryBootstrap            bool // Should Bootstrap be retried
-	RetryBootstrapMaxAttempts int  // Max number of times to retry bootstrap
+	RetryBootstrap            bool             // Should Bootstrap be retried
+	RetryBootstrapMaxAttempts int              // Max number of times to retry bootstrap
+	JustifyChits              bool             // Should chits include the heights of the voted containers
+	GossipAcceptedTxs         bool             // Should the IDs of accepted txs be gossiped
+	MaxOutstandingGets        int              // Max number of outstanding vertex Get requests. 0 means no limit.
+	MaxOutstandingGetsPerPeer int              // Max number of outstanding vertex Get requests to a validator. 0 means no limit.
+	MaxQueuedGets             int              // Max number of queued vertex Get requests. 0 means the engine's default.
+	HeartbeatFrequency        time.Duration    // Time without a finished poll after which unfinalized containers are re-polled. 0 disables heartbeat polls.
+	StaleVertexDepth          uint64           // Number of heights the parents of a vertex may be behind the accepted frontier. 0 disables stale vertex detection.
+	RefuseStaleParents        bool             // Should stale vertices be excluded from the parents of new vertices
+	RandomSeed                []byte           // Seed that the randomness of each chain is derived from. If empty, chains use non-deterministic randomness.
+	VertexEdgeConfig          state.EdgeConfig // When the accepted frontier of avalanche chains is persisted
+	DiscardDBBackups          bool             // Should the backups made before migrating chain databases be removed
+	ChainRestartBudget        int              // Max number of times a chain is restarted after a fatal error. 0 disables restarts.
+
+	// Key: ID of a chain
+	// Value: IDs of the chains, in addition to the chain's
+	// ChainParameters.Dependencies, that must finish bootstrapping before the
+	// chain is created
+	ChainDependencies map[ids.ID][]ids.ID
+
+	// Key: ID of a chain
+	// Value: Name of the hash function the chain uses, if its ChainParameters
+	// don't name one
+	ChainHashFunctions map[ids.ID]string
+
+	// Key: ID of a chain
+	// Value: Names of the key spaces of the chain's databases to roll back to
+	// the schema version they had before they were last migrated. A chain with
+	// rolled back databases isn't started, so that the node can be downgraded
+	// to the version that ran it before.
+	DBRollbacks map[ids.ID][]string
 }
 
 type manager struct {
@@ -151,17 +237,22 @@ type manager struct {
 
 	registrants []Registrant // Those notified when a chain is created
 
-	unblocked     bool
-	blockedChains []ChainParameters
+	// Tracks the chains that are waiting on other chains to finish
+	// bootstrapping before they are created
+	dependencies dependencies
+
+	// Protects [subnets] and [chains]. Chains are created on the Platform
+	// Chain's thread, and on the thread of a chain that finished bootstrapping
+	// when chains were waiting on it.
+	chainsLock sync.Mutex
 
 	// Key: Subnet's ID
 	// Value: Subnet description
 	subnets map[ids.ID]Subnet
 
-	chainsLock sync.Mutex
 	// Key: Chain's ID
 	// Value: The chain
-	chains map[ids.ID]*router.Handler
+	chains map[ids.ID]*chain
 }
 
 // New returns a new Manager
@@ -169,7 +260,7 @@ func New(config *ManagerConfig) Manager {
 	m := &manager{
 		ManagerConfig: *config,
 		subnets:       make(map[ids.ID]Subnet),
-		chains:        make(map[ids.ID]*router.Handler),
+		chains:        make(map[ids.ID]*chain),
 	}
 	m.Initialize()
 	return m
@@ -178,17 +269,23 @@ func New(config *ManagerConfig) Manager {
 // Router that this chain manager is using to route consensus messages to chains
 func (m *manager) Router() router.Router { return m.ManagerConfig.Router }
 
-// Create a chain
-func (m *manager) CreateChain(chain ChainParameters) {
-	if !m.unblocked {
-		m.blockedChains = append(m.blockedChains, chain)
-	} else {
-		m.ForceCreateChain(chain)
+// Create a chain once all of its dependencies have finished bootstrapping
+func (m *manager) CreateChain(chainParams ChainParameters) {
+	chainParams.Dependencies = append(chainParams.Dependencies, m.ChainDependencies[chainParams.ID]...)
+	deps := make([]ids.ID, 0, len(chainParams.Dependencies)+1)
+	deps = append(deps, constants.PlatformChainID)
+	deps = append(deps, chainParams.Dependencies...)
+	if m.dependencies.block(chainParams, deps) {
+		m.Log.Info("delaying creation of chain %s until its dependencies have finished bootstrapping",
+			chainParams.ID)
+		return
 	}
+	m.ForceCreateChain(chainParams)
 }
 
 // Create a chain, this is only called from the P-chain thread, except for
-// creating the P-chain.
+// creating the P-chain and for creating the chains that were waiting on a chain
+// that finished bootstrapping, which happens on that chain's thread.
 func (m *manager) ForceCreateChain(chainParams ChainParameters) {
 	if !m.WhitelistedSubnets.Contains(chainParams.SubnetID) {
 		m.Log.Debug("Skipped creating non-whitelisted chain:\n"+
@@ -214,11 +311,14 @@ func (m *manager) ForceCreateChain(chainParams ChainParameters) {
 		chainParams.VMAlias,
 	)
 
+	m.chainsLock.Lock()
 	sb, exists := m.subnets[chainParams.SubnetID]
 	if !exists {
 		sb = &subnet{}
+		m.subnets[chainParams.SubnetID] = sb
 	}
 	sb.addChain(chainParams.ID)
+	m.chainsLock.Unlock()
 
 	chain, err := m.buildChain(chainParams, sb)
 	if err != nil {
@@ -228,12 +328,8 @@ func (m *manager) ForceCreateChain(chainParams ChainParameters) {
 		return
 	}
 
-	if !exists {
-		m.subnets[chainParams.SubnetID] = sb
-	}
-
 	m.chainsLock.Lock()
-	m.chains[chainParams.ID] = chain.Handler
+	m.chains[chainParams.ID] = chain
 	m.chainsLock.Unlock()
 
 	// Associate the newly created chain with its default alias
@@ -243,6 +339,41 @@ func (m *manager) ForceCreateChain(chainParams ChainParameters) {
 	m.notifyRegistrants(chain.Name, chain.Ctx, chain.VM)
 }
 
+// verifyHashFunction records the hash function of [chainID] the first time the
+// chain is created. Returns an error if the chain was previously created with a
+// different hash function, as its DAG would otherwise mix hashes.
+func (m *manager) verifyHashFunction(chainID ids.ID, hasher hashing.Hasher) error {
+	db := prefixdb.New(hashFunctionPrefix, prefixdb.New(chainID[:], m.DB))
+	name, err := db.Get(hashFunctionKey)
+	switch err {
+	case nil:
+		if string(name) != hasher.Name() {
+			return fmt.Errorf("%w: chain %s uses %s but %s was requested",
+				errMixedHashFunctions, chainID, name, hasher.Name())
+		}
+		return nil
+	case database.ErrNotFound:
+		return db.Put(hashFunctionKey, []byte(hasher.Name()))
+	default:
+		return fmt.Errorf("error while reading chain's hash function: %w", err)
+	}
+}
+
+// chainHasher returns the hash function of the chain described by
+// [chainParams]. It's the one named in [chainParams], or else the one
+// configured for the chain in ChainHashFunctions, or else hashing.Default.
+func (m *manager) chainHasher(chainParams ChainParameters) (hashing.Hasher, error) {
+	name := chainParams.HashFunction
+	if name == "" {
+		name = m.ChainHashFunctions[chainParams.ID]
+	}
+	hasher, err := hashing.GetHasher(name)
+	if err != nil {
+		return nil, fmt.Errorf("error while looking up hash function: %w", err)
+	}
+	return hasher, m.verifyHashFunction(chainParams.ID, hasher)
+}
+
 // Create a chain
 func (m *manager) buildChain(chainParams ChainParameters, sb Subnet) (*chain, error) {
 	vmID, err := m.VMManager.Lookup(chainParams.VMAlias)
@@ -255,6 +386,11 @@ func (m *manager) buildChain(chainParams ChainParameters, sb Subnet) (*chain, er
 		primaryAlias = chainParams.ID.String()
 	}
 
+	hasher, err := m.chainHasher(chainParams)
+	if err != nil {
+		return nil, err
+	}
+
 	// Create the log and context of the chain
 	chainLog, err := m.LogFactory.MakeChain(primaryAlias, "")
 	if err != nil {
@@ -273,12 +409,17 @@ func (m *manager) buildChain(chainParams ChainParameters, sb Subnet) (*chain, er
 		ConsensusDispatcher:  m.ConsensusEvents,
 		Keystore:             m.Keystore.NewBlockchainKeyStore(chainParams.ID),
 		SharedMemory:         m.AtomicMemory.NewSharedMemory(chainParams.ID),
+		MessageBus:           m.AtomicMemory.NewMessageBus(chainParams.ID),
 		BCLookup:             m,
 		SNLookup:             m,
 		Namespace:            fmt.Sprintf("%s_%s_vm", constants.PlatformName, primaryAlias),
 		Metrics:              m.ConsensusParams.Metrics,
 		EpochFirstTransition: m.EpochFirstTransition,
 		EpochDuration:        m.EpochDuration,
+		Hasher:               hasher,
+	}
+	if len(m.RandomSeed) > 0 {
+		ctx.Random = snow.NewRandom(chainParams.ID, m.RandomSeed)
 	}
 
 	// Get a factory for the vm we want to use on our chain
@@ -377,6 +518,11 @@ func (m *manager) buildChain(chainParams ChainParameters, sb Subnet) (*chain, er
 		return nil, fmt.Errorf("the vm should have type avalanche.DAGVM or snowman.ChainVM. Chain not created")
 	}
 
+	// Allows the messages this chain sends to be verified by other chains
+	if checker, ok := vm.(atomic.AcceptanceChecker); ok {
+		m.AtomicMemory.RegisterAcceptanceChecker(chainParams.ID, checker)
+	}
+
 	// Register the chain with the timeout manager
 	if err := m.TimeoutManager.RegisterChain(ctx, consensusParams.Namespace); err != nil {
 		return nil, err
@@ -399,11 +545,10 @@ func (m *manager) buildChain(chainParams ChainParameters, sb Subnet) (*chain, er
 // Implements Manager.AddRegistrant
 func (m *manager) AddRegistrant(r Registrant) { m.registrants = append(m.registrants, r) }
 
-func (m *manager) unblockChains() {
-	m.unblocked = true
-	blocked := m.blockedChains
-	m.blockedChains = nil
-	for _, chainParams := range blocked {
+// chainBootstrapped is called when the chain with ID [chainID] has finished
+// bootstrapping. Creates the chains that were only waiting on [chainID].
+func (m *manager) chainBootstrapped(chainID ids.ID) {
+	for _, chainParams := range m.dependencies.markBootstrapped(chainID) {
 		m.ForceCreateChain(chainParams)
 	}
 }
@@ -432,13 +577,15 @@ func (m *manager) createAvalancheChain(
 	vertexDB := prefixdb.New([]byte("vertex"), db)
 	vertexBootstrappingDB := prefixdb.New([]byte("vertex_bs"), db)
 	txBootstrappingDB := prefixdb.New([]byte("tx_bs"), db)
-
-	vtxBlocker, err := queue.New(vertexBootstrappingDB)
-	if err != nil {
-		return nil, err
+	vertexProcessingDB := prefixdb.New([]byte("vertex_processing"), db)
+	dbs := map[string]database.Database{
+		"vm":                vmDB,
+		"vertex":            vertexDB,
+		"vertex_bs":         vertexBootstrappingDB,
+		"tx_bs":             txBootstrappingDB,
+		"vertex_processing": vertexProcessingDB,
 	}
-	txBlocker, err := queue.New(txBootstrappingDB)
-	if err != nil {
+	if err := m.migrateDBs(ctx, db, dbs, vm); err != nil {
 		return nil, err
 	}
 
@@ -452,7 +599,9 @@ func (m *manager) createAvalancheChain(
 
 	// Handles serialization/deserialization of vertices and also the
 	// persistence of vertices
-	vtxManager := &state.Serializer{}
+	vtxManager := &state.Serializer{
+		EdgeConfig: m.VertexEdgeConfig,
+	}
 	vtxManager.Initialize(ctx, vm, vertexDB)
 
 	// Passes messages from the consensus engine to the network
@@ -469,51 +618,92 @@ func (m *manager) createAvalancheChain(
 
 	delay := &router.Delay{}
 
-	// The engine handles consensus
-	engine := &aveng.Transitive{}
-	if err := engine.Initialize(aveng.Config{
-		Config: avbootstrap.Config{
-			Config: common.Config{
-				Ctx:                       ctx,
-				Validators:                validators,
-				Beacons:                   beacons,
-				SampleK:                   sampleK,
-				StartupAlpha:              (3*bootstrapWeight + 3) / 4,
-				Alpha:                     bootstrapWeight/2 + 1, // must be > 50%
-				Sender:                    &sender,
-				Subnet:                    sb,
-				Delay:                     delay,
-				RetryBootstrap:            m.RetryBootstrap,
-				RetryBootstrapMaxAttempts: m.RetryBootstrapMaxAttempts,
+	// newEngine returns an initialized engine, which starts by bootstrapping
+	// the chain. A restarted engine replaces the metrics of the engine before
+	// it, and doesn't wait for the beacons to connect, as they already have.
+	newEngine := func(restarted bool) (common.Engine, error) {
+		params := consensusParams
+		startupAlpha := (3*bootstrapWeight + 3) / 4
+		if restarted {
+			params.Metrics = replacingRegisterer{params.Metrics}
+			startupAlpha = 0
+		}
+
+		vtxBlocker, err := queue.New(vertexBootstrappingDB)
+		if err != nil {
+			return nil, err
+		}
+		txBlocker, err := queue.New(txBootstrappingDB)
+		if err != nil {
+			return nil, err
+		}
+
+		// The engine handles consensus
+		engine := &aveng.Transitive{}
+		if err := engine.Initialize(aveng.Config{
+			Config: avbootstrap.Config{
+				Config: common.Config{
+					Ctx:                       ctx,
+					Validators:                validators,
+					Beacons:                   beacons,
+					SampleK:                   sampleK,
+					StartupAlpha:              startupAlpha,
+					Alpha:                     bootstrapWeight/2 + 1, // must be > 50%
+					Sender:                    &sender,
+					Subnet:                    sb,
+					Delay:                     delay,
+					RetryBootstrap:            m.RetryBootstrap,
+					RetryBootstrapMaxAttempts: m.RetryBootstrapMaxAttempts,
+					JustifyChits:              m.JustifyChits,
+					GossipAcceptedTxs:         m.GossipAcceptedTxs,
+					MaxOutstandingGets:        m.MaxOutstandingGets,
+					MaxOutstandingGetsPerPeer: m.MaxOutstandingGetsPerPeer,
+					MaxQueuedGets:             m.MaxQueuedGets,
+					HeartbeatFrequency:        m.HeartbeatFrequency,
+					StaleVertexDepth:          m.StaleVertexDepth,
+					RefuseStaleParents:        m.RefuseStaleParents,
+					Benchlist:                 m.Benchlist,
+				},
+				VtxBlocked: vtxBlocker,
+				TxBlocked:  txBlocker,
+				Manager:    vtxManager,
+				VM:         vm,
+				Bootstrapped: func() {
+					m.chainBootstrapped(ctx.ChainID)
+				},
 			},
-			VtxBlocked: vtxBlocker,
-			TxBlocked:  txBlocker,
-			Manager:    vtxManager,
-			VM:         vm,
-		},
-		Params:    consensusParams,
-		Consensus: &avcon.Topological{},
-	}); err != nil {
-		return nil, fmt.Errorf("error initializing avalanche engine: %w", err)
+			Params:       params,
+			Consensus:    &avcon.Topological{},
+			ProcessingDB: vertexProcessingDB,
+		}); err != nil {
+			return nil, fmt.Errorf("error initializing avalanche engine: %w", err)
+		}
+		return engine, nil
 	}
+	engine, err := newEngine(false)
+	if err != nil {
+		return nil, err
+	}
+
+	// Asynchronously passes messages from the network to the consensus engine
+	handler := &router.Handler{}
 
 	// Register health check for this chain
 	chainAlias, err := m.PrimaryAlias(ctx.ChainID)
 	if err != nil {
 		chainAlias = ctx.ChainID.String()
 	}
-	// Grab the context lock before calling the chain's health check
+	// Grab the context lock before calling the chain's health check. The
+	// engine may have been restarted, so the handler's engine is checked.
 	checkFn := func() (interface{}, error) {
 		ctx.Lock.Lock()
 		defer ctx.Lock.Unlock()
-		return engine.HealthCheck()
+		return handler.Engine().HealthCheck()
 	}
 	if err := m.HealthService.RegisterCheck(chainAlias, checkFn); err != nil {
 		return nil, fmt.Errorf("couldn't add health check for chain %s: %w", chainAlias, err)
 	}
 
-	// Asynchronously passes messages from the network to the consensus engine
-	handler := &router.Handler{}
 	err = handler.Initialize(
 		engine,
 		validators,
@@ -522,18 +712,24 @@ func (m *manager) createAvalancheChain(
 		m.MaxNonStakerPendingMsgs,
 		m.StakerMSGPortion,
 		m.StakerCPUPortion,
+		m.ParseCostTracker,
 		fmt.Sprintf("%s_handler", consensusParams.Namespace),
 		consensusParams.Metrics,
 		delay,
 	)
 
-	return &chain{
-		Name:    chainAlias,
-		Engine:  engine,
-		Handler: handler,
-		VM:      vm,
-		Ctx:     ctx,
-	}, err
+	c := &chain{
+		Name:          chainAlias,
+		Engine:        engine,
+		Handler:       handler,
+		VM:            vm,
+		Ctx:           ctx,
+		Sender:        &sender,
+		DBs:           dbs,
+		CacheFlushers: cacheFlushers(vtxManager, vm),
+	}
+	m.supervise(c, newEngine)
+	return c, err
 }
 
 // Create a linear chain using the Snowman consensus engine
@@ -558,9 +754,11 @@ func (m *manager) createSnowmanChain(
 	db := prefixdb.New(ctx.ChainID[:], metricsDB)
 	vmDB := prefixdb.New([]byte("vm"), db)
 	bootstrappingDB := prefixdb.New([]byte("bs"), db)
-
-	blocked, err := queue.New(bootstrappingDB)
-	if err != nil {
+	dbs := map[string]database.Database{
+		"vm": vmDB,
+		"bs": bootstrappingDB,
+	}
+	if err := m.migrateDBs(ctx, db, dbs, vm); err != nil {
 		return nil, err
 	}
 
@@ -594,31 +792,56 @@ func (m *manager) createSnowmanChain(
 
 	delay := &router.Delay{}
 
-	// The engine handles consensus
-	engine := &smeng.Transitive{}
-	if err := engine.Initialize(smeng.Config{
-		Config: smbootstrap.Config{
-			Config: common.Config{
-				Ctx:                       ctx,
-				Validators:                validators,
-				Beacons:                   beacons,
-				SampleK:                   sampleK,
-				StartupAlpha:              (3*bootstrapWeight + 3) / 4,
-				Alpha:                     bootstrapWeight/2 + 1, // must be > 50%
-				Sender:                    &sender,
-				Subnet:                    sb,
-				Delay:                     delay,
-				RetryBootstrap:            m.RetryBootstrap,
-				RetryBootstrapMaxAttempts: m.RetryBootstrapMaxAttempts,
+	// newEngine returns an initialized engine, which starts by bootstrapping
+	// the chain. A restarted engine replaces the metrics of the engine before
+	// it, and doesn't wait for the beacons to connect, as they already have.
+	newEngine := func(restarted bool) (common.Engine, error) {
+		params := consensusParams
+		startupAlpha := (3*bootstrapWeight + 3) / 4
+		if restarted {
+			params.Metrics = replacingRegisterer{params.Metrics}
+			startupAlpha = 0
+		}
+
+		blocked, err := queue.New(bootstrappingDB)
+		if err != nil {
+			return nil, err
+		}
+
+		// The engine handles consensus
+		engine := &smeng.Transitive{}
+		if err := engine.Initialize(smeng.Config{
+			Config: smbootstrap.Config{
+				Config: common.Config{
+					Ctx:                       ctx,
+					Validators:                validators,
+					Beacons:                   beacons,
+					SampleK:                   sampleK,
+					StartupAlpha:              startupAlpha,
+					Alpha:                     bootstrapWeight/2 + 1, // must be > 50%
+					Sender:                    &sender,
+					Subnet:                    sb,
+					Delay:                     delay,
+					RetryBootstrap:            m.RetryBootstrap,
+					RetryBootstrapMaxAttempts: m.RetryBootstrapMaxAttempts,
+					JustifyChits:              m.JustifyChits,
+				},
+				Blocked: blocked,
+				VM:      vm,
+				Bootstrapped: func() {
+					m.chainBootstrapped(ctx.ChainID)
+				},
 			},
-			Blocked:      blocked,
-			VM:           vm,
-			Bootstrapped: m.unblockChains,
-		},
-		Params:    consensusParams,
-		Consensus: &smcon.Topological{},
-	}); err != nil {
-		return nil, fmt.Errorf("error initializing snowman engine: %w", err)
+			Params:    params,
+			Consensus: &smcon.Topological{},
+		}); err != nil {
+			return nil, fmt.Errorf("error initializing snowman engine: %w", err)
+		}
+		return engine, nil
+	}
+	engine, err := newEngine(false)
+	if err != nil {
+		return nil, err
 	}
 
 	// Asynchronously passes messages from the network to the consensus engine
@@ -631,6 +854,7 @@ func (m *manager) createSnowmanChain(
 		m.MaxNonStakerPendingMsgs,
 		m.StakerMSGPortion,
 		m.StakerCPUPortion,
+		m.ParseCostTracker,
 		fmt.Sprintf("%s_handler", consensusParams.Namespace),
 		consensusParams.Metrics,
 		delay,
@@ -648,19 +872,24 @@ func (m *manager) createSnowmanChain(
 	checkFn := func() (interface{}, error) {
 		ctx.Lock.Lock()
 		defer ctx.Lock.Unlock()
-		return engine.HealthCheck()
+		return handler.Engine().HealthCheck()
 	}
 	if err := m.HealthService.RegisterCheck(chainAlias, checkFn); err != nil {
 		return nil, fmt.Errorf("couldn't add health check for chain %s: %w", chainAlias, err)
 	}
 
-	return &chain{
-		Name:    chainAlias,
-		Engine:  engine,
-		Handler: handler,
-		VM:      vm,
-		Ctx:     ctx,
-	}, nil
+	c := &chain{
+		Name:          chainAlias,
+		Engine:        engine,
+		Handler:       handler,
+		VM:            vm,
+		Ctx:           ctx,
+		Sender:        &sender,
+		DBs:           dbs,
+		CacheFlushers: cacheFlushers(vm),
+	}
+	m.supervise(c, newEngine)
+	return c, nil
 }
 
 func (m *manager) SubnetID(chainID ids.ID) (ids.ID, error) {
@@ -669,9 +898,9 @@ func (m *manager) SubnetID(chainID ids.ID) (ids.ID, error) {
 
 	chain, exists := m.chains[chainID]
 	if !exists {
-		return ids.ID{}, errors.New("unknown chain ID")
+		return ids.ID{}, errUnknownChain
 	}
-	return chain.Context().SubnetID, nil
+	return chain.Ctx.SubnetID, nil
 }
 
 func (m *manager) IsBootstrapped(id ids.ID) bool {
@@ -682,7 +911,191 @@ func (m *manager) IsBootstrapped(id ids.ID) bool {
 		return false
 	}
 
-	return chain.Engine().IsBootstrapped()
+	return chain.Engine.IsBootstrapped()
+}
+
+func (m *manager) DependencyStatus(id ids.ID) DependencyStatus {
+	if waitingOn, blocked := m.dependencies.blockedOn(id); blocked {
+		return DependencyStatus{WaitingOn: waitingOn}
+	}
+
+	m.chainsLock.Lock()
+	_, created := m.chains[id]
+	m.chainsLock.Unlock()
+	return DependencyStatus{
+		Created:      created,
+		Bootstrapped: created && m.dependencies.isBootstrapped(id),
+	}
+}
+
+// CompactDB compacts each of the databases of the chain with ID [id]
+func (m *manager) CompactDB(id ids.ID) error {
+	chain, err := m.getChain(id)
+	if err != nil {
+		return err
+	}
+	for name, db := range chain.DBs {
+		if err := db.Compact(nil, nil); err != nil {
+			return fmt.Errorf("couldn't compact %s database: %w", name, err)
+		}
+	}
+	return nil
+}
+
+// FlushCaches drops the in-memory caches of the chain with ID [id]
+func (m *manager) FlushCaches(id ids.ID) error {
+	chain, err := m.getChain(id)
+	if err != nil {
+		return err
+	}
+
+	chain.Ctx.Lock.Lock()
+	defer chain.Ctx.Lock.Unlock()
+
+	for _, flusher := range chain.CacheFlushers {
+		flusher.FlushCaches()
+	}
+	return nil
+}
+
+// DBSpaceUsage returns the number of key and value bytes stored in each of the
+// databases of the chain with ID [id]. This iterates over every key, so it
+// should only be used for maintenance.
+func (m *manager) DBSpaceUsage(id ids.ID) (map[string]uint64, error) {
+	chain, err := m.getChain(id)
+	if err != nil {
+		return nil, err
+	}
+
+	usage := make(map[string]uint64, len(chain.DBs))
+	for name, db := range chain.DBs {
+		size := uint64(0)
+		iter := db.NewIterator()
+		for iter.Next() {
+			size += uint64(len(iter.Key()) + len(iter.Value()))
+		}
+		err := iter.Error()
+		iter.Release()
+		if err != nil {
+			return nil, fmt.Errorf("couldn't iterate over %s database: %w", name, err)
+		}
+		usage[name] = size
+	}
+	return usage, nil
+}
+
+// DisableChain replaces the engine of the chain with ID [id] with an engine
+// that doesn't participate in consensus
+func (m *manager) DisableChain(id ids.ID) error {
+	chain, err := m.getChain(id)
+	if err != nil {
+		return err
+	}
+
+	chain.Ctx.Lock.Lock()
+	defer chain.Ctx.Lock.Unlock()
+
+	if _, disabled := chain.Handler.Engine().(*common.DisabledEngine); disabled {
+		return nil
+	}
+	chain.Handler.SetEngine(&common.DisabledEngine{
+		Engine: chain.Engine,
+		Sender: chain.Sender,
+	})
+	m.Log.Info("disabled chain %s", id)
+	return nil
+}
+
+// EnableChain restores the engine of the chain with ID [id]. The requests that
+// the engine sent before the chain was disabled, and that were answered while
+// it was disabled, are reported to the engine as failed.
+func (m *manager) EnableChain(id ids.ID) error {
+	chain, err := m.getChain(id)
+	if err != nil {
+		return err
+	}
+
+	chain.Ctx.Lock.Lock()
+	defer chain.Ctx.Lock.Unlock()
+
+	disabledEngine, disabled := chain.Handler.Engine().(*common.DisabledEngine)
+	if !disabled {
+		return nil
+	}
+	chain.Handler.SetEngine(chain.Engine)
+	if err := disabledEngine.Enable(); err != nil {
+		return fmt.Errorf("couldn't report the requests answered while chain %s was disabled: %w", id, err)
+	}
+	m.Log.Info("enabled chain %s", id)
+	return nil
+}
+
+func (m *manager) getChain(id ids.ID) (*chain, error) {
+	m.chainsLock.Lock()
+	defer m.chainsLock.Unlock()
+
+	chain, exists := m.chains[id]
+	if !exists {
+		return nil, errUnknownChain
+	}
+	return chain, nil
+}
+
+// cacheFlushers returns the [components] that are able to flush their caches
+func cacheFlushers(components ...interface{}) []common.CacheFlusher {
+	flushers := []common.CacheFlusher(nil)
+	for _, component := range components {
+		if flusher, ok := component.(common.CacheFlusher); ok {
+			flushers = append(flushers, flusher)
+		}
+	}
+	return flushers
+}
+
+// migrateDBs migrates each of a chain's databases, which are in [db], to its
+// latest schema version. The migrations of the VM's database are provided by
+// [vm], if it implements dbmanager.Migrator. If any of the chain's databases
+// are to be rolled back, they're rolled back instead and an error is returned,
+// as the chain can't run on the schema versions they're rolled back to.
+func (m *manager) migrateDBs(ctx *snow.Context, db database.Database, dbs map[string]database.Database, vm interface{}) error {
+	vmMigrations := []dbmanager.Migration(nil)
+	if migrator, ok := vm.(dbmanager.Migrator); ok {
+		vmMigrations = migrator.Migrations()
+	}
+
+	names := make([]string, 0, len(dbs))
+	for name := range dbs {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	schemas := dbmanager.New(db, ctx.Log)
+	for _, name := range names {
+		migrations := []dbmanager.Migration(nil)
+		if name == "vm" {
+			migrations = vmMigrations
+		}
+		if err := schemas.Register(name, dbs[name], migrations...); err != nil {
+			return err
+		}
+	}
+	if rollbacks := m.DBRollbacks[ctx.ChainID]; len(rollbacks) > 0 {
+		for _, name := range rollbacks {
+			if err := schemas.Rollback(name); err != nil {
+				return fmt.Errorf("couldn't roll back chain database %s: %w", name, err)
+			}
+		}
+		return fmt.Errorf("%w: rolled back %s", errRolledBackDBs, strings.Join(rollbacks, ", "))
+	}
+	if err := schemas.Migrate(); err != nil {
+		return fmt.Errorf("couldn't migrate chain databases: %w", err)
+	}
+	if m.DiscardDBBackups {
+		if err := schemas.DiscardBackups(); err != nil {
+			return fmt.Errorf("couldn't discard chain database backups: %w", err)
+		}
+	}
+	return nil
 }
 
 // Shutdown stops all the chains
@@ -0,0 +1,179 @@
+// (c) 2019-2020, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package chains
+
+import (
+	"errors"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/ava-labs/avalanchego/database"
+	"github.com/ava-labs/avalanchego/database/memdb"
+	"github.com/ava-labs/avalanchego/database/prefixdb"
+	"github.com/ava-labs/avalanchego/ids"
+	"github.com/ava-labs/avalanchego/snow"
+	"github.com/ava-labs/avalanchego/utils/constants"
+	"github.com/ava-labs/avalanchego/utils/hashing"
+	"github.com/ava-labs/avalanchego/utils/logging"
+	"github.com/ava-labs/avalanchego/vms"
+
+	dbmanager "github.com/ava-labs/avalanchego/database/manager"
+)
+
+func TestVerifyHashFunction(t *testing.T) {
+	m := New(&ManagerConfig{DB: memdb.New()}).(*manager)
+
+	chainID := ids.GenerateTestID()
+	otherChainID := ids.GenerateTestID()
+
+	assert.NoError(t, m.verifyHashFunction(chainID, hashing.SHA3))
+	assert.NoError(t, m.verifyHashFunction(chainID, hashing.SHA3))
+
+	// Recreating the chain with a different hash function would mix hashes in
+	// its DAG
+	err := m.verifyHashFunction(chainID, hashing.Default)
+	assert.True(t, errors.Is(err, errMixedHashFunctions))
+
+	// Other chains are free to use a different hash function
+	assert.NoError(t, m.verifyHashFunction(otherChainID, hashing.Default))
+}
+
+func TestChainHasher(t *testing.T) {
+	configuredChainID := ids.GenerateTestID()
+	m := New(&ManagerConfig{
+		DB: memdb.New(),
+		ChainHashFunctions: map[ids.ID]string{
+			configuredChainID: hashing.SHA3Name,
+		},
+	}).(*manager)
+
+	// The configured hash function is used when the chain doesn't name one
+	hasher, err := m.chainHasher(ChainParameters{ID: configuredChainID})
+	assert.NoError(t, err)
+	assert.Equal(t, hashing.SHA3, hasher)
+
+	hasher, err = m.chainHasher(ChainParameters{ID: ids.GenerateTestID()})
+	assert.NoError(t, err)
+	assert.Equal(t, hashing.Default, hasher)
+
+	namedChainID := ids.GenerateTestID()
+	hasher, err = m.chainHasher(ChainParameters{
+		ID:           namedChainID,
+		HashFunction: hashing.SHA3Name,
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, hashing.SHA3, hasher)
+
+	// The chain's hash function can't change once it has been created
+	_, err = m.chainHasher(ChainParameters{ID: namedChainID})
+	assert.True(t, errors.Is(err, errMixedHashFunctions))
+}
+
+func TestCreateChainWaitsOnConfiguredDependencies(t *testing.T) {
+	assert := assert.New(t)
+
+	xChainID := ids.GenerateTestID()
+	cChainID := ids.GenerateTestID()
+	m := New(&ManagerConfig{
+		Log: logging.NoLog{},
+		DB:  memdb.New(),
+		ChainDependencies: map[ids.ID][]ids.ID{
+			cChainID: {xChainID},
+		},
+	}).(*manager)
+
+	m.CreateChain(ChainParameters{ID: cChainID})
+	status := m.DependencyStatus(cChainID)
+	assert.False(status.Created)
+	assert.ElementsMatch([]ids.ID{constants.PlatformChainID, xChainID}, status.WaitingOn)
+
+	// The chain keeps waiting on the configured dependency once the Platform
+	// Chain has bootstrapped
+	assert.Empty(m.dependencies.markBootstrapped(constants.PlatformChainID))
+	status = m.DependencyStatus(cChainID)
+	assert.Equal([]ids.ID{xChainID}, status.WaitingOn)
+
+	unblocked := m.dependencies.markBootstrapped(xChainID)
+	assert.Len(unblocked, 1)
+	assert.Equal([]ids.ID{xChainID}, unblocked[0].Dependencies)
+}
+
+func TestConcurrentForceCreateChain(t *testing.T) {
+	subnetID := ids.GenerateTestID()
+	whitelistedSubnets := ids.Set{}
+	whitelistedSubnets.Add(subnetID)
+	m := New(&ManagerConfig{
+		Log:                logging.NoLog{},
+		DB:                 memdb.New(),
+		VMManager:          vms.NewManager(nil, logging.NoLog{}),
+		WhitelistedSubnets: whitelistedSubnets,
+	}).(*manager)
+
+	// Chains that were waiting on a chain are created on that chain's thread,
+	// concurrently with the chains created on the Platform Chain's thread
+	wg := sync.WaitGroup{}
+	for i := 0; i < 8; i++ {
+		chainID := ids.GenerateTestID()
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			m.ForceCreateChain(ChainParameters{
+				ID:       chainID,
+				SubnetID: subnetID,
+				VMAlias:  "unknown",
+			})
+		}()
+	}
+	wg.Wait()
+
+	m.chainsLock.Lock()
+	defer m.chainsLock.Unlock()
+	assert.Len(t, m.subnets, 1)
+	assert.True(t, m.subnets[subnetID].IsBootstrapped(), "chains that failed to be created shouldn't be bootstrapping")
+}
+
+// migratingVM is a VM whose database has migrations
+type migratingVM struct{ migrations []dbmanager.Migration }
+
+func (vm migratingVM) Migrations() []dbmanager.Migration { return vm.migrations }
+
+func TestMigrateDBsRollsBack(t *testing.T) {
+	assert := assert.New(t)
+
+	ctx := snow.DefaultContextTest()
+	db := memdb.New()
+	vmDB := prefixdb.New([]byte("vm"), db)
+	dbs := map[string]database.Database{"vm": vmDB}
+	key := []byte("key")
+	assert.NoError(vmDB.Put(key, []byte("old")))
+
+	vm := migratingVM{migrations: []dbmanager.Migration{
+		func(db database.Database) error { return db.Put(key, []byte("new")) },
+	}}
+	m := New(&ManagerConfig{DB: memdb.New()}).(*manager)
+	assert.NoError(m.migrateDBs(ctx, db, dbs, vm))
+	value, err := vmDB.Get(key)
+	assert.NoError(err)
+	assert.Equal([]byte("new"), value)
+
+	// Once the VM's database is rolled back, the chain can't be started
+	m = New(&ManagerConfig{
+		DB: memdb.New(),
+		DBRollbacks: map[ids.ID][]string{
+			ctx.ChainID: {"vm"},
+		},
+	}).(*manager)
+	err = m.migrateDBs(ctx, db, dbs, vm)
+	assert.True(errors.Is(err, errRolledBackDBs))
+	value, err = vmDB.Get(key)
+	assert.NoError(err)
+	assert.Equal([]byte("old"), value)
+
+	// There's nothing left to roll back to
+	err = m.migrateDBs(ctx, db, dbs, vm)
+	assert.Error(err)
+	assert.False(errors.Is(err, errRolledBackDBs))
+}
@@ -19,6 +19,14 @@ func (mm MockManager) Shutdown()                        {}
 func (mm MockManager) SubnetID(ids.ID) (ids.ID, error)  { return ids.ID{}, nil }
 func (mm MockManager) IsBootstrapped(ids.ID) bool       { return false }
 
+func (mm MockManager) DependencyStatus(ids.ID) DependencyStatus { return DependencyStatus{} }
+
+func (mm MockManager) CompactDB(ids.ID) error                         { return nil }
+func (mm MockManager) FlushCaches(ids.ID) error                       { return nil }
+func (mm MockManager) DBSpaceUsage(ids.ID) (map[string]uint64, error) { return nil, nil }
+func (mm MockManager) DisableChain(ids.ID) error                      { return nil }
+func (mm MockManager) EnableChain(ids.ID) error                       { return nil }
+
 func (mm MockManager) Lookup(s string) (ids.ID, error) {
 	id, err := ids.FromString(s)
 	if err == nil {
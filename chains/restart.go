@@ -0,0 +1,55 @@
+// (c) 2019-2020, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package chains
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/ava-labs/avalanchego/snow/engine/common"
+)
+
+// replacingRegisterer registers collectors in place of the collectors that
+// were already registered with the same descriptors. This allows an engine
+// restarted after a fatal error to report its own metrics, rather than failing
+// to initialize.
+type replacingRegisterer struct{ prometheus.Registerer }
+
+func (r replacingRegisterer) Register(c prometheus.Collector) error {
+	err := r.Registerer.Register(c)
+	existing, ok := err.(prometheus.AlreadyRegisteredError)
+	if !ok {
+		return err
+	}
+	r.Registerer.Unregister(existing.ExistingCollector)
+	return r.Registerer.Register(c)
+}
+
+func (r replacingRegisterer) MustRegister(cs ...prometheus.Collector) {
+	for _, c := range cs {
+		if err := r.Register(c); err != nil {
+			panic(err)
+		}
+	}
+}
+
+// supervise allows the engine of [c] to be replaced by one returned by
+// [newEngine] when it hits a fatal error, up to [ChainRestartBudget] times.
+// The new engine keeps the chain's VM, which isn't shut down, and re-runs
+// bootstrapping, so the chain is marked as bootstrapping until it's done.
+func (m *manager) supervise(c *chain, newEngine func(restarted bool) (common.Engine, error)) {
+	if m.ChainRestartBudget <= 0 {
+		return
+	}
+	c.Handler.SetRestarter(func() (common.Engine, error) {
+		c.Ctx.Unbootstrapped()
+		engine, err := newEngine(true)
+		if err != nil {
+			return nil, err
+		}
+		m.chainsLock.Lock()
+		c.Engine = engine
+		m.chainsLock.Unlock()
+		return engine, nil
+	}, m.ChainRestartBudget)
+}
@@ -0,0 +1,174 @@
+// (c) 2019-2020, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package chains
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/ava-labs/avalanchego/database/memdb"
+	"github.com/ava-labs/avalanchego/ids"
+	"github.com/ava-labs/avalanchego/snow"
+	"github.com/ava-labs/avalanchego/snow/consensus/snowball"
+	"github.com/ava-labs/avalanchego/snow/engine/common"
+	"github.com/ava-labs/avalanchego/snow/engine/common/queue"
+	"github.com/ava-labs/avalanchego/snow/networking/router"
+	"github.com/ava-labs/avalanchego/snow/validators"
+	"github.com/ava-labs/avalanchego/vms/timestampvm"
+
+	smcon "github.com/ava-labs/avalanchego/snow/consensus/snowman"
+	smeng "github.com/ava-labs/avalanchego/snow/engine/snowman"
+	smbootstrap "github.com/ava-labs/avalanchego/snow/engine/snowman/bootstrap"
+)
+
+func TestReplacingRegisterer(t *testing.T) {
+	assert := assert.New(t)
+
+	registry := prometheus.NewRegistry()
+	opts := prometheus.CounterOpts{Name: "restarted"}
+	old := prometheus.NewCounter(opts)
+	assert.NoError(registry.Register(old))
+	assert.Error(registry.Register(prometheus.NewCounter(opts)))
+
+	// The new counter is reported in place of the old one
+	replacement := prometheus.NewCounter(opts)
+	replacement.Inc()
+	assert.NoError(replacingRegisterer{registry}.Register(replacement))
+	families, err := registry.Gather()
+	assert.NoError(err)
+	assert.Len(families, 1)
+	assert.Len(families[0].GetMetric(), 1)
+	assert.Equal(1.0, families[0].GetMetric()[0].GetCounter().GetValue())
+}
+
+// failingEngine fails to gossip, which is a fatal error
+type failingEngine struct{ common.Engine }
+
+func (failingEngine) Gossip() error { return errors.New("fatal engine error") }
+
+func TestRestartedChainKeepsVM(t *testing.T) {
+	assert := assert.New(t)
+
+	ctx := snow.DefaultContextTest()
+	vm := &timestampvm.VM{}
+	msgChan := make(chan common.Message, 1)
+	assert.NoError(vm.Initialize(ctx, memdb.New(), []byte{0, 0, 0, 0, 0}, msgChan, nil))
+
+	vdrs := validators.NewSet()
+	assert.NoError(vdrs.AddWeight(ids.GenerateTestShortID(), 1))
+	sender := &common.SenderTest{T: t}
+	sender.Default(false)
+	registry := prometheus.NewRegistry()
+
+	newEngine := func(restarted bool) (common.Engine, error) {
+		params := snowball.Parameters{
+			Metrics:               registry,
+			K:                     1,
+			Alpha:                 1,
+			BetaVirtuous:          1,
+			BetaRogue:             2,
+			ConcurrentRepolls:     1,
+			OptimalProcessing:     100,
+			MaxOutstandingItems:   1,
+			MaxItemProcessingTime: 1,
+		}
+		if restarted {
+			params.Metrics = replacingRegisterer{params.Metrics}
+		}
+		blocked, err := queue.New(memdb.New())
+		if err != nil {
+			return nil, err
+		}
+		engine := &smeng.Transitive{}
+		err = engine.Initialize(smeng.Config{
+			Config: smbootstrap.Config{
+				Config: common.Config{
+					Ctx:        ctx,
+					Validators: vdrs,
+					Beacons:    vdrs,
+					SampleK:    1,
+					Alpha:      1,
+					Sender:     sender,
+					Subnet: &common.SubnetTest{
+						IsBootstrappedF: func() bool { return true },
+						BootstrappedF:   func(ids.ID) {},
+					},
+					Delay: &router.Delay{},
+				},
+				Blocked: blocked,
+				VM:      vm,
+			},
+			Params:    params,
+			Consensus: &smcon.Topological{},
+		})
+		return engine, err
+	}
+
+	ctx.Lock.Lock()
+	engine, err := newEngine(false)
+	assert.NoError(err)
+	// The chain is assumed to have already bootstrapped
+	ctx.Bootstrapped()
+	failing := failingEngine{Engine: engine}
+
+	handler := &router.Handler{}
+	assert.NoError(handler.Initialize(
+		failing,
+		vdrs,
+		msgChan,
+		16,
+		router.DefaultMaxNonStakerPendingMsgs,
+		router.DefaultStakerPortion,
+		router.DefaultStakerPortion,
+		nil,
+		"",
+		prometheus.NewRegistry(),
+		&router.Delay{},
+	))
+	c := &chain{
+		Engine:  failing,
+		Handler: handler,
+		VM:      vm,
+		Ctx:     ctx,
+	}
+	m := New(&ManagerConfig{
+		DB:                 memdb.New(),
+		ChainRestartBudget: 1,
+	}).(*manager)
+	m.supervise(c, newEngine)
+	ctx.Lock.Unlock()
+
+	go handler.Dispatch()
+	defer handler.Shutdown()
+
+	handler.Gossip()
+	restarted := false
+	for deadline := time.Now().Add(time.Second); !restarted && time.Now().Before(deadline); {
+		time.Sleep(time.Millisecond)
+		ctx.Lock.Lock()
+		restarted = handler.Engine() != common.Engine(failing)
+		ctx.Lock.Unlock()
+	}
+	assert.True(restarted, "the failed engine should have been replaced")
+
+	ctx.Lock.Lock()
+	defer ctx.Lock.Unlock()
+
+	// The new engine is waiting for the beacons to answer, so the chain is
+	// bootstrapping again
+	assert.False(ctx.IsBootstrapped())
+	m.chainsLock.Lock()
+	assert.Equal(handler.Engine(), c.Engine)
+	m.chainsLock.Unlock()
+
+	// The VM wasn't shut down, so it can still read its database
+	lastAccepted, err := vm.LastAccepted()
+	assert.NoError(err)
+	_, err = vm.GetBlock(lastAccepted)
+	assert.NoError(err)
+}
@@ -0,0 +1,374 @@
+// (c) 2019-2020, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package manager
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+
+	"github.com/ava-labs/avalanchego/database"
+	"github.com/ava-labs/avalanchego/database/prefixdb"
+	"github.com/ava-labs/avalanchego/database/versiondb"
+	"github.com/ava-labs/avalanchego/utils"
+	"github.com/ava-labs/avalanchego/utils/logging"
+	"github.com/ava-labs/avalanchego/utils/wrappers"
+)
+
+const (
+	// The number of bytes written to the backup of a key space before they
+	// are committed, so that the key space doesn't have to fit in memory
+	backupBatchSize = 1 << 20
+)
+
+var (
+	versionPrefix       = []byte("schema")
+	backupPrefix        = []byte("schema_backup")
+	backupVersionPrefix = []byte("schema_backup_version")
+
+	errDuplicateKeySpace = errors.New("duplicate key space")
+	errUnknownKeySpace   = errors.New("unknown key space")
+	errUnknownVersion    = errors.New("unknown schema version")
+	errNoBackup          = errors.New("no backup to roll back to")
+	errMalformedVersion  = errors.New("malformed schema version")
+)
+
+// Migration upgrades the contents of a key space from one schema version to
+// the next. The writes of a migration aren't persisted unless it, and every
+// other migration of the key space that is pending, succeeds.
+type Migration func(db database.Database) error
+
+// Migrator is implemented by the owner of a key space whose format has
+// changed. The migration at index i upgrades the key space from version i to
+// version i+1, so the latest version is the number of migrations.
+type Migrator interface {
+	Migrations() []Migration
+}
+
+type keySpace struct {
+	name       string
+	db         database.Database
+	migrations []Migration
+}
+
+// Manager records the schema version of each key space of a database and
+// migrates key spaces to their latest version.
+//
+// Every key space must have the same underlying database as the database
+// passed to New, so that a migration and its new version are written
+// atomically.
+type Manager struct {
+	log logging.Logger
+
+	versionDB, backupDB, backupVersionDB database.Database
+
+	// Key spaces in the order they were registered
+	keySpaces []*keySpace
+	// Key space name --> key space
+	keySpaceIndex map[string]*keySpace
+}
+
+// New returns a manager that records schema versions in [db]
+func New(db database.Database, log logging.Logger) *Manager {
+	return &Manager{
+		log:             log,
+		versionDB:       prefixdb.New(versionPrefix, db),
+		backupDB:        prefixdb.New(backupPrefix, db),
+		backupVersionDB: prefixdb.New(backupVersionPrefix, db),
+		keySpaceIndex:   make(map[string]*keySpace),
+	}
+}
+
+// Register the key space [name], stored in [db], whose migrations are
+// [migrations]
+func (m *Manager) Register(name string, db database.Database, migrations ...Migration) error {
+	if _, exists := m.keySpaceIndex[name]; exists {
+		return fmt.Errorf("%w: %s", errDuplicateKeySpace, name)
+	}
+	ks := &keySpace{
+		name:       name,
+		db:         db,
+		migrations: migrations,
+	}
+	m.keySpaces = append(m.keySpaces, ks)
+	m.keySpaceIndex[name] = ks
+	return nil
+}
+
+// LatestVersion returns the version that key space [name] will be migrated to
+func (m *Manager) LatestVersion(name string) (uint32, error) {
+	ks, exists := m.keySpaceIndex[name]
+	if !exists {
+		return 0, fmt.Errorf("%w: %s", errUnknownKeySpace, name)
+	}
+	return uint32(len(ks.migrations)), nil
+}
+
+// Version returns the current schema version of key space [name]. A key space
+// without a recorded version is assumed to be at its latest version if it's
+// empty, and to have been written before versions were recorded otherwise.
+func (m *Manager) Version(name string) (uint32, error) {
+	ks, exists := m.keySpaceIndex[name]
+	if !exists {
+		return 0, fmt.Errorf("%w: %s", errUnknownKeySpace, name)
+	}
+	return m.version(ks)
+}
+
+func (m *Manager) version(ks *keySpace) (uint32, error) {
+	version, err := getVersion(m.versionDB, ks.name)
+	if err != database.ErrNotFound {
+		return version, err
+	}
+
+	iter := ks.db.NewIterator()
+	defer iter.Release()
+
+	if iter.Next() {
+		return 0, nil
+	}
+	return uint32(len(ks.migrations)), iter.Error()
+}
+
+// Migrate every registered key space to its latest version, in the order they
+// were registered. Before a key space is migrated, its contents are backed up
+// so that the migration can be undone with Rollback, until the backup is
+// removed with DiscardBackups. If a migration fails, its key space isn't
+// modified.
+func (m *Manager) Migrate() error {
+	for _, ks := range m.keySpaces {
+		if err := m.migrate(ks); err != nil {
+			return fmt.Errorf("couldn't migrate key space %s: %w", ks.name, err)
+		}
+	}
+	return nil
+}
+
+func (m *Manager) migrate(ks *keySpace) error {
+	version, err := m.version(ks)
+	if err != nil {
+		return err
+	}
+	latest := uint32(len(ks.migrations))
+	switch {
+	case version > latest:
+		return fmt.Errorf("%w: %d is newer than %d", errUnknownVersion, version, latest)
+	case version == latest:
+		// Record the version of key spaces that were empty or written before
+		// versions were recorded, so that they aren't assumed to be up to
+		// date once they're written to
+		return putVersion(m.versionDB, ks.name, version)
+	}
+
+	m.log.Info("migrating key space %s from schema version %d to %d", ks.name, version, latest)
+
+	ksDB := versiondb.New(ks.db)
+	defer ksDB.Abort()
+	for v := version; v < latest; v++ {
+		if err := ks.migrations[v](ksDB); err != nil {
+			return fmt.Errorf("migration from version %d failed: %w", v, err)
+		}
+	}
+
+	// The backup is a copy of the key space before it was migrated. It's
+	// written in batches, rather than with the migration, so the version of
+	// the previous backup is removed first. The version of the new backup is
+	// written with the migration, so a partially written backup is never
+	// rolled back to.
+	if err := m.backupVersionDB.Delete([]byte(ks.name)); err != nil {
+		return err
+	}
+	backupDB := prefixdb.New([]byte(ks.name), m.backupDB)
+	if err := clear(backupDB); err != nil {
+		return err
+	}
+	if err := copyAll(backupDB, ks.db); err != nil {
+		return err
+	}
+
+	versionDB := versiondb.New(m.versionDB)
+	defer versionDB.Abort()
+	if err := putVersion(versionDB, ks.name, latest); err != nil {
+		return err
+	}
+	backupVersionDB := versiondb.New(m.backupVersionDB)
+	defer backupVersionDB.Abort()
+	if err := putVersion(backupVersionDB, ks.name, version); err != nil {
+		return err
+	}
+
+	if err := writeAll(ksDB, versionDB, backupVersionDB); err != nil {
+		return err
+	}
+	m.log.Info("migrated key space %s to schema version %d", ks.name, latest)
+	return nil
+}
+
+// DiscardBackups removes the backup of every registered key space, once their
+// migrations no longer need to be rolled back
+func (m *Manager) DiscardBackups() error {
+	for _, ks := range m.keySpaces {
+		if err := m.discardBackup(ks.name); err != nil {
+			return fmt.Errorf("couldn't discard the backup of key space %s: %w", ks.name, err)
+		}
+	}
+	return nil
+}
+
+// discardBackup removes the backup of key space [name]. The backup's version
+// is removed first, so a partially removed backup is never rolled back to.
+func (m *Manager) discardBackup(name string) error {
+	if _, err := getVersion(m.backupVersionDB, name); err == database.ErrNotFound {
+		return nil
+	} else if err != nil {
+		return err
+	}
+
+	m.log.Info("discarding the backup of key space %s", name)
+	if err := m.backupVersionDB.Delete([]byte(name)); err != nil {
+		return err
+	}
+	return clear(prefixdb.New([]byte(name), m.backupDB))
+}
+
+// Rollback restores key space [name] to the contents and version it had
+// before it was last migrated
+func (m *Manager) Rollback(name string) error {
+	ks, exists := m.keySpaceIndex[name]
+	if !exists {
+		return fmt.Errorf("%w: %s", errUnknownKeySpace, name)
+	}
+
+	version, err := getVersion(m.backupVersionDB, name)
+	if err == database.ErrNotFound {
+		return fmt.Errorf("%w: %s", errNoBackup, name)
+	}
+	if err != nil {
+		return err
+	}
+
+	m.log.Info("rolling back key space %s to schema version %d", name, version)
+
+	ksDB := versiondb.New(ks.db)
+	defer ksDB.Abort()
+	if err := clear(ksDB); err != nil {
+		return err
+	}
+	backupDB := prefixdb.New([]byte(name), m.backupDB)
+	if err := copyAll(ksDB, backupDB); err != nil {
+		return err
+	}
+
+	versionDB := versiondb.New(m.versionDB)
+	defer versionDB.Abort()
+	if err := putVersion(versionDB, name, version); err != nil {
+		return err
+	}
+	backupVersionDB := versiondb.New(m.backupVersionDB)
+	defer backupVersionDB.Abort()
+	if err := backupVersionDB.Delete([]byte(name)); err != nil {
+		return err
+	}
+
+	if err := writeAll(ksDB, versionDB, backupVersionDB); err != nil {
+		return err
+	}
+	// The backup's version was removed with the rollback, so the backup can
+	// be removed in batches
+	return clear(backupDB)
+}
+
+func getVersion(db database.KeyValueReader, name string) (uint32, error) {
+	versionBytes, err := db.Get([]byte(name))
+	if err != nil {
+		return 0, err
+	}
+	if len(versionBytes) != wrappers.IntLen {
+		return 0, fmt.Errorf("%w: %d bytes", errMalformedVersion, len(versionBytes))
+	}
+	return binary.BigEndian.Uint32(versionBytes), nil
+}
+
+func putVersion(db database.KeyValueWriter, name string, version uint32) error {
+	versionBytes := make([]byte, wrappers.IntLen)
+	binary.BigEndian.PutUint32(versionBytes, version)
+	return db.Put([]byte(name), versionBytes)
+}
+
+// clear deletes every key in [db]. The deletes are written in batches of
+// roughly [backupBatchSize] bytes, so they aren't atomic.
+func clear(db database.Database) error {
+	iter := db.NewIterator()
+	defer iter.Release()
+
+	batch := db.NewBatch()
+	for iter.Next() {
+		if err := batch.Delete(utils.CopyBytes(iter.Key())); err != nil {
+			return err
+		}
+		if err := writeIfFull(batch); err != nil {
+			return err
+		}
+	}
+	if err := iter.Error(); err != nil {
+		return err
+	}
+	return batch.Write()
+}
+
+// copyAll writes every key/value pair of [src] to [dst]. The pairs are written
+// in batches of roughly [backupBatchSize] bytes, so they aren't written
+// atomically.
+func copyAll(dst, src database.Database) error {
+	iter := src.NewIterator()
+	defer iter.Release()
+
+	batch := dst.NewBatch()
+	for iter.Next() {
+		if err := batch.Put(utils.CopyBytes(iter.Key()), utils.CopyBytes(iter.Value())); err != nil {
+			return err
+		}
+		if err := writeIfFull(batch); err != nil {
+			return err
+		}
+	}
+	if err := iter.Error(); err != nil {
+		return err
+	}
+	return batch.Write()
+}
+
+// writeIfFull writes and resets [batch] once it holds at least
+// [backupBatchSize] bytes
+func writeIfFull(batch database.Batch) error {
+	if batch.Size() < backupBatchSize {
+		return nil
+	}
+	if err := batch.Write(); err != nil {
+		return err
+	}
+	batch.Reset()
+	return nil
+}
+
+// writeAll atomically commits [dbs], which must have the same underlying
+// database
+func writeAll(dbs ...*versiondb.Database) error {
+	batches := make([]database.Batch, len(dbs))
+	for i, db := range dbs {
+		batch, err := db.CommitBatch()
+		if err != nil {
+			return err
+		}
+		batches[i] = batch.Inner()
+	}
+
+	baseBatch := batches[0]
+	for _, batch := range batches[1:] {
+		if err := batch.Replay(baseBatch); err != nil {
+			return err
+		}
+	}
+	return baseBatch.Write()
+}
@@ -0,0 +1,252 @@
+// (c) 2019-2020, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package manager
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/ava-labs/avalanchego/database"
+	"github.com/ava-labs/avalanchego/database/memdb"
+	"github.com/ava-labs/avalanchego/database/prefixdb"
+	"github.com/ava-labs/avalanchego/utils/logging"
+)
+
+// renameKeys is a migration that prefixes every key with [prefix]
+func renameKeys(prefix string) Migration {
+	return func(db database.Database) error {
+		iter := db.NewIterator()
+		defer iter.Release()
+
+		for iter.Next() {
+			key := append([]byte(prefix), iter.Key()...)
+			if err := db.Put(key, iter.Value()); err != nil {
+				return err
+			}
+			if err := db.Delete(iter.Key()); err != nil {
+				return err
+			}
+		}
+		return iter.Error()
+	}
+}
+
+func TestManagerEmptyKeySpace(t *testing.T) {
+	assert := assert.New(t)
+
+	baseDB := memdb.New()
+	ksDB := prefixdb.New([]byte("ks"), baseDB)
+
+	m := New(baseDB, logging.NoLog{})
+	assert.NoError(m.Register("ks", ksDB, renameKeys("a"), renameKeys("b")))
+
+	version, err := m.Version("ks")
+	assert.NoError(err)
+	assert.Equal(uint32(2), version, "an empty key space should be at the latest version")
+
+	assert.NoError(m.Migrate())
+	assert.NoError(ksDB.Put([]byte("key"), []byte("value")))
+
+	// Once the version is recorded, writes to the key space don't change it
+	m = New(baseDB, logging.NoLog{})
+	assert.NoError(m.Register("ks", ksDB, renameKeys("a"), renameKeys("b")))
+	version, err = m.Version("ks")
+	assert.NoError(err)
+	assert.Equal(uint32(2), version)
+
+	assert.NoError(m.Migrate())
+	value, err := ksDB.Get([]byte("key"))
+	assert.NoError(err)
+	assert.Equal([]byte("value"), value)
+}
+
+func TestManagerMigrateAndRollback(t *testing.T) {
+	assert := assert.New(t)
+
+	baseDB := memdb.New()
+	ksDB := prefixdb.New([]byte("ks"), baseDB)
+	assert.NoError(ksDB.Put([]byte("key"), []byte("value")))
+
+	m := New(baseDB, logging.NoLog{})
+	assert.NoError(m.Register("ks", ksDB, renameKeys("a"), renameKeys("b")))
+
+	version, err := m.Version("ks")
+	assert.NoError(err)
+	assert.Equal(uint32(0), version, "an unversioned key space should be at version 0")
+
+	assert.NoError(m.Migrate())
+
+	version, err = m.Version("ks")
+	assert.NoError(err)
+	assert.Equal(uint32(2), version)
+
+	has, err := ksDB.Has([]byte("key"))
+	assert.NoError(err)
+	assert.False(has, "migrations should have renamed the key")
+	value, err := ksDB.Get([]byte("bakey"))
+	assert.NoError(err)
+	assert.Equal([]byte("value"), value)
+
+	assert.NoError(m.Rollback("ks"))
+
+	version, err = m.Version("ks")
+	assert.NoError(err)
+	assert.Equal(uint32(0), version)
+
+	value, err = ksDB.Get([]byte("key"))
+	assert.NoError(err)
+	assert.Equal([]byte("value"), value)
+	has, err = ksDB.Has([]byte("bakey"))
+	assert.NoError(err)
+	assert.False(has, "rollback should have removed the migrated key")
+
+	err = m.Rollback("ks")
+	assert.True(errors.Is(err, errNoBackup), "the backup should be removed after rolling back")
+}
+
+func TestManagerMigratesFromRecordedVersion(t *testing.T) {
+	assert := assert.New(t)
+
+	baseDB := memdb.New()
+	ksDB := prefixdb.New([]byte("ks"), baseDB)
+	assert.NoError(ksDB.Put([]byte("key"), []byte("value")))
+
+	m := New(baseDB, logging.NoLog{})
+	assert.NoError(m.Register("ks", ksDB, renameKeys("a")))
+	assert.NoError(m.Migrate())
+
+	// Only the migration that was added since the last run should be applied
+	m = New(baseDB, logging.NoLog{})
+	assert.NoError(m.Register("ks", ksDB, renameKeys("a"), renameKeys("b")))
+	assert.NoError(m.Migrate())
+
+	value, err := ksDB.Get([]byte("bakey"))
+	assert.NoError(err)
+	assert.Equal([]byte("value"), value)
+
+	// Rolling back only undoes the last migration
+	assert.NoError(m.Rollback("ks"))
+	version, err := m.Version("ks")
+	assert.NoError(err)
+	assert.Equal(uint32(1), version)
+	value, err = ksDB.Get([]byte("akey"))
+	assert.NoError(err)
+	assert.Equal([]byte("value"), value)
+}
+
+func TestManagerFailedMigration(t *testing.T) {
+	assert := assert.New(t)
+
+	baseDB := memdb.New()
+	ksDB := prefixdb.New([]byte("ks"), baseDB)
+	assert.NoError(ksDB.Put([]byte("key"), []byte("value")))
+
+	errFailed := errors.New("failed")
+	m := New(baseDB, logging.NoLog{})
+	assert.NoError(m.Register("ks", ksDB, renameKeys("a"), func(database.Database) error { return errFailed }))
+
+	err := m.Migrate()
+	assert.True(errors.Is(err, errFailed))
+
+	version, err := m.Version("ks")
+	assert.NoError(err)
+	assert.Equal(uint32(0), version)
+
+	value, err := ksDB.Get([]byte("key"))
+	assert.NoError(err)
+	assert.Equal([]byte("value"), value, "a failed migration shouldn't write to the key space")
+	has, err := ksDB.Has([]byte("akey"))
+	assert.NoError(err)
+	assert.False(has, "a failed migration shouldn't write to the key space")
+
+	err = m.Rollback("ks")
+	assert.True(errors.Is(err, errNoBackup))
+}
+
+func TestManagerBacksUpInBatches(t *testing.T) {
+	assert := assert.New(t)
+
+	baseDB := memdb.New()
+	ksDB := prefixdb.New([]byte("ks"), baseDB)
+	value := make([]byte, 1024)
+	numKeys := 2 * backupBatchSize / len(value)
+	for i := 0; i < numKeys; i++ {
+		assert.NoError(ksDB.Put([]byte(fmt.Sprintf("key%d", i)), value))
+	}
+
+	m := New(baseDB, logging.NoLog{})
+	assert.NoError(m.Register("ks", ksDB, renameKeys("a")))
+	assert.NoError(m.Migrate())
+	assert.NoError(m.Rollback("ks"))
+
+	for i := 0; i < numKeys; i++ {
+		has, err := ksDB.Has([]byte(fmt.Sprintf("key%d", i)))
+		assert.NoError(err)
+		assert.True(has, "rollback should have restored every key")
+	}
+}
+
+func TestManagerDiscardBackups(t *testing.T) {
+	assert := assert.New(t)
+
+	baseDB := memdb.New()
+	ksDB := prefixdb.New([]byte("ks"), baseDB)
+	assert.NoError(ksDB.Put([]byte("key"), []byte("value")))
+
+	m := New(baseDB, logging.NoLog{})
+	assert.NoError(m.Register("ks", ksDB, renameKeys("a")))
+	assert.NoError(m.Migrate())
+	assert.NoError(m.DiscardBackups())
+
+	iter := prefixdb.New([]byte("ks"), m.backupDB).NewIterator()
+	assert.False(iter.Next(), "the backup should have been removed")
+	iter.Release()
+
+	err := m.Rollback("ks")
+	assert.True(errors.Is(err, errNoBackup), "shouldn't roll back to a discarded backup")
+
+	value, err := ksDB.Get([]byte("akey"))
+	assert.NoError(err)
+	assert.Equal([]byte("value"), value, "discarding the backup shouldn't modify the key space")
+
+	// Discarding a missing backup is a no-op
+	assert.NoError(m.DiscardBackups())
+}
+
+func TestManagerNewerVersion(t *testing.T) {
+	assert := assert.New(t)
+
+	baseDB := memdb.New()
+	ksDB := prefixdb.New([]byte("ks"), baseDB)
+
+	m := New(baseDB, logging.NoLog{})
+	assert.NoError(m.Register("ks", ksDB, renameKeys("a")))
+	assert.NoError(m.Migrate())
+
+	m = New(baseDB, logging.NoLog{})
+	assert.NoError(m.Register("ks", ksDB))
+	err := m.Migrate()
+	assert.True(errors.Is(err, errUnknownVersion), "shouldn't run against a key space written by a newer version")
+}
+
+func TestManagerRegister(t *testing.T) {
+	assert := assert.New(t)
+
+	baseDB := memdb.New()
+	m := New(baseDB, logging.NoLog{})
+	assert.NoError(m.Register("ks", prefixdb.New([]byte("ks"), baseDB)))
+
+	err := m.Register("ks", prefixdb.New([]byte("ks"), baseDB))
+	assert.True(errors.Is(err, errDuplicateKeySpace))
+
+	_, err = m.Version("unknown")
+	assert.True(errors.Is(err, errUnknownKeySpace))
+	_, err = m.LatestVersion("unknown")
+	assert.True(errors.Is(err, errUnknownKeySpace))
+	err = m.Rollback("unknown")
+	assert.True(errors.Is(err, errUnknownKeySpace))
+}
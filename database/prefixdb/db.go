@@ -177,6 +177,9 @@ func (db *Database) Stat(stat string) (string, error) {
 }
 
 // Compact implements the Database interface
+// A nil [limit] is treated as a key after all keys in this database, rather
+// than as the prefix itself, so that Compact(nil, nil) compacts every key in
+// this database.
 func (db *Database) Compact(start, limit []byte) error {
 	db.lock.Lock()
 	defer db.lock.Unlock()
@@ -184,9 +187,26 @@ func (db *Database) Compact(start, limit []byte) error {
 	if db.db == nil {
 		return database.ErrClosed
 	}
+	if limit == nil {
+		return db.db.Compact(db.prefix(start), prefixLimit(db.dbPrefix))
+	}
 	return db.db.Compact(db.prefix(start), db.prefix(limit))
 }
 
+// prefixLimit returns the smallest key that is greater than every key that
+// starts with [prefix]. If there is no such key, nil is returned.
+func prefixLimit(prefix []byte) []byte {
+	limit := make([]byte, len(prefix))
+	copy(limit, prefix)
+	for i := len(limit) - 1; i >= 0; i-- {
+		if limit[i] != 0xff {
+			limit[i]++
+			return limit[:i+1]
+		}
+	}
+	return nil
+}
+
 // Close implements the Database interface
 func (db *Database) Close() error {
 	db.lock.Lock()
@@ -4,6 +4,7 @@
 package prefixdb
 
 import (
+	"bytes"
 	"testing"
 
 	"github.com/ava-labs/avalanchego/database"
@@ -21,3 +22,54 @@ func TestInterface(t *testing.T) {
 		test(t, NewNested([]byte("ld"), New([]byte("wor"), db)))
 	}
 }
+
+// compactRecorder records the range of the last call to Compact
+type compactRecorder struct {
+	database.Database
+	start, limit []byte
+}
+
+func (db *compactRecorder) Compact(start, limit []byte) error {
+	db.start, db.limit = start, limit
+	return db.Database.Compact(start, limit)
+}
+
+func TestCompactEntirePrefix(t *testing.T) {
+	baseDB := &compactRecorder{Database: memdb.New()}
+	db := New([]byte("hello"), baseDB)
+
+	keys := [][]byte{{}, {0x00}, {0xff, 0xff, 0xff}}
+	for _, key := range keys {
+		if err := db.Put(key, nil); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if err := db.Compact(nil, nil); err != nil {
+		t.Fatal(err)
+	}
+
+	iter := baseDB.NewIterator()
+	defer iter.Release()
+	for iter.Next() {
+		key := iter.Key()
+		if bytes.Compare(key, baseDB.start) < 0 || bytes.Compare(key, baseDB.limit) >= 0 {
+			t.Fatalf("key %x isn't in the compacted range [%x, %x)", key, baseDB.start, baseDB.limit)
+		}
+	}
+}
+
+func TestPrefixLimit(t *testing.T) {
+	tests := []struct {
+		prefix, limit []byte
+	}{
+		{prefix: []byte{0x00}, limit: []byte{0x01}},
+		{prefix: []byte{0x01, 0xff}, limit: []byte{0x02}},
+		{prefix: []byte{0xff, 0xff}, limit: nil},
+	}
+	for _, test := range tests {
+		if limit := prefixLimit(test.prefix); !bytes.Equal(limit, test.limit) {
+			t.Fatalf("expected limit %x for prefix %x but got %x", test.limit, test.prefix, limit)
+		}
+	}
+}
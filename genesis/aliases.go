@@ -9,6 +9,7 @@ import (
 	"github.com/ava-labs/avalanchego/vms/avm"
 	"github.com/ava-labs/avalanchego/vms/evm"
 	"github.com/ava-labs/avalanchego/vms/nftfx"
+	"github.com/ava-labs/avalanchego/vms/pausefx"
 	"github.com/ava-labs/avalanchego/vms/platformvm"
 	"github.com/ava-labs/avalanchego/vms/propertyfx"
 	"github.com/ava-labs/avalanchego/vms/secp256k1fx"
@@ -35,6 +36,7 @@ func Aliases(genesisBytes []byte) (map[string][]string, map[ids.ID][]string, map
 		secp256k1fx.ID: {"secp256k1fx"},
 		nftfx.ID:       {"nftfx"},
 		propertyfx.ID:  {"propertyfx"},
+		pausefx.ID:     {"pausefx"},
 	}
 
 	genesis := &platformvm.Genesis{} // TODO let's not re-create genesis to do aliasing
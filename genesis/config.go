@@ -12,7 +12,6 @@ import (
 
 	"github.com/ava-labs/avalanchego/ids"
 	"github.com/ava-labs/avalanchego/utils/constants"
-	"github.com/ava-labs/avalanchego/utils/formatting"
 	"github.com/ava-labs/avalanchego/utils/wrappers"
 
 	safemath "github.com/ava-labs/avalanchego/utils/math"
@@ -39,11 +38,7 @@ func (a Allocation) Unparse(networkID uint32) (UnparsedAllocation, error) {
 		UnlockSchedule: a.UnlockSchedule,
 		ETHAddr:        "0x" + hex.EncodeToString(a.ETHAddr.Bytes()),
 	}
-	avaxAddr, err := formatting.FormatAddress(
-		"X",
-		constants.GetHRP(networkID),
-		a.AVAXAddr.Bytes(),
-	)
+	avaxAddr, err := a.AVAXAddr.Address("X", constants.GetHRP(networkID))
 	ua.AVAXAddr = avaxAddr
 	return ua, err
 }
@@ -57,11 +52,7 @@ type Staker struct {
 
 // Unparse ...
 func (s Staker) Unparse(networkID uint32) (UnparsedStaker, error) {
-	avaxAddr, err := formatting.FormatAddress(
-		"X",
-		constants.GetHRP(networkID),
-		s.RewardAddress.Bytes(),
-	)
+	avaxAddr, err := s.RewardAddress.Address("X", constants.GetHRP(networkID))
 	return UnparsedStaker{
 		NodeID:        s.NodeID.PrefixedString(constants.NodeIDPrefix),
 		RewardAddress: avaxAddr,
@@ -107,11 +98,7 @@ func (c Config) Unparse() (UnparsedConfig, error) {
 		uc.Allocations[i] = ua
 	}
 	for i, isa := range c.InitialStakedFunds {
-		avaxAddr, err := formatting.FormatAddress(
-			"X",
-			constants.GetHRP(uc.NetworkID),
-			isa.Bytes(),
-		)
+		avaxAddr, err := isa.Address("X", constants.GetHRP(uc.NetworkID))
 		if err != nil {
 			return uc, err
 		}
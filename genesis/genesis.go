@@ -14,6 +14,7 @@ import (
 	"github.com/ava-labs/avalanchego/codec"
 	"github.com/ava-labs/avalanchego/codec/linearcodec"
 	"github.com/ava-labs/avalanchego/codec/reflectcodec"
+	"github.com/ava-labs/avalanchego/genesis/xchain"
 	"github.com/ava-labs/avalanchego/ids"
 	"github.com/ava-labs/avalanchego/utils/constants"
 	"github.com/ava-labs/avalanchego/utils/formatting"
@@ -54,11 +55,7 @@ func validateInitialStakedFunds(config *Config) error {
 
 	for _, staker := range config.InitialStakedFunds {
 		if initialStakedFundsSet.Contains(staker) {
-			avaxAddr, err := formatting.FormatAddress(
-				configChainIDAlias,
-				constants.GetHRP(config.NetworkID),
-				staker.Bytes(),
-			)
+			avaxAddr, err := staker.Address(configChainIDAlias, constants.GetHRP(config.NetworkID))
 			if err != nil {
 				return fmt.Errorf(
 					"unable to format address from %s",
@@ -74,11 +71,7 @@ func validateInitialStakedFunds(config *Config) error {
 		initialStakedFundsSet.Add(staker)
 
 		if !allocationSet.Contains(staker) {
-			avaxAddr, err := formatting.FormatAddress(
-				configChainIDAlias,
-				constants.GetHRP(config.NetworkID),
-				staker.Bytes(),
-			)
+			avaxAddr, err := staker.Address(configChainIDAlias, constants.GetHRP(config.NetworkID))
 			if err != nil {
 				return fmt.Errorf(
 					"unable to format address from %s",
@@ -212,18 +205,15 @@ func FromConfig(config *Config) ([]byte, ids.ID, error) {
 	amount := uint64(0)
 
 	// Specify the genesis state of the AVM
-	avmArgs := avm.BuildGenesisArgs{
-		NetworkID: json.Uint32(config.NetworkID),
-		Encoding:  defaultEncoding,
-	}
+	avmGenesis := xchain.NewBuilder(config.NetworkID)
 	{
-		avax := avm.AssetDefinition{
+		avax := &xchain.Asset{
+			Alias:        "AVAX", // The AVM starts out with one asset: AVAX
 			Name:         "Avalanche",
 			Symbol:       "AVAX",
 			Denomination: 9,
-			InitialState: map[string][]interface{}{},
+			Memo:         []byte{},
 		}
-		memoBytes := []byte{}
 		xAllocations := []Allocation(nil)
 		for _, allocation := range config.Allocations {
 			if allocation.InitialAmount > 0 {
@@ -233,39 +223,23 @@ func FromConfig(config *Config) ([]byte, ids.ID, error) {
 		sortXAllocation(xAllocations)
 
 		for _, allocation := range xAllocations {
-			addr, err := formatting.FormatBech32(hrp, allocation.AVAXAddr.Bytes())
-			if err != nil {
-				return nil, ids.ID{}, err
-			}
-
-			avax.InitialState["fixedCap"] = append(avax.InitialState["fixedCap"], avm.Holder{
-				Amount:  json.Uint64(allocation.InitialAmount),
-				Address: addr,
+			avax.Allocations = append(avax.Allocations, xchain.Allocation{
+				Address: allocation.AVAXAddr,
+				Amount:  allocation.InitialAmount,
 			})
-			memoBytes = append(memoBytes, allocation.ETHAddr.Bytes()...)
+			avax.Memo = append(avax.Memo, allocation.ETHAddr.Bytes()...)
 			amount += allocation.InitialAmount
 		}
-
-		var err error
-		avax.Memo, err = formatting.Encode(defaultEncoding, memoBytes)
-		if err != nil {
-			return nil, ids.Empty, fmt.Errorf("couldn't parse memo bytes to string: %w", err)
-		}
-		avmArgs.GenesisData = map[string]avm.AssetDefinition{
-			"AVAX": avax, // The AVM starts out with one asset: AVAX
-		}
+		avmGenesis.AddAsset(avax)
 	}
-	avmReply := avm.BuildGenesisReply{}
 
-	avmSS := avm.CreateStaticService()
-	err := avmSS.BuildGenesis(nil, &avmArgs, &avmReply)
+	bytes, err := avmGenesis.Build()
 	if err != nil {
-		return nil, ids.ID{}, err
+		return nil, ids.ID{}, fmt.Errorf("couldn't build avm genesis: %w", err)
 	}
-
-	bytes, err := formatting.Decode(defaultEncoding, avmReply.Bytes)
+	avmGenesisStr, err := formatting.Encode(defaultEncoding, bytes)
 	if err != nil {
-		return nil, ids.ID{}, fmt.Errorf("couldn't parse avm genesis reply: %w", err)
+		return nil, ids.ID{}, fmt.Errorf("couldn't encode avm genesis: %w", err)
 	}
 	avaxAssetID, err := AVAXAssetID(bytes)
 	if err != nil {
@@ -296,7 +270,7 @@ func FromConfig(config *Config) ([]byte, ids.ID, error) {
 			skippedAllocations = append(skippedAllocations, allocation)
 			continue
 		}
-		addr, err := formatting.FormatBech32(hrp, allocation.AVAXAddr.Bytes())
+		addr, err := allocation.AVAXAddr.Bech32(hrp)
 		if err != nil {
 			return nil, ids.ID{}, err
 		}
@@ -327,14 +301,14 @@ func FromConfig(config *Config) ([]byte, ids.ID, error) {
 		endStakingTime := endStakingTime.Add(-stakingOffset)
 		stakingOffset += time.Duration(config.InitialStakeDurationOffset) * time.Second
 
-		destAddrStr, err := formatting.FormatBech32(hrp, staker.RewardAddress.Bytes())
+		destAddrStr, err := staker.RewardAddress.Bech32(hrp)
 		if err != nil {
 			return nil, ids.ID{}, err
 		}
 
 		utxos := []platformvm.APIUTXO(nil)
 		for _, allocation := range nodeAllocations {
-			addr, err := formatting.FormatBech32(hrp, allocation.AVAXAddr.Bytes())
+			addr, err := allocation.AVAXAddr.Bech32(hrp)
 			if err != nil {
 				return nil, ids.ID{}, err
 			}
@@ -379,7 +353,7 @@ func FromConfig(config *Config) ([]byte, ids.ID, error) {
 	}
 	platformvmArgs.Chains = []platformvm.APIChain{
 		{
-			GenesisData: avmReply.Bytes,
+			GenesisData: avmGenesisStr,
 			SubnetID:    constants.PrimaryNetworkID,
 			VMID:        avm.ID,
 			FxIDs: []ids.ID{
@@ -9,7 +9,6 @@ import (
 
 	"github.com/ava-labs/avalanchego/ids"
 	"github.com/ava-labs/avalanchego/utils/constants"
-	"github.com/ava-labs/avalanchego/utils/formatting"
 )
 
 // UnparsedAllocation ...
@@ -41,11 +40,7 @@ func (ua UnparsedAllocation) Parse() (Allocation, error) {
 	}
 	a.ETHAddr = ethAddr
 
-	_, _, avaxAddrBytes, err := formatting.ParseAddress(ua.AVAXAddr)
-	if err != nil {
-		return a, err
-	}
-	avaxAddr, err := ids.ToShortID(avaxAddrBytes)
+	_, _, avaxAddr, err := ids.ShortFromAddress(ua.AVAXAddr)
 	if err != nil {
 		return a, err
 	}
@@ -73,11 +68,7 @@ func (us UnparsedStaker) Parse() (Staker, error) {
 	}
 	s.NodeID = nodeID
 
-	_, _, avaxAddrBytes, err := formatting.ParseAddress(us.RewardAddress)
-	if err != nil {
-		return s, err
-	}
-	avaxAddr, err := ids.ToShortID(avaxAddrBytes)
+	_, _, avaxAddr, err := ids.ShortFromAddress(us.RewardAddress)
 	if err != nil {
 		return s, err
 	}
@@ -123,11 +114,7 @@ func (uc UnparsedConfig) Parse() (Config, error) {
 		c.Allocations[i] = a
 	}
 	for i, isa := range uc.InitialStakedFunds {
-		_, _, avaxAddrBytes, err := formatting.ParseAddress(isa)
-		if err != nil {
-			return c, err
-		}
-		avaxAddr, err := ids.ToShortID(avaxAddrBytes)
+		_, _, avaxAddr, err := ids.ShortFromAddress(isa)
 		if err != nil {
 			return c, err
 		}
@@ -0,0 +1,181 @@
+// (c) 2019-2020, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package xchain
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/ava-labs/avalanchego/ids"
+	"github.com/ava-labs/avalanchego/utils/math"
+	"github.com/ava-labs/avalanchego/vms/avm"
+	"github.com/ava-labs/avalanchego/vms/components/avax"
+	"github.com/ava-labs/avalanchego/vms/secp256k1fx"
+)
+
+var (
+	errNoAlias          = errors.New("asset has no alias")
+	errDuplicateAlias   = errors.New("duplicated asset alias")
+	errNoInitialState   = errors.New("asset has no allocations or minters")
+	errZeroAllocation   = errors.New("allocation amount must be positive")
+	errSupplyOverflow   = errors.New("asset supply overflows uint64")
+	errNoMinters        = errors.New("minter set has no addresses")
+	errInvalidThreshold = errors.New("minter set threshold must be positive and at most the number of addresses")
+)
+
+// Allocation of an amount of an asset to an address
+type Allocation struct {
+	Address ids.ShortID
+	Amount  uint64
+}
+
+// MinterSet is a set of addresses that may mint more of an asset. Minting
+// requires the signatures of [Threshold] of the [Addresses].
+type MinterSet struct {
+	Threshold uint32
+	Addresses []ids.ShortID
+}
+
+// Asset that's created in the genesis state
+type Asset struct {
+	// Alias of the asset's ID on the chain, e.g. "AVAX"
+	Alias        string
+	Name         string
+	Symbol       string
+	Denomination byte
+	Memo         []byte
+
+	// FxID is the index, in the chain's fxs, of the secp256k1fx that the
+	// asset's initial state is owned with
+	FxID uint32
+
+	// Allocations of the asset's initial supply
+	Allocations []Allocation
+
+	// Sets of addresses that may mint more of the asset. If there are none,
+	// the asset's supply is fixed.
+	Minters []MinterSet
+}
+
+// Builder builds the genesis state of an X-chain
+type Builder struct {
+	networkID uint32
+	assets    []*Asset
+}
+
+// NewBuilder returns a builder of the genesis state of an X-chain on the
+// network [networkID]
+func NewBuilder(networkID uint32) *Builder {
+	return &Builder{networkID: networkID}
+}
+
+// AddAsset to the genesis state
+func (b *Builder) AddAsset(asset *Asset) {
+	b.assets = append(b.assets, asset)
+}
+
+// Validate the assets of the genesis state
+func (b *Builder) Validate() error {
+	aliases := make(map[string]struct{}, len(b.assets))
+	for _, asset := range b.assets {
+		if asset.Alias == "" {
+			return fmt.Errorf("%w: %q", errNoAlias, asset.Name)
+		}
+		if _, ok := aliases[asset.Alias]; ok {
+			return fmt.Errorf("%w: %q", errDuplicateAlias, asset.Alias)
+		}
+		aliases[asset.Alias] = struct{}{}
+
+		if err := asset.validate(); err != nil {
+			return fmt.Errorf("invalid asset %q: %w", asset.Alias, err)
+		}
+	}
+	return nil
+}
+
+// Build returns the serialized genesis state. The serialization doesn't depend
+// on the order that assets, allocations, or minter sets were added in.
+func (b *Builder) Build() ([]byte, error) {
+	if err := b.Validate(); err != nil {
+		return nil, err
+	}
+
+	g := avm.Genesis{
+		Txs: make([]*avm.GenesisAsset, len(b.assets)),
+	}
+	for i, asset := range b.assets {
+		g.Txs[i] = asset.genesisAsset(b.networkID)
+	}
+	return avm.MarshalGenesis(&g)
+}
+
+func (a *Asset) validate() error {
+	if err := avm.VerifyAssetDescription(a.Name, a.Symbol, a.Denomination); err != nil {
+		return err
+	}
+	if len(a.Allocations) == 0 && len(a.Minters) == 0 {
+		return errNoInitialState
+	}
+
+	supply := uint64(0)
+	for _, allocation := range a.Allocations {
+		if allocation.Amount == 0 {
+			return fmt.Errorf("%w: %s", errZeroAllocation, allocation.Address)
+		}
+		newSupply, err := math.Add64(supply, allocation.Amount)
+		if err != nil {
+			return errSupplyOverflow
+		}
+		supply = newSupply
+	}
+	for _, minters := range a.Minters {
+		if len(minters.Addresses) == 0 {
+			return errNoMinters
+		}
+		if minters.Threshold == 0 || int(minters.Threshold) > len(minters.Addresses) {
+			return fmt.Errorf("%w: %d of %d", errInvalidThreshold, minters.Threshold, len(minters.Addresses))
+		}
+	}
+	return nil
+}
+
+func (a *Asset) genesisAsset(networkID uint32) *avm.GenesisAsset {
+	initialState := &avm.InitialState{
+		FxID: a.FxID,
+	}
+	for _, allocation := range a.Allocations {
+		initialState.Outs = append(initialState.Outs, &secp256k1fx.TransferOutput{
+			Amt: allocation.Amount,
+			OutputOwners: secp256k1fx.OutputOwners{
+				Threshold: 1,
+				Addrs:     []ids.ShortID{allocation.Address},
+			},
+		})
+	}
+	for _, minters := range a.Minters {
+		out := &secp256k1fx.MintOutput{
+			OutputOwners: secp256k1fx.OutputOwners{
+				Threshold: minters.Threshold,
+				Addrs:     append([]ids.ShortID(nil), minters.Addresses...),
+			},
+		}
+		out.Sort()
+		initialState.Outs = append(initialState.Outs, out)
+	}
+
+	return &avm.GenesisAsset{
+		Alias: a.Alias,
+		CreateAssetTx: avm.CreateAssetTx{
+			BaseTx: avm.BaseTx{BaseTx: avax.BaseTx{
+				NetworkID:    networkID,
+				BlockchainID: ids.Empty,
+				Memo:         a.Memo,
+			}},
+			Name:         a.Name,
+			Symbol:       a.Symbol,
+			Denomination: a.Denomination,
+			States:       []*avm.InitialState{initialState},
+		},
+	}
+}
@@ -0,0 +1,199 @@
+// (c) 2019-2020, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package xchain
+
+import (
+	"errors"
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/ava-labs/avalanchego/ids"
+	"github.com/ava-labs/avalanchego/utils/constants"
+	"github.com/ava-labs/avalanchego/utils/formatting"
+	"github.com/ava-labs/avalanchego/utils/json"
+	"github.com/ava-labs/avalanchego/vms/avm"
+)
+
+func TestBuilderMatchesStaticService(t *testing.T) {
+	assert := assert.New(t)
+
+	addr0 := ids.ShortID{1}
+	addr1 := ids.ShortID{2}
+	hrp := constants.GetHRP(constants.LocalID)
+	addr0Str, err := formatting.FormatBech32(hrp, addr0[:])
+	assert.NoError(err)
+	addr1Str, err := formatting.FormatBech32(hrp, addr1[:])
+	assert.NoError(err)
+	memo := []byte{1, 2, 3}
+	memoStr, err := formatting.Encode(formatting.Hex, memo)
+	assert.NoError(err)
+
+	reply := avm.BuildGenesisReply{}
+	err = avm.CreateStaticService().BuildGenesis(nil, &avm.BuildGenesisArgs{
+		NetworkID: json.Uint32(constants.LocalID),
+		Encoding:  formatting.Hex,
+		GenesisData: map[string]avm.AssetDefinition{
+			"asset": {
+				Name:         "Asset",
+				Symbol:       "ASST",
+				Denomination: 9,
+				Memo:         memoStr,
+				InitialState: map[string][]interface{}{
+					"fixedCap": {
+						avm.Holder{Amount: 5, Address: addr0Str},
+						avm.Holder{Amount: 7, Address: addr1Str},
+					},
+				},
+			},
+		},
+	}, &reply)
+	assert.NoError(err)
+	expected, err := formatting.Decode(formatting.Hex, reply.Bytes)
+	assert.NoError(err)
+
+	b := NewBuilder(constants.LocalID)
+	b.AddAsset(&Asset{
+		Alias:        "asset",
+		Name:         "Asset",
+		Symbol:       "ASST",
+		Denomination: 9,
+		Memo:         memo,
+		Allocations: []Allocation{
+			{Address: addr1, Amount: 7},
+			{Address: addr0, Amount: 5},
+		},
+	})
+	genesisBytes, err := b.Build()
+	assert.NoError(err)
+	assert.Equal(expected, genesisBytes)
+}
+
+func TestBuilderDeterministic(t *testing.T) {
+	assert := assert.New(t)
+
+	fixedCap := &Asset{
+		Alias:  "fixed",
+		Name:   "Fixed",
+		Symbol: "FIX",
+		Allocations: []Allocation{
+			{Address: ids.ShortID{1}, Amount: 1},
+			{Address: ids.ShortID{2}, Amount: 2},
+		},
+	}
+	variableCap := &Asset{
+		Alias:  "variable",
+		Name:   "Variable",
+		Symbol: "VAR",
+		Minters: []MinterSet{
+			{Threshold: 2, Addresses: []ids.ShortID{{3}, {4}}},
+			{Threshold: 1, Addresses: []ids.ShortID{{5}}},
+		},
+	}
+
+	b0 := NewBuilder(constants.LocalID)
+	b0.AddAsset(fixedCap)
+	b0.AddAsset(variableCap)
+	genesis0, err := b0.Build()
+	assert.NoError(err)
+
+	b1 := NewBuilder(constants.LocalID)
+	b1.AddAsset(&Asset{
+		Alias:  "variable",
+		Name:   "Variable",
+		Symbol: "VAR",
+		Minters: []MinterSet{
+			{Threshold: 1, Addresses: []ids.ShortID{{5}}},
+			{Threshold: 2, Addresses: []ids.ShortID{{4}, {3}}},
+		},
+	})
+	b1.AddAsset(&Asset{
+		Alias:  "fixed",
+		Name:   "Fixed",
+		Symbol: "FIX",
+		Allocations: []Allocation{
+			{Address: ids.ShortID{2}, Amount: 2},
+			{Address: ids.ShortID{1}, Amount: 1},
+		},
+	})
+	genesis1, err := b1.Build()
+	assert.NoError(err)
+	assert.Equal(genesis0, genesis1)
+}
+
+func TestBuilderValidate(t *testing.T) {
+	allocations := []Allocation{{Address: ids.ShortID{1}, Amount: 1}}
+	tests := []struct {
+		name   string
+		assets []*Asset
+		err    error
+	}{
+		{
+			name:   "no alias",
+			assets: []*Asset{{Name: "Asset", Symbol: "ASST", Allocations: allocations}},
+			err:    errNoAlias,
+		},
+		{
+			name: "duplicated alias",
+			assets: []*Asset{
+				{Alias: "asset", Name: "Asset", Symbol: "ASST", Allocations: allocations},
+				{Alias: "asset", Name: "Other", Symbol: "OTHR", Allocations: allocations},
+			},
+			err: errDuplicateAlias,
+		},
+		{
+			name:   "no initial state",
+			assets: []*Asset{{Alias: "asset", Name: "Asset", Symbol: "ASST"}},
+			err:    errNoInitialState,
+		},
+		{
+			name: "zero allocation",
+			assets: []*Asset{{Alias: "asset", Name: "Asset", Symbol: "ASST", Allocations: []Allocation{
+				{Address: ids.ShortID{1}},
+			}}},
+			err: errZeroAllocation,
+		},
+		{
+			name: "supply overflow",
+			assets: []*Asset{{Alias: "asset", Name: "Asset", Symbol: "ASST", Allocations: []Allocation{
+				{Address: ids.ShortID{1}, Amount: math.MaxUint64},
+				{Address: ids.ShortID{2}, Amount: 1},
+			}}},
+			err: errSupplyOverflow,
+		},
+		{
+			name: "no minters",
+			assets: []*Asset{{Alias: "asset", Name: "Asset", Symbol: "ASST", Minters: []MinterSet{
+				{Threshold: 1},
+			}}},
+			err: errNoMinters,
+		},
+		{
+			name: "threshold too high",
+			assets: []*Asset{{Alias: "asset", Name: "Asset", Symbol: "ASST", Minters: []MinterSet{
+				{Threshold: 2, Addresses: []ids.ShortID{{1}}},
+			}}},
+			err: errInvalidThreshold,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			b := NewBuilder(constants.LocalID)
+			for _, asset := range test.assets {
+				b.AddAsset(asset)
+			}
+			err := b.Validate()
+			assert.True(t, errors.Is(err, test.err), "expected %q, got %v", test.err, err)
+
+			_, err = b.Build()
+			assert.True(t, errors.Is(err, test.err), "expected %q, got %v", test.err, err)
+		})
+	}
+
+	// The asset's description is verified like the AVM verifies it
+	b := NewBuilder(constants.LocalID)
+	b.AddAsset(&Asset{Alias: "asset", Name: "Asset", Symbol: "asset", Allocations: allocations})
+	assert.Error(t, b.Validate())
+}
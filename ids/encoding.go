@@ -0,0 +1,64 @@
+// (c) 2019-2020, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package ids
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/ava-labs/avalanchego/utils/formatting"
+)
+
+var errWrongHRP = errors.New("wrong bech32 HRP")
+
+// CB58 returns the CB58 encoding of the ID, which ends with a checksum. This
+// is the same as [String].
+func (id ID) CB58() string { return id.String() }
+
+// CB58 returns the CB58 encoding of the ID, which ends with a checksum. This
+// is the same as [String].
+func (id ShortID) CB58() string { return id.String() }
+
+// Bech32 returns the bech32 encoding of the ID with the human readable part
+// [hrp]
+func (id ShortID) Bech32(hrp string) (string, error) {
+	return formatting.FormatBech32(hrp, id[:])
+}
+
+// Address returns the ID formatted as an address on the chain aliased to
+// [chainIDAlias]. For example: X-avax1...
+func (id ShortID) Address(chainIDAlias, hrp string) (string, error) {
+	return formatting.FormatAddress(chainIDAlias, hrp, id[:])
+}
+
+// ShortFromBech32 is the inverse of ShortID.Bech32. If [hrp] is empty, any
+// human readable part is accepted.
+func ShortFromBech32(hrp, str string) (ShortID, error) {
+	parsedHRP, bytes, err := formatting.ParseBech32(str)
+	if err != nil {
+		return ShortID{}, err
+	}
+	if hrp != "" && parsedHRP != hrp {
+		return ShortID{}, fmt.Errorf("%w: expected %q but got %q", errWrongHRP, hrp, parsedHRP)
+	}
+	id, err := ToShortID(bytes)
+	if err != nil {
+		return ShortID{}, fmt.Errorf("couldn't parse bech32 ID %q: %w", str, err)
+	}
+	return id, nil
+}
+
+// ShortFromAddress is the inverse of ShortID.Address. Returns the alias of the
+// chain and the human readable part of the address, along with the ID.
+func ShortFromAddress(addrStr string) (string, string, ShortID, error) {
+	chainIDAlias, hrp, bytes, err := formatting.ParseAddress(addrStr)
+	if err != nil {
+		return "", "", ShortID{}, err
+	}
+	id, err := ToShortID(bytes)
+	if err != nil {
+		return "", "", ShortID{}, fmt.Errorf("couldn't parse address %q: %w", addrStr, err)
+	}
+	return chainIDAlias, hrp, id, nil
+}
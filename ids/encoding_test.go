@@ -0,0 +1,92 @@
+// (c) 2019-2020, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package ids
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestCB58(t *testing.T) {
+	id := ID{24}
+	parsedID, err := FromString(id.CB58())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if parsedID != id {
+		t.Fatalf("expected %s but got %s", id, parsedID)
+	}
+
+	shortID := ShortID{24}
+	parsedShortID, err := ShortFromString(shortID.CB58())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if parsedShortID != shortID {
+		t.Fatalf("expected %s but got %s", shortID, parsedShortID)
+	}
+
+	// Changing a character breaks the checksum
+	str := []byte(id.CB58())
+	str[0]++
+	if _, err := FromString(string(str)); err == nil {
+		t.Fatal("should have failed to parse a corrupted ID")
+	}
+}
+
+func TestBech32(t *testing.T) {
+	id := ShortID{1, 2, 3}
+	str, err := id.Bech32("avax")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	parsed, err := ShortFromBech32("avax", str)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if parsed != id {
+		t.Fatalf("expected %s but got %s", id, parsed)
+	}
+
+	// An empty HRP accepts any HRP
+	parsed, err = ShortFromBech32("", str)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if parsed != id {
+		t.Fatalf("expected %s but got %s", id, parsed)
+	}
+
+	if _, err := ShortFromBech32("fuji", str); !errors.Is(err, errWrongHRP) {
+		t.Fatalf("expected %s but got %v", errWrongHRP, err)
+	}
+	if _, err := ShortFromBech32("avax", str[:len(str)-1]); err == nil {
+		t.Fatal("should have failed to parse a truncated ID")
+	}
+}
+
+func TestAddress(t *testing.T) {
+	id := ShortID{1, 2, 3}
+	addr, err := id.Address("X", "avax")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	chainIDAlias, hrp, parsed, err := ShortFromAddress(addr)
+	switch {
+	case err != nil:
+		t.Fatal(err)
+	case chainIDAlias != "X":
+		t.Fatalf("expected chain alias %s but got %s", "X", chainIDAlias)
+	case hrp != "avax":
+		t.Fatalf("expected HRP %s but got %s", "avax", hrp)
+	case parsed != id:
+		t.Fatalf("expected %s but got %s", id, parsed)
+	}
+
+	if _, _, _, err := ShortFromAddress("avax1qqqq"); err == nil {
+		t.Fatal("should have failed to parse an address without a chain alias")
+	}
+}
@@ -39,7 +39,7 @@ func ToID(bytes []byte) (ID, error) {
 func FromString(idStr string) (ID, error) {
 	bytes, err := formatting.Decode(defaultEncoding, idStr)
 	if err != nil {
-		return ID{}, err
+		return ID{}, fmt.Errorf("couldn't parse ID %q: %w", idStr, err)
 	}
 	return ToID(bytes)
 }
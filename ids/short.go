@@ -29,7 +29,7 @@ func ToShortID(bytes []byte) (ShortID, error) {
 func ShortFromString(idStr string) (ShortID, error) {
 	bytes, err := formatting.Decode(defaultEncoding, idStr)
 	if err != nil {
-		return ShortID{}, err
+		return ShortID{}, fmt.Errorf("couldn't parse ID %q: %w", idStr, err)
 	}
 	return ToShortID(bytes)
 }
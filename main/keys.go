@@ -11,6 +11,12 @@ const (
 	networkNameKey                          = "network-id"
 	txFeeKey                                = "tx-fee"
 	creationTxFeeKey                        = "creation-tx-fee"
+	avmMaxTxSizeKey                         = "avm-max-tx-size"
+	avmMaxTxInputsKey                       = "avm-max-tx-inputs"
+	avmMaxTxOutputsKey                      = "avm-max-tx-outputs"
+	avmReindexKey                           = "avm-reindex"
+	avmAsyncSideEffectsKey                  = "avm-async-side-effects"
+	avmIndexMemosKey                        = "avm-index-memos"
 	uptimeRequirementKey                    = "uptime-requirement"
 	minValidatorStakeKey                    = "min-validator-stake"
 	maxValidatorStakeKey                    = "max-validator-stake"
@@ -23,6 +29,8 @@ const (
 	signatureVerificationEnabledKey         = "signature-verification-enabled"
 	dbEnabledKey                            = "db-enabled"
 	dbPathKey                               = "db-dir"
+	dbDiscardBackupsKey                     = "db-discard-migration-backups"
+	dbRollbackMigrationsKey                 = "db-rollback-migrations"
 	publicIPKey                             = "public-ip"
 	dynamicUpdateDurationKey                = "dynamic-update-duration"
 	dynamicPublicIPResolverKey              = "dynamic-public-ip"
@@ -39,15 +47,23 @@ const (
 	bootstrapIPsKey                         = "bootstrap-ips"
 	bootstrapIDsKey                         = "bootstrap-ids"
 	stakingPortKey                          = "staking-port"
+	stakingListenAddressesKey               = "staking-listen-addresses"
+	advertisedIPKey                         = "advertised-ip"
 	stakingEnabledKey                       = "staking-enabled"
 	p2pTLSEnabledKey                        = "p2p-tls-enabled"
+	p2pMACEnabledKey                        = "p2p-mac-enabled"
 	stakingKeyPathKey                       = "staking-tls-key-file"
 	stakingCertPathKey                      = "staking-tls-cert-file"
 	stakingDisabledWeightKey                = "staking-disabled-weight"
+	stakingPreviousKeyPathKey               = "staking-tls-previous-key-file"
+	stakingPreviousCertPathKey              = "staking-tls-previous-cert-file"
+	stakingRotationExpiryKey                = "staking-rotation-expiry"
 	maxNonStakerPendingMsgsKey              = "max-non-staker-pending-msgs"
 	stakerMsgReservedKey                    = "staker-msg-reserved"
 	stakerCPUReservedKey                    = "staker-cpu-reserved"
 	maxPendingMsgsKey                       = "max-pending-msgs"
+	parseBudgetRateKey                      = "parse-budget-rate"
+	parseBudgetBurstKey                     = "parse-budget-burst"
 	networkInitialTimeoutKey                = "network-initial-timeout"
 	networkMinimumTimeoutKey                = "network-minimum-timeout"
 	networkMaximumTimeoutKey                = "network-maximum-timeout"
@@ -59,7 +75,34 @@ const (
 	networkHealthMaxPortionSendQueueFillKey = "network-health-max-portion-send-queue-full"
 	networkHealthMaxSendFailRateKey         = "network-health-max-send-fail-rate"
 	networkHealthMaxOutstandingDurationKey  = "network-health-max-outstanding-request-duration"
+	networkHealthMaxClockSkewKey            = "network-health-max-clock-skew"
 	sendQueueSizeKey                        = "send-queue-size"
+	sendQueueConsensusPortionKey            = "send-queue-consensus-portion"
+	sendQueueConsensusDropPolicyKey         = "send-queue-consensus-drop-policy"
+	sendQueueGossipPortionKey               = "send-queue-gossip-portion"
+	sendQueueGossipDropPolicyKey            = "send-queue-gossip-drop-policy"
+	sendQueueBootstrapPortionKey            = "send-queue-bootstrap-portion"
+	sendQueueBootstrapDropPolicyKey         = "send-queue-bootstrap-drop-policy"
+	bandwidthPeerInboundRateKey             = "bandwidth-peer-inbound-rate"
+	bandwidthPeerInboundBurstKey            = "bandwidth-peer-inbound-burst"
+	bandwidthPeerOutboundRateKey            = "bandwidth-peer-outbound-rate"
+	bandwidthPeerOutboundBurstKey           = "bandwidth-peer-outbound-burst"
+	bandwidthInboundRateKey                 = "bandwidth-inbound-rate"
+	bandwidthInboundBurstKey                = "bandwidth-inbound-burst"
+	bandwidthOutboundRateKey                = "bandwidth-outbound-rate"
+	bandwidthOutboundBurstKey               = "bandwidth-outbound-burst"
+	networkGossipSizeKey                    = "network-gossip-size"
+	networkPeerListGossipFreqKey            = "network-peer-list-gossip-frequency"
+	networkGossipValidatorsOnlyKey          = "network-gossip-validators-only"
+	networkGossipDuplicateCacheSizeKey      = "network-gossip-duplicate-cache-size"
+	networkGossipDuplicateWindowKey         = "network-gossip-duplicate-window"
+	networkDialTimeoutKey                   = "network-dial-timeout"
+	networkMaxPendingDialsKey               = "network-max-pending-dials"
+	networkMaxPeersKey                      = "network-max-peers"
+	networkSubnetConnectionBudgetsKey       = "network-subnet-connection-budgets"
+	networkMaxMessageSizeKey                = "network-max-message-size"
+	networkMinCompatibleVersionKey          = "network-min-compatible-version"
+	networkMinRecommendedVersionKey         = "network-min-recommended-version"
 	benchlistFailThresholdKey               = "benchlist-fail-threshold"
 	benchlistPeerSummaryEnabledKey          = "benchlist-peer-summary-enabled"
 	benchlistDurationKey                    = "benchlist-duration"
@@ -69,6 +112,7 @@ const (
 	logLevelKey                             = "log-level"
 	logDisplayLevelKey                      = "log-display-level"
 	logDisplayHighlightKey                  = "log-display-highlight"
+	snowConsensusProfileKey                 = "snow-consensus-profile"
 	snowSampleSizeKey                       = "snow-sample-size"
 	snowQuorumSizeKey                       = "snow-quorum-size"
 	snowVirtuousCommitThresholdKey          = "snow-virtuous-commit-threshold"
@@ -76,7 +120,19 @@ const (
 	snowAvalancheNumParentsKey              = "snow-avalanche-num-parents"
 	snowAvalancheBatchSizeKey               = "snow-avalanche-batch-size"
 	snowConcurrentRepollsKey                = "snow-concurrent-repolls"
+	snowMaxConcurrentRepollsKey             = "snow-max-concurrent-repolls"
 	snowOptimalProcessingKey                = "snow-optimal-processing"
+	snowJustifyChitsKey                     = "snow-justify-chits"
+	snowGossipAcceptedTxsKey                = "snow-gossip-accepted-txs"
+	snowMaxOutstandingGetsKey               = "snow-max-outstanding-gets"
+	snowMaxOutstandingGetsPerPeerKey        = "snow-max-outstanding-gets-per-peer"
+	snowMaxQueuedGetsKey                    = "snow-max-queued-gets"
+	snowHeartbeatFrequencyKey               = "snow-heartbeat-frequency"
+	snowAvalancheStaleDepthKey              = "snow-avalanche-stale-depth"
+	snowAvalancheRefuseStaleParentsKey      = "snow-avalanche-refuse-stale-parents"
+	snowRandomSeedKey                       = "snow-random-seed"
+	snowAvalancheEdgePolicyKey              = "snow-avalanche-edge-policy"
+	snowAvalancheEdgeIntervalKey            = "snow-avalanche-edge-interval"
 	snowMaxProcessingKey                    = "snow-max-processing"
 	snowMaxTimeProcessingKey                = "snow-max-time-processing"
 	snowEpochFirstTransition                = "snow-epoch-first-transition"
@@ -86,12 +142,17 @@ const (
 	infoAPIEnabledKey                       = "api-info-enabled"
 	keystoreAPIEnabledKey                   = "api-keystore-enabled"
 	metricsAPIEnabledKey                    = "api-metrics-enabled"
+	metricsSnapshotDirKey                   = "metrics-snapshot-dir"
+	metricsSnapshotFrequencyKey             = "metrics-snapshot-frequency"
+	metricsSnapshotCountKey                 = "metrics-snapshot-count"
 	healthAPIEnabledKey                     = "api-health-enabled"
 	ipcAPIEnabledKey                        = "api-ipcs-enabled"
 	xputServerPortKey                       = "xput-server-port"
 	xputServerEnabledKey                    = "xput-server-enabled"
 	ipcsChainIDsKey                         = "ipcs-chain-ids"
 	ipcsPathKey                             = "ipcs-path"
+	acceptorWebhookURLsKey                  = "acceptor-webhook-urls"
+	acceptorWebhookTimeoutKey               = "acceptor-webhook-timeout"
 	consensusGossipFrequencyKey             = "consensus-gossip-frequency"
 	consensusShutdownTimeoutKey             = "consensus-shutdown-timeout"
 	fdLimitKey                              = "fd-limit"
@@ -105,5 +166,10 @@ const (
 	healthCheckAveragerHalflifeKey          = "health-check-averager-halflife"
 	retryBootstrap                          = "bootstrap-retry-enabled"
 	retryBootstrapMaxAttempts               = "bootstrap-retry-max-attempts"
+	chainRestartBudgetKey                   = "chain-restart-budget"
+	chainHashFunctionsKey                   = "chain-hash-functions"
 	peerAliasTimeoutKey                     = "peer-alias-timeout"
+	proxyKey                                = "proxy"
+	peerProxiesKey                          = "peer-proxies"
+	proxyDialTimeoutKey                     = "proxy-dial-timeout"
 )
@@ -5,6 +5,7 @@ package main
 
 import (
 	"fmt"
+	"time"
 
 	"github.com/ava-labs/avalanchego/database"
 	"github.com/ava-labs/avalanchego/database/leveldb"
@@ -15,6 +16,7 @@ import (
 	"github.com/ava-labs/avalanchego/utils/constants"
 	"github.com/ava-labs/avalanchego/utils/crypto"
 	"github.com/ava-labs/avalanchego/utils/dynamicip"
+	"github.com/ava-labs/avalanchego/utils/lifecycle"
 	"github.com/ava-labs/avalanchego/utils/logging"
 	"github.com/ava-labs/avalanchego/utils/perms"
 )
@@ -29,6 +31,12 @@ const (
 		`          \/           \/          \/     \/     \/     \/     \/`
 )
 
+// Deadlines for shutting down the components started by main
+const (
+	dbShutdownTimeout  = time.Minute
+	natShutdownTimeout = 10 * time.Second
+)
+
 var (
 	stakingPortName = fmt.Sprintf("%s-staking", constants.AppName)
 	httpPortName    = fmt.Sprintf("%s-http", constants.AppName)
@@ -49,15 +57,32 @@ func main() {
 	}
 
 	logFactory := logging.NewFactory(Config.LoggingConfig)
-	defer logFactory.Close()
-
 	log, err := logFactory.Make()
 	if err != nil {
+		logFactory.Close()
 		fmt.Printf("starting logger failed with: %s\n", err)
 		return
 	}
 	fmt.Println(header)
 
+	defer func() {
+		if r := recover(); r != nil {
+			fmt.Println("Recovered panic from", r)
+		}
+	}()
+
+	// The components started here are shut down once the node exits. The
+	// logs are flushed last, so failures to shut down are still logged.
+	shutdownManager := lifecycle.NewManager(log)
+	shutdownManager.OnFlush(func() {
+		log.Stop()
+		logFactory.Close()
+	})
+	defer func() {
+		// Failures are logged by the manager
+		_ = shutdownManager.Shutdown()
+	}()
+
 	var db database.Database
 	if Config.DBEnabled {
 		db, err = leveldb.New(Config.DBPath, log, 0, 0, 0)
@@ -68,20 +93,10 @@ func main() {
 	} else {
 		db = memdb.New()
 	}
-
-	defer func() {
-		if r := recover(); r != nil {
-			fmt.Println("Recovered panic from", r)
-		}
-	}()
-
-	defer func() {
-		if err := db.Close(); err != nil {
-			log.Warn("failed to close the node's DB: %s", err)
-		}
-		log.StopOnPanic()
-		log.Stop()
-	}()
+	if err := shutdownManager.Register("database", dbShutdownTimeout, db.Close); err != nil {
+		log.Error("couldn't register the database for shutdown: %s", err)
+		return
+	}
 
 	// Track if sybil control is enforced
 	if !Config.EnableStaking && Config.EnableP2PTLS {
@@ -115,7 +130,13 @@ func main() {
 	}
 
 	mapper := nat.NewPortMapper(log, Config.Nat)
-	defer mapper.UnmapAllPorts()
+	if err := shutdownManager.Register("nat", natShutdownTimeout, func() error {
+		mapper.UnmapAllPorts()
+		return nil
+	}); err != nil {
+		log.Error("couldn't register the port mapper for shutdown: %s", err)
+		return
+	}
 
 	// Open staking port we want for NAT Traversal to have the external port
 	// (Config.StakingIP.Port) to connect to our internal listening port
@@ -150,7 +171,13 @@ func main() {
 		log,
 		&Config.StakingIP,
 	)
-	defer externalIPUpdater.Stop()
+	if err := shutdownManager.Register("dynamic-ip", natShutdownTimeout, func() error {
+		externalIPUpdater.Stop()
+		return nil
+	}); err != nil {
+		log.Error("couldn't register the IP updater for shutdown: %s", err)
+		return
+	}
 
 	log.Info("this node's IP is set to: %s", Config.StakingIP.IP())
 
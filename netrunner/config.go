@@ -0,0 +1,154 @@
+// (c) 2019-2020, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package netrunner
+
+import (
+	"time"
+
+	"github.com/ava-labs/avalanchego/genesis"
+	"github.com/ava-labs/avalanchego/ids"
+	"github.com/ava-labs/avalanchego/ipcs"
+	"github.com/ava-labs/avalanchego/nat"
+	"github.com/ava-labs/avalanchego/network"
+	"github.com/ava-labs/avalanchego/node"
+	"github.com/ava-labs/avalanchego/snow/consensus/avalanche"
+	"github.com/ava-labs/avalanchego/snow/consensus/snowball"
+	"github.com/ava-labs/avalanchego/snow/networking/router"
+	"github.com/ava-labs/avalanchego/utils/constants"
+	"github.com/ava-labs/avalanchego/utils/dynamicip"
+	"github.com/ava-labs/avalanchego/utils/logging"
+)
+
+// Config of a local network
+type Config struct {
+	// Number of nodes in the network. Must be positive.
+	NumNodes int
+
+	// Directory that the nodes' logs and staking keys are written to. If
+	// empty, a temporary directory is created and removed on shutdown.
+	Dir string
+
+	// Directory that VM plugins are loaded from. If the evm plugin isn't in
+	// it, the nodes run without a C-chain.
+	PluginDir string
+
+	// Level that the nodes log at
+	LogLevel logging.Level
+
+	// If non-nil, called with the configuration of each node before the node
+	// is started
+	ConfigureNode func(index int, config *node.Config)
+}
+
+// DefaultNodeConfig returns the configuration of a node on the local network
+// that uses the same defaults as avalanchego's flags, except that:
+// * staking is disabled, so the nodes validate for each other once connected
+// * consensus only samples a single node
+// * the node doesn't persist its state, traverse NATs or resolve its public IP
+// The staking port, HTTP port, staking key and bootstrap peers are set by the
+// network.
+func DefaultNodeConfig() (node.Config, error) {
+	genesisBytes, avaxAssetID, err := genesis.Genesis(constants.LocalID, "")
+	if err != nil {
+		return node.Config{}, err
+	}
+	loggingConfig, err := logging.DefaultConfig()
+	if err != nil {
+		return node.Config{}, err
+	}
+	loggingConfig.DisplayLevel = logging.Off
+	consensusParams, err := avalanche.Profile(snowball.LocalFastProfile)
+	if err != nil {
+		return node.Config{}, err
+	}
+
+	config := node.Config{
+		Params:       *genesis.GetParams(constants.LocalID),
+		GenesisBytes: genesisBytes,
+		AvaxAssetID:  avaxAssetID,
+		Nat:          nat.NewNoRouter(),
+		NetworkID:    constants.LocalID,
+
+		EnableAssertions: true,
+		EnableCrypto:     true,
+
+		EnableP2PTLS:          true,
+		DisabledStakingWeight: 1,
+
+		MaxNonStakerPendingMsgs: router.DefaultMaxNonStakerPendingMsgs,
+		StakerMSGPortion:        router.DefaultStakerPortion,
+		StakerCPUPortion:        router.DefaultStakerPortion,
+		ParseBudgetRate:         router.DefaultParseBudgetRate,
+		ParseBudgetBurst:        router.DefaultParseBudgetBurst,
+		SendQueueSize:           4096,
+		SendQueueConfig:         network.DefaultSendQueueConfig,
+		GossipConfig:            network.DefaultGossipConfig,
+		MaxPendingMsgs:          4096,
+
+		HealthCheckFreq: 30 * time.Second,
+
+		HTTPHost:          "127.0.0.1",
+		APIAllowedOrigins: []string{"*"},
+
+		AdminAPIEnabled:    true,
+		InfoAPIEnabled:     true,
+		KeystoreAPIEnabled: true,
+		MetricsAPIEnabled:  true,
+		HealthAPIEnabled:   true,
+
+		LoggingConfig: loggingConfig,
+
+		ConsensusParams: consensusParams,
+
+		IPCPath: ipcs.DefaultBaseURL,
+
+		ConsensusRouter:          &router.ChainRouter{},
+		ConsensusGossipFrequency: 10 * time.Second,
+		ConsensusShutdownTimeout: 5 * time.Second,
+
+		DynamicUpdateDuration:   5 * time.Minute,
+		DynamicPublicIPResolver: dynamicip.NewResolver(""),
+
+		ConnMeterMaxConns: 5,
+
+		WhitelistedSubnets: ids.Set{},
+
+		DisconnectedCheckFreq:      10 * time.Second,
+		DisconnectedRestartTimeout: time.Minute,
+
+		RetryBootstrap:            true,
+		RetryBootstrapMaxAttempts: 50,
+		MaxOutstandingGets:        1024,
+		MaxOutstandingGetsPerPeer: 256,
+		MaxQueuedGets:             4096,
+		HeartbeatFrequency:        30 * time.Second,
+		PeerAliasTimeout:          10 * time.Minute,
+	}
+	config.WhitelistedSubnets.Add(constants.PrimaryNetworkID)
+
+	config.NetworkConfig.InitialTimeout = 5 * time.Second
+	config.NetworkConfig.MinimumTimeout = 2 * time.Second
+	config.NetworkConfig.MaximumTimeout = 10 * time.Second
+	config.NetworkConfig.TimeoutHalflife = 5 * time.Minute
+	config.NetworkConfig.TimeoutCoefficient = 2
+
+	config.NetworkHealthConfig.MaxTimeSinceMsgSent = time.Minute
+	config.NetworkHealthConfig.MaxTimeSinceMsgReceived = time.Minute
+	config.NetworkHealthConfig.MaxPortionSendQueueBytesFull = .9
+	config.NetworkHealthConfig.MinConnectedPeers = 1
+	config.NetworkHealthConfig.MaxSendFailRate = .9
+	config.NetworkHealthConfig.MaxSendFailRateHalflife = 10 * time.Second
+
+	config.RouterHealthConfig.MaxDropRate = 1
+	config.RouterHealthConfig.MaxOutstandingRequests = 1024
+	config.RouterHealthConfig.MaxOutstandingDuration = 5 * time.Minute
+	config.RouterHealthConfig.MaxRunTimeRequests = config.NetworkConfig.MaximumTimeout
+	config.RouterHealthConfig.MaxDropRateHalflife = 10 * time.Second
+
+	config.BenchlistConfig.Threshold = 10
+	config.BenchlistConfig.Duration = 30 * time.Minute
+	config.BenchlistConfig.MinimumFailingDuration = 5 * time.Minute
+	config.BenchlistConfig.MaxPortion = (1.0 - (float64(config.ConsensusParams.Alpha) / float64(config.ConsensusParams.K))) / 3.0
+	return config, nil
+}
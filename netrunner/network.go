@@ -0,0 +1,362 @@
+// (c) 2019-2020, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+// Package netrunner runs a local network of in-process nodes, so that the
+// consensus engines, networking and VMs can be tested end to end.
+package netrunner
+
+import (
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net"
+	"os"
+	"path/filepath"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/ava-labs/avalanchego/api/info"
+	"github.com/ava-labs/avalanchego/database/memdb"
+	"github.com/ava-labs/avalanchego/ids"
+	"github.com/ava-labs/avalanchego/node"
+	"github.com/ava-labs/avalanchego/snow/choices"
+	"github.com/ava-labs/avalanchego/staking"
+	"github.com/ava-labs/avalanchego/utils"
+	"github.com/ava-labs/avalanchego/utils/logging"
+	"github.com/ava-labs/avalanchego/vms/avm"
+)
+
+const (
+	// Timeout of the API requests made to the nodes
+	requestTimeout = 10 * time.Second
+
+	// How often the nodes are polled while awaiting a condition
+	pollFrequency = 100 * time.Millisecond
+
+	// Number of times a node is started with new ports, if its ports were
+	// taken before it could bind them
+	maxStartAttempts = 5
+
+	// How long a started node has to begin serving its API
+	serveTimeout = 10 * time.Second
+)
+
+var (
+	errNoNodes   = errors.New("network must have at least one node")
+	errTimedOut  = errors.New("timed out")
+	errRejected  = errors.New("rejected")
+	errPortInUse = errors.New("port already in use")
+)
+
+// Node is a node of a local network
+type Node struct {
+	// Index of the node in the network
+	Index int
+	// ID of the node
+	ID ids.ShortID
+	// Ports the node listens on
+	StakingPort, HTTPPort uint16
+
+	config     node.Config
+	node       node.Node
+	log        logging.Logger
+	logFactory logging.Factory
+	// Closed once the node has shut down
+	done chan struct{}
+}
+
+// URI of the node's HTTP API
+func (n *Node) URI() string { return fmt.Sprintf("http://127.0.0.1:%d", n.HTTPPort) }
+
+// Info returns a client of the node's info API
+func (n *Node) Info() *info.Client { return info.NewClient(n.URI(), requestTimeout) }
+
+// XChain returns a client of the node's X-chain API
+func (n *Node) XChain() *avm.Client { return avm.NewClient(n.URI(), "X", requestTimeout) }
+
+// Network of in-process nodes on the local network. The first node is the
+// bootstrap beacon of the others.
+type Network struct {
+	nodes []*Node
+
+	// Directory that's removed on shutdown, if the network created it
+	tempDir string
+
+	// Ports that were handed out to the nodes
+	ports map[uint16]bool
+
+	shutdownOnce sync.Once
+}
+
+// New starts a local network configured by [config]. The returned network
+// should be shut down once it's no longer needed.
+func New(config Config) (*Network, error) {
+	if config.NumNodes <= 0 {
+		return nil, errNoNodes
+	}
+
+	n := &Network{ports: make(map[uint16]bool)}
+	dir := config.Dir
+	if dir == "" {
+		tempDir, err := ioutil.TempDir("", "netrunner")
+		if err != nil {
+			return nil, fmt.Errorf("couldn't create network directory: %w", err)
+		}
+		n.tempDir = tempDir
+		dir = tempDir
+	}
+
+	for i := 0; i < config.NumNodes; i++ {
+		if err := n.add(i, dir, config); err != nil {
+			n.Shutdown()
+			return nil, err
+		}
+	}
+	return n, nil
+}
+
+// add the [index]th node to the network. The ports of the node are only
+// reserved until they're picked, so another process may bind them before the
+// node does. If so, the node is started again with new ports.
+func (n *Network) add(index int, dir string, config Config) error {
+	for attempt := 1; ; attempt++ {
+		nodeConfig, err := n.nodeConfig(index, dir, config)
+		if err != nil {
+			return err
+		}
+		if config.ConfigureNode != nil {
+			config.ConfigureNode(index, &nodeConfig)
+		}
+		err = n.start(index, nodeConfig)
+		if err == nil {
+			return nil
+		}
+		if !errors.Is(err, errPortInUse) || attempt == maxStartAttempts {
+			return fmt.Errorf("couldn't start node %d: %w", index, err)
+		}
+	}
+}
+
+// Nodes of the network, in the order that they were started
+func (n *Network) Nodes() []*Node { return n.nodes }
+
+// nodeConfig returns the configuration of the [index]th node of the network
+func (n *Network) nodeConfig(index int, dir string, config Config) (node.Config, error) {
+	nodeConfig, err := DefaultNodeConfig()
+	if err != nil {
+		return node.Config{}, err
+	}
+
+	nodeDir := filepath.Join(dir, fmt.Sprintf("node%d", index))
+	nodeConfig.LoggingConfig.Directory = filepath.Join(nodeDir, "logs")
+	nodeConfig.LoggingConfig.LogLevel = config.LogLevel
+	nodeConfig.PluginDir = config.PluginDir
+
+	nodeConfig.StakingKeyFile = filepath.Join(nodeDir, "staking", "staker.key")
+	nodeConfig.StakingCertFile = filepath.Join(nodeDir, "staking", "staker.crt")
+	if err := staking.GenerateStakingKeyCert(nodeConfig.StakingKeyFile, nodeConfig.StakingCertFile); err != nil {
+		return node.Config{}, fmt.Errorf("couldn't generate staking key/cert: %w", err)
+	}
+
+	stakingPort, err := n.freePort()
+	if err != nil {
+		return node.Config{}, err
+	}
+	httpPort, err := n.freePort()
+	if err != nil {
+		return node.Config{}, err
+	}
+	nodeConfig.StakingIP = utils.NewDynamicIPDesc(net.ParseIP("127.0.0.1"), stakingPort)
+	nodeConfig.HTTPPort = httpPort
+
+	if index > 0 {
+		beacon := n.nodes[0]
+		nodeConfig.BootstrapPeers = []*node.Peer{{
+			ID: beacon.ID,
+			IP: beacon.config.StakingIP.IP(),
+		}}
+	}
+	return nodeConfig, nil
+}
+
+// start the [index]th node of the network
+func (n *Network) start(index int, config node.Config) error {
+	logFactory := logging.NewFactory(config.LoggingConfig)
+	log, err := logFactory.Make()
+	if err != nil {
+		logFactory.Close()
+		return err
+	}
+
+	nd := &Node{
+		Index:       index,
+		StakingPort: config.StakingIP.IP().Port,
+		HTTPPort:    config.HTTPPort,
+		config:      config,
+		log:         log,
+		logFactory:  logFactory,
+		done:        make(chan struct{}),
+	}
+	if err := nd.node.Initialize(&nd.config, memdb.New(), log, logFactory, nd); err != nil {
+		nd.node.Shutdown()
+		nd.close()
+		if errors.Is(err, syscall.EADDRINUSE) {
+			return fmt.Errorf("%w: %s", errPortInUse, err)
+		}
+		return err
+	}
+	nd.ID = nd.node.ID
+
+	go func() {
+		defer nd.close()
+		if err := nd.node.Dispatch(); err != nil {
+			log.Debug("node dispatch returned: %s", err)
+		}
+	}()
+
+	// The API server binds its port once the node is dispatched, and the node
+	// shuts down if it can't
+	if err := nd.awaitServing(time.Now().Add(serveTimeout)); err != nil {
+		nd.node.Shutdown()
+		<-nd.done
+		return err
+	}
+	n.nodes = append(n.nodes, nd)
+	return nil
+}
+
+// awaitServing waits until the node's API server accepts connections, or until
+// [deadline] passes. Returns [errPortInUse] if the node shut down first.
+func (n *Node) awaitServing(deadline time.Time) error {
+	addr := fmt.Sprintf("127.0.0.1:%d", n.HTTPPort)
+	for {
+		select {
+		case <-n.done:
+			return fmt.Errorf("%w: node shut down before serving its API on %s", errPortInUse, addr)
+		default:
+		}
+		conn, err := net.Dial("tcp", addr)
+		if err == nil {
+			return conn.Close()
+		}
+		if time.Now().After(deadline) {
+			return err
+		}
+		time.Sleep(pollFrequency)
+	}
+}
+
+// Restart implements utils.Restarter. Nodes of a local network aren't
+// restarted, so the node is shut down.
+func (n *Node) Restart() { n.node.Shutdown() }
+
+func (n *Node) close() {
+	n.log.Stop()
+	n.logFactory.Close()
+	close(n.done)
+}
+
+// AwaitBootstrapped waits until every node has bootstrapped each of [chains],
+// which are chain IDs or aliases, or until [timeout] elapses.
+func (n *Network) AwaitBootstrapped(timeout time.Duration, chains ...string) error {
+	deadline := time.Now().Add(timeout)
+	for _, nd := range n.nodes {
+		client := nd.Info()
+		for _, chain := range chains {
+			err := await(deadline, func() (bool, error) {
+				return client.IsBootstrapped(chain)
+			})
+			if err != nil {
+				return fmt.Errorf("node %d didn't bootstrap %s: %w", nd.Index, chain, err)
+			}
+		}
+	}
+	return nil
+}
+
+// IssueTx issues [txBytes] to the X-chain of the first node, and waits until
+// every node has accepted it, or until [timeout] elapses.
+func (n *Network) IssueTx(txBytes []byte, timeout time.Duration) (ids.ID, error) {
+	txID, err := n.nodes[0].XChain().IssueTx(txBytes)
+	if err != nil {
+		return ids.ID{}, err
+	}
+	return txID, n.AwaitTx(txID, timeout)
+}
+
+// AwaitTx waits until every node has accepted the X-chain tx [txID], or until
+// [timeout] elapses. Returns an error if a node rejects the tx.
+func (n *Network) AwaitTx(txID ids.ID, timeout time.Duration) error {
+	deadline := time.Now().Add(timeout)
+	for _, nd := range n.nodes {
+		client := nd.XChain()
+		status := choices.Unknown
+		err := await(deadline, func() (bool, error) {
+			var err error
+			status, err = client.GetTxStatus(txID)
+			return status.Decided(), err
+		})
+		if err != nil {
+			return fmt.Errorf("node %d didn't accept %s: %w", nd.Index, txID, err)
+		}
+		if status != choices.Accepted {
+			return fmt.Errorf("node %d %w %s", nd.Index, errRejected, txID)
+		}
+	}
+	return nil
+}
+
+// Shutdown every node of the network, and wait for them to shut down. Safe to
+// call multiple times.
+func (n *Network) Shutdown() {
+	n.shutdownOnce.Do(func() {
+		for _, nd := range n.nodes {
+			nd.node.Shutdown()
+		}
+		for _, nd := range n.nodes {
+			<-nd.done
+		}
+		if n.tempDir != "" {
+			_ = os.RemoveAll(n.tempDir)
+		}
+	})
+}
+
+// await polls [done] until it returns true or [deadline] passes. Errors
+// returned by [done] are treated as the condition not being met, because the
+// node may not be serving the request yet, unless [deadline] has passed.
+func await(deadline time.Time, done func() (bool, error)) error {
+	for {
+		ok, err := done()
+		if err == nil && ok {
+			return nil
+		}
+		if time.Now().After(deadline) {
+			if err != nil {
+				return err
+			}
+			return errTimedOut
+		}
+		time.Sleep(pollFrequency)
+	}
+}
+
+// freePort returns a port that's currently unused, and that wasn't already
+// handed out to a node of the network
+func (n *Network) freePort() (uint16, error) {
+	for {
+		listener, err := net.Listen("tcp", ":0")
+		if err != nil {
+			return 0, fmt.Errorf("couldn't find a free port: %w", err)
+		}
+		port := uint16(listener.Addr().(*net.TCPAddr).Port)
+		if err := listener.Close(); err != nil {
+			return 0, err
+		}
+		if !n.ports[port] {
+			n.ports[port] = true
+			return port, nil
+		}
+	}
+}
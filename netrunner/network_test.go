@@ -0,0 +1,66 @@
+// (c) 2019-2020, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package netrunner
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/ava-labs/avalanchego/api"
+	"github.com/ava-labs/avalanchego/api/keystore"
+	"github.com/ava-labs/avalanchego/utils/logging"
+)
+
+const (
+	// Key that's allocated AVAX on the X-chain by the local genesis
+	ewoqKey  = "PrivateKey-ewoqjP7PxY4yr3iLTpLisriqt94hdyDFNgchSxGGztUrTXtNN"
+	ewoqAddr = "X-local18jma8ppw3nhx5r4ap8clazz0dps7rv5u00z96u"
+
+	testTimeout = time.Minute
+)
+
+func TestNewNoNodes(t *testing.T) {
+	_, err := New(Config{})
+	assert.Equal(t, errNoNodes, err)
+}
+
+func TestNetworkAcceptsTx(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping local network test in short mode")
+	}
+	assert := assert.New(t)
+
+	network, err := New(Config{
+		NumNodes: 3,
+		LogLevel: logging.Off,
+	})
+	assert.NoError(err)
+	defer network.Shutdown()
+
+	nodes := network.Nodes()
+	assert.Len(nodes, 3)
+	assert.NoError(network.AwaitBootstrapped(testTimeout, "P", "X"))
+
+	user := api.UserPass{Username: "netrunner", Password: "Tr0ub4dor&3xyzw"}
+	_, err = keystore.NewClient(nodes[0].URI(), requestTimeout).CreateUser(user)
+	assert.NoError(err)
+	xChain := nodes[0].XChain()
+	_, err = xChain.ImportKey(user, ewoqKey)
+	assert.NoError(err)
+	to, err := xChain.CreateAddress(user)
+	assert.NoError(err)
+
+	txID, err := xChain.Send(user, []string{ewoqAddr}, ewoqAddr, 1, "AVAX", to, "")
+	assert.NoError(err)
+	assert.NoError(network.AwaitTx(txID, testTimeout))
+
+	// The tx was gossiped to, and accepted by, every node
+	for _, node := range nodes[1:] {
+		reply, err := node.XChain().GetBalance(to, "AVAX", false)
+		assert.NoError(err)
+		assert.EqualValues(1, reply.Balance)
+	}
+}
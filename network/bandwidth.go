@@ -0,0 +1,62 @@
+// (c) 2019-2020, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package network
+
+import (
+	"time"
+
+	"github.com/ava-labs/avalanchego/ids"
+	"github.com/ava-labs/avalanchego/snow/networking/tracker"
+)
+
+// throttleInterval is how long a peer's reader, or writer, waits before
+// checking again whether it has bandwidth
+const throttleInterval = 10 * time.Millisecond
+
+// BandwidthConfig limits the rate, in bytes per second, at which messages are
+// read from and written to peers. Each rate may be exceeded by its burst at
+// once. A rate of 0 means that the bandwidth isn't limited.
+type BandwidthConfig struct {
+	// Limits on the messages read from, and written to, each peer
+	PeerInboundRate, PeerInboundBurst   uint64
+	PeerOutboundRate, PeerOutboundBurst uint64
+
+	// Limits on the messages read from, and written to, all peers
+	InboundRate, InboundBurst   uint64
+	OutboundRate, OutboundBurst uint64
+}
+
+// bandwidthLimiter tracks the bandwidth used in one direction
+type bandwidthLimiter struct {
+	// per peer
+	peer tracker.CostTracker
+	// across all peers, which are charged as [ids.ShortEmpty]
+	global tracker.CostTracker
+}
+
+func newBandwidthLimiter(peerRate, peerBurst, rate, burst uint64) bandwidthLimiter {
+	return bandwidthLimiter{
+		peer:   tracker.NewCostTracker(peerRate, peerBurst),
+		global: tracker.NewCostTracker(rate, burst),
+	}
+}
+
+// wait blocks until [p] has bandwidth left, and then charges it, and the
+// global budget, [size] bytes. Returns true if [p] had to wait.
+func (l bandwidthLimiter) wait(p *peer, size int) bool {
+	throttled := false
+	for !p.closed.GetValue() {
+		now := p.net.clock.Time()
+		if !l.peer.Exhausted(p.id, now) && !l.global.Exhausted(ids.ShortEmpty, now) {
+			break
+		}
+		throttled = true
+		time.Sleep(throttleInterval)
+	}
+
+	now := p.net.clock.Time()
+	l.peer.Charge(p.id, uint64(size), now)
+	l.global.Charge(ids.ShortEmpty, uint64(size), now)
+	return throttled
+}
@@ -0,0 +1,48 @@
+// (c) 2019-2020, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package network
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/ava-labs/avalanchego/ids"
+	"github.com/ava-labs/avalanchego/utils"
+	"github.com/ava-labs/avalanchego/utils/logging"
+)
+
+func TestBandwidthLimiterWaits(t *testing.T) {
+	net := &network{log: logging.NoLog{}}
+	peer0 := newPeer(net, nil, utils.IPDesc{})
+	peer0.id = ids.ShortID{1}
+	peer1 := newPeer(net, nil, utils.IPDesc{})
+	peer1.id = ids.ShortID{2}
+
+	// each peer may burst 100 bytes
+	limiter := newBandwidthLimiter(1000, 100, 0, 0)
+
+	// a peer may exceed its budget with a single message
+	assert.False(t, limiter.wait(peer0, 110))
+	// but then has to wait for its budget to be replenished
+	assert.True(t, limiter.wait(peer0, 10))
+	// other peers have their own budget
+	assert.False(t, limiter.wait(peer1, 10))
+
+	// the peers may burst 100 bytes together
+	limiter = newBandwidthLimiter(0, 0, 1000, 100)
+
+	assert.False(t, limiter.wait(peer0, 110))
+	assert.True(t, limiter.wait(peer1, 10))
+}
+
+func TestBandwidthLimiterUnlimited(t *testing.T) {
+	net := &network{log: logging.NoLog{}}
+	peer := newPeer(net, nil, utils.IPDesc{})
+
+	limiter := newBandwidthLimiter(0, 0, 0, 0)
+	for i := 0; i < 10; i++ {
+		assert.False(t, limiter.wait(peer, 1<<20))
+	}
+}
@@ -0,0 +1,193 @@
+// (c) 2019-2020, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package network
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"net"
+	"sync"
+	"time"
+
+	"github.com/ava-labs/avalanchego/database"
+	"github.com/ava-labs/avalanchego/database/prefixdb"
+	"github.com/ava-labs/avalanchego/ids"
+	"github.com/ava-labs/avalanchego/utils/constants"
+)
+
+const (
+	nodeIDTarget byte = iota
+	ipTarget
+)
+
+var (
+	bannedPrefix  = []byte("banned")
+	allowedPrefix = []byte("allowed")
+
+	errInvalidBlacklistTarget = errors.New("expected a node ID or an IP")
+	errPeerAllowlisted        = errors.New("peer is allowlisted")
+	errPeerBlacklisted        = errors.New("peer is blacklisted")
+	errWrongExpiryLength      = errors.New("blacklist expiry has the wrong length")
+)
+
+// nodeIDKey returns the blacklist key of the peer with node ID [nodeID]
+func nodeIDKey(nodeID ids.ShortID) string {
+	return string(append([]byte{nodeIDTarget}, nodeID[:]...))
+}
+
+// ipKey returns the blacklist key of the peers with IP [ip], regardless of
+// their port
+func ipKey(ip net.IP) string {
+	return string(append([]byte{ipTarget}, ip.To16()...))
+}
+
+// parseBlacklistTarget returns the blacklist key of [target], which is either
+// a node ID or an IP
+func parseBlacklistTarget(target string) (string, error) {
+	if nodeID, err := ids.ShortFromPrefixedString(target, constants.NodeIDPrefix); err == nil {
+		return nodeIDKey(nodeID), nil
+	}
+	if ip := net.ParseIP(target); ip != nil {
+		return ipKey(ip), nil
+	}
+	return "", fmt.Errorf("%w but got %q", errInvalidBlacklistTarget, target)
+}
+
+// peerBlacklist tracks the peers, identified by node ID or IP, that the network
+// refuses to connect to, and the peers that are exempt from being blacklisted.
+// Both are persisted in [db] so they survive restarts.
+type peerBlacklist struct {
+	lock sync.RWMutex
+
+	bannedDB, allowedDB database.Database
+
+	// key --> time at which the ban expires
+	banned  map[string]time.Time
+	allowed map[string]struct{}
+}
+
+func newPeerBlacklist(db database.Database) *peerBlacklist {
+	return &peerBlacklist{
+		bannedDB:  prefixdb.New(bannedPrefix, db),
+		allowedDB: prefixdb.New(allowedPrefix, db),
+		banned:    make(map[string]time.Time),
+		allowed:   make(map[string]struct{}),
+	}
+}
+
+// load the persisted blacklist and allowlist. Bans that expired before [now]
+// are deleted.
+func (b *peerBlacklist) load(now time.Time) error {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+
+	expired := [][]byte(nil)
+	iter := b.bannedDB.NewIterator()
+	for iter.Next() {
+		if len(iter.Value()) != 8 {
+			iter.Release()
+			return errWrongExpiryLength
+		}
+		key := iter.Key()
+		until := time.Unix(0, int64(binary.BigEndian.Uint64(iter.Value())))
+		if !until.After(now) {
+			expired = append(expired, append([]byte(nil), key...))
+			continue
+		}
+		b.banned[string(key)] = until
+	}
+	err := iter.Error()
+	iter.Release()
+	if err != nil {
+		return err
+	}
+	for _, key := range expired {
+		if err := b.bannedDB.Delete(key); err != nil {
+			return err
+		}
+	}
+
+	iter = b.allowedDB.NewIterator()
+	defer iter.Release()
+	for iter.Next() {
+		b.allowed[string(iter.Key())] = struct{}{}
+	}
+	return iter.Error()
+}
+
+// Ban [key] until [until]. Returns an error if [key] is allowlisted.
+func (b *peerBlacklist) Ban(key string, until time.Time) error {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+
+	if _, ok := b.allowed[key]; ok {
+		return errPeerAllowlisted
+	}
+	value := make([]byte, 8)
+	binary.BigEndian.PutUint64(value, uint64(until.UnixNano()))
+	if err := b.bannedDB.Put([]byte(key), value); err != nil {
+		return err
+	}
+	b.banned[key] = until
+	return nil
+}
+
+// Unban [key]
+func (b *peerBlacklist) Unban(key string) error {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+
+	if err := b.bannedDB.Delete([]byte(key)); err != nil {
+		return err
+	}
+	delete(b.banned, key)
+	return nil
+}
+
+// Allow [key], which lifts any ban on it and prevents it from being banned
+func (b *peerBlacklist) Allow(key string) error {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+
+	if err := b.bannedDB.Delete([]byte(key)); err != nil {
+		return err
+	}
+	delete(b.banned, key)
+	if err := b.allowedDB.Put([]byte(key), nil); err != nil {
+		return err
+	}
+	b.allowed[key] = struct{}{}
+	return nil
+}
+
+// Disallow [key], so that it can be banned again
+func (b *peerBlacklist) Disallow(key string) error {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+
+	if err := b.allowedDB.Delete([]byte(key)); err != nil {
+		return err
+	}
+	delete(b.allowed, key)
+	return nil
+}
+
+// Blocked returns true if any of [keys], which identify a single peer, is
+// banned at [now] and none of them are allowlisted
+func (b *peerBlacklist) Blocked(now time.Time, keys ...string) bool {
+	b.lock.RLock()
+	defer b.lock.RUnlock()
+
+	blocked := false
+	for _, key := range keys {
+		if _, ok := b.allowed[key]; ok {
+			return false
+		}
+		if until, ok := b.banned[key]; ok && until.After(now) {
+			blocked = true
+		}
+	}
+	return blocked
+}
@@ -0,0 +1,67 @@
+// (c) 2019-2020, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package network
+
+import (
+	"net"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/ava-labs/avalanchego/database/memdb"
+	"github.com/ava-labs/avalanchego/ids"
+	"github.com/ava-labs/avalanchego/utils/constants"
+)
+
+func TestParseBlacklistTarget(t *testing.T) {
+	nodeID := ids.ShortID{1}
+	key, err := parseBlacklistTarget(nodeID.PrefixedString(constants.NodeIDPrefix))
+	assert.NoError(t, err)
+	assert.Equal(t, nodeIDKey(nodeID), key)
+
+	// IPv4 addresses are keyed the same however they're written
+	key, err = parseBlacklistTarget("127.0.0.1")
+	assert.NoError(t, err)
+	assert.Equal(t, ipKey(net.IPv4(127, 0, 0, 1).To4()), key)
+
+	_, err = parseBlacklistTarget("127.0.0.1:9651")
+	assert.Error(t, err)
+}
+
+func TestPeerBlacklistPersists(t *testing.T) {
+	db := memdb.New()
+	now := time.Unix(1000, 0)
+
+	banned := nodeIDKey(ids.ShortID{1})
+	expired := nodeIDKey(ids.ShortID{2})
+	allowed := ipKey(net.IPv4(127, 0, 0, 1))
+
+	b := newPeerBlacklist(db)
+	assert.NoError(t, b.load(now))
+	assert.NoError(t, b.Ban(banned, now.Add(time.Hour)))
+	assert.NoError(t, b.Ban(expired, now.Add(time.Minute)))
+	assert.NoError(t, b.Allow(allowed))
+	assert.Equal(t, errPeerAllowlisted, b.Ban(allowed, now.Add(time.Hour)))
+
+	// restart after the shorter ban expired
+	now = now.Add(2 * time.Minute)
+	b = newPeerBlacklist(db)
+	assert.NoError(t, b.load(now))
+	assert.True(t, b.Blocked(now, banned))
+	assert.False(t, b.Blocked(now, expired))
+	assert.NotContains(t, b.banned, expired)
+
+	// an allowlisted key exempts the peer even if it's banned by another key
+	assert.False(t, b.Blocked(now, banned, allowed))
+
+	assert.NoError(t, b.Unban(banned))
+	assert.False(t, b.Blocked(now, banned))
+	assert.NoError(t, b.Disallow(allowed))
+
+	b = newPeerBlacklist(db)
+	assert.NoError(t, b.load(now))
+	assert.Empty(t, b.banned)
+	assert.Empty(t, b.allowed)
+}
@@ -39,6 +39,13 @@ func (m Builder) Ping() (Msg, error) { return m.Pack(Ping, nil) }
 // Pong message
 func (m Builder) Pong() (Msg, error) { return m.Pack(Pong, nil) }
 
+// TimedPong message
+func (m Builder) TimedPong(timestamp uint64) (Msg, error) {
+	return m.Pack(TimedPong, map[Field]interface{}{
+		Timestamp: timestamp,
+	})
+}
+
 // GetAcceptedFrontier message
 func (m Builder) GetAcceptedFrontier(chainID ids.ID, requestID uint32, deadline uint64) (Msg, error) {
 	return m.Pack(GetAcceptedFrontier, map[Field]interface{}{
@@ -130,6 +137,17 @@ func (m Builder) Put(chainID ids.ID, requestID uint32, containerID ids.ID, conta
 	})
 }
 
+// HintedPut message
+func (m Builder) HintedPut(chainID ids.ID, requestID uint32, containerID ids.ID, container []byte, hints [][]byte) (Msg, error) {
+	return m.Pack(HintedPut, map[Field]interface{}{
+		ChainID:             chainID[:],
+		RequestID:           requestID,
+		ContainerID:         containerID[:],
+		ContainerBytes:      container,
+		MultiContainerBytes: hints,
+	})
+}
+
 // PushQuery message
 func (m Builder) PushQuery(chainID ids.ID, requestID uint32, deadline uint64, containerID ids.ID, container []byte) (Msg, error) {
 	return m.Pack(PushQuery, map[Field]interface{}{
@@ -164,3 +182,74 @@ func (m Builder) Chits(chainID ids.ID, requestID uint32, containerIDs []ids.ID)
 		ContainerIDs: containerIDBytes,
 	})
 }
+
+// GossipTxs message
+func (m Builder) GossipTxs(chainID ids.ID, containerID ids.ID, txIDs []ids.ID) (Msg, error) {
+	txIDBytes := make([][]byte, len(txIDs))
+	for i, txID := range txIDs {
+		copy := txID
+		txIDBytes[i] = copy[:]
+	}
+	return m.Pack(GossipTxs, map[Field]interface{}{
+		ChainID:      chainID[:],
+		ContainerID:  containerID[:],
+		ContainerIDs: txIDBytes,
+	})
+}
+
+// JustifiedChits message
+func (m Builder) JustifiedChits(chainID ids.ID, requestID uint32, containerIDs []ids.ID, heights []uint64) (Msg, error) {
+	containerIDBytes := make([][]byte, len(containerIDs))
+	for i, containerID := range containerIDs {
+		copy := containerID
+		containerIDBytes[i] = copy[:]
+	}
+	return m.Pack(JustifiedChits, map[Field]interface{}{
+		ChainID:          chainID[:],
+		RequestID:        requestID,
+		ContainerIDs:     containerIDBytes,
+		ContainerHeights: heights,
+	})
+}
+
+// Rotation message
+func (m Builder) Rotation(cert, signature []byte, expiry uint64) (Msg, error) {
+	return m.Pack(Rotation, map[Field]interface{}{
+		Certificate: cert,
+		Signature:   signature,
+		Expiry:      expiry,
+	})
+}
+
+// Capabilities message
+func (m Builder) Capabilities(flags uint32) (Msg, error) {
+	return m.Pack(Capabilities, map[Field]interface{}{
+		CapabilityFlags: flags,
+	})
+}
+
+// Traced message
+func (m Builder) Traced(traceID uint64, msg Msg) (Msg, error) {
+	return m.Pack(Traced, map[Field]interface{}{
+		TraceID:   traceID,
+		TracedMsg: msg.Bytes(),
+		// ChainID isn't packed. It's set so that the message is queued on
+		// behalf of the same chain as [msg].
+		ChainID: msg.Get(ChainID),
+	})
+}
+
+// Compressed message
+func (m Builder) Compressed(msg Msg) (Msg, error) {
+	compressed, err := compress(msg.Bytes())
+	if err != nil {
+		return nil, err
+	}
+	return m.Pack(Compressed, map[Field]interface{}{
+		CompressionType: gzipCompression,
+		CompressedMsg:   compressed,
+		// ChainID isn't packed. It's set so that the message is queued on
+		// behalf of the same chain as [msg].
+		ChainID: msg.Get(ChainID),
+	})
+}
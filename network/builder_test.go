@@ -4,6 +4,7 @@
 package network
 
 import (
+	"math"
 	"net"
 	"testing"
 
@@ -316,3 +317,134 @@ func TestBuildChits(t *testing.T) {
 	assert.Equal(t, requestID, parsedMsg.Get(RequestID))
 	assert.Equal(t, containerIDs, parsedMsg.Get(ContainerIDs))
 }
+
+func TestBuildJustifiedChits(t *testing.T) {
+	chainID := ids.Empty.Prefix(0)
+	requestID := uint32(5)
+	containerID := ids.Empty.Prefix(1)
+	containerIDs := [][]byte{containerID[:]}
+	heights := []uint64{7}
+
+	msg, err := TestBuilder.JustifiedChits(chainID, requestID, []ids.ID{containerID}, heights)
+	assert.NoError(t, err)
+	assert.NotNil(t, msg)
+	assert.Equal(t, JustifiedChits, msg.Op())
+	assert.Equal(t, chainID[:], msg.Get(ChainID))
+	assert.Equal(t, requestID, msg.Get(RequestID))
+	assert.Equal(t, containerIDs, msg.Get(ContainerIDs))
+	assert.Equal(t, heights, msg.Get(ContainerHeights))
+
+	parsedMsg, err := TestBuilder.Parse(msg.Bytes())
+	assert.NoError(t, err)
+	assert.NotNil(t, parsedMsg)
+	assert.Equal(t, JustifiedChits, parsedMsg.Op())
+	assert.Equal(t, chainID[:], parsedMsg.Get(ChainID))
+	assert.Equal(t, requestID, parsedMsg.Get(RequestID))
+	assert.Equal(t, containerIDs, parsedMsg.Get(ContainerIDs))
+	assert.Equal(t, heights, parsedMsg.Get(ContainerHeights))
+}
+
+func TestBuildHintedPut(t *testing.T) {
+	chainID := ids.Empty.Prefix(0)
+	requestID := uint32(5)
+	containerID := ids.Empty.Prefix(1)
+	container := []byte{2}
+	hints := [][]byte{{3}, {4, 5}}
+
+	msg, err := TestBuilder.HintedPut(chainID, requestID, containerID, container, hints)
+	assert.NoError(t, err)
+	assert.NotNil(t, msg)
+	assert.Equal(t, HintedPut, msg.Op())
+	assert.Equal(t, chainID[:], msg.Get(ChainID))
+	assert.Equal(t, requestID, msg.Get(RequestID))
+	assert.Equal(t, containerID[:], msg.Get(ContainerID))
+	assert.Equal(t, container, msg.Get(ContainerBytes))
+	assert.Equal(t, hints, msg.Get(MultiContainerBytes))
+
+	parsedMsg, err := TestBuilder.Parse(msg.Bytes())
+	assert.NoError(t, err)
+	assert.NotNil(t, parsedMsg)
+	assert.Equal(t, HintedPut, parsedMsg.Op())
+	assert.Equal(t, chainID[:], parsedMsg.Get(ChainID))
+	assert.Equal(t, requestID, parsedMsg.Get(RequestID))
+	assert.Equal(t, containerID[:], parsedMsg.Get(ContainerID))
+	assert.Equal(t, container, parsedMsg.Get(ContainerBytes))
+	assert.Equal(t, hints, parsedMsg.Get(MultiContainerBytes))
+}
+
+func TestBuildGossipTxs(t *testing.T) {
+	chainID := ids.Empty.Prefix(0)
+	containerID := ids.Empty.Prefix(1)
+	txID := ids.Empty.Prefix(2)
+	txIDs := [][]byte{txID[:]}
+
+	msg, err := TestBuilder.GossipTxs(chainID, containerID, []ids.ID{txID})
+	assert.NoError(t, err)
+	assert.NotNil(t, msg)
+	assert.Equal(t, GossipTxs, msg.Op())
+	assert.Equal(t, chainID[:], msg.Get(ChainID))
+	assert.Equal(t, containerID[:], msg.Get(ContainerID))
+	assert.Equal(t, txIDs, msg.Get(ContainerIDs))
+
+	parsedMsg, err := TestBuilder.Parse(msg.Bytes())
+	assert.NoError(t, err)
+	assert.NotNil(t, parsedMsg)
+	assert.Equal(t, GossipTxs, parsedMsg.Op())
+	assert.Equal(t, chainID[:], parsedMsg.Get(ChainID))
+	assert.Equal(t, containerID[:], parsedMsg.Get(ContainerID))
+	assert.Equal(t, txIDs, parsedMsg.Get(ContainerIDs))
+}
+
+func TestBuildTraced(t *testing.T) {
+	chainID := ids.Empty.Prefix(0)
+	requestID := uint32(5)
+	deadline := uint64(15)
+	containerID := ids.Empty.Prefix(1)
+	traceID := uint64(42)
+
+	inner, err := TestBuilder.Get(chainID, requestID, deadline, containerID)
+	assert.NoError(t, err)
+
+	msg, err := TestBuilder.Traced(traceID, inner)
+	assert.NoError(t, err)
+	assert.NotNil(t, msg)
+	assert.Equal(t, Traced, msg.Op())
+	assert.Equal(t, traceID, msg.Get(TraceID))
+	assert.Equal(t, inner.Bytes(), msg.Get(TracedMsg))
+	assert.Equal(t, chainID[:], msg.Get(ChainID))
+
+	parsedMsg, err := TestBuilder.Parse(msg.Bytes())
+	assert.NoError(t, err)
+	assert.NotNil(t, parsedMsg)
+	assert.Equal(t, Traced, parsedMsg.Op())
+	assert.Equal(t, traceID, parsedMsg.Get(TraceID))
+	assert.Equal(t, inner.Bytes(), parsedMsg.Get(TracedMsg))
+}
+
+func TestBuildCompressed(t *testing.T) {
+	chainID := ids.Empty.Prefix(0)
+	requestID := uint32(5)
+	containerID := ids.Empty.Prefix(1)
+	container := make([]byte, 2*compressionThreshold)
+
+	inner, err := TestBuilder.Put(chainID, requestID, containerID, container)
+	assert.NoError(t, err)
+
+	msg, err := TestBuilder.Compressed(inner)
+	assert.NoError(t, err)
+	assert.NotNil(t, msg)
+	assert.Equal(t, Compressed, msg.Op())
+	assert.Equal(t, gzipCompression, msg.Get(CompressionType))
+	assert.Equal(t, chainID[:], msg.Get(ChainID))
+	assert.Less(t, len(msg.Bytes()), len(inner.Bytes()))
+
+	parsedMsg, err := TestBuilder.Parse(msg.Bytes())
+	assert.NoError(t, err)
+	assert.NotNil(t, parsedMsg)
+	assert.Equal(t, Compressed, parsedMsg.Op())
+	assert.Equal(t, gzipCompression, parsedMsg.Get(CompressionType))
+
+	innerBytes, err := decompress(parsedMsg.Get(CompressedMsg).([]byte), math.MaxInt32)
+	assert.NoError(t, err)
+	assert.Equal(t, inner.Bytes(), innerBytes)
+}
@@ -0,0 +1,98 @@
+// (c) 2019-2020, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package network
+
+import (
+	"sync"
+
+	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/ava-labs/avalanchego/ids"
+	"github.com/ava-labs/avalanchego/utils/constants"
+	"github.com/ava-labs/avalanchego/utils/wrappers"
+)
+
+const (
+	// Maximum number of chains that are given their own metrics. As peers
+	// choose the chain IDs of the messages they send, the messages of any
+	// further chains are counted together so the number of series is bounded.
+	maxTrackedChains = 64
+
+	// Label of the messages of the chains that aren't tracked individually
+	otherChainsLabel = "other"
+)
+
+// chainMetrics counts the messages sent to, and received from, peers on behalf
+// of each chain
+type chainMetrics struct {
+	lock sync.Mutex
+	// chain ID --> label of the chain's metrics
+	labels map[ids.ID]string
+
+	msgsSent, bytesSent, msgsReceived, bytesReceived, msgsDropped *prometheus.CounterVec
+}
+
+func newChainCounter(name, help string) *prometheus.CounterVec {
+	return prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: constants.PlatformName,
+		Name:      name,
+		Help:      help,
+	}, []string{"chain"})
+}
+
+func (m *chainMetrics) initialize(registerer prometheus.Registerer) error {
+	m.labels = make(map[ids.ID]string)
+	m.msgsSent = newChainCounter("chain_msgs_sent", "Number of messages queued to be sent to peers, by chain")
+	m.bytesSent = newChainCounter("chain_bytes_sent", "Size, before compression, of the messages queued to be sent to peers, by chain")
+	m.msgsReceived = newChainCounter("chain_msgs_received", "Number of messages received from peers, by chain")
+	m.bytesReceived = newChainCounter("chain_bytes_received", "Size, after decompression, of the messages received from peers, by chain")
+	m.msgsDropped = newChainCounter("chain_msgs_dropped", "Number of messages to peers that were dropped rather than sent, by chain")
+
+	errs := wrappers.Errs{}
+	errs.Add(
+		registerer.Register(m.msgsSent),
+		registerer.Register(m.bytesSent),
+		registerer.Register(m.msgsReceived),
+		registerer.Register(m.bytesReceived),
+		registerer.Register(m.msgsDropped),
+	)
+	return errs.Err
+}
+
+// label returns the label of the metrics of [chainID]
+func (m *chainMetrics) label(chainID ids.ID) string {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	if label, ok := m.labels[chainID]; ok {
+		return label
+	}
+	if len(m.labels) >= maxTrackedChains {
+		return otherChainsLabel
+	}
+	label := chainID.String()
+	m.labels[chainID] = label
+	return label
+}
+
+// sent counts a message of [numBytes] bytes queued to be sent on behalf of
+// [chainID]
+func (m *chainMetrics) sent(chainID ids.ID, numBytes int) {
+	label := m.label(chainID)
+	m.msgsSent.WithLabelValues(label).Inc()
+	m.bytesSent.WithLabelValues(label).Add(float64(numBytes))
+}
+
+// received counts a message of [numBytes] bytes received on behalf of
+// [chainID]
+func (m *chainMetrics) received(chainID ids.ID, numBytes int) {
+	label := m.label(chainID)
+	m.msgsReceived.WithLabelValues(label).Inc()
+	m.bytesReceived.WithLabelValues(label).Add(float64(numBytes))
+}
+
+// dropped counts a message to be sent on behalf of [chainID] that was dropped
+func (m *chainMetrics) dropped(chainID ids.ID) {
+	m.msgsDropped.WithLabelValues(m.label(chainID)).Inc()
+}
@@ -0,0 +1,34 @@
+// (c) 2019-2020, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package network
+
+import (
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/ava-labs/avalanchego/ids"
+)
+
+func TestChainMetricsBoundsChains(t *testing.T) {
+	m := chainMetrics{}
+	assert.NoError(t, m.initialize(prometheus.NewRegistry()))
+
+	for i := 0; i < maxTrackedChains; i++ {
+		m.received(ids.ID{byte(i)}, 1)
+	}
+	// further chains are counted together
+	m.received(ids.ID{255, 1}, 2)
+	m.received(ids.ID{255, 2}, 3)
+	// chains that are already tracked keep their own metrics
+	m.received(ids.ID{0}, 4)
+
+	assert.Equal(t, 2.0, testutil.ToFloat64(m.msgsReceived.WithLabelValues(otherChainsLabel)))
+	assert.Equal(t, 5.0, testutil.ToFloat64(m.bytesReceived.WithLabelValues(otherChainsLabel)))
+	assert.Equal(t, 2.0, testutil.ToFloat64(m.msgsReceived.WithLabelValues(ids.ID{0}.String())))
+	assert.Equal(t, 5.0, testutil.ToFloat64(m.bytesReceived.WithLabelValues(ids.ID{0}.String())))
+	assert.Equal(t, maxTrackedChains+1, testutil.CollectAndCount(m.msgsReceived))
+}
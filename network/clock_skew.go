@@ -0,0 +1,20 @@
+// (c) 2019-2020, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package network
+
+import (
+	"sort"
+	"time"
+)
+
+// medianDuration returns the median of [offsets], which are in nanoseconds.
+// Sorts [offsets] in place. Assumes [offsets] is non-empty.
+func medianDuration(offsets []int64) time.Duration {
+	sort.Slice(offsets, func(i, j int) bool { return offsets[i] < offsets[j] })
+	mid := len(offsets) / 2
+	if len(offsets)%2 == 1 {
+		return time.Duration(offsets[mid])
+	}
+	return time.Duration(offsets[mid-1]/2 + offsets[mid]/2)
+}
@@ -25,6 +25,16 @@ const (
 	ContainerBytes                   // Used for gossiping
 	ContainerIDs                     // Used for querying
 	MultiContainerBytes              // Used in MultiPut
+	ContainerHeights                 // Used for justifying votes
+	Certificate                      // Used for identity rotation
+	Signature                        // Used for identity rotation
+	Expiry                           // Used for identity rotation
+	CapabilityFlags                  // Used in handshake
+	TraceID                          // Used for tracing requests
+	TracedMsg                        // Used for tracing requests
+	CompressionType                  // Used for compression
+	CompressedMsg                    // Used for compression
+	Timestamp                        // Used for time synchronization
 )
 
 // Packer returns the packer function that can be used to pack this field.
@@ -56,6 +66,26 @@ func (f Field) Packer() func(*wrappers.Packer, interface{}) {
 		return wrappers.TryPackHashes
 	case MultiContainerBytes:
 		return wrappers.TryPack2DBytes
+	case ContainerHeights:
+		return wrappers.TryPackLongs
+	case Certificate:
+		return wrappers.TryPackBytes
+	case Signature:
+		return wrappers.TryPackBytes
+	case Expiry:
+		return wrappers.TryPackLong
+	case CapabilityFlags:
+		return wrappers.TryPackInt
+	case TraceID:
+		return wrappers.TryPackLong
+	case TracedMsg:
+		return wrappers.TryPackBytes
+	case CompressionType:
+		return wrappers.TryPackByte
+	case CompressedMsg:
+		return wrappers.TryPackBytes
+	case Timestamp:
+		return wrappers.TryPackLong
 	default:
 		return nil
 	}
@@ -90,6 +120,26 @@ func (f Field) Unpacker() func(*wrappers.Packer) interface{} {
 		return wrappers.TryUnpackHashes
 	case MultiContainerBytes:
 		return wrappers.TryUnpack2DBytes
+	case ContainerHeights:
+		return wrappers.TryUnpackLongs
+	case Certificate:
+		return wrappers.TryUnpackBytes
+	case Signature:
+		return wrappers.TryUnpackBytes
+	case Expiry:
+		return wrappers.TryUnpackLong
+	case CapabilityFlags:
+		return wrappers.TryUnpackInt
+	case TraceID:
+		return wrappers.TryUnpackLong
+	case TracedMsg:
+		return wrappers.TryUnpackBytes
+	case CompressionType:
+		return wrappers.TryUnpackByte
+	case CompressedMsg:
+		return wrappers.TryUnpackBytes
+	case Timestamp:
+		return wrappers.TryUnpackLong
 	default:
 		return nil
 	}
@@ -123,6 +173,26 @@ func (f Field) String() string {
 		return "Container IDs"
 	case MultiContainerBytes:
 		return "MultiContainerBytes"
+	case ContainerHeights:
+		return "Container Heights"
+	case Certificate:
+		return "Certificate"
+	case Signature:
+		return "Signature"
+	case Expiry:
+		return "Expiry"
+	case CapabilityFlags:
+		return "Capability Flags"
+	case TraceID:
+		return "Trace ID"
+	case TracedMsg:
+		return "Traced Message"
+	case CompressionType:
+		return "Compression Type"
+	case CompressedMsg:
+		return "Compressed Message"
+	case Timestamp:
+		return "Timestamp"
 	default:
 		return "Unknown Field"
 	}
@@ -167,6 +237,22 @@ func (op Op) String() string {
 		return "pull_query"
 	case Chits:
 		return "chits"
+	case JustifiedChits:
+		return "justified_chits"
+	case GossipTxs:
+		return "gossip_txs"
+	case Rotation:
+		return "rotation"
+	case Capabilities:
+		return "capabilities"
+	case Traced:
+		return "traced"
+	case HintedPut:
+		return "hinted_put"
+	case Compressed:
+		return "compressed"
+	case TimedPong:
+		return "timed_pong"
 	default:
 		return "Unknown Op"
 	}
@@ -194,6 +280,20 @@ const (
 	PushQuery
 	PullQuery
 	Chits
+	JustifiedChits
+	// Gossip:
+	GossipTxs
+	// Handshake:
+	Rotation
+	Capabilities
+	// Tracing:
+	Traced
+	// Consensus:
+	HintedPut
+	// Compression:
+	Compressed
+	// Time synchronization:
+	TimedPong
 )
 
 // Defines the messages that can be sent/received with this network
@@ -219,5 +319,39 @@ var (
 		PushQuery: {ChainID, RequestID, Deadline, ContainerID, ContainerBytes},
 		PullQuery: {ChainID, RequestID, Deadline, ContainerID},
 		Chits:     {ChainID, RequestID, ContainerIDs},
+		// JustifiedChits includes the height of each voted container so that
+		// the requester can detect nonsensical votes. It's only sent to peers
+		// that advertised capabilityJustifiedChits.
+		JustifiedChits: {ChainID, RequestID, ContainerIDs, ContainerHeights},
+		// Gossip:
+		GossipTxs: {ChainID, ContainerID, ContainerIDs},
+		// Handshake:
+		// Rotation is sent after Version by a node that is rotating
+		// its staking certificate.
+		Rotation: {Certificate, Signature, Expiry},
+		// Capabilities is sent after Version to advertise the optional
+		// features this node supports.
+		Capabilities: {CapabilityFlags},
+		// Tracing:
+		// Traced wraps a request, or a response, with the ID used to
+		// correlate the request with its responses. It's only sent to peers
+		// that advertised CapabilityTracing.
+		Traced: {TraceID, TracedMsg},
+		// Consensus:
+		// HintedPut is a Put that also carries the ancestors of the container
+		// that the receiver is likely missing. It's only sent to peers that
+		// advertised capabilityParentHints.
+		HintedPut: {ChainID, RequestID, ContainerID, ContainerBytes, MultiContainerBytes},
+		// Compression:
+		// Compressed wraps a message that was compressed with the algorithm
+		// identified by CompressionType. It's only sent to peers that
+		// advertised capabilityGzip.
+		Compressed: {CompressionType, CompressedMsg},
+		// Time synchronization:
+		// TimedPong is a Pong that carries the time, in unix nanoseconds,
+		// that the sender sent it at, so that the receiver can estimate the
+		// offset between their clocks. It's only sent to peers that
+		// advertised capabilityTimedPong.
+		TimedPong: {Timestamp},
 	}
 )
@@ -0,0 +1,117 @@
+// (c) 2019-2020, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package network
+
+import (
+	"bytes"
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"sync/atomic"
+)
+
+// Compression algorithms that a Compressed message may be compressed with
+const (
+	gzipCompression byte = iota
+)
+
+const (
+	// compressionThreshold is the size of the smallest message that is
+	// compressed. Smaller messages rarely shrink enough to be worth the CPU.
+	compressionThreshold = 1024
+)
+
+var errDecompressedTooLarge = errors.New("decompressed message is too large")
+
+// compressibleOps are the messages that may be wrapped in a Compressed message
+var compressibleOps = map[Op]bool{
+	MultiPut:  true,
+	Put:       true,
+	HintedPut: true,
+	PushQuery: true,
+	Traced:    true,
+}
+
+// compress [b] with gzip
+func compress(b []byte) ([]byte, error) {
+	buf := bytes.Buffer{}
+	w, err := gzip.NewWriterLevel(&buf, gzip.BestSpeed)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := w.Write(b); err != nil {
+		return nil, err
+	}
+	if err := w.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// decompress the gzip compressed [b], failing if the result is larger than
+// [maxSize]
+func decompress(b []byte, maxSize int64) ([]byte, error) {
+	r, err := gzip.NewReader(bytes.NewReader(b))
+	if err != nil {
+		return nil, err
+	}
+	// Read one byte past [maxSize] so that oversized messages are detected
+	// without decompressing all of them
+	decompressed, err := ioutil.ReadAll(io.LimitReader(r, maxSize+1))
+	if err != nil {
+		return nil, err
+	}
+	if int64(len(decompressed)) > maxSize {
+		return nil, fmt.Errorf("%w: exceeds %d bytes", errDecompressedTooLarge, maxSize)
+	}
+	return decompressed, r.Close()
+}
+
+// compressedMsg compresses [msg] if the peer supports compression and doing so
+// shrinks it
+func (p *peer) compressedMsg(msg Msg) Msg {
+	if atomic.LoadUint32(&p.peerCapabilities)&capabilityGzip == 0 ||
+		!compressibleOps[msg.Op()] ||
+		len(msg.Bytes()) < compressionThreshold {
+		return msg
+	}
+	compressed, err := p.net.b.Compressed(msg)
+	if err != nil {
+		p.net.log.Debug("failed to compress %s message to %s due to %s", msg.Op(), p.id, err)
+		return msg
+	}
+	rawLen := len(msg.Bytes())
+	compressedLen := len(compressed.Bytes())
+	if compressedLen >= rawLen {
+		return msg
+	}
+	p.net.compressionRawBytes.Add(float64(rawLen))
+	p.net.compressionCompressedBytes.Add(float64(compressedLen))
+	return compressed
+}
+
+// assumes the [stateLock] is not held
+func (p *peer) compressed(msg Msg) {
+	if compression := msg.Get(CompressionType).(byte); compression != gzipCompression {
+		p.net.log.Debug("dropping message from %s compressed with unknown algorithm %d", p.id, compression)
+		return
+	}
+	innerBytes, err := decompress(msg.Get(CompressedMsg).([]byte), p.net.maxMessageSize)
+	if err != nil {
+		p.net.log.Debug("failed to decompress message from %s due to %s", p.id, err)
+		return
+	}
+	inner, err := p.net.b.Parse(innerBytes)
+	if err != nil {
+		p.net.log.Debug("failed to parse compressed message from %s due to %s", p.id, err)
+		return
+	}
+	if op := inner.Op(); !compressibleOps[op] {
+		p.net.log.Debug("dropping compressed %s message from %s", op, p.id)
+		return
+	}
+	p.handle(inner)
+}
@@ -0,0 +1,24 @@
+// (c) 2019-2020, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package network
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDecompressRejectsOversizedMessages(t *testing.T) {
+	raw := make([]byte, 1024)
+	compressed, err := compress(raw)
+	assert.NoError(t, err)
+
+	decompressed, err := decompress(compressed, int64(len(raw)))
+	assert.NoError(t, err)
+	assert.Equal(t, raw, decompressed)
+
+	_, err = decompress(compressed, int64(len(raw)-1))
+	assert.True(t, errors.Is(err, errDecompressedTooLarge))
+}
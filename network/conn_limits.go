@@ -0,0 +1,167 @@
+// (c) 2019-2020, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package network
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"time"
+
+	"github.com/ava-labs/avalanchego/ids"
+	"github.com/ava-labs/avalanchego/snow/validators"
+	"github.com/ava-labs/avalanchego/utils"
+)
+
+var (
+	errDialTimeout           = errors.New("dial timed out")
+	errTooManyPendingDials   = errors.New("too many pending dials")
+	errTooManyPeers          = errors.New("too many peers")
+	errSubnetBudgetExhausted = errors.New("subnet connection budget exhausted")
+)
+
+// ConnectionConfig bounds the connections the network makes and accepts
+type ConnectionConfig struct {
+	// Maximum amount of time to spend dialing a peer. 0 means no timeout.
+	DialTimeout time.Duration
+
+	// Maximum number of IPs being dialed at once, including those waiting to
+	// be redialed. 0 means no limit.
+	MaxPendingDials int
+
+	// Maximum number of peers, including the IPs being dialed. 0 means no
+	// limit.
+	MaxPeers int
+
+	// Subnet ID --> maximum number of peers validating that subnet
+	SubnetBudgets map[ids.ID]int
+
+	// Validator sets of the subnets in [SubnetBudgets]. May be nil if there
+	// are no budgets.
+	Validators validators.Manager
+
+	// Maximum size, in bytes, of a message read from or sent to a peer. A peer
+	// that sends a larger message is disconnected. 0 means
+	// DefaultMaxMessageSize.
+	MaxMessageSize uint32
+
+	// If true, this node's own IP is included in the peer lists it sends, so
+	// that peers learn the IP even if it differs from the address that this
+	// node's connections come from, such as when it's behind a load balancer.
+	AdvertiseIP bool
+
+	// Decides which versions peers may run
+	VersionPolicy VersionPolicy
+}
+
+// DefaultConnectionConfig gives up on dials after 30 seconds, doesn't limit
+// the number of connections, and allows messages of up to
+// DefaultMaxMessageSize bytes
+var DefaultConnectionConfig = ConnectionConfig{
+	DialTimeout:    defaultDialTimeout,
+	MaxMessageSize: DefaultMaxMessageSize,
+}
+
+// timeoutDialer is a Dialer that gives up on dials that take longer than
+// [timeout]
+type timeoutDialer struct {
+	dialer  Dialer
+	timeout time.Duration
+}
+
+// newTimeoutDialer returns [dialer] if [timeout] is 0
+func newTimeoutDialer(dialer Dialer, timeout time.Duration) Dialer {
+	if timeout == 0 {
+		return dialer
+	}
+	return &timeoutDialer{
+		dialer:  dialer,
+		timeout: timeout,
+	}
+}
+
+type dialResult struct {
+	conn net.Conn
+	err  error
+}
+
+func (d *timeoutDialer) Dial(ip utils.IPDesc) (net.Conn, error) {
+	results := make(chan dialResult, 1)
+	go func() {
+		conn, err := d.dialer.Dial(ip)
+		results <- dialResult{conn: conn, err: err}
+	}()
+
+	timer := time.NewTimer(d.timeout)
+	defer timer.Stop()
+
+	select {
+	case result := <-results:
+		return result.conn, result.err
+	case <-timer.C:
+		// Close the connection if the dial eventually succeeds
+		go func() {
+			if result := <-results; result.err == nil {
+				_ = result.conn.Close()
+			}
+		}()
+		return nil, fmt.Errorf("%w: %s after %s", errDialTimeout, ip, d.timeout)
+	}
+}
+
+// canDial returns an error if dialing another IP would exceed the connection
+// limits.
+// assumes the stateLock is held.
+func (n *network) canDial() error {
+	config := &n.connectionConfig
+	pending := len(n.disconnectedIPs)
+	if config.MaxPendingDials > 0 && pending >= config.MaxPendingDials {
+		return fmt.Errorf("%w: %d of %d dials are pending",
+			errTooManyPendingDials, pending, config.MaxPendingDials)
+	}
+	if total := len(n.peers) + pending; config.MaxPeers > 0 && total >= config.MaxPeers {
+		return fmt.Errorf("%w: %d peers and %d pending dials of %d allowed",
+			errTooManyPeers, len(n.peers), pending, config.MaxPeers)
+	}
+	return nil
+}
+
+// canAddPeer returns an error if adding a peer with ID [nodeID] would exceed
+// the connection limits.
+// assumes the stateLock is held.
+func (n *network) canAddPeer(nodeID ids.ShortID) error {
+	config := &n.connectionConfig
+	if config.MaxPeers > 0 && len(n.peers) >= config.MaxPeers {
+		return fmt.Errorf("%w: %d of %d allowed",
+			errTooManyPeers, len(n.peers), config.MaxPeers)
+	}
+	return n.withinSubnetBudgets(nodeID)
+}
+
+// withinSubnetBudgets returns an error if [nodeID] validates a subnet whose
+// budget is already spent on other peers.
+// assumes the stateLock is held.
+func (n *network) withinSubnetBudgets(nodeID ids.ShortID) error {
+	config := &n.connectionConfig
+	if config.Validators == nil {
+		return nil
+	}
+	for subnetID, budget := range config.SubnetBudgets {
+		vdrs, ok := config.Validators.GetValidators(subnetID)
+		if !ok || !vdrs.Contains(nodeID) {
+			continue
+		}
+		spent := 0
+		for peerID := range n.peers {
+			if peerID != nodeID && vdrs.Contains(peerID) {
+				spent++
+			}
+		}
+		if spent >= budget {
+			return fmt.Errorf("%w: %d of %d peers validate subnet %s",
+				errSubnetBudgetExhausted, spent, budget, subnetID)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,156 @@
+// (c) 2019-2020, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package network
+
+import (
+	"errors"
+	"io"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/ava-labs/avalanchego/database/memdb"
+	"github.com/ava-labs/avalanchego/ids"
+	"github.com/ava-labs/avalanchego/network/networktest"
+	"github.com/ava-labs/avalanchego/snow/networking/benchlist"
+	"github.com/ava-labs/avalanchego/snow/validators"
+	"github.com/ava-labs/avalanchego/utils"
+	"github.com/ava-labs/avalanchego/utils/logging"
+	"github.com/ava-labs/avalanchego/version"
+)
+
+// newLimitedNetwork returns a network, limited by [config], that is never able
+// to connect to anyone
+func newLimitedNetwork(config ConnectionConfig) Network {
+	ip := utils.NewDynamicIPDesc(net.IPv6loopback, 0)
+	listener := networktest.NewListener(&net.TCPAddr{IP: net.IPv6loopback, Port: 0})
+	caller := networktest.NewDialer(&net.TCPAddr{IP: net.IPv6loopback, Port: 0})
+	vdrs := validators.NewSet()
+	return NewDefaultNetwork(
+		prometheus.NewRegistry(),
+		logging.NoLog{},
+		ids.GenerateTestShortID(),
+		ip,
+		0,
+		version.NewDefaultVersion("app", 0, 1, 0),
+		version.NewDefaultParser(),
+		[]net.Listener{listener},
+		caller,
+		NewIPUpgrader(),
+		NewIPUpgrader(),
+		vdrs,
+		vdrs,
+		&testHandler{},
+		time.Duration(0),
+		0,
+		nil,
+		false,
+		0,
+		0,
+		time.Now(),
+		defaultSendQueueSize,
+		DefaultSendQueueConfig,
+		BandwidthConfig{},
+		DefaultGossipConfig,
+		config,
+		HealthConfig{},
+		benchlist.NewManager(&benchlist.Config{}),
+		memdb.New(),
+		defaultAliasTimeout,
+		nil,
+	)
+}
+
+func TestTrackPendingDialLimit(t *testing.T) {
+	netw := newLimitedNetwork(ConnectionConfig{MaxPendingDials: 1})
+
+	ip1 := utils.IPDesc{IP: netw.IP().IP, Port: 1}
+	ip2 := utils.IPDesc{IP: netw.IP().IP, Port: 2}
+	assert.NoError(t, netw.Track(ip1))
+	// Tracking an IP that is already being dialed doesn't need another dial
+	assert.NoError(t, netw.Track(ip1))
+	err := netw.Track(ip2)
+	assert.True(t, errors.Is(err, errTooManyPendingDials))
+
+	assert.NoError(t, netw.Close())
+	assert.True(t, errors.Is(netw.Track(ip2), errNetworkClosed))
+}
+
+func TestTrackPeerLimit(t *testing.T) {
+	netw := newLimitedNetwork(ConnectionConfig{MaxPeers: 2})
+
+	assert.NoError(t, netw.Track(utils.IPDesc{IP: netw.IP().IP, Port: 1}))
+	assert.NoError(t, netw.Track(utils.IPDesc{IP: netw.IP().IP, Port: 2}))
+	err := netw.Track(utils.IPDesc{IP: netw.IP().IP, Port: 3})
+	assert.True(t, errors.Is(err, errTooManyPeers))
+
+	assert.NoError(t, netw.Close())
+}
+
+func TestSubnetConnectionBudget(t *testing.T) {
+	assert := assert.New(t)
+
+	subnetID := ids.GenerateTestID()
+	vdr0 := ids.GenerateTestShortID()
+	vdr1 := ids.GenerateTestShortID()
+	nonVdr := ids.GenerateTestShortID()
+
+	vdrs := validators.NewSet()
+	assert.NoError(vdrs.AddWeight(vdr0, 1))
+	assert.NoError(vdrs.AddWeight(vdr1, 1))
+	manager := validators.NewManager()
+	assert.NoError(manager.Set(subnetID, vdrs))
+
+	n := &network{
+		peers: make(map[ids.ShortID]*peer),
+		connectionConfig: ConnectionConfig{
+			SubnetBudgets: map[ids.ID]int{subnetID: 1},
+			Validators:    manager,
+		},
+	}
+	assert.NoError(n.canAddPeer(vdr0))
+	n.peers[vdr0] = &peer{}
+
+	// The budget is spent on [vdr0]
+	err := n.canAddPeer(vdr1)
+	assert.True(errors.Is(err, errSubnetBudgetExhausted))
+	assert.True(errors.Is(n.TrackNode(utils.IPDesc{}, vdr1), errSubnetBudgetExhausted))
+
+	// Peers that don't validate the subnet aren't limited by its budget
+	assert.NoError(n.canAddPeer(nonVdr))
+}
+
+// blockingDialer returns [conn] once [release] is closed
+type blockingDialer struct {
+	conn    net.Conn
+	release chan struct{}
+}
+
+func (d *blockingDialer) Dial(utils.IPDesc) (net.Conn, error) {
+	<-d.release
+	return d.conn, nil
+}
+
+func TestTimeoutDialer(t *testing.T) {
+	local, remote := net.Pipe()
+	blocking := &blockingDialer{
+		conn:    local,
+		release: make(chan struct{}),
+	}
+	dialer := newTimeoutDialer(blocking, time.Millisecond)
+
+	_, err := dialer.Dial(utils.IPDesc{})
+	assert.True(t, errors.Is(err, errDialTimeout))
+
+	// The connection is closed when the abandoned dial finishes
+	close(blocking.release)
+	_, err = remote.Read(make([]byte, 1))
+	assert.Equal(t, io.EOF, err)
+
+	assert.Equal(t, blocking, newTimeoutDialer(blocking, 0))
+}
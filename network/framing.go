@@ -0,0 +1,164 @@
+// (c) 2019-2020, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package network
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"io"
+
+	"github.com/ava-labs/avalanchego/utils/wrappers"
+)
+
+// Messages are sent to peers as frames. Every connection starts out with plain
+// frames of:
+//
+//	[big-endian length of message][message]
+//
+// Once a peer has advertised capabilityChecksums, the frames sent to it are
+// checksummed frames of:
+//
+//	[big-endian length of message][message][CRC-32 (IEEE) of message]
+//
+// The switch is marked by an empty plain frame, which is never a valid message,
+// so that the reader knows which frame is the first checksummed one, no matter
+// when the writer learned of its capabilities. A peer that didn't advertise
+// capabilityChecksums is never sent the marker, so it keeps reading plain
+// frames.
+//
+// The length is validated against the maximum message size before any memory
+// is allocated for the message, so a malformed length prefix can't make the
+// reader allocate more than the maximum message size.
+const (
+	frameHeaderLen   = wrappers.IntLen
+	frameChecksumLen = wrappers.IntLen
+)
+
+var (
+	errMessageTooLarge = errors.New("message is too large")
+	errInvalidChecksum = errors.New("message has an invalid checksum")
+)
+
+// frameOverhead returns the number of bytes a frame adds to its message
+func frameOverhead(checksummed bool) int {
+	if checksummed {
+		return frameHeaderLen + frameChecksumLen
+	}
+	return frameHeaderLen
+}
+
+// WriteFrame writes [msg] to [w] as a single frame, with a checksum if
+// [checksummed]. The header, message, and checksum are each passed to [w] in
+// their own Write.
+func WriteFrame(w io.Writer, msg []byte, checksummed bool) error {
+	if uint64(len(msg)) > uint64(^uint32(0)) {
+		return fmt.Errorf("%w: %d bytes", errMessageTooLarge, len(msg))
+	}
+
+	header := [frameHeaderLen]byte{}
+	binary.BigEndian.PutUint32(header[:], uint32(len(msg)))
+	if _, err := w.Write(header[:]); err != nil {
+		return err
+	}
+	if _, err := w.Write(msg); err != nil {
+		return err
+	}
+	if !checksummed {
+		return nil
+	}
+	checksum := [frameChecksumLen]byte{}
+	binary.BigEndian.PutUint32(checksum[:], crc32.ChecksumIEEE(msg))
+	_, err := w.Write(checksum[:])
+	return err
+}
+
+// WriteChecksumsMarker writes the empty plain frame that marks that every
+// following frame written to [w] is checksummed. Must only be written to peers
+// that advertised capabilityChecksums.
+func WriteChecksumsMarker(w io.Writer) error { return WriteFrame(w, nil, false) }
+
+// ReadFrame reads the next frame, which has a checksum if [checksummed], from
+// [r] and returns its message. Returns an error, without reading the message,
+// if the frame's length prefix is larger than [maxMessageSize]. Returns an
+// error if the message doesn't match its checksum.
+func ReadFrame(r io.Reader, maxMessageSize uint32, checksummed bool) ([]byte, error) {
+	header := [frameHeaderLen]byte{}
+	if _, err := io.ReadFull(r, header[:]); err != nil {
+		return nil, err
+	}
+	size := binary.BigEndian.Uint32(header[:])
+	if size > maxMessageSize {
+		return nil, fmt.Errorf("%w: %d > %d", errMessageTooLarge, size, maxMessageSize)
+	}
+
+	frameSize := int(size)
+	if checksummed {
+		frameSize += frameChecksumLen
+	}
+	frame := make([]byte, frameSize)
+	if _, err := io.ReadFull(r, frame); err == io.EOF {
+		// The frame was cut off after its header
+		return nil, io.ErrUnexpectedEOF
+	} else if err != nil {
+		return nil, err
+	}
+	if !checksummed {
+		return frame, nil
+	}
+	msg, checksum := frame[:size], frame[size:]
+	if binary.BigEndian.Uint32(checksum) != crc32.ChecksumIEEE(msg) {
+		return nil, errInvalidChecksum
+	}
+	return msg, nil
+}
+
+// frameReader reads the messages sent by a peer, switching to checksummed
+// frames once the peer sends the marker
+type frameReader struct {
+	r              io.Reader
+	maxMessageSize uint32
+	checksummed    bool
+}
+
+// Read the next message. Returns the message and the number of bytes read.
+func (f *frameReader) Read() ([]byte, int, error) {
+	read := 0
+	for {
+		msg, err := ReadFrame(f.r, f.maxMessageSize, f.checksummed)
+		if err != nil {
+			return nil, read, err
+		}
+		read += frameOverhead(f.checksummed) + len(msg)
+		if f.checksummed || len(msg) != 0 {
+			return msg, read, nil
+		}
+		// This is the marker, so the following frames are checksummed
+		f.checksummed = true
+	}
+}
+
+// frameWriter writes messages to a peer, as plain frames until checksums are
+// enabled
+type frameWriter struct {
+	w           io.Writer
+	checksummed bool
+}
+
+// EnableChecksums writes the marker, if it hasn't been written yet, so that
+// every following message is written in a checksummed frame. Returns the
+// number of bytes written.
+func (f *frameWriter) EnableChecksums() (int, error) {
+	if f.checksummed {
+		return 0, nil
+	}
+	f.checksummed = true
+	return frameHeaderLen, WriteChecksumsMarker(f.w)
+}
+
+// Write [msg] as a single frame. Returns the number of bytes written.
+func (f *frameWriter) Write(msg []byte) (int, error) {
+	return frameOverhead(f.checksummed) + len(msg), WriteFrame(f.w, msg, f.checksummed)
+}
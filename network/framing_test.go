@@ -0,0 +1,146 @@
+// (c) 2019-2020, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package network
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"io"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFrameRoundTrip(t *testing.T) {
+	for _, checksummed := range []bool{false, true} {
+		buf := bytes.Buffer{}
+		msgs := [][]byte{{}, {1}, bytes.Repeat([]byte{2}, 1024)}
+		for _, msg := range msgs {
+			assert.NoError(t, WriteFrame(&buf, msg, checksummed))
+		}
+		assert.Equal(t, 3*frameOverhead(checksummed)+1+1024, buf.Len())
+
+		for _, expected := range msgs {
+			msg, err := ReadFrame(&buf, 1024, checksummed)
+			assert.NoError(t, err)
+			assert.Equal(t, expected, msg)
+		}
+		_, err := ReadFrame(&buf, 1024, checksummed)
+		assert.Equal(t, io.EOF, err)
+	}
+}
+
+func TestFrameReaderReadsPlainFrames(t *testing.T) {
+	// A peer that doesn't support checksums writes [length][message] frames
+	buf := bytes.Buffer{}
+	msgs := [][]byte{{1}, bytes.Repeat([]byte{2}, 1024)}
+	for _, msg := range msgs {
+		header := [frameHeaderLen]byte{}
+		binary.BigEndian.PutUint32(header[:], uint32(len(msg)))
+		buf.Write(header[:])
+		buf.Write(msg)
+	}
+
+	reader := frameReader{
+		r:              &buf,
+		maxMessageSize: DefaultMaxMessageSize,
+	}
+	for _, expected := range msgs {
+		msg, read, err := reader.Read()
+		assert.NoError(t, err)
+		assert.Equal(t, expected, msg)
+		assert.Equal(t, frameHeaderLen+len(expected), read)
+	}
+	assert.False(t, reader.checksummed)
+}
+
+func TestFrameWriterWritesPlainFramesByDefault(t *testing.T) {
+	buf := bytes.Buffer{}
+	writer := frameWriter{w: &buf}
+	written, err := writer.Write([]byte{1, 2, 3})
+	assert.NoError(t, err)
+	assert.Equal(t, frameHeaderLen+3, written)
+
+	// A peer that doesn't support checksums reads [length][message] frames
+	assert.Equal(t, []byte{0, 0, 0, 3, 1, 2, 3}, buf.Bytes())
+}
+
+func TestFramesSwitchToChecksums(t *testing.T) {
+	buf := bytes.Buffer{}
+	writer := frameWriter{w: &buf}
+	_, err := writer.Write([]byte{1})
+	assert.NoError(t, err)
+	written, err := writer.EnableChecksums()
+	assert.NoError(t, err)
+	assert.Equal(t, frameHeaderLen, written)
+	// Checksums are only enabled once
+	written, err = writer.EnableChecksums()
+	assert.NoError(t, err)
+	assert.Zero(t, written)
+	written, err = writer.Write([]byte{2})
+	assert.NoError(t, err)
+	assert.Equal(t, frameOverhead(true)+1, written)
+
+	reader := frameReader{
+		r:              &buf,
+		maxMessageSize: DefaultMaxMessageSize,
+	}
+	msg, _, err := reader.Read()
+	assert.NoError(t, err)
+	assert.Equal(t, []byte{1}, msg)
+	assert.False(t, reader.checksummed)
+
+	// The marker is skipped
+	msg, read, err := reader.Read()
+	assert.NoError(t, err)
+	assert.Equal(t, []byte{2}, msg)
+	assert.Equal(t, frameHeaderLen+frameOverhead(true)+1, read)
+	assert.True(t, reader.checksummed)
+
+	_, _, err = reader.Read()
+	assert.Equal(t, io.EOF, err)
+}
+
+func TestReadFrameTooLarge(t *testing.T) {
+	// Only the length prefix is sent, so the frame must be rejected before
+	// the reader tries to read, or allocate, the message
+	header := [frameHeaderLen]byte{}
+	binary.BigEndian.PutUint32(header[:], ^uint32(0))
+
+	_, err := ReadFrame(bytes.NewReader(header[:]), DefaultMaxMessageSize, true)
+	assert.True(t, errors.Is(err, errMessageTooLarge))
+
+	buf := bytes.Buffer{}
+	assert.NoError(t, WriteFrame(&buf, make([]byte, 11), true))
+	_, err = ReadFrame(&buf, 10, true)
+	assert.True(t, errors.Is(err, errMessageTooLarge))
+}
+
+func TestReadFrameInvalidChecksum(t *testing.T) {
+	buf := bytes.Buffer{}
+	assert.NoError(t, WriteFrame(&buf, []byte{1, 2, 3}, true))
+	frame := buf.Bytes()
+
+	corrupted := append([]byte(nil), frame...)
+	corrupted[frameHeaderLen] ^= 1
+	_, err := ReadFrame(bytes.NewReader(corrupted), DefaultMaxMessageSize, true)
+	assert.Equal(t, errInvalidChecksum, err)
+
+	corrupted = append([]byte(nil), frame...)
+	corrupted[len(corrupted)-1] ^= 1
+	_, err = ReadFrame(bytes.NewReader(corrupted), DefaultMaxMessageSize, true)
+	assert.Equal(t, errInvalidChecksum, err)
+}
+
+func TestReadFrameTruncated(t *testing.T) {
+	buf := bytes.Buffer{}
+	assert.NoError(t, WriteFrame(&buf, []byte{1, 2, 3}, true))
+	frame := buf.Bytes()
+
+	for i := 1; i < len(frame); i++ {
+		_, err := ReadFrame(bytes.NewReader(frame[:i]), DefaultMaxMessageSize, true)
+		assert.Equal(t, io.ErrUnexpectedEOF, err, "frame truncated to %d bytes", i)
+	}
+}
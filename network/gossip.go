@@ -0,0 +1,38 @@
+// (c) 2019-2020, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package network
+
+import (
+	"time"
+)
+
+// GossipConfig configures how the network gossips containers and peer lists
+type GossipConfig struct {
+	// Number of peers each container is gossiped to
+	Size int
+
+	// How often the network gossips its peer list. Must be positive
+	PeerListFrequency time.Duration
+
+	// If true, containers are only gossiped to peers that are validators
+	ValidatorsOnly bool
+
+	// Number of containers gossiped to this node that are remembered, so that
+	// copies gossiped by other peers aren't routed to the chains again. If 0,
+	// every gossiped container is routed.
+	DuplicateCacheSize int
+
+	// How long a gossiped container is remembered for
+	DuplicateWindow time.Duration
+}
+
+// DefaultGossipConfig gossips each container to 50 peers, whether or not they
+// are validators, and gossips the peer list every minute. Copies of a gossiped
+// container received within 30 seconds of the first aren't routed.
+var DefaultGossipConfig = GossipConfig{
+	Size:               defaultGossipSize,
+	PeerListFrequency:  defaultPeerListGossipSpacing,
+	DuplicateCacheSize: defaultGossipDuplicateCacheSize,
+	DuplicateWindow:    defaultGossipDuplicateWindow,
+}
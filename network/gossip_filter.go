@@ -0,0 +1,54 @@
+// (c) 2019-2020, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package network
+
+import (
+	"time"
+
+	"github.com/ava-labs/avalanchego/cache"
+	"github.com/ava-labs/avalanchego/ids"
+	"github.com/ava-labs/avalanchego/utils/hashing"
+)
+
+// gossipFilter remembers the containers that were recently gossiped to this
+// node, so that a container gossiped by many peers is only routed once. The
+// container ID claimed by the sender isn't trusted, so containers are
+// identified by the hash of their bytes.
+type gossipFilter struct {
+	window time.Duration
+
+	// Hash of the chain ID and container --> time.Time it was last routed
+	seen cache.LRU
+}
+
+// newGossipFilter returns a filter that remembers up to [size] containers for
+// [window] each. If [size] is 0, nil is returned, which filters nothing.
+func newGossipFilter(size int, window time.Duration) *gossipFilter {
+	if size == 0 {
+		return nil
+	}
+	return &gossipFilter{
+		window: window,
+		seen:   cache.LRU{Size: size},
+	}
+}
+
+// Duplicate returns true if [container] was gossiped on [chainID] within the
+// window before [now]. Otherwise, the container is recorded as seen at [now].
+func (f *gossipFilter) Duplicate(chainID ids.ID, container []byte, now time.Time) bool {
+	if f == nil {
+		return false
+	}
+
+	key := make([]byte, len(chainID)+len(container))
+	copy(key, chainID[:])
+	copy(key[len(chainID):], container)
+	hash := ids.ID(hashing.ComputeHash256Array(key))
+
+	if seenIntf, ok := f.seen.Get(hash); ok && now.Sub(seenIntf.(time.Time)) < f.window {
+		return true
+	}
+	f.seen.Put(hash, now)
+	return false
+}
@@ -0,0 +1,43 @@
+// (c) 2019-2020, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package network
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/ava-labs/avalanchego/ids"
+)
+
+func TestGossipFilter(t *testing.T) {
+	assert := assert.New(t)
+
+	f := newGossipFilter(64, time.Minute)
+	chainID := ids.GenerateTestID()
+	container := []byte{1, 2, 3}
+	now := time.Now()
+
+	assert.False(f.Duplicate(chainID, container, now))
+	assert.True(f.Duplicate(chainID, container, now.Add(time.Second)))
+
+	// The same bytes gossiped on another chain, and other bytes on the same
+	// chain, aren't duplicates
+	assert.False(f.Duplicate(ids.GenerateTestID(), container, now))
+	assert.False(f.Duplicate(chainID, []byte{1, 2}, now))
+
+	// Containers are forgotten once the window passes
+	assert.False(f.Duplicate(chainID, container, now.Add(time.Minute)))
+	assert.True(f.Duplicate(chainID, container, now.Add(time.Minute+time.Second)))
+}
+
+func TestGossipFilterDisabled(t *testing.T) {
+	f := newGossipFilter(0, time.Minute)
+	chainID := ids.GenerateTestID()
+	now := time.Now()
+
+	assert.False(t, f.Duplicate(chainID, []byte{1}, now))
+	assert.False(t, f.Duplicate(chainID, []byte{1}, now))
+}
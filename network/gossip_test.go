@@ -0,0 +1,109 @@
+// (c) 2019-2020, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package network
+
+import (
+	"net"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/ava-labs/avalanchego/database/memdb"
+	"github.com/ava-labs/avalanchego/ids"
+	"github.com/ava-labs/avalanchego/network/networktest"
+	"github.com/ava-labs/avalanchego/snow/networking/benchlist"
+	"github.com/ava-labs/avalanchego/snow/validators"
+	"github.com/ava-labs/avalanchego/utils"
+	"github.com/ava-labs/avalanchego/utils/hashing"
+	"github.com/ava-labs/avalanchego/utils/logging"
+	"github.com/ava-labs/avalanchego/version"
+)
+
+func TestSampleGossipPeers(t *testing.T) {
+	ip := utils.NewDynamicIPDesc(
+		net.IPv6loopback,
+		0,
+	)
+	id := ids.ShortID(hashing.ComputeHash160Array([]byte(ip.IP().String())))
+
+	listener := networktest.NewListener(&net.TCPAddr{
+		IP:   net.IPv6loopback,
+		Port: 0,
+	})
+	caller := networktest.NewDialer(&net.TCPAddr{
+		IP:   net.IPv6loopback,
+		Port: 0,
+	})
+
+	vdrs := validators.NewSet()
+	netwrk := NewDefaultNetwork(
+		prometheus.NewRegistry(),
+		logging.NoLog{},
+		id,
+		ip,
+		0,
+		version.NewDefaultVersion("app", 0, 1, 0),
+		version.NewDefaultParser(),
+		[]net.Listener{listener},
+		caller,
+		NewIPUpgrader(),
+		NewIPUpgrader(),
+		vdrs,
+		vdrs,
+		&testHandler{},
+		time.Duration(0),
+		0,
+		nil,
+		false,
+		0,
+		0,
+		time.Now(),
+		defaultSendQueueSize,
+		DefaultSendQueueConfig,
+		BandwidthConfig{},
+		GossipConfig{
+			Size:              2,
+			PeerListFrequency: time.Minute,
+		},
+		DefaultConnectionConfig,
+		HealthConfig{},
+		benchlist.NewManager(&benchlist.Config{}),
+		memdb.New(),
+		defaultAliasTimeout,
+		nil,
+	)
+	basenetwork := netwrk.(*network)
+
+	validatorID := ids.GenerateTestShortID()
+	assert.NoError(t, vdrs.AddWeight(validatorID, 1))
+
+	basenetwork.stateLock.Lock()
+	for _, peerID := range []ids.ShortID{validatorID, ids.GenerateTestShortID(), ids.GenerateTestShortID()} {
+		p := newPeer(basenetwork, nil, utils.IPDesc{})
+		p.id = peerID
+		basenetwork.peers[peerID] = p
+	}
+	basenetwork.stateLock.Unlock()
+
+	// The gossip size bounds the number of peers gossiped to
+	peers, err := basenetwork.sampleGossipPeers(false)
+	assert.NoError(t, err)
+	assert.Len(t, peers, 2)
+
+	peers, err = basenetwork.sampleGossipPeers(true)
+	assert.NoError(t, err)
+	if assert.Len(t, peers, 1) {
+		assert.Equal(t, validatorID, peers[0].id)
+	}
+
+	// The network may be configured to only gossip to validators
+	basenetwork.gossipValidatorsOnly = true
+	peers, err = basenetwork.sampleGossipPeers(false)
+	assert.NoError(t, err)
+	if assert.Len(t, peers, 1) {
+		assert.Equal(t, validatorID, peers[0].id)
+	}
+}
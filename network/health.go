@@ -28,4 +28,9 @@ type HealthConfig struct {
 	// Must be > 0.
 	// Larger value --> Drop rate affected less by recent messages
 	MaxSendFailRateHalflife time.Duration
+
+	// If the median offset of connected peers' clocks from ours is larger
+	// than this, our clock is assumed to have drifted and will report
+	// unhealthy. 0 disables the check.
+	MaxClockSkew time.Duration
 }
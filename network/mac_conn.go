@@ -0,0 +1,236 @@
+// (c) 2019-2020, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package network
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"hash"
+	"io"
+	"net"
+
+	"golang.org/x/crypto/curve25519"
+
+	"github.com/ava-labs/avalanchego/ids"
+	"github.com/ava-labs/avalanchego/utils/wrappers"
+)
+
+// Capability flags that are negotiated during the handshake of an upgrader
+// returned by NewMACIPUpgrader. A capability is only enabled if both sides of
+// the connection request it.
+const (
+	// CapabilityMAC authenticates every frame written to the connection with
+	// an HMAC keyed by the handshake's shared secret. Unlike other
+	// capabilities, a side that requests CapabilityMAC rejects peers that
+	// don't, so that it's never silently downgraded to an unauthenticated
+	// connection.
+	CapabilityMAC uint8 = 1 << iota
+)
+
+const (
+	// handshakeLen is the length of the handshake each side sends: a byte of
+	// capability flags followed by an ephemeral X25519 public key
+	handshakeLen = 1 + curve25519.PointSize
+
+	macLen = sha256.Size
+
+	// maxFrameSize is the largest frame that will be read from a MAC
+	// authenticated connection. Each write of a message, or of the length
+	// prefix of a message, is sent in its own frame.
+	maxFrameSize = DefaultMaxMessageSize + wrappers.IntLen
+)
+
+var (
+	errInvalidMAC      = errors.New("frame has an invalid MAC")
+	errFrameTooLarge   = errors.New("frame is too large")
+	errInvalidPeerKey  = errors.New("peer sent an invalid handshake key")
+	errIdenticalPubKey = errors.New("peer sent our own handshake key")
+	errMACRequired     = errors.New("peer didn't negotiate a MAC")
+)
+
+type macIPUpgrader struct {
+	capabilities uint8
+}
+
+// NewMACIPUpgrader returns an upgrader that identifies peers by their IP, like
+// NewIPUpgrader, but first performs a handshake with the peer to negotiate
+// [capabilities] and to agree on a shared secret. If both sides request
+// CapabilityMAC, the returned connection authenticates every frame so that
+// messages can't be tampered with by on-path actors.
+//
+// The handshake isn't authenticated, so this doesn't protect against an
+// actor that is on-path when the connection is established. However, an
+// on-path actor can't strip CapabilityMAC from the handshake to disable the
+// MAC, as the side that requested it will reject the connection.
+//
+// Both sides of a connection must use this upgrader. A peer that uses
+// NewIPUpgrader doesn't send a handshake, so the start of its first message is
+// read as one. Messages are prefixed by their big-endian length, so as long as
+// the maximum message size is less than 2^24 bytes, as the default is, the
+// capability byte read from such a peer is always 0 and the connection is
+// rejected if CapabilityMAC was requested. Likewise, the plain peer reads our
+// handshake as the length of a message, which it rejects as too large.
+func NewMACIPUpgrader(capabilities uint8) Upgrader {
+	return macIPUpgrader{capabilities: capabilities}
+}
+
+func (u macIPUpgrader) Upgrade(conn net.Conn) (ids.ShortID, net.Conn, error) {
+	id, _, _ := ipUpgrader{}.Upgrade(conn)
+
+	privKey := make([]byte, curve25519.ScalarSize)
+	if _, err := rand.Read(privKey); err != nil {
+		return ids.ShortID{}, nil, err
+	}
+	pubKey, err := curve25519.X25519(privKey, curve25519.Basepoint)
+	if err != nil {
+		return ids.ShortID{}, nil, err
+	}
+
+	handshake := make([]byte, handshakeLen)
+	handshake[0] = u.capabilities
+	copy(handshake[1:], pubKey)
+	// Both sides write their handshake before reading the other's, so the
+	// write can't block the read
+	writeErr := make(chan error, 1)
+	go func() {
+		_, err := conn.Write(handshake)
+		writeErr <- err
+	}()
+
+	peerHandshake := make([]byte, handshakeLen)
+	if _, err := io.ReadFull(conn, peerHandshake); err != nil {
+		return ids.ShortID{}, nil, fmt.Errorf("couldn't read handshake: %w", err)
+	}
+	if err := <-writeErr; err != nil {
+		return ids.ShortID{}, nil, fmt.Errorf("couldn't write handshake: %w", err)
+	}
+	peerPubKey := peerHandshake[1:]
+
+	if u.capabilities&CapabilityMAC == 0 {
+		return id, conn, nil
+	}
+	if peerHandshake[0]&CapabilityMAC == 0 {
+		return ids.ShortID{}, nil, errMACRequired
+	}
+
+	if hmac.Equal(pubKey, peerPubKey) {
+		return ids.ShortID{}, nil, errIdenticalPubKey
+	}
+	secret, err := curve25519.X25519(privKey, peerPubKey)
+	if err != nil {
+		return ids.ShortID{}, nil, fmt.Errorf("%w: %s", errInvalidPeerKey, err)
+	}
+	return id, newMACConn(conn, secret, pubKey, peerPubKey), nil
+}
+
+// macConn authenticates every write to the underlying connection. Each write
+// is sent as a frame of:
+//
+//	[length of payload][payload][HMAC of sequence number, length, payload]
+//
+// Each direction is keyed separately, by the shared secret and the sender's
+// handshake key, and has its own sequence number so that frames can't be
+// reflected, replayed, or reordered.
+//
+// Reads and writes may happen concurrently, but there may only be one reader
+// and one writer at a time.
+type macConn struct {
+	net.Conn
+
+	writeMAC hash.Hash
+	writeSeq uint64
+
+	readMAC hash.Hash
+	readSeq uint64
+	// pendingRead is the part of the last read frame's payload that hasn't
+	// been returned by Read yet
+	pendingRead []byte
+}
+
+func newMACConn(conn net.Conn, secret, pubKey, peerPubKey []byte) *macConn {
+	return &macConn{
+		Conn:     conn,
+		writeMAC: hmac.New(sha256.New, deriveMACKey(secret, pubKey)),
+		readMAC:  hmac.New(sha256.New, deriveMACKey(secret, peerPubKey)),
+	}
+}
+
+// deriveMACKey returns the key for frames sent by the owner of [pubKey]
+func deriveMACKey(secret, pubKey []byte) []byte {
+	h := sha256.New()
+	_, _ = h.Write(secret)
+	_, _ = h.Write(pubKey)
+	return h.Sum(nil)
+}
+
+// frameMAC returns the MAC of a frame with the given sequence number and
+// payload
+func frameMAC(mac hash.Hash, seq uint64, header, payload []byte) []byte {
+	seqBytes := [wrappers.LongLen]byte{}
+	binary.BigEndian.PutUint64(seqBytes[:], seq)
+
+	mac.Reset()
+	_, _ = mac.Write(seqBytes[:])
+	_, _ = mac.Write(header)
+	_, _ = mac.Write(payload)
+	return mac.Sum(nil)
+}
+
+func (c *macConn) Write(b []byte) (int, error) {
+	if uint64(len(b)) > uint64(maxFrameSize) {
+		return 0, errFrameTooLarge
+	}
+
+	frame := make([]byte, wrappers.IntLen, wrappers.IntLen+len(b)+macLen)
+	binary.BigEndian.PutUint32(frame, uint32(len(b)))
+	frame = append(frame, b...)
+	frame = append(frame, frameMAC(c.writeMAC, c.writeSeq, frame[:wrappers.IntLen], b)...)
+	c.writeSeq++
+
+	if _, err := c.Conn.Write(frame); err != nil {
+		return 0, err
+	}
+	return len(b), nil
+}
+
+func (c *macConn) Read(b []byte) (int, error) {
+	for len(c.pendingRead) == 0 {
+		if err := c.readFrame(); err != nil {
+			return 0, err
+		}
+	}
+
+	n := copy(b, c.pendingRead)
+	c.pendingRead = c.pendingRead[n:]
+	return n, nil
+}
+
+// readFrame reads the next frame from the underlying connection into
+// [pendingRead], after verifying its MAC
+func (c *macConn) readFrame() error {
+	header := [wrappers.IntLen]byte{}
+	if _, err := io.ReadFull(c.Conn, header[:]); err != nil {
+		return err
+	}
+	size := binary.BigEndian.Uint32(header[:])
+	if size > maxFrameSize {
+		return fmt.Errorf("%w: %d > %d", errFrameTooLarge, size, maxFrameSize)
+	}
+
+	frame := make([]byte, int(size)+macLen)
+	if _, err := io.ReadFull(c.Conn, frame); err != nil {
+		return err
+	}
+	payload, mac := frame[:size], frame[size:]
+	if !hmac.Equal(mac, frameMAC(c.readMAC, c.readSeq, header[:], payload)) {
+		return errInvalidMAC
+	}
+	c.readSeq++
+	c.pendingRead = payload
+	return nil
+}
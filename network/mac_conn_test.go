@@ -0,0 +1,150 @@
+// (c) 2019-2020, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package network
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"net"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/ava-labs/avalanchego/ids"
+)
+
+// bufferConn is a net.Conn that reads from and writes to a buffer
+type bufferConn struct {
+	net.Conn
+	bytes.Buffer
+}
+
+func (c *bufferConn) Read(b []byte) (int, error)  { return c.Buffer.Read(b) }
+func (c *bufferConn) Write(b []byte) (int, error) { return c.Buffer.Write(b) }
+
+type upgradeResult struct {
+	id   ids.ShortID
+	conn net.Conn
+	err  error
+}
+
+// upgradePipe upgrades both ends of a pipe with the provided upgraders
+func upgradePipe(server, client Upgrader) (upgradeResult, upgradeResult) {
+	serverConn, clientConn := net.Pipe()
+	results := make(chan upgradeResult)
+	go func() {
+		id, conn, err := server.Upgrade(serverConn)
+		results <- upgradeResult{id: id, conn: conn, err: err}
+	}()
+	id, conn, err := client.Upgrade(clientConn)
+	return <-results, upgradeResult{id: id, conn: conn, err: err}
+}
+
+func TestMACIPUpgrader(t *testing.T) {
+	upgrader := NewMACIPUpgrader(CapabilityMAC)
+	server, client := upgradePipe(upgrader, upgrader)
+	assert.NoError(t, server.err)
+	assert.NoError(t, client.err)
+	assert.IsType(t, &macConn{}, server.conn)
+	assert.IsType(t, &macConn{}, client.conn)
+
+	msgs := [][]byte{{1, 2, 3}, {}, bytes.Repeat([]byte{4}, 1024)}
+	go func() {
+		for _, msg := range msgs {
+			if _, err := client.conn.Write(msg); err != nil {
+				return
+			}
+		}
+	}()
+
+	for _, msg := range msgs {
+		if len(msg) == 0 {
+			continue
+		}
+		read := make([]byte, len(msg))
+		_, err := io.ReadFull(server.conn, read)
+		assert.NoError(t, err)
+		assert.Equal(t, msg, read)
+	}
+}
+
+func TestMACIPUpgraderNegotiation(t *testing.T) {
+	server, client := upgradePipe(NewMACIPUpgrader(CapabilityMAC), NewMACIPUpgrader(0))
+	assert.True(t, errors.Is(server.err, errMACRequired), "should reject a peer that doesn't request the MAC")
+	assert.NoError(t, client.err)
+
+	_, clientMAC := client.conn.(*macConn)
+	assert.False(t, clientMAC, "shouldn't enable the MAC unless both sides request it")
+
+	server, client = upgradePipe(NewMACIPUpgrader(0), NewMACIPUpgrader(0))
+	assert.NoError(t, server.err)
+	assert.NoError(t, client.err)
+}
+
+func TestMACIPUpgraderRejectsPlainPeer(t *testing.T) {
+	serverConn, clientConn := net.Pipe()
+	go func() {
+		// The plain peer starts by sending a length prefixed message, and
+		// reads the start of the handshake as the length of a message
+		msg := make([]byte, handshakeLen)
+		msg[3] = handshakeLen - 4
+		_, _ = clientConn.Write(msg)
+		_, _ = io.ReadFull(clientConn, make([]byte, handshakeLen))
+	}()
+
+	_, _, err := NewMACIPUpgrader(CapabilityMAC).Upgrade(serverConn)
+	assert.True(t, errors.Is(err, errMACRequired))
+}
+
+func TestMACConnRejectsTamperedFrames(t *testing.T) {
+	secret := []byte("secret")
+	senderKey, receiverKey := []byte("sender"), []byte("receiver")
+
+	newPair := func() (*macConn, *macConn, *bufferConn) {
+		buf := &bufferConn{}
+		return newMACConn(buf, secret, senderKey, receiverKey), newMACConn(buf, secret, receiverKey, senderKey), buf
+	}
+
+	// An untampered frame is accepted
+	sender, receiver, _ := newPair()
+	_, err := sender.Write([]byte{1, 2, 3})
+	assert.NoError(t, err)
+	read := make([]byte, 3)
+	_, err = receiver.Read(read)
+	assert.NoError(t, err)
+	assert.Equal(t, []byte{1, 2, 3}, read)
+
+	// A modified payload is rejected
+	sender, receiver, buf := newPair()
+	_, err = sender.Write([]byte{1, 2, 3})
+	assert.NoError(t, err)
+	buf.Bytes()[4] ^= 1
+	_, err = receiver.Read(read)
+	assert.True(t, errors.Is(err, errInvalidMAC))
+
+	// A replayed frame is rejected
+	sender, receiver, buf = newPair()
+	_, err = sender.Write([]byte{1, 2, 3})
+	assert.NoError(t, err)
+	frame := append([]byte(nil), buf.Bytes()...)
+	buf.Write(frame)
+	_, err = receiver.Read(read)
+	assert.NoError(t, err)
+	_, err = receiver.Read(read)
+	assert.True(t, errors.Is(err, errInvalidMAC))
+
+	// A frame reflected back to its sender is rejected
+	sender, _, _ = newPair()
+	_, err = sender.Write([]byte{1, 2, 3})
+	assert.NoError(t, err)
+	_, err = sender.Read(read)
+	assert.True(t, errors.Is(err, errInvalidMAC))
+
+	// An oversized frame is rejected before it's read
+	_, receiver, buf = newPair()
+	buf.Write([]byte{0xff, 0xff, 0xff, 0xff})
+	_, err = receiver.Read(read)
+	assert.True(t, errors.Is(err, errFrameTooLarge))
+}
@@ -62,6 +62,21 @@ type metrics struct {
 	timeSinceLastMsgReceived prometheus.Gauge
 	sendQueuePortionFull     prometheus.Gauge
 	sendFailRate             prometheus.Gauge
+	clockSkew                prometheus.Gauge
+
+	deprecatedPeers  prometheus.Gauge
+	rejectedVersions prometheus.Counter
+
+	chains chainMetrics
+
+	compressionRawBytes        prometheus.Counter
+	compressionCompressedBytes prometheus.Counter
+
+	throttledInbound  prometheus.Counter
+	throttledOutbound prometheus.Counter
+
+	gossipedPuts          prometheus.Counter
+	duplicateGossipedPuts prometheus.Counter
 
 	getVersion, version,
 	getPeerlist, peerlist,
@@ -69,7 +84,9 @@ type metrics struct {
 	getAcceptedFrontier, acceptedFrontier,
 	getAccepted, accepted,
 	get, getAncestors, put, multiPut,
-	pushQuery, pullQuery, chits messageMetrics
+	pushQuery, pullQuery, chits, justifiedChits,
+	gossipTxs, rotation,
+	capabilities, traced, hintedPut, compressed, timedPong messageMetrics
 }
 
 func (m *metrics) initialize(registerer prometheus.Registerer) error {
@@ -99,6 +116,53 @@ func (m *metrics) initialize(registerer prometheus.Registerer) error {
 		Name:      "send_fail_rate",
 		Help:      "Portion of messages that recently failed to be sent over the network",
 	})
+	m.clockSkew = prometheus.NewGauge(prometheus.GaugeOpts{
+		Namespace: constants.PlatformName,
+		Name:      "clock_skew",
+		Help:      "Median offset, in seconds, of connected peers' clocks from ours. Positive if peers' clocks are ahead of ours",
+	})
+
+	m.deprecatedPeers = prometheus.NewGauge(prometheus.GaugeOpts{
+		Namespace: constants.PlatformName,
+		Name:      "deprecated_peers",
+		Help:      "Number of connected peers running a deprecated version",
+	})
+	m.rejectedVersions = prometheus.NewCounter(prometheus.CounterOpts{
+		Namespace: constants.PlatformName,
+		Name:      "rejected_peer_versions",
+		Help:      "Number of handshakes refused because the peer's version isn't allowed",
+	})
+
+	m.compressionRawBytes = prometheus.NewCounter(prometheus.CounterOpts{
+		Namespace: constants.PlatformName,
+		Name:      "compression_raw_bytes",
+		Help:      "Size of the sent messages that were compressed, before compression",
+	})
+	m.compressionCompressedBytes = prometheus.NewCounter(prometheus.CounterOpts{
+		Namespace: constants.PlatformName,
+		Name:      "compression_compressed_bytes",
+		Help:      "Size of the sent messages that were compressed, after compression",
+	})
+	m.throttledInbound = prometheus.NewCounter(prometheus.CounterOpts{
+		Namespace: constants.PlatformName,
+		Name:      "throttled_inbound_msgs",
+		Help:      "Number of messages read from peers that were delayed by bandwidth limits",
+	})
+	m.throttledOutbound = prometheus.NewCounter(prometheus.CounterOpts{
+		Namespace: constants.PlatformName,
+		Name:      "throttled_outbound_msgs",
+		Help:      "Number of messages written to peers that were delayed by bandwidth limits",
+	})
+	m.gossipedPuts = prometheus.NewCounter(prometheus.CounterOpts{
+		Namespace: constants.PlatformName,
+		Name:      "gossiped_puts_received",
+		Help:      "Number of gossiped containers received from peers",
+	})
+	m.duplicateGossipedPuts = prometheus.NewCounter(prometheus.CounterOpts{
+		Namespace: constants.PlatformName,
+		Name:      "gossiped_puts_suppressed",
+		Help:      "Number of gossiped containers received from peers that weren't routed, as the container was recently received",
+	})
 
 	errs := wrappers.Errs{}
 	errs.Add(
@@ -107,6 +171,16 @@ func (m *metrics) initialize(registerer prometheus.Registerer) error {
 		registerer.Register(m.timeSinceLastMsgSent),
 		registerer.Register(m.sendQueuePortionFull),
 		registerer.Register(m.sendFailRate),
+		registerer.Register(m.clockSkew),
+		registerer.Register(m.deprecatedPeers),
+		registerer.Register(m.rejectedVersions),
+		registerer.Register(m.compressionRawBytes),
+		registerer.Register(m.compressionCompressedBytes),
+		registerer.Register(m.throttledInbound),
+		registerer.Register(m.throttledOutbound),
+		registerer.Register(m.gossipedPuts),
+		registerer.Register(m.duplicateGossipedPuts),
+		m.chains.initialize(registerer),
 
 		m.getVersion.initialize(GetVersion, registerer),
 		m.version.initialize(Version, registerer),
@@ -125,6 +199,14 @@ func (m *metrics) initialize(registerer prometheus.Registerer) error {
 		m.pushQuery.initialize(PushQuery, registerer),
 		m.pullQuery.initialize(PullQuery, registerer),
 		m.chits.initialize(Chits, registerer),
+		m.justifiedChits.initialize(JustifiedChits, registerer),
+		m.gossipTxs.initialize(GossipTxs, registerer),
+		m.rotation.initialize(Rotation, registerer),
+		m.capabilities.initialize(Capabilities, registerer),
+		m.traced.initialize(Traced, registerer),
+		m.hintedPut.initialize(HintedPut, registerer),
+		m.compressed.initialize(Compressed, registerer),
+		m.timedPong.initialize(TimedPong, registerer),
 	)
 	return errs.Err
 }
@@ -165,6 +247,22 @@ func (m *metrics) message(msgType Op) *messageMetrics {
 		return &m.pullQuery
 	case Chits:
 		return &m.chits
+	case JustifiedChits:
+		return &m.justifiedChits
+	case GossipTxs:
+		return &m.gossipTxs
+	case Rotation:
+		return &m.rotation
+	case Capabilities:
+		return &m.capabilities
+	case Traced:
+		return &m.traced
+	case HintedPut:
+		return &m.hintedPut
+	case Compressed:
+		return &m.compressed
+	case TimedPong:
+		return &m.timedPong
 	default:
 		return nil
 	}
@@ -0,0 +1,99 @@
+// (c) 2019-2020, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package network
+
+import (
+	"errors"
+	"net"
+	"sync"
+	"time"
+
+	"github.com/ava-labs/avalanchego/utils/wrappers"
+)
+
+var errListenerClosed = errors.New("listener closed")
+
+// acceptResult is the outcome of a call to Accept on one of the listeners of a
+// multiListener
+type acceptResult struct {
+	conn net.Conn
+	err  error
+}
+
+// multiListener accepts connections from several listeners, so that a node
+// can listen on several addresses
+type multiListener struct {
+	listeners []net.Listener
+
+	results   chan acceptResult
+	closed    chan struct{}
+	closeOnce sync.Once
+}
+
+// NewMultiListener returns a listener that accepts connections from each of
+// [listeners]. Accept returns the first error, other than a temporary error,
+// returned by any of the listeners. Closing the returned listener closes each
+// of [listeners]. The address of the returned listener is the address of the
+// first of [listeners].
+func NewMultiListener(listeners ...net.Listener) net.Listener {
+	if len(listeners) == 1 {
+		return listeners[0]
+	}
+
+	l := &multiListener{
+		listeners: listeners,
+		results:   make(chan acceptResult),
+		closed:    make(chan struct{}),
+	}
+	for _, listener := range listeners {
+		go l.accept(listener)
+	}
+	return l
+}
+
+// accept forwards the connections accepted by [listener] until it returns an
+// error that isn't temporary
+func (l *multiListener) accept(listener net.Listener) {
+	for {
+		conn, err := listener.Accept()
+		if netErr, ok := err.(net.Error); ok && netErr.Temporary() {
+			time.Sleep(time.Millisecond)
+			continue
+		}
+
+		select {
+		case l.results <- acceptResult{conn: conn, err: err}:
+		case <-l.closed:
+			if conn != nil {
+				_ = conn.Close()
+			}
+			return
+		}
+		if err != nil {
+			return
+		}
+	}
+}
+
+func (l *multiListener) Accept() (net.Conn, error) {
+	select {
+	case result := <-l.results:
+		return result.conn, result.err
+	case <-l.closed:
+		return nil, errListenerClosed
+	}
+}
+
+func (l *multiListener) Close() error {
+	errs := wrappers.Errs{}
+	l.closeOnce.Do(func() {
+		close(l.closed)
+		for _, listener := range l.listeners {
+			errs.Add(listener.Close())
+		}
+	})
+	return errs.Err
+}
+
+func (l *multiListener) Addr() net.Addr { return l.listeners[0].Addr() }
@@ -0,0 +1,42 @@
+// (c) 2019-2020, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package network
+
+import (
+	"net"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMultiListener(t *testing.T) {
+	listener0, err := net.Listen("tcp", "127.0.0.1:0")
+	assert.NoError(t, err)
+	listener1, err := net.Listen("tcp", "127.0.0.1:0")
+	assert.NoError(t, err)
+
+	assert.Equal(t, listener0, NewMultiListener(listener0))
+
+	listener := NewMultiListener(listener0, listener1)
+	assert.Equal(t, listener0.Addr(), listener.Addr())
+
+	for _, l := range []net.Listener{listener0, listener1} {
+		conn, err := net.Dial("tcp", l.Addr().String())
+		assert.NoError(t, err)
+
+		accepted, err := listener.Accept()
+		assert.NoError(t, err)
+		assert.Equal(t, conn.LocalAddr().String(), accepted.RemoteAddr().String())
+		assert.Equal(t, l.Addr().String(), accepted.LocalAddr().String())
+
+		assert.NoError(t, conn.Close())
+		assert.NoError(t, accepted.Close())
+	}
+
+	assert.NoError(t, listener.Close())
+	_, err = listener.Accept()
+	assert.Error(t, err)
+	_, err = net.Dial("tcp", listener1.Addr().String())
+	assert.Error(t, err, "closing the listener should close each of its listeners")
+}
@@ -14,6 +14,7 @@ import (
 
 	"github.com/prometheus/client_golang/prometheus"
 
+	"github.com/ava-labs/avalanchego/database"
 	"github.com/ava-labs/avalanchego/health"
 	"github.com/ava-labs/avalanchego/ids"
 	"github.com/ava-labs/avalanchego/snow"
@@ -46,11 +47,15 @@ const (
 	defaultGetVersionTimeout                         = 2 * time.Second
 	defaultAllowPrivateIPs                           = true
 	defaultGossipSize                                = 50
+	defaultGossipDuplicateCacheSize                  = 4096
+	defaultGossipDuplicateWindow                     = 30 * time.Second
 	defaultPingPongTimeout                           = time.Minute
 	defaultPingFrequency                             = 3 * defaultPingPongTimeout / 4
+	defaultMaxMissedPongs                            = 3
 	defaultReadBufferSize                            = 16 * 1024
 	defaultReadHandshakeTimeout                      = 15 * time.Second
 	defaultConnMeterCacheSize                        = 10000
+	defaultDialTimeout                               = 30 * time.Second
 )
 
 var (
@@ -80,8 +85,15 @@ type Network interface {
 
 	// Attempt to connect to this IP. Thread safety must be managed internally
 	// to the network. The network will never stop attempting to connect to this
-	// IP.
-	Track(ip utils.IPDesc)
+	// IP. Returns an error if connecting to the IP would exceed the connection
+	// limits.
+	Track(ip utils.IPDesc) error
+
+	// Attempt to connect to this IP, only accepting the connection if the node
+	// listening on it has the ID [nodeID]. Thread safety must be managed
+	// internally to the network. Returns an error if connecting to the node
+	// would exceed the connection limits.
+	TrackNode(ip utils.IPDesc, nodeID ids.ShortID) error
 
 	// Returns the description of the specified [nodeIDs] this network is currently
 	// connected to externally or all nodes this network is connected to if [nodeIDs]
@@ -96,6 +108,34 @@ type Network interface {
 	// Return the IP of the node
 	IP() utils.IPDesc
 
+	// Disconnect from, and refuse connections with, the peers with the node ID
+	// or IP [target] for [duration]. Allowlisted peers can't be blacklisted.
+	// The blacklist is persisted across restarts. Thread safety must be
+	// managed internally to the network.
+	Blacklist(target string, duration time.Duration) error
+
+	// Remove the node ID or IP [target] from the blacklist. Thread safety must
+	// be managed internally to the network.
+	Unblacklist(target string) error
+
+	// Exempt the peers with the node ID or IP [target] from the blacklist,
+	// lifting any existing ban. The allowlist is persisted across restarts.
+	// Thread safety must be managed internally to the network.
+	Allowlist(target string) error
+
+	// Remove the node ID or IP [target] from the allowlist. Thread safety must
+	// be managed internally to the network.
+	Unallowlist(target string) error
+
+	// Notify [handler] of the lifecycle events of peers until it's
+	// deregistered under [name]. Thread safety must be managed internally to
+	// the network.
+	RegisterPeerHandler(name string, handler PeerHandler) error
+
+	// Stop notifying the handler registered under [name]. Thread safety must
+	// be managed internally to the network.
+	DeregisterPeerHandler(name string) error
+
 	// Has a health check
 	health.Checkable
 }
@@ -112,6 +152,9 @@ type network struct {
 	// Unix time at which last message of any type sent over network
 	// Must only be accessed atomically
 	lastMsgSentTime int64
+	// Most recently generated trace ID
+	// Must only be accessed atomically
+	lastTraceID uint64
 	// Keeps track of the percentage of sends that fail
 	sendFailRateCalculator             math.Averager
 	log                                logging.Logger
@@ -133,6 +176,9 @@ type network struct {
 	maxReconnectDelay                  time.Duration
 	maxMessageSize                     int64
 	sendQueueSize                      uint32
+	sendQueueConfig                    SendQueueConfig
+	inboundBandwidth                   bandwidthLimiter
+	outboundBandwidth                  bandwidthLimiter
 	maxNetworkPendingSendBytes         int64
 	networkPendingSendBytesToRateLimit int64
 	maxClockDifference                 time.Duration
@@ -142,12 +188,16 @@ type network struct {
 	getVersionTimeout                  time.Duration
 	allowPrivateIPs                    bool
 	gossipSize                         int
+	gossipValidatorsOnly               bool
+	gossipFilter                       *gossipFilter
 	pingPongTimeout                    time.Duration
 	pingFrequency                      time.Duration
+	maxMissedPongs                     uint32
 	readBufferSize                     uint32
 	readHandshakeTimeout               time.Duration
 	connMeterMaxConns                  int
 	connMeter                          ConnMeter
+	connectionConfig                   ConnectionConfig
 	b                                  Builder
 	apricotPhase0Time                  time.Time
 
@@ -156,6 +206,13 @@ type network struct {
 	pendingBytes int64
 	closed       utils.AtomicBool
 	peers        map[ids.ShortID]*peer
+	// previous node ID --> peer that has rotated its staking certificate
+	// away from that ID. [stateLock] should be held when accessing it.
+	previousIDs map[ids.ShortID]*peer
+
+	// identityRotation, if non-nil, is sent to peers so that they recognize
+	// this node by its previous ID
+	identityRotation *IdentityRotation
 
 	// disconnectedIPs, connectedIPs, peerAliasIPs, and myIPs
 	// are maps with ip.String() keys that are used to determine if
@@ -167,6 +224,11 @@ type network struct {
 	// TODO: bound the size of [myIPs] to avoid DoS. LRU caching would be ideal
 	myIPs map[string]struct{} // set of IPs that resulted in my ID.
 
+	// ip.String() --> ID the node at that IP must have. Connections we dial to
+	// the IP are dropped if the peer has a different ID. [stateLock] should be
+	// held when accessing it.
+	expectedIDs map[string]ids.ShortID
+
 	// retryDelay is a map with ip.String() keys that is used to track
 	// the backoff delay we should wait before attempting to dial an IP address
 	// again.
@@ -205,10 +267,23 @@ type network struct {
 	maskedValidators ids.ShortSet
 
 	benchlistManager benchlist.Manager
+
+	// Peers, by node ID or IP, that connections are refused with
+	blacklist *peerBlacklist
+
+	// Peers connected to, persisted so that they are reconnected to after a
+	// restart. [stateLock] should be held when accessing it.
+	peerStore *peerStore
+
+	// Components observing the lifecycle of peers
+	peerHandlers peerHandlers
 }
 
 // NewDefaultNetwork returns a new Network implementation with the provided
-// parameters and some reasonable default values.
+// parameters and some reasonable default values. Connections are accepted from
+// each of [listeners], of which there must be at least one. [ip] is the IP that
+// is advertised to peers, which may differ from the addresses that are
+// listened on, such as when the node is behind a load balancer.
 func NewDefaultNetwork(
 	registerer prometheus.Registerer,
 	log logging.Logger,
@@ -217,7 +292,7 @@ func NewDefaultNetwork(
 	networkID uint32,
 	version version.Version,
 	parser version.Parser,
-	listener net.Listener,
+	listeners []net.Listener,
 	dialer Dialer,
 	serverUpgrader,
 	clientUpgrader Upgrader,
@@ -232,10 +307,20 @@ func NewDefaultNetwork(
 	disconnectedRestartTimeout time.Duration,
 	apricotPhase0Time time.Time,
 	sendQueueSize uint32,
+	sendQueueConfig SendQueueConfig,
+	bandwidthConfig BandwidthConfig,
+	gossipConfig GossipConfig,
+	connectionConfig ConnectionConfig,
 	healthConfig HealthConfig,
 	benchlistManager benchlist.Manager,
+	db database.Database,
 	peerAliasTimeout time.Duration,
+	rotation *IdentityRotation,
 ) Network {
+	maxMessageSize := connectionConfig.MaxMessageSize
+	if maxMessageSize == 0 {
+		maxMessageSize = DefaultMaxMessageSize
+	}
 	return NewNetwork(
 		registerer,
 		log,
@@ -244,7 +329,7 @@ func NewDefaultNetwork(
 		networkID,
 		version,
 		parser,
-		listener,
+		NewMultiListener(listeners...),
 		dialer,
 		serverUpgrader,
 		clientUpgrader,
@@ -253,19 +338,26 @@ func NewDefaultNetwork(
 		router,
 		defaultInitialReconnectDelay,
 		defaultMaxReconnectDelay,
-		DefaultMaxMessageSize,
+		maxMessageSize,
 		sendQueueSize,
+		sendQueueConfig,
+		bandwidthConfig,
+		connectionConfig,
 		defaultMaxNetworkPendingSendBytes,
 		defaultNetworkPendingSendBytesToRateLimit,
 		defaultMaxClockDifference,
-		defaultPeerListGossipSpacing,
+		gossipConfig.PeerListFrequency,
 		defaultPeerListGossipSize,
 		defaultPeerListStakerGossipFraction,
 		defaultGetVersionTimeout,
 		defaultAllowPrivateIPs,
-		defaultGossipSize,
+		gossipConfig.Size,
+		gossipConfig.ValidatorsOnly,
+		gossipConfig.DuplicateCacheSize,
+		gossipConfig.DuplicateWindow,
 		defaultPingPongTimeout,
 		defaultPingFrequency,
+		defaultMaxMissedPongs,
 		defaultReadBufferSize,
 		defaultReadHandshakeTimeout,
 		connMeterResetDuration,
@@ -278,7 +370,9 @@ func NewDefaultNetwork(
 		apricotPhase0Time,
 		healthConfig,
 		benchlistManager,
+		db,
 		peerAliasTimeout,
+		rotation,
 	)
 }
 
@@ -302,6 +396,9 @@ func NewNetwork(
 	maxReconnectDelay time.Duration,
 	maxMessageSize uint32,
 	sendQueueSize uint32,
+	sendQueueConfig SendQueueConfig,
+	bandwidthConfig BandwidthConfig,
+	connectionConfig ConnectionConfig,
 	maxNetworkPendingSendBytes int,
 	networkPendingSendBytesToRateLimit int,
 	maxClockDifference time.Duration,
@@ -311,8 +408,12 @@ func NewNetwork(
 	getVersionTimeout time.Duration,
 	allowPrivateIPs bool,
 	gossipSize int,
+	gossipValidatorsOnly bool,
+	gossipDuplicateCacheSize int,
+	gossipDuplicateWindow time.Duration,
 	pingPongTimeout time.Duration,
 	pingFrequency time.Duration,
+	maxMissedPongs uint32,
 	readBufferSize uint32,
 	readHandshakeTimeout time.Duration,
 	connMeterResetDuration time.Duration,
@@ -325,7 +426,9 @@ func NewNetwork(
 	apricotPhase0Time time.Time,
 	healthConfig HealthConfig,
 	benchlistManager benchlist.Manager,
+	db database.Database,
 	peerAliasTimeout time.Duration,
+	rotation *IdentityRotation,
 ) Network {
 	// #nosec G404
 	netw := &network{
@@ -336,7 +439,7 @@ func NewNetwork(
 		msgVersion:     version,
 		parser:         parser,
 		listener:       listener,
-		dialer:         dialer,
+		dialer:         newTimeoutDialer(dialer, connectionConfig.DialTimeout),
 		serverUpgrader: serverUpgrader,
 		clientUpgrader: clientUpgrader,
 		vdrs:           vdrs,
@@ -350,6 +453,7 @@ func NewNetwork(
 		maxReconnectDelay:                  maxReconnectDelay,
 		maxMessageSize:                     int64(maxMessageSize),
 		sendQueueSize:                      sendQueueSize,
+		sendQueueConfig:                    sendQueueConfig,
 		maxNetworkPendingSendBytes:         int64(maxNetworkPendingSendBytes),
 		networkPendingSendBytesToRateLimit: int64(networkPendingSendBytesToRateLimit),
 		maxClockDifference:                 maxClockDifference,
@@ -359,19 +463,26 @@ func NewNetwork(
 		getVersionTimeout:                  getVersionTimeout,
 		allowPrivateIPs:                    allowPrivateIPs,
 		gossipSize:                         gossipSize,
+		gossipValidatorsOnly:               gossipValidatorsOnly,
+		gossipFilter:                       newGossipFilter(gossipDuplicateCacheSize, gossipDuplicateWindow),
 		pingPongTimeout:                    pingPongTimeout,
 		pingFrequency:                      pingFrequency,
+		maxMissedPongs:                     maxMissedPongs,
 		disconnectedIPs:                    make(map[string]struct{}),
 		connectedIPs:                       make(map[string]struct{}),
+		expectedIDs:                        make(map[string]ids.ShortID),
 		peerAliasIPs:                       make(map[string]struct{}),
 		peerAliasTimeout:                   peerAliasTimeout,
 		retryDelay:                         make(map[string]time.Duration),
 		myIPs:                              map[string]struct{}{ip.IP().String(): {}},
 		peers:                              make(map[ids.ShortID]*peer),
+		previousIDs:                        make(map[ids.ShortID]*peer),
+		identityRotation:                   rotation,
 		readBufferSize:                     readBufferSize,
 		readHandshakeTimeout:               readHandshakeTimeout,
 		connMeter:                          NewConnMeter(connMeterResetDuration, connMeterCacheSize),
 		connMeterMaxConns:                  connMeterMaxConns,
+		connectionConfig:                   connectionConfig,
 		restartOnDisconnected:              restartOnDisconnected,
 		connectedCheckerCloser:             make(chan struct{}),
 		disconnectedCheckFreq:              disconnectedCheckFreq,
@@ -381,7 +492,30 @@ func NewNetwork(
 		healthConfig:                       healthConfig,
 		benchlistManager:                   benchlistManager,
 	}
+	netw.inboundBandwidth = newBandwidthLimiter(
+		bandwidthConfig.PeerInboundRate,
+		bandwidthConfig.PeerInboundBurst,
+		bandwidthConfig.InboundRate,
+		bandwidthConfig.InboundBurst,
+	)
+	netw.outboundBandwidth = newBandwidthLimiter(
+		bandwidthConfig.PeerOutboundRate,
+		bandwidthConfig.PeerOutboundBurst,
+		bandwidthConfig.OutboundRate,
+		bandwidthConfig.OutboundBurst,
+	)
+	netw.blacklist = newPeerBlacklist(db)
+	if err := netw.blacklist.load(netw.clock.Time()); err != nil {
+		log.Error("loading the peer blacklist failed with: %s", err)
+	}
+	netw.peerStore = newPeerStore(db)
+	if err := netw.peerStore.load(netw.clock.Time()); err != nil {
+		log.Error("loading the stored peers failed with: %s", err)
+	}
 	netw.sendFailRateCalculator = math.NewSyncAverager(math.NewAverager(0, healthConfig.MaxSendFailRateHalflife, netw.clock.Time()))
+	// Trace IDs are seeded with the current time so that they aren't reused
+	// after a restart
+	netw.lastTraceID = uint64(netw.clock.Time().UnixNano())
 
 	if err := netw.initialize(registerer); err != nil {
 		log.Warn("initializing network metrics failed with: %s", err)
@@ -398,6 +532,7 @@ func NewNetwork(
 // GetAcceptedFrontier implements the Sender interface.
 // assumes the stateLock is not held.
 func (n *network) GetAcceptedFrontier(validatorIDs ids.ShortSet, chainID ids.ID, requestID uint32, deadline time.Duration) []ids.ShortID {
+	traceID := n.newTraceID()
 	msg, err := n.b.GetAcceptedFrontier(chainID, requestID, uint64(deadline))
 	n.log.AssertNoError(err)
 
@@ -406,7 +541,7 @@ func (n *network) GetAcceptedFrontier(validatorIDs ids.ShortSet, chainID ids.ID,
 	for _, peerElement := range n.getPeers(validatorIDs) {
 		peer := peerElement.peer
 		vID := peerElement.id
-		if peer == nil || !peer.connected.GetValue() || !peer.Send(msg) {
+		if peer == nil || !peer.connected.GetValue() || !peer.SendRequest(msg, traceID) {
 			n.log.Debug("failed to send GetAcceptedFrontier(%s, %s, %d)",
 				vID,
 				chainID,
@@ -440,7 +575,7 @@ func (n *network) AcceptedFrontier(validatorID ids.ShortID, chainID ids.ID, requ
 	}
 
 	peer := n.getPeer(validatorID)
-	if peer == nil || !peer.connected.GetValue() || !peer.Send(msg) {
+	if peer == nil || !peer.connected.GetValue() || !peer.SendResponse(msg, chainID, requestID) {
 		n.log.Debug("failed to send AcceptedFrontier(%s, %s, %d, %s)",
 			validatorID,
 			chainID,
@@ -458,6 +593,7 @@ func (n *network) AcceptedFrontier(validatorID ids.ShortID, chainID ids.ID, requ
 // GetAccepted implements the Sender interface.
 // assumes the stateLock is not held.
 func (n *network) GetAccepted(validatorIDs ids.ShortSet, chainID ids.ID, requestID uint32, deadline time.Duration, containerIDs []ids.ID) []ids.ShortID {
+	traceID := n.newTraceID()
 	now := n.clock.Time()
 
 	msg, err := n.b.GetAccepted(chainID, requestID, uint64(deadline), containerIDs)
@@ -475,7 +611,7 @@ func (n *network) GetAccepted(validatorIDs ids.ShortSet, chainID ids.ID, request
 	for _, peerElement := range n.getPeers(validatorIDs) {
 		peer := peerElement.peer
 		vID := peerElement.id
-		if peer == nil || !peer.connected.GetValue() || !peer.Send(msg) {
+		if peer == nil || !peer.connected.GetValue() || !peer.SendRequest(msg, traceID) {
 			n.log.Debug("failed to send GetAccepted(%s, %s, %d, %s)",
 				vID,
 				chainID,
@@ -510,7 +646,7 @@ func (n *network) Accepted(validatorID ids.ShortID, chainID ids.ID, requestID ui
 	}
 
 	peer := n.getPeer(validatorID)
-	if peer == nil || !peer.connected.GetValue() || !peer.Send(msg) {
+	if peer == nil || !peer.connected.GetValue() || !peer.SendResponse(msg, chainID, requestID) {
 		n.log.Debug("failed to send Accepted(%s, %s, %d, %s)",
 			validatorID,
 			chainID,
@@ -528,6 +664,7 @@ func (n *network) Accepted(validatorID ids.ShortID, chainID ids.ID, requestID ui
 // GetAncestors implements the Sender interface.
 // assumes the stateLock is not held.
 func (n *network) GetAncestors(validatorID ids.ShortID, chainID ids.ID, requestID uint32, deadline time.Duration, containerID ids.ID) bool {
+	traceID := n.newTraceID()
 	now := n.clock.Time()
 
 	msg, err := n.b.GetAncestors(chainID, requestID, uint64(deadline), containerID)
@@ -538,7 +675,7 @@ func (n *network) GetAncestors(validatorID ids.ShortID, chainID ids.ID, requestI
 	}
 
 	peer := n.getPeer(validatorID)
-	if peer == nil || !peer.connected.GetValue() || !peer.Send(msg) {
+	if peer == nil || !peer.connected.GetValue() || !peer.SendRequest(msg, traceID) {
 		n.log.Debug("failed to send GetAncestors(%s, %s, %d, %s)",
 			validatorID,
 			chainID,
@@ -567,7 +704,7 @@ func (n *network) MultiPut(validatorID ids.ShortID, chainID ids.ID, requestID ui
 	}
 
 	peer := n.getPeer(validatorID)
-	if peer == nil || !peer.connected.GetValue() || !peer.Send(msg) {
+	if peer == nil || !peer.connected.GetValue() || !peer.SendResponse(msg, chainID, requestID) {
 		n.log.Debug("failed to send MultiPut(%s, %s, %d, %d)",
 			validatorID,
 			chainID,
@@ -585,13 +722,14 @@ func (n *network) MultiPut(validatorID ids.ShortID, chainID ids.ID, requestID ui
 // Get implements the Sender interface.
 // assumes the stateLock is not held.
 func (n *network) Get(validatorID ids.ShortID, chainID ids.ID, requestID uint32, deadline time.Duration, containerID ids.ID) bool {
+	traceID := n.newTraceID()
 	now := n.clock.Time()
 
 	msg, err := n.b.Get(chainID, requestID, uint64(deadline), containerID)
 	n.log.AssertNoError(err)
 
 	peer := n.getPeer(validatorID)
-	if peer == nil || !peer.connected.GetValue() || !peer.Send(msg) {
+	if peer == nil || !peer.connected.GetValue() || !peer.SendRequest(msg, traceID) {
 		n.log.Debug("failed to send Get(%s, %s, %d, %s)",
 			validatorID,
 			chainID,
@@ -625,7 +763,7 @@ func (n *network) Put(validatorID ids.ShortID, chainID ids.ID, requestID uint32,
 	}
 
 	peer := n.getPeer(validatorID)
-	if peer == nil || !peer.connected.GetValue() || !peer.Send(msg) {
+	if peer == nil || !peer.connected.GetValue() || !peer.SendResponse(msg, chainID, requestID) {
 		n.log.Debug("failed to send Put(%s, %s, %d, %s)",
 			validatorID,
 			chainID,
@@ -641,9 +779,50 @@ func (n *network) Put(validatorID ids.ShortID, chainID ids.ID, requestID uint32,
 	}
 }
 
+// HintedPut implements the Sender interface. Peers that don't understand
+// HintedPut messages are sent a Put without the hints.
+// assumes the stateLock is not held.
+func (n *network) HintedPut(validatorID ids.ShortID, chainID ids.ID, requestID uint32, containerID ids.ID, container []byte, hints [][]byte) {
+	peer := n.getPeer(validatorID)
+	if peer == nil || atomic.LoadUint32(&peer.peerCapabilities)&capabilityParentHints == 0 {
+		n.Put(validatorID, chainID, requestID, containerID, container)
+		return
+	}
+
+	now := n.clock.Time()
+	msg, err := n.b.HintedPut(chainID, requestID, containerID, container, hints)
+	if err != nil {
+		// The hints are best effort, so fall back to a Put if they don't fit
+		n.log.Debug("failed to build HintedPut(%s, %d, %s) with %d hints: %s",
+			chainID,
+			requestID,
+			containerID,
+			len(hints),
+			err)
+		n.Put(validatorID, chainID, requestID, containerID, container)
+		return
+	}
+
+	if !peer.connected.GetValue() || !peer.SendResponse(msg, chainID, requestID) {
+		n.log.Debug("failed to send HintedPut(%s, %s, %d, %s)",
+			validatorID,
+			chainID,
+			requestID,
+			containerID)
+		n.log.Verbo("container: %s", formatting.DumpBytes{Bytes: container})
+		n.hintedPut.numFailed.Inc()
+		n.sendFailRateCalculator.Observe(1, now)
+	} else {
+		n.hintedPut.numSent.Inc()
+		n.sendFailRateCalculator.Observe(0, now)
+		n.hintedPut.sentBytes.Add(float64(len(msg.Bytes())))
+	}
+}
+
 // PushQuery implements the Sender interface.
 // assumes the stateLock is not held.
 func (n *network) PushQuery(validatorIDs ids.ShortSet, chainID ids.ID, requestID uint32, deadline time.Duration, containerID ids.ID, container []byte) []ids.ShortID {
+	traceID := n.newTraceID()
 	now := n.clock.Time()
 
 	msg, err := n.b.PushQuery(chainID, requestID, uint64(deadline), containerID, container)
@@ -663,7 +842,7 @@ func (n *network) PushQuery(validatorIDs ids.ShortSet, chainID ids.ID, requestID
 	for _, peerElement := range n.getPeers(validatorIDs) {
 		peer := peerElement.peer
 		vID := peerElement.id
-		if peer == nil || !peer.connected.GetValue() || !peer.Send(msg) {
+		if peer == nil || !peer.connected.GetValue() || !peer.SendRequest(msg, traceID) {
 			n.log.Debug("failed to send PushQuery(%s, %s, %d, %s)",
 				vID,
 				chainID,
@@ -685,6 +864,7 @@ func (n *network) PushQuery(validatorIDs ids.ShortSet, chainID ids.ID, requestID
 // PullQuery implements the Sender interface.
 // assumes the stateLock is not held.
 func (n *network) PullQuery(validatorIDs ids.ShortSet, chainID ids.ID, requestID uint32, deadline time.Duration, containerID ids.ID) []ids.ShortID {
+	traceID := n.newTraceID()
 	now := n.clock.Time()
 
 	msg, err := n.b.PullQuery(chainID, requestID, uint64(deadline), containerID)
@@ -694,7 +874,7 @@ func (n *network) PullQuery(validatorIDs ids.ShortSet, chainID ids.ID, requestID
 	for _, peerElement := range n.getPeers(validatorIDs) {
 		peer := peerElement.peer
 		vID := peerElement.id
-		if peer == nil || !peer.connected.GetValue() || !peer.Send(msg) {
+		if peer == nil || !peer.connected.GetValue() || !peer.SendRequest(msg, traceID) {
 			n.log.Debug("failed to send PullQuery(%s, %s, %d, %s)",
 				vID,
 				chainID,
@@ -729,7 +909,7 @@ func (n *network) Chits(validatorID ids.ShortID, chainID ids.ID, requestID uint3
 	}
 
 	peer := n.getPeer(validatorID)
-	if peer == nil || !peer.connected.GetValue() || !peer.Send(msg) {
+	if peer == nil || !peer.connected.GetValue() || !peer.SendResponse(msg, chainID, requestID) {
 		n.log.Debug("failed to send Chits(%s, %s, %d, %s)",
 			validatorID,
 			chainID,
@@ -744,15 +924,96 @@ func (n *network) Chits(validatorID ids.ShortID, chainID ids.ID, requestID uint3
 	}
 }
 
+// JustifiedChits implements the Sender interface. Peers that don't understand
+// JustifiedChits messages are sent Chits without the heights.
+// assumes the stateLock is not held.
+func (n *network) JustifiedChits(validatorID ids.ShortID, chainID ids.ID, requestID uint32, votes []ids.ID, heights []uint64) {
+	peer := n.getPeer(validatorID)
+	if peer == nil || atomic.LoadUint32(&peer.peerCapabilities)&capabilityJustifiedChits == 0 {
+		n.Chits(validatorID, chainID, requestID, votes)
+		return
+	}
+
+	now := n.clock.Time()
+
+	msg, err := n.b.JustifiedChits(chainID, requestID, votes, heights)
+	if err != nil {
+		n.log.Error("failed to build JustifiedChits(%s, %d, %s): %s",
+			chainID,
+			requestID,
+			votes,
+			err)
+		n.sendFailRateCalculator.Observe(1, now)
+		return
+	}
+
+	if !peer.connected.GetValue() || !peer.SendResponse(msg, chainID, requestID) {
+		n.log.Debug("failed to send JustifiedChits(%s, %s, %d, %s)",
+			validatorID,
+			chainID,
+			requestID,
+			votes)
+		n.justifiedChits.numFailed.Inc()
+		n.sendFailRateCalculator.Observe(1, now)
+	} else {
+		n.sendFailRateCalculator.Observe(0, now)
+		n.justifiedChits.numSent.Inc()
+		n.justifiedChits.sentBytes.Add(float64(len(msg.Bytes())))
+	}
+}
+
 // Gossip attempts to gossip the container to the network
 // assumes the stateLock is not held.
 func (n *network) Gossip(chainID, containerID ids.ID, container []byte) {
-	if err := n.gossipContainer(chainID, containerID, container); err != nil {
+	if err := n.gossipContainer(chainID, containerID, container, false); err != nil {
 		n.log.Debug("failed to Gossip(%s, %s): %s", chainID, containerID, err)
 		n.log.Verbo("container:\n%s", formatting.DumpBytes{Bytes: container})
 	}
 }
 
+// GossipToValidators attempts to gossip the container to the validators
+// connected to the network
+// assumes the stateLock is not held.
+func (n *network) GossipToValidators(chainID, containerID ids.ID, container []byte) {
+	if err := n.gossipContainer(chainID, containerID, container, true); err != nil {
+		n.log.Debug("failed to GossipToValidators(%s, %s): %s", chainID, containerID, err)
+		n.log.Verbo("container:\n%s", formatting.DumpBytes{Bytes: container})
+	}
+}
+
+// GossipTxs attempts to gossip the IDs of the txs in the container to the
+// network
+// assumes the stateLock is not held.
+func (n *network) GossipTxs(chainID, containerID ids.ID, txIDs []ids.ID) {
+	now := n.clock.Time()
+
+	msg, err := n.b.GossipTxs(chainID, containerID, txIDs)
+	if err != nil {
+		n.log.Error("failed to build GossipTxs(%s, %s): %s",
+			chainID,
+			containerID,
+			err)
+		n.sendFailRateCalculator.Observe(1, now)
+		return
+	}
+
+	peers, err := n.sampleGossipPeers(false)
+	if err != nil {
+		n.log.Debug("failed to GossipTxs(%s, %s): %s", chainID, containerID, err)
+		return
+	}
+	for _, peer := range peers {
+		if peer.Send(msg) {
+			n.gossipTxs.numSent.Inc()
+			n.gossipTxs.sentBytes.Add(float64(len(msg.Bytes())))
+			n.sendFailRateCalculator.Observe(0, now)
+		} else {
+			n.sendFailRateCalculator.Observe(1, now)
+			n.gossipTxs.numFailed.Inc()
+		}
+	}
+}
+
 // Accept is called after every consensus decision
 // assumes the stateLock is not held.
 func (n *network) Accept(ctx *snow.Context, containerID ids.ID, container []byte) error {
@@ -760,7 +1021,7 @@ func (n *network) Accept(ctx *snow.Context, containerID ids.ID, container []byte
 		// don't gossip during bootstrapping
 		return nil
 	}
-	return n.gossipContainer(ctx.ChainID, containerID, container)
+	return n.gossipContainer(ctx.ChainID, containerID, container, false)
 }
 
 // upgradeIncoming returns a boolean indicating if we should
@@ -786,6 +1047,9 @@ func (n *network) upgradeIncoming(remoteAddr string) (bool, error) {
 	if _, ok := n.peerAliasIPs[str]; ok {
 		return false, nil
 	}
+	if n.blacklist.Blocked(n.clock.Time(), ipKey(ip.IP)) {
+		return false, nil
+	}
 
 	// Note that we attempt to upgrade remote addresses contained
 	// in disconnectedIPs to because that could allow us to initialize
@@ -797,6 +1061,15 @@ func (n *network) upgradeIncoming(remoteAddr string) (bool, error) {
 // to this node.
 // assumes the stateLock is not held.
 func (n *network) Dispatch() error {
+	// Reconnect to the peers we were connected to before restarting
+	n.stateLock.Lock()
+	for _, ip := range n.peerStore.IPs(n.clock.Time()) {
+		if err := n.track(ip); err != nil {
+			n.log.Debug("not reconnecting to stored peer: %s", err)
+		}
+	}
+	n.stateLock.Unlock()
+
 	go n.gossip() // Periodically gossip peers
 	go func() {
 		duration := time.Until(n.apricotPhase0Time)
@@ -847,7 +1120,7 @@ func (n *network) Dispatch() error {
 			_ = conn.Close()
 			continue
 		} else if !upgrade {
-			n.log.Debug("dropping duplicate connection from %s", addr)
+			n.log.Debug("dropping duplicate or blacklisted connection from %s", addr)
 			_ = conn.Close()
 			continue
 		}
@@ -889,15 +1162,7 @@ func (n *network) Peers(nodeIDs []ids.ShortID) []PeerID {
 		peers = make([]PeerID, 0, len(n.peers))
 		for _, peer := range n.peers {
 			if peer.connected.GetValue() {
-				peers = append(peers, PeerID{
-					IP:           peer.conn.RemoteAddr().String(),
-					PublicIP:     peer.getIP().String(),
-					ID:           peer.id.PrefixedString(constants.NodeIDPrefix),
-					Version:      peer.versionStr.GetValue().(string),
-					LastSent:     time.Unix(atomic.LoadInt64(&peer.lastSent), 0),
-					LastReceived: time.Unix(atomic.LoadInt64(&peer.lastReceived), 0),
-					Benched:      n.benchlistManager.GetBenched(peer.id),
-				})
+				peers = append(peers, n.peerID(peer))
 			}
 		}
 	} else {
@@ -905,21 +1170,40 @@ func (n *network) Peers(nodeIDs []ids.ShortID) []PeerID {
 		for _, nodeID := range nodeIDs {
 			peer, ok := n.peers[nodeID]
 			if ok && peer.connected.GetValue() {
-				peers = append(peers, PeerID{
-					IP:           peer.conn.RemoteAddr().String(),
-					PublicIP:     peer.getIP().String(),
-					ID:           peer.id.PrefixedString(constants.NodeIDPrefix),
-					Version:      peer.versionStr.GetValue().(string),
-					LastSent:     time.Unix(atomic.LoadInt64(&peer.lastSent), 0),
-					LastReceived: time.Unix(atomic.LoadInt64(&peer.lastReceived), 0),
-					Benched:      n.benchlistManager.GetBenched(peer.id),
-				})
+				peers = append(peers, n.peerID(peer))
 			}
 		}
 	}
 	return peers
 }
 
+// peerID returns the description of [peer] reported by the Peers API
+// assumes the stateLock is held.
+func (n *network) peerID(peer *peer) PeerID {
+	droppedMessages, chronicDrops := peer.dropStats()
+	return PeerID{
+		IP:           peer.conn.RemoteAddr().String(),
+		PublicIP:     peer.getIP().String(),
+		ID:           peer.id.PrefixedString(constants.NodeIDPrefix),
+		Version:      peer.versionStr.GetValue().(string),
+		LastSent:     time.Unix(atomic.LoadInt64(&peer.lastSent), 0),
+		LastReceived: time.Unix(atomic.LoadInt64(&peer.lastReceived), 0),
+		Benched:      n.benchlistManager.GetBenched(peer.id),
+
+		SessionID:         atomic.LoadUint32(&peer.sessionID),
+		ConnectedAt:       peer.connectedAt,
+		BytesSent:         atomic.LoadUint64(&peer.bytesSent),
+		BytesReceived:     atomic.LoadUint64(&peer.bytesReceived),
+		RTT:               time.Duration(atomic.LoadInt64(&peer.rtt)),
+		MissedPongs:       atomic.LoadUint32(&peer.missedPongs),
+		ClockOffset:       time.Duration(atomic.LoadInt64(&peer.clockOffset)),
+		DeprecatedVersion: peer.deprecatedVersion.GetValue(),
+		PendingChainBytes: peer.chainPendingBytes(),
+		DroppedMessages:   droppedMessages,
+		ChronicDrops:      chronicDrops,
+	}
+}
+
 // Close implements the Network interface
 // assumes the stateLock is not held.
 func (n *network) Close() error {
@@ -963,11 +1247,24 @@ func (n *network) close() {
 
 // Track implements the Network interface
 // assumes the stateLock is not held.
-func (n *network) Track(ip utils.IPDesc) {
+func (n *network) Track(ip utils.IPDesc) error {
 	n.stateLock.Lock()
 	defer n.stateLock.Unlock()
 
-	n.track(ip)
+	return n.track(ip)
+}
+
+// TrackNode implements the Network interface
+// assumes the stateLock is not held.
+func (n *network) TrackNode(ip utils.IPDesc, nodeID ids.ShortID) error {
+	n.stateLock.Lock()
+	defer n.stateLock.Unlock()
+
+	if err := n.withinSubnetBudgets(nodeID); err != nil {
+		return err
+	}
+	n.expectedIDs[ip.String()] = nodeID
+	return n.track(ip)
 }
 
 func (n *network) IP() utils.IPDesc {
@@ -975,7 +1272,7 @@ func (n *network) IP() utils.IPDesc {
 }
 
 // assumes the stateLock is not held.
-func (n *network) gossipContainer(chainID, containerID ids.ID, container []byte) error {
+func (n *network) gossipContainer(chainID, containerID ids.ID, container []byte, validatorsOnly bool) error {
 	now := n.clock.Time()
 
 	msg, err := n.b.Put(chainID, constants.GossipMsgRequestID, containerID, container)
@@ -984,23 +1281,12 @@ func (n *network) gossipContainer(chainID, containerID ids.ID, container []byte)
 		return fmt.Errorf("attempted to pack too large of a Put message.\nContainer length: %d", len(container))
 	}
 
-	allPeers := n.getAllPeers()
-
-	numToGossip := n.gossipSize
-	if numToGossip > len(allPeers) {
-		numToGossip = len(allPeers)
-	}
-
-	s := sampler.NewUniform()
-	if err := s.Initialize(uint64(len(allPeers))); err != nil {
-		return err
-	}
-	indices, err := s.Sample(numToGossip)
+	peers, err := n.sampleGossipPeers(validatorsOnly)
 	if err != nil {
 		return err
 	}
-	for _, index := range indices {
-		if allPeers[int(index)].Send(msg) {
+	for _, peer := range peers {
+		if peer.Send(msg) {
 			n.put.numSent.Inc()
 			n.sendFailRateCalculator.Observe(0, now)
 		} else {
@@ -1011,28 +1297,33 @@ func (n *network) gossipContainer(chainID, containerID ids.ID, container []byte)
 	return nil
 }
 
-// assumes the stateLock is held.
-func (n *network) track(ip utils.IPDesc) {
+// assumes the stateLock is held. Returns an error if dialing [ip] would exceed
+// the connection limits.
+func (n *network) track(ip utils.IPDesc) error {
 	if n.closed.GetValue() {
-		return
+		return errNetworkClosed
 	}
 
 	str := ip.String()
 	if _, ok := n.disconnectedIPs[str]; ok {
-		return
+		return nil
 	}
 	if _, ok := n.connectedIPs[str]; ok {
-		return
+		return nil
 	}
 	if _, ok := n.peerAliasIPs[str]; ok {
-		return
+		return nil
 	}
 	if _, ok := n.myIPs[str]; ok {
-		return
+		return nil
+	}
+	if err := n.canDial(); err != nil {
+		return fmt.Errorf("not connecting to %s: %w", ip, err)
 	}
 	n.disconnectedIPs[str] = struct{}{}
 
 	go n.connectTo(ip)
+	return nil
 }
 
 // assumes the stateLock is not held. Only returns after the network is closed.
@@ -1177,6 +1468,12 @@ func (n *network) connectTo(ip utils.IPDesc) {
 		n.retryDelay[str] = delay
 		n.stateLock.Unlock()
 
+		if n.blacklist.Blocked(n.clock.Time(), ipKey(ip.IP)) {
+			// Keep retrying, in case the ban expires or is lifted
+			n.log.Verbo("not connecting to blacklisted %s. Reattempting in %s", ip, delay)
+			continue
+		}
+
 		err := n.attemptConnect(ip)
 		if err == nil {
 			return
@@ -1222,6 +1519,12 @@ func (n *network) upgrade(p *peer, upgrader Upgrader) error {
 		return err
 	}
 
+	if err := n.verifyExpectedID(p.ip, id); err != nil {
+		_ = conn.Close()
+		n.log.Debug("dropping peer connection due to: %s", err)
+		return err
+	}
+
 	if err := conn.SetReadDeadline(time.Time{}); err != nil {
 		_ = p.conn.Close()
 		n.log.Verbo("failed to clear the read deadline with %s", err)
@@ -1239,6 +1542,27 @@ func (n *network) upgrade(p *peer, upgrader Upgrader) error {
 	return nil
 }
 
+// assumes the stateLock is not held. Returns an error if [ip] is expected to be
+// reached at a node ID other than [id].
+func (n *network) verifyExpectedID(ip utils.IPDesc, id ids.ShortID) error {
+	if ip.IsZero() {
+		return nil
+	}
+
+	n.stateLock.RLock()
+	expectedID, ok := n.expectedIDs[ip.String()]
+	n.stateLock.RUnlock()
+
+	if ok && id != expectedID {
+		return fmt.Errorf("%w: expected %s at %s but got %s",
+			errPeerIDMismatch,
+			expectedID.PrefixedString(constants.NodeIDPrefix),
+			ip,
+			id.PrefixedString(constants.NodeIDPrefix))
+	}
+	return nil
+}
+
 // assumes the stateLock is not held. Returns an error if the peer couldn't be
 // added.
 func (n *network) tryAddPeer(p *peer) error {
@@ -1253,6 +1577,10 @@ func (n *network) tryAddPeer(p *peer) error {
 		return errNetworkClosed
 	}
 
+	if n.blocked(p) {
+		return fmt.Errorf("%w: %s at %s", errPeerBlacklisted, p.id.PrefixedString(constants.NodeIDPrefix), ip)
+	}
+
 	// if this connection is myself, then I should delete the connection and
 	// mark the IP as one of mine.
 	if p.id == n.id {
@@ -1272,19 +1600,42 @@ func (n *network) tryAddPeer(p *peer) error {
 	}
 
 	// If I am already connected to this peer, then I should close this new
-	// connection and add an alias record.
+	// connection and add an alias record. If the IP of the existing
+	// connection is unknown, such as when the peer is behind a load balancer
+	// and advertises an IP that its connections don't come from, then this
+	// connection has verified the IP that the peer can be reached at.
 	if peer, ok := n.peers[p.id]; ok {
 		if !ip.IsZero() {
 			str := ip.String()
 			delete(n.disconnectedIPs, str)
 			delete(n.retryDelay, str)
-			peer.addAlias(ip)
+			if peer.connected.GetValue() && peer.getIP().IsZero() {
+				n.log.Debug("learned that %s is reachable at %s", p.id, ip)
+				peer.setIP(ip)
+				n.connectedIPs[str] = struct{}{}
+				if err := n.peerStore.Connected(ip, p.id, n.clock.Time()); err != nil {
+					n.log.Warn("failed to store peer %s at %s: %s", p.id, ip, err)
+				}
+			} else {
+				peer.addAlias(ip)
+			}
 		}
 		return fmt.Errorf("duplicated connection from %s at %s", p.id.PrefixedString(constants.NodeIDPrefix), ip)
 	}
 
+	if err := n.canAddPeer(p.id); err != nil {
+		if !ip.IsZero() {
+			// Allow the IP to be tracked again once there's room for it
+			str := ip.String()
+			delete(n.disconnectedIPs, str)
+			delete(n.retryDelay, str)
+		}
+		return fmt.Errorf("refusing %s at %s: %w", p.id.PrefixedString(constants.NodeIDPrefix), ip, err)
+	}
+
 	n.peers[p.id] = p
 	n.numPeers.Set(float64(len(n.peers)))
+	n.peerHandlers.upgraded(p.id, ip)
 	p.Start()
 	return nil
 }
@@ -1298,7 +1649,7 @@ func (n *network) validatorIPs() []utils.IPDesc {
 	ips := make([]utils.IPDesc, 0, len(n.peers))
 	for _, peer := range n.peers {
 		ip := peer.getIP()
-		if peer.connected.GetValue() && !ip.IsZero() && n.vdrs.Contains(peer.id) {
+		if peer.connected.GetValue() && !ip.IsZero() && n.vdrs.Contains(peer.validatorID()) {
 			peerVersion := peer.versionStruct.GetValue().(version.Version)
 			if !peerVersion.Before(minimumUnmaskedVersion) || time.Since(n.apricotPhase0Time) < 0 {
 				ips = append(ips, ip)
@@ -1338,6 +1689,10 @@ func (n *network) connected(p *peer) {
 		}
 	}
 
+	if p.deprecatedVersion.GetValue() {
+		n.deprecatedPeers.Inc()
+	}
+
 	ip := p.getIP()
 	n.log.Debug("connected to %s at %s", p.id, ip)
 
@@ -1347,9 +1702,17 @@ func (n *network) connected(p *peer) {
 		delete(n.disconnectedIPs, str)
 		delete(n.retryDelay, str)
 		n.connectedIPs[str] = struct{}{}
+
+		if err := n.peerStore.Connected(ip, p.id, n.clock.Time()); err != nil {
+			n.log.Warn("failed to store peer %s at %s: %s", p.id, ip, err)
+		}
 	}
 
 	n.router.Connected(p.id)
+	if p.previousID != ids.ShortEmpty {
+		n.router.Connected(p.previousID)
+	}
+	n.peerHandlers.connected(p.id, ip)
 }
 
 // should only be called after the peer is marked as connected.
@@ -1364,6 +1727,12 @@ func (n *network) disconnected(p *peer) {
 
 	delete(n.peers, p.id)
 	n.numPeers.Set(float64(len(n.peers)))
+	if p.deprecatedVersion.GetValue() {
+		n.deprecatedPeers.Dec()
+	}
+	if p.previousID != ids.ShortEmpty && n.previousIDs[p.previousID] == p {
+		delete(n.previousIDs, p.previousID)
+	}
 
 	p.releaseAllAliases()
 
@@ -1373,11 +1742,23 @@ func (n *network) disconnected(p *peer) {
 		delete(n.disconnectedIPs, str)
 		delete(n.connectedIPs, str)
 
-		n.track(ip)
+		if p.connected.GetValue() {
+			if err := n.peerStore.Disconnected(ip, p.connectedAt, n.clock.Time()); err != nil {
+				n.log.Warn("failed to store peer %s at %s: %s", p.id, ip, err)
+			}
+		}
+
+		if err := n.track(ip); err != nil {
+			n.log.Debug("not reconnecting to %s: %s", p.id, err)
+		}
 	}
 
 	if p.connected.GetValue() {
 		n.router.Disconnected(p.id)
+		if p.previousID != ids.ShortEmpty {
+			n.router.Disconnected(p.previousID)
+		}
+		n.peerHandlers.disconnected(p.id, ip)
 	}
 }
 
@@ -1406,7 +1787,7 @@ func (n *network) getPeers(validatorIDs ids.ShortSet) []*PeerElement {
 	for validatorID := range validatorIDs {
 		vID := validatorID // Prevent overwrite in next loop iteration
 		peers[i] = &PeerElement{
-			peer: n.peers[vID],
+			peer: n.peerByID(vID),
 			id:   vID,
 		}
 		i++
@@ -1415,6 +1796,84 @@ func (n *network) getPeers(validatorIDs ids.ShortSet) []*PeerElement {
 	return peers
 }
 
+// Blacklist implements the Network interface
+// assumes the stateLock is not held.
+func (n *network) Blacklist(target string, duration time.Duration) error {
+	key, err := parseBlacklistTarget(target)
+	if err != nil {
+		return err
+	}
+	if err := n.blacklist.Ban(key, n.clock.Time().Add(duration)); err != nil {
+		return fmt.Errorf("couldn't blacklist %s: %w", target, err)
+	}
+	n.log.Info("blacklisted %s for %s", target, duration)
+
+	for _, peer := range n.getAllPeers() {
+		if n.blocked(peer) {
+			n.log.Debug("disconnecting from blacklisted peer %s", peer.id)
+			peer.Close()
+		}
+	}
+	return nil
+}
+
+// Unblacklist implements the Network interface
+// assumes the stateLock is not held.
+func (n *network) Unblacklist(target string) error {
+	key, err := parseBlacklistTarget(target)
+	if err != nil {
+		return err
+	}
+	if err := n.blacklist.Unban(key); err != nil {
+		return fmt.Errorf("couldn't unblacklist %s: %w", target, err)
+	}
+	n.log.Info("unblacklisted %s", target)
+	return nil
+}
+
+// Allowlist implements the Network interface
+// assumes the stateLock is not held.
+func (n *network) Allowlist(target string) error {
+	key, err := parseBlacklistTarget(target)
+	if err != nil {
+		return err
+	}
+	if err := n.blacklist.Allow(key); err != nil {
+		return fmt.Errorf("couldn't allowlist %s: %w", target, err)
+	}
+	n.log.Info("allowlisted %s", target)
+	return nil
+}
+
+// Unallowlist implements the Network interface
+// assumes the stateLock is not held.
+func (n *network) Unallowlist(target string) error {
+	key, err := parseBlacklistTarget(target)
+	if err != nil {
+		return err
+	}
+	if err := n.blacklist.Disallow(key); err != nil {
+		return fmt.Errorf("couldn't unallowlist %s: %w", target, err)
+	}
+	n.log.Info("unallowlisted %s", target)
+	return nil
+}
+
+// blocked returns true if [p] is blacklisted by either its node ID, the IP it
+// reported, or the IP it's connected from
+func (n *network) blocked(p *peer) bool {
+	keys := []string{nodeIDKey(p.id)}
+	if ip := p.getIP(); !ip.IsZero() {
+		keys = append(keys, ipKey(ip.IP))
+	}
+	if p.conn != nil {
+		if ip, err := utils.ToIPDesc(p.conn.RemoteAddr().String()); err == nil {
+			keys = append(keys, ipKey(ip.IP))
+		}
+	}
+	return n.blacklist.Blocked(n.clock.Time(), keys...)
+}
+
 // Safe copy the peers. Assumes the stateLock is not held.
 func (n *network) getAllPeers() []*peer {
 	n.stateLock.RLock()
@@ -1433,6 +1892,42 @@ func (n *network) getAllPeers() []*peer {
 	return peers
 }
 
+// sampleGossipPeers returns up to [gossipSize] connected peers, sampled
+// uniformly, to gossip to. If [validatorsOnly] is true, or the network only
+// gossips to validators, only validators are sampled.
+// assumes the stateLock is not held.
+func (n *network) sampleGossipPeers(validatorsOnly bool) ([]*peer, error) {
+	allPeers := n.getAllPeers()
+	if validatorsOnly || n.gossipValidatorsOnly {
+		validators := allPeers[:0]
+		for _, peer := range allPeers {
+			if n.vdrs.Contains(peer.id) {
+				validators = append(validators, peer)
+			}
+		}
+		allPeers = validators
+	}
+
+	numToGossip := n.gossipSize
+	if numToGossip > len(allPeers) {
+		numToGossip = len(allPeers)
+	}
+
+	s := sampler.NewUniform()
+	if err := s.Initialize(uint64(len(allPeers))); err != nil {
+		return nil, err
+	}
+	indices, err := s.Sample(numToGossip)
+	if err != nil {
+		return nil, err
+	}
+	peers := make([]*peer, len(indices))
+	for i, index := range indices {
+		peers[i] = allPeers[int(index)]
+	}
+	return peers, nil
+}
+
 // Safe find a single peer
 // assumes the stateLock is not held.
 func (n *network) getPeer(validatorID ids.ShortID) *peer {
@@ -1442,7 +1937,53 @@ func (n *network) getPeer(validatorID ids.ShortID) *peer {
 	if n.closed.GetValue() {
 		return nil
 	}
-	return n.peers[validatorID]
+	return n.peerByID(validatorID)
+}
+
+// peerByID returns the peer with ID [validatorID], or the peer that has
+// rotated away from [validatorID] if there's no such peer.
+// assumes the stateLock is held.
+func (n *network) peerByID(validatorID ids.ShortID) *peer {
+	if p, ok := n.peers[validatorID]; ok {
+		return p
+	}
+	if p, ok := n.previousIDs[validatorID]; ok && n.clock.Time().Before(p.previousIDExpiry) {
+		return p
+	}
+	return nil
+}
+
+// addPreviousID records that [p] is recognized by [previousID] until
+// [expiry]. Only one peer is recognized by [previousID] at a time, so the
+// rotation is dropped if another peer is already recognized by [previousID].
+// assumes the stateLock is not held.
+func (n *network) addPreviousID(p *peer, previousID ids.ShortID, expiry time.Time) {
+	n.stateLock.Lock()
+	defer n.stateLock.Unlock()
+
+	if p.previousID != ids.ShortEmpty {
+		n.log.Debug("dropping duplicated identity rotation from %s", p.id)
+		return
+	}
+	if _, ok := n.peers[p.id]; !ok {
+		// The peer was disconnected
+		return
+	}
+	if other, ok := n.previousIDs[previousID]; ok && n.clock.Time().Before(other.previousIDExpiry) {
+		n.log.Debug("dropping identity rotation from %s as %s already rotated away from %s",
+			p.id, other.id, previousID)
+		return
+	}
+
+	n.log.Debug("recognizing %s as %s until %s", p.id, previousID, expiry)
+
+	p.previousID = previousID
+	p.previousIDExpiry = expiry
+	n.previousIDs[previousID] = p
+
+	if p.connected.GetValue() {
+		n.router.Connected(previousID)
+	}
 }
 
 // restartOnDisconnect checks every [n.disconnectedCheckFreq] whether this node is connected
@@ -1484,10 +2025,12 @@ func (n *network) restartOnDisconnect() {
 func (n *network) HealthCheck() (interface{}, error) {
 	// Get some data with the state lock held
 	connectedTo := 0
+	clockOffsets := []int64(nil)
 	n.stateLock.RLock()
 	for _, peer := range n.peers {
 		if peer != nil && peer.connected.GetValue() {
 			connectedTo++
+			clockOffsets = append(clockOffsets, atomic.LoadInt64(&peer.clockOffset))
 		}
 	}
 	pendingSendBytes := n.pendingBytes
@@ -1527,6 +2070,19 @@ func (n *network) HealthCheck() (interface{}, error) {
 	details["sendFailRate"] = sendFailRate
 	n.metrics.sendFailRate.Set(sendFailRate)
 
+	// Make sure our clock hasn't drifted too far from our peers' clocks
+	if len(clockOffsets) > 0 {
+		clockSkew := medianDuration(clockOffsets)
+		details["clockSkew"] = clockSkew.String()
+		n.metrics.clockSkew.Set(clockSkew.Seconds())
+		if maxSkew := n.healthConfig.MaxClockSkew; maxSkew > 0 && (clockSkew > maxSkew || clockSkew < -maxSkew) {
+			n.log.Warn("local clock differs from the median of %d peers' clocks by %s. Peers' clocks are ahead if positive",
+				len(clockOffsets),
+				clockSkew)
+			healthy = false
+		}
+	}
+
 	// Network layer is unhealthy
 	if !healthy {
 		return details, errNetworkLayerUnhealthy
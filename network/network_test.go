@@ -4,7 +4,6 @@
 package network
 
 import (
-	"errors"
 	"net"
 	"sync"
 	"testing"
@@ -14,7 +13,9 @@ import (
 
 	"github.com/stretchr/testify/assert"
 
+	"github.com/ava-labs/avalanchego/database/memdb"
 	"github.com/ava-labs/avalanchego/ids"
+	"github.com/ava-labs/avalanchego/network/networktest"
 	"github.com/ava-labs/avalanchego/snow/networking/benchlist"
 	"github.com/ava-labs/avalanchego/snow/networking/router"
 	"github.com/ava-labs/avalanchego/snow/validators"
@@ -30,157 +31,6 @@ const (
 	defaultAliasTimeout  = 2 * time.Second
 )
 
-var (
-	errClosed  = errors.New("closed")
-	errRefused = errors.New("connection refused")
-)
-
-type testListener struct {
-	addr    net.Addr
-	inbound chan net.Conn
-	once    sync.Once
-	closed  chan struct{}
-}
-
-func (l *testListener) Accept() (net.Conn, error) {
-	select {
-	case c := <-l.inbound:
-		return c, nil
-	case <-l.closed:
-		return nil, errClosed
-	}
-}
-
-func (l *testListener) Close() error {
-	l.once.Do(func() { close(l.closed) })
-	return nil
-}
-
-func (l *testListener) Addr() net.Addr { return l.addr }
-
-type testDialer struct {
-	addr net.Addr
-
-	outbounds     map[string]*testListener
-	outboundsLock sync.Mutex
-
-	// clients tracks opened client connections
-	// by IP
-	clients map[string]*testConn
-
-	// closer is invoked when testDialer is closed
-	closer func(net.Addr, net.Addr)
-}
-
-func (d *testDialer) Dial(ip utils.IPDesc) (net.Conn, error) {
-	d.outboundsLock.Lock()
-	defer d.outboundsLock.Unlock()
-
-	outbound, ok := d.outbounds[ip.String()]
-	if !ok {
-		return nil, errRefused
-	}
-	server := &testConn{
-		pendingReads:  make(chan []byte, 1<<10),
-		pendingWrites: make(chan []byte, 1<<10),
-		closed:        make(chan struct{}),
-		local:         outbound.addr,
-		remote:        d.addr,
-		closer:        d.closer,
-	}
-	client := &testConn{
-		pendingReads:  server.pendingWrites,
-		pendingWrites: server.pendingReads,
-		closed:        make(chan struct{}),
-		local:         d.addr,
-		remote:        outbound.addr,
-		closer:        d.closer,
-	}
-
-	select {
-	case outbound.inbound <- server:
-		if d.clients == nil {
-			d.clients = make(map[string]*testConn)
-		}
-		d.clients[ip.String()] = client
-		return client, nil
-	default:
-		return nil, errRefused
-	}
-}
-
-func (d *testDialer) Update(ip utils.DynamicIPDesc, listener *testListener) {
-	d.outboundsLock.Lock()
-	defer d.outboundsLock.Unlock()
-
-	d.outbounds[ip.String()] = listener
-}
-
-type testConn struct {
-	partialRead   []byte
-	pendingReads  chan []byte
-	pendingWrites chan []byte
-	closed        chan struct{}
-	once          sync.Once
-
-	// closer is invoked when testConn is closed
-	closer func(net.Addr, net.Addr)
-
-	local, remote net.Addr
-}
-
-func (c *testConn) Read(b []byte) (int, error) {
-	for len(c.partialRead) == 0 {
-		select {
-		case read, ok := <-c.pendingReads:
-			if !ok {
-				return 0, errClosed
-			}
-			c.partialRead = read
-		case <-c.closed:
-			return 0, errClosed
-		}
-	}
-
-	copy(b, c.partialRead)
-	if length := len(c.partialRead); len(b) > length {
-		c.partialRead = nil
-		return length, nil
-	}
-	c.partialRead = c.partialRead[len(b):]
-	return len(b), nil
-}
-
-func (c *testConn) Write(b []byte) (int, error) {
-	newB := make([]byte, len(b))
-	copy(newB, b)
-
-	select {
-	case c.pendingWrites <- newB:
-	case <-c.closed:
-		return 0, errClosed
-	}
-
-	return len(b), nil
-}
-
-func (c *testConn) Close() error {
-	c.once.Do(func() {
-		close(c.closed)
-
-		if c.closer != nil {
-			c.closer(c.local, c.remote)
-		}
-	})
-	return nil
-}
-
-func (c *testConn) LocalAddr() net.Addr              { return c.local }
-func (c *testConn) RemoteAddr() net.Addr             { return c.remote }
-func (c *testConn) SetDeadline(time.Time) error      { return nil }
-func (c *testConn) SetReadDeadline(time.Time) error  { return nil }
-func (c *testConn) SetWriteDeadline(time.Time) error { return nil }
-
 type testHandler struct {
 	router.Router
 	connected    func(ids.ShortID)
@@ -233,21 +83,14 @@ func TestNewDefaultNetwork(t *testing.T) {
 	appVersion := version.NewDefaultVersion("app", 0, 1, 0)
 	versionParser := version.NewDefaultParser()
 
-	listener := &testListener{
-		addr: &net.TCPAddr{
-			IP:   net.IPv6loopback,
-			Port: 0,
-		},
-		inbound: make(chan net.Conn, 1<<10),
-		closed:  make(chan struct{}),
-	}
-	caller := &testDialer{
-		addr: &net.TCPAddr{
-			IP:   net.IPv6loopback,
-			Port: 0,
-		},
-		outbounds: make(map[string]*testListener),
-	}
+	listener := networktest.NewListener(&net.TCPAddr{
+		IP:   net.IPv6loopback,
+		Port: 0,
+	})
+	caller := networktest.NewDialer(&net.TCPAddr{
+		IP:   net.IPv6loopback,
+		Port: 0,
+	})
 	serverUpgrader := NewIPUpgrader()
 	clientUpgrader := NewIPUpgrader()
 
@@ -262,7 +105,7 @@ func TestNewDefaultNetwork(t *testing.T) {
 		networkID,
 		appVersion,
 		versionParser,
-		listener,
+		[]net.Listener{listener},
 		caller,
 		serverUpgrader,
 		clientUpgrader,
@@ -277,9 +120,15 @@ func TestNewDefaultNetwork(t *testing.T) {
 		0,
 		time.Now(),
 		defaultSendQueueSize,
+		DefaultSendQueueConfig,
+		BandwidthConfig{},
+		DefaultGossipConfig,
+		DefaultConnectionConfig,
 		HealthConfig{},
 		benchlist.NewManager(&benchlist.Config{}),
+		memdb.New(),
 		defaultAliasTimeout,
+		nil,
 	)
 	assert.NotNil(t, net)
 
@@ -309,39 +158,25 @@ func TestEstablishConnection(t *testing.T) {
 	)
 	id1 := ids.ShortID(hashing.ComputeHash160Array([]byte(ip1.IP().String())))
 
-	listener0 := &testListener{
-		addr: &net.TCPAddr{
-			IP:   net.IPv6loopback,
-			Port: 0,
-		},
-		inbound: make(chan net.Conn, 1<<10),
-		closed:  make(chan struct{}),
-	}
-	caller0 := &testDialer{
-		addr: &net.TCPAddr{
-			IP:   net.IPv6loopback,
-			Port: 0,
-		},
-		outbounds: make(map[string]*testListener),
-	}
-	listener1 := &testListener{
-		addr: &net.TCPAddr{
-			IP:   net.IPv6loopback,
-			Port: 1,
-		},
-		inbound: make(chan net.Conn, 1<<10),
-		closed:  make(chan struct{}),
-	}
-	caller1 := &testDialer{
-		addr: &net.TCPAddr{
-			IP:   net.IPv6loopback,
-			Port: 1,
-		},
-		outbounds: make(map[string]*testListener),
-	}
+	listener0 := networktest.NewListener(&net.TCPAddr{
+		IP:   net.IPv6loopback,
+		Port: 0,
+	})
+	caller0 := networktest.NewDialer(&net.TCPAddr{
+		IP:   net.IPv6loopback,
+		Port: 0,
+	})
+	listener1 := networktest.NewListener(&net.TCPAddr{
+		IP:   net.IPv6loopback,
+		Port: 1,
+	})
+	caller1 := networktest.NewDialer(&net.TCPAddr{
+		IP:   net.IPv6loopback,
+		Port: 1,
+	})
 
-	caller0.outbounds[ip1.IP().String()] = listener1
-	caller1.outbounds[ip0.IP().String()] = listener0
+	caller0.Route(ip1.IP(), listener1)
+	caller1.Route(ip0.IP(), listener0)
 
 	serverUpgrader := NewIPUpgrader()
 	clientUpgrader := NewIPUpgrader()
@@ -379,7 +214,7 @@ func TestEstablishConnection(t *testing.T) {
 		networkID,
 		appVersion,
 		versionParser,
-		listener0,
+		[]net.Listener{listener0},
 		caller0,
 		serverUpgrader,
 		clientUpgrader,
@@ -394,9 +229,15 @@ func TestEstablishConnection(t *testing.T) {
 		0,
 		time.Now(),
 		defaultSendQueueSize,
+		DefaultSendQueueConfig,
+		BandwidthConfig{},
+		DefaultGossipConfig,
+		DefaultConnectionConfig,
 		HealthConfig{},
 		benchlist.NewManager(&benchlist.Config{}),
+		memdb.New(),
 		defaultAliasTimeout,
+		nil,
 	)
 	assert.NotNil(t, net0)
 
@@ -408,7 +249,7 @@ func TestEstablishConnection(t *testing.T) {
 		networkID,
 		appVersion,
 		versionParser,
-		listener1,
+		[]net.Listener{listener1},
 		caller1,
 		serverUpgrader,
 		clientUpgrader,
@@ -423,9 +264,15 @@ func TestEstablishConnection(t *testing.T) {
 		0,
 		time.Now(),
 		defaultSendQueueSize,
+		DefaultSendQueueConfig,
+		BandwidthConfig{},
+		DefaultGossipConfig,
+		DefaultConnectionConfig,
 		HealthConfig{},
 		benchlist.NewManager(&benchlist.Config{}),
+		memdb.New(),
 		defaultAliasTimeout,
+		nil,
 	)
 	assert.NotNil(t, net1)
 
@@ -438,7 +285,7 @@ func TestEstablishConnection(t *testing.T) {
 		assert.Error(t, err)
 	}()
 
-	net0.Track(ip1.IP())
+	assert.NoError(t, net0.Track(ip1.IP()))
 
 	wg0.Wait()
 	wg1.Wait()
@@ -450,7 +297,7 @@ func TestEstablishConnection(t *testing.T) {
 	assert.NoError(t, err)
 }
 
-func TestDoubleTrack(t *testing.T) {
+func TestEstablishConnectionWithRotation(t *testing.T) {
 	log := logging.NoLog{}
 	networkID := uint32(0)
 	appVersion := version.NewDefaultVersion("app", 0, 1, 0)
@@ -467,39 +314,195 @@ func TestDoubleTrack(t *testing.T) {
 	)
 	id1 := ids.ShortID(hashing.ComputeHash160Array([]byte(ip1.IP().String())))
 
-	listener0 := &testListener{
-		addr: &net.TCPAddr{
-			IP:   net.IPv6loopback,
-			Port: 0,
-		},
-		inbound: make(chan net.Conn, 1<<10),
-		closed:  make(chan struct{}),
-	}
-	caller0 := &testDialer{
-		addr: &net.TCPAddr{
-			IP:   net.IPv6loopback,
-			Port: 0,
+	listener0 := networktest.NewListener(&net.TCPAddr{
+		IP:   net.IPv6loopback,
+		Port: 0,
+	})
+	caller0 := networktest.NewDialer(&net.TCPAddr{
+		IP:   net.IPv6loopback,
+		Port: 0,
+	})
+	listener1 := networktest.NewListener(&net.TCPAddr{
+		IP:   net.IPv6loopback,
+		Port: 1,
+	})
+	caller1 := networktest.NewDialer(&net.TCPAddr{
+		IP:   net.IPv6loopback,
+		Port: 1,
+	})
+
+	caller0.Route(ip1.IP(), listener1)
+	caller1.Route(ip0.IP(), listener0)
+
+	serverUpgrader := NewIPUpgrader()
+	clientUpgrader := NewIPUpgrader()
+
+	vdrs := validators.NewSet()
+
+	// net1 rotated its identity away from [previousID]
+	rotation, err := NewIdentityRotation(newTestCert(t), id1, time.Now().Add(time.Hour))
+	assert.NoError(t, err)
+	previousID := rotation.PreviousID()
+	assert.NoError(t, vdrs.AddWeight(previousID, 1))
+
+	var (
+		wg0 sync.WaitGroup
+		wg1 sync.WaitGroup
+	)
+	wg0.Add(2)
+	wg1.Add(1)
+
+	handler0 := &testHandler{
+		connected: func(id ids.ShortID) {
+			if id == id1 || id == previousID {
+				wg0.Done()
+			}
 		},
-		outbounds: make(map[string]*testListener),
 	}
-	listener1 := &testListener{
-		addr: &net.TCPAddr{
-			IP:   net.IPv6loopback,
-			Port: 1,
+
+	handler1 := &testHandler{
+		connected: func(id ids.ShortID) {
+			if id != id1 {
+				wg1.Done()
+			}
 		},
-		inbound: make(chan net.Conn, 1<<10),
-		closed:  make(chan struct{}),
 	}
-	caller1 := &testDialer{
-		addr: &net.TCPAddr{
-			IP:   net.IPv6loopback,
-			Port: 1,
-		},
-		outbounds: make(map[string]*testListener),
+
+	net0 := NewDefaultNetwork(
+		prometheus.NewRegistry(),
+		log,
+		id0,
+		ip0,
+		networkID,
+		appVersion,
+		versionParser,
+		[]net.Listener{listener0},
+		caller0,
+		serverUpgrader,
+		clientUpgrader,
+		vdrs,
+		vdrs,
+		handler0,
+		time.Duration(0),
+		0,
+		nil,
+		false,
+		0,
+		0,
+		time.Now(),
+		defaultSendQueueSize,
+		DefaultSendQueueConfig,
+		BandwidthConfig{},
+		DefaultGossipConfig,
+		DefaultConnectionConfig,
+		HealthConfig{},
+		benchlist.NewManager(&benchlist.Config{}),
+		memdb.New(),
+		defaultAliasTimeout,
+		nil,
+	)
+	assert.NotNil(t, net0)
+
+	net1 := NewDefaultNetwork(
+		prometheus.NewRegistry(),
+		log,
+		id1,
+		ip1,
+		networkID,
+		appVersion,
+		versionParser,
+		[]net.Listener{listener1},
+		caller1,
+		serverUpgrader,
+		clientUpgrader,
+		vdrs,
+		vdrs,
+		handler1,
+		time.Duration(0),
+		0,
+		nil,
+		false,
+		0,
+		0,
+		time.Now(),
+		defaultSendQueueSize,
+		DefaultSendQueueConfig,
+		BandwidthConfig{},
+		DefaultGossipConfig,
+		DefaultConnectionConfig,
+		HealthConfig{},
+		benchlist.NewManager(&benchlist.Config{}),
+		memdb.New(),
+		defaultAliasTimeout,
+		rotation,
+	)
+	assert.NotNil(t, net1)
+
+	go func() {
+		err := net0.Dispatch()
+		assert.Error(t, err)
+	}()
+	go func() {
+		err := net1.Dispatch()
+		assert.Error(t, err)
+	}()
+
+	assert.NoError(t, net0.Track(ip1.IP()))
+
+	wg0.Wait()
+	wg1.Wait()
+
+	// net1 is reachable by, and its messages are attributed to, its previous
+	// ID while its new ID isn't a validator
+	peer := net0.(*network).getPeer(previousID)
+	if assert.NotNil(t, peer) {
+		assert.Equal(t, id1, peer.id)
+		assert.Equal(t, previousID, peer.validatorID())
 	}
 
-	caller0.outbounds[ip1.IP().String()] = listener1
-	caller1.outbounds[ip0.IP().String()] = listener0
+	err = net0.Close()
+	assert.NoError(t, err)
+
+	err = net1.Close()
+	assert.NoError(t, err)
+}
+
+func TestDoubleTrack(t *testing.T) {
+	log := logging.NoLog{}
+	networkID := uint32(0)
+	appVersion := version.NewDefaultVersion("app", 0, 1, 0)
+	versionParser := version.NewDefaultParser()
+
+	ip0 := utils.NewDynamicIPDesc(
+		net.IPv6loopback,
+		0,
+	)
+	id0 := ids.ShortID(hashing.ComputeHash160Array([]byte(ip0.IP().String())))
+	ip1 := utils.NewDynamicIPDesc(
+		net.IPv6loopback,
+		1,
+	)
+	id1 := ids.ShortID(hashing.ComputeHash160Array([]byte(ip1.IP().String())))
+
+	listener0 := networktest.NewListener(&net.TCPAddr{
+		IP:   net.IPv6loopback,
+		Port: 0,
+	})
+	caller0 := networktest.NewDialer(&net.TCPAddr{
+		IP:   net.IPv6loopback,
+		Port: 0,
+	})
+	listener1 := networktest.NewListener(&net.TCPAddr{
+		IP:   net.IPv6loopback,
+		Port: 1,
+	})
+	caller1 := networktest.NewDialer(&net.TCPAddr{
+		IP:   net.IPv6loopback,
+		Port: 1,
+	})
+
+	caller0.Route(ip1.IP(), listener1)
+	caller1.Route(ip0.IP(), listener0)
 
 	serverUpgrader := NewIPUpgrader()
 	clientUpgrader := NewIPUpgrader()
@@ -537,7 +540,7 @@ func TestDoubleTrack(t *testing.T) {
 		networkID,
 		appVersion,
 		versionParser,
-		listener0,
+		[]net.Listener{listener0},
 		caller0,
 		serverUpgrader,
 		clientUpgrader,
@@ -552,9 +555,15 @@ func TestDoubleTrack(t *testing.T) {
 		0,
 		time.Now(),
 		defaultSendQueueSize,
+		DefaultSendQueueConfig,
+		BandwidthConfig{},
+		DefaultGossipConfig,
+		DefaultConnectionConfig,
 		HealthConfig{},
 		benchlist.NewManager(&benchlist.Config{}),
+		memdb.New(),
 		defaultAliasTimeout,
+		nil,
 	)
 	assert.NotNil(t, net0)
 
@@ -566,7 +575,7 @@ func TestDoubleTrack(t *testing.T) {
 		networkID,
 		appVersion,
 		versionParser,
-		listener1,
+		[]net.Listener{listener1},
 		caller1,
 		serverUpgrader,
 		clientUpgrader,
@@ -581,14 +590,20 @@ func TestDoubleTrack(t *testing.T) {
 		0,
 		time.Now(),
 		defaultSendQueueSize,
+		DefaultSendQueueConfig,
+		BandwidthConfig{},
+		DefaultGossipConfig,
+		DefaultConnectionConfig,
 		HealthConfig{},
 		benchlist.NewManager(&benchlist.Config{}),
+		memdb.New(),
 		defaultAliasTimeout,
+		nil,
 	)
 	assert.NotNil(t, net1)
 
-	net0.Track(ip1.IP())
-	net0.Track(ip1.IP())
+	assert.NoError(t, net0.Track(ip1.IP()))
+	assert.NoError(t, net0.Track(ip1.IP()))
 
 	go func() {
 		err := net0.Dispatch()
@@ -626,39 +641,25 @@ func TestDoubleClose(t *testing.T) {
 	)
 	id1 := ids.ShortID(hashing.ComputeHash160Array([]byte(ip1.IP().String())))
 
-	listener0 := &testListener{
-		addr: &net.TCPAddr{
-			IP:   net.IPv6loopback,
-			Port: 0,
-		},
-		inbound: make(chan net.Conn, 1<<10),
-		closed:  make(chan struct{}),
-	}
-	caller0 := &testDialer{
-		addr: &net.TCPAddr{
-			IP:   net.IPv6loopback,
-			Port: 0,
-		},
-		outbounds: make(map[string]*testListener),
-	}
-	listener1 := &testListener{
-		addr: &net.TCPAddr{
-			IP:   net.IPv6loopback,
-			Port: 1,
-		},
-		inbound: make(chan net.Conn, 1<<10),
-		closed:  make(chan struct{}),
-	}
-	caller1 := &testDialer{
-		addr: &net.TCPAddr{
-			IP:   net.IPv6loopback,
-			Port: 1,
-		},
-		outbounds: make(map[string]*testListener),
-	}
+	listener0 := networktest.NewListener(&net.TCPAddr{
+		IP:   net.IPv6loopback,
+		Port: 0,
+	})
+	caller0 := networktest.NewDialer(&net.TCPAddr{
+		IP:   net.IPv6loopback,
+		Port: 0,
+	})
+	listener1 := networktest.NewListener(&net.TCPAddr{
+		IP:   net.IPv6loopback,
+		Port: 1,
+	})
+	caller1 := networktest.NewDialer(&net.TCPAddr{
+		IP:   net.IPv6loopback,
+		Port: 1,
+	})
 
-	caller0.outbounds[ip1.IP().String()] = listener1
-	caller1.outbounds[ip0.IP().String()] = listener0
+	caller0.Route(ip1.IP(), listener1)
+	caller1.Route(ip0.IP(), listener0)
 
 	serverUpgrader := NewIPUpgrader()
 	clientUpgrader := NewIPUpgrader()
@@ -696,7 +697,7 @@ func TestDoubleClose(t *testing.T) {
 		networkID,
 		appVersion,
 		versionParser,
-		listener0,
+		[]net.Listener{listener0},
 		caller0,
 		serverUpgrader,
 		clientUpgrader,
@@ -711,9 +712,15 @@ func TestDoubleClose(t *testing.T) {
 		0,
 		time.Now(),
 		defaultSendQueueSize,
+		DefaultSendQueueConfig,
+		BandwidthConfig{},
+		DefaultGossipConfig,
+		DefaultConnectionConfig,
 		HealthConfig{},
 		benchlist.NewManager(&benchlist.Config{}),
+		memdb.New(),
 		defaultAliasTimeout,
+		nil,
 	)
 	assert.NotNil(t, net0)
 
@@ -725,7 +732,7 @@ func TestDoubleClose(t *testing.T) {
 		networkID,
 		appVersion,
 		versionParser,
-		listener1,
+		[]net.Listener{listener1},
 		caller1,
 		serverUpgrader,
 		clientUpgrader,
@@ -740,13 +747,19 @@ func TestDoubleClose(t *testing.T) {
 		0,
 		time.Now(),
 		defaultSendQueueSize,
+		DefaultSendQueueConfig,
+		BandwidthConfig{},
+		DefaultGossipConfig,
+		DefaultConnectionConfig,
 		HealthConfig{},
 		benchlist.NewManager(&benchlist.Config{}),
+		memdb.New(),
 		defaultAliasTimeout,
+		nil,
 	)
 	assert.NotNil(t, net1)
 
-	net0.Track(ip1.IP())
+	assert.NoError(t, net0.Track(ip1.IP()))
 
 	go func() {
 		err := net0.Dispatch()
@@ -790,39 +803,25 @@ func TestTrackConnected(t *testing.T) {
 	)
 	id1 := ids.ShortID(hashing.ComputeHash160Array([]byte(ip1.IP().String())))
 
-	listener0 := &testListener{
-		addr: &net.TCPAddr{
-			IP:   net.IPv6loopback,
-			Port: 0,
-		},
-		inbound: make(chan net.Conn, 1<<10),
-		closed:  make(chan struct{}),
-	}
-	caller0 := &testDialer{
-		addr: &net.TCPAddr{
-			IP:   net.IPv6loopback,
-			Port: 0,
-		},
-		outbounds: make(map[string]*testListener),
-	}
-	listener1 := &testListener{
-		addr: &net.TCPAddr{
-			IP:   net.IPv6loopback,
-			Port: 1,
-		},
-		inbound: make(chan net.Conn, 1<<10),
-		closed:  make(chan struct{}),
-	}
-	caller1 := &testDialer{
-		addr: &net.TCPAddr{
-			IP:   net.IPv6loopback,
-			Port: 1,
-		},
-		outbounds: make(map[string]*testListener),
-	}
+	listener0 := networktest.NewListener(&net.TCPAddr{
+		IP:   net.IPv6loopback,
+		Port: 0,
+	})
+	caller0 := networktest.NewDialer(&net.TCPAddr{
+		IP:   net.IPv6loopback,
+		Port: 0,
+	})
+	listener1 := networktest.NewListener(&net.TCPAddr{
+		IP:   net.IPv6loopback,
+		Port: 1,
+	})
+	caller1 := networktest.NewDialer(&net.TCPAddr{
+		IP:   net.IPv6loopback,
+		Port: 1,
+	})
 
-	caller0.outbounds[ip1.IP().String()] = listener1
-	caller1.outbounds[ip0.IP().String()] = listener0
+	caller0.Route(ip1.IP(), listener1)
+	caller1.Route(ip0.IP(), listener0)
 
 	serverUpgrader := NewIPUpgrader()
 	clientUpgrader := NewIPUpgrader()
@@ -860,7 +859,7 @@ func TestTrackConnected(t *testing.T) {
 		networkID,
 		appVersion,
 		versionParser,
-		listener0,
+		[]net.Listener{listener0},
 		caller0,
 		serverUpgrader,
 		clientUpgrader,
@@ -875,9 +874,15 @@ func TestTrackConnected(t *testing.T) {
 		0,
 		time.Now(),
 		defaultSendQueueSize,
+		DefaultSendQueueConfig,
+		BandwidthConfig{},
+		DefaultGossipConfig,
+		DefaultConnectionConfig,
 		HealthConfig{},
 		benchlist.NewManager(&benchlist.Config{}),
+		memdb.New(),
 		defaultAliasTimeout,
+		nil,
 	)
 	assert.NotNil(t, net0)
 
@@ -889,7 +894,7 @@ func TestTrackConnected(t *testing.T) {
 		networkID,
 		appVersion,
 		versionParser,
-		listener1,
+		[]net.Listener{listener1},
 		caller1,
 		serverUpgrader,
 		clientUpgrader,
@@ -904,13 +909,19 @@ func TestTrackConnected(t *testing.T) {
 		0,
 		time.Now(),
 		defaultSendQueueSize,
+		DefaultSendQueueConfig,
+		BandwidthConfig{},
+		DefaultGossipConfig,
+		DefaultConnectionConfig,
 		HealthConfig{},
 		benchlist.NewManager(&benchlist.Config{}),
+		memdb.New(),
 		defaultAliasTimeout,
+		nil,
 	)
 	assert.NotNil(t, net1)
 
-	net0.Track(ip1.IP())
+	assert.NoError(t, net0.Track(ip1.IP()))
 
 	go func() {
 		err := net0.Dispatch()
@@ -924,7 +935,7 @@ func TestTrackConnected(t *testing.T) {
 	wg0.Wait()
 	wg1.Wait()
 
-	net0.Track(ip1.IP())
+	assert.NoError(t, net0.Track(ip1.IP()))
 
 	err := net0.Close()
 	assert.NoError(t, err)
@@ -950,39 +961,25 @@ func TestTrackConnectedRace(t *testing.T) {
 	)
 	id1 := ids.ShortID(hashing.ComputeHash160Array([]byte(ip1.IP().String())))
 
-	listener0 := &testListener{
-		addr: &net.TCPAddr{
-			IP:   net.IPv6loopback,
-			Port: 0,
-		},
-		inbound: make(chan net.Conn, 1<<10),
-		closed:  make(chan struct{}),
-	}
-	caller0 := &testDialer{
-		addr: &net.TCPAddr{
-			IP:   net.IPv6loopback,
-			Port: 0,
-		},
-		outbounds: make(map[string]*testListener),
-	}
-	listener1 := &testListener{
-		addr: &net.TCPAddr{
-			IP:   net.IPv6loopback,
-			Port: 1,
-		},
-		inbound: make(chan net.Conn, 1<<10),
-		closed:  make(chan struct{}),
-	}
-	caller1 := &testDialer{
-		addr: &net.TCPAddr{
-			IP:   net.IPv6loopback,
-			Port: 1,
-		},
-		outbounds: make(map[string]*testListener),
-	}
+	listener0 := networktest.NewListener(&net.TCPAddr{
+		IP:   net.IPv6loopback,
+		Port: 0,
+	})
+	caller0 := networktest.NewDialer(&net.TCPAddr{
+		IP:   net.IPv6loopback,
+		Port: 0,
+	})
+	listener1 := networktest.NewListener(&net.TCPAddr{
+		IP:   net.IPv6loopback,
+		Port: 1,
+	})
+	caller1 := networktest.NewDialer(&net.TCPAddr{
+		IP:   net.IPv6loopback,
+		Port: 1,
+	})
 
-	caller0.outbounds[ip1.IP().String()] = listener1
-	caller1.outbounds[ip0.IP().String()] = listener0
+	caller0.Route(ip1.IP(), listener1)
+	caller1.Route(ip0.IP(), listener0)
 
 	serverUpgrader := NewIPUpgrader()
 	clientUpgrader := NewIPUpgrader()
@@ -998,7 +995,7 @@ func TestTrackConnectedRace(t *testing.T) {
 		networkID,
 		appVersion,
 		versionParser,
-		listener0,
+		[]net.Listener{listener0},
 		caller0,
 		serverUpgrader,
 		clientUpgrader,
@@ -1013,9 +1010,15 @@ func TestTrackConnectedRace(t *testing.T) {
 		0,
 		time.Now(),
 		defaultSendQueueSize,
+		DefaultSendQueueConfig,
+		BandwidthConfig{},
+		DefaultGossipConfig,
+		DefaultConnectionConfig,
 		HealthConfig{},
 		benchlist.NewManager(&benchlist.Config{}),
+		memdb.New(),
 		defaultAliasTimeout,
+		nil,
 	)
 	assert.NotNil(t, net0)
 
@@ -1027,7 +1030,7 @@ func TestTrackConnectedRace(t *testing.T) {
 		networkID,
 		appVersion,
 		versionParser,
-		listener1,
+		[]net.Listener{listener1},
 		caller1,
 		serverUpgrader,
 		clientUpgrader,
@@ -1042,13 +1045,19 @@ func TestTrackConnectedRace(t *testing.T) {
 		0,
 		time.Now(),
 		defaultSendQueueSize,
+		DefaultSendQueueConfig,
+		BandwidthConfig{},
+		DefaultGossipConfig,
+		DefaultConnectionConfig,
 		HealthConfig{},
 		benchlist.NewManager(&benchlist.Config{}),
+		memdb.New(),
 		defaultAliasTimeout,
+		nil,
 	)
 	assert.NotNil(t, net1)
 
-	net0.Track(ip1.IP())
+	assert.NoError(t, net0.Track(ip1.IP()))
 
 	go func() {
 		err := net0.Dispatch()
@@ -1097,72 +1106,44 @@ func TestPeerAliasesTicker(t *testing.T) {
 	)
 	id2 := ids.ShortID(hashing.ComputeHash160Array([]byte(ip2.IP().String())))
 
-	listener0 := &testListener{
-		addr: &net.TCPAddr{
-			IP:   net.IPv6loopback,
-			Port: 0,
-		},
-		inbound: make(chan net.Conn, 1<<10),
-		closed:  make(chan struct{}),
-	}
-	caller0 := &testDialer{
-		addr: &net.TCPAddr{
-			IP:   net.IPv6loopback,
-			Port: 0,
-		},
-		outbounds: make(map[string]*testListener),
-	}
-	listener1 := &testListener{
-		addr: &net.TCPAddr{
-			IP:   net.IPv6loopback,
-			Port: 1,
-		},
-		inbound: make(chan net.Conn, 1<<10),
-		closed:  make(chan struct{}),
-	}
-	caller1 := &testDialer{
-		addr: &net.TCPAddr{
-			IP:   net.IPv6loopback,
-			Port: 1,
-		},
-		outbounds: make(map[string]*testListener),
-	}
-	listener2 := &testListener{
-		addr: &net.TCPAddr{
-			IP:   net.IPv6loopback,
-			Port: 2,
-		},
-		inbound: make(chan net.Conn, 1<<10),
-		closed:  make(chan struct{}),
-	}
-	caller2 := &testDialer{
-		addr: &net.TCPAddr{
-			IP:   net.IPv6loopback,
-			Port: 2,
-		},
-		outbounds: make(map[string]*testListener),
-	}
-	listener3 := &testListener{
-		addr: &net.TCPAddr{
-			IP:   net.IPv6loopback,
-			Port: 2,
-		},
-		inbound: make(chan net.Conn, 1<<10),
-		closed:  make(chan struct{}),
-	}
-	caller3 := &testDialer{
-		addr: &net.TCPAddr{
-			IP:   net.IPv6loopback,
-			Port: 2,
-		},
-		outbounds: make(map[string]*testListener),
-	}
+	listener0 := networktest.NewListener(&net.TCPAddr{
+		IP:   net.IPv6loopback,
+		Port: 0,
+	})
+	caller0 := networktest.NewDialer(&net.TCPAddr{
+		IP:   net.IPv6loopback,
+		Port: 0,
+	})
+	listener1 := networktest.NewListener(&net.TCPAddr{
+		IP:   net.IPv6loopback,
+		Port: 1,
+	})
+	caller1 := networktest.NewDialer(&net.TCPAddr{
+		IP:   net.IPv6loopback,
+		Port: 1,
+	})
+	listener2 := networktest.NewListener(&net.TCPAddr{
+		IP:   net.IPv6loopback,
+		Port: 2,
+	})
+	caller2 := networktest.NewDialer(&net.TCPAddr{
+		IP:   net.IPv6loopback,
+		Port: 2,
+	})
+	listener3 := networktest.NewListener(&net.TCPAddr{
+		IP:   net.IPv6loopback,
+		Port: 2,
+	})
+	caller3 := networktest.NewDialer(&net.TCPAddr{
+		IP:   net.IPv6loopback,
+		Port: 2,
+	})
 
-	caller0.outbounds[ip1.IP().String()] = listener1
-	caller0.outbounds[ip2.IP().String()] = listener2
-	caller1.outbounds[ip0.IP().String()] = listener0
-	caller2.outbounds[ip0.IP().String()] = listener0
-	caller3.outbounds[ip0.IP().String()] = listener0
+	caller0.Route(ip1.IP(), listener1)
+	caller0.Route(ip2.IP(), listener2)
+	caller1.Route(ip0.IP(), listener0)
+	caller2.Route(ip0.IP(), listener0)
+	caller3.Route(ip0.IP(), listener0)
 
 	upgrader := &testUpgrader{
 		ids: map[string]ids.ShortID{
@@ -1244,7 +1225,7 @@ func TestPeerAliasesTicker(t *testing.T) {
 		},
 	}
 
-	caller0.closer = func(local net.Addr, remote net.Addr) {
+	caller0.OnClose(func(local net.Addr, remote net.Addr) {
 		if remote.String() == ip2.String() && !wg1Done {
 			wg1.Done()
 			return
@@ -1254,7 +1235,7 @@ func TestPeerAliasesTicker(t *testing.T) {
 		}
 
 		assert.Fail(t, "caller 0 unauthorized close", local.String(), remote.String())
-	}
+	})
 
 	net0 := NewDefaultNetwork(
 		prometheus.NewRegistry(),
@@ -1264,7 +1245,7 @@ func TestPeerAliasesTicker(t *testing.T) {
 		networkID,
 		appVersion,
 		versionParser,
-		listener0,
+		[]net.Listener{listener0},
 		caller0,
 		serverUpgrader,
 		clientUpgrader,
@@ -1279,9 +1260,15 @@ func TestPeerAliasesTicker(t *testing.T) {
 		0,
 		time.Now(),
 		defaultSendQueueSize,
+		DefaultSendQueueConfig,
+		BandwidthConfig{},
+		DefaultGossipConfig,
+		DefaultConnectionConfig,
 		HealthConfig{},
 		benchlist.NewManager(&benchlist.Config{}),
+		memdb.New(),
 		defaultAliasTimeout,
+		nil,
 	)
 	assert.NotNil(t, net0)
 
@@ -1293,7 +1280,7 @@ func TestPeerAliasesTicker(t *testing.T) {
 		networkID,
 		appVersion,
 		versionParser,
-		listener1,
+		[]net.Listener{listener1},
 		caller1,
 		serverUpgrader,
 		clientUpgrader,
@@ -1308,9 +1295,15 @@ func TestPeerAliasesTicker(t *testing.T) {
 		0,
 		time.Now(),
 		defaultSendQueueSize,
+		DefaultSendQueueConfig,
+		BandwidthConfig{},
+		DefaultGossipConfig,
+		DefaultConnectionConfig,
 		HealthConfig{},
 		benchlist.NewManager(&benchlist.Config{}),
+		memdb.New(),
 		defaultAliasTimeout,
+		nil,
 	)
 	assert.NotNil(t, net1)
 
@@ -1322,7 +1315,7 @@ func TestPeerAliasesTicker(t *testing.T) {
 		networkID,
 		appVersion,
 		versionParser,
-		listener2,
+		[]net.Listener{listener2},
 		caller2,
 		serverUpgrader,
 		clientUpgrader,
@@ -1337,9 +1330,15 @@ func TestPeerAliasesTicker(t *testing.T) {
 		0,
 		time.Now(),
 		defaultSendQueueSize,
+		DefaultSendQueueConfig,
+		BandwidthConfig{},
+		DefaultGossipConfig,
+		DefaultConnectionConfig,
 		HealthConfig{},
 		benchlist.NewManager(&benchlist.Config{}),
+		memdb.New(),
 		defaultAliasTimeout,
+		nil,
 	)
 	assert.NotNil(t, net2)
 
@@ -1351,7 +1350,7 @@ func TestPeerAliasesTicker(t *testing.T) {
 		networkID,
 		appVersion,
 		versionParser,
-		listener3,
+		[]net.Listener{listener3},
 		caller3,
 		serverUpgrader,
 		clientUpgrader,
@@ -1366,9 +1365,15 @@ func TestPeerAliasesTicker(t *testing.T) {
 		0,
 		time.Now(),
 		defaultSendQueueSize,
+		DefaultSendQueueConfig,
+		BandwidthConfig{},
+		DefaultGossipConfig,
+		DefaultConnectionConfig,
 		HealthConfig{},
 		benchlist.NewManager(&benchlist.Config{}),
+		memdb.New(),
 		defaultAliasTimeout,
+		nil,
 	)
 	assert.NotNil(t, net3)
 
@@ -1390,7 +1395,7 @@ func TestPeerAliasesTicker(t *testing.T) {
 	}()
 
 	// Connect to peer with id1
-	net0.Track(ip1.IP())
+	assert.NoError(t, net0.Track(ip1.IP()))
 
 	// Confirm peers correct
 	wg0.Wait()
@@ -1404,7 +1409,7 @@ func TestPeerAliasesTicker(t *testing.T) {
 	assert.Len(t, net3.Peers([]ids.ShortID{}), 0)
 
 	// Attempt to connect to ip2 (same id as ip1)
-	net0.Track(ip2.IP())
+	assert.NoError(t, net0.Track(ip2.IP()))
 
 	// Confirm that ip2 was not added to net0 peers
 	wg1.Wait()
@@ -1420,15 +1425,15 @@ func TestPeerAliasesTicker(t *testing.T) {
 
 	// Subsequent track call returns immediately with no connection attempts
 	// (would cause fatal error from unauthorized connection if allowed)
-	net0.Track(ip2.IP())
+	assert.NoError(t, net0.Track(ip2.IP()))
 
 	// Wait for aliases to be removed by peer
 	time.Sleep(3 * time.Second)
 
 	// Track ip2 on net3
 	upgrader.Update(ip2, id2)
-	caller0.Update(ip2, listener3)
-	net0.Track(ip2.IP())
+	caller0.Route(ip2.IP(), listener3)
+	assert.NoError(t, net0.Track(ip2.IP()))
 
 	// Confirm that id2 was added as peer
 	wg2.Wait()
@@ -1481,72 +1486,44 @@ func TestPeerAliasesDisconnect(t *testing.T) {
 	)
 	id2 := ids.ShortID(hashing.ComputeHash160Array([]byte(ip2.IP().String())))
 
-	listener0 := &testListener{
-		addr: &net.TCPAddr{
-			IP:   net.IPv6loopback,
-			Port: 0,
-		},
-		inbound: make(chan net.Conn, 1<<10),
-		closed:  make(chan struct{}),
-	}
-	caller0 := &testDialer{
-		addr: &net.TCPAddr{
-			IP:   net.IPv6loopback,
-			Port: 0,
-		},
-		outbounds: make(map[string]*testListener),
-	}
-	listener1 := &testListener{
-		addr: &net.TCPAddr{
-			IP:   net.IPv6loopback,
-			Port: 1,
-		},
-		inbound: make(chan net.Conn, 1<<10),
-		closed:  make(chan struct{}),
-	}
-	caller1 := &testDialer{
-		addr: &net.TCPAddr{
-			IP:   net.IPv6loopback,
-			Port: 1,
-		},
-		outbounds: make(map[string]*testListener),
-	}
-	listener2 := &testListener{
-		addr: &net.TCPAddr{
-			IP:   net.IPv6loopback,
-			Port: 2,
-		},
-		inbound: make(chan net.Conn, 1<<10),
-		closed:  make(chan struct{}),
-	}
-	caller2 := &testDialer{
-		addr: &net.TCPAddr{
-			IP:   net.IPv6loopback,
-			Port: 2,
-		},
-		outbounds: make(map[string]*testListener),
-	}
-	listener3 := &testListener{
-		addr: &net.TCPAddr{
-			IP:   net.IPv6loopback,
-			Port: 2,
-		},
-		inbound: make(chan net.Conn, 1<<10),
-		closed:  make(chan struct{}),
-	}
-	caller3 := &testDialer{
-		addr: &net.TCPAddr{
-			IP:   net.IPv6loopback,
-			Port: 2,
-		},
-		outbounds: make(map[string]*testListener),
-	}
+	listener0 := networktest.NewListener(&net.TCPAddr{
+		IP:   net.IPv6loopback,
+		Port: 0,
+	})
+	caller0 := networktest.NewDialer(&net.TCPAddr{
+		IP:   net.IPv6loopback,
+		Port: 0,
+	})
+	listener1 := networktest.NewListener(&net.TCPAddr{
+		IP:   net.IPv6loopback,
+		Port: 1,
+	})
+	caller1 := networktest.NewDialer(&net.TCPAddr{
+		IP:   net.IPv6loopback,
+		Port: 1,
+	})
+	listener2 := networktest.NewListener(&net.TCPAddr{
+		IP:   net.IPv6loopback,
+		Port: 2,
+	})
+	caller2 := networktest.NewDialer(&net.TCPAddr{
+		IP:   net.IPv6loopback,
+		Port: 2,
+	})
+	listener3 := networktest.NewListener(&net.TCPAddr{
+		IP:   net.IPv6loopback,
+		Port: 2,
+	})
+	caller3 := networktest.NewDialer(&net.TCPAddr{
+		IP:   net.IPv6loopback,
+		Port: 2,
+	})
 
-	caller0.outbounds[ip1.IP().String()] = listener1
-	caller0.outbounds[ip2.IP().String()] = listener2
-	caller1.outbounds[ip0.IP().String()] = listener0
-	caller2.outbounds[ip0.IP().String()] = listener0
-	caller3.outbounds[ip0.IP().String()] = listener0
+	caller0.Route(ip1.IP(), listener1)
+	caller0.Route(ip2.IP(), listener2)
+	caller1.Route(ip0.IP(), listener0)
+	caller2.Route(ip0.IP(), listener0)
+	caller3.Route(ip0.IP(), listener0)
 
 	upgrader := &testUpgrader{
 		ids: map[string]ids.ShortID{
@@ -1642,7 +1619,7 @@ func TestPeerAliasesDisconnect(t *testing.T) {
 		},
 	}
 
-	caller0.closer = func(local net.Addr, remote net.Addr) {
+	caller0.OnClose(func(local net.Addr, remote net.Addr) {
 		if remote.String() == ip2.String() && !wg1Done {
 			wg1.Done()
 			return
@@ -1660,7 +1637,7 @@ func TestPeerAliasesDisconnect(t *testing.T) {
 		}
 
 		assert.Fail(t, "caller 0 unauthorized close", local.String(), remote.String())
-	}
+	})
 
 	net0 := NewDefaultNetwork(
 		prometheus.NewRegistry(),
@@ -1670,7 +1647,7 @@ func TestPeerAliasesDisconnect(t *testing.T) {
 		networkID,
 		appVersion,
 		versionParser,
-		listener0,
+		[]net.Listener{listener0},
 		caller0,
 		serverUpgrader,
 		clientUpgrader,
@@ -1685,9 +1662,15 @@ func TestPeerAliasesDisconnect(t *testing.T) {
 		0,
 		time.Now(),
 		defaultSendQueueSize,
+		DefaultSendQueueConfig,
+		BandwidthConfig{},
+		DefaultGossipConfig,
+		DefaultConnectionConfig,
 		HealthConfig{},
 		benchlist.NewManager(&benchlist.Config{}),
+		memdb.New(),
 		defaultAliasTimeout,
+		nil,
 	)
 	assert.NotNil(t, net0)
 
@@ -1699,7 +1682,7 @@ func TestPeerAliasesDisconnect(t *testing.T) {
 		networkID,
 		appVersion,
 		versionParser,
-		listener1,
+		[]net.Listener{listener1},
 		caller1,
 		serverUpgrader,
 		clientUpgrader,
@@ -1714,9 +1697,15 @@ func TestPeerAliasesDisconnect(t *testing.T) {
 		0,
 		time.Now(),
 		defaultSendQueueSize,
+		DefaultSendQueueConfig,
+		BandwidthConfig{},
+		DefaultGossipConfig,
+		DefaultConnectionConfig,
 		HealthConfig{},
 		benchlist.NewManager(&benchlist.Config{}),
+		memdb.New(),
 		defaultAliasTimeout,
+		nil,
 	)
 	assert.NotNil(t, net1)
 
@@ -1728,7 +1717,7 @@ func TestPeerAliasesDisconnect(t *testing.T) {
 		networkID,
 		appVersion,
 		versionParser,
-		listener2,
+		[]net.Listener{listener2},
 		caller2,
 		serverUpgrader,
 		clientUpgrader,
@@ -1743,9 +1732,15 @@ func TestPeerAliasesDisconnect(t *testing.T) {
 		0,
 		time.Now(),
 		defaultSendQueueSize,
+		DefaultSendQueueConfig,
+		BandwidthConfig{},
+		DefaultGossipConfig,
+		DefaultConnectionConfig,
 		HealthConfig{},
 		benchlist.NewManager(&benchlist.Config{}),
+		memdb.New(),
 		defaultAliasTimeout,
+		nil,
 	)
 	assert.NotNil(t, net2)
 
@@ -1757,7 +1752,7 @@ func TestPeerAliasesDisconnect(t *testing.T) {
 		networkID,
 		appVersion,
 		versionParser,
-		listener3,
+		[]net.Listener{listener3},
 		caller3,
 		serverUpgrader,
 		clientUpgrader,
@@ -1772,9 +1767,15 @@ func TestPeerAliasesDisconnect(t *testing.T) {
 		0,
 		time.Now(),
 		defaultSendQueueSize,
+		DefaultSendQueueConfig,
+		BandwidthConfig{},
+		DefaultGossipConfig,
+		DefaultConnectionConfig,
 		HealthConfig{},
 		benchlist.NewManager(&benchlist.Config{}),
+		memdb.New(),
 		defaultAliasTimeout,
+		nil,
 	)
 	assert.NotNil(t, net3)
 
@@ -1796,7 +1797,7 @@ func TestPeerAliasesDisconnect(t *testing.T) {
 	}()
 
 	// Connect to peer with id1
-	net0.Track(ip1.IP())
+	assert.NoError(t, net0.Track(ip1.IP()))
 
 	// Confirm peers correct
 	wg0.Wait()
@@ -1810,7 +1811,7 @@ func TestPeerAliasesDisconnect(t *testing.T) {
 	assert.Len(t, net3.Peers([]ids.ShortID{}), 0)
 
 	// Attempt to connect to ip2 (same id as ip1)
-	net0.Track(ip2.IP())
+	assert.NoError(t, net0.Track(ip2.IP()))
 
 	// Confirm that ip2 was not added to net0 peers
 	wg1.Wait()
@@ -1825,7 +1826,7 @@ func TestPeerAliasesDisconnect(t *testing.T) {
 	assert.Len(t, net3.Peers([]ids.ShortID{}), 0)
 
 	// Disconnect original peer
-	caller0.clients[ip1.String()].Close()
+	caller0.Client(ip1.IP()).Close()
 
 	// Track ip2 on net3
 	wg2.Wait()
@@ -1837,8 +1838,8 @@ func TestPeerAliasesDisconnect(t *testing.T) {
 	assert.Len(t, net2.Peers([]ids.ShortID{}), 0)
 	assert.Len(t, net3.Peers([]ids.ShortID{}), 0)
 	upgrader.Update(ip2, id2)
-	caller0.Update(ip2, listener3)
-	net0.Track(ip2.IP())
+	caller0.Route(ip2.IP(), listener3)
+	assert.NoError(t, net0.Track(ip2.IP()))
 
 	// Confirm that id2 was added as peer
 	wg3.Wait()
@@ -0,0 +1,212 @@
+// (c) 2019-2020, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+// Package networktest provides in-memory listeners, dialers, and connections
+// that can stand in for real sockets in tests of the network, and of anything
+// built on top of it. Connections can be given latency and packet loss to
+// simulate realistic network conditions.
+//
+// This package doesn't depend on the network package, so that the network
+// package's own tests can use it.
+package networktest
+
+import (
+	"errors"
+	"math/rand"
+	"net"
+	"sync"
+	"time"
+)
+
+var (
+	// ErrClosed is returned by operations on a closed listener or connection
+	ErrClosed = errors.New("closed")
+	// ErrRefused is returned when dialing an address that nothing is listening
+	// on
+	ErrRefused = errors.New("connection refused")
+)
+
+// pendingWritesSize is the number of writes that may be buffered on a
+// connection before writes block
+const pendingWritesSize = 1 << 10
+
+// Conditions of the simulated network that connections are established over.
+// The zero value delivers writes immediately, and never loses them.
+type Conditions struct {
+	// Time it takes for a write to be delivered to the other side of the
+	// connection
+	Latency time.Duration
+
+	// Maximum amount of time, chosen uniformly at random, that is added to
+	// the latency of each write
+	Jitter time.Duration
+
+	// Probability, in [0, 1], that a write is lost. Connections are reliable
+	// streams, like TCP, so lost writes are retransmitted and delivered after
+	// an additional [RetransmitDelay], rather than dropped.
+	LossRate float64
+
+	// Time it takes for a lost write to be retransmitted
+	RetransmitDelay time.Duration
+}
+
+// delay returns how long a write should take to be delivered
+func (c Conditions) delay() time.Duration {
+	delay := c.Latency
+	if c.Jitter > 0 {
+		delay += time.Duration(rand.Int63n(int64(c.Jitter))) // #nosec G404
+	}
+	if c.LossRate > 0 && rand.Float64() < c.LossRate { // #nosec G404
+		delay += c.RetransmitDelay
+	}
+	return delay
+}
+
+// write is a write that will be delivered at [deliverAt]
+type write struct {
+	bytes     []byte
+	deliverAt time.Time
+}
+
+// Conn is one side of an in-memory connection
+type Conn struct {
+	conditions Conditions
+
+	partialRead   []byte
+	pendingReads  chan write
+	pendingWrites chan write
+	// Time that the last write will be delivered at. Writes are delivered in
+	// order, so later writes are never delivered earlier.
+	lastDelivery time.Time
+	writeLock    sync.Mutex
+
+	closed chan struct{}
+	once   sync.Once
+	// onClose is invoked when the connection is closed
+	onClose func(local, remote net.Addr)
+
+	local, remote net.Addr
+}
+
+// NewConnPair returns the two sides of a connection between [local] and
+// [remote], over a network with [conditions]. [onClose], if non-nil, is
+// invoked with the local and remote addresses of each side that is closed.
+func NewConnPair(local, remote net.Addr, conditions Conditions, onClose func(local, remote net.Addr)) (*Conn, *Conn) {
+	localToRemote := make(chan write, pendingWritesSize)
+	remoteToLocal := make(chan write, pendingWritesSize)
+	localConn := &Conn{
+		conditions:    conditions,
+		pendingReads:  remoteToLocal,
+		pendingWrites: localToRemote,
+		closed:        make(chan struct{}),
+		onClose:       onClose,
+		local:         local,
+		remote:        remote,
+	}
+	remoteConn := &Conn{
+		conditions:    conditions,
+		pendingReads:  localToRemote,
+		pendingWrites: remoteToLocal,
+		closed:        make(chan struct{}),
+		onClose:       onClose,
+		local:         remote,
+		remote:        local,
+	}
+	return localConn, remoteConn
+}
+
+// Read implements the net.Conn interface. Blocks until data written by the
+// other side has been delivered.
+func (c *Conn) Read(b []byte) (int, error) {
+	for len(c.partialRead) == 0 {
+		select {
+		case read := <-c.pendingReads:
+			if err := c.waitUntil(read.deliverAt); err != nil {
+				return 0, err
+			}
+			c.partialRead = read.bytes
+		case <-c.closed:
+			return 0, ErrClosed
+		}
+	}
+
+	n := copy(b, c.partialRead)
+	c.partialRead = c.partialRead[n:]
+	return n, nil
+}
+
+// waitUntil blocks until [deliverAt], or until the connection is closed
+func (c *Conn) waitUntil(deliverAt time.Time) error {
+	wait := time.Until(deliverAt)
+	if wait <= 0 {
+		return nil
+	}
+	timer := time.NewTimer(wait)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-c.closed:
+		return ErrClosed
+	}
+}
+
+// Write implements the net.Conn interface. [b] is delivered to the other side
+// after the delay given by the connection's conditions.
+func (c *Conn) Write(b []byte) (int, error) {
+	c.writeLock.Lock()
+	defer c.writeLock.Unlock()
+
+	// Checked first because the select below picks randomly if there's also
+	// room for the write
+	select {
+	case <-c.closed:
+		return 0, ErrClosed
+	default:
+	}
+
+	deliverAt := time.Now().Add(c.conditions.delay())
+	if deliverAt.Before(c.lastDelivery) {
+		deliverAt = c.lastDelivery
+	}
+	c.lastDelivery = deliverAt
+
+	bytes := make([]byte, len(b))
+	copy(bytes, b)
+	select {
+	case c.pendingWrites <- write{bytes: bytes, deliverAt: deliverAt}:
+		return len(b), nil
+	case <-c.closed:
+		return 0, ErrClosed
+	}
+}
+
+// Close implements the net.Conn interface. Only this side of the connection
+// is closed.
+func (c *Conn) Close() error {
+	c.once.Do(func() {
+		close(c.closed)
+
+		if c.onClose != nil {
+			c.onClose(c.local, c.remote)
+		}
+	})
+	return nil
+}
+
+// LocalAddr implements the net.Conn interface
+func (c *Conn) LocalAddr() net.Addr { return c.local }
+
+// RemoteAddr implements the net.Conn interface
+func (c *Conn) RemoteAddr() net.Addr { return c.remote }
+
+// SetDeadline implements the net.Conn interface. Deadlines aren't enforced.
+func (c *Conn) SetDeadline(time.Time) error { return nil }
+
+// SetReadDeadline implements the net.Conn interface. Deadlines aren't
+// enforced.
+func (c *Conn) SetReadDeadline(time.Time) error { return nil }
+
+// SetWriteDeadline implements the net.Conn interface. Deadlines aren't
+// enforced.
+func (c *Conn) SetWriteDeadline(time.Time) error { return nil }
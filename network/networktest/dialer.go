@@ -0,0 +1,92 @@
+// (c) 2019-2020, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package networktest
+
+import (
+	"net"
+	"sync"
+
+	"github.com/ava-labs/avalanchego/utils"
+)
+
+// Dialer connects to the Listeners that have been routed to it. It implements
+// the Dialer interface of the network package.
+type Dialer struct {
+	addr net.Addr
+
+	lock       sync.Mutex
+	conditions Conditions
+	// IP --> listener that dials to the IP connect to
+	routes map[string]*Listener
+	// IP --> client side of the last connection dialed to the IP
+	clients map[string]*Conn
+	// onClose is invoked when a connection dialed by this dialer is closed
+	onClose func(local, remote net.Addr)
+}
+
+// NewDialer returns a dialer whose connections come from [addr]
+func NewDialer(addr net.Addr) *Dialer {
+	return &Dialer{
+		addr:    addr,
+		routes:  make(map[string]*Listener),
+		clients: make(map[string]*Conn),
+	}
+}
+
+// Route dials to [ip] to [listener]
+func (d *Dialer) Route(ip utils.IPDesc, listener *Listener) {
+	d.lock.Lock()
+	defer d.lock.Unlock()
+
+	d.routes[ip.String()] = listener
+}
+
+// SetConditions sets the conditions of the connections that are dialed from
+// now on
+func (d *Dialer) SetConditions(conditions Conditions) {
+	d.lock.Lock()
+	defer d.lock.Unlock()
+
+	d.conditions = conditions
+}
+
+// OnClose sets a function that is invoked with the local and remote addresses
+// of each side of a connection dialed from now on, when that side is closed
+func (d *Dialer) OnClose(onClose func(local, remote net.Addr)) {
+	d.lock.Lock()
+	defer d.lock.Unlock()
+
+	d.onClose = onClose
+}
+
+// Client returns the client side of the last connection dialed to [ip], or
+// nil if [ip] hasn't been dialed
+func (d *Dialer) Client(ip utils.IPDesc) *Conn {
+	d.lock.Lock()
+	defer d.lock.Unlock()
+
+	return d.clients[ip.String()]
+}
+
+// Dial connects to the listener routed to [ip]. Returns ErrRefused if no
+// listener is routed to [ip], or if the listener has too many connections
+// waiting to be accepted.
+func (d *Dialer) Dial(ip utils.IPDesc) (net.Conn, error) {
+	d.lock.Lock()
+	defer d.lock.Unlock()
+
+	listener, ok := d.routes[ip.String()]
+	if !ok {
+		return nil, ErrRefused
+	}
+	client, server := NewConnPair(d.addr, listener.addr, d.conditions, d.onClose)
+
+	select {
+	case listener.inbound <- server:
+		d.clients[ip.String()] = client
+		return client, nil
+	default:
+		return nil, ErrRefused
+	}
+}
@@ -0,0 +1,49 @@
+// (c) 2019-2020, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package networktest
+
+import (
+	"net"
+	"sync"
+)
+
+// pendingAcceptsSize is the number of dialed connections that may be waiting
+// to be accepted before dials are refused
+const pendingAcceptsSize = 1 << 10
+
+// Listener accepts the connections dialed to it by a Dialer
+type Listener struct {
+	addr    net.Addr
+	inbound chan net.Conn
+	once    sync.Once
+	closed  chan struct{}
+}
+
+// NewListener returns a listener whose address is [addr]
+func NewListener(addr net.Addr) *Listener {
+	return &Listener{
+		addr:    addr,
+		inbound: make(chan net.Conn, pendingAcceptsSize),
+		closed:  make(chan struct{}),
+	}
+}
+
+// Accept implements the net.Listener interface
+func (l *Listener) Accept() (net.Conn, error) {
+	select {
+	case c := <-l.inbound:
+		return c, nil
+	case <-l.closed:
+		return nil, ErrClosed
+	}
+}
+
+// Close implements the net.Listener interface
+func (l *Listener) Close() error {
+	l.once.Do(func() { close(l.closed) })
+	return nil
+}
+
+// Addr implements the net.Listener interface
+func (l *Listener) Addr() net.Addr { return l.addr }
@@ -0,0 +1,100 @@
+// (c) 2019-2020, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package networktest
+
+import (
+	"io"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/ava-labs/avalanchego/utils"
+)
+
+func TestDialAndAccept(t *testing.T) {
+	serverAddr := &net.TCPAddr{IP: net.IPv6loopback, Port: 1}
+	clientAddr := &net.TCPAddr{IP: net.IPv6loopback, Port: 2}
+	serverIP := utils.IPDesc{IP: serverAddr.IP, Port: uint16(serverAddr.Port)}
+
+	listener := NewListener(serverAddr)
+	dialer := NewDialer(clientAddr)
+
+	_, err := dialer.Dial(serverIP)
+	assert.Equal(t, ErrRefused, err)
+	assert.Nil(t, dialer.Client(serverIP))
+
+	var closed []net.Addr
+	dialer.OnClose(func(local, _ net.Addr) { closed = append(closed, local) })
+	dialer.Route(serverIP, listener)
+	client, err := dialer.Dial(serverIP)
+	assert.NoError(t, err)
+	assert.Equal(t, client, dialer.Client(serverIP))
+	server, err := listener.Accept()
+	assert.NoError(t, err)
+	assert.Equal(t, clientAddr, server.RemoteAddr())
+	assert.Equal(t, serverAddr, client.RemoteAddr())
+
+	_, err = client.Write([]byte("hello"))
+	assert.NoError(t, err)
+	_, err = client.Write([]byte(" world"))
+	assert.NoError(t, err)
+	read := make([]byte, 11)
+	_, err = io.ReadFull(server, read)
+	assert.NoError(t, err)
+	assert.Equal(t, "hello world", string(read))
+
+	assert.NoError(t, client.Close())
+	_, err = client.Write([]byte{0})
+	assert.Equal(t, ErrClosed, err)
+	assert.Equal(t, []net.Addr{clientAddr}, closed)
+
+	assert.NoError(t, listener.Close())
+	_, err = listener.Accept()
+	assert.Equal(t, ErrClosed, err)
+}
+
+func TestConditionsDelayWrites(t *testing.T) {
+	addr := &net.TCPAddr{IP: net.IPv6loopback}
+	latency := 20 * time.Millisecond
+	client, server := NewConnPair(addr, addr, Conditions{
+		Latency:         latency,
+		Jitter:          latency,
+		LossRate:        1,
+		RetransmitDelay: latency,
+	}, nil)
+
+	start := time.Now()
+	for i := byte(0); i < 10; i++ {
+		_, err := client.Write([]byte{i})
+		assert.NoError(t, err)
+	}
+
+	// Writes are delivered in order, after the latency and the retransmission
+	// of the lost write
+	read := make([]byte, 1)
+	for i := byte(0); i < 10; i++ {
+		_, err := server.Read(read)
+		assert.NoError(t, err)
+		assert.Equal(t, i, read[0])
+	}
+	assert.True(t, time.Since(start) >= 2*latency)
+}
+
+func TestCloseInterruptsDelayedRead(t *testing.T) {
+	addr := &net.TCPAddr{IP: net.IPv6loopback}
+	client, server := NewConnPair(addr, addr, Conditions{Latency: time.Hour}, nil)
+
+	_, err := client.Write([]byte{1})
+	assert.NoError(t, err)
+
+	readErr := make(chan error, 1)
+	go func() {
+		_, err := server.Read(make([]byte, 1))
+		readErr <- err
+	}()
+	assert.NoError(t, server.Close())
+	assert.Equal(t, ErrClosed, <-readErr)
+}
@@ -4,18 +4,19 @@
 package network
 
 import (
-	"encoding/binary"
+	"bufio"
 	"math"
 	"net"
 	"sync"
 	"sync/atomic"
 	"time"
 
+	"github.com/ava-labs/avalanchego/cache"
 	"github.com/ava-labs/avalanchego/ids"
 	"github.com/ava-labs/avalanchego/utils"
+	"github.com/ava-labs/avalanchego/utils/constants"
 	"github.com/ava-labs/avalanchego/utils/formatting"
 	"github.com/ava-labs/avalanchego/utils/timer"
-	"github.com/ava-labs/avalanchego/utils/wrappers"
 	"github.com/ava-labs/avalanchego/version"
 )
 
@@ -29,6 +30,72 @@ type alias struct {
 	expiry time.Time
 }
 
+// queuedMsg is a message waiting to be sent to a peer
+type queuedMsg struct {
+	op    Op
+	bytes []byte
+}
+
+// classQueue holds the messages of a single send class queued on behalf of a
+// chain
+type classQueue struct {
+	// messages waiting to be sent, in the order they were queued
+	msgs []queuedMsg
+
+	// number of bytes in [msgs]
+	pendingBytes int64
+}
+
+// push [msg] to the back of the queue
+func (q *classQueue) push(msg queuedMsg) {
+	q.msgs = append(q.msgs, msg)
+	q.pendingBytes += int64(len(msg.bytes))
+}
+
+// pop the message at the front of the queue
+func (q *classQueue) pop() queuedMsg {
+	msg := q.msgs[0]
+	q.msgs[0] = queuedMsg{}
+	q.msgs = q.msgs[1:]
+	q.pendingBytes -= int64(len(msg.bytes))
+	return msg
+}
+
+// chainQueue is the logical channel of a single chain over a peer connection
+type chainQueue struct {
+	// messages waiting to be sent, by send class
+	classes [numSendClasses]classQueue
+
+	// number of messages in [classes]
+	numMsgs int
+
+	// number of bytes in [classes]
+	pendingBytes int64
+}
+
+// msgChainID returns the ID of the chain [msg] is bound to, if any
+// sendOp returns the op that determines how [msg] is prioritized. Traced
+// messages are prioritized like the messages they wrap.
+func sendOp(msg Msg) Op {
+	op := msg.Op()
+	if op != Traced {
+		return op
+	}
+	if traced, ok := msg.Get(TracedMsg).([]byte); ok && len(traced) > 0 {
+		return Op(traced[0])
+	}
+	return op
+}
+
+func msgChainID(msg Msg) (ids.ID, bool) {
+	chainIDBytes, ok := msg.Get(ChainID).([]byte)
+	if !ok {
+		return ids.ID{}, false
+	}
+	chainID, err := ids.ToID(chainIDBytes)
+	return chainID, err == nil
+}
+
 type peer struct {
 	net *network // network this peer is part of
 
@@ -56,10 +123,31 @@ type peer struct {
 	// lock to ensure that closing of the sender queue is handled safely
 	senderLock sync.Mutex
 
-	// queue of messages this connection is attempting to send the peer. Is
-	// closed when the connection is closed.
+	// queue of messages this connection is attempting to send the peer that
+	// aren't bound to a chain. Is closed when the connection is closed.
 	sender chan []byte
 
+	// chainQueues holds the messages this connection is attempting to send the
+	// peer on behalf of each chain. Each chain has its own flow control window,
+	// so that one chain's traffic doesn't delay another chain's messages.
+	// [senderLock] must be held when accessing [chainQueues], [chainOrder], or
+	// [nextChain].
+	chainQueues map[ids.ID]*chainQueue
+
+	// chainOrder is the order in which chain queues are serviced
+	chainOrder []ids.ID
+
+	// nextChain is the index in [chainOrder] of the next queue to service,
+	// by send class
+	nextChain [numSendClasses]int
+
+	// chainNotify is signalled when a message is added to a chain queue
+	chainNotify chan struct{}
+
+	// drops tracks the messages dropped because this peer's send queue was
+	// full. [senderLock] must be held when accessing [drops].
+	drops dropStats
+
 	// ip may or may not be set when the peer is first started. is only modified
 	// on the connection's reader routine.
 	ip utils.IPDesc
@@ -80,16 +168,68 @@ type peer struct {
 	// id should be set when the peer is first created.
 	id ids.ShortID
 
+	// previousID is the ID that this peer rotated its staking certificate
+	// away from, if any. It's recognized until [previousIDExpiry]. These are
+	// set at most once, with the [stateLock] held.
+	previousID       ids.ShortID
+	previousIDExpiry time.Time
+
 	// the connection object that is used to read/write messages from
 	conn net.Conn
 
 	// version that the peer reported during the handshake
 	versionStruct, versionStr utils.AtomicInterface
 
+	// capabilities that the peer advertised during the handshake
+	// Must only be accessed atomically
+	peerCapabilities uint32
+
+	// traces maps the requests received from this peer to their trace IDs, so
+	// that the responses can echo them
+	traces cache.LRU
+
 	// unix time of the last message sent and received respectively
 	// Must only be accessed atomically
 	lastSent, lastReceived int64
 
+	// number of bytes sent to and received from this peer, including the
+	// framing of the messages
+	// Must only be accessed atomically
+	bytesSent, bytesReceived uint64
+
+	// random ID that the peer chose for the current run of its node, as sent
+	// in its version message
+	// Must only be accessed atomically
+	sessionID uint32
+
+	// time at which the connection was established. Set before the peer is
+	// started.
+	connectedAt time.Time
+
+	// unix time, in nanoseconds, at which the unanswered ping was sent, or 0
+	// if every ping has been answered
+	// Must only be accessed atomically
+	pingSent int64
+
+	// number of consecutive pings that weren't answered before the next one
+	// was sent
+	// Must only be accessed atomically
+	missedPongs uint32
+
+	// round trip time, in nanoseconds, of the most recently answered ping
+	// Must only be accessed atomically
+	rtt int64
+
+	// true if the peer is running a version that the version policy
+	// deprecates
+	deprecatedVersion utils.AtomicBool
+
+	// estimated offset, in nanoseconds, of this peer's clock from ours. It's
+	// positive if the peer's clock is ahead of ours. Set from the peer's
+	// Version and refined whenever the peer answers a ping with a TimedPong.
+	// Must only be accessed atomically
+	clockOffset int64
+
 	tickerCloser chan struct{}
 
 	// ticker processes
@@ -102,7 +242,10 @@ func newPeer(net *network, conn net.Conn, ip utils.IPDesc) *peer {
 		net:          net,
 		conn:         conn,
 		ip:           ip,
+		chainQueues:  make(map[ids.ID]*chainQueue),
+		chainNotify:  make(chan struct{}, 1),
 		tickerCloser: make(chan struct{}),
+		traces:       cache.LRU{Size: traceCacheSize},
 	}
 	p.aliasTimer = timer.NewTimer(p.releaseExpiredAliases)
 
@@ -111,6 +254,7 @@ func newPeer(net *network, conn net.Conn, ip utils.IPDesc) *peer {
 
 // assume the [stateLock] is held
 func (p *peer) Start() {
+	p.connectedAt = p.net.clock.Time()
 	go p.ReadMessages()
 	go p.WriteMessages()
 }
@@ -134,6 +278,15 @@ func (p *peer) sendPings() {
 				return
 			}
 
+			if atomic.LoadInt64(&p.pingSent) != 0 {
+				missed := atomic.AddUint32(&p.missedPongs, 1)
+				if p.net.maxMissedPongs != 0 && missed >= p.net.maxMissedPongs {
+					p.net.log.Debug("disconnecting from %s after %d unanswered pings", p.id, missed)
+					p.Close()
+					return
+				}
+			}
+
 			p.Ping()
 		case <-p.tickerCloser:
 			return
@@ -194,49 +347,22 @@ func (p *peer) ReadMessages() {
 		return
 	}
 
-	pendingBuffer := wrappers.Packer{}
-	readBuffer := make([]byte, p.net.readBufferSize)
+	reader := frameReader{
+		r:              bufio.NewReaderSize(p.conn, int(p.net.readBufferSize)),
+		maxMessageSize: uint32(p.net.maxMessageSize),
+	}
 	for {
-		read, err := p.conn.Read(readBuffer)
+		msgBytes, read, err := reader.Read()
+		atomic.AddUint64(&p.bytesReceived, uint64(read))
 		if err != nil {
+			// Once a frame is malformed, the start of the next frame can't be
+			// found, so the connection must be terminated
 			p.net.log.Verbo("error on connection read to %s %s %s", p.id, p.getIP(), err)
 			return
 		}
 
-		pendingBuffer.Bytes = append(pendingBuffer.Bytes, readBuffer[:read]...)
-
-		msgBytes := pendingBuffer.UnpackBytes()
-		if pendingBuffer.Errored() {
-			// if reading the bytes errored, then we haven't read the full
-			// message yet
-			pendingBuffer.Offset = 0
-			pendingBuffer.Err = nil
-
-			if int64(len(pendingBuffer.Bytes)) > p.net.maxMessageSize+wrappers.IntLen {
-				// we have read more bytes than the max message size allows for,
-				// so we should terminate this connection
-
-				p.net.log.Verbo("error reading too many bytes on %s %s", p.id, err)
-				return
-			}
-
-			// we should try to read more bytes to finish the message
-			continue
-		}
-
-		// we read the full message bytes
-
-		// set the pending bytes to any extra bytes that were read
-		pendingBuffer.Bytes = pendingBuffer.Bytes[pendingBuffer.Offset:]
-		// set the offset back to the start of the next message
-		pendingBuffer.Offset = 0
-
-		if int64(len(msgBytes)) > p.net.maxMessageSize {
-			// if this message is longer than the max message length, then we
-			// should terminate this connection
-
-			p.net.log.Verbo("error reading too many bytes on %s %s", p.id, err)
-			return
+		if p.net.inboundBandwidth.wait(p, len(msgBytes)) {
+			p.net.throttledInbound.Inc()
 		}
 
 		p.net.log.Verbo("parsing new message from %s:\n%s",
@@ -261,8 +387,22 @@ func (p *peer) WriteMessages() {
 	defer p.Close()
 
 	p.Version()
+	if p.net.identityRotation != nil {
+		p.Rotation(p.net.identityRotation)
+	}
+	p.Capabilities()
+
+	writer := frameWriter{w: p.conn}
+	for {
+		msg, ok := p.nextMessage()
+		if !ok {
+			return
+		}
+
+		if p.net.outboundBandwidth.wait(p, len(msg)) {
+			p.net.throttledOutbound.Inc()
+		}
 
-	for msg := range p.sender {
 		p.net.log.Verbo("sending new message to %s:\n%s",
 			p.id,
 			formatting.DumpBytes{Bytes: msg})
@@ -270,27 +410,75 @@ func (p *peer) WriteMessages() {
 		atomic.AddInt64(&p.pendingBytes, -int64(len(msg)))
 		atomic.AddInt64(&p.net.pendingBytes, -int64(len(msg)))
 
-		msgb := [wrappers.IntLen]byte{}
-		binary.BigEndian.PutUint32(msgb[:], uint32(len(msg)))
-		for _, byteSlice := range [][]byte{msgb[:], msg} {
-			for len(byteSlice) > 0 {
-				written, err := p.conn.Write(byteSlice)
-				if err != nil {
-					p.net.log.Verbo("error writing to %s at %s due to: %s", p.id, p.getIP(), err)
-					return
-				}
-				p.tickerOnce.Do(p.StartTicker)
-				byteSlice = byteSlice[written:]
+		if atomic.LoadUint32(&p.peerCapabilities)&capabilityChecksums != 0 {
+			written, err := writer.EnableChecksums()
+			atomic.AddUint64(&p.bytesSent, uint64(written))
+			if err != nil {
+				p.net.log.Verbo("error writing to %s at %s due to: %s", p.id, p.getIP(), err)
+				return
 			}
 		}
+		written, err := writer.Write(msg)
+		atomic.AddUint64(&p.bytesSent, uint64(written))
+		if err != nil {
+			p.net.log.Verbo("error writing to %s at %s due to: %s", p.id, p.getIP(), err)
+			return
+		}
+		p.tickerOnce.Do(p.StartTicker)
 		now := p.net.clock.Time().Unix()
 		atomic.StoreInt64(&p.lastSent, now)
 		atomic.StoreInt64(&p.net.lastMsgSentTime, now)
 	}
 }
 
-// send assumes that the [stateLock] is not held.
+// nextMessage returns the next message to write to the peer. Messages that
+// aren't bound to a chain are prioritized. Chain messages are taken from each
+// chain's queue in round robin order. Returns false once the peer is closed.
+func (p *peer) nextMessage() ([]byte, bool) {
+	for {
+		select {
+		case msg, ok := <-p.sender:
+			return msg, ok
+		default:
+		}
+
+		if msg, ok := p.popChainMessage(); ok {
+			return msg, true
+		}
+
+		select {
+		case msg, ok := <-p.sender:
+			return msg, ok
+		case <-p.chainNotify:
+		}
+	}
+}
+
+// Send queues [msg] to be sent to this peer. Returns false if the message was
+// dropped.
+// assumes that the [stateLock] is not held.
 func (p *peer) Send(msg Msg) bool {
+	chainID, hasChainID := msgChainID(msg)
+	msgLen := len(msg.Bytes())
+
+	sent := p.send(msg)
+	if hasChainID {
+		if sent {
+			p.net.chains.sent(chainID, msgLen)
+		} else {
+			p.net.chains.dropped(chainID)
+		}
+	}
+	return sent
+}
+
+// send assumes that the [stateLock] is not held.
+func (p *peer) send(msg Msg) bool {
+	// The op of the uncompressed message determines how it's prioritized.
+	// Compression happens before the lock is grabbed as it may take a while.
+	op := sendOp(msg)
+	msg = p.compressedMsg(msg)
+
 	p.senderLock.Lock()
 	defer p.senderLock.Unlock()
 
@@ -301,14 +489,22 @@ func (p *peer) Send(msg Msg) bool {
 		return false
 	}
 
+	priority := opSendPriority(op)
+	msgBytes := msg.Bytes()
+	msgBytesLen := int64(len(msgBytes))
+
 	// is it possible to send?
 	if dropMsg := p.dropMessagePeer(); dropMsg {
 		p.net.log.Debug("dropping message to %s due to a send queue with too many bytes", p.id)
+		p.drops.Add(op, p.net.clock.Time())
+		return false
+	}
+	if priority == lowSendPriority &&
+		atomic.LoadInt64(&p.pendingBytes)+msgBytesLen > p.net.maxMessageSize/lowPriorityQueuePortion {
+		p.net.log.Debug("dropping %s message to %s to leave room in its send queue", op, p.id)
+		p.drops.Add(op, p.net.clock.Time())
 		return false
 	}
-
-	msgBytes := msg.Bytes()
-	msgBytesLen := int64(len(msgBytes))
 
 	// lets assume send will be successful, we add to the network pending bytes
 	// if we determine that we are being a bit restrictive, we could increase the global bandwidth?
@@ -319,6 +515,28 @@ func (p *peer) Send(msg Msg) bool {
 		// we never sent the message, remove from pending totals
 		atomic.AddInt64(&p.net.pendingBytes, -msgBytesLen)
 		p.net.log.Debug("dropping message to %s due to a send queue with too many bytes", p.id)
+		p.drops.Add(op, p.net.clock.Time())
+		return false
+	}
+
+	if chainID, ok := msgChainID(msg); ok {
+		if !p.pushChainMessage(chainID, queuedMsg{op: op, bytes: msgBytes}) {
+			// we never sent the message, remove from pending totals
+			atomic.AddInt64(&p.net.pendingBytes, -msgBytesLen)
+			p.net.log.Debug("dropping %s message to %s due to a full send window for chain %s", op, p.id, chainID)
+			p.drops.Add(op, p.net.clock.Time())
+			return false
+		}
+		atomic.AddInt64(&p.pendingBytes, msgBytesLen)
+		return true
+	}
+
+	// Leave room in the send queue for messages with a higher priority
+	if priority == lowSendPriority && len(p.sender) >= cap(p.sender)/lowPriorityQueuePortion {
+		// we never sent the message, remove from pending totals
+		atomic.AddInt64(&p.net.pendingBytes, -msgBytesLen)
+		p.net.log.Debug("dropping %s message to %s to leave room in its send queue", op, p.id)
+		p.drops.Add(op, p.net.clock.Time())
 		return false
 	}
 
@@ -330,10 +548,121 @@ func (p *peer) Send(msg Msg) bool {
 		// we never sent the message, remove from pending totals
 		atomic.AddInt64(&p.net.pendingBytes, -msgBytesLen)
 		p.net.log.Debug("dropping message to %s due to a full send queue", p.id)
+		p.drops.Add(op, p.net.clock.Time())
 		return false
 	}
 }
 
+// pushChainMessage adds [msg] to the send queue of [chainID]. Returns false if
+// the chain's send window is full. Messages of each send class may only fill
+// their portion of the window. Once they have, the class's drop policy
+// determines whether [msg] or the oldest messages of the class are dropped.
+// assumes the [senderLock] is held
+func (p *peer) pushChainMessage(chainID ids.ID, msg queuedMsg) bool {
+	q, exists := p.chainQueues[chainID]
+	if !exists {
+		q = &chainQueue{}
+		p.chainQueues[chainID] = q
+		p.chainOrder = append(p.chainOrder, chainID)
+	}
+
+	class := opSendClass(msg.op)
+	config := p.net.sendQueueConfig.class(class)
+	cq := &q.classes[class]
+
+	msgBytesLen := int64(len(msg.bytes))
+	windowMsgs := int(p.net.sendQueueSize)
+	windowBytes := p.net.maxNetworkPendingSendBytes / 20
+	maxMsgs := int(float64(windowMsgs) * config.MaxPortion)
+	if maxMsgs < 1 {
+		maxMsgs = 1
+	}
+	maxBytes := int64(float64(windowBytes) * config.MaxPortion)
+	for q.numMsgs >= windowMsgs ||
+		len(cq.msgs) >= maxMsgs ||
+		(len(cq.msgs) > 0 && cq.pendingBytes+msgBytesLen > maxBytes) ||
+		(q.numMsgs > 0 && q.pendingBytes+msgBytesLen > windowBytes) {
+		if config.DropPolicy != DropOldest || len(cq.msgs) == 0 {
+			return false
+		}
+
+		dropped := cq.pop()
+		droppedLen := int64(len(dropped.bytes))
+		q.numMsgs--
+		q.pendingBytes -= droppedLen
+		atomic.AddInt64(&p.pendingBytes, -droppedLen)
+		atomic.AddInt64(&p.net.pendingBytes, -droppedLen)
+		p.net.log.Debug("dropping queued %s message to %s to make room for a newer one", dropped.op, p.id)
+		p.drops.Add(dropped.op, p.net.clock.Time())
+		p.net.chains.dropped(chainID)
+	}
+	cq.push(msg)
+	q.numMsgs++
+	q.pendingBytes += msgBytesLen
+
+	select {
+	case p.chainNotify <- struct{}{}:
+	default:
+	}
+	return true
+}
+
+// popChainMessage removes and returns the next message from the chain send
+// queues. Messages are taken from the first send class with queued messages,
+// servicing the chains in round robin order. Returns false if there are no
+// queued chain messages.
+// assumes the [senderLock] is not held
+func (p *peer) popChainMessage() ([]byte, bool) {
+	p.senderLock.Lock()
+	defer p.senderLock.Unlock()
+
+	numChains := len(p.chainOrder)
+	for class := sendClass(0); class < numSendClasses; class++ {
+		for i := 0; i < numChains; i++ {
+			index := (p.nextChain[class] + i) % numChains
+			q := p.chainQueues[p.chainOrder[index]]
+			cq := &q.classes[class]
+			if len(cq.msgs) == 0 {
+				continue
+			}
+
+			msg := cq.pop()
+			q.numMsgs--
+			q.pendingBytes -= int64(len(msg.bytes))
+			p.nextChain[class] = (index + 1) % numChains
+			return msg.bytes, true
+		}
+	}
+	return nil, false
+}
+
+// chainPendingBytes returns the number of bytes queued to be sent to this peer
+// on behalf of each chain with a non-empty send queue.
+// assumes the [senderLock] is not held
+func (p *peer) chainPendingBytes() map[string]int64 {
+	p.senderLock.Lock()
+	defer p.senderLock.Unlock()
+
+	pending := make(map[string]int64)
+	for chainID, q := range p.chainQueues {
+		if q.pendingBytes > 0 {
+			pending[chainID.String()] = q.pendingBytes
+		}
+	}
+	return pending
+}
+
+// dropStats returns the number of messages of each type dropped because this
+// peer's send queue was full, and whether messages to this peer are being
+// dropped chronically.
+// assumes the [senderLock] is not held
+func (p *peer) dropStats() (map[string]uint64, bool) {
+	p.senderLock.Lock()
+	defer p.senderLock.Unlock()
+
+	return p.drops.Counts(), p.drops.Chronic(p.net.clock.Time())
+}
+
 // assumes the [stateLock] is not held
 func (p *peer) handle(msg Msg) {
 	now := p.net.clock.Time()
@@ -354,6 +683,9 @@ func (p *peer) handle(msg Msg) {
 	}
 	msgMetrics.numReceived.Inc()
 	msgMetrics.receivedBytes.Add(float64(len(msg.Bytes())))
+	if chainID, ok := msgChainID(msg); ok {
+		p.net.chains.received(chainID, len(msg.Bytes()))
+	}
 
 	switch op {
 	case Version:
@@ -368,12 +700,21 @@ func (p *peer) handle(msg Msg) {
 	case Pong:
 		p.pong(msg)
 		return
+	case TimedPong:
+		p.timedPong(msg)
+		return
 	case GetPeerList:
 		p.getPeerList(msg)
 		return
 	case PeerList:
 		p.peerList(msg)
 		return
+	case Rotation:
+		p.rotation(msg)
+		return
+	case Capabilities:
+		p.capabilities(msg)
+		return
 	}
 	if !p.connected.GetValue() {
 		p.net.log.Debug("dropping message from %s because the connection hasn't been established yet", p.id)
@@ -417,6 +758,16 @@ func (p *peer) handle(msg Msg) {
 		p.pullQuery(msg)
 	case Chits:
 		p.chits(msg)
+	case JustifiedChits:
+		p.justifiedChits(msg)
+	case GossipTxs:
+		p.gossipTxs(msg)
+	case Traced:
+		p.traced(msg)
+	case HintedPut:
+		p.hintedPut(msg)
+	case Compressed:
+		p.compressed(msg)
 	default:
 		p.net.log.Debug("dropping an unknown message from %s with op %s", p.id, op.String())
 	}
@@ -513,6 +864,11 @@ func (p *peer) GetPeerList() {
 // assumes the stateLock is not held
 func (p *peer) SendPeerList() {
 	ips := p.net.validatorIPs()
+	if p.net.connectionConfig.AdvertiseIP {
+		if ip := p.net.ip.IP(); !ip.IsZero() {
+			ips = append(ips, ip)
+		}
+	}
 	p.PeerList(ips)
 }
 
@@ -533,15 +889,37 @@ func (p *peer) PeerList(peers []utils.IPDesc) {
 	}
 }
 
+// assumes the [stateLock] is not held
+func (p *peer) Rotation(rotation *IdentityRotation) {
+	msg, err := p.net.b.Rotation(rotation.PreviousCert, rotation.Signature, rotation.Expiry)
+	if err != nil {
+		p.net.log.Warn("failed to send Rotation message due to %s", err)
+		return
+	}
+	if p.Send(msg) {
+		p.net.rotation.numSent.Inc()
+		p.net.rotation.sentBytes.Add(float64(len(msg.Bytes())))
+		p.net.sendFailRateCalculator.Observe(0, p.net.clock.Time())
+	} else {
+		p.net.rotation.numFailed.Inc()
+		p.net.sendFailRateCalculator.Observe(1, p.net.clock.Time())
+	}
+}
+
 // assumes the [stateLock] is not held
 func (p *peer) Ping() {
 	msg, err := p.net.b.Ping()
 	p.net.log.AssertNoError(err)
+
+	// The send time is recorded before sending so that the pong can't be
+	// handled first
+	atomic.StoreInt64(&p.pingSent, p.net.clock.Time().UnixNano())
 	if p.Send(msg) {
 		p.net.ping.numSent.Inc()
 		p.net.ping.sentBytes.Add(float64(len(msg.Bytes())))
 		p.net.sendFailRateCalculator.Observe(0, p.net.clock.Time())
 	} else {
+		atomic.StoreInt64(&p.pingSent, 0)
 		p.net.ping.numFailed.Inc()
 		p.net.sendFailRateCalculator.Observe(1, p.net.clock.Time())
 	}
@@ -561,6 +939,20 @@ func (p *peer) Pong() {
 	}
 }
 
+// assumes the [stateLock] is not held
+func (p *peer) TimedPong() {
+	msg, err := p.net.b.TimedPong(uint64(p.net.clock.Time().UnixNano()))
+	p.net.log.AssertNoError(err)
+	if p.Send(msg) {
+		p.net.timedPong.numSent.Inc()
+		p.net.timedPong.sentBytes.Add(float64(len(msg.Bytes())))
+		p.net.sendFailRateCalculator.Observe(0, p.net.clock.Time())
+	} else {
+		p.net.timedPong.numFailed.Inc()
+		p.net.sendFailRateCalculator.Observe(1, p.net.clock.Time())
+	}
+}
+
 // assumes the [stateLock] is not held
 func (p *peer) getVersion(msg Msg) {
 	p.Version()
@@ -582,15 +974,21 @@ func (p *peer) version(msg Msg) {
 		return
 	}
 
-	if nodeID := msg.Get(NodeID).(uint32); nodeID == p.net.nodeID {
+	nodeID := msg.Get(NodeID).(uint32)
+	if nodeID == p.net.nodeID {
 		p.net.log.Debug("peer's node ID matches our nodeID")
 
 		p.discardMyIP()
 		return
 	}
+	atomic.StoreUint32(&p.sessionID, nodeID)
 
 	myTime := float64(p.net.clock.Unix())
-	if peerTime := float64(msg.Get(MyTime).(uint64)); math.Abs(peerTime-myTime) > p.net.maxClockDifference.Seconds() {
+	peerTime := float64(msg.Get(MyTime).(uint64))
+	// The version only has second precision, so this is a rough estimate until
+	// the peer answers a ping with a TimedPong
+	atomic.StoreInt64(&p.clockOffset, int64(peerTime-myTime)*int64(time.Second))
+	if math.Abs(peerTime-myTime) > p.net.maxClockDifference.Seconds() {
 		if p.net.beacons.Contains(p.id) {
 			p.net.log.Warn("beacon %s has a clock that is too far out of sync with mine. Peer's = %d, Ours = %d (seconds)",
 				p.id,
@@ -628,8 +1026,15 @@ func (p *peer) version(msg Msg) {
 		}
 	}
 
-	if err := p.net.msgVersion.Compatible(peerVersion); err != nil {
+	err = p.net.msgVersion.Compatible(peerVersion)
+	if err == nil {
+		var deprecated bool
+		deprecated, err = p.net.connectionConfig.VersionPolicy.Check(peerVersion)
+		p.deprecatedVersion.SetValue(deprecated)
+	}
+	if err != nil {
 		p.net.log.Debug("peer version not compatible due to %s", err)
+		p.net.rejectedVersions.Inc()
 
 		if !p.net.beacons.Contains(p.id) {
 			p.discardIP()
@@ -686,19 +1091,80 @@ func (p *peer) peerList(msg Msg) {
 			!ip.IsZero() &&
 			(p.net.allowPrivateIPs || !ip.IsPrivate()) {
 			// TODO: only try to connect once
-			p.net.track(ip)
+			if err := p.net.track(ip); err != nil {
+				p.net.log.Verbo("not connecting to gossiped peer: %s", err)
+			}
 		}
 		p.net.stateLock.Unlock()
 	}
 }
 
+// assumes the [stateLock] is not held
+func (p *peer) rotation(msg Msg) {
+	rotation := &IdentityRotation{
+		PreviousCert: msg.Get(Certificate).([]byte),
+		Signature:    msg.Get(Signature).([]byte),
+		Expiry:       msg.Get(Expiry).(uint64),
+	}
+	previousID, err := rotation.Verify(p.id, p.net.clock.Time())
+	if err != nil {
+		p.net.log.Debug("dropping identity rotation from %s due to %s", p.id, err)
+		return
+	}
+	p.net.addPreviousID(p, previousID, rotation.ExpiryTime())
+}
+
+// validatorID returns the ID that messages from this peer are attributed to.
+// While this peer is rotating its staking certificate, that's its previous ID
+// until its new ID is a validator.
+func (p *peer) validatorID() ids.ShortID {
+	if p.previousID == ids.ShortEmpty ||
+		!p.net.clock.Time().Before(p.previousIDExpiry) ||
+		p.net.vdrs.Contains(p.id) {
+		return p.id
+	}
+	return p.previousID
+}
+
 // assumes the [stateLock] is not held
 func (p *peer) ping(_ Msg) {
+	if atomic.LoadUint32(&p.peerCapabilities)&capabilityTimedPong != 0 {
+		p.TimedPong()
+		return
+	}
 	p.Pong()
 }
 
 // assumes the [stateLock] is not held
-func (p *peer) pong(_ Msg) {}
+func (p *peer) pong(_ Msg) {
+	p.answerPing()
+}
+
+// assumes the [stateLock] is not held
+func (p *peer) timedPong(msg Msg) {
+	sent, answered, ok := p.answerPing()
+	if !ok {
+		return
+	}
+	// Assume the pong was sent halfway through the round trip
+	peerTime := int64(msg.Get(Timestamp).(uint64))
+	atomic.StoreInt64(&p.clockOffset, peerTime-(sent+(answered-sent)/2))
+}
+
+// answerPing records that the outstanding ping was answered. Returns the time,
+// in unix nanoseconds, that the ping was sent at and that it was answered at.
+// Returns false if there's no outstanding ping.
+func (p *peer) answerPing() (int64, int64, bool) {
+	sent := atomic.SwapInt64(&p.pingSent, 0)
+	if sent == 0 {
+		// This pong doesn't answer an outstanding ping
+		return 0, 0, false
+	}
+	answered := p.net.clock.Time().UnixNano()
+	atomic.StoreInt64(&p.rtt, answered-sent)
+	atomic.StoreUint32(&p.missedPongs, 0)
+	return sent, answered, true
+}
 
 // assumes the [stateLock] is not held
 func (p *peer) getAcceptedFrontier(msg Msg) {
@@ -707,7 +1173,7 @@ func (p *peer) getAcceptedFrontier(msg Msg) {
 	requestID := msg.Get(RequestID).(uint32)
 	deadline := p.net.clock.Time().Add(time.Duration(msg.Get(Deadline).(uint64)))
 
-	p.net.router.GetAcceptedFrontier(p.id, chainID, requestID, deadline)
+	p.net.router.GetAcceptedFrontier(p.validatorID(), chainID, requestID, deadline)
 }
 
 // assumes the [stateLock] is not held
@@ -733,7 +1199,7 @@ func (p *peer) acceptedFrontier(msg Msg) {
 		containerIDsSet.Add(containerID)
 	}
 
-	p.net.router.AcceptedFrontier(p.id, chainID, requestID, containerIDs)
+	p.net.router.AcceptedFrontier(p.validatorID(), chainID, requestID, containerIDs)
 }
 
 // assumes the [stateLock] is not held
@@ -760,7 +1226,7 @@ func (p *peer) getAccepted(msg Msg) {
 		containerIDsSet.Add(containerID)
 	}
 
-	p.net.router.GetAccepted(p.id, chainID, requestID, deadline, containerIDs)
+	p.net.router.GetAccepted(p.validatorID(), chainID, requestID, deadline, containerIDs)
 }
 
 // assumes the [stateLock] is not held
@@ -786,7 +1252,7 @@ func (p *peer) accepted(msg Msg) {
 		containerIDsSet.Add(containerID)
 	}
 
-	p.net.router.Accepted(p.id, chainID, requestID, containerIDs)
+	p.net.router.Accepted(p.validatorID(), chainID, requestID, containerIDs)
 }
 
 // assumes the [stateLock] is not held
@@ -798,7 +1264,7 @@ func (p *peer) get(msg Msg) {
 	containerID, err := ids.ToID(msg.Get(ContainerID).([]byte))
 	p.net.log.AssertNoError(err)
 
-	p.net.router.Get(p.id, chainID, requestID, deadline, containerID)
+	p.net.router.Get(p.validatorID(), chainID, requestID, deadline, containerID)
 }
 
 func (p *peer) getAncestors(msg Msg) {
@@ -809,7 +1275,7 @@ func (p *peer) getAncestors(msg Msg) {
 	containerID, err := ids.ToID(msg.Get(ContainerID).([]byte))
 	p.net.log.AssertNoError(err)
 
-	p.net.router.GetAncestors(p.id, chainID, requestID, deadline, containerID)
+	p.net.router.GetAncestors(p.validatorID(), chainID, requestID, deadline, containerID)
 }
 
 // assumes the [stateLock] is not held
@@ -821,7 +1287,29 @@ func (p *peer) put(msg Msg) {
 	p.net.log.AssertNoError(err)
 	container := msg.Get(ContainerBytes).([]byte)
 
-	p.net.router.Put(p.id, chainID, requestID, containerID, container)
+	if requestID == constants.GossipMsgRequestID {
+		p.net.metrics.gossipedPuts.Inc()
+		if p.net.gossipFilter.Duplicate(chainID, container, p.net.clock.Time()) {
+			p.net.metrics.duplicateGossipedPuts.Inc()
+			p.net.log.Verbo("dropping duplicate gossiped container %s from %s", containerID, p.id)
+			return
+		}
+	}
+
+	p.net.router.Put(p.validatorID(), chainID, requestID, containerID, container)
+}
+
+// assumes the [stateLock] is not held
+func (p *peer) hintedPut(msg Msg) {
+	chainID, err := ids.ToID(msg.Get(ChainID).([]byte))
+	p.net.log.AssertNoError(err)
+	requestID := msg.Get(RequestID).(uint32)
+	containerID, err := ids.ToID(msg.Get(ContainerID).([]byte))
+	p.net.log.AssertNoError(err)
+	container := msg.Get(ContainerBytes).([]byte)
+	hints := msg.Get(MultiContainerBytes).([][]byte)
+
+	p.net.router.HintedPut(p.validatorID(), chainID, requestID, containerID, container, hints)
 }
 
 // assumes the [stateLock] is not held
@@ -831,7 +1319,7 @@ func (p *peer) multiPut(msg Msg) {
 	requestID := msg.Get(RequestID).(uint32)
 	containers := msg.Get(MultiContainerBytes).([][]byte)
 
-	p.net.router.MultiPut(p.id, chainID, requestID, containers)
+	p.net.router.MultiPut(p.validatorID(), chainID, requestID, containers)
 }
 
 // assumes the [stateLock] is not held
@@ -844,7 +1332,7 @@ func (p *peer) pushQuery(msg Msg) {
 	p.net.log.AssertNoError(err)
 	container := msg.Get(ContainerBytes).([]byte)
 
-	p.net.router.PushQuery(p.id, chainID, requestID, deadline, containerID, container)
+	p.net.router.PushQuery(p.validatorID(), chainID, requestID, deadline, containerID, container)
 }
 
 // assumes the [stateLock] is not held
@@ -856,7 +1344,7 @@ func (p *peer) pullQuery(msg Msg) {
 	containerID, err := ids.ToID(msg.Get(ContainerID).([]byte))
 	p.net.log.AssertNoError(err)
 
-	p.net.router.PullQuery(p.id, chainID, requestID, deadline, containerID)
+	p.net.router.PullQuery(p.validatorID(), chainID, requestID, deadline, containerID)
 }
 
 // assumes the [stateLock] is not held
@@ -882,7 +1370,66 @@ func (p *peer) chits(msg Msg) {
 		containerIDsSet.Add(containerID)
 	}
 
-	p.net.router.Chits(p.id, chainID, requestID, containerIDs)
+	p.net.router.Chits(p.validatorID(), chainID, requestID, containerIDs)
+}
+
+// assumes the [stateLock] is not held
+func (p *peer) justifiedChits(msg Msg) {
+	chainID, err := ids.ToID(msg.Get(ChainID).([]byte))
+	p.net.log.AssertNoError(err)
+	requestID := msg.Get(RequestID).(uint32)
+
+	containerIDsBytes := msg.Get(ContainerIDs).([][]byte)
+	heights := msg.Get(ContainerHeights).([]uint64)
+	if len(heights) != len(containerIDsBytes) {
+		p.net.log.Debug("message contains %d container IDs but %d heights", len(containerIDsBytes), len(heights))
+		return
+	}
+
+	containerIDs := make([]ids.ID, len(containerIDsBytes))
+	containerIDsSet := ids.Set{} // To prevent duplicates
+	for i, containerIDBytes := range containerIDsBytes {
+		containerID, err := ids.ToID(containerIDBytes)
+		if err != nil {
+			p.net.log.Debug("error parsing ContainerID 0x%x: %s", containerIDBytes, err)
+			return
+		}
+		if containerIDsSet.Contains(containerID) {
+			p.net.log.Debug("message contains duplicate of container ID %s", containerID)
+			return
+		}
+		containerIDs[i] = containerID
+		containerIDsSet.Add(containerID)
+	}
+
+	p.net.router.JustifiedChits(p.validatorID(), chainID, requestID, containerIDs, heights)
+}
+
+// assumes the [stateLock] is not held
+func (p *peer) gossipTxs(msg Msg) {
+	chainID, err := ids.ToID(msg.Get(ChainID).([]byte))
+	p.net.log.AssertNoError(err)
+	containerID, err := ids.ToID(msg.Get(ContainerID).([]byte))
+	p.net.log.AssertNoError(err)
+
+	txIDsBytes := msg.Get(ContainerIDs).([][]byte)
+	txIDs := make([]ids.ID, len(txIDsBytes))
+	txIDsSet := ids.Set{} // To prevent duplicates
+	for i, txIDBytes := range txIDsBytes {
+		txID, err := ids.ToID(txIDBytes)
+		if err != nil {
+			p.net.log.Debug("error parsing tx ID 0x%x: %s", txIDBytes, err)
+			return
+		}
+		if txIDsSet.Contains(txID) {
+			p.net.log.Debug("message contains duplicate of tx ID %s", txID)
+			return
+		}
+		txIDs[i] = txID
+		txIDsSet.Add(txID)
+	}
+
+	p.net.router.GossipTxs(p.validatorID(), chainID, containerID, txIDs)
 }
 
 // assumes the [stateLock] is held
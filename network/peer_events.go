@@ -0,0 +1,103 @@
+// (c) 2019-2020, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package network
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+
+	"github.com/ava-labs/avalanchego/ids"
+	"github.com/ava-labs/avalanchego/utils"
+)
+
+var (
+	errDuplicatePeerHandler = errors.New("duplicate peer handler")
+	errUnknownPeerHandler   = errors.New("unknown peer handler")
+)
+
+// PeerHandler observes the lifecycle of the network's peers. Its methods are
+// called while the network's state is locked, so they must return quickly and
+// must not call into the network. [ip] is zero if the peer's IP isn't known.
+type PeerHandler interface {
+	// Upgraded is called once the connection with [nodeID] has been
+	// authenticated, before the handshake with it has finished
+	Upgraded(nodeID ids.ShortID, ip utils.IPDesc)
+
+	// Connected is called once the handshake with [nodeID] has finished
+	Connected(nodeID ids.ShortID, ip utils.IPDesc)
+
+	// Disconnected is called once the network disconnects from [nodeID], if
+	// Connected was called for it
+	Disconnected(nodeID ids.ShortID, ip utils.IPDesc)
+}
+
+// peerHandlers dispatches the lifecycle events of peers to the registered
+// handlers
+type peerHandlers struct {
+	lock     sync.RWMutex
+	handlers map[string]PeerHandler
+}
+
+func (h *peerHandlers) register(name string, handler PeerHandler) error {
+	h.lock.Lock()
+	defer h.lock.Unlock()
+
+	if _, exists := h.handlers[name]; exists {
+		return fmt.Errorf("%w: %s", errDuplicatePeerHandler, name)
+	}
+	if h.handlers == nil {
+		h.handlers = make(map[string]PeerHandler)
+	}
+	h.handlers[name] = handler
+	return nil
+}
+
+func (h *peerHandlers) deregister(name string) error {
+	h.lock.Lock()
+	defer h.lock.Unlock()
+
+	if _, exists := h.handlers[name]; !exists {
+		return fmt.Errorf("%w: %s", errUnknownPeerHandler, name)
+	}
+	delete(h.handlers, name)
+	return nil
+}
+
+func (h *peerHandlers) upgraded(nodeID ids.ShortID, ip utils.IPDesc) {
+	h.lock.RLock()
+	defer h.lock.RUnlock()
+
+	for _, handler := range h.handlers {
+		handler.Upgraded(nodeID, ip)
+	}
+}
+
+func (h *peerHandlers) connected(nodeID ids.ShortID, ip utils.IPDesc) {
+	h.lock.RLock()
+	defer h.lock.RUnlock()
+
+	for _, handler := range h.handlers {
+		handler.Connected(nodeID, ip)
+	}
+}
+
+func (h *peerHandlers) disconnected(nodeID ids.ShortID, ip utils.IPDesc) {
+	h.lock.RLock()
+	defer h.lock.RUnlock()
+
+	for _, handler := range h.handlers {
+		handler.Disconnected(nodeID, ip)
+	}
+}
+
+// RegisterPeerHandler implements the Network interface
+func (n *network) RegisterPeerHandler(name string, handler PeerHandler) error {
+	return n.peerHandlers.register(name, handler)
+}
+
+// DeregisterPeerHandler implements the Network interface
+func (n *network) DeregisterPeerHandler(name string) error {
+	return n.peerHandlers.deregister(name)
+}
@@ -0,0 +1,133 @@
+// (c) 2019-2020, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package network
+
+import (
+	"errors"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/ava-labs/avalanchego/database/memdb"
+	"github.com/ava-labs/avalanchego/ids"
+	"github.com/ava-labs/avalanchego/network/networktest"
+	"github.com/ava-labs/avalanchego/snow/networking/benchlist"
+	"github.com/ava-labs/avalanchego/snow/validators"
+	"github.com/ava-labs/avalanchego/utils"
+	"github.com/ava-labs/avalanchego/utils/hashing"
+	"github.com/ava-labs/avalanchego/utils/logging"
+	"github.com/ava-labs/avalanchego/version"
+)
+
+type peerEvent struct {
+	name   string
+	nodeID ids.ShortID
+}
+
+// testPeerHandler sends the events it observes on [events]
+type testPeerHandler struct{ events chan peerEvent }
+
+func (h *testPeerHandler) Upgraded(nodeID ids.ShortID, _ utils.IPDesc) {
+	h.events <- peerEvent{name: "upgraded", nodeID: nodeID}
+}
+
+func (h *testPeerHandler) Connected(nodeID ids.ShortID, _ utils.IPDesc) {
+	h.events <- peerEvent{name: "connected", nodeID: nodeID}
+}
+
+func (h *testPeerHandler) Disconnected(nodeID ids.ShortID, _ utils.IPDesc) {
+	h.events <- peerEvent{name: "disconnected", nodeID: nodeID}
+}
+
+func TestPeerHandlers(t *testing.T) {
+	ip0 := utils.NewDynamicIPDesc(net.IPv6loopback, 0)
+	id0 := ids.ShortID(hashing.ComputeHash160Array([]byte(ip0.IP().String())))
+	ip1 := utils.NewDynamicIPDesc(net.IPv6loopback, 1)
+	id1 := ids.ShortID(hashing.ComputeHash160Array([]byte(ip1.IP().String())))
+
+	listener0 := networktest.NewListener(&net.TCPAddr{IP: net.IPv6loopback, Port: 0})
+	caller0 := networktest.NewDialer(&net.TCPAddr{IP: net.IPv6loopback, Port: 0})
+	listener1 := networktest.NewListener(&net.TCPAddr{IP: net.IPv6loopback, Port: 1})
+	caller1 := networktest.NewDialer(&net.TCPAddr{IP: net.IPv6loopback, Port: 1})
+	caller0.Route(ip1.IP(), listener1)
+	caller1.Route(ip0.IP(), listener0)
+
+	vdrs := validators.NewSet()
+	newNetwork := func(id ids.ShortID, ip utils.DynamicIPDesc, listener net.Listener, dialer Dialer) Network {
+		return NewDefaultNetwork(
+			prometheus.NewRegistry(),
+			logging.NoLog{},
+			id,
+			ip,
+			0,
+			version.NewDefaultVersion("app", 0, 1, 0),
+			version.NewDefaultParser(),
+			[]net.Listener{listener},
+			dialer,
+			NewIPUpgrader(),
+			NewIPUpgrader(),
+			vdrs,
+			vdrs,
+			&testHandler{},
+			time.Duration(0),
+			0,
+			nil,
+			false,
+			0,
+			0,
+			time.Now(),
+			defaultSendQueueSize,
+			DefaultSendQueueConfig,
+			BandwidthConfig{},
+			DefaultGossipConfig,
+			DefaultConnectionConfig,
+			HealthConfig{},
+			benchlist.NewManager(&benchlist.Config{}),
+			memdb.New(),
+			defaultAliasTimeout,
+			nil,
+		)
+	}
+	net0 := newNetwork(id0, ip0, listener0, caller0)
+	net1 := newNetwork(id1, ip1, listener1, caller1)
+
+	handler := &testPeerHandler{events: make(chan peerEvent, 16)}
+	assert.NoError(t, net0.RegisterPeerHandler("test", handler))
+	err := net0.RegisterPeerHandler("test", handler)
+	assert.True(t, errors.Is(err, errDuplicatePeerHandler))
+
+	go func() {
+		assert.Error(t, net0.Dispatch())
+	}()
+	go func() {
+		assert.Error(t, net1.Dispatch())
+	}()
+
+	assert.NoError(t, net0.Track(ip1.IP()))
+
+	next := func() peerEvent {
+		select {
+		case e := <-handler.events:
+			return e
+		case <-time.After(5 * time.Second):
+			t.Fatal("timed out")
+			return peerEvent{}
+		}
+	}
+	assert.Equal(t, peerEvent{name: "upgraded", nodeID: id1}, next())
+	assert.Equal(t, peerEvent{name: "connected", nodeID: id1}, next())
+
+	assert.NoError(t, net0.Close())
+	assert.Equal(t, peerEvent{name: "disconnected", nodeID: id1}, next())
+
+	assert.NoError(t, net0.DeregisterPeerHandler("test"))
+	err = net0.DeregisterPeerHandler("test")
+	assert.True(t, errors.Is(err, errUnknownPeerHandler))
+
+	assert.NoError(t, net1.Close())
+}
@@ -18,4 +18,33 @@ type PeerID struct {
 	LastSent     time.Time `json:"lastSent"`
 	LastReceived time.Time `json:"lastReceived"`
 	Benched      []ids.ID  `json:"benched"`
+
+	// Random ID that the peer chose for the current run of its node
+	SessionID   uint32    `json:"sessionID"`
+	ConnectedAt time.Time `json:"connectedAt"`
+	// Number of bytes sent to, and received from, this peer on this connection
+	BytesSent     uint64 `json:"bytesSent"`
+	BytesReceived uint64 `json:"bytesReceived"`
+
+	// Round trip time of the most recently answered ping, or 0 if no ping
+	// has been answered yet
+	RTT time.Duration `json:"rtt"`
+	// Number of consecutive pings that this peer hasn't answered
+	MissedPongs uint32 `json:"missedPongs"`
+	// Estimated offset of this peer's clock from ours. Positive if the peer's
+	// clock is ahead of ours.
+	ClockOffset time.Duration `json:"clockOffset"`
+	// True if this peer is running a version that the version policy
+	// deprecates
+	DeprecatedVersion bool `json:"deprecatedVersion"`
+
+	// Chain ID --> number of bytes queued to be sent to this peer
+	PendingChainBytes map[string]int64 `json:"pendingChainBytes,omitempty"`
+
+	// Message type --> number of messages dropped because the send queue to
+	// this peer was full
+	DroppedMessages map[string]uint64 `json:"droppedMessages,omitempty"`
+	// True if messages to this peer have been dropped continuously for
+	// several minutes
+	ChronicDrops bool `json:"chronicDrops"`
 }
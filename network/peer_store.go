@@ -0,0 +1,186 @@
+// (c) 2019-2020, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package network
+
+import (
+	"sort"
+	"time"
+
+	"github.com/ava-labs/avalanchego/database"
+	"github.com/ava-labs/avalanchego/database/prefixdb"
+	"github.com/ava-labs/avalanchego/ids"
+	"github.com/ava-labs/avalanchego/utils"
+	"github.com/ava-labs/avalanchego/utils/wrappers"
+)
+
+const (
+	// Stored peers that haven't been seen for this long are forgotten
+	peerStoreExpiry = 14 * 24 * time.Hour
+
+	// At most this many peers are stored. When there are more, the peers with
+	// the lowest uptime scores are forgotten.
+	maxStoredPeers = 1000
+
+	// IP, node ID, first seen, last seen and connected duration
+	storedPeerLen = 16 + wrappers.ShortLen + 20 + 3*wrappers.LongLen
+)
+
+var peerStorePrefix = []byte("peers")
+
+// storedPeer is a peer the network has connected to, as remembered across
+// restarts
+type storedPeer struct {
+	IP     utils.IPDesc
+	NodeID ids.ShortID
+
+	// First and last time the network was connected to the peer
+	FirstSeen, LastSeen time.Time
+
+	// Total time the network has been connected to the peer
+	Connected time.Duration
+}
+
+// UptimeScore returns the portion of the time since the peer was first seen
+// that the network has been connected to it
+func (s *storedPeer) UptimeScore(now time.Time) float64 {
+	known := now.Sub(s.FirstSeen)
+	if known <= 0 {
+		return 1
+	}
+	score := float64(s.Connected) / float64(known)
+	if score > 1 {
+		return 1
+	}
+	return score
+}
+
+func (s *storedPeer) Bytes() []byte {
+	p := wrappers.Packer{Bytes: make([]byte, storedPeerLen)}
+	p.PackIP(s.IP)
+	p.PackFixedBytes(s.NodeID[:])
+	p.PackLong(uint64(s.FirstSeen.UnixNano()))
+	p.PackLong(uint64(s.LastSeen.UnixNano()))
+	p.PackLong(uint64(s.Connected))
+	return p.Bytes
+}
+
+func parseStoredPeer(b []byte) (*storedPeer, error) {
+	p := wrappers.Packer{Bytes: b}
+	s := &storedPeer{IP: p.UnpackIP()}
+	copy(s.NodeID[:], p.UnpackFixedBytes(len(s.NodeID)))
+	s.FirstSeen = time.Unix(0, int64(p.UnpackLong()))
+	s.LastSeen = time.Unix(0, int64(p.UnpackLong()))
+	s.Connected = time.Duration(p.UnpackLong())
+	return s, p.Err
+}
+
+// peerStore persists the peers the network has connected to, so that it can
+// reconnect to them after a restart. It isn't thread safe; the network's
+// stateLock should be held when calling its methods.
+type peerStore struct {
+	db database.Database
+
+	// ip.String() --> peer
+	peers map[string]*storedPeer
+}
+
+func newPeerStore(db database.Database) *peerStore {
+	return &peerStore{
+		db:    prefixdb.New(peerStorePrefix, db),
+		peers: make(map[string]*storedPeer),
+	}
+}
+
+// load the stored peers. Peers that haven't been seen since [peerStoreExpiry]
+// before [now], or that would exceed [maxStoredPeers], are deleted.
+func (s *peerStore) load(now time.Time) error {
+	iter := s.db.NewIterator()
+	for iter.Next() {
+		peer, err := parseStoredPeer(iter.Value())
+		if err != nil {
+			iter.Release()
+			return err
+		}
+		s.peers[peer.IP.String()] = peer
+	}
+	err := iter.Error()
+	iter.Release()
+	if err != nil {
+		return err
+	}
+
+	for _, peer := range s.sorted(now) {
+		if len(s.peers) <= maxStoredPeers && now.Sub(peer.LastSeen) < peerStoreExpiry {
+			continue
+		}
+		if err := s.forget(peer); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// sorted returns the stored peers, from the lowest uptime score at [now] to
+// the highest
+func (s *peerStore) sorted(now time.Time) []*storedPeer {
+	peers := make([]*storedPeer, 0, len(s.peers))
+	for _, peer := range s.peers {
+		peers = append(peers, peer)
+	}
+	sort.Slice(peers, func(i, j int) bool {
+		return peers[i].UptimeScore(now) < peers[j].UptimeScore(now)
+	})
+	return peers
+}
+
+// IPs returns the IPs of the stored peers, from the highest uptime score at
+// [now] to the lowest
+func (s *peerStore) IPs(now time.Time) []utils.IPDesc {
+	peers := s.sorted(now)
+	ips := make([]utils.IPDesc, len(peers))
+	for i, peer := range peers {
+		ips[len(peers)-1-i] = peer.IP
+	}
+	return ips
+}
+
+// Connected records that the network connected to [nodeID] at [ip] at [now]
+func (s *peerStore) Connected(ip utils.IPDesc, nodeID ids.ShortID, now time.Time) error {
+	peer, ok := s.peers[ip.String()]
+	if !ok {
+		if len(s.peers) >= maxStoredPeers {
+			// Make room for the new peer by forgetting the least useful one
+			if err := s.forget(s.sorted(now)[0]); err != nil {
+				return err
+			}
+		}
+		peer = &storedPeer{
+			IP:        ip,
+			FirstSeen: now,
+		}
+		s.peers[ip.String()] = peer
+	}
+	peer.NodeID = nodeID
+	peer.LastSeen = now
+	return s.db.Put([]byte(peer.IP.String()), peer.Bytes())
+}
+
+// Disconnected records that the network disconnected at [now] from the peer
+// at [ip], which it had been connected to since [connectedAt]
+func (s *peerStore) Disconnected(ip utils.IPDesc, connectedAt, now time.Time) error {
+	peer, ok := s.peers[ip.String()]
+	if !ok {
+		return nil
+	}
+	if !connectedAt.IsZero() && now.After(connectedAt) {
+		peer.Connected += now.Sub(connectedAt)
+	}
+	peer.LastSeen = now
+	return s.db.Put([]byte(peer.IP.String()), peer.Bytes())
+}
+
+func (s *peerStore) forget(peer *storedPeer) error {
+	delete(s.peers, peer.IP.String())
+	return s.db.Delete([]byte(peer.IP.String()))
+}
@@ -0,0 +1,75 @@
+// (c) 2019-2020, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package network
+
+import (
+	"net"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/ava-labs/avalanchego/database/memdb"
+	"github.com/ava-labs/avalanchego/ids"
+	"github.com/ava-labs/avalanchego/utils"
+)
+
+func TestPeerStorePersists(t *testing.T) {
+	assert := assert.New(t)
+
+	db := memdb.New()
+	now := time.Unix(1000000, 0)
+
+	ip0 := utils.IPDesc{IP: net.IPv4(1, 2, 3, 4), Port: 9651}
+	ip1 := utils.IPDesc{IP: net.IPv4(5, 6, 7, 8), Port: 9651}
+	id0 := ids.GenerateTestShortID()
+	id1 := ids.GenerateTestShortID()
+
+	store := newPeerStore(db)
+	assert.NoError(store.load(now))
+	assert.NoError(store.Connected(ip0, id0, now))
+	assert.NoError(store.Connected(ip1, id1, now))
+
+	// [ip0] stays connected for an hour, [ip1] for a minute
+	assert.NoError(store.Disconnected(ip0, now, now.Add(time.Hour)))
+	assert.NoError(store.Disconnected(ip1, now, now.Add(time.Minute)))
+
+	later := now.Add(2 * time.Hour)
+	reloaded := newPeerStore(db)
+	assert.NoError(reloaded.load(later))
+
+	peer := reloaded.peers[ip0.String()]
+	if assert.NotNil(peer) {
+		assert.Equal(id0, peer.NodeID)
+		assert.Equal(time.Hour, peer.Connected)
+		assert.Equal(0.5, peer.UptimeScore(later))
+	}
+
+	// Peers with better uptime are reconnected to first
+	ips := reloaded.IPs(later)
+	if assert.Len(ips, 2) {
+		assert.True(ip0.Equal(ips[0]))
+		assert.True(ip1.Equal(ips[1]))
+	}
+}
+
+func TestPeerStoreForgetsExpiredPeers(t *testing.T) {
+	assert := assert.New(t)
+
+	db := memdb.New()
+	now := time.Unix(1000000, 0)
+	ip := utils.IPDesc{IP: net.IPv4(1, 2, 3, 4), Port: 9651}
+
+	store := newPeerStore(db)
+	assert.NoError(store.Connected(ip, ids.GenerateTestShortID(), now))
+
+	reloaded := newPeerStore(db)
+	assert.NoError(reloaded.load(now.Add(peerStoreExpiry)))
+	assert.Empty(reloaded.IPs(now))
+
+	// The expired peer was deleted from the database
+	reloaded = newPeerStore(db)
+	assert.NoError(reloaded.load(now))
+	assert.Empty(reloaded.IPs(now))
+}
@@ -2,17 +2,22 @@ package network
 
 import (
 	"net"
+	"sync/atomic"
 	"testing"
 	"time"
 
+	"github.com/ava-labs/avalanchego/database/memdb"
 	"github.com/ava-labs/avalanchego/ids"
+	"github.com/ava-labs/avalanchego/network/networktest"
 	"github.com/ava-labs/avalanchego/snow/networking/benchlist"
 	"github.com/ava-labs/avalanchego/snow/validators"
 	"github.com/ava-labs/avalanchego/utils"
 	"github.com/ava-labs/avalanchego/utils/hashing"
 	"github.com/ava-labs/avalanchego/utils/logging"
+	"github.com/ava-labs/avalanchego/utils/math"
 	"github.com/ava-labs/avalanchego/version"
 	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -46,21 +51,14 @@ func TestPeer_Close(t *testing.T) {
 	appVersion := version.NewDefaultVersion("app", 0, 1, 0)
 	versionParser := version.NewDefaultParser()
 
-	listener := &testListener{
-		addr: &net.TCPAddr{
-			IP:   net.IPv6loopback,
-			Port: 0,
-		},
-		inbound: make(chan net.Conn, 1<<10),
-		closed:  make(chan struct{}),
-	}
-	caller := &testDialer{
-		addr: &net.TCPAddr{
-			IP:   net.IPv6loopback,
-			Port: 0,
-		},
-		outbounds: make(map[string]*testListener),
-	}
+	listener := networktest.NewListener(&net.TCPAddr{
+		IP:   net.IPv6loopback,
+		Port: 0,
+	})
+	caller := networktest.NewDialer(&net.TCPAddr{
+		IP:   net.IPv6loopback,
+		Port: 0,
+	})
 	serverUpgrader := NewIPUpgrader()
 	clientUpgrader := NewIPUpgrader()
 
@@ -75,7 +73,7 @@ func TestPeer_Close(t *testing.T) {
 		networkID,
 		appVersion,
 		versionParser,
-		listener,
+		[]net.Listener{listener},
 		caller,
 		serverUpgrader,
 		clientUpgrader,
@@ -90,9 +88,15 @@ func TestPeer_Close(t *testing.T) {
 		0,
 		time.Now(),
 		defaultSendQueueSize,
+		DefaultSendQueueConfig,
+		BandwidthConfig{},
+		DefaultGossipConfig,
+		DefaultConnectionConfig,
 		HealthConfig{},
 		benchlist.NewManager(&benchlist.Config{}),
+		memdb.New(),
 		defaultAliasTimeout,
+		nil,
 	)
 	assert.NotNil(t, netwrk)
 
@@ -100,7 +104,7 @@ func TestPeer_Close(t *testing.T) {
 		net.IPv6loopback,
 		1,
 	)
-	caller.outbounds[ip1.IP().String()] = listener
+	caller.Route(ip1.IP(), listener)
 	conn, _ := caller.Dial(ip1.IP())
 
 	basenetwork := netwrk.(*network)
@@ -136,3 +140,594 @@ func TestPeer_Close(t *testing.T) {
 		t.Fatalf("pending bytes invalid")
 	}
 }
+
+func newTestChainMsg(op Op, chainID ids.ID, bits []byte) Msg {
+	return &msg{
+		op:     op,
+		fields: map[Field]interface{}{ChainID: chainID[:]},
+		bytes:  bits,
+	}
+}
+
+func TestPeerChainQueuesRoundRobin(t *testing.T) {
+	net := &network{
+		log:                        logging.NoLog{},
+		sendQueueSize:              10,
+		sendQueueConfig:            DefaultSendQueueConfig,
+		maxMessageSize:             1 << 20,
+		maxNetworkPendingSendBytes: 1 << 20,
+	}
+	assert.NoError(t, net.metrics.initialize(prometheus.NewRegistry()))
+	peer := newPeer(net, nil, utils.IPDesc{})
+	peer.sender = make(chan []byte, 10)
+
+	chainA := ids.ID{1}
+	chainB := ids.ID{2}
+
+	// chain A queues a burst of messages before chain B queues a single one
+	assert.True(t, peer.Send(newTestChainMsg(PushQuery, chainA, []byte("a1"))))
+	assert.True(t, peer.Send(newTestChainMsg(PushQuery, chainA, []byte("a2"))))
+	assert.True(t, peer.Send(newTestChainMsg(PushQuery, chainA, []byte("a3"))))
+	assert.True(t, peer.Send(newTestChainMsg(Chits, chainB, []byte("b1"))))
+	// messages that aren't bound to a chain are sent first
+	assert.True(t, peer.Send(newTestMsg(Ping, []byte("ping"))))
+
+	assert.Equal(t, map[string]int64{
+		chainA.String(): 6,
+		chainB.String(): 2,
+	}, peer.chainPendingBytes())
+
+	for _, expected := range []string{"ping", "a1", "b1", "a2", "a3"} {
+		msg, ok := peer.nextMessage()
+		assert.True(t, ok)
+		assert.Equal(t, expected, string(msg))
+	}
+	assert.Empty(t, peer.chainPendingBytes())
+}
+
+func TestPeerChainQueueWindow(t *testing.T) {
+	net := &network{
+		log:                        logging.NoLog{},
+		sendQueueSize:              2,
+		sendQueueConfig:            DefaultSendQueueConfig,
+		maxMessageSize:             1 << 20,
+		maxNetworkPendingSendBytes: 1 << 20,
+	}
+	assert.NoError(t, net.metrics.initialize(prometheus.NewRegistry()))
+	peer := newPeer(net, nil, utils.IPDesc{})
+	peer.sender = make(chan []byte, 2)
+
+	chainA := ids.ID{1}
+	chainB := ids.ID{2}
+
+	assert.True(t, peer.Send(newTestChainMsg(PushQuery, chainA, []byte("a1"))))
+	assert.True(t, peer.Send(newTestChainMsg(PushQuery, chainA, []byte("a2"))))
+	// chain A's window is full
+	assert.False(t, peer.Send(newTestChainMsg(PushQuery, chainA, []byte("a3"))))
+	// but chain B is unaffected
+	assert.True(t, peer.Send(newTestChainMsg(Chits, chainB, []byte("b1"))))
+	assert.Equal(t, int64(6), net.pendingBytes)
+}
+
+func TestPeerChainQueuesSendClassesInOrder(t *testing.T) {
+	net := &network{
+		log:                        logging.NoLog{},
+		sendQueueSize:              10,
+		sendQueueConfig:            DefaultSendQueueConfig,
+		maxMessageSize:             1 << 20,
+		maxNetworkPendingSendBytes: 1 << 20,
+	}
+	assert.NoError(t, net.metrics.initialize(prometheus.NewRegistry()))
+	peer := newPeer(net, nil, utils.IPDesc{})
+	peer.sender = make(chan []byte, 10)
+
+	chainA := ids.ID{1}
+	chainB := ids.ID{2}
+
+	// a bootstrapping peer is sent a burst of MultiPuts before chits are
+	// queued
+	assert.True(t, peer.Send(newTestChainMsg(MultiPut, chainA, []byte("m1"))))
+	assert.True(t, peer.Send(newTestChainMsg(MultiPut, chainA, []byte("m2"))))
+	assert.True(t, peer.Send(newTestChainMsg(GossipTxs, chainB, []byte("g1"))))
+	assert.True(t, peer.Send(newTestChainMsg(Chits, chainB, []byte("c1"))))
+	assert.True(t, peer.Send(newTestChainMsg(Chits, chainA, []byte("c2"))))
+
+	for _, expected := range []string{"c2", "c1", "g1", "m1", "m2"} {
+		msg, ok := peer.nextMessage()
+		assert.True(t, ok)
+		assert.Equal(t, expected, string(msg))
+	}
+}
+
+func TestPeerChainQueueDropsOldest(t *testing.T) {
+	config := DefaultSendQueueConfig
+	config.Gossip.DropPolicy = DropOldest
+	net := &network{
+		log:                        logging.NoLog{},
+		sendQueueSize:              4,
+		sendQueueConfig:            config,
+		maxMessageSize:             1 << 20,
+		maxNetworkPendingSendBytes: 1 << 20,
+	}
+	assert.NoError(t, net.metrics.initialize(prometheus.NewRegistry()))
+	peer := newPeer(net, nil, utils.IPDesc{})
+	peer.sender = make(chan []byte, 4)
+
+	chainID := ids.ID{1}
+
+	// bootstrapping messages may only fill half of the window
+	assert.True(t, peer.Send(newTestChainMsg(MultiPut, chainID, []byte("m1"))))
+	assert.True(t, peer.Send(newTestChainMsg(MultiPut, chainID, []byte("m2"))))
+	assert.False(t, peer.Send(newTestChainMsg(MultiPut, chainID, []byte("m3"))))
+
+	// gossip makes room for newer gossip by dropping the oldest
+	assert.True(t, peer.Send(newTestChainMsg(GossipTxs, chainID, []byte("g1"))))
+	assert.True(t, peer.Send(newTestChainMsg(GossipTxs, chainID, []byte("g2"))))
+	assert.True(t, peer.Send(newTestChainMsg(GossipTxs, chainID, []byte("g3"))))
+	assert.Equal(t, int64(8), net.pendingBytes)
+	assert.Equal(t, int64(8), peer.pendingBytes)
+
+	for _, expected := range []string{"g2", "g3", "m1", "m2"} {
+		msg, ok := peer.nextMessage()
+		assert.True(t, ok)
+		assert.Equal(t, expected, string(msg))
+	}
+
+	counts, _ := peer.dropStats()
+	assert.Equal(t, map[string]uint64{
+		MultiPut.String():  1,
+		GossipTxs.String(): 1,
+	}, counts)
+
+	label := chainID.String()
+	assert.Equal(t, 5.0, testutil.ToFloat64(net.chains.msgsSent.WithLabelValues(label)))
+	assert.Equal(t, 10.0, testutil.ToFloat64(net.chains.bytesSent.WithLabelValues(label)))
+	assert.Equal(t, 2.0, testutil.ToFloat64(net.chains.msgsDropped.WithLabelValues(label)))
+}
+
+func TestPeerSendQueueDropsLowPriorityFirst(t *testing.T) {
+	net := &network{
+		log:                        logging.NoLog{},
+		sendQueueSize:              4,
+		sendQueueConfig:            DefaultSendQueueConfig,
+		maxMessageSize:             1 << 20,
+		maxNetworkPendingSendBytes: 1 << 20,
+	}
+	assert.NoError(t, net.metrics.initialize(prometheus.NewRegistry()))
+	peer := newPeer(net, nil, utils.IPDesc{})
+	peer.sender = make(chan []byte, 4)
+
+	chainID := ids.ID{1}
+
+	// gossip may only fill half of the send queue
+	assert.True(t, peer.Send(newTestMsg(PeerList, []byte("p1"))))
+	assert.True(t, peer.Send(newTestMsg(PeerList, []byte("p2"))))
+	assert.False(t, peer.Send(newTestMsg(PeerList, []byte("p3"))))
+	assert.True(t, peer.Send(newTestChainMsg(GossipTxs, chainID, []byte("g1"))))
+	assert.True(t, peer.Send(newTestChainMsg(GossipTxs, chainID, []byte("g2"))))
+	assert.False(t, peer.Send(newTestChainMsg(GossipTxs, chainID, []byte("g3"))))
+
+	// while the rest is left for other messages
+	assert.True(t, peer.Send(newTestMsg(Ping, []byte("ping"))))
+	assert.True(t, peer.Send(newTestMsg(Pong, []byte("pong"))))
+	assert.False(t, peer.Send(newTestMsg(Ping, []byte("ping"))))
+	assert.True(t, peer.Send(newTestChainMsg(Chits, chainID, []byte("c1"))))
+	assert.True(t, peer.Send(newTestChainMsg(Chits, chainID, []byte("c2"))))
+	assert.False(t, peer.Send(newTestChainMsg(Chits, chainID, []byte("c3"))))
+
+	counts, chronic := peer.dropStats()
+	assert.Equal(t, map[string]uint64{
+		PeerList.String():  1,
+		GossipTxs.String(): 1,
+		Ping.String():      1,
+		Chits.String():     1,
+	}, counts)
+	assert.False(t, chronic)
+}
+
+func TestDropStatsChronic(t *testing.T) {
+	d := dropStats{}
+	now := time.Unix(1, 0)
+
+	d.Add(Chits, now)
+	assert.False(t, d.Chronic(now))
+
+	// drops continue without a long gap
+	for elapsed := time.Duration(0); elapsed < chronicDropDuration; elapsed += chronicDropGap {
+		now = now.Add(chronicDropGap)
+		d.Add(Chits, now)
+	}
+	assert.True(t, d.Chronic(now))
+
+	// drops stop
+	now = now.Add(chronicDropGap + time.Second)
+	assert.False(t, d.Chronic(now))
+
+	// and the streak restarts after the gap
+	d.Add(Chits, now)
+	assert.False(t, d.Chronic(now))
+	assert.Equal(t, map[string]uint64{Chits.String(): uint64(chronicDropDuration/chronicDropGap) + 2}, d.Counts())
+}
+
+func TestPeerSendResponseEchoesTrace(t *testing.T) {
+	net := &network{
+		log:                        logging.NoLog{},
+		sendQueueSize:              4,
+		sendQueueConfig:            DefaultSendQueueConfig,
+		maxMessageSize:             1 << 20,
+		maxNetworkPendingSendBytes: 1 << 20,
+	}
+	assert.NoError(t, net.metrics.initialize(prometheus.NewRegistry()))
+	peer := newPeer(net, nil, utils.IPDesc{})
+	peer.sender = make(chan []byte, 4)
+
+	chainID := ids.ID{1}
+	requestID := uint32(5)
+	traceID := uint64(42)
+	peer.traces.Put(traceKey{
+		chainID:   chainID,
+		requestID: requestID,
+	}, traceID)
+
+	put, err := net.b.Put(chainID, requestID, ids.ID{2}, []byte{3})
+	assert.NoError(t, err)
+
+	// the peer hasn't advertised that it supports tracing
+	assert.True(t, peer.SendResponse(put, chainID, requestID))
+	msgBytes, ok := peer.nextMessage()
+	assert.True(t, ok)
+	assert.Equal(t, put.Bytes(), msgBytes)
+
+	peer.peerCapabilities = capabilityTracing
+	peer.traces.Put(traceKey{
+		chainID:   chainID,
+		requestID: requestID,
+	}, traceID)
+
+	assert.True(t, peer.SendResponse(put, chainID, requestID))
+	msgBytes, ok = peer.nextMessage()
+	assert.True(t, ok)
+	msg, err := net.b.Parse(msgBytes)
+	assert.NoError(t, err)
+	assert.Equal(t, Traced, msg.Op())
+	assert.Equal(t, traceID, msg.Get(TraceID))
+	assert.Equal(t, put.Bytes(), msg.Get(TracedMsg))
+
+	// the trace is only echoed once
+	assert.True(t, peer.SendResponse(put, chainID, requestID))
+	msgBytes, ok = peer.nextMessage()
+	assert.True(t, ok)
+	assert.Equal(t, put.Bytes(), msgBytes)
+}
+
+func TestPeerSendCompressesLargeMessages(t *testing.T) {
+	net := &network{
+		log:                        logging.NoLog{},
+		sendQueueSize:              4,
+		sendQueueConfig:            DefaultSendQueueConfig,
+		maxMessageSize:             1 << 20,
+		maxNetworkPendingSendBytes: 1 << 20,
+	}
+	assert.NoError(t, net.metrics.initialize(prometheus.NewRegistry()))
+	peer := newPeer(net, nil, utils.IPDesc{})
+	peer.sender = make(chan []byte, 4)
+
+	chainID := ids.ID{1}
+	put, err := net.b.Put(chainID, 5, ids.ID{2}, make([]byte, 2*compressionThreshold))
+	assert.NoError(t, err)
+
+	// the peer hasn't advertised that it supports compression
+	assert.True(t, peer.Send(put))
+	msgBytes, ok := peer.nextMessage()
+	assert.True(t, ok)
+	assert.Equal(t, put.Bytes(), msgBytes)
+
+	peer.peerCapabilities = capabilityGzip
+
+	assert.True(t, peer.Send(put))
+	msgBytes, ok = peer.nextMessage()
+	assert.True(t, ok)
+	msg, err := net.b.Parse(msgBytes)
+	assert.NoError(t, err)
+	assert.Equal(t, Compressed, msg.Op())
+	innerBytes, err := decompress(msg.Get(CompressedMsg).([]byte), net.maxMessageSize)
+	assert.NoError(t, err)
+	assert.Equal(t, put.Bytes(), innerBytes)
+
+	// small messages aren't compressed
+	small, err := net.b.Put(chainID, 5, ids.ID{2}, []byte{3})
+	assert.NoError(t, err)
+	assert.True(t, peer.Send(small))
+	msgBytes, ok = peer.nextMessage()
+	assert.True(t, ok)
+	assert.Equal(t, small.Bytes(), msgBytes)
+}
+
+func TestPeerPongMeasuresRTT(t *testing.T) {
+	net := &network{
+		log:                        logging.NoLog{},
+		sendQueueSize:              4,
+		sendQueueConfig:            DefaultSendQueueConfig,
+		maxMessageSize:             1 << 20,
+		maxNetworkPendingSendBytes: 1 << 20,
+	}
+	peer := newPeer(net, nil, utils.IPDesc{})
+
+	now := time.Unix(1, 0)
+	net.clock.Set(now)
+
+	// a pong that doesn't answer a ping is ignored
+	peer.pong(nil)
+	assert.Zero(t, atomic.LoadInt64(&peer.rtt))
+
+	atomic.StoreInt64(&peer.pingSent, now.UnixNano())
+	atomic.StoreUint32(&peer.missedPongs, 2)
+	net.clock.Set(now.Add(150 * time.Millisecond))
+
+	peer.pong(nil)
+	assert.Equal(t, 150*time.Millisecond, time.Duration(atomic.LoadInt64(&peer.rtt)))
+	assert.Zero(t, atomic.LoadUint32(&peer.missedPongs))
+	assert.Zero(t, atomic.LoadInt64(&peer.pingSent))
+}
+
+func TestPeerTimedPongMeasuresClockOffset(t *testing.T) {
+	net := &network{
+		log:                        logging.NoLog{},
+		sendQueueSize:              4,
+		sendQueueConfig:            DefaultSendQueueConfig,
+		maxMessageSize:             1 << 20,
+		maxNetworkPendingSendBytes: 1 << 20,
+	}
+	peer := newPeer(net, nil, utils.IPDesc{})
+
+	// the peer's clock is 5 seconds ahead of ours
+	skew := 5 * time.Second
+	now := time.Unix(1000, 0)
+	net.clock.Set(now)
+	atomic.StoreInt64(&peer.pingSent, now.UnixNano())
+
+	// the peer answers halfway through the round trip
+	pong, err := net.b.TimedPong(uint64(now.Add(skew + 50*time.Millisecond).UnixNano()))
+	assert.NoError(t, err)
+	net.clock.Set(now.Add(100 * time.Millisecond))
+
+	peer.timedPong(pong)
+	assert.Equal(t, 100*time.Millisecond, time.Duration(atomic.LoadInt64(&peer.rtt)))
+	assert.Equal(t, skew, time.Duration(atomic.LoadInt64(&peer.clockOffset)))
+
+	// a timed pong that doesn't answer a ping is ignored
+	pong, err = net.b.TimedPong(0)
+	assert.NoError(t, err)
+	peer.timedPong(pong)
+	assert.Equal(t, skew, time.Duration(atomic.LoadInt64(&peer.clockOffset)))
+}
+
+func TestPeerPingSendsTimedPong(t *testing.T) {
+	netw := &network{
+		log:                        logging.NoLog{},
+		sendQueueSize:              4,
+		sendQueueConfig:            DefaultSendQueueConfig,
+		maxMessageSize:             1 << 20,
+		maxNetworkPendingSendBytes: 1 << 20,
+		sendFailRateCalculator:     math.NewAverager(0, time.Second, time.Now()),
+	}
+	assert.NoError(t, netw.metrics.initialize(prometheus.NewRegistry()))
+	peer := newPeer(netw, nil, utils.IPDesc{})
+	peer.sender = make(chan []byte, 4)
+
+	now := time.Unix(1000, 0)
+	netw.clock.Set(now)
+
+	// the peer hasn't advertised that it understands timed pongs
+	peer.ping(nil)
+	msgBytes, ok := peer.nextMessage()
+	assert.True(t, ok)
+	msg, err := netw.b.Parse(msgBytes)
+	assert.NoError(t, err)
+	assert.Equal(t, Pong, msg.Op())
+
+	peer.peerCapabilities = capabilityTimedPong
+
+	peer.ping(nil)
+	msgBytes, ok = peer.nextMessage()
+	assert.True(t, ok)
+	msg, err = netw.b.Parse(msgBytes)
+	assert.NoError(t, err)
+	assert.Equal(t, TimedPong, msg.Op())
+	assert.Equal(t, uint64(now.UnixNano()), msg.Get(Timestamp))
+}
+
+func TestHealthCheckReportsClockSkew(t *testing.T) {
+	netw := &network{
+		log:                        logging.NoLog{},
+		sendQueueSize:              4,
+		sendQueueConfig:            DefaultSendQueueConfig,
+		maxMessageSize:             1 << 20,
+		maxNetworkPendingSendBytes: 1 << 20,
+		sendFailRateCalculator:     math.NewAverager(0, time.Second, time.Now()),
+		peers:                      make(map[ids.ShortID]*peer),
+		healthConfig: HealthConfig{
+			MaxTimeSinceMsgReceived:      time.Hour,
+			MaxTimeSinceMsgSent:          time.Hour,
+			MaxPortionSendQueueBytesFull: 1,
+			MaxSendFailRate:              1,
+			MaxClockSkew:                 30 * time.Second,
+		},
+	}
+	assert.NoError(t, netw.metrics.initialize(prometheus.NewRegistry()))
+	now := time.Unix(1000, 0)
+	netw.clock.Set(now)
+	atomic.StoreInt64(&netw.lastMsgReceivedTime, now.Unix())
+	atomic.StoreInt64(&netw.lastMsgSentTime, now.Unix())
+
+	// simulate our clock being behind most of our peers' clocks
+	for i, offset := range []time.Duration{-time.Second, 40 * time.Second, time.Minute} {
+		peer := newPeer(netw, nil, utils.IPDesc{})
+		peer.connected.SetValue(true)
+		atomic.StoreInt64(&peer.clockOffset, int64(offset))
+		netw.peers[ids.ShortID{byte(i)}] = peer
+	}
+
+	details, err := netw.HealthCheck()
+	assert.Equal(t, errNetworkLayerUnhealthy, err)
+	assert.Equal(t, (40 * time.Second).String(), details.(map[string]interface{})["clockSkew"])
+
+	// the skew is within the threshold
+	netw.healthConfig.MaxClockSkew = time.Minute
+	_, err = netw.HealthCheck()
+	assert.NoError(t, err)
+
+	// the check can be disabled
+	netw.healthConfig.MaxClockSkew = 0
+	_, err = netw.HealthCheck()
+	assert.NoError(t, err)
+}
+
+func TestMedianDuration(t *testing.T) {
+	assert.Equal(t, time.Duration(3), medianDuration([]int64{5, 3, 1}))
+	assert.Equal(t, time.Duration(-1), medianDuration([]int64{-5, 1, -3, 7}))
+	assert.Equal(t, time.Duration(4), medianDuration([]int64{4}))
+}
+
+func TestNetworkPeerIDReportsSession(t *testing.T) {
+	n := &network{
+		log:                        logging.NoLog{},
+		sendQueueSize:              4,
+		sendQueueConfig:            DefaultSendQueueConfig,
+		maxMessageSize:             1 << 20,
+		maxNetworkPendingSendBytes: 1 << 20,
+		benchlistManager:           benchlist.NewManager(&benchlist.Config{}),
+	}
+	conn, _ := networktest.NewConnPair(
+		&net.TCPAddr{IP: net.IPv6loopback, Port: 0},
+		&net.TCPAddr{IP: net.IPv6loopback, Port: 9651},
+		networktest.Conditions{},
+		nil,
+	)
+	peer := newPeer(n, conn, utils.IPDesc{})
+	peer.versionStr.SetValue("avalanche/1.0.0")
+	peer.connectedAt = time.Unix(1000, 0)
+	atomic.StoreUint32(&peer.sessionID, 7)
+	atomic.StoreUint64(&peer.bytesSent, 10)
+	atomic.StoreUint64(&peer.bytesReceived, 20)
+
+	peerID := n.peerID(peer)
+	assert.Equal(t, "[::1]:9651", peerID.IP)
+	assert.Equal(t, "avalanche/1.0.0", peerID.Version)
+	assert.Equal(t, uint32(7), peerID.SessionID)
+	assert.Equal(t, time.Unix(1000, 0), peerID.ConnectedAt)
+	assert.Equal(t, uint64(10), peerID.BytesSent)
+	assert.Equal(t, uint64(20), peerID.BytesReceived)
+}
+
+func TestPeerListAdvertisesOwnIP(t *testing.T) {
+	ip := utils.IPDesc{IP: net.IPv4(1, 2, 3, 4), Port: 9651}
+	netw := &network{
+		log:                        logging.NoLog{},
+		ip:                         utils.NewDynamicIPDesc(ip.IP, ip.Port),
+		vdrs:                       validators.NewSet(),
+		sendQueueSize:              4,
+		sendQueueConfig:            DefaultSendQueueConfig,
+		maxMessageSize:             1 << 20,
+		maxNetworkPendingSendBytes: 1 << 20,
+		sendFailRateCalculator:     math.NewAverager(0, time.Second, time.Now()),
+	}
+	assert.NoError(t, netw.metrics.initialize(prometheus.NewRegistry()))
+	peer := newPeer(netw, nil, utils.IPDesc{})
+	peer.sender = make(chan []byte, 4)
+
+	sentIPs := func() []utils.IPDesc {
+		msgBytes, ok := peer.nextMessage()
+		assert.True(t, ok)
+		msg, err := netw.b.Parse(msgBytes)
+		assert.NoError(t, err)
+		assert.Equal(t, PeerList, msg.Op())
+		return msg.Get(Peers).([]utils.IPDesc)
+	}
+
+	peer.SendPeerList()
+	assert.Empty(t, sentIPs())
+
+	netw.connectionConfig.AdvertiseIP = true
+	peer.SendPeerList()
+	assert.Equal(t, []utils.IPDesc{ip}, sentIPs())
+}
+
+func TestDuplicateConnectionLearnsAdvertisedIP(t *testing.T) {
+	db := memdb.New()
+	netw := &network{
+		log:             logging.NoLog{},
+		peers:           make(map[ids.ShortID]*peer),
+		disconnectedIPs: make(map[string]struct{}),
+		connectedIPs:    make(map[string]struct{}),
+		peerAliasIPs:    make(map[string]struct{}),
+		retryDelay:      make(map[string]time.Duration),
+		blacklist:       newPeerBlacklist(db),
+		peerStore:       newPeerStore(db),
+	}
+
+	// The existing connection came from an address other than the IP that
+	// the peer advertises, so its IP is unknown
+	id := ids.GenerateTestShortID()
+	existing := newPeer(netw, nil, utils.IPDesc{})
+	existing.id = id
+	existing.connected.SetValue(true)
+	netw.peers[id] = existing
+
+	ip := utils.IPDesc{IP: net.IPv4(1, 2, 3, 4), Port: 9651}
+	dialed := newPeer(netw, nil, ip)
+	dialed.id = id
+	assert.Error(t, netw.tryAddPeer(dialed))
+	assert.Equal(t, ip, existing.getIP())
+	assert.Contains(t, netw.connectedIPs, ip.String())
+
+	// Once the IP is known, other IPs are recorded as aliases
+	alias := utils.IPDesc{IP: net.IPv4(5, 6, 7, 8), Port: 9651}
+	dialed = newPeer(netw, nil, alias)
+	dialed.id = id
+	assert.Error(t, netw.tryAddPeer(dialed))
+	assert.Equal(t, ip, existing.getIP())
+	assert.NotContains(t, netw.connectedIPs, alias.String())
+	assert.Contains(t, netw.peerAliasIPs, alias.String())
+}
+
+func TestJustifiedChitsFallsBackToChits(t *testing.T) {
+	netw := &network{
+		log:                        logging.NoLog{},
+		sendQueueSize:              4,
+		sendQueueConfig:            DefaultSendQueueConfig,
+		maxMessageSize:             1 << 20,
+		maxNetworkPendingSendBytes: 1 << 20,
+		sendFailRateCalculator:     math.NewAverager(0, time.Second, time.Now()),
+		peers:                      make(map[ids.ShortID]*peer),
+	}
+	assert.NoError(t, netw.metrics.initialize(prometheus.NewRegistry()))
+	peer := newPeer(netw, nil, utils.IPDesc{})
+	peer.sender = make(chan []byte, 4)
+	peer.id = ids.ShortID{1}
+	peer.connected.SetValue(true)
+	netw.peers[peer.id] = peer
+
+	chainID := ids.ID{2}
+	votes := []ids.ID{{3}}
+	heights := []uint64{4}
+
+	// the peer hasn't advertised that it understands justified chits
+	netw.JustifiedChits(peer.id, chainID, 5, votes, heights)
+	msgBytes, ok := peer.nextMessage()
+	assert.True(t, ok)
+	msg, err := netw.b.Parse(msgBytes)
+	assert.NoError(t, err)
+	assert.Equal(t, Chits, msg.Op())
+	assert.Nil(t, msg.Get(ContainerHeights))
+
+	peer.peerCapabilities = capabilityJustifiedChits
+
+	netw.JustifiedChits(peer.id, chainID, 6, votes, heights)
+	msgBytes, ok = peer.nextMessage()
+	assert.True(t, ok)
+	msg, err = netw.b.Parse(msgBytes)
+	assert.NoError(t, err)
+	assert.Equal(t, JustifiedChits, msg.Op())
+	assert.Equal(t, heights, msg.Get(ContainerHeights))
+}
@@ -0,0 +1,104 @@
+// (c) 2019-2020, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+// Package protocoltest checks that a node speaks the peer-to-peer wire
+// protocol correctly. It connects to a node's staking port, plays scripted
+// sequences of handshake and consensus messages, including malformed and
+// out of order ones, and checks that the node responds, or disconnects, as the
+// protocol requires. Only the wire protocol is relied on, so the scripts can
+// be run against any implementation of it.
+package protocoltest
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"net"
+	"time"
+
+	"github.com/ava-labs/avalanchego/network"
+)
+
+var (
+	errUnexpectedDisconnect = errors.New("node disconnected unexpectedly")
+	errNoDisconnect         = errors.New("node didn't disconnect")
+	errMalformedMessage     = errors.New("node sent a malformed message")
+)
+
+// Config describes the node under test and how to connect to it
+type Config struct {
+	// Address, as host:port, of the node's staking port
+	Address string
+	// Upgrader of connections to the node. Nodes that require TLS need
+	// network.NewTLSClientUpgrader.
+	Upgrader network.Upgrader
+	// ID of the network that the node is on
+	NetworkID uint32
+	// Version that the scripts claim to run. Must be compatible with the
+	// node's version.
+	Version string
+	// Maximum size of a message that the node accepts
+	MaxMessageSize uint32
+	// How long to wait for each response from the node
+	Timeout time.Duration
+}
+
+// Conn is a connection to the node under test
+type Conn struct {
+	config Config
+	conn   net.Conn
+	b      network.Builder
+}
+
+// Dial connects to the node described by [config]
+func Dial(config Config) (*Conn, error) {
+	conn, err := net.DialTimeout("tcp", config.Address, config.Timeout)
+	if err != nil {
+		return nil, err
+	}
+	_, upgraded, err := config.Upgrader.Upgrade(conn)
+	if err != nil {
+		_ = conn.Close()
+		return nil, fmt.Errorf("couldn't upgrade the connection: %w", err)
+	}
+	return &Conn{
+		config: config,
+		conn:   upgraded,
+	}, nil
+}
+
+// Close the connection
+func (c *Conn) Close() error { return c.conn.Close() }
+
+// Send [msg] to the node
+func (c *Conn) Send(msg network.Msg) error { return c.SendRaw(msg.Bytes()) }
+
+// SendRaw sends [msgBytes] to the node as a message, whether or not it's a
+// valid message
+func (c *Conn) SendRaw(msgBytes []byte) error {
+	if err := c.conn.SetWriteDeadline(time.Now().Add(c.config.Timeout)); err != nil {
+		return err
+	}
+	return network.WriteFrame(c.conn, msgBytes, false)
+}
+
+// Receive the next message from the node. Returns io.EOF if the node
+// disconnected.
+func (c *Conn) Receive() (network.Msg, error) {
+	if err := c.conn.SetReadDeadline(time.Now().Add(c.config.Timeout)); err != nil {
+		return nil, err
+	}
+	maxMessageSize := c.config.MaxMessageSize
+	if maxMessageSize == 0 {
+		maxMessageSize = math.MaxUint32
+	}
+	msgBytes, err := network.ReadFrame(c.conn, maxMessageSize, false)
+	if err != nil {
+		return nil, err
+	}
+	msg, err := c.b.Parse(msgBytes)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s", errMalformedMessage, err)
+	}
+	return msg, nil
+}
@@ -0,0 +1,87 @@
+// (c) 2019-2020, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package protocoltest
+
+import (
+	"net"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/ava-labs/avalanchego/database/memdb"
+	"github.com/ava-labs/avalanchego/ids"
+	"github.com/ava-labs/avalanchego/network"
+	"github.com/ava-labs/avalanchego/snow/networking/benchlist"
+	"github.com/ava-labs/avalanchego/snow/networking/router"
+	"github.com/ava-labs/avalanchego/snow/validators"
+	"github.com/ava-labs/avalanchego/utils"
+	"github.com/ava-labs/avalanchego/utils/logging"
+	"github.com/ava-labs/avalanchego/version"
+)
+
+type testRouter struct{ router.Router }
+
+func (testRouter) Connected(ids.ShortID)    {}
+func (testRouter) Disconnected(ids.ShortID) {}
+
+func TestScriptsPassAgainstNetwork(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	addr := listener.Addr().(*net.TCPAddr)
+	networkID := uint32(12345)
+	vdrs := validators.NewSet()
+
+	n := network.NewDefaultNetwork(
+		prometheus.NewRegistry(),
+		logging.NoLog{},
+		ids.ShortID{1},
+		utils.NewDynamicIPDesc(addr.IP, uint16(addr.Port)),
+		networkID,
+		version.NewDefaultVersion("app", 0, 1, 0),
+		version.NewDefaultParser(),
+		[]net.Listener{listener},
+		network.NewDialer("tcp"),
+		network.NewIPUpgrader(),
+		network.NewIPUpgrader(),
+		vdrs,
+		vdrs,
+		testRouter{},
+		time.Duration(0),
+		0,
+		nil,
+		false,
+		0,
+		0,
+		time.Now(),
+		1<<10,
+		network.DefaultSendQueueConfig,
+		network.BandwidthConfig{},
+		network.DefaultGossipConfig,
+		network.DefaultConnectionConfig,
+		network.HealthConfig{},
+		benchlist.NewManager(&benchlist.Config{}),
+		memdb.New(),
+		time.Minute,
+		nil,
+	)
+	go func() { _ = n.Dispatch() }()
+	defer func() { assert.NoError(t, n.Close()) }()
+
+	results := RunAll(Config{
+		Address:        addr.String(),
+		Upgrader:       network.NewIPUpgrader(),
+		NetworkID:      networkID,
+		Version:        "app/0.1.0",
+		MaxMessageSize: network.DefaultMaxMessageSize,
+		Timeout:        5 * time.Second,
+	})
+	assert.Len(t, results, len(Scripts()))
+	for _, result := range results {
+		assert.NoError(t, result.Err, result.Script)
+	}
+}
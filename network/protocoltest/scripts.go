@@ -0,0 +1,148 @@
+// (c) 2019-2020, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package protocoltest
+
+import (
+	"time"
+
+	"github.com/ava-labs/avalanchego/ids"
+	"github.com/ava-labs/avalanchego/network"
+)
+
+// Clock difference that every node should refuse to handshake with
+const skewedClock = 24 * time.Hour
+
+// Script is a sequence of steps played over a single connection to the node
+type Script struct {
+	Name  string
+	Steps []Step
+}
+
+// Result of running a script against a node
+type Result struct {
+	Script string
+	// Nil if the node behaved as expected
+	Err error
+}
+
+// Scripts returns the scripts that every implementation of the wire protocol
+// should pass
+func Scripts() []Script {
+	ping := Send(func(b network.Builder) (network.Msg, error) { return b.Ping() })
+	return []Script{
+		{
+			Name: "sends version on connect",
+			Steps: []Step{
+				Expect(network.Version),
+			},
+		},
+		{
+			Name: "answers get version",
+			Steps: []Step{
+				Expect(network.Version),
+				Send(func(b network.Builder) (network.Msg, error) { return b.GetVersion() }),
+				Expect(network.Version),
+			},
+		},
+		{
+			Name: "answers ping before handshake",
+			Steps: []Step{
+				ping,
+				Expect(network.Pong),
+			},
+		},
+		{
+			Name: "completes handshake",
+			Steps: []Step{
+				Handshake(),
+				Expect(network.PeerList),
+				ping,
+				Expect(network.Pong),
+			},
+		},
+		{
+			Name: "asks for handshake on early consensus message",
+			Steps: []Step{
+				Expect(network.Version),
+				Send(func(b network.Builder) (network.Msg, error) {
+					return b.PullQuery(ids.Empty, 0, 0, ids.Empty)
+				}),
+				Expect(network.GetVersion),
+			},
+		},
+		{
+			Name: "ignores malformed message",
+			Steps: []Step{
+				SendRaw([]byte{0xff, 0xff, 0xff}),
+				ping,
+				Expect(network.Pong),
+			},
+		},
+		{
+			Name: "disconnects on wrong network ID",
+			Steps: []Step{
+				func(c *Conn) error { return SendVersion(c.config.NetworkID+1, 0)(c) },
+				ExpectDisconnect(),
+			},
+		},
+		{
+			Name: "disconnects on skewed clock",
+			Steps: []Step{
+				func(c *Conn) error { return SendVersion(c.config.NetworkID, skewedClock)(c) },
+				ExpectDisconnect(),
+			},
+		},
+		{
+			Name: "disconnects on oversized message",
+			Steps: []Step{
+				SendOversized(),
+				ExpectDisconnect(),
+			},
+		},
+		{
+			// The scripts never advertise any capabilities, so the node must
+			// keep sending plain frames
+			Name: "keeps plain framing with peers that don't advertise checksums",
+			Steps: []Step{
+				Handshake(),
+				Expect(network.PeerList),
+				ping,
+				Expect(network.Pong),
+				ping,
+				Expect(network.Pong),
+			},
+		},
+		{
+			Name: "disconnects on invalid checksum",
+			Steps: []Step{
+				SendBadChecksum(),
+				ExpectDisconnect(),
+			},
+		},
+	}
+}
+
+// Run [script] against the node described by [config]
+func Run(config Config, script Script) error {
+	c, err := Dial(config)
+	if err != nil {
+		return err
+	}
+	defer c.Close()
+
+	return Steps(script.Steps...)(c)
+}
+
+// RunAll runs every script in Scripts against the node described by [config]
+func RunAll(config Config) []Result {
+	scripts := Scripts()
+	results := make([]Result, len(scripts))
+	for i, script := range scripts {
+		results[i] = Result{
+			Script: script.Name,
+			Err:    Run(config, script),
+		}
+	}
+	return results
+}
@@ -0,0 +1,158 @@
+// (c) 2019-2020, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package protocoltest
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"math/rand"
+	"net"
+	"time"
+
+	"github.com/ava-labs/avalanchego/network"
+	"github.com/ava-labs/avalanchego/utils"
+)
+
+// Step is one action of a script, such as sending a message or expecting a
+// response. Returns an error if the node didn't behave as expected.
+type Step func(c *Conn) error
+
+// Send the message built by [build]
+func Send(build func(b network.Builder) (network.Msg, error)) Step {
+	return func(c *Conn) error {
+		msg, err := build(c.b)
+		if err != nil {
+			return fmt.Errorf("couldn't build message: %w", err)
+		}
+		if err := c.Send(msg); err != nil {
+			return fmt.Errorf("couldn't send %s: %w", msg.Op(), err)
+		}
+		return nil
+	}
+}
+
+// SendRaw sends [msgBytes] as a message, whether or not it's a valid message
+func SendRaw(msgBytes []byte) Step {
+	return func(c *Conn) error {
+		if err := c.SendRaw(msgBytes); err != nil {
+			return fmt.Errorf("couldn't send raw message: %w", err)
+		}
+		return nil
+	}
+}
+
+// SendOversized sends a message that's larger than the node accepts. Since the
+// node may disconnect before the whole message is sent, errors are ignored.
+func SendOversized() Step {
+	return func(c *Conn) error {
+		_ = c.SendRaw(make([]byte, c.config.MaxMessageSize+1))
+		return nil
+	}
+}
+
+// SendBadChecksum switches to checksummed frames and sends a Ping whose frame
+// has an invalid checksum
+func SendBadChecksum() Step {
+	return func(c *Conn) error {
+		msg, err := c.b.Ping()
+		if err != nil {
+			return fmt.Errorf("couldn't build message: %w", err)
+		}
+		frame := bytes.Buffer{}
+		if err := network.WriteChecksumsMarker(&frame); err != nil {
+			return err
+		}
+		if err := network.WriteFrame(&frame, msg.Bytes(), true); err != nil {
+			return err
+		}
+		frameBytes := frame.Bytes()
+		frameBytes[len(frameBytes)-1] ^= 1
+
+		if err := c.conn.SetWriteDeadline(time.Now().Add(c.config.Timeout)); err != nil {
+			return err
+		}
+		if _, err := c.conn.Write(frameBytes); err != nil {
+			return fmt.Errorf("couldn't send corrupted message: %w", err)
+		}
+		return nil
+	}
+}
+
+// SendVersion sends a Version message that claims to be on [networkID], and
+// whose clock is [clockSkew] ahead of ours
+func SendVersion(networkID uint32, clockSkew time.Duration) Step {
+	return func(c *Conn) error {
+		// The session ID only needs to differ from the node's own
+		sessionID := rand.Uint32() // #nosec G404
+		myTime := uint64(time.Now().Add(clockSkew).Unix())
+		return Send(func(b network.Builder) (network.Msg, error) {
+			return b.Version(networkID, sessionID, myTime, utils.IPDesc{IP: net.IPv4zero}, c.config.Version)
+		})(c)
+	}
+}
+
+// Handshake completes the handshake with the node by sending it a valid
+// Version and PeerList
+func Handshake() Step {
+	return Steps(
+		func(c *Conn) error { return SendVersion(c.config.NetworkID, 0)(c) },
+		Send(func(b network.Builder) (network.Msg, error) { return b.PeerList(nil) }),
+	)
+}
+
+// Steps runs [steps] in order as a single step
+func Steps(steps ...Step) Step {
+	return func(c *Conn) error {
+		for _, step := range steps {
+			if err := step(c); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+}
+
+// Expect the node to send a message with [op]. Other messages sent by the node
+// before it are ignored.
+func Expect(op network.Op) Step {
+	return func(c *Conn) error {
+		for {
+			msg, err := c.Receive()
+			switch {
+			case isTimeout(err):
+				return fmt.Errorf("node didn't send %s", op)
+			case errors.Is(err, errMalformedMessage):
+				return err
+			case err != nil:
+				return fmt.Errorf("%w while waiting for %s: %s", errUnexpectedDisconnect, op, err)
+			case msg.Op() == op:
+				return nil
+			}
+		}
+	}
+}
+
+// ExpectDisconnect expects the node to close the connection. Messages sent by
+// the node before it does are ignored.
+func ExpectDisconnect() Step {
+	return func(c *Conn) error {
+		for {
+			_, err := c.Receive()
+			switch {
+			case isTimeout(err):
+				return errNoDisconnect
+			case errors.Is(err, errMalformedMessage):
+				return err
+			case err != nil:
+				return nil
+			}
+		}
+	}
+}
+
+func isTimeout(err error) bool {
+	netErr, ok := err.(net.Error)
+	return ok && netErr.Timeout()
+}
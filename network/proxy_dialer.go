@@ -0,0 +1,274 @@
+// (c) 2019-2020, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package network
+
+import (
+	"bufio"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"golang.org/x/net/proxy"
+
+	"github.com/ava-labs/avalanchego/utils"
+	"github.com/ava-labs/avalanchego/utils/constants"
+	"github.com/ava-labs/avalanchego/utils/wrappers"
+)
+
+const (
+	// ProxySOCKS5 is the URL scheme of a SOCKS5 proxy
+	ProxySOCKS5 = "socks5"
+	// ProxyHTTP is the URL scheme of a proxy supporting HTTP CONNECT
+	ProxyHTTP = "http"
+)
+
+var (
+	errUnknownProxyScheme = errors.New("unknown proxy scheme")
+	errMissingProxyHost   = errors.New("proxy address is missing a host")
+	errUnhealthyProxy     = errors.New("couldn't reach one or more proxies")
+)
+
+// ProxyConfig describes which proxies, if any, should be used to dial peers.
+type ProxyConfig struct {
+	// Proxy used to dial peers that don't have an entry in [PeerProxies]. If
+	// nil, those peers are dialed directly.
+	DefaultProxy *url.URL
+
+	// Peer IP:port --> proxy used to dial that peer
+	PeerProxies map[string]*url.URL
+
+	// Maximum amount of time to spend establishing a connection through a
+	// proxy. 0 means no timeout.
+	DialTimeout time.Duration
+}
+
+// Enabled returns true if at least one proxy is configured
+func (c *ProxyConfig) Enabled() bool { return c.DefaultProxy != nil || len(c.PeerProxies) > 0 }
+
+// ProxyDialer is a Dialer that is able to route connections through SOCKS5 or
+// HTTP CONNECT proxies.
+type ProxyDialer interface {
+	Dialer
+
+	// HealthCheck reports whether each configured proxy is reachable
+	HealthCheck() (interface{}, error)
+}
+
+type proxyMetrics struct {
+	numDials, numDialFailures prometheus.Counter
+	numUnhealthyProxies       prometheus.Gauge
+}
+
+func (m *proxyMetrics) initialize(registerer prometheus.Registerer) error {
+	m.numDials = prometheus.NewCounter(prometheus.CounterOpts{
+		Namespace: constants.PlatformName,
+		Name:      "proxy_dials",
+		Help:      "Number of connections attempted through a proxy",
+	})
+	m.numDialFailures = prometheus.NewCounter(prometheus.CounterOpts{
+		Namespace: constants.PlatformName,
+		Name:      "proxy_dial_failures",
+		Help:      "Number of connections attempted through a proxy that failed",
+	})
+	m.numUnhealthyProxies = prometheus.NewGauge(prometheus.GaugeOpts{
+		Namespace: constants.PlatformName,
+		Name:      "proxy_unhealthy",
+		Help:      "Number of configured proxies that were unreachable during the last health check",
+	})
+
+	errs := wrappers.Errs{}
+	errs.Add(
+		registerer.Register(m.numDials),
+		registerer.Register(m.numDialFailures),
+		registerer.Register(m.numUnhealthyProxies),
+	)
+	return errs.Err
+}
+
+type proxyDialer struct {
+	network string
+	config  ProxyConfig
+	metrics proxyMetrics
+}
+
+// NewProxyDialer returns a new ProxyDialer that dials peers over [network]
+// using the proxies described by [config].
+func NewProxyDialer(network string, config ProxyConfig, registerer prometheus.Registerer) (ProxyDialer, error) {
+	if config.DefaultProxy != nil {
+		if err := verifyProxyURL(config.DefaultProxy); err != nil {
+			return nil, fmt.Errorf("invalid default proxy: %w", err)
+		}
+	}
+	for peerIP, proxyURL := range config.PeerProxies {
+		if err := verifyProxyURL(proxyURL); err != nil {
+			return nil, fmt.Errorf("invalid proxy for peer %s: %w", peerIP, err)
+		}
+	}
+
+	d := &proxyDialer{
+		network: network,
+		config:  config,
+	}
+	return d, d.metrics.initialize(registerer)
+}
+
+func verifyProxyURL(proxyURL *url.URL) error {
+	switch {
+	case proxyURL.Scheme != ProxySOCKS5 && proxyURL.Scheme != ProxyHTTP:
+		return fmt.Errorf("%w: %q", errUnknownProxyScheme, proxyURL.Scheme)
+	case proxyURL.Host == "":
+		return errMissingProxyHost
+	default:
+		return nil
+	}
+}
+
+func (d *proxyDialer) Dial(ip utils.IPDesc) (net.Conn, error) {
+	addr := ip.String()
+	proxyURL, ok := d.config.PeerProxies[addr]
+	if !ok {
+		proxyURL = d.config.DefaultProxy
+	}
+	if proxyURL == nil {
+		return net.Dial(d.network, addr)
+	}
+
+	d.metrics.numDials.Inc()
+	conn, err := d.dialThroughProxy(proxyURL, addr)
+	if err != nil {
+		d.metrics.numDialFailures.Inc()
+		return nil, fmt.Errorf("couldn't dial %s through proxy %s: %w", addr, proxyURL.Host, err)
+	}
+	return &proxiedConn{
+		Conn:       conn,
+		reader:     conn,
+		remoteAddr: &net.TCPAddr{IP: ip.IP, Port: int(ip.Port)},
+	}, nil
+}
+
+func (d *proxyDialer) dialThroughProxy(proxyURL *url.URL, addr string) (net.Conn, error) {
+	switch proxyURL.Scheme {
+	case ProxySOCKS5:
+		var auth *proxy.Auth
+		if proxyURL.User != nil {
+			password, _ := proxyURL.User.Password()
+			auth = &proxy.Auth{
+				User:     proxyURL.User.Username(),
+				Password: password,
+			}
+		}
+		socksDialer, err := proxy.SOCKS5(d.network, proxyURL.Host, auth, &net.Dialer{Timeout: d.config.DialTimeout})
+		if err != nil {
+			return nil, err
+		}
+		return socksDialer.Dial(d.network, addr)
+	case ProxyHTTP:
+		return d.dialHTTPConnect(proxyURL, addr)
+	default:
+		return nil, fmt.Errorf("%w: %q", errUnknownProxyScheme, proxyURL.Scheme)
+	}
+}
+
+// dialHTTPConnect opens a tunnel to [addr] by issuing an HTTP CONNECT request
+// to the proxy at [proxyURL].
+func (d *proxyDialer) dialHTTPConnect(proxyURL *url.URL, addr string) (net.Conn, error) {
+	conn, err := net.DialTimeout(d.network, proxyURL.Host, d.config.DialTimeout)
+	if err != nil {
+		return nil, err
+	}
+	if d.config.DialTimeout > 0 {
+		if err := conn.SetDeadline(time.Now().Add(d.config.DialTimeout)); err != nil {
+			_ = conn.Close()
+			return nil, err
+		}
+	}
+
+	req := &http.Request{
+		Method: http.MethodConnect,
+		URL:    &url.URL{Opaque: addr},
+		Host:   addr,
+		Header: make(http.Header),
+	}
+	if proxyURL.User != nil {
+		password, _ := proxyURL.User.Password()
+		credentials := proxyURL.User.Username() + ":" + password
+		req.Header.Set("Proxy-Authorization", "Basic "+base64.StdEncoding.EncodeToString([]byte(credentials)))
+	}
+	if err := req.Write(conn); err != nil {
+		_ = conn.Close()
+		return nil, err
+	}
+
+	reader := bufio.NewReader(conn)
+	resp, err := http.ReadResponse(reader, req)
+	if err != nil {
+		_ = conn.Close()
+		return nil, err
+	}
+	_ = resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		_ = conn.Close()
+		return nil, fmt.Errorf("proxy responded with %q", resp.Status)
+	}
+
+	if err := conn.SetDeadline(time.Time{}); err != nil {
+		_ = conn.Close()
+		return nil, err
+	}
+	return &proxiedConn{Conn: conn, reader: reader}, nil
+}
+
+// HealthCheck attempts to open a connection to every configured proxy
+func (d *proxyDialer) HealthCheck() (interface{}, error) {
+	proxyHosts := make(map[string]struct{}, len(d.config.PeerProxies)+1)
+	if d.config.DefaultProxy != nil {
+		proxyHosts[d.config.DefaultProxy.Host] = struct{}{}
+	}
+	for _, proxyURL := range d.config.PeerProxies {
+		proxyHosts[proxyURL.Host] = struct{}{}
+	}
+
+	details := make(map[string]interface{}, len(proxyHosts))
+	numUnhealthy := 0
+	for host := range proxyHosts {
+		conn, err := net.DialTimeout(d.network, host, d.config.DialTimeout)
+		if err != nil {
+			numUnhealthy++
+			details[host] = err.Error()
+			continue
+		}
+		_ = conn.Close()
+		details[host] = "reachable"
+	}
+	d.metrics.numUnhealthyProxies.Set(float64(numUnhealthy))
+
+	if numUnhealthy > 0 {
+		return details, errUnhealthyProxy
+	}
+	return details, nil
+}
+
+// proxiedConn is a connection tunneled through a proxy. Reads are served from
+// [reader] so that any bytes buffered while reading the proxy's response aren't
+// lost. If [remoteAddr] is set, it is reported instead of the proxy's address.
+type proxiedConn struct {
+	net.Conn
+	reader     io.Reader
+	remoteAddr net.Addr
+}
+
+func (c *proxiedConn) Read(b []byte) (int, error) { return c.reader.Read(b) }
+
+func (c *proxiedConn) RemoteAddr() net.Addr {
+	if c.remoteAddr != nil {
+		return c.remoteAddr
+	}
+	return c.Conn.RemoteAddr()
+}
@@ -0,0 +1,146 @@
+// (c) 2019-2020, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package network
+
+import (
+	"bufio"
+	"errors"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/ava-labs/avalanchego/utils"
+)
+
+// serveHTTPConnect accepts a single connection on [listener], responds to its
+// CONNECT request with [status] and, on success, writes [payload] through the
+// tunnel.
+func serveHTTPConnect(t *testing.T, listener net.Listener, status int, payload string) <-chan *http.Request {
+	requests := make(chan *http.Request, 1)
+	go func() {
+		conn, err := listener.Accept()
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		defer conn.Close()
+
+		req, err := http.ReadRequest(bufio.NewReader(conn))
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		requests <- req
+
+		resp := &http.Response{
+			StatusCode: status,
+			ProtoMajor: 1,
+			ProtoMinor: 1,
+			Header:     make(http.Header),
+		}
+		if err := resp.Write(conn); err != nil {
+			t.Error(err)
+			return
+		}
+		if status == http.StatusOK {
+			_, _ = conn.Write([]byte(payload))
+		}
+	}()
+	return requests
+}
+
+func TestProxyDialerHTTPConnect(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	assert.NoError(t, err)
+	defer listener.Close()
+
+	requests := serveHTTPConnect(t, listener, http.StatusOK, "hello")
+
+	d, err := NewProxyDialer("tcp", ProxyConfig{
+		DefaultProxy: &url.URL{
+			Scheme: ProxyHTTP,
+			Host:   listener.Addr().String(),
+			User:   url.UserPassword("user", "pass"),
+		},
+		DialTimeout: time.Second,
+	}, prometheus.NewRegistry())
+	assert.NoError(t, err)
+
+	peerIP := utils.IPDesc{IP: net.IPv4(10, 0, 0, 1), Port: 9651}
+	conn, err := d.Dial(peerIP)
+	assert.NoError(t, err)
+	defer conn.Close()
+
+	req := <-requests
+	assert.Equal(t, http.MethodConnect, req.Method)
+	assert.Equal(t, peerIP.String(), req.Host)
+	assert.Equal(t, "Basic dXNlcjpwYXNz", req.Header.Get("Proxy-Authorization"))
+
+	payload, err := ioutil.ReadAll(conn)
+	assert.NoError(t, err)
+	assert.Equal(t, "hello", string(payload))
+
+	// The connection should report the peer's address rather than the proxy's
+	assert.Equal(t, peerIP.String(), conn.RemoteAddr().String())
+}
+
+func TestProxyDialerHTTPConnectRejected(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	assert.NoError(t, err)
+	defer listener.Close()
+
+	_ = serveHTTPConnect(t, listener, http.StatusForbidden, "")
+
+	peerIP := utils.IPDesc{IP: net.IPv4(10, 0, 0, 1), Port: 9651}
+	d, err := NewProxyDialer("tcp", ProxyConfig{
+		PeerProxies: map[string]*url.URL{
+			peerIP.String(): {Scheme: ProxyHTTP, Host: listener.Addr().String()},
+		},
+		DialTimeout: time.Second,
+	}, prometheus.NewRegistry())
+	assert.NoError(t, err)
+
+	_, err = d.Dial(peerIP)
+	assert.Error(t, err)
+}
+
+func TestProxyDialerInvalidConfig(t *testing.T) {
+	_, err := NewProxyDialer("tcp", ProxyConfig{
+		DefaultProxy: &url.URL{Scheme: "ftp", Host: "127.0.0.1:1080"},
+	}, prometheus.NewRegistry())
+	assert.True(t, errors.Is(err, errUnknownProxyScheme))
+
+	_, err = NewProxyDialer("tcp", ProxyConfig{
+		PeerProxies: map[string]*url.URL{
+			localhost: {Scheme: ProxySOCKS5},
+		},
+	}, prometheus.NewRegistry())
+	assert.True(t, errors.Is(err, errMissingProxyHost))
+}
+
+func TestProxyDialerHealthCheck(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	assert.NoError(t, err)
+	proxyURL := &url.URL{Scheme: ProxySOCKS5, Host: listener.Addr().String()}
+
+	d, err := NewProxyDialer("tcp", ProxyConfig{
+		DefaultProxy: proxyURL,
+		DialTimeout:  time.Second,
+	}, prometheus.NewRegistry())
+	assert.NoError(t, err)
+
+	_, err = d.HealthCheck()
+	assert.NoError(t, err)
+
+	assert.NoError(t, listener.Close())
+
+	_, err = d.HealthCheck()
+	assert.Equal(t, errUnhealthyProxy, err)
+}
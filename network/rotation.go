@@ -0,0 +1,128 @@
+// (c) 2019-2020, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package network
+
+import (
+	"crypto"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/ava-labs/avalanchego/ids"
+	"github.com/ava-labs/avalanchego/utils/hashing"
+	"github.com/ava-labs/avalanchego/utils/wrappers"
+)
+
+// MaxIdentityRotationOverlap is the longest that a node may be recognized by
+// its previous ID after rotating its staking certificate. Rotations that
+// expire further in the future are rejected, so that a leaked previous key
+// can't be used to impersonate the previous ID indefinitely.
+const MaxIdentityRotationOverlap = 7 * 24 * time.Hour
+
+var (
+	errRotationExpired        = errors.New("identity rotation expired")
+	errRotationTooLong        = errors.New("identity rotation expires too far in the future")
+	errRotationToSameIdentity = errors.New("identity rotation doesn't change the identity")
+	errUnsupportedRotationKey = errors.New("unsupported identity rotation key")
+	errNoRotationCert         = errors.New("no certificate to rotate from")
+)
+
+// IdentityRotation is a statement, signed by the key of a node's previous
+// staking certificate, that the node's new ID may be recognized as the
+// previous ID until [Expiry]. This allows a node to rotate its staking
+// certificate without downtime, while the validator set still refers to its
+// previous ID.
+type IdentityRotation struct {
+	// Raw previous staking certificate
+	PreviousCert []byte
+	// Signature of the new node ID and [Expiry] by the previous certificate's
+	// key
+	Signature []byte
+	// Unix time, in seconds, after which the previous ID isn't recognized
+	Expiry uint64
+}
+
+// NewIdentityRotation returns a statement that [previous] has rotated to
+// [nodeID] until [expiry], which must be within MaxIdentityRotationOverlap of
+// now
+func NewIdentityRotation(previous tls.Certificate, nodeID ids.ShortID, expiry time.Time) (*IdentityRotation, error) {
+	if maxExpiry := time.Now().Add(MaxIdentityRotationOverlap); expiry.After(maxExpiry) {
+		return nil, fmt.Errorf("%w: %s is after %s", errRotationTooLong, expiry, maxExpiry)
+	}
+	if len(previous.Certificate) == 0 {
+		return nil, errNoRotationCert
+	}
+	signer, ok := previous.PrivateKey.(crypto.Signer)
+	if !ok {
+		return nil, errUnsupportedRotationKey
+	}
+
+	r := &IdentityRotation{
+		PreviousCert: previous.Certificate[0],
+		Expiry:       uint64(expiry.Unix()),
+	}
+	digest := hashing.ComputeHash256(r.signedBytes(nodeID))
+	sig, err := signer.Sign(rand.Reader, digest, crypto.SHA256)
+	if err != nil {
+		return nil, fmt.Errorf("couldn't sign identity rotation: %w", err)
+	}
+	r.Signature = sig
+	return r, nil
+}
+
+// PreviousID returns the node ID of the previous staking certificate
+func (r *IdentityRotation) PreviousID() ids.ShortID {
+	return CertToID(r.PreviousCert)
+}
+
+// Verify that the previous staking certificate rotated to [nodeID], and that
+// the rotation hasn't expired at [now] and doesn't expire more than
+// MaxIdentityRotationOverlap after [now]. Returns the previous node ID.
+func (r *IdentityRotation) Verify(nodeID ids.ShortID, now time.Time) (ids.ShortID, error) {
+	if uint64(now.Unix()) >= r.Expiry {
+		return ids.ShortID{}, errRotationExpired
+	}
+	if maxExpiry := now.Add(MaxIdentityRotationOverlap); r.ExpiryTime().After(maxExpiry) {
+		return ids.ShortID{}, fmt.Errorf("%w: %s is after %s", errRotationTooLong, r.ExpiryTime(), maxExpiry)
+	}
+
+	cert, err := x509.ParseCertificate(r.PreviousCert)
+	if err != nil {
+		return ids.ShortID{}, fmt.Errorf("couldn't parse previous certificate: %w", err)
+	}
+
+	var algorithm x509.SignatureAlgorithm
+	switch cert.PublicKeyAlgorithm {
+	case x509.RSA:
+		algorithm = x509.SHA256WithRSA
+	case x509.ECDSA:
+		algorithm = x509.ECDSAWithSHA256
+	default:
+		return ids.ShortID{}, fmt.Errorf("%w: %s", errUnsupportedRotationKey, cert.PublicKeyAlgorithm)
+	}
+	if err := cert.CheckSignature(algorithm, r.signedBytes(nodeID), r.Signature); err != nil {
+		return ids.ShortID{}, fmt.Errorf("invalid identity rotation signature: %w", err)
+	}
+
+	previousID := r.PreviousID()
+	if previousID == nodeID {
+		return ids.ShortID{}, errRotationToSameIdentity
+	}
+	return previousID, nil
+}
+
+// ExpiryTime returns the time at which the rotation expires
+func (r *IdentityRotation) ExpiryTime() time.Time {
+	return time.Unix(int64(r.Expiry), 0)
+}
+
+func (r *IdentityRotation) signedBytes(nodeID ids.ShortID) []byte {
+	p := wrappers.Packer{MaxSize: hashing.AddrLen + wrappers.LongLen}
+	p.PackFixedBytes(nodeID[:])
+	p.PackLong(r.Expiry)
+	return p.Bytes
+}
@@ -0,0 +1,126 @@
+// (c) 2019-2020, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package network
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/ava-labs/avalanchego/ids"
+	"github.com/ava-labs/avalanchego/utils/logging"
+)
+
+// newTestCert returns a self-signed staking certificate
+func newTestCert(t *testing.T) tls.Certificate {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(0),
+		NotBefore:    time.Date(2000, time.January, 0, 0, 0, 0, 0, time.UTC),
+		NotAfter:     time.Now().AddDate(100, 0, 0),
+	}
+	certBytes, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return tls.Certificate{
+		Certificate: [][]byte{certBytes},
+		PrivateKey:  key,
+	}
+}
+
+func TestIdentityRotation(t *testing.T) {
+	assert := assert.New(t)
+
+	cert := newTestCert(t)
+	nodeID := ids.GenerateTestShortID()
+	now := time.Now()
+
+	rotation, err := NewIdentityRotation(cert, nodeID, now.Add(time.Hour))
+	assert.NoError(err)
+
+	previousID, err := rotation.Verify(nodeID, now)
+	assert.NoError(err)
+	assert.Equal(rotation.PreviousID(), previousID)
+
+	_, err = rotation.Verify(nodeID, now.Add(time.Hour))
+	assert.True(errors.Is(err, errRotationExpired), "shouldn't be recognized once expired")
+
+	_, err = rotation.Verify(ids.GenerateTestShortID(), now)
+	assert.Error(err, "shouldn't be recognized for a different node ID")
+
+	rotation.Expiry++
+	_, err = rotation.Verify(nodeID, now)
+	assert.Error(err, "shouldn't be recognized with a different expiry")
+}
+
+func TestIdentityRotationMaxOverlap(t *testing.T) {
+	assert := assert.New(t)
+
+	cert := newTestCert(t)
+	nodeID := ids.GenerateTestShortID()
+	now := time.Now()
+
+	_, err := NewIdentityRotation(cert, nodeID, now.Add(2*MaxIdentityRotationOverlap))
+	assert.True(errors.Is(err, errRotationTooLong), "shouldn't create a rotation that overlaps for too long")
+
+	rotation, err := NewIdentityRotation(cert, nodeID, now.Add(MaxIdentityRotationOverlap/2))
+	assert.NoError(err)
+
+	_, err = rotation.Verify(nodeID, now.Add(-MaxIdentityRotationOverlap))
+	assert.True(errors.Is(err, errRotationTooLong), "shouldn't be recognized for longer than the max overlap")
+	_, err = rotation.Verify(nodeID, now)
+	assert.NoError(err)
+}
+
+func TestAddPreviousIDOnlyOnce(t *testing.T) {
+	assert := assert.New(t)
+
+	n := &network{
+		log:         logging.NoLog{},
+		peers:       make(map[ids.ShortID]*peer),
+		previousIDs: make(map[ids.ShortID]*peer),
+	}
+	p0 := &peer{net: n, id: ids.GenerateTestShortID()}
+	p1 := &peer{net: n, id: ids.GenerateTestShortID()}
+	n.peers[p0.id] = p0
+	n.peers[p1.id] = p1
+
+	previousID := ids.GenerateTestShortID()
+	expiry := time.Now().Add(time.Hour)
+	n.addPreviousID(p0, previousID, expiry)
+	n.addPreviousID(p1, previousID, expiry)
+	assert.Equal(p0, n.peerByID(previousID), "a second rotation away from the same ID shouldn't be honoured")
+	assert.Equal(ids.ShortEmpty, p1.previousID)
+
+	// Once the first rotation expires, the previous ID may be rotated again
+	n.clock.Set(expiry)
+	n.addPreviousID(p1, previousID, expiry.Add(time.Hour))
+	assert.Equal(p1, n.peerByID(previousID))
+}
+
+func TestIdentityRotationSignedByOtherKey(t *testing.T) {
+	cert := newTestCert(t)
+	nodeID := ids.GenerateTestShortID()
+	now := time.Now()
+
+	rotation, err := NewIdentityRotation(cert, nodeID, now.Add(time.Hour))
+	assert.NoError(t, err)
+
+	// Claim another node's certificate
+	rotation.PreviousCert = newTestCert(t).Certificate[0]
+	_, err = rotation.Verify(nodeID, now)
+	assert.Error(t, err)
+}
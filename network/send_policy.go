@@ -0,0 +1,193 @@
+// (c) 2019-2020, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package network
+
+import (
+	"fmt"
+	"time"
+)
+
+const (
+	// Low priority messages may only fill 1/[lowPriorityQueuePortion] of a
+	// peer's send queue, so that there is room left for other messages when
+	// the queue fills.
+	lowPriorityQueuePortion = 2
+
+	// A peer is reported as chronically dropping messages if messages to it
+	// have been dropped for at least [chronicDropDuration], without a gap of
+	// more than [chronicDropGap] between drops.
+	chronicDropDuration = 5 * time.Minute
+	chronicDropGap      = 30 * time.Second
+)
+
+// sendPriority determines which messages are dropped first when a peer's send
+// queue fills
+type sendPriority byte
+
+const (
+	// lowSendPriority messages, such as gossip, are dropped before a peer's
+	// send queue is full. Missing one of these messages only delays
+	// information that will be sent again.
+	lowSendPriority sendPriority = iota
+	// highSendPriority messages, such as consensus messages, are only dropped
+	// once a peer's send queue is full
+	highSendPriority
+)
+
+// opSendPriority returns the priority of sending messages of type [op]
+func opSendPriority(op Op) sendPriority {
+	switch op {
+	case GossipTxs, GetPeerList, PeerList:
+		return lowSendPriority
+	default:
+		return highSendPriority
+	}
+}
+
+// sendClass determines the order in which the messages queued on behalf of
+// chains are sent. All queued messages of a class are sent before any message
+// of a later class.
+type sendClass byte
+
+const (
+	// consensusSendClass messages, such as queries and chits, are sent first
+	// so that polls finish quickly
+	consensusSendClass sendClass = iota
+	// gossipSendClass messages are sent once no consensus messages are queued
+	gossipSendClass
+	// bootstrapSendClass messages, such as MultiPuts, are large and only
+	// needed by bootstrapping peers, so they're sent last
+	bootstrapSendClass
+
+	numSendClasses
+)
+
+// opSendClass returns the class of messages of type [op]
+func opSendClass(op Op) sendClass {
+	switch op {
+	case GossipTxs:
+		return gossipSendClass
+	case GetAcceptedFrontier, AcceptedFrontier, GetAccepted, Accepted, GetAncestors, MultiPut:
+		return bootstrapSendClass
+	default:
+		return consensusSendClass
+	}
+}
+
+// DropPolicy determines which message is dropped when a send class has filled
+// its portion of a chain's send window
+type DropPolicy byte
+
+const (
+	// DropNewest drops the message that didn't fit
+	DropNewest DropPolicy = iota
+	// DropOldest drops the oldest queued messages of the class to make room
+	// for the message that didn't fit
+	DropOldest
+)
+
+// ParseDropPolicy returns the DropPolicy named [s]
+func ParseDropPolicy(s string) (DropPolicy, error) {
+	switch s {
+	case "newest":
+		return DropNewest, nil
+	case "oldest":
+		return DropOldest, nil
+	default:
+		return 0, fmt.Errorf("unknown drop policy %q", s)
+	}
+}
+
+func (d DropPolicy) String() string {
+	switch d {
+	case DropNewest:
+		return "newest"
+	case DropOldest:
+		return "oldest"
+	default:
+		return "unknown"
+	}
+}
+
+// SendClassConfig describes how much of a chain's send window messages of a
+// class may fill, and what happens once they've filled it
+type SendClassConfig struct {
+	// Portion of the send window that messages of this class may fill.
+	// Must be in (0,1]
+	MaxPortion float64
+
+	// Policy applied when a message doesn't fit in the send window
+	DropPolicy DropPolicy
+}
+
+// SendQueueConfig describes the send window of each class of messages
+type SendQueueConfig struct {
+	Consensus, Gossip, Bootstrap SendClassConfig
+}
+
+// DefaultSendQueueConfig lets consensus messages fill a chain's whole send
+// window, and leaves room for them by limiting gossip and bootstrapping
+// messages to half of it
+var DefaultSendQueueConfig = SendQueueConfig{
+	Consensus: SendClassConfig{MaxPortion: 1},
+	Gossip:    SendClassConfig{MaxPortion: 1. / lowPriorityQueuePortion},
+	Bootstrap: SendClassConfig{MaxPortion: 1. / lowPriorityQueuePortion},
+}
+
+// class returns the configuration of [class]
+func (c *SendQueueConfig) class(class sendClass) SendClassConfig {
+	switch class {
+	case gossipSendClass:
+		return c.Gossip
+	case bootstrapSendClass:
+		return c.Bootstrap
+	default:
+		return c.Consensus
+	}
+}
+
+// dropStats tracks the messages dropped because a peer's send queue was full
+type dropStats struct {
+	// Op --> number of messages of that type that were dropped
+	counts map[Op]uint64
+
+	// Time of the first drop since which there hasn't been a gap of more than
+	// [chronicDropGap] between drops
+	streakStart time.Time
+	// Time of the last drop
+	lastDrop time.Time
+}
+
+// Add records that a message of type [op] was dropped at [now]
+func (d *dropStats) Add(op Op, now time.Time) {
+	if d.counts == nil {
+		d.counts = make(map[Op]uint64)
+	}
+	d.counts[op]++
+
+	if now.Sub(d.lastDrop) > chronicDropGap {
+		d.streakStart = now
+	}
+	d.lastDrop = now
+}
+
+// Chronic returns true if messages have been dropped continuously for at
+// least [chronicDropDuration] as of [now]
+func (d *dropStats) Chronic(now time.Time) bool {
+	return !d.lastDrop.IsZero() &&
+		now.Sub(d.lastDrop) <= chronicDropGap &&
+		d.lastDrop.Sub(d.streakStart) >= chronicDropDuration
+}
+
+// Counts returns the number of dropped messages of each type
+func (d *dropStats) Counts() map[string]uint64 {
+	if len(d.counts) == 0 {
+		return nil
+	}
+	counts := make(map[string]uint64, len(d.counts))
+	for op, count := range d.counts {
+		counts[op.String()] = count
+	}
+	return counts
+}
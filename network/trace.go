@@ -0,0 +1,163 @@
+// (c) 2019-2020, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package network
+
+import (
+	"sync/atomic"
+
+	"github.com/ava-labs/avalanchego/ids"
+)
+
+// Capabilities that a peer may advertise during the handshake
+const (
+	// capabilityTracing means that the peer understands Traced messages
+	capabilityTracing uint32 = 1 << iota
+	// capabilityParentHints means that the peer understands HintedPut
+	// messages
+	capabilityParentHints
+	// capabilityGzip means that the peer understands Compressed messages
+	// compressed with gzip
+	capabilityGzip
+	// capabilityTimedPong means that the peer understands TimedPong messages
+	capabilityTimedPong
+	// capabilityChecksums means that the peer reads checksummed frames after
+	// the marker
+	capabilityChecksums
+	// capabilityJustifiedChits means that the peer understands JustifiedChits
+	// messages
+	capabilityJustifiedChits
+)
+
+const (
+	// localCapabilities are the capabilities that this node advertises
+	localCapabilities = capabilityTracing | capabilityParentHints | capabilityGzip | capabilityTimedPong | capabilityChecksums |
+		capabilityJustifiedChits
+
+	// traceCacheSize is the number of inbound requests, per peer, whose trace
+	// IDs are remembered until they're responded to
+	traceCacheSize = 1024
+)
+
+// traceKey identifies a request received from a peer
+type traceKey struct {
+	chainID   ids.ID
+	requestID uint32
+}
+
+// tracedOps are the messages that may be wrapped in a Traced message
+var tracedOps = map[Op]bool{
+	GetAcceptedFrontier: true,
+	AcceptedFrontier:    true,
+	GetAccepted:         true,
+	Accepted:            true,
+	GetAncestors:        true,
+	MultiPut:            true,
+	Get:                 true,
+	Put:                 true,
+	HintedPut:           true,
+	PushQuery:           true,
+	PullQuery:           true,
+	Chits:               true,
+	JustifiedChits:      true,
+}
+
+// newTraceID returns the ID used to correlate a request sent by this node with
+// the responses to it
+func (n *network) newTraceID() uint64 {
+	return atomic.AddUint64(&n.lastTraceID, 1)
+}
+
+// tracedMsg wraps [msg] with [traceID] if the peer supports tracing
+func (p *peer) tracedMsg(msg Msg, traceID uint64) Msg {
+	if atomic.LoadUint32(&p.peerCapabilities)&capabilityTracing == 0 {
+		return msg
+	}
+	traced, err := p.net.b.Traced(traceID, msg)
+	if err != nil {
+		p.net.log.Debug("failed to trace %s message to %s due to %s", msg.Op(), p.id, err)
+		return msg
+	}
+	return traced
+}
+
+// SendRequest sends the request [msg] to this peer, tagged with [traceID] if
+// the peer supports tracing.
+// assumes the [stateLock] is not held
+func (p *peer) SendRequest(msg Msg, traceID uint64) bool {
+	if !p.Send(p.tracedMsg(msg, traceID)) {
+		return false
+	}
+	p.net.log.Verbo("sent %s message to %s with trace ID %d", msg.Op(), p.id, traceID)
+	return true
+}
+
+// SendResponse sends the response [msg] to this peer. If the request that it
+// responds to was traced, the response is tagged with the same trace ID.
+// assumes the [stateLock] is not held
+func (p *peer) SendResponse(msg Msg, chainID ids.ID, requestID uint32) bool {
+	key := traceKey{
+		chainID:   chainID,
+		requestID: requestID,
+	}
+	traceIntf, ok := p.traces.Get(key)
+	if !ok {
+		return p.Send(msg)
+	}
+	p.traces.Evict(key)
+
+	traceID := traceIntf.(uint64)
+	if !p.Send(p.tracedMsg(msg, traceID)) {
+		return false
+	}
+	p.net.log.Verbo("sent %s message to %s with trace ID %d", msg.Op(), p.id, traceID)
+	return true
+}
+
+// assumes the [stateLock] is not held
+func (p *peer) Capabilities() {
+	msg, err := p.net.b.Capabilities(localCapabilities)
+	p.net.log.AssertNoError(err)
+	if p.Send(msg) {
+		p.net.capabilities.numSent.Inc()
+		p.net.capabilities.sentBytes.Add(float64(len(msg.Bytes())))
+		p.net.sendFailRateCalculator.Observe(0, p.net.clock.Time())
+	} else {
+		p.net.capabilities.numFailed.Inc()
+		p.net.sendFailRateCalculator.Observe(1, p.net.clock.Time())
+	}
+}
+
+// assumes the [stateLock] is not held
+func (p *peer) capabilities(msg Msg) {
+	atomic.StoreUint32(&p.peerCapabilities, msg.Get(CapabilityFlags).(uint32))
+}
+
+// assumes the [stateLock] is not held
+func (p *peer) traced(msg Msg) {
+	traceID := msg.Get(TraceID).(uint64)
+	inner, err := p.net.b.Parse(msg.Get(TracedMsg).([]byte))
+	if err != nil {
+		p.net.log.Debug("failed to parse message from %s with trace ID %d due to %s", p.id, traceID, err)
+		return
+	}
+	op := inner.Op()
+	if !tracedOps[op] {
+		p.net.log.Debug("dropping traced %s message from %s with trace ID %d", op, p.id, traceID)
+		return
+	}
+
+	chainID, err := ids.ToID(inner.Get(ChainID).([]byte))
+	p.net.log.AssertNoError(err)
+	requestID := inner.Get(RequestID).(uint32)
+	p.net.log.Debug("received %s(%s, %d) from %s with trace ID %d", op, chainID, requestID, p.id, traceID)
+
+	if _, ok := inner.Get(Deadline).(uint64); ok {
+		// [inner] is a request, so its response should echo [traceID]
+		p.traces.Put(traceKey{
+			chainID:   chainID,
+			requestID: requestID,
+		}, traceID)
+	}
+	p.handle(inner)
+}
@@ -13,9 +13,18 @@ import (
 )
 
 var (
-	errNoCert = errors.New("tls handshake finished with no peer certificate")
+	errNoCert         = errors.New("tls handshake finished with no peer certificate")
+	errPeerIDMismatch = errors.New("peer's node ID doesn't match the expected node ID")
 )
 
+// CertToID returns the node ID of the staking certificate [cert], which is the
+// raw DER encoding of the certificate
+func CertToID(cert []byte) ids.ShortID {
+	return ids.ShortID(
+		hashing.ComputeHash160Array(
+			hashing.ComputeHash256(cert)))
+}
+
 // Upgrader ...
 type Upgrader interface {
 	// Must be thread safe
@@ -46,20 +55,7 @@ func NewTLSServerUpgrader(config *tls.Config) Upgrader {
 }
 
 func (t tlsServerUpgrader) Upgrade(conn net.Conn) (ids.ShortID, net.Conn, error) {
-	encConn := tls.Server(conn, t.config)
-	if err := encConn.Handshake(); err != nil {
-		return ids.ShortID{}, nil, err
-	}
-
-	connState := encConn.ConnectionState()
-	if len(connState.PeerCertificates) == 0 {
-		return ids.ShortID{}, nil, errNoCert
-	}
-	peerCert := connState.PeerCertificates[0]
-	id := ids.ShortID(
-		hashing.ComputeHash160Array(
-			hashing.ComputeHash256(peerCert.Raw)))
-	return id, encConn, nil
+	return upgradeTLS(tls.Server(conn, t.config))
 }
 
 type tlsClientUpgrader struct {
@@ -74,7 +70,12 @@ func NewTLSClientUpgrader(config *tls.Config) Upgrader {
 }
 
 func (t tlsClientUpgrader) Upgrade(conn net.Conn) (ids.ShortID, net.Conn, error) {
-	encConn := tls.Client(conn, t.config)
+	return upgradeTLS(tls.Client(conn, t.config))
+}
+
+// upgradeTLS performs the TLS handshake over [encConn] and returns the node ID
+// derived from the peer's certificate
+func upgradeTLS(encConn *tls.Conn) (ids.ShortID, net.Conn, error) {
 	if err := encConn.Handshake(); err != nil {
 		return ids.ShortID{}, nil, err
 	}
@@ -83,9 +84,5 @@ func (t tlsClientUpgrader) Upgrade(conn net.Conn) (ids.ShortID, net.Conn, error)
 	if len(connState.PeerCertificates) == 0 {
 		return ids.ShortID{}, nil, errNoCert
 	}
-	peerCert := connState.PeerCertificates[0]
-	id := ids.ShortID(
-		hashing.ComputeHash160Array(
-			hashing.ComputeHash256(peerCert.Raw)))
-	return id, encConn, nil
+	return CertToID(connState.PeerCertificates[0].Raw), encConn, nil
 }
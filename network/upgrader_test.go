@@ -0,0 +1,133 @@
+// (c) 2019-2020, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package network
+
+import (
+	"crypto/tls"
+	"errors"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/ava-labs/avalanchego/database/memdb"
+	"github.com/ava-labs/avalanchego/ids"
+	"github.com/ava-labs/avalanchego/network/networktest"
+	"github.com/ava-labs/avalanchego/snow/networking/benchlist"
+	"github.com/ava-labs/avalanchego/snow/validators"
+	"github.com/ava-labs/avalanchego/utils"
+	"github.com/ava-labs/avalanchego/utils/hashing"
+	"github.com/ava-labs/avalanchego/utils/logging"
+	"github.com/ava-labs/avalanchego/version"
+)
+
+func newTestTLSConfig(cert tls.Certificate) *tls.Config {
+	// #nosec G402
+	return &tls.Config{
+		Certificates:       []tls.Certificate{cert},
+		ClientAuth:         tls.RequireAnyClientCert,
+		InsecureSkipVerify: true,
+	}
+}
+
+func TestTLSUpgradersDeriveIDsFromCerts(t *testing.T) {
+	serverCert := newTestCert(t)
+	clientCert := newTestCert(t)
+
+	serverConn, clientConn := net.Pipe()
+
+	type result struct {
+		id  ids.ShortID
+		err error
+	}
+	serverResult := make(chan result, 1)
+	go func() {
+		id, _, err := NewTLSServerUpgrader(newTestTLSConfig(serverCert)).Upgrade(serverConn)
+		serverResult <- result{id: id, err: err}
+	}()
+
+	id, _, err := NewTLSClientUpgrader(newTestTLSConfig(clientCert)).Upgrade(clientConn)
+	assert.NoError(t, err)
+	assert.Equal(t, CertToID(serverCert.Certificate[0]), id)
+
+	res := <-serverResult
+	assert.NoError(t, res.err)
+	assert.Equal(t, CertToID(clientCert.Certificate[0]), res.id)
+}
+
+func TestUpgradeRejectsUnexpectedID(t *testing.T) {
+	ip := utils.NewDynamicIPDesc(
+		net.IPv6loopback,
+		0,
+	)
+	id := ids.ShortID(hashing.ComputeHash160Array([]byte(ip.IP().String())))
+	ip1 := utils.NewDynamicIPDesc(
+		net.IPv6loopback,
+		1,
+	)
+
+	listener := networktest.NewListener(&net.TCPAddr{
+		IP:   net.IPv6loopback,
+		Port: 0,
+	})
+	caller := networktest.NewDialer(&net.TCPAddr{
+		IP:   net.IPv6loopback,
+		Port: 0,
+	})
+	caller.Route(ip1.IP(), listener)
+
+	vdrs := validators.NewSet()
+	netwrk := NewDefaultNetwork(
+		prometheus.NewRegistry(),
+		logging.NoLog{},
+		id,
+		ip,
+		0,
+		version.NewDefaultVersion("app", 0, 1, 0),
+		version.NewDefaultParser(),
+		[]net.Listener{listener},
+		caller,
+		NewIPUpgrader(),
+		NewIPUpgrader(),
+		vdrs,
+		vdrs,
+		&testHandler{},
+		time.Duration(0),
+		0,
+		nil,
+		false,
+		0,
+		0,
+		time.Now(),
+		defaultSendQueueSize,
+		DefaultSendQueueConfig,
+		BandwidthConfig{},
+		DefaultGossipConfig,
+		DefaultConnectionConfig,
+		HealthConfig{},
+		benchlist.NewManager(&benchlist.Config{}),
+		memdb.New(),
+		defaultAliasTimeout,
+		nil,
+	)
+	basenetwork := netwrk.(*network)
+
+	basenetwork.stateLock.Lock()
+	basenetwork.expectedIDs[ip1.IP().String()] = ids.GenerateTestShortID()
+	basenetwork.stateLock.Unlock()
+
+	conn, err := caller.Dial(ip1.IP())
+	assert.NoError(t, err)
+
+	err = basenetwork.upgrade(newPeer(basenetwork, conn, ip1.IP()), NewIPUpgrader())
+	assert.True(t, errors.Is(err, errPeerIDMismatch))
+
+	basenetwork.stateLock.RLock()
+	assert.Empty(t, basenetwork.peers)
+	basenetwork.stateLock.RUnlock()
+
+	assert.NoError(t, netwrk.Close())
+}
@@ -0,0 +1,35 @@
+// (c) 2019-2020, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package network
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/ava-labs/avalanchego/version"
+)
+
+var errVersionTooOld = errors.New("version is too old")
+
+// VersionPolicy decides which versions peers may run. Peers must also run a
+// version that's compatible with ours.
+type VersionPolicy struct {
+	// Peers running a version before this one are refused. nil means that no
+	// compatible version is refused.
+	MinCompatible version.Version
+
+	// Peers running a version before this one are allowed to connect, but are
+	// counted as running a deprecated version. nil means that no version is
+	// deprecated.
+	MinRecommended version.Version
+}
+
+// Check returns an error if a peer running [v] should be refused. Otherwise,
+// returns true if [v] is deprecated.
+func (p VersionPolicy) Check(v version.Version) (bool, error) {
+	if p.MinCompatible != nil && v.Before(p.MinCompatible) {
+		return false, fmt.Errorf("%w: %s is before %s", errVersionTooOld, v, p.MinCompatible)
+	}
+	return p.MinRecommended != nil && v.Before(p.MinRecommended), nil
+}
@@ -0,0 +1,36 @@
+// (c) 2019-2020, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package network
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/ava-labs/avalanchego/version"
+)
+
+func TestVersionPolicyCheck(t *testing.T) {
+	policy := VersionPolicy{
+		MinCompatible:  version.NewDefaultVersion("app", 1, 2, 0),
+		MinRecommended: version.NewDefaultVersion("app", 1, 3, 0),
+	}
+
+	_, err := policy.Check(version.NewDefaultVersion("app", 1, 1, 9))
+	assert.True(t, errors.Is(err, errVersionTooOld))
+
+	deprecated, err := policy.Check(version.NewDefaultVersion("app", 1, 2, 0))
+	assert.NoError(t, err)
+	assert.True(t, deprecated)
+
+	deprecated, err = policy.Check(version.NewDefaultVersion("app", 1, 3, 0))
+	assert.NoError(t, err)
+	assert.False(t, deprecated)
+
+	// an empty policy allows every version
+	deprecated, err = VersionPolicy{}.Check(version.NewDefaultVersion("app", 0, 0, 1))
+	assert.NoError(t, err)
+	assert.False(t, deprecated)
+}
@@ -11,6 +11,7 @@ import (
 	"github.com/ava-labs/avalanchego/nat"
 	"github.com/ava-labs/avalanchego/network"
 	"github.com/ava-labs/avalanchego/snow/consensus/avalanche"
+	"github.com/ava-labs/avalanchego/snow/engine/avalanche/state"
 	"github.com/ava-labs/avalanchego/snow/networking/benchlist"
 	"github.com/ava-labs/avalanchego/snow/networking/router"
 	"github.com/ava-labs/avalanchego/utils"
@@ -48,19 +49,44 @@ type Config struct {
 	// If false, uses an in memory database
 	DBEnabled bool
 
+	// If true, the backups made before migrating chain databases are removed
+	DBDiscardBackups bool
+
+	// Chain ID --> Key spaces of the chain's databases to roll back to the
+	// schema version they had before they were last migrated
+	DBRollbacks map[ids.ID][]string
+
 	// Staking configuration
-	StakingIP             utils.DynamicIPDesc
+	// IP advertised to peers, which may differ from [ListenAddresses]
+	StakingIP utils.DynamicIPDesc
+	// Addresses, as host:port, that the node listens for peers on. If empty,
+	// the node listens on the port of [StakingIP] on every interface.
+	ListenAddresses []string
+
 	EnableP2PTLS          bool
+	EnableP2PMAC          bool
 	EnableStaking         bool
 	StakingKeyFile        string
 	StakingCertFile       string
 	DisabledStakingWeight uint64
 
+	// Previous staking certificate, by which peers recognize this node until
+	// [StakingRotationExpiry]. Unused if the files are empty.
+	StakingPreviousKeyFile  string
+	StakingPreviousCertFile string
+	StakingRotationExpiry   time.Time
+
 	// Throttling
 	MaxNonStakerPendingMsgs uint32
 	StakerMSGPortion        float64
 	StakerCPUPortion        float64
+	ParseBudgetRate         uint64
+	ParseBudgetBurst        uint64
 	SendQueueSize           uint32
+	SendQueueConfig         network.SendQueueConfig
+	BandwidthConfig         network.BandwidthConfig
+	GossipConfig            network.GossipConfig
+	ConnectionConfig        network.ConnectionConfig
 	MaxPendingMsgs          uint32
 
 	// Health
@@ -94,6 +120,12 @@ type Config struct {
 	MetricsAPIEnabled  bool
 	HealthAPIEnabled   bool
 
+	// Metrics snapshot configuration. If [MetricsSnapshotDir] is empty,
+	// snapshots aren't written.
+	MetricsSnapshotDir       string
+	MetricsSnapshotFrequency time.Duration
+	MetricsSnapshotCount     int
+
 	// Logging configuration
 	LoggingConfig logging.Config
 
@@ -112,6 +144,10 @@ type Config struct {
 	IPCPath            string
 	IPCDefaultChainIDs []string
 
+	// Acceptor configuration
+	AcceptorWebhookURLs    []string
+	AcceptorWebhookTimeout time.Duration
+
 	// Router that is used to handle incoming consensus messages
 	ConsensusRouter          router.Router
 	RouterHealthConfig       router.HealthConfig
@@ -127,6 +163,9 @@ type Config struct {
 	ConnMeterResetDuration time.Duration
 	ConnMeterMaxConns      int
 
+	// Proxies used to dial peers
+	ProxyConfig network.ProxyConfig
+
 	// Subnet Whitelist
 	WhitelistedSubnets ids.Set
 
@@ -138,12 +177,68 @@ type Config struct {
 	// Coreth
 	CorethConfig string
 
+	// X-Chain transaction limits. 0 means no limit.
+	AVMMaxTxSize    int
+	AVMMaxTxInputs  int
+	AVMMaxTxOutputs int
+
+	// Should the X-Chain rebuild its status and UTXO indexes on startup
+	AVMReindex bool
+
+	// Should the X-Chain apply the shared memory operations of accepted txs in
+	// the background
+	AVMAsyncSideEffects bool
+
+	// Should the X-Chain index the memos of accepted txs
+	AVMIndexMemos bool
+
 	// Should Bootstrap be retried
 	RetryBootstrap bool
 
 	// Max number of times to retry bootstrap
 	RetryBootstrapMaxAttempts int
 
+	// Max number of times a chain is restarted after a fatal error
+	ChainRestartBudget int
+
+	// Chain ID --> Name of the hash function the chain uses
+	ChainHashFunctions map[ids.ID]string
+
+	// Should chits include the heights of the voted containers
+	JustifyChits bool
+
+	// Should the IDs of accepted txs be gossiped
+	GossipAcceptedTxs bool
+
+	// Max number of outstanding vertex Get requests. 0 means no limit.
+	MaxOutstandingGets int
+
+	// Max number of outstanding vertex Get requests to a validator. 0 means no
+	// limit.
+	MaxOutstandingGetsPerPeer int
+
+	// Max number of queued vertex Get requests
+	MaxQueuedGets int
+
+	// Time without a finished poll after which unfinalized containers are
+	// re-polled. 0 disables heartbeat polls.
+	HeartbeatFrequency time.Duration
+
+	// Number of heights the parents of a vertex may be behind the accepted
+	// frontier before the vertex is considered stale. 0 disables stale vertex
+	// detection.
+	StaleVertexDepth uint64
+
+	// Should stale vertices be excluded from the parents of new vertices
+	RefuseStaleParents bool
+
+	// Seed that the randomness of each chain is derived from. If empty, chains
+	// use non-deterministic randomness.
+	RandomSeed []byte
+
+	// When the accepted frontier of avalanche chains is persisted
+	VertexEdgeConfig state.EdgeConfig
+
 	// Peer alias configuration
 	PeerAliasTimeout time.Duration
 }
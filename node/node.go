@@ -17,6 +17,7 @@ import (
 	"syscall"
 	"time"
 
+	"github.com/ava-labs/avalanchego/acceptors"
 	"github.com/ava-labs/avalanchego/api"
 	"github.com/ava-labs/avalanchego/api/admin"
 	"github.com/ava-labs/avalanchego/api/health"
@@ -35,11 +36,13 @@ import (
 	"github.com/ava-labs/avalanchego/snow/networking/benchlist"
 	"github.com/ava-labs/avalanchego/snow/networking/router"
 	"github.com/ava-labs/avalanchego/snow/networking/timeout"
+	"github.com/ava-labs/avalanchego/snow/networking/tracker"
 	"github.com/ava-labs/avalanchego/snow/triggers"
 	"github.com/ava-labs/avalanchego/snow/validators"
 	"github.com/ava-labs/avalanchego/utils"
 	"github.com/ava-labs/avalanchego/utils/constants"
 	"github.com/ava-labs/avalanchego/utils/hashing"
+	"github.com/ava-labs/avalanchego/utils/lifecycle"
 	"github.com/ava-labs/avalanchego/utils/logging"
 	"github.com/ava-labs/avalanchego/utils/math"
 	"github.com/ava-labs/avalanchego/utils/timer"
@@ -49,6 +52,7 @@ import (
 	"github.com/ava-labs/avalanchego/vms/avm"
 	"github.com/ava-labs/avalanchego/vms/evm"
 	"github.com/ava-labs/avalanchego/vms/nftfx"
+	"github.com/ava-labs/avalanchego/vms/pausefx"
 	"github.com/ava-labs/avalanchego/vms/platformvm"
 	"github.com/ava-labs/avalanchego/vms/propertyfx"
 	"github.com/ava-labs/avalanchego/vms/rpcchainvm"
@@ -67,6 +71,13 @@ var (
 	errPrimarySubnetNotBootstrapped = errors.New("primary subnet has not finished bootstrapping")
 )
 
+// Deadlines for shutting down the node's components
+const (
+	apiShutdownTimeout       = 15 * time.Second
+	chainsShutdownTimeout    = time.Minute
+	componentShutdownTimeout = 10 * time.Second
+)
+
 var (
 	genesisHashKey = []byte("genesisID")
 
@@ -113,9 +124,20 @@ type Node struct {
 
 	IPCs *ipcs.ChainIPCs
 
+	// Delivers accepted decisions to external sinks. Nil if no sinks are
+	// configured.
+	Acceptors *acceptors.Acceptors
+
+	// Writes snapshots of the node's metrics to disk. Nil if snapshots are
+	// disabled.
+	metricsSnapshotter *metrics.Snapshotter
+
 	// Net runs the networking stack
 	Net network.Network
 
+	// Dials peers through proxies. Nil if no proxies are configured.
+	proxyDialer network.ProxyDialer
+
 	// this node's initial connections to the network
 	beacons validators.Set
 
@@ -152,13 +174,36 @@ type Node struct {
  */
 
 func (n *Node) initNetworking() error {
-	listener, err := net.Listen(TCP, fmt.Sprintf(":%d", n.Config.StakingIP.Port))
-	if err != nil {
-		return err
+	var err error
+	listenAddrs := n.Config.ListenAddresses
+	if len(listenAddrs) == 0 {
+		listenAddrs = []string{fmt.Sprintf(":%d", n.Config.StakingIP.Port)}
+	}
+	listeners := make([]net.Listener, 0, len(listenAddrs))
+	for _, addr := range listenAddrs {
+		var listener net.Listener
+		listener, err = net.Listen(TCP, addr)
+		if err != nil {
+			for _, listener := range listeners {
+				_ = listener.Close()
+			}
+			return fmt.Errorf("couldn't listen on %s: %w", addr, err)
+		}
+		listeners = append(listeners, listener)
 	}
 	dialer := network.NewDialer(TCP)
+	if n.Config.ProxyConfig.Enabled() {
+		n.proxyDialer, err = network.NewProxyDialer(TCP, n.Config.ProxyConfig, n.Config.ConsensusParams.Metrics)
+		if err != nil {
+			return fmt.Errorf("couldn't create proxy dialer: %w", err)
+		}
+		dialer = n.proxyDialer
+	}
 
-	var serverUpgrader, clientUpgrader network.Upgrader
+	var (
+		serverUpgrader, clientUpgrader network.Upgrader
+		rotation                       *network.IdentityRotation
+	)
 	if n.Config.EnableP2PTLS {
 		cert, err := tls.LoadX509KeyPair(n.Config.StakingCertFile, n.Config.StakingKeyFile)
 		if err != nil {
@@ -180,6 +225,21 @@ func (n *Node) initNetworking() error {
 
 		serverUpgrader = network.NewTLSServerUpgrader(tlsConfig)
 		clientUpgrader = network.NewTLSClientUpgrader(tlsConfig)
+
+		if n.Config.StakingPreviousCertFile != "" {
+			previousCert, err := tls.LoadX509KeyPair(n.Config.StakingPreviousCertFile, n.Config.StakingPreviousKeyFile)
+			if err != nil {
+				return fmt.Errorf("couldn't load previous staking key/cert: %w", err)
+			}
+			rotation, err = network.NewIdentityRotation(previousCert, n.ID, n.Config.StakingRotationExpiry)
+			if err != nil {
+				return err
+			}
+			n.Log.Info("rotated from staking identity %s until %s", rotation.PreviousID().PrefixedString(constants.NodeIDPrefix), n.Config.StakingRotationExpiry)
+		}
+	} else if n.Config.EnableP2PMAC {
+		serverUpgrader = network.NewMACIPUpgrader(network.CapabilityMAC)
+		clientUpgrader = network.NewMACIPUpgrader(network.CapabilityMAC)
 	} else {
 		serverUpgrader = network.NewIPUpgrader()
 		clientUpgrader = network.NewIPUpgrader()
@@ -234,6 +294,8 @@ func (n *Node) initNetworking() error {
 		}
 	}
 
+	connectionConfig := n.Config.ConnectionConfig
+	connectionConfig.Validators = n.vdrs
 	n.Net = network.NewDefaultNetwork(
 		n.Config.ConsensusParams.Metrics,
 		n.Log,
@@ -242,7 +304,7 @@ func (n *Node) initNetworking() error {
 		n.Config.NetworkID,
 		Version,
 		versionParser,
-		listener,
+		listeners,
 		dialer,
 		serverUpgrader,
 		clientUpgrader,
@@ -257,9 +319,15 @@ func (n *Node) initNetworking() error {
 		n.Config.DisconnectedRestartTimeout,
 		n.Config.ApricotPhase0Time,
 		n.Config.SendQueueSize,
+		n.Config.SendQueueConfig,
+		n.Config.BandwidthConfig,
+		n.Config.GossipConfig,
+		connectionConfig,
 		n.Config.NetworkHealthConfig,
 		n.benchlistManager,
+		prefixdb.New([]byte("network"), n.DB),
 		n.Config.PeerAliasTimeout,
+		rotation,
 	)
 
 	n.nodeCloser = utils.HandleSignals(func(os.Signal) {
@@ -354,10 +422,18 @@ func (n *Node) Dispatch() error {
 
 	// Add bootstrap nodes to the peer network
 	for _, peer := range n.Config.BootstrapPeers {
-		if !peer.IP.Equal(n.Config.StakingIP.IP()) {
-			n.Net.Track(peer.IP)
-		} else {
+		switch {
+		case peer.IP.Equal(n.Config.StakingIP.IP()):
 			n.Log.Error("can't add self as a bootstrapper")
+		case n.Config.EnableP2PTLS:
+			// The bootstrap peer's ID can be verified against its certificate
+			if err := n.Net.TrackNode(peer.IP, peer.ID); err != nil {
+				n.Log.Error("couldn't connect to bootstrapper %s: %s", peer.IP, err)
+			}
+		default:
+			if err := n.Net.Track(peer.IP); err != nil {
+				n.Log.Error("couldn't connect to bootstrapper %s: %s", peer.IP, err)
+			}
 		}
 	}
 
@@ -475,6 +551,31 @@ func (n *Node) initIPCs() error {
 	return err
 }
 
+func (n *Node) initAcceptors() error {
+	if len(n.Config.AcceptorWebhookURLs) == 0 {
+		return nil
+	}
+
+	// Sinks are named by their URL, so that their queued events survive
+	// reordering the configured URLs
+	sinks := make(map[string]acceptors.Sink, len(n.Config.AcceptorWebhookURLs))
+	for _, url := range n.Config.AcceptorWebhookURLs {
+		sinks[url] = acceptors.NewWebhookSink(url, n.Config.AcceptorWebhookTimeout)
+	}
+
+	var err error
+	n.Acceptors, err = acceptors.New(n.Log, prefixdb.New([]byte("acceptors"), n.DB), n.DecisionDispatcher, sinks, n.Config.ConsensusParams.Metrics)
+	if err != nil {
+		return err
+	}
+
+	// Register the acceptors with the health service
+	if err := n.healthService.RegisterCheck("acceptors", n.Acceptors.HealthCheck); err != nil {
+		return fmt.Errorf("couldn't register acceptors health check: %w", err)
+	}
+	return nil
+}
+
 // Initializes the Platform chain.
 // Its genesis data specifies the other chains that should be created.
 func (n *Node) initChains(genesisBytes []byte) {
@@ -561,6 +662,7 @@ func (n *Node) initChainManager(avaxAssetID ids.ID) error {
 		MaxNonStakerPendingMsgs:   n.Config.MaxNonStakerPendingMsgs,
 		StakerMSGPortion:          n.Config.StakerMSGPortion,
 		StakerCPUPortion:          n.Config.StakerCPUPortion,
+		ParseCostTracker:          tracker.NewCostTracker(n.Config.ParseBudgetRate, n.Config.ParseBudgetBurst),
 		Log:                       n.Log,
 		LogFactory:                n.LogFactory,
 		VMManager:                 n.vmManager,
@@ -582,10 +684,30 @@ func (n *Node) initChainManager(avaxAssetID ids.ID) error {
 		XChainID:                  xChainID,
 		CriticalChains:            criticalChains,
 		TimeoutManager:            timeoutManager,
+		Benchlist:                 n.benchlistManager,
 		HealthService:             n.healthService,
 		WhitelistedSubnets:        n.Config.WhitelistedSubnets,
 		RetryBootstrap:            n.Config.RetryBootstrap,
 		RetryBootstrapMaxAttempts: n.Config.RetryBootstrapMaxAttempts,
+		JustifyChits:              n.Config.JustifyChits,
+		GossipAcceptedTxs:         n.Config.GossipAcceptedTxs,
+		MaxOutstandingGets:        n.Config.MaxOutstandingGets,
+		MaxOutstandingGetsPerPeer: n.Config.MaxOutstandingGetsPerPeer,
+		MaxQueuedGets:             n.Config.MaxQueuedGets,
+		HeartbeatFrequency:        n.Config.HeartbeatFrequency,
+		StaleVertexDepth:          n.Config.StaleVertexDepth,
+		RefuseStaleParents:        n.Config.RefuseStaleParents,
+		RandomSeed:                n.Config.RandomSeed,
+		VertexEdgeConfig:          n.Config.VertexEdgeConfig,
+		DiscardDBBackups:          n.Config.DBDiscardBackups,
+		DBRollbacks:               n.Config.DBRollbacks,
+		ChainRestartBudget:        n.Config.ChainRestartBudget,
+		ChainHashFunctions:        n.Config.ChainHashFunctions,
+		// The C-Chain imports the UTXOs exported by the X-Chain through
+		// shared memory, so it must wait for the X-Chain to bootstrap
+		ChainDependencies: map[ids.ID][]ids.ID{
+			cChainID: {xChainID},
+		},
 	})
 
 	vdrs := n.vdrs
@@ -617,8 +739,14 @@ func (n *Node) initChainManager(avaxAssetID ids.ID) error {
 			ApricotPhase0Time:  n.Config.ApricotPhase0Time,
 		}),
 		n.vmManager.RegisterVMFactory(avm.ID, &avm.Factory{
-			CreationFee: n.Config.CreationTxFee,
-			Fee:         n.Config.TxFee,
+			CreationFee:      n.Config.CreationTxFee,
+			Fee:              n.Config.TxFee,
+			MaxTxSize:        n.Config.AVMMaxTxSize,
+			MaxTxInputs:      n.Config.AVMMaxTxInputs,
+			MaxTxOutputs:     n.Config.AVMMaxTxOutputs,
+			Reindex:          n.Config.AVMReindex,
+			AsyncSideEffects: n.Config.AVMAsyncSideEffects,
+			IndexMemos:       n.Config.AVMIndexMemos,
 		}),
 		n.vmManager.RegisterVMFactory(evm.ID, &rpcchainvm.Factory{
 			Path:   filepath.Join(n.Config.PluginDir, "evm"),
@@ -628,6 +756,7 @@ func (n *Node) initChainManager(avaxAssetID ids.ID) error {
 		n.vmManager.RegisterVMFactory(secp256k1fx.ID, &secp256k1fx.Factory{}),
 		n.vmManager.RegisterVMFactory(nftfx.ID, &nftfx.Factory{}),
 		n.vmManager.RegisterVMFactory(propertyfx.ID, &propertyfx.Factory{}),
+		n.vmManager.RegisterVMFactory(pausefx.ID, &pausefx.Factory{}),
 	)
 	if errs.Errored() {
 		return errs.Err
@@ -675,6 +804,22 @@ func (n *Node) initMetricsAPI() error {
 	n.Config.NetworkConfig.MetricsNamespace = constants.PlatformName
 	n.Config.NetworkConfig.Registerer = registry
 
+	if n.Config.MetricsSnapshotDir != "" {
+		snapshotter, err := metrics.NewSnapshotter(
+			n.Log,
+			registry,
+			n.Config.MetricsSnapshotDir,
+			n.Config.MetricsSnapshotFrequency,
+			n.Config.MetricsSnapshotCount,
+		)
+		if err != nil {
+			return err
+		}
+		n.metricsSnapshotter = snapshotter
+		n.Log.Info("writing metrics snapshots to %s", n.Config.MetricsSnapshotDir)
+		go n.Log.RecoverAndPanic(snapshotter.Dispatch)
+	}
+
 	if !n.Config.MetricsAPIEnabled {
 		n.Log.Info("skipping metrics API initialization because it has been disabled")
 		return nil
@@ -770,6 +915,14 @@ func (n *Node) initHealthAPI() error {
 		return fmt.Errorf("couldn't register network health check")
 	}
 
+	// Register the proxies with the health service
+	if n.proxyDialer != nil {
+		err = n.healthService.RegisterCheck("proxy", n.proxyDialer.HealthCheck)
+		if err != nil {
+			return fmt.Errorf("couldn't register proxy health check")
+		}
+	}
+
 	// Register the router with the health service
 	err = n.healthService.RegisterCheck("router", n.Config.ConsensusRouter.HealthCheck)
 	if err != nil {
@@ -899,6 +1052,9 @@ func (n *Node) Initialize(
 	if err := n.initIPCs(); err != nil { // Start the IPCs
 		return fmt.Errorf("couldn't initialize IPCs: %w", err)
 	}
+	if err := n.initAcceptors(); err != nil { // Start the acceptors
+		return fmt.Errorf("couldn't initialize acceptors: %w", err)
+	}
 	if err := n.initIPCAPI(); err != nil { // Start the IPC API
 		return fmt.Errorf("couldn't initialize the IPC API: %w", err)
 	}
@@ -917,22 +1073,60 @@ func (n *Node) Shutdown() {
 	n.shutdownOnce.Do(n.shutdown)
 }
 
+// newShutdownManager returns the order in which the node's components are shut
+// down. The API server is shut down first, so that no new requests arrive.
+// Then the chains' router and engines are shut down, followed by the
+// consumers of their decisions and the network they send messages over.
+func (n *Node) newShutdownManager() (*lifecycle.Manager, error) {
+	m := lifecycle.NewManager(n.Log)
+	errs := wrappers.Errs{}
+	errs.Add(
+		m.Register("api", apiShutdownTimeout, n.APIServer.Shutdown),
+		m.Register("ipcs", componentShutdownTimeout, func() error {
+			if n.IPCs == nil {
+				return nil
+			}
+			return n.IPCs.Shutdown()
+		}, "api"),
+		m.Register("chains", chainsShutdownTimeout, func() error {
+			if n.chainManager != nil {
+				n.chainManager.Shutdown()
+			}
+			return nil
+		}, "api", "ipcs"),
+		m.Register("acceptors", componentShutdownTimeout, func() error {
+			if n.Acceptors == nil {
+				return nil
+			}
+			return n.Acceptors.Shutdown()
+		}, "chains"),
+		m.Register("network", componentShutdownTimeout, func() error {
+			if n.Net != nil {
+				// Close already logs its own error if one occurs, so the error
+				// is ignored here
+				_ = n.Net.Close()
+			}
+			return nil
+		}, "chains"),
+		// The last snapshot is taken once the chains have stopped, so that
+		// it records the node's final metrics
+		m.Register("metrics-snapshots", componentShutdownTimeout, func() error {
+			if n.metricsSnapshotter == nil {
+				return nil
+			}
+			return n.metricsSnapshotter.Stop()
+		}, "chains", "network"),
+	)
+	return m, errs.Err
+}
+
 func (n *Node) shutdown() {
 	n.Log.Info("shutting down node")
-	if n.IPCs != nil {
-		if err := n.IPCs.Shutdown(); err != nil {
-			n.Log.Debug("error during IPC shutdown: %s", err)
-		}
-	}
-	if n.chainManager != nil {
-		n.chainManager.Shutdown()
-	}
-	if n.Net != nil {
-		// Close already logs its own error if one occurs, so the error is ignored here
-		_ = n.Net.Close()
-	}
-	if err := n.APIServer.Shutdown(); err != nil {
-		n.Log.Debug("error during API shutdown: %s", err)
+	shutdownManager, err := n.newShutdownManager()
+	if err != nil {
+		n.Log.Error("couldn't order the node's components for shutdown: %s", err)
+	} else if err := shutdownManager.Shutdown(); err != nil {
+		n.Log.Debug("error during node shutdown: %s", err)
 	}
 	utils.ClearSignals(n.nodeCloser)
 	n.doneShuttingDown.Done()
@@ -0,0 +1,15 @@
+#!/usr/bin/env bash
+
+set -o errexit
+set -o nounset
+set -o pipefail
+
+# Runs the consensus benchmarks and writes the results, in the standard go
+# benchmark format, to the file named by the first argument. The results of two
+# runs can be compared with benchstat.
+BENCH_OUT=${1:-"bench.out"}
+BENCH_COUNT=${BENCH_COUNT:-5}
+
+go test -run="^$" -bench="." -benchmem -count="$BENCH_COUNT" \
+    ./snow/consensus/avalanche/... \
+    ./snow/consensus/snowstorm/... | tee "$BENCH_OUT"
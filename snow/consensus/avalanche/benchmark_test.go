@@ -0,0 +1,162 @@
+// (c) 2019-2020, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package avalanche
+
+import (
+	"math"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/ava-labs/avalanchego/ids"
+	"github.com/ava-labs/avalanchego/snow"
+	"github.com/ava-labs/avalanchego/snow/choices"
+	"github.com/ava-labs/avalanchego/snow/consensus/snowball"
+)
+
+// BenchmarkTopological measures the time it takes to add a randomly generated
+// DAG to a Topological instance, and to poll its preferred frontier until its
+// txs are decided.
+//
+// Along with the time per DAG, the number of polls and the number of accepted
+// vertices are reported, so the results can be tracked with standard benchmark
+// tooling.
+func BenchmarkTopological(b *testing.B) {
+	dags := []DAGParameters{
+		{Width: 8, Depth: 16, TxsPerVertex: 1, ConflictRate: 0},
+		{Width: 8, Depth: 16, TxsPerVertex: 8, ConflictRate: 0},
+		{Width: 32, Depth: 16, TxsPerVertex: 4, ConflictRate: 0},
+		{Width: 8, Depth: 64, TxsPerVertex: 4, ConflictRate: 0},
+		{Width: 8, Depth: 16, TxsPerVertex: 4, ConflictRate: 0.05},
+		{Width: 8, Depth: 16, TxsPerVertex: 4, ConflictRate: 0.25},
+	}
+	for _, dag := range dags {
+		dag := dag
+		b.Run(dag.String(), func(b *testing.B) {
+			SimulateDAG(b, TopologicalFactory{}, dag, 0)
+		})
+	}
+}
+
+// SimulateDAG runs the consensus instances created by [factory] against the
+// DAG described by [dagParams] and generated from [seed].
+func SimulateDAG(b *testing.B, factory Factory, dagParams DAGParameters, seed int64) {
+	params := Parameters{
+		Parameters: snowball.Parameters{
+			K:                     20,
+			Alpha:                 15,
+			BetaVirtuous:          15,
+			BetaRogue:             20,
+			ConcurrentRepolls:     1,
+			OptimalProcessing:     1,
+			MaxOutstandingItems:   1,
+			MaxItemProcessingTime: 1,
+		},
+		Parents:   5,
+		BatchSize: 1,
+	}
+
+	totalPolls, totalAccepted := 0, 0
+	for n := 0; n < b.N; n++ {
+		b.StopTimer()
+		genesis, vtxs := GenerateDAG(dagParams, params.Parents, seed)
+		params.Metrics = prometheus.NewRegistry()
+		avl := factory.New()
+		if err := avl.Initialize(snow.DefaultContextTest(), params, []Vertex{genesis}); err != nil {
+			b.Fatal(err)
+		}
+		b.StartTimer()
+
+		for _, vtx := range vtxs {
+			if err := avl.Add(vtx); err != nil {
+				b.Fatal(err)
+			}
+		}
+
+		// Every validator votes for the preferred frontier until every tx is
+		// decided, or until no vertex has been decided for more polls than it
+		// takes to decide a rogue tx. Rogue txs may never be decided without
+		// the engine reissuing them, which isn't modeled here.
+		polls, lastProgress := 0, 0
+		for numProcessing := avl.NumProcessing(); !avl.Finalized() && polls-lastProgress <= params.BetaRogue; polls++ {
+			votes := ids.UniqueBag{}
+			for vdr := 0; vdr < params.K; vdr++ {
+				votes.Add(uint(vdr), avl.Preferences().List()...)
+			}
+			if err := avl.RecordPoll(votes); err != nil {
+				b.Fatal(err)
+			}
+			if avl.NumProcessing() != numProcessing {
+				numProcessing = avl.NumProcessing()
+				lastProgress = polls
+			}
+		}
+
+		b.StopTimer()
+		totalPolls += polls
+		for _, vtx := range vtxs {
+			if vtx.Status() == choices.Accepted {
+				totalAccepted++
+			}
+		}
+		b.StartTimer()
+	}
+	b.ReportMetric(float64(totalPolls)/float64(b.N), "polls/op")
+	b.ReportMetric(float64(totalAccepted)/float64(b.N), "accepted-vtxs/op")
+}
+
+// BenchmarkTopologicalRecordPoll measures the cost of polling a live set that
+// doesn't change between polls, as happens while txs are building confidence.
+func BenchmarkTopologicalRecordPoll(b *testing.B) {
+	dags := []DAGParameters{
+		{Width: 8, Depth: 16, TxsPerVertex: 4},
+		{Width: 32, Depth: 16, TxsPerVertex: 4},
+		{Width: 32, Depth: 64, TxsPerVertex: 4},
+	}
+	for _, dag := range dags {
+		dag := dag
+		b.Run(dag.String(), func(b *testing.B) {
+			params := Parameters{
+				Parameters: snowball.Parameters{
+					Metrics: prometheus.NewRegistry(),
+					K:       20,
+					Alpha:   15,
+					// Nothing is decided, so the live set is the same for
+					// every poll
+					BetaVirtuous:          math.MaxInt32,
+					BetaRogue:             math.MaxInt32,
+					ConcurrentRepolls:     1,
+					OptimalProcessing:     1,
+					MaxOutstandingItems:   1,
+					MaxItemProcessingTime: 1,
+				},
+				Parents:   5,
+				BatchSize: 1,
+			}
+			genesis, vtxs := GenerateDAG(dag, params.Parents, 0)
+			avl := TopologicalFactory{}.New()
+			if err := avl.Initialize(snow.DefaultContextTest(), params, []Vertex{genesis}); err != nil {
+				b.Fatal(err)
+			}
+			for _, vtx := range vtxs {
+				if err := avl.Add(vtx); err != nil {
+					b.Fatal(err)
+				}
+			}
+
+			votes := ids.UniqueBag{}
+			for vdr := 0; vdr < params.K; vdr++ {
+				votes.Add(uint(vdr), avl.Preferences().List()...)
+			}
+
+			b.ReportAllocs()
+			b.ResetTimer()
+			for n := 0; n < b.N; n++ {
+				if err := avl.RecordPoll(votes); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
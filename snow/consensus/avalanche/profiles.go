@@ -0,0 +1,46 @@
+// (c) 2019-2020, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package avalanche
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/ava-labs/avalanchego/snow/consensus/snowball"
+)
+
+var (
+	errUnknownProfile = errors.New("unknown consensus profile")
+
+	// profiles maps the name of a snowball profile to the avalanche specific
+	// parameters that extend it
+	profiles = map[string]Parameters{
+		snowball.MainnetProfile: {
+			Parents:   5,
+			BatchSize: 30,
+		},
+		snowball.FujiProfile: {
+			Parents:   5,
+			BatchSize: 30,
+		},
+		snowball.LocalFastProfile: {
+			Parents:   5,
+			BatchSize: 30,
+		},
+	}
+)
+
+// Profile returns the parameters of the profile named [name]
+func Profile(name string) (Parameters, error) {
+	p, ok := profiles[name]
+	if !ok {
+		return Parameters{}, fmt.Errorf("%w: %q", errUnknownProfile, name)
+	}
+	sbParams, err := snowball.Profile(name)
+	if err != nil {
+		return Parameters{}, err
+	}
+	p.Parameters = sbParams
+	return p, nil
+}
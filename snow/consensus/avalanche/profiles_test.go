@@ -0,0 +1,29 @@
+// (c) 2019-2020, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package avalanche
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/ava-labs/avalanchego/snow/consensus/snowball"
+)
+
+func TestProfilesValid(t *testing.T) {
+	for _, name := range snowball.Profiles() {
+		p, err := Profile(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := p.Valid(); err != nil {
+			t.Fatalf("profile %q is invalid: %s", name, err)
+		}
+	}
+}
+
+func TestProfileUnknown(t *testing.T) {
+	if _, err := Profile("unknown"); !errors.Is(err, errUnknownProfile) {
+		t.Fatalf("Should have failed with %s, but got %v", errUnknownProfile, err)
+	}
+}
@@ -0,0 +1,121 @@
+// (c) 2019-2020, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package avalanche
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/ava-labs/avalanchego/ids"
+	"github.com/ava-labs/avalanchego/utils/wrappers"
+)
+
+// shapeMetrics reports the shape of the DAG, so that pathologies such as a
+// collapsing frontier or a growing backlog of processing vertices can be
+// detected early
+type shapeMetrics struct {
+	// Number of vertices with no descendants
+	frontierWidth prometheus.Gauge
+	// Average number of parents of the processing vertices
+	avgParents prometheus.Gauge
+	// Length of the longest chain of processing vertices
+	processingDepth prometheus.Gauge
+	// Number of virtuous txs that aren't in a preferred vertex
+	orphans prometheus.Gauge
+}
+
+func (m *shapeMetrics) Initialize(namespace string, registerer prometheus.Registerer) error {
+	m.frontierWidth = prometheus.NewGauge(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "dag_frontier_width",
+		Help:      "Number of vertices with no descendants",
+	})
+	m.avgParents = prometheus.NewGauge(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "dag_avg_parents",
+		Help:      "Average number of parents of the processing vertices",
+	})
+	m.processingDepth = prometheus.NewGauge(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "dag_processing_depth",
+		Help:      "Length of the longest chain of processing vertices",
+	})
+	m.orphans = prometheus.NewGauge(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "dag_orphan_txs",
+		Help:      "Number of virtuous txs that aren't in a preferred vertex",
+	})
+
+	errs := wrappers.Errs{}
+	errs.Add(
+		registerer.Register(m.frontierWidth),
+		registerer.Register(m.avgParents),
+		registerer.Register(m.processingDepth),
+		registerer.Register(m.orphans),
+	)
+	return errs.Err
+}
+
+// dagShape describes the processing section of the DAG
+type dagShape struct {
+	avgParents      float64
+	processingDepth int
+}
+
+// processingShape returns the shape of the graph formed by the processing
+// vertices in [nodes]
+func processingShape(nodes map[ids.ID]Vertex) (dagShape, error) {
+	shape := dagShape{}
+	if len(nodes) == 0 {
+		return shape, nil
+	}
+
+	// vtxID --> length of the longest chain of processing vertices ending at
+	// the vertex
+	depths := make(map[ids.ID]int, len(nodes))
+	var depth func(vtx Vertex) (int, error)
+	depth = func(vtx Vertex) (int, error) {
+		vtxID := vtx.ID()
+		if d, ok := depths[vtxID]; ok {
+			return d, nil
+		}
+		parents, err := vtx.Parents()
+		if err != nil {
+			return 0, err
+		}
+		d := 1
+		for _, parent := range parents {
+			if _, processing := nodes[parent.ID()]; !processing {
+				continue
+			}
+			parentDepth, err := depth(parent)
+			if err != nil {
+				return 0, err
+			}
+			if parentDepth+1 > d {
+				d = parentDepth + 1
+			}
+		}
+		depths[vtxID] = d
+		return d, nil
+	}
+
+	numParents := 0
+	for _, vtx := range nodes {
+		parents, err := vtx.Parents()
+		if err != nil {
+			return shape, err
+		}
+		numParents += len(parents)
+
+		d, err := depth(vtx)
+		if err != nil {
+			return shape, err
+		}
+		if d > shape.processingDepth {
+			shape.processingDepth = d
+		}
+	}
+	shape.avgParents = float64(numParents) / float64(len(nodes))
+	return shape, nil
+}
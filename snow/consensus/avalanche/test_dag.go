@@ -0,0 +1,121 @@
+// (c) 2019-2020, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package avalanche
+
+import (
+	"fmt"
+	"math/rand"
+
+	"github.com/ava-labs/avalanchego/ids"
+	"github.com/ava-labs/avalanchego/snow/choices"
+	"github.com/ava-labs/avalanchego/snow/consensus/snowstorm"
+)
+
+// DAGParameters describes the shape of a randomly generated DAG
+type DAGParameters struct {
+	// Width is the number of vertices in each layer of the DAG
+	Width int
+	// Depth is the number of layers in the DAG
+	Depth int
+	// TxsPerVertex is the number of txs issued in each vertex
+	TxsPerVertex int
+	// ConflictRate is the probability that a tx conflicts with a tx from a
+	// previous layer that isn't in its vertex's ancestry, rather than consuming
+	// a fresh UTXO
+	ConflictRate float64
+}
+
+func (p DAGParameters) String() string {
+	return fmt.Sprintf("width=%d/depth=%d/txs=%d/conflicts=%.2f",
+		p.Width, p.Depth, p.TxsPerVertex, p.ConflictRate)
+}
+
+// GenerateDAG returns an accepted genesis vertex and the processing vertices
+// of a DAG described by [dagParams], in topological order. Each vertex has up
+// to [numParents] parents from the previous layer. As an honest issuer would,
+// no vertex is issued with conflicting txs in its ancestry. The same DAG is
+// returned for the same [seed].
+func GenerateDAG(dagParams DAGParameters, numParents int, seed int64) (*TestVertex, []*TestVertex) {
+	r := rand.New(rand.NewSource(seed)) // #nosec G404
+
+	nextID := uint64(0)
+	newID := func() ids.ID {
+		nextID++
+		return ids.Empty.Prefix(nextID)
+	}
+
+	genesis := &TestVertex{TestDecidable: choices.TestDecidable{
+		IDV:     newID(),
+		StatusV: choices.Accepted,
+	}}
+
+	// Vertex ID --> UTXO ID --> ID of the tx in the vertex's ancestry, or the
+	// vertex itself, that consumes the UTXO
+	spent := map[ids.ID]map[ids.ID]ids.ID{
+		genesis.ID(): {},
+	}
+
+	vtxs := make([]*TestVertex, 0, dagParams.Width*dagParams.Depth)
+	prevLayer := []*TestVertex{genesis}
+	prevTxs := []*snowstorm.TestTx(nil)
+	for height := uint64(1); height <= uint64(dagParams.Depth); height++ {
+		layer := make([]*TestVertex, dagParams.Width)
+		layerTxs := make([]*snowstorm.TestTx, 0, dagParams.Width*dagParams.TxsPerVertex)
+		for i := range layer {
+			vtx := &TestVertex{
+				TestDecidable: choices.TestDecidable{
+					IDV:     newID(),
+					StatusV: choices.Processing,
+				},
+				HeightV: height,
+			}
+			vtxSpent := map[ids.ID]ids.ID{}
+			for _, j := range r.Perm(len(prevLayer)) {
+				if len(vtx.ParentsV) == numParents {
+					break
+				}
+				parent := prevLayer[j]
+				if conflicts(vtxSpent, spent[parent.ID()]) {
+					continue
+				}
+				vtx.ParentsV = append(vtx.ParentsV, parent)
+				for utxoID, txID := range spent[parent.ID()] {
+					vtxSpent[utxoID] = txID
+				}
+			}
+			for j := 0; j < dagParams.TxsPerVertex; j++ {
+				tx := &snowstorm.TestTx{TestDecidable: choices.TestDecidable{
+					IDV:     newID(),
+					StatusV: choices.Processing,
+				}}
+				tx.InputIDsV = []ids.ID{newID()}
+				if len(prevTxs) > 0 && r.Float64() < dagParams.ConflictRate {
+					conflict := prevTxs[r.Intn(len(prevTxs))]
+					if _, ok := vtxSpent[conflict.InputIDsV[0]]; !ok {
+						tx.InputIDsV[0] = conflict.InputIDsV[0]
+					}
+				}
+				vtxSpent[tx.InputIDsV[0]] = tx.ID()
+				vtx.TxsV = append(vtx.TxsV, tx)
+				layerTxs = append(layerTxs, tx)
+			}
+			spent[vtx.ID()] = vtxSpent
+			layer[i] = vtx
+		}
+		vtxs = append(vtxs, layer...)
+		prevLayer = layer
+		prevTxs = append(prevTxs, layerTxs...)
+	}
+	return genesis, vtxs
+}
+
+// conflicts returns true if a UTXO is consumed by different txs in [a] and [b]
+func conflicts(a, b map[ids.ID]ids.ID) bool {
+	for utxoID, txID := range b {
+		if otherTxID, ok := a[utxoID]; ok && otherTxID != txID {
+			return true
+		}
+	}
+	return false
+}
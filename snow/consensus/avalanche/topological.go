@@ -36,6 +36,7 @@ func (TopologicalFactory) New() Consensus { return &Topological{} }
 // order.
 type Topological struct {
 	metrics.Metrics
+	shape shapeMetrics
 
 	// Context used for logging
 	ctx *snow.Context
@@ -56,6 +57,20 @@ type Topological struct {
 	// preferenceCache is the cache for strongly preferred checks
 	// virtuousCache is the cache for strongly virtuous checks
 	preferenceCache, virtuousCache map[ids.ID]bool
+
+	// The following are used by RecordPoll. They're reused across polls, rather
+	// than reallocated, and are cleared before RecordPoll returns.
+	// kahnNodes is the reachable section of the graph
+	// leaves is the set of kahnNodes that have no inbound edges
+	// leafStack is the stack of leaves that are being traversed
+	// txVotes is the set of votes for each tx
+	// conflictingVotes is the set of votes for each tx's conflicts
+	// txConflicts is the set of conflicts of each tx
+	kahnNodes                 map[ids.ID]kahnNode
+	leaves                    ids.Set
+	leafStack                 []ids.ID
+	txVotes, conflictingVotes ids.UniqueBag
+	txConflicts               map[ids.ID]ids.Set
 }
 
 type kahnNode struct {
@@ -79,6 +94,9 @@ func (ta *Topological) Initialize(
 	if err := ta.Metrics.Initialize("vtx", "vertex/vertices", ctx.Log, params.Namespace, params.Metrics); err != nil {
 		return err
 	}
+	if err := ta.shape.Initialize(params.Namespace, params.Metrics); err != nil {
+		return err
+	}
 
 	ta.nodes = make(map[ids.ID]Vertex, minMapSize)
 
@@ -88,10 +106,14 @@ func (ta *Topological) Initialize(
 	}
 
 	ta.frontier = make(map[ids.ID]Vertex, minMapSize)
+	ta.resetPollState()
 	for _, vtx := range frontier {
 		ta.frontier[vtx.ID()] = vtx
 	}
-	return ta.updateFrontiers()
+	if err := ta.updateFrontiers(); err != nil {
+		return err
+	}
+	return ta.updateShape()
 }
 
 // NumProcessing implements the Avalanche interface
@@ -158,6 +180,14 @@ func (ta *Topological) Preferences() ids.Set { return ta.preferred }
 
 // RecordPoll implements the Avalanche interface
 func (ta *Topological) RecordPoll(responses ids.UniqueBag) error {
+	if err := ta.recordPoll(responses); err != nil {
+		return err
+	}
+	// Report the shape of the DAG after every poll, even if no statuses changed
+	return ta.updateShape()
+}
+
+func (ta *Topological) recordPoll(responses ids.UniqueBag) error {
 	// If it isn't possible to have alpha votes for any transaction, then we can
 	// just reset the confidence values in the conflict graph and not perform
 	// any traversals.
@@ -175,13 +205,14 @@ func (ta *Topological) RecordPoll(responses ids.UniqueBag) error {
 		return err
 	}
 
+	defer ta.clearPollState()
+
 	// Set up the topological sort: O(|Live Set|)
-	kahns, leaves, err := ta.calculateInDegree(responses)
-	if err != nil {
+	if err := ta.calculateInDegree(responses); err != nil {
 		return err
 	}
 	// Collect the votes for each transaction: O(|Live Set|)
-	votes, err := ta.pushVotes(kahns, leaves)
+	votes, err := ta.pushVotes()
 	if err != nil {
 		return err
 	}
@@ -224,50 +255,42 @@ func (ta *Topological) HealthCheck() (interface{}, error) {
 	return details, nil
 }
 
-// Takes in a list of votes and sets up the topological ordering. Returns the
-// reachable section of the graph annotated with the number of inbound edges and
-// the non-transitively applied votes. Also returns the list of leaf nodes.
-func (ta *Topological) calculateInDegree(responses ids.UniqueBag) (
-	map[ids.ID]kahnNode,
-	[]ids.ID,
-	error,
-) {
-	kahns := make(map[ids.ID]kahnNode, minMapSize)
-	leaves := ids.Set{}
-
+// Takes in a list of votes and sets up the topological ordering. Populates
+// [kahnNodes] with the reachable section of the graph annotated with the number
+// of inbound edges and the non-transitively applied votes. Also populates
+// [leafStack] with the leaf nodes.
+func (ta *Topological) calculateInDegree(responses ids.UniqueBag) error {
 	for vote := range responses {
 		// If it is not found, then the vote is either for something decided,
 		// or something we haven't heard of yet.
 		if vtx := ta.nodes[vote]; vtx != nil {
-			kahn, previouslySeen := kahns[vote]
+			kahn, previouslySeen := ta.kahnNodes[vote]
 			// Add this new vote to the current bag of votes
 			kahn.votes.Union(responses.GetSet(vote))
-			kahns[vote] = kahn
+			ta.kahnNodes[vote] = kahn
 
 			if !previouslySeen {
 				// If I've never seen this node before, it is currently a leaf.
-				leaves.Add(vote)
+				ta.leaves.Add(vote)
 				parents, err := vtx.Parents()
 				if err != nil {
-					return nil, nil, err
+					return err
 				}
-				kahns, leaves, err = ta.markAncestorInDegrees(kahns, leaves, parents)
-				if err != nil {
-					return nil, nil, err
+				if err := ta.markAncestorInDegrees(parents); err != nil {
+					return err
 				}
 			}
 		}
 	}
 
-	return kahns, leaves.List(), nil
+	for leaf := range ta.leaves {
+		ta.leafStack = append(ta.leafStack, leaf)
+	}
+	return nil
 }
 
 // adds a new in-degree reference for all nodes
-func (ta *Topological) markAncestorInDegrees(
-	kahns map[ids.ID]kahnNode,
-	leaves ids.Set,
-	deps []Vertex,
-) (map[ids.ID]kahnNode, ids.Set, error) {
+func (ta *Topological) markAncestorInDegrees(deps []Vertex) error {
 	frontier := make([]Vertex, 0, len(deps))
 	for _, vtx := range deps {
 		// The vertex may have been decided, no need to vote in that case
@@ -282,15 +305,15 @@ func (ta *Topological) markAncestorInDegrees(
 		frontier = frontier[:newLen]
 
 		currentID := current.ID()
-		kahn, alreadySeen := kahns[currentID]
+		kahn, alreadySeen := ta.kahnNodes[currentID]
 		// I got here through a transitive edge, so increase the in-degree
 		kahn.inDegree++
-		kahns[currentID] = kahn
+		ta.kahnNodes[currentID] = kahn
 
 		if kahn.inDegree == 1 {
 			// If I am transitively seeing this node for the first
 			// time, it is no longer a leaf.
-			leaves.Remove(currentID)
+			ta.leaves.Remove(currentID)
 		}
 
 		if !alreadySeen {
@@ -298,7 +321,7 @@ func (ta *Topological) markAncestorInDegrees(
 			// parents
 			parents, err := current.Parents()
 			if err != nil {
-				return nil, nil, err
+				return err
 			}
 			for _, depVtx := range parents {
 				// No need to traverse to a decided vertex
@@ -308,23 +331,17 @@ func (ta *Topological) markAncestorInDegrees(
 			}
 		}
 	}
-	return kahns, leaves, nil
+	return nil
 }
 
 // count the number of votes for each operation
-func (ta *Topological) pushVotes(
-	kahnNodes map[ids.ID]kahnNode,
-	leaves []ids.ID,
-) (ids.Bag, error) {
-	votes := make(ids.UniqueBag)
-	txConflicts := make(map[ids.ID]ids.Set, minMapSize)
+func (ta *Topological) pushVotes() (ids.Bag, error) {
+	for len(ta.leafStack) > 0 {
+		newLeavesSize := len(ta.leafStack) - 1
+		leaf := ta.leafStack[newLeavesSize]
+		ta.leafStack = ta.leafStack[:newLeavesSize]
 
-	for len(leaves) > 0 {
-		newLeavesSize := len(leaves) - 1
-		leaf := leaves[newLeavesSize]
-		leaves = leaves[:newLeavesSize]
-
-		kahn := kahnNodes[leaf]
+		kahn := ta.kahnNodes[leaf]
 
 		if vtx := ta.nodes[leaf]; vtx != nil {
 			txs, err := vtx.Txs()
@@ -334,11 +351,11 @@ func (ta *Topological) pushVotes(
 			for _, tx := range txs {
 				// Give the votes to the consumer
 				txID := tx.ID()
-				votes.UnionSet(txID, kahn.votes)
+				ta.txVotes.UnionSet(txID, kahn.votes)
 
 				// Map txID to set of Conflicts
-				if _, exists := txConflicts[txID]; !exists {
-					txConflicts[txID] = ta.cg.Conflicts(tx)
+				if _, exists := ta.txConflicts[txID]; !exists {
+					ta.txConflicts[txID] = ta.cg.Conflicts(tx)
 				}
 			}
 
@@ -348,15 +365,15 @@ func (ta *Topological) pushVotes(
 			}
 			for _, dep := range parents {
 				depID := dep.ID()
-				if depNode, notPruned := kahnNodes[depID]; notPruned {
+				if depNode, notPruned := ta.kahnNodes[depID]; notPruned {
 					depNode.inDegree--
 					// Give the votes to my parents
 					depNode.votes.Union(kahn.votes)
-					kahnNodes[depID] = depNode
+					ta.kahnNodes[depID] = depNode
 
 					if depNode.inDegree == 0 {
 						// Only traverse into the leaves
-						leaves = append(leaves, depID)
+						ta.leafStack = append(ta.leafStack, depID)
 					}
 				}
 			}
@@ -364,15 +381,54 @@ func (ta *Topological) pushVotes(
 	}
 
 	// Create bag of votes for conflicting transactions
-	conflictingVotes := make(ids.UniqueBag)
-	for txID, conflicts := range txConflicts {
+	for txID, conflicts := range ta.txConflicts {
 		for conflictTxID := range conflicts {
-			conflictingVotes.UnionSet(txID, votes.GetSet(conflictTxID))
+			ta.conflictingVotes.UnionSet(txID, ta.txVotes.GetSet(conflictTxID))
 		}
 	}
 
-	votes.Difference(&conflictingVotes)
-	return votes.Bag(ta.params.Alpha), nil
+	ta.txVotes.Difference(&ta.conflictingVotes)
+	return ta.txVotes.Bag(ta.params.Alpha), nil
+}
+
+// clearPollState empties the data structures used by RecordPoll so that they
+// can be reused by the next poll. If the live set has shrunk to well below the
+// size of the section of the graph that was traversed, the data structures are
+// reallocated instead, so that their memory can be released.
+func (ta *Topological) clearPollState() {
+	if len(ta.kahnNodes) > 2*len(ta.nodes)+minMapSize {
+		ta.resetPollState()
+		return
+	}
+
+	for vtxID := range ta.kahnNodes {
+		delete(ta.kahnNodes, vtxID)
+	}
+	ta.leaves.Clear()
+	ta.leafStack = ta.leafStack[:0]
+	for txID := range ta.txVotes {
+		delete(ta.txVotes, txID)
+	}
+	for txID := range ta.conflictingVotes {
+		delete(ta.conflictingVotes, txID)
+	}
+	for txID := range ta.txConflicts {
+		delete(ta.txConflicts, txID)
+	}
+}
+
+// resetPollState allocates the data structures used by RecordPoll
+func (ta *Topological) resetPollState() {
+	size := minMapSize
+	if len(ta.nodes) > size {
+		size = len(ta.nodes)
+	}
+	ta.kahnNodes = make(map[ids.ID]kahnNode, size)
+	ta.leaves = ids.Set{}
+	ta.leafStack = nil
+	ta.txVotes = make(ids.UniqueBag, size)
+	ta.conflictingVotes = make(ids.UniqueBag, size)
+	ta.txConflicts = make(map[ids.ID]ids.Set, size)
 }
 
 // If I've already checked, do nothing
@@ -547,3 +603,16 @@ func (ta *Topological) updateFrontiers() error {
 	}
 	return nil
 }
+
+// updateShape reports the current shape of the DAG
+func (ta *Topological) updateShape() error {
+	shape, err := processingShape(ta.nodes)
+	if err != nil {
+		return err
+	}
+	ta.shape.frontierWidth.Set(float64(len(ta.frontier)))
+	ta.shape.avgParents.Set(shape.avgParents)
+	ta.shape.processingDepth.Set(float64(shape.processingDepth))
+	ta.shape.orphans.Set(float64(ta.orphans.Len()))
+	return nil
+}
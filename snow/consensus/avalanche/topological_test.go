@@ -5,6 +5,158 @@ package avalanche
 
 import (
 	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+
+	"github.com/ava-labs/avalanchego/ids"
+	"github.com/ava-labs/avalanchego/snow"
+	"github.com/ava-labs/avalanchego/snow/choices"
+	"github.com/ava-labs/avalanchego/snow/consensus/snowball"
+	"github.com/ava-labs/avalanchego/snow/consensus/snowstorm"
 )
 
 func TestTopological(t *testing.T) { ConsensusTest(t, TopologicalFactory{}) }
+
+func TestTopologicalRecordPollClearsPollState(t *testing.T) {
+	params := Parameters{
+		Parameters: snowball.Parameters{
+			Metrics:               prometheus.NewRegistry(),
+			K:                     2,
+			Alpha:                 2,
+			BetaVirtuous:          2,
+			BetaRogue:             2,
+			ConcurrentRepolls:     1,
+			OptimalProcessing:     1,
+			MaxOutstandingItems:   1,
+			MaxItemProcessingTime: 1,
+		},
+		Parents:   2,
+		BatchSize: 1,
+	}
+	genesis, vtxs := GenerateDAG(DAGParameters{Width: 4, Depth: 4, TxsPerVertex: 2}, params.Parents, 0)
+
+	ta := &Topological{}
+	if err := ta.Initialize(snow.DefaultContextTest(), params, []Vertex{genesis}); err != nil {
+		t.Fatal(err)
+	}
+	for _, vtx := range vtxs {
+		if err := ta.Add(vtx); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	for i := 0; i < params.BetaVirtuous; i++ {
+		votes := ids.UniqueBag{}
+		votes.Add(0, ta.Preferences().List()...)
+		votes.Add(1, ta.Preferences().List()...)
+		if err := ta.RecordPoll(votes); err != nil {
+			t.Fatal(err)
+		}
+
+		switch {
+		case len(ta.kahnNodes) != 0:
+			t.Fatalf("Should have cleared the kahn nodes")
+		case ta.leaves.Len() != 0 || len(ta.leafStack) != 0:
+			t.Fatalf("Should have cleared the leaves")
+		case len(ta.txVotes) != 0 || len(ta.conflictingVotes) != 0:
+			t.Fatalf("Should have cleared the votes")
+		case len(ta.txConflicts) != 0:
+			t.Fatalf("Should have cleared the conflicts")
+		}
+	}
+
+	// The reused data structures shouldn't change the outcome of the polls
+	for _, vtx := range vtxs {
+		if status := vtx.Status(); status != choices.Accepted {
+			t.Fatalf("Vertex %s should have been accepted but is %s", vtx.ID(), status)
+		}
+	}
+	if ta.NumProcessing() != 0 {
+		t.Fatalf("Should have decided every vertex")
+	}
+}
+
+func TestTopologicalShapeMetrics(t *testing.T) {
+	params := Parameters{
+		Parameters: snowball.Parameters{
+			Metrics:               prometheus.NewRegistry(),
+			K:                     1,
+			Alpha:                 1,
+			BetaVirtuous:          2,
+			BetaRogue:             2,
+			ConcurrentRepolls:     1,
+			OptimalProcessing:     1,
+			MaxOutstandingItems:   1,
+			MaxItemProcessingTime: 1,
+		},
+		Parents:   2,
+		BatchSize: 1,
+	}
+	vts := []Vertex{
+		&TestVertex{TestDecidable: choices.TestDecidable{
+			IDV:     ids.GenerateTestID(),
+			StatusV: choices.Accepted,
+		}},
+		&TestVertex{TestDecidable: choices.TestDecidable{
+			IDV:     ids.GenerateTestID(),
+			StatusV: choices.Accepted,
+		}},
+	}
+	newVertex := func(height uint64, parents ...Vertex) *TestVertex {
+		tx := &snowstorm.TestTx{
+			TestDecidable: choices.TestDecidable{
+				IDV:     ids.GenerateTestID(),
+				StatusV: choices.Processing,
+			},
+			InputIDsV: []ids.ID{ids.GenerateTestID()},
+		}
+		return &TestVertex{
+			TestDecidable: choices.TestDecidable{
+				IDV:     ids.GenerateTestID(),
+				StatusV: choices.Processing,
+			},
+			ParentsV: parents,
+			HeightV:  height,
+			TxsV:     []snowstorm.Tx{tx},
+		}
+	}
+	// vtx0 <- vtx1 is a chain of two processing vertices, next to vtx2
+	vtx0 := newVertex(1, vts...)
+	vtx1 := newVertex(2, vtx0)
+	vtx2 := newVertex(1, vts[1])
+
+	ta := &Topological{}
+	if err := ta.Initialize(snow.DefaultContextTest(), params, vts); err != nil {
+		t.Fatal(err)
+	}
+	if width := testutil.ToFloat64(ta.shape.frontierWidth); width != 2 {
+		t.Fatalf("expected a frontier width of %d but got %v", 2, width)
+	}
+	if depth := testutil.ToFloat64(ta.shape.processingDepth); depth != 0 {
+		t.Fatalf("expected a processing depth of %d but got %v", 0, depth)
+	}
+
+	for _, vtx := range []Vertex{vtx0, vtx1, vtx2} {
+		if err := ta.Add(vtx); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// The gauges are updated once per poll
+	votes := ids.UniqueBag{}
+	votes.Add(0, vtx1.ID())
+	if err := ta.RecordPoll(votes); err != nil {
+		t.Fatal(err)
+	}
+
+	switch {
+	case testutil.ToFloat64(ta.shape.frontierWidth) != 2:
+		t.Fatalf("wrong frontier width")
+	case testutil.ToFloat64(ta.shape.avgParents) != 4.0/3.0:
+		t.Fatalf("wrong average parent count")
+	case testutil.ToFloat64(ta.shape.processingDepth) != 2:
+		t.Fatalf("wrong processing depth")
+	case testutil.ToFloat64(ta.shape.orphans) != 0:
+		t.Fatalf("wrong orphan count")
+	}
+}
@@ -40,6 +40,11 @@ type Parameters struct {
 	// Reports unhealthy if there is an item processing for longer than this
 	// duration.
 	MaxItemProcessingTime time.Duration
+
+	// If greater than ConcurrentRepolls, the number of concurrent re-polls
+	// adapts between ConcurrentRepolls and this number, rising while queries
+	// fail and falling once consensus quiesces.
+	MaxConcurrentRepolls int
 }
 
 // Verify returns nil if the parameters describe a valid initialization.
@@ -65,6 +70,10 @@ func (p Parameters) Verify() error {
 		return fmt.Errorf("MaxOutstandingItems = %d: Fails the condition that: 0 < MaxOutstandingItems", p.MaxOutstandingItems)
 	case p.MaxItemProcessingTime <= 0:
 		return fmt.Errorf("MaxItemProcessingTime = %d: Fails the condition that: 0 < MaxItemProcessingTime", p.MaxItemProcessingTime)
+	case p.MaxConcurrentRepolls < 0:
+		return fmt.Errorf("MaxConcurrentRepolls = %d: Fails the condition that: 0 <= MaxConcurrentRepolls", p.MaxConcurrentRepolls)
+	case p.MaxConcurrentRepolls > p.BetaRogue:
+		return fmt.Errorf("MaxConcurrentRepolls = %d, BetaRogue = %d: Fails the condition that: MaxConcurrentRepolls <= BetaRogue", p.MaxConcurrentRepolls, p.BetaRogue)
 	default:
 		return nil
 	}
@@ -230,3 +230,28 @@ func TestParametersInvalidMaxItemProcessingTime(t *testing.T) {
 		t.Fatalf("Should have failed due to invalid max item processing time")
 	}
 }
+
+func TestParametersInvalidMaxConcurrentRepolls(t *testing.T) {
+	tests := map[string]int{
+		"negative":                -1,
+		"greater than beta rogue": 3,
+	}
+	for label, maxConcurrentRepolls := range tests {
+		t.Run(label, func(t *testing.T) {
+			p := Parameters{
+				K:                     1,
+				Alpha:                 1,
+				BetaVirtuous:          1,
+				BetaRogue:             2,
+				ConcurrentRepolls:     1,
+				OptimalProcessing:     1,
+				MaxOutstandingItems:   1,
+				MaxItemProcessingTime: 1,
+				MaxConcurrentRepolls:  maxConcurrentRepolls,
+			}
+			if err := p.Verify(); err == nil {
+				t.Error("Should have failed due to invalid max concurrent repolls")
+			}
+		})
+	}
+}
@@ -0,0 +1,78 @@
+// (c) 2019-2020, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package snowball
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+)
+
+// Names of the parameter profiles
+const (
+	MainnetProfile   = "mainnet"
+	FujiProfile      = "fuji"
+	LocalFastProfile = "local-fast"
+)
+
+var (
+	errUnknownProfile = errors.New("unknown consensus profile")
+
+	// profiles maps the name of a profile to its parameters. The Namespace
+	// and Metrics of a profile are left unset.
+	profiles = map[string]Parameters{
+		MainnetProfile: {
+			K:                     20,
+			Alpha:                 14,
+			BetaVirtuous:          15,
+			BetaRogue:             20,
+			ConcurrentRepolls:     4,
+			OptimalProcessing:     50,
+			MaxOutstandingItems:   1024,
+			MaxItemProcessingTime: 2 * time.Minute,
+		},
+		FujiProfile: {
+			K:                     20,
+			Alpha:                 14,
+			BetaVirtuous:          15,
+			BetaRogue:             20,
+			ConcurrentRepolls:     4,
+			OptimalProcessing:     50,
+			MaxOutstandingItems:   1024,
+			MaxItemProcessingTime: 2 * time.Minute,
+		},
+		// LocalFastProfile finalizes after a single poll of a single node, so
+		// it's only suitable for local networks and tests
+		LocalFastProfile: {
+			K:                     1,
+			Alpha:                 1,
+			BetaVirtuous:          1,
+			BetaRogue:             2,
+			ConcurrentRepolls:     1,
+			OptimalProcessing:     50,
+			MaxOutstandingItems:   1024,
+			MaxItemProcessingTime: 2 * time.Minute,
+		},
+	}
+)
+
+// Profile returns the parameters of the profile named [name]
+func Profile(name string) (Parameters, error) {
+	p, ok := profiles[name]
+	if !ok {
+		return Parameters{}, fmt.Errorf("%w: %q", errUnknownProfile, name)
+	}
+	return p, nil
+}
+
+// Profiles returns the names of the profiles, sorted
+func Profiles() []string {
+	names := make([]string, 0, len(profiles))
+	for name := range profiles {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
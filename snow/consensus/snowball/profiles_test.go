@@ -0,0 +1,27 @@
+// (c) 2019-2020, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package snowball
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestProfilesVerify(t *testing.T) {
+	for _, name := range Profiles() {
+		p, err := Profile(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := p.Verify(); err != nil {
+			t.Fatalf("profile %q is invalid: %s", name, err)
+		}
+	}
+}
+
+func TestProfileUnknown(t *testing.T) {
+	if _, err := Profile("unknown"); !errors.Is(err, errUnknownProfile) {
+		t.Fatalf("Should have failed with %s, but got %v", errUnknownProfile, err)
+	}
+}
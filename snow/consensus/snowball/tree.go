@@ -35,11 +35,21 @@ type Tree struct {
 	// that any later traversal into this sub-tree should call
 	// RecordUnsuccessfulPoll before performing any other action.
 	shouldReset bool
+
+	// numNodes is the number of nodes that are currently in the tree
+	numNodes int
+
+	// Metrics reports the memory usage of the tree. It's shared by every tree
+	// of a consensus instance, so it's set by the creator of the tree rather
+	// than in Initialize. If nil, nothing is reported.
+	Metrics *TreeMetrics
 }
 
 // Initialize implements the Consensus interface
 func (t *Tree) Initialize(params Parameters, choice ids.ID) {
 	t.params = params
+	t.numNodes = 0
+	t.allocate(1)
 
 	snowball := &unarySnowball{}
 	snowball.Initialize(params.BetaVirtuous)
@@ -61,6 +71,9 @@ func (t *Tree) Add(choice ids.ID) {
 	// Make sure that we haven't already decided against this new id
 	if ids.EqualSubset(0, prefix, t.Preference(), choice) {
 		t.node = t.node.Add(choice)
+		if t.Metrics != nil {
+			t.Metrics.observeDepth(pathDepth(t.node, choice))
+		}
 	}
 }
 
@@ -85,6 +98,22 @@ func (t *Tree) RecordPoll(votes ids.Bag) {
 // RecordUnsuccessfulPoll implements the Consensus interface
 func (t *Tree) RecordUnsuccessfulPoll() { t.shouldReset = true }
 
+// NumNodes returns the number of nodes that are currently in the tree
+func (t *Tree) NumNodes() int { return t.numNodes }
+
+// Depth returns the number of nodes on the longest path from the root of the
+// tree to a leaf
+func (t *Tree) Depth() int { return depth(t.node) }
+
+// allocate registers that [numNodes] new nodes were added to the tree
+func (t *Tree) allocate(numNodes int) {
+	t.numNodes += numNodes
+	t.Metrics.allocated(numNodes)
+}
+
+// release registers that [numNodes] nodes were removed from the tree
+func (t *Tree) release(numNodes int) { t.numNodes -= numNodes }
+
 func (t *Tree) String() string {
 	builder := strings.Builder{}
 
@@ -128,9 +157,55 @@ type node interface {
 	// Returns true if consensus has been reached on this node
 	Finalized() bool
 
+	// Returns the, possibly empty, sub-trees of this node
+	Children() []node
 	Printable() (string, []node)
 }
 
+// size returns the number of nodes in the sub-tree rooted at [n]
+func size(n node) int {
+	if n == nil {
+		return 0
+	}
+	numNodes := 1
+	for _, child := range n.Children() {
+		numNodes += size(child)
+	}
+	return numNodes
+}
+
+// depth returns the number of nodes on the longest path from [n] to a leaf
+func depth(n node) int {
+	if n == nil {
+		return 0
+	}
+	maxDepth := 0
+	for _, child := range n.Children() {
+		if childDepth := depth(child); childDepth > maxDepth {
+			maxDepth = childDepth
+		}
+	}
+	return maxDepth + 1
+}
+
+// pathDepth returns the number of nodes on the path from [n] to the leaf that
+// [choice] was added at. Unlike depth, only a single path is traversed.
+func pathDepth(n node, choice ids.ID) int {
+	numNodes := 0
+	for n != nil {
+		numNodes++
+		switch typedNode := n.(type) {
+		case *unaryNode:
+			n = typedNode.child
+		case *binaryNode:
+			n = typedNode.children[choice.Bit(uint(typedNode.bit))]
+		default:
+			n = nil
+		}
+	}
+	return numNodes
+}
+
 // unary is a node with either no children, or a single child. It handles the
 // voting on a range of identical, virtuous, snowball instances.
 type unaryNode struct {
@@ -352,13 +427,18 @@ func (u *unaryNode) Add(newChoice ids.ID) node {
 			b.children[bit] = u.child
 			if u.child != nil {
 				b.children[1-bit] = newChild
+				u.tree.allocate(1)
 			}
+			// b replaces this node
+			u.tree.allocate(1)
+			u.tree.release(1)
 			return b
 		case index == u.decidedPrefix:
 			// This node was split on the first bit. (Case 3. from above)
 			u.decidedPrefix++
 			b.children[bit] = u
 			b.children[1-bit] = newChild
+			u.tree.allocate(2)
 			return b
 		case index == u.commonPrefix-1:
 			// This node was split on the last bit. (Case 4. from above)
@@ -366,8 +446,10 @@ func (u *unaryNode) Add(newChoice ids.ID) node {
 			b.children[bit] = u.child
 			if u.child != nil {
 				b.children[1-bit] = newChild
+				u.tree.allocate(1)
 			}
 			u.child = b
+			u.tree.allocate(1)
 			return u
 		default:
 			// This node was split on an interior bit. (Case 5. from above)
@@ -375,6 +457,7 @@ func (u *unaryNode) Add(newChoice ids.ID) node {
 			u.decidedPrefix = index + 1
 			b.children[bit] = u
 			b.children[1-bit] = newChild
+			u.tree.allocate(3)
 			return &unaryNode{
 				tree:          u.tree,
 				preference:    u.preference,
@@ -413,6 +496,7 @@ func (u *unaryNode) RecordPoll(votes ids.Bag, reset bool) node {
 
 			// If I'm now decided, return my child
 			if u.Finalized() {
+				u.tree.release(1)
 				return u.child.RecordPoll(votes, u.shouldReset)
 			}
 			u.child = u.child.RecordPoll(votes, u.shouldReset)
@@ -434,6 +518,13 @@ func (u *unaryNode) RecordPoll(votes ids.Bag, reset bool) node {
 
 func (u *unaryNode) Finalized() bool { return u.snowball.Finalized() }
 
+func (u *unaryNode) Children() []node {
+	if u.child == nil {
+		return nil
+	}
+	return []node{u.child}
+}
+
 func (u *unaryNode) Printable() (string, []node) {
 	s := fmt.Sprintf("%s Bits = [%d, %d)",
 		u.snowball, u.decidedPrefix, u.commonPrefix)
@@ -521,6 +612,7 @@ func (b *binaryNode) RecordPoll(votes ids.Bag, reset bool) node {
 			if b.snowball.Finalized() {
 				// If we are decided here, that means we must have decided due
 				// to this poll. Therefore, we must have decided on bit.
+				b.tree.release(1 + size(b.children[1-bit]))
 				return child.RecordPoll(filteredVotes, b.shouldReset[bit])
 			}
 			newChild := child.RecordPoll(filteredVotes, b.shouldReset[bit])
@@ -538,6 +630,13 @@ func (b *binaryNode) RecordPoll(votes ids.Bag, reset bool) node {
 
 func (b *binaryNode) Finalized() bool { return b.snowball.Finalized() }
 
+func (b *binaryNode) Children() []node {
+	if b.children[0] == nil {
+		return nil
+	}
+	return []node{b.children[0], b.children[1]}
+}
+
 func (b *binaryNode) Printable() (string, []node) {
 	s := fmt.Sprintf("%s Bit = %d", b.snowball, b.bit)
 	if b.children[0] == nil {
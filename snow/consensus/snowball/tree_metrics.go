@@ -0,0 +1,57 @@
+// (c) 2019-2020, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package snowball
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/ava-labs/avalanchego/utils/wrappers"
+)
+
+var depthBuckets = []float64{1, 2, 4, 8, 16, 32, 64, 128, 256, 512}
+
+// TreeMetrics reports the memory usage of snowball trees. It's initialized
+// once per consensus instance and shared by all of the instance's trees.
+type TreeMetrics struct {
+	// nodesAllocated tracks the number of nodes that have been allocated
+	nodesAllocated prometheus.Counter
+
+	// depth tracks the depth of the path to a choice after it was added to a
+	// tree
+	depth prometheus.Histogram
+}
+
+// Initialize the metrics and register them in [registerer]
+func (m *TreeMetrics) Initialize(namespace string, registerer prometheus.Registerer) error {
+	m.nodesAllocated = prometheus.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "tree_nodes_allocated",
+		Help:      "Number of snowball tree nodes allocated",
+	})
+	m.depth = prometheus.NewHistogram(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "tree_depth",
+		Help:      "Depth of the path to a choice after it was added to a snowball tree",
+		Buckets:   depthBuckets,
+	})
+
+	errs := wrappers.Errs{}
+	errs.Add(
+		registerer.Register(m.nodesAllocated),
+		registerer.Register(m.depth),
+	)
+	return errs.Err
+}
+
+func (m *TreeMetrics) allocated(numNodes int) {
+	if m != nil && numNodes > 0 {
+		m.nodesAllocated.Add(float64(numNodes))
+	}
+}
+
+func (m *TreeMetrics) observeDepth(depth int) {
+	if m != nil {
+		m.depth.Observe(float64(depth))
+	}
+}
@@ -9,6 +9,7 @@ import (
 	"testing"
 
 	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
 
 	"github.com/ava-labs/avalanchego/ids"
 )
@@ -1089,3 +1090,88 @@ func TestSnowballFilterBinaryChildren(t *testing.T) {
 		}
 	}
 }
+
+func TestSnowballTreeNumNodes(t *testing.T) {
+	numColors := 50
+	params := Parameters{
+		Metrics: prometheus.NewRegistry(),
+		K:       1, Alpha: 1, BetaVirtuous: 3, BetaRogue: 5,
+	}
+	rand.Seed(0)
+
+	colors := []ids.ID{ids.GenerateTestID()}
+	tree := Tree{}
+	tree.Initialize(params, colors[0])
+	for i := 1; i < numColors; i++ {
+		color := ids.GenerateTestID()
+		colors = append(colors, color)
+		tree.Add(color)
+
+		if numNodes, expected := tree.NumNodes(), size(tree.node); numNodes != expected {
+			t.Fatalf("Wrong number of nodes after adding a choice. Expected %d got %d", expected, numNodes)
+		}
+	}
+
+	// Vote for random colors for a while, and then converge on the preference
+	for i := 0; !tree.Finalized(); i++ {
+		votes := ids.Bag{}
+		if i < 1000 {
+			votes.Add(colors[rand.Intn(len(colors))]) // #nosec G404
+		} else {
+			votes.Add(tree.Preference())
+		}
+		tree.RecordPoll(votes)
+
+		if numNodes, expected := tree.NumNodes(), size(tree.node); numNodes != expected {
+			t.Fatalf("Wrong number of nodes after a poll. Expected %d got %d", expected, numNodes)
+		}
+	}
+
+	if numNodes := tree.NumNodes(); numNodes != 1 {
+		t.Fatalf("Finalized tree should only contain a single node, but contains %d", numNodes)
+	}
+}
+
+func TestSnowballTreePathDepth(t *testing.T) {
+	c0000 := ids.ID{0b00000000}
+	c1000 := ids.ID{0b00000001}
+	c0010 := ids.ID{0b00000100}
+
+	tree := Tree{}
+	tree.Initialize(Parameters{K: 1, Alpha: 1, BetaVirtuous: 1, BetaRogue: 2}, c0000)
+	tree.Add(c1000)
+	tree.Add(c0010)
+
+	if depth := pathDepth(tree.node, c0000); depth != 4 {
+		t.Fatalf("Wrong path depth. Expected 4 got %d", depth)
+	} else if depth := pathDepth(tree.node, c0010); depth != 4 {
+		t.Fatalf("Wrong path depth. Expected 4 got %d", depth)
+	} else if depth := pathDepth(tree.node, c1000); depth != 2 {
+		t.Fatalf("Wrong path depth. Expected 2 got %d", depth)
+	}
+}
+
+func TestSnowballTreesShareMetrics(t *testing.T) {
+	c0000 := ids.ID{0b00000000}
+	c1000 := ids.ID{0b00000001}
+
+	metrics := &TreeMetrics{}
+	if err := metrics.Initialize("", prometheus.NewRegistry()); err != nil {
+		t.Fatal(err)
+	}
+	params := Parameters{K: 1, Alpha: 1, BetaVirtuous: 1, BetaRogue: 2}
+
+	for i := 0; i < 2; i++ {
+		tree := Tree{Metrics: metrics}
+		tree.Initialize(params, c0000)
+		tree.Add(c1000)
+		if numNodes := tree.NumNodes(); numNodes != 3 {
+			t.Fatalf("Wrong number of nodes. Expected 3 got %d", numNodes)
+		}
+	}
+
+	// Both trees report into the same metrics
+	if allocated := testutil.ToFloat64(metrics.nodesAllocated); allocated != 6 {
+		t.Fatalf("Wrong number of allocated nodes. Expected 6 got %f", allocated)
+	}
+}
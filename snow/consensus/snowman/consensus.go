@@ -46,6 +46,9 @@ type Consensus interface {
 	// decisions.
 	Preference() ids.ID
 
+	// LastAccepted returns the ID and height of the last accepted block.
+	LastAccepted() (ids.ID, uint64)
+
 	// RecordPoll collects the results of a network poll. Assumes all decisions
 	// have been previously added. Returns if a critical error has occurred.
 	RecordPoll(ids.Bag) error
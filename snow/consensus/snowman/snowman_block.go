@@ -12,7 +12,7 @@ import (
 // Tracks the state of a snowman block
 type snowmanBlock struct {
 	// pointer to the snowman instance this node is managed by
-	sm Consensus
+	sm *Topological
 
 	// block that this node contains. For the genesis, this value will be nil
 	blk Block
@@ -38,7 +38,7 @@ func (n *snowmanBlock) AddChild(child Block) {
 	// if the snowball instance is nil, this is the first child. So the instance
 	// should be initialized.
 	if n.sb == nil {
-		n.sb = &snowball.Tree{}
+		n.sb = &snowball.Tree{Metrics: &n.sm.treeMetrics}
 		n.sb.Initialize(n.sm.Parameters(), childID)
 		n.children = make(map[ids.ID]Block)
 	} else {
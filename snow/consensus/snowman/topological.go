@@ -38,6 +38,9 @@ type Topological struct {
 	// instances
 	params snowball.Parameters
 
+	// treeMetrics is shared by the snowball instances of every block
+	treeMetrics snowball.TreeMetrics
+
 	// head is the last accepted block
 	head ids.ID
 
@@ -79,6 +82,9 @@ func (ts *Topological) Initialize(ctx *snow.Context, params snowball.Parameters,
 	if err := ts.Metrics.Initialize("blks", "block(s)", ctx.Log, params.Namespace, params.Metrics); err != nil {
 		return err
 	}
+	if err := ts.treeMetrics.Initialize(params.Namespace, params.Metrics); err != nil {
+		return err
+	}
 
 	ts.ctx = ctx
 	ts.params = params
@@ -184,6 +190,9 @@ func (ts *Topological) IsPreferred(blk Block) bool {
 // Preference implements the Snowman interface
 func (ts *Topological) Preference() ids.ID { return ts.tail }
 
+// LastAccepted implements the Snowman interface
+func (ts *Topological) LastAccepted() (ids.ID, uint64) { return ts.head, ts.height }
+
 // RecordPoll implements the Snowman interface
 //
 // The votes bag contains at most K votes for blocks in the tree. If there is a
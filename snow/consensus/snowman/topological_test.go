@@ -4,7 +4,70 @@
 package snowman
 
 import (
+	"strings"
 	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+
+	"github.com/ava-labs/avalanchego/ids"
+	"github.com/ava-labs/avalanchego/snow"
+	"github.com/ava-labs/avalanchego/snow/choices"
+	"github.com/ava-labs/avalanchego/snow/consensus/snowball"
 )
 
 func TestTopological(t *testing.T) { ConsensusTest(t, TopologicalFactory{}) }
+
+func TestTopologicalTreesShareMetrics(t *testing.T) {
+	sm := &Topological{}
+
+	ctx := snow.DefaultContextTest()
+	registry := prometheus.NewRegistry()
+	params := snowball.Parameters{
+		Metrics:               registry,
+		K:                     1,
+		Alpha:                 1,
+		BetaVirtuous:          3,
+		BetaRogue:             5,
+		ConcurrentRepolls:     1,
+		OptimalProcessing:     1,
+		MaxOutstandingItems:   1,
+		MaxItemProcessingTime: 1,
+	}
+	if err := sm.Initialize(ctx, params, GenesisID, GenesisHeight); err != nil {
+		t.Fatal(err)
+	}
+
+	// Each block gets its own snowball tree once a child is added to it
+	block0 := &TestBlock{
+		TestDecidable: choices.TestDecidable{
+			IDV:     ids.Empty.Prefix(1),
+			StatusV: choices.Processing,
+		},
+		ParentV: Genesis,
+		HeightV: Genesis.HeightV + 1,
+	}
+	block1 := &TestBlock{
+		TestDecidable: choices.TestDecidable{
+			IDV:     ids.Empty.Prefix(2),
+			StatusV: choices.Processing,
+		},
+		ParentV: block0,
+		HeightV: block0.HeightV + 1,
+	}
+	if err := sm.Add(block0); err != nil {
+		t.Fatal(err)
+	}
+	if err := sm.Add(block1); err != nil {
+		t.Fatal(err)
+	}
+
+	expected := `
+# HELP tree_nodes_allocated Number of snowball tree nodes allocated
+# TYPE tree_nodes_allocated counter
+tree_nodes_allocated 2
+`
+	if err := testutil.GatherAndCompare(registry, strings.NewReader(expected), "tree_nodes_allocated"); err != nil {
+		t.Fatal(err)
+	}
+}
@@ -176,8 +176,14 @@ func (c *common) acceptTx(tx Tx) error {
 	return nil
 }
 
-// reject the provided tx.
-func (c *common) rejectTx(tx Tx) error {
+// reject the provided tx for [reason].
+func (c *common) rejectTx(tx Tx, reason Rejection) error {
+	if tx, ok := tx.(RejectionTx); ok {
+		if err := tx.RecordRejection(reason); err != nil {
+			return err
+		}
+	}
+
 	// Reject is called before notifying the IPC so that rejections that
 	// cause fatal errors aren't sent to an IPC peer.
 	if err := tx.Reject(); err != nil {
@@ -293,14 +299,17 @@ type rejector struct {
 
 func (r *rejector) Dependencies() ids.Set { return r.deps }
 
-func (r *rejector) Fulfill(ids.ID) {
+func (r *rejector) Fulfill(depID ids.ID) {
 	if r.rejected || r.errs.Errored() {
 		return
 	}
 	r.rejected = true
 	asSet := ids.Set{}
 	asSet.Add(r.txID)
-	r.errs.Add(r.g.reject(asSet))
+	r.errs.Add(r.g.reject(asSet, Rejection{
+		Cause: DependencyRejected,
+		TxID:  depID,
+	}))
 }
 
 func (*rejector) Abandon(ids.ID) {}
@@ -66,6 +66,6 @@ type Consensus interface {
 	// Accept the provided tx remove it from the graph
 	accept(txID ids.ID) error
 
-	// Reject all the provided txs and remove them from the graph
-	reject(txIDs ids.Set) error
+	// Reject all the provided txs, for [reason], and remove them from the graph
+	reject(txIDs ids.Set, reason Rejection) error
 }
@@ -42,6 +42,9 @@ var (
 		ErrorOnRejectingLowerConfidenceConflictTest,
 		ErrorOnRejectingHigherConfidenceConflictTest,
 		UTXOCleanupTest,
+		RejectingConflictingDependentTest,
+		RejectingPendingAcceptTest,
+		RejectionReasonTest,
 	}
 
 	Red, Green, Blue, Alpha *TestTx
@@ -1365,6 +1368,126 @@ func UTXOCleanupTest(t *testing.T, factory Factory) {
 	assert.Equal(t, choices.Accepted, Blue.Status())
 }
 
+func RejectingConflictingDependentTest(t *testing.T, factory Factory) {
+	graph := factory.New()
+
+	// purple conflicts with green, and depends on red, which also conflicts
+	// with green
+	purple := &TestTx{
+		TestDecidable: choices.TestDecidable{
+			IDV:     ids.Empty.Prefix(7),
+			StatusV: choices.Processing,
+		},
+		DependenciesV: []Tx{Red},
+		InputIDsV:     Blue.InputIDsV[:1],
+	}
+
+	params := sbcon.Parameters{
+		Metrics:               prometheus.NewRegistry(),
+		K:                     1,
+		Alpha:                 1,
+		BetaVirtuous:          1,
+		BetaRogue:             2,
+		ConcurrentRepolls:     1,
+		OptimalProcessing:     1,
+		MaxOutstandingItems:   1,
+		MaxItemProcessingTime: 1,
+	}
+	err := graph.Initialize(snow.DefaultContextTest(), params)
+	assert.NoError(t, err)
+
+	err = graph.Add(Red)
+	assert.NoError(t, err)
+
+	err = graph.Add(purple)
+	assert.NoError(t, err)
+
+	err = graph.Add(Green)
+	assert.NoError(t, err)
+
+	greenVotes := ids.Bag{}
+	greenVotes.Add(Green.ID())
+	_, err = graph.RecordPoll(greenVotes)
+	assert.NoError(t, err)
+
+	// Accepting green rejects red, which rejects purple while the conflicts of
+	// green are still being rejected
+	changed, err := graph.RecordPoll(greenVotes)
+	assert.NoError(t, err)
+	assert.True(t, changed, "should have accepted the green tx")
+
+	assert.Equal(t, choices.Accepted, Green.Status())
+	assert.Equal(t, choices.Rejected, Red.Status())
+	assert.Equal(t, choices.Rejected, purple.Status())
+	assert.True(t, graph.Finalized())
+}
+
+func RejectingPendingAcceptTest(t *testing.T, factory Factory) {
+	graph := factory.New()
+
+	// purple conflicts with red, and depends on alpha
+	purple := &TestTx{
+		TestDecidable: choices.TestDecidable{
+			IDV:     ids.Empty.Prefix(7),
+			StatusV: choices.Processing,
+		},
+		DependenciesV: []Tx{Alpha},
+		InputIDsV:     Red.InputIDsV,
+	}
+
+	params := sbcon.Parameters{
+		Metrics:               prometheus.NewRegistry(),
+		K:                     1,
+		Alpha:                 1,
+		BetaVirtuous:          1,
+		BetaRogue:             2,
+		ConcurrentRepolls:     1,
+		OptimalProcessing:     1,
+		MaxOutstandingItems:   1,
+		MaxItemProcessingTime: 1,
+	}
+	err := graph.Initialize(snow.DefaultContextTest(), params)
+	assert.NoError(t, err)
+
+	err = graph.Add(Alpha)
+	assert.NoError(t, err)
+
+	err = graph.Add(purple)
+	assert.NoError(t, err)
+
+	err = graph.Add(Red)
+	assert.NoError(t, err)
+
+	// purple is finalized, but its acceptance is pending on alpha
+	purpleVotes := ids.Bag{}
+	purpleVotes.Add(purple.ID())
+	for i := 0; i < params.BetaRogue; i++ {
+		_, err = graph.RecordPoll(purpleVotes)
+		assert.NoError(t, err)
+	}
+	assert.Equal(t, choices.Processing, purple.Status())
+
+	// red is finalized after purple, which rejects purple
+	redVotes := ids.Bag{}
+	redVotes.Add(Red.ID())
+	for i := 0; i <= params.BetaRogue && Red.Status() == choices.Processing; i++ {
+		_, err = graph.RecordPoll(redVotes)
+		assert.NoError(t, err)
+	}
+	assert.Equal(t, choices.Accepted, Red.Status())
+	assert.Equal(t, choices.Rejected, purple.Status())
+
+	// Accepting alpha shouldn't attempt to accept the rejected purple tx
+	alphaVotes := ids.Bag{}
+	alphaVotes.Add(Alpha.ID())
+	_, err = graph.RecordPoll(alphaVotes)
+	assert.NoError(t, err)
+
+	assert.Equal(t, choices.Accepted, Alpha.Status())
+	assert.Equal(t, choices.Rejected, purple.Status())
+	assert.True(t, graph.Finalized())
+}
+
 func StringTest(t *testing.T, factory Factory, prefix string) {
 	graph := factory.New()
 
@@ -1596,3 +1719,84 @@ func StringTest(t *testing.T, factory Factory, prefix string) {
 		t.Fatalf("%s should have been rejected", Blue.ID())
 	}
 }
+
+// rejectionTx records the reason it was rejected
+type rejectionTx struct {
+	*TestTx
+
+	rejection *Rejection
+}
+
+func (tx *rejectionTx) RecordRejection(reason Rejection) error {
+	tx.rejection = &reason
+	return nil
+}
+
+func RejectionReasonTest(t *testing.T, factory Factory) {
+	graph := factory.New()
+
+	inputID := ids.Empty.Prefix(8)
+	red := &rejectionTx{TestTx: &TestTx{
+		TestDecidable: choices.TestDecidable{
+			IDV:     ids.Empty.Prefix(9),
+			StatusV: choices.Processing,
+		},
+		InputIDsV: []ids.ID{inputID},
+	}}
+	green := &rejectionTx{TestTx: &TestTx{
+		TestDecidable: choices.TestDecidable{
+			IDV:     ids.Empty.Prefix(10),
+			StatusV: choices.Processing,
+		},
+		InputIDsV: []ids.ID{inputID},
+	}}
+	purple := &rejectionTx{TestTx: &TestTx{
+		TestDecidable: choices.TestDecidable{
+			IDV:     ids.Empty.Prefix(11),
+			StatusV: choices.Processing,
+		},
+		DependenciesV: []Tx{red},
+		InputIDsV:     []ids.ID{ids.Empty.Prefix(12)},
+	}}
+
+	params := sbcon.Parameters{
+		Metrics:               prometheus.NewRegistry(),
+		K:                     1,
+		Alpha:                 1,
+		BetaVirtuous:          1,
+		BetaRogue:             1,
+		ConcurrentRepolls:     1,
+		OptimalProcessing:     1,
+		MaxOutstandingItems:   1,
+		MaxItemProcessingTime: 1,
+	}
+	if err := graph.Initialize(snow.DefaultContextTest(), params); err != nil {
+		t.Fatal(err)
+	}
+	for _, tx := range []Tx{red, green, purple} {
+		if err := graph.Add(tx); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	votes := ids.Bag{}
+	votes.Add(green.ID())
+	if _, err := graph.RecordPoll(votes); err != nil {
+		t.Fatal(err)
+	}
+
+	switch {
+	case green.Status() != choices.Accepted:
+		t.Fatalf("%s should have been accepted", green.ID())
+	case green.rejection != nil:
+		t.Fatalf("%s shouldn't have recorded a rejection", green.ID())
+	case red.Status() != choices.Rejected:
+		t.Fatalf("%s should have been rejected", red.ID())
+	case red.rejection == nil || *red.rejection != (Rejection{Cause: PrecludedByConflict, TxID: green.ID()}):
+		t.Fatalf("%s should have been precluded by %s", red.ID(), green.ID())
+	case purple.Status() != choices.Rejected:
+		t.Fatalf("%s should have been rejected", purple.ID())
+	case purple.rejection == nil || *purple.rejection != (Rejection{Cause: DependencyRejected, TxID: red.ID()}):
+		t.Fatalf("%s should have been rejected due to its dependency %s", purple.ID(), red.ID())
+	}
+}
@@ -0,0 +1,19 @@
+// (c) 2019-2020, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package snowstorm
+
+import (
+	"time"
+)
+
+// DelayedTx is optionally implemented by txs that shouldn't be issued before a
+// time chosen by the VM. The engine holds such txs until then, rather than
+// rejecting them, which lets the VM shape the rate that txs are issued at.
+type DelayedTx interface {
+	Tx
+
+	// NotBefore returns the earliest time this tx may be issued into
+	// consensus. A zero time means the tx may be issued immediately.
+	NotBefore() time.Time
+}
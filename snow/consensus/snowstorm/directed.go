@@ -257,7 +257,13 @@ func (dg *Directed) String() string {
 
 // accept the named txID and remove it from the graph
 func (dg *Directed) accept(txID ids.ID) error {
-	txNode := dg.txs[txID]
+	txNode, exists := dg.txs[txID]
+	if !exists {
+		// The tx may have been rejected, due to the acceptance of a conflicting
+		// tx, while it was waiting for its dependencies to be accepted.
+		return nil
+	}
+
 	// We are accepting the tx, so we should remove the node from the graph.
 	delete(dg.txs, txID)
 
@@ -273,22 +279,33 @@ func (dg *Directed) accept(txID ids.ID) error {
 	dg.preferences.Remove(txID)
 
 	// Reject all the txs that conflicted with this tx.
-	if err := dg.reject(txNode.ins); err != nil {
+	reason := Rejection{
+		Cause: PrecludedByConflict,
+		TxID:  txID,
+	}
+	if err := dg.reject(txNode.ins, reason); err != nil {
 		return err
 	}
 	// While it is typically true that a tx this is being accepted is preferred,
 	// it is possible for this to not be the case. So this is handled for
 	// completeness.
-	if err := dg.reject(txNode.outs); err != nil {
+	if err := dg.reject(txNode.outs, reason); err != nil {
 		return err
 	}
 	return dg.acceptTx(txNode.tx)
 }
 
-// reject all the named txIDs and remove them from the graph
-func (dg *Directed) reject(conflictIDs ids.Set) error {
+// reject all the named txIDs, for [reason], and remove them from the graph
+func (dg *Directed) reject(conflictIDs ids.Set, reason Rejection) error {
 	for conflictKey := range conflictIDs {
-		conflict := dg.txs[conflictKey]
+		conflict, exists := dg.txs[conflictKey]
+		if !exists {
+			// This tx may have already been rejected, either because it
+			// conflicted with an accepted tx or because one of its
+			// dependencies was rejected.
+			continue
+		}
+
 		// This tx is no longer an option for consuming the UTXOs from its
 		// inputs, so we should remove their reference to this tx.
 		for _, inputID := range conflict.tx.InputIDs() {
@@ -322,7 +339,7 @@ func (dg *Directed) reject(conflictIDs ids.Set) error {
 		dg.removeConflict(conflictKey, conflict.ins)
 		dg.removeConflict(conflictKey, conflict.outs)
 
-		if err := dg.rejectTx(conflict.tx); err != nil {
+		if err := dg.rejectTx(conflict.tx, reason); err != nil {
 			return err
 		}
 	}
@@ -0,0 +1,14 @@
+// (c) 2019-2020, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package snowstorm
+
+// FeeTx is optionally implemented by txs that pay a fee. When the engine can't
+// re-poll every preference, it prefers to re-poll txs that pay higher fees.
+type FeeTx interface {
+	Tx
+
+	// Fee returns the amount this tx pays to be issued, in the smallest
+	// denomination of the chain's fee asset.
+	Fee() uint64
+}
@@ -0,0 +1,245 @@
+// (c) 2019-2020, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package snowstorm
+
+import (
+	"math/rand"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/ava-labs/avalanchego/ids"
+	"github.com/ava-labs/avalanchego/snow"
+	"github.com/ava-labs/avalanchego/snow/choices"
+
+	sbcon "github.com/ava-labs/avalanchego/snow/consensus/snowball"
+)
+
+const (
+	fuzzNumUTXOs        = 16
+	fuzzNumOps          = 256
+	fuzzNumRandomSeeds  = 64
+	fuzzMaxFinalizeRuns = 1024
+)
+
+// fuzzRegressionSeeds are always run, in addition to the generated seeds. When
+// a seed finds an invariant violation, it should be added here once fixed.
+var fuzzRegressionSeeds = []int64{
+	0,
+	1,
+	42,
+	1337,
+	2020,
+}
+
+// fuzzTx is a TestTx that records how many times it was decided
+type fuzzTx struct {
+	TestTx
+
+	numAccepts, numRejects int
+}
+
+func (tx *fuzzTx) Accept() error {
+	tx.numAccepts++
+	return tx.TestTx.Accept()
+}
+
+func (tx *fuzzTx) Reject() error {
+	tx.numRejects++
+	return tx.TestTx.Reject()
+}
+
+func TestDirectedFuzz(t *testing.T) { InvariantsTest(t, DirectedFactory{}) }
+
+func TestInputFuzz(t *testing.T) { InvariantsTest(t, InputFactory{}) }
+
+// InvariantsTest applies random sequences of Add and RecordPoll calls to instances
+// created by [factory], with randomly generated conflict and dependency graphs,
+// and checks that the consensus invariants hold after every call.
+func InvariantsTest(t *testing.T, factory Factory) {
+	seeds := append([]int64(nil), fuzzRegressionSeeds...)
+	for seed := int64(0); seed < fuzzNumRandomSeeds; seed++ {
+		seeds = append(seeds, 1<<32+seed)
+	}
+	for _, seed := range seeds {
+		fuzzSeed(t, factory, seed)
+	}
+}
+
+func fuzzSeed(t *testing.T, factory Factory, seed int64) {
+	r := rand.New(rand.NewSource(seed)) // #nosec G404
+
+	params := sbcon.Parameters{
+		Metrics:               prometheus.NewRegistry(),
+		K:                     3,
+		Alpha:                 2,
+		BetaVirtuous:          2,
+		BetaRogue:             3,
+		ConcurrentRepolls:     1,
+		OptimalProcessing:     1,
+		MaxOutstandingItems:   1,
+		MaxItemProcessingTime: 1,
+	}
+	graph := factory.New()
+	if err := graph.Initialize(snow.DefaultContextTest(), params); err != nil {
+		t.Fatalf("seed %d: %s", seed, err)
+	}
+
+	utxos := make([]ids.ID, fuzzNumUTXOs)
+	for i := range utxos {
+		utxos[i] = ids.Empty.Prefix(uint64(i))
+	}
+
+	txs := []*fuzzTx(nil)
+	for op := 0; op < fuzzNumOps; op++ {
+		if len(txs) == 0 || r.Intn(3) == 0 {
+			tx := newFuzzTx(r, utxos, txs)
+			if tx == nil {
+				continue
+			}
+			txs = append(txs, tx)
+			if err := graph.Add(tx); err != nil {
+				t.Fatalf("seed %d: Add(%s) failed: %s", seed, tx.ID(), err)
+			}
+		} else {
+			votes := ids.Bag{}
+			for i := 0; i < params.K; i++ {
+				votes.Add(txs[r.Intn(len(txs))].ID())
+			}
+			if _, err := graph.RecordPoll(votes); err != nil {
+				t.Fatalf("seed %d: RecordPoll failed: %s", seed, err)
+			}
+		}
+		checkFuzzInvariants(t, seed, txs)
+	}
+
+	// Vote for the oldest processing tx until every tx has been decided. As in
+	// Avalanche, a vote for a tx is also a vote for its processing ancestors.
+	for i := 0; i < fuzzMaxFinalizeRuns; i++ {
+		votes := ids.Bag{}
+		for _, tx := range txs {
+			if tx.Status() != choices.Processing {
+				continue
+			}
+			for ancestors := []Tx{tx}; len(ancestors) > 0; {
+				ancestor := ancestors[len(ancestors)-1]
+				ancestors = append(ancestors[:len(ancestors)-1], ancestor.Dependencies()...)
+				if ancestor.Status() == choices.Processing {
+					votes.AddCount(ancestor.ID(), params.Alpha)
+				}
+			}
+			break
+		}
+		if votes.Len() == 0 {
+			break
+		}
+		if _, err := graph.RecordPoll(votes); err != nil {
+			t.Fatalf("seed %d: RecordPoll failed: %s", seed, err)
+		}
+		checkFuzzInvariants(t, seed, txs)
+	}
+
+	for _, tx := range txs {
+		if !tx.Status().Decided() {
+			t.Fatalf("seed %d: tx %s is %s after %d polls", seed, tx.ID(), tx.Status(), fuzzMaxFinalizeRuns)
+		}
+	}
+	if !graph.Finalized() {
+		t.Fatalf("seed %d: should be finalized after every tx was decided", seed)
+	}
+
+	switch graph := graph.(type) {
+	case *Directed:
+		if len(graph.txs) != 0 || len(graph.utxos) != 0 {
+			t.Fatalf("seed %d: %d txs and %d utxos left in the graph after finalizing",
+				seed, len(graph.txs), len(graph.utxos))
+		}
+	case *Input:
+		if len(graph.txs) != 0 || len(graph.utxos) != 0 {
+			t.Fatalf("seed %d: %d txs and %d utxos left in the graph after finalizing",
+				seed, len(graph.txs), len(graph.utxos))
+		}
+	}
+}
+
+// newFuzzTx returns a new tx that consumes random UTXOs that haven't been
+// consumed by an accepted tx, and possibly depends on a tx in [txs] that
+// hasn't been rejected. This mirrors the txs that a VM would allow to be
+// issued. If no UTXOs are available, nil is returned.
+func newFuzzTx(r *rand.Rand, utxos []ids.ID, txs []*fuzzTx) *fuzzTx {
+	unavailable := ids.Set{}
+	for _, tx := range txs {
+		if tx.Status() == choices.Accepted {
+			unavailable.Add(tx.InputIDs()...)
+		}
+	}
+
+	tx := &fuzzTx{TestTx: TestTx{TestDecidable: choices.TestDecidable{
+		IDV:     ids.Empty.Prefix(uint64(fuzzNumUTXOs + len(txs))),
+		StatusV: choices.Processing,
+	}}}
+
+	if len(txs) > 0 && r.Intn(3) == 0 {
+		dep := txs[r.Intn(len(txs))]
+		if dep.Status() != choices.Rejected {
+			tx.DependenciesV = []Tx{dep}
+			// A tx can't consume the same UTXOs as any of its ancestors
+			for ancestors := []Tx{dep}; len(ancestors) > 0; {
+				ancestor := ancestors[len(ancestors)-1]
+				ancestors = append(ancestors[:len(ancestors)-1], ancestor.Dependencies()...)
+				unavailable.Add(ancestor.InputIDs()...)
+			}
+		}
+	}
+
+	available := []ids.ID(nil)
+	for _, utxo := range utxos {
+		if !unavailable.Contains(utxo) {
+			available = append(available, utxo)
+		}
+	}
+	if len(available) == 0 {
+		return nil
+	}
+
+	numInputs := 1 + r.Intn(2)
+	for _, i := range r.Perm(len(available)) {
+		if len(tx.InputIDsV) == numInputs {
+			break
+		}
+		tx.InputIDsV = append(tx.InputIDsV, available[i])
+	}
+	return tx
+}
+
+func checkFuzzInvariants(t *testing.T, seed int64, txs []*fuzzTx) {
+	// UTXO ID --> ID of the accepted tx that consumed it
+	spent := map[ids.ID]ids.ID{}
+	for _, tx := range txs {
+		switch {
+		case tx.numAccepts+tx.numRejects > 1:
+			t.Fatalf("seed %d: tx %s was accepted %d times and rejected %d times",
+				seed, tx.ID(), tx.numAccepts, tx.numRejects)
+		case tx.numAccepts == 1 && tx.Status() != choices.Accepted,
+			tx.numRejects == 1 && tx.Status() != choices.Rejected:
+			t.Fatalf("seed %d: tx %s has status %s after being decided", seed, tx.ID(), tx.Status())
+		}
+
+		if tx.Status() != choices.Accepted {
+			continue
+		}
+		for _, dep := range tx.Dependencies() {
+			if dep.Status() != choices.Accepted {
+				t.Fatalf("seed %d: tx %s was accepted while its dependency %s is %s",
+					seed, tx.ID(), dep.ID(), dep.Status())
+			}
+		}
+		for _, utxo := range tx.InputIDs() {
+			if conflictID, ok := spent[utxo]; ok {
+				t.Fatalf("seed %d: conflicting txs %s and %s were both accepted", seed, tx.ID(), conflictID)
+			}
+			spent[utxo] = tx.ID()
+		}
+	}
+}
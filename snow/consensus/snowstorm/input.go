@@ -341,7 +341,13 @@ func (ig *Input) String() string {
 
 // accept the named txID and remove it from the graph
 func (ig *Input) accept(txID ids.ID) error {
-	txNode := ig.txs[txID]
+	txNode, exists := ig.txs[txID]
+	if !exists {
+		// The tx may have been rejected, due to the acceptance of a conflicting
+		// tx, while it was waiting for its dependencies to be accepted.
+		return nil
+	}
+
 	// We are accepting the tx, so we should remove the node from the graph.
 	delete(ig.txs, txID)
 
@@ -360,16 +366,26 @@ func (ig *Input) accept(txID ids.ID) error {
 	ig.preferences.Remove(txID)
 
 	// Reject all the txs that conflicted with this tx.
-	if err := ig.reject(conflicts); err != nil {
+	if err := ig.reject(conflicts, Rejection{
+		Cause: PrecludedByConflict,
+		TxID:  txID,
+	}); err != nil {
 		return err
 	}
 	return ig.acceptTx(txNode.tx)
 }
 
-// reject all the named txIDs and remove them from their conflict sets
-func (ig *Input) reject(conflictIDs ids.Set) error {
+// reject all the named txIDs, for [reason], and remove them from their
+// conflict sets
+func (ig *Input) reject(conflictIDs ids.Set, reason Rejection) error {
 	for conflictKey := range conflictIDs {
-		conflict := ig.txs[conflictKey]
+		conflict, exists := ig.txs[conflictKey]
+		if !exists {
+			// This tx may have already been rejected, either because it
+			// conflicted with an accepted tx or because one of its
+			// dependencies was rejected.
+			continue
+		}
 
 		// We are rejecting the tx, so we should remove it from the graph
 		delete(ig.txs, conflictKey)
@@ -381,7 +397,7 @@ func (ig *Input) reject(conflictIDs ids.Set) error {
 		// Remove this tx from all the conflict sets it's currently in
 		ig.removeConflict(conflictKey, conflict.tx.InputIDs())
 
-		if err := ig.rejectTx(conflict.tx); err != nil {
+		if err := ig.rejectTx(conflict.tx, reason); err != nil {
 			return err
 		}
 	}
@@ -0,0 +1,24 @@
+// (c) 2019-2020, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package snowstorm
+
+import (
+	"fmt"
+
+	"github.com/ava-labs/avalanchego/ids"
+)
+
+// MissingDependenciesError may be returned, or wrapped, by Verify when a tx
+// can't be verified until other txs are accepted, such as a tx that consumes
+// state produced by txs the VM hasn't accepted yet. Rather than dropping the
+// vertex containing such a tx for good, the engine retries issuing it once
+// the txs are accepted.
+type MissingDependenciesError struct {
+	// IDs of the txs that must be accepted before the tx can be verified
+	TxIDs []ids.ID
+}
+
+func (e *MissingDependenciesError) Error() string {
+	return fmt.Sprintf("missing dependencies %v", e.TxIDs)
+}
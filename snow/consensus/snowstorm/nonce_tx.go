@@ -0,0 +1,72 @@
+// (c) 2019-2020, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package snowstorm
+
+import (
+	"github.com/ava-labs/avalanchego/ids"
+	"github.com/ava-labs/avalanchego/snow/choices"
+	"github.com/ava-labs/avalanchego/utils/hashing"
+	"github.com/ava-labs/avalanchego/utils/wrappers"
+)
+
+// AccountTx is a tx of an account-based VM, which orders the txs of each
+// account by nonce rather than by the UTXOs they spend
+type AccountTx interface {
+	choices.Decidable
+
+	// Account that issued this tx
+	Account() ids.ShortID
+
+	// Nonce of [Account] that this tx uses. Each nonce of an account may only
+	// be used by one accepted tx, and only after the previous nonce was used.
+	Nonce() uint64
+
+	// Verify that the state transition this tx would make if it were accepted
+	// is valid. It's guaranteed that the tx that uses the previous nonce of
+	// [Account] has already been verified.
+	Verify() error
+
+	// Bytes returns the binary representation of this tx
+	Bytes() []byte
+}
+
+// NonceTx adapts an AccountTx to the Tx interface, so that account-based VMs
+// can be run on the DAG engine.
+//
+// The nonce of an account is modelled as a piece of state that the tx that
+// uses it consumes. Therefore, txs that use the same nonce of the same account
+// conflict, such as a tx and its replacement, and at most one of them is
+// accepted. A tx depends on the tx that uses the previous nonce of its
+// account, so it's only accepted after that tx is, and it's rejected if that
+// tx is rejected in favor of a conflicting replacement.
+type NonceTx struct {
+	AccountTx
+
+	// Tx that uses the previous nonce of this tx's account, or nil if this
+	// tx uses the account's first nonce or the previous nonce has already
+	// been used by an accepted tx
+	Previous Tx
+}
+
+// NonceInputID returns the ID of the piece of state that the tx using [nonce]
+// of [account] consumes
+func NonceInputID(account ids.ShortID, nonce uint64) ids.ID {
+	p := wrappers.Packer{Bytes: make([]byte, len(account)+wrappers.LongLen)}
+	p.PackFixedBytes(account[:])
+	p.PackLong(nonce)
+	return ids.ID(hashing.ComputeHash256Array(p.Bytes))
+}
+
+// Dependencies implements the Tx interface
+func (tx *NonceTx) Dependencies() []Tx {
+	if tx.Previous == nil {
+		return nil
+	}
+	return []Tx{tx.Previous}
+}
+
+// InputIDs implements the Tx interface
+func (tx *NonceTx) InputIDs() []ids.ID {
+	return []ids.ID{NonceInputID(tx.Account(), tx.Nonce())}
+}
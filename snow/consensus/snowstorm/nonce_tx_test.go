@@ -0,0 +1,142 @@
+// (c) 2019-2020, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package snowstorm
+
+import (
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/ava-labs/avalanchego/ids"
+	"github.com/ava-labs/avalanchego/snow"
+	"github.com/ava-labs/avalanchego/snow/choices"
+
+	sbcon "github.com/ava-labs/avalanchego/snow/consensus/snowball"
+)
+
+type testAccountTx struct {
+	choices.TestDecidable
+
+	account ids.ShortID
+	nonce   uint64
+}
+
+func (tx *testAccountTx) Account() ids.ShortID { return tx.account }
+func (tx *testAccountTx) Nonce() uint64        { return tx.nonce }
+func (tx *testAccountTx) Verify() error        { return nil }
+func (tx *testAccountTx) Bytes() []byte        { return tx.IDV[:] }
+
+func newTestNonceTx(account ids.ShortID, nonce uint64, previous Tx) *NonceTx {
+	return &NonceTx{
+		AccountTx: &testAccountTx{
+			TestDecidable: choices.TestDecidable{
+				IDV:     ids.GenerateTestID(),
+				StatusV: choices.Processing,
+			},
+			account: account,
+			nonce:   nonce,
+		},
+		Previous: previous,
+	}
+}
+
+func newNonceTestGraph(t *testing.T, factory Factory) Consensus {
+	graph := factory.New()
+	params := sbcon.Parameters{
+		Metrics:               prometheus.NewRegistry(),
+		K:                     1,
+		Alpha:                 1,
+		BetaVirtuous:          1,
+		BetaRogue:             2,
+		ConcurrentRepolls:     1,
+		OptimalProcessing:     1,
+		MaxOutstandingItems:   1,
+		MaxItemProcessingTime: 1,
+	}
+	assert.NoError(t, graph.Initialize(snow.DefaultContextTest(), params))
+	return graph
+}
+
+func TestNonceInputID(t *testing.T) {
+	account := ids.ShortID{1}
+	assert.Equal(t, NonceInputID(account, 1), NonceInputID(account, 1))
+	assert.NotEqual(t, NonceInputID(account, 1), NonceInputID(account, 2))
+	assert.NotEqual(t, NonceInputID(account, 1), NonceInputID(ids.ShortID{2}, 1))
+}
+
+func TestNonceTxReplacementPrecludesLaterNonces(t *testing.T) {
+	for _, factory := range []Factory{DirectedFactory{}, InputFactory{}} {
+		graph := newNonceTestGraph(t, factory)
+
+		alice, bob := ids.ShortID{1}, ids.ShortID{2}
+		alice0 := newTestNonceTx(alice, 0, nil)
+		alice1 := newTestNonceTx(alice, 1, alice0)
+		// replaces [alice0], such as to pay a higher fee
+		alice0Replacement := newTestNonceTx(alice, 0, nil)
+		bob0 := newTestNonceTx(bob, 0, nil)
+
+		for _, tx := range []Tx{alice0, alice1, alice0Replacement, bob0} {
+			assert.NoError(t, graph.Add(tx))
+		}
+
+		// only txs that use the same nonce of the same account conflict
+		bobConflicts := graph.Conflicts(bob0)
+		assert.Zero(t, bobConflicts.Len())
+		replacementConflicts := graph.Conflicts(alice0Replacement)
+		assert.True(t, replacementConflicts.Contains(alice0.ID()))
+		laterConflicts := graph.Conflicts(alice1)
+		assert.False(t, laterConflicts.Contains(alice0.ID()))
+		assert.True(t, graph.IsVirtuous(bob0))
+
+		votes := ids.Bag{}
+		votes.Add(alice0Replacement.ID(), bob0.ID())
+		for i := 0; i < 2; i++ {
+			_, err := graph.RecordPoll(votes)
+			assert.NoError(t, err)
+		}
+
+		// accepting the replacement rejects the tx it replaced, and the txs
+		// that use later nonces on top of the replaced tx
+		assert.Equal(t, choices.Accepted, alice0Replacement.Status())
+		assert.Equal(t, choices.Rejected, alice0.Status())
+		assert.Equal(t, choices.Rejected, alice1.Status())
+		assert.Equal(t, choices.Accepted, bob0.Status())
+		assert.True(t, graph.Finalized())
+	}
+}
+
+func TestNonceTxAcceptedInNonceOrder(t *testing.T) {
+	for _, factory := range []Factory{DirectedFactory{}, InputFactory{}} {
+		graph := newNonceTestGraph(t, factory)
+
+		alice := ids.ShortID{1}
+		alice0 := newTestNonceTx(alice, 0, nil)
+		alice1 := newTestNonceTx(alice, 1, alice0)
+		alice0Replacement := newTestNonceTx(alice, 0, nil)
+
+		for _, tx := range []Tx{alice0, alice1, alice0Replacement} {
+			assert.NoError(t, graph.Add(tx))
+		}
+
+		// a later nonce isn't accepted before the nonce it builds on
+		votes := ids.Bag{}
+		votes.Add(alice1.ID())
+		for i := 0; i < 2; i++ {
+			_, err := graph.RecordPoll(votes)
+			assert.NoError(t, err)
+		}
+		assert.Equal(t, choices.Processing, alice1.Status())
+
+		votes = ids.Bag{}
+		votes.Add(alice0.ID(), alice1.ID())
+		for i := 0; i < 2; i++ {
+			_, err := graph.RecordPoll(votes)
+			assert.NoError(t, err)
+		}
+		assert.Equal(t, choices.Accepted, alice0.Status())
+		assert.Equal(t, choices.Accepted, alice1.Status())
+		assert.Equal(t, choices.Rejected, alice0Replacement.Status())
+	}
+}
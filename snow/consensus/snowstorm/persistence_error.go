@@ -0,0 +1,23 @@
+// (c) 2019-2020, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package snowstorm
+
+import (
+	"fmt"
+)
+
+// PersistenceError may be returned, or wrapped, by a VM when it fails to
+// persist a tx before the tx is issued into consensus, such as when it saves a
+// newly parsed tx. The failure may be transient, such as a full disk, so
+// rather than halting, the engine pauses issuance and retries the vertex
+// containing the tx with a backoff.
+type PersistenceError struct {
+	Err error
+}
+
+func (e *PersistenceError) Error() string {
+	return fmt.Sprintf("failed to persist tx: %s", e.Err)
+}
+
+func (e *PersistenceError) Unwrap() error { return e.Err }
@@ -0,0 +1,49 @@
+// (c) 2019-2020, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package snowstorm
+
+import (
+	"fmt"
+
+	"github.com/ava-labs/avalanchego/ids"
+)
+
+// RejectionCause is the reason that a tx was rejected
+type RejectionCause byte
+
+const (
+	// PrecludedByConflict means that a conflicting tx was accepted
+	PrecludedByConflict RejectionCause = iota + 1
+	// DependencyRejected means that a tx this tx depends on was rejected
+	DependencyRejected
+)
+
+func (c RejectionCause) String() string {
+	switch c {
+	case PrecludedByConflict:
+		return "PrecludedByConflict"
+	case DependencyRejected:
+		return "DependencyRejected"
+	default:
+		return "Unknown"
+	}
+}
+
+// Rejection describes why a tx was rejected
+type Rejection struct {
+	Cause RejectionCause
+	// The accepted tx that conflicted with the rejected tx, or the rejected
+	// dependency of the rejected tx, depending on [Cause]
+	TxID ids.ID
+}
+
+func (r Rejection) String() string { return fmt.Sprintf("%s by %s", r.Cause, r.TxID) }
+
+// RejectionTx is optionally implemented by txs that record why they were
+// rejected. RecordRejection is called immediately before Reject.
+type RejectionTx interface {
+	Tx
+
+	RecordRejection(Rejection) error
+}
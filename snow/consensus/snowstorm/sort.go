@@ -0,0 +1,147 @@
+// (c) 2019-2020, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package snowstorm
+
+import (
+	"bytes"
+	"container/heap"
+	"errors"
+	"fmt"
+
+	"github.com/ava-labs/avalanchego/ids"
+)
+
+// ErrCycle is returned, wrapped in a *CycleError, when txs can't be sorted
+// because their dependencies form a cycle
+var ErrCycle = errors.New("dependency cycle")
+
+// CycleError reports the txs that form a dependency cycle
+type CycleError struct {
+	// IDs of the txs in the cycle. Each tx depends on the tx after it, and
+	// the last tx depends on the first.
+	TxIDs []ids.ID
+}
+
+func (e *CycleError) Error() string {
+	return fmt.Sprintf("%s between %s", ErrCycle, e.TxIDs)
+}
+
+// Unwrap returns ErrCycle
+func (e *CycleError) Unwrap() error { return ErrCycle }
+
+// TopologicalSort returns [txs] ordered so that each tx comes after the txs in
+// [txs] that it depends on. Dependencies that aren't in [txs] are ignored.
+// When more than one tx may come next, the tx with the smallest ID does, so the
+// order doesn't depend on the order of [txs]. Duplicated txs are only returned
+// once.
+//
+// If the dependencies of [txs] form a cycle, a *CycleError is returned.
+func TopologicalSort(txs []Tx) ([]Tx, error) {
+	byID := make(map[ids.ID]Tx, len(txs))
+	for _, tx := range txs {
+		byID[tx.ID()] = tx
+	}
+
+	// tx ID --> number of its dependencies that haven't been sorted yet
+	numDeps := make(map[ids.ID]int, len(byID))
+	// tx ID --> the txs that depend on it
+	dependents := make(map[ids.ID][]Tx, len(byID))
+	ready := txHeap(nil)
+	for txID, tx := range byID {
+		depIDs := ids.Set{}
+		for _, dep := range tx.Dependencies() {
+			depID := dep.ID()
+			if _, ok := byID[depID]; !ok || depIDs.Contains(depID) {
+				continue
+			}
+			depIDs.Add(depID)
+			dependents[depID] = append(dependents[depID], tx)
+		}
+		numDeps[txID] = depIDs.Len()
+		if depIDs.Len() == 0 {
+			ready = append(ready, tx)
+		}
+	}
+	heap.Init(&ready)
+
+	sorted := make([]Tx, 0, len(byID))
+	for ready.Len() > 0 {
+		tx := heap.Pop(&ready).(Tx)
+		txID := tx.ID()
+		sorted = append(sorted, tx)
+		delete(numDeps, txID)
+
+		for _, dependent := range dependents[txID] {
+			dependentID := dependent.ID()
+			numDeps[dependentID]--
+			if numDeps[dependentID] == 0 {
+				heap.Push(&ready, dependent)
+			}
+		}
+	}
+
+	if len(numDeps) > 0 {
+		return nil, &CycleError{TxIDs: findCycle(byID, numDeps)}
+	}
+	return sorted, nil
+}
+
+// findCycle returns a cycle among the txs in [unsorted]. Every tx in
+// [unsorted] depends on another tx in [unsorted], so following those
+// dependencies must eventually revisit a tx.
+func findCycle(byID map[ids.ID]Tx, unsorted map[ids.ID]int) []ids.ID {
+	unsortedIDs := make([]ids.ID, 0, len(unsorted))
+	for txID := range unsorted {
+		unsortedIDs = append(unsortedIDs, txID)
+	}
+	ids.SortIDs(unsortedIDs)
+
+	// tx ID --> index of the tx in [path]
+	visited := make(map[ids.ID]int)
+	path := []ids.ID(nil)
+	txID := unsortedIDs[0]
+	for {
+		if index, ok := visited[txID]; ok {
+			return path[index:]
+		}
+		visited[txID] = len(path)
+		path = append(path, txID)
+
+		// Follow the smallest unsorted dependency so that the reported cycle
+		// is deterministic
+		next := ids.ID{}
+		found := false
+		for _, dep := range byID[txID].Dependencies() {
+			depID := dep.ID()
+			if _, ok := unsorted[depID]; !ok {
+				continue
+			}
+			if !found || bytes.Compare(depID[:], next[:]) == -1 {
+				next = depID
+				found = true
+			}
+		}
+		txID = next
+	}
+}
+
+// txHeap is a min-heap of txs ordered by ID
+type txHeap []Tx
+
+func (h txHeap) Len() int { return len(h) }
+func (h txHeap) Less(i, j int) bool {
+	iID := h[i].ID()
+	jID := h[j].ID()
+	return bytes.Compare(iID[:], jID[:]) == -1
+}
+func (h txHeap) Swap(i, j int)       { h[i], h[j] = h[j], h[i] }
+func (h *txHeap) Push(x interface{}) { *h = append(*h, x.(Tx)) }
+func (h *txHeap) Pop() interface{} {
+	old := *h
+	n := len(old)
+	tx := old[n-1]
+	old[n-1] = nil
+	*h = old[:n-1]
+	return tx
+}
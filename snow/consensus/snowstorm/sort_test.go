@@ -0,0 +1,60 @@
+// (c) 2019-2020, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package snowstorm
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/ava-labs/avalanchego/ids"
+	"github.com/ava-labs/avalanchego/snow/choices"
+)
+
+func newSortTestTx(id byte, deps ...Tx) *TestTx {
+	return &TestTx{
+		TestDecidable: choices.TestDecidable{IDV: ids.ID{id}},
+		DependenciesV: deps,
+	}
+}
+
+func TestTopologicalSortStableOrder(t *testing.T) {
+	tx0 := newSortTestTx(0)
+	tx1 := newSortTestTx(1)
+	tx3 := newSortTestTx(3)
+	tx2 := newSortTestTx(2, tx3, tx0)
+	// tx4 depends on a tx that isn't being sorted
+	tx4 := newSortTestTx(4, newSortTestTx(5))
+
+	expected := []Tx{tx0, tx1, tx3, tx2, tx4}
+	for _, txs := range [][]Tx{
+		{tx0, tx1, tx2, tx3, tx4},
+		{tx4, tx3, tx2, tx1, tx0},
+		{tx2, tx4, tx0, tx3, tx1, tx2},
+	} {
+		sorted, err := TopologicalSort(txs)
+		assert.NoError(t, err)
+		assert.Equal(t, expected, sorted)
+	}
+}
+
+func TestTopologicalSortCycle(t *testing.T) {
+	tx0 := newSortTestTx(0)
+	tx1 := newSortTestTx(1)
+	tx2 := newSortTestTx(2, tx1)
+	tx3 := newSortTestTx(3)
+	tx1.DependenciesV = []Tx{tx0, tx3}
+	tx3.DependenciesV = []Tx{tx2}
+	// tx4 isn't part of the cycle, but can't be sorted because it depends on
+	// the cycle
+	tx4 := newSortTestTx(4, tx2)
+
+	_, err := TopologicalSort([]Tx{tx4, tx3, tx2, tx1, tx0})
+	assert.True(t, errors.Is(err, ErrCycle))
+
+	cycleErr := &CycleError{}
+	assert.True(t, errors.As(err, &cycleErr))
+	assert.Equal(t, []ids.ID{tx1.ID(), tx3.ID(), tx2.ID()}, cycleErr.TxIDs)
+}
@@ -4,6 +4,7 @@
 package snow
 
 import (
+	"math/rand"
 	"sync"
 	"time"
 
@@ -14,7 +15,9 @@ import (
 	"github.com/ava-labs/avalanchego/chains/atomic"
 	"github.com/ava-labs/avalanchego/database"
 	"github.com/ava-labs/avalanchego/ids"
+	"github.com/ava-labs/avalanchego/utils/hashing"
 	"github.com/ava-labs/avalanchego/utils/logging"
+	"github.com/ava-labs/avalanchego/utils/sampler"
 	"github.com/ava-labs/avalanchego/utils/timer"
 )
 
@@ -23,6 +26,9 @@ type EventDispatcher interface {
 	Issue(ctx *Context, containerID ids.ID, container []byte)
 	Accept(ctx *Context, containerID ids.ID, container []byte)
 	Reject(ctx *Context, containerID ids.ID, container []byte)
+	// AdvanceFrontier is called when the accepted frontier of a chain changes.
+	// [height] is the greatest height of the containers in [frontier].
+	AdvanceFrontier(ctx *Context, height uint64, frontier []ids.ID)
 }
 
 // Keystore ...
@@ -60,11 +66,19 @@ type Context struct {
 	Lock                sync.RWMutex
 	Keystore            Keystore
 	SharedMemory        atomic.SharedMemory
+	MessageBus          atomic.MessageBus
 	BCLookup            AliasLookup
 	SNLookup            SubnetLookup
 	Namespace           string
 	Metrics             prometheus.Registerer
 
+	// Hash function this chain identifies its data with
+	Hasher hashing.Hasher
+
+	// Source of randomness for this chain's sampling. It's only safe to use
+	// while holding [Lock]. If nil, the global source is used.
+	Random *rand.Rand
+
 	// Epoch management
 	EpochFirstTransition time.Time
 	EpochDuration        time.Duration
@@ -74,6 +88,24 @@ type Context struct {
 	bootstrapped uint32
 }
 
+// RandomSource returns the source of randomness for this chain's sampling, or
+// nil if the global source should be used
+func (ctx *Context) RandomSource() sampler.Source {
+	if ctx.Random == nil {
+		return nil
+	}
+	return ctx.Random
+}
+
+// HashFunction returns the hash function this chain identifies its data with.
+// Returns hashing.Default if [ctx] or its Hasher is nil.
+func (ctx *Context) HashFunction() hashing.Hasher {
+	if ctx == nil || ctx.Hasher == nil {
+		return hashing.Default
+	}
+	return ctx.Hasher
+}
+
 // IsBootstrapped returns true iff this chain is done bootstrapping
 func (ctx *Context) IsBootstrapped() bool {
 	return stdatomic.LoadUint32(&ctx.bootstrapped) > 0
@@ -84,6 +116,12 @@ func (ctx *Context) Bootstrapped() {
 	stdatomic.StoreUint32(&ctx.bootstrapped, 1)
 }
 
+// Unbootstrapped marks this chain as bootstrapping again, such as after its
+// engine was restarted
+func (ctx *Context) Unbootstrapped() {
+	stdatomic.StoreUint32(&ctx.bootstrapped, 0)
+}
+
 // Epoch this context thinks it's in based on the wall clock time.
 func (ctx *Context) Epoch() uint32 {
 	now := ctx.Clock.Time()
@@ -111,11 +149,14 @@ func DefaultContextTest() *Context {
 		BCLookup:            aliaser,
 		Namespace:           "",
 		Metrics:             prometheus.NewRegistry(),
+		Hasher:              hashing.Default,
+		Random:              NewRandom(ids.Empty, nil),
 	}
 }
 
 type emptyEventDispatcher struct{}
 
-func (emptyEventDispatcher) Issue(*Context, ids.ID, []byte)  {}
-func (emptyEventDispatcher) Accept(*Context, ids.ID, []byte) {}
-func (emptyEventDispatcher) Reject(*Context, ids.ID, []byte) {}
+func (emptyEventDispatcher) Issue(*Context, ids.ID, []byte)             {}
+func (emptyEventDispatcher) Accept(*Context, ids.ID, []byte)            {}
+func (emptyEventDispatcher) Reject(*Context, ids.ID, []byte)            {}
+func (emptyEventDispatcher) AdvanceFrontier(*Context, uint64, []ids.ID) {}
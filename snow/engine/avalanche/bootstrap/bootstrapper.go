@@ -45,6 +45,8 @@ type Config struct {
 
 	Manager vertex.Manager
 	VM      vertex.DAGVM
+
+	Bootstrapped func()
 }
 
 // Bootstrapper ...
@@ -60,6 +62,8 @@ type Bootstrapper struct {
 	Manager vertex.Manager
 	VM      vertex.DAGVM
 
+	Bootstrapped func()
+
 	// IDs of vertices that we will send a GetAncestors request for once we are
 	// not at the max number of outstanding requests
 	needToFetch ids.Set
@@ -83,6 +87,7 @@ func (b *Bootstrapper) Initialize(
 	b.TxBlocked = config.TxBlocked
 	b.Manager = config.Manager
 	b.VM = config.VM
+	b.Bootstrapped = config.Bootstrapped
 	b.processedCache = &cache.LRU{Size: cacheSize}
 	b.OnFinished = onFinished
 	b.executedStateTransitions = math.MaxInt32
@@ -186,34 +191,42 @@ func (b *Bootstrapper) process(vtxs ...avalanche.Vertex) error {
 		case choices.Processing:
 			b.needToFetch.Remove(vtxID)
 
-			if err := b.VtxBlocked.Push(&vertexJob{ // Add to queue of vertices to execute when bootstrapping finishes.
+			pushed, err := b.VtxBlocked.Push(&vertexJob{ // Add to queue of vertices to execute when bootstrapping finishes.
 				log:         b.Ctx.Log,
 				numAccepted: b.numAcceptedVts,
 				numDropped:  b.numDroppedVts,
 				vtx:         vtx,
-			}); err == nil {
+			})
+			if err != nil {
+				return err
+			}
+			if pushed {
 				b.numFetchedVts.Inc()
 				b.NumFetched++ // Progress tracker
 				if b.NumFetched%common.StatusUpdateFrequency == 0 {
 					b.Ctx.Log.Info("fetched %d vertices", b.NumFetched)
 				}
 			} else {
-				b.Ctx.Log.Verbo("couldn't push to vtxBlocked: %s", err)
+				b.Ctx.Log.Verbo("vertex %s was already pushed to vtxBlocked", vtxID)
 			}
 			txs, err := vtx.Txs()
 			if err != nil {
 				return err
 			}
 			for _, tx := range txs { // Add transactions to queue of transactions to execute when bootstrapping finishes.
-				if err := b.TxBlocked.Push(&txJob{
+				pushed, err := b.TxBlocked.Push(&txJob{
 					log:         b.Ctx.Log,
 					numAccepted: b.numAcceptedTxs,
 					numDropped:  b.numDroppedTxs,
 					tx:          tx,
-				}); err == nil {
+				})
+				if err != nil {
+					return err
+				}
+				if pushed {
 					b.numFetchedTxs.Inc()
 				} else {
-					b.Ctx.Log.Verbo("couldn't push to txBlocked: %s", err)
+					b.Ctx.Log.Verbo("tx %s was already pushed to txBlocked", tx.ID())
 				}
 			}
 			parents, err := vtx.Parents()
@@ -403,6 +416,12 @@ func (b *Bootstrapper) checkFinish() error {
 	// Notify the subnet that this chain is synced
 	b.Subnet.Bootstrapped(b.Ctx.ChainID)
 
+	// If there is an additional callback, notify them that this chain has been
+	// synced.
+	if b.Bootstrapped != nil {
+		b.Bootstrapped()
+	}
+
 	// If the subnet hasn't finished bootstrapping, this chain should remain
 	// syncing.
 	if !b.Subnet.IsBootstrapped() {
@@ -4,6 +4,7 @@
 package avalanche
 
 import (
+	"github.com/ava-labs/avalanchego/database"
 	"github.com/ava-labs/avalanchego/snow/consensus/avalanche"
 	"github.com/ava-labs/avalanchego/snow/engine/avalanche/bootstrap"
 )
@@ -14,4 +15,8 @@ type Config struct {
 
 	Params    avalanche.Parameters
 	Consensus avalanche.Consensus
+
+	// Holds the processing vertices across restarts, so that they don't need
+	// to be fetched again. If nil, they aren't persisted.
+	ProcessingDB database.Database
 }
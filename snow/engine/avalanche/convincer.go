@@ -6,32 +6,36 @@ package avalanche
 import (
 	"github.com/ava-labs/avalanchego/ids"
 	"github.com/ava-labs/avalanchego/snow/consensus/avalanche"
+	"github.com/ava-labs/avalanchego/snow/engine/avalanche/vertex"
 	"github.com/ava-labs/avalanchego/snow/engine/common"
+	"github.com/ava-labs/avalanchego/snow/events"
 	"github.com/ava-labs/avalanchego/utils/wrappers"
 )
 
 // convincer sends chits to [vdr] after its dependencies are met.
 type convincer struct {
 	consensus avalanche.Consensus
+	manager   vertex.Manager
 	sender    common.Sender
 	vdr       ids.ShortID
 	requestID uint32
+	justify   bool
 	sent      bool
 	abandoned bool
-	deps      ids.Set
+	deps      events.KeySet
 	errs      *wrappers.Errs
 }
 
-func (c *convincer) Dependencies() ids.Set { return c.deps }
+func (c *convincer) Dependencies() events.KeySet { return c.deps }
 
 // Mark that a dependency has been met.
-func (c *convincer) Fulfill(id ids.ID) {
-	c.deps.Remove(id)
+func (c *convincer) Fulfill(key events.Key) {
+	c.deps.Remove(key)
 	c.Update()
 }
 
 // Abandon this attempt to send chits.
-func (c *convincer) Abandon(ids.ID) {
+func (c *convincer) Abandon(events.Key) {
 	c.abandoned = true
 	c.Update()
 }
@@ -42,5 +46,43 @@ func (c *convincer) Update() {
 	}
 	c.sent = true
 
-	c.sender.Chits(c.vdr, c.requestID, c.consensus.Preferences().List())
+	prefs := c.preferences()
+	if !c.justify {
+		c.sender.Chits(c.vdr, c.requestID, prefs)
+		return
+	}
+	heights, ok := c.heights(prefs)
+	if !ok {
+		c.sender.Chits(c.vdr, c.requestID, prefs)
+		return
+	}
+	c.sender.JustifiedChits(c.vdr, c.requestID, prefs, heights)
+}
+
+// heights returns the heights of the vertices in [prefs]. Returns false if the
+// height of any of the vertices couldn't be loaded.
+func (c *convincer) heights(prefs []ids.ID) ([]uint64, bool) {
+	heights := make([]uint64, len(prefs))
+	for i, vtxID := range prefs {
+		vtx, err := c.manager.Get(vtxID)
+		if err != nil {
+			return nil, false
+		}
+		height, err := vtx.Height()
+		if err != nil {
+			return nil, false
+		}
+		heights[i] = height
+	}
+	return heights, true
+}
+
+// preferences returns the vertices to vote for. If consensus doesn't have any
+// preferences, for example because the frontier couldn't be loaded after a
+// restart, the persisted accepted frontier is used instead.
+func (c *convincer) preferences() []ids.ID {
+	if prefs := c.consensus.Preferences(); prefs.Len() > 0 {
+		return prefs.List()
+	}
+	return c.manager.Edge()
 }
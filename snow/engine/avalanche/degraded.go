@@ -0,0 +1,84 @@
+// (c) 2019-2020, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package avalanche
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/ava-labs/avalanchego/utils/timer"
+)
+
+const (
+	// Delay before issuance is retried after the first failure to build a
+	// vertex. The delay doubles after each consecutive failure, up to
+	// [maxDegradedBackoff].
+	minDegradedBackoff = time.Second
+	maxDegradedBackoff = time.Minute
+)
+
+// degradedMode tracks consecutive failures to build vertices, such as failures
+// to persist them, and failures of the VM to persist the txs of vertices being
+// issued. While degraded, the engine still participates in consensus, but
+// doesn't issue txs or vertices until the backoff since the last failure has
+// elapsed, so that transient failures don't halt the chain.
+//
+// Only failures before a vertex is added to consensus are tolerated, as its
+// txs can then be safely retried. Txs that fail verification are already
+// dropped without halting the engine. VM errors while a vertex is added to
+// consensus, or while its txs are accepted or rejected, still halt the engine,
+// as consensus may have been partially updated and can't be safely retried.
+type degradedMode struct {
+	clock timer.Clock
+
+	// Number of consecutive failures. 0 means the engine isn't degraded.
+	failures int
+	lastErr  error
+	// Time before which issuance shouldn't be retried
+	retryTime time.Time
+}
+
+// Degraded returns true if the last attempt to build a vertex failed
+func (d *degradedMode) Degraded() bool { return d.failures > 0 }
+
+// CanIssue returns true if txs may be issued
+func (d *degradedMode) CanIssue() bool {
+	return d.failures == 0 || !d.clock.Time().Before(d.retryTime)
+}
+
+// Fail records a failure to build a vertex and returns how long issuance is
+// paused for
+func (d *degradedMode) Fail(err error) time.Duration {
+	backoff := maxDegradedBackoff
+	if d.failures < 32 {
+		backoff = minDegradedBackoff << uint(d.failures)
+	}
+	if backoff > maxDegradedBackoff {
+		backoff = maxDegradedBackoff
+	}
+
+	d.failures++
+	d.lastErr = err
+	d.retryTime = d.clock.Time().Add(backoff)
+	return backoff
+}
+
+// Recover records that a vertex was built
+func (d *degradedMode) Recover() {
+	d.failures = 0
+	d.lastErr = nil
+	d.retryTime = time.Time{}
+}
+
+// HealthCheck returns an error if the engine is degraded
+func (d *degradedMode) HealthCheck() (interface{}, error) {
+	if d.failures == 0 {
+		return map[string]interface{}{"degraded": false}, nil
+	}
+	return map[string]interface{}{
+		"degraded":  true,
+		"failures":  d.failures,
+		"retryTime": d.retryTime,
+	}, fmt.Errorf("issuance is paused after %d consecutive failures to build a vertex: %s", d.failures, d.lastErr)
+}
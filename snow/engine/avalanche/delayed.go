@@ -0,0 +1,94 @@
+// (c) 2019-2020, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package avalanche
+
+import (
+	"container/heap"
+	"time"
+
+	"github.com/ava-labs/avalanchego/ids"
+	"github.com/ava-labs/avalanchego/snow/consensus/snowstorm"
+	"github.com/ava-labs/avalanchego/utils/timer"
+)
+
+// delayedTx is a tx that may not be issued before [notBefore]
+type delayedTx struct {
+	tx        snowstorm.Tx
+	notBefore time.Time
+}
+
+// delayedTxHeap orders delayed txs by the time they may be issued, earliest
+// first
+type delayedTxHeap []delayedTx
+
+func (h delayedTxHeap) Len() int            { return len(h) }
+func (h delayedTxHeap) Less(i, j int) bool  { return h[i].notBefore.Before(h[j].notBefore) }
+func (h delayedTxHeap) Swap(i, j int)       { h[i], h[j] = h[j], h[i] }
+func (h *delayedTxHeap) Push(x interface{}) { *h = append(*h, x.(delayedTx)) }
+func (h *delayedTxHeap) Pop() interface{} {
+	old := *h
+	n := len(old) - 1
+	tx := old[n]
+	old[n] = delayedTx{}
+	*h = old[:n]
+	return tx
+}
+
+// delayedQueue holds the txs that the VM asked not to be issued yet, as
+// reported by snowstorm.DelayedTx, until they may be issued
+type delayedQueue struct {
+	clock timer.Clock
+
+	txs   delayedTxHeap
+	txIDs ids.Set
+}
+
+// Split returns the txs in [txs] that may be issued now. The others are held
+// until they may be issued. [numDropped] is the number of txs that weren't
+// held because [maxDelayedTxs] txs are already held.
+func (q *delayedQueue) Split(txs []snowstorm.Tx) (ready []snowstorm.Tx, numDropped int) {
+	now := q.clock.Time()
+	ready = make([]snowstorm.Tx, 0, len(txs))
+	for _, tx := range txs {
+		delayed, ok := tx.(snowstorm.DelayedTx)
+		if !ok || !now.Before(delayed.NotBefore()) {
+			ready = append(ready, tx)
+			continue
+		}
+		txID := tx.ID()
+		if q.txIDs.Contains(txID) {
+			continue
+		}
+		if q.txIDs.Len() >= maxDelayedTxs {
+			numDropped++
+			continue
+		}
+		q.txIDs.Add(txID)
+		heap.Push(&q.txs, delayedTx{
+			tx:        tx,
+			notBefore: delayed.NotBefore(),
+		})
+	}
+	return ready, numDropped
+}
+
+// HasReady returns true if a held tx may be issued now
+func (q *delayedQueue) HasReady() bool {
+	return len(q.txs) > 0 && !q.clock.Time().Before(q.txs[0].notBefore)
+}
+
+// Ready removes, and returns, the held txs that may be issued now, in the
+// order they became issuable
+func (q *delayedQueue) Ready() []snowstorm.Tx {
+	var ready []snowstorm.Tx
+	for q.HasReady() {
+		tx := heap.Pop(&q.txs).(delayedTx).tx
+		q.txIDs.Remove(tx.ID())
+		ready = append(ready, tx)
+	}
+	return ready
+}
+
+// Len returns the number of held txs
+func (q *delayedQueue) Len() int { return len(q.txs) }
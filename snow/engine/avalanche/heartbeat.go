@@ -0,0 +1,40 @@
+// (c) 2019-2020, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package avalanche
+
+import (
+	"time"
+
+	"github.com/ava-labs/avalanchego/utils/timer"
+)
+
+// heartbeat tracks when a poll last finished, so that the preferences can be
+// re-polled if consensus stalls without any poll outstanding. Otherwise, a
+// chain whose polls were all dropped would only make progress once new txs
+// are issued.
+type heartbeat struct {
+	clock timer.Clock
+
+	// Amount of time without a poll finishing after which a heartbeat is due.
+	// 0 means heartbeats are disabled.
+	frequency time.Duration
+	// Time a poll last finished, or a heartbeat was last issued
+	lastPoll time.Time
+}
+
+// Initialize sets the heartbeat frequency. The first heartbeat is due
+// [frequency] from now.
+func (h *heartbeat) Initialize(frequency time.Duration) {
+	h.frequency = frequency
+	h.lastPoll = h.clock.Time()
+}
+
+// Polled records that a poll finished, or that a heartbeat was issued
+func (h *heartbeat) Polled() { h.lastPoll = h.clock.Time() }
+
+// Due returns true if heartbeats are enabled and no poll finished within the
+// heartbeat frequency
+func (h *heartbeat) Due() bool {
+	return h.frequency > 0 && h.clock.Time().Sub(h.lastPoll) >= h.frequency
+}
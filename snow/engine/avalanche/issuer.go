@@ -4,9 +4,12 @@
 package avalanche
 
 import (
+	"errors"
+
 	"github.com/ava-labs/avalanchego/ids"
 	"github.com/ava-labs/avalanchego/snow/consensus/avalanche"
 	"github.com/ava-labs/avalanchego/snow/consensus/snowstorm"
+	"github.com/ava-labs/avalanchego/snow/events"
 )
 
 // issuer issues [vtx] into consensus after its dependencies are met.
@@ -14,34 +17,32 @@ type issuer struct {
 	t                 *Transitive
 	vtx               avalanche.Vertex
 	issued, abandoned bool
-	vtxDeps, txDeps   ids.Set
+	// Vertices and transactions that must be issued before [vtx]
+	deps events.KeySet
 }
 
-// Register that a vertex we were waiting on has been issued to consensus.
-func (i *issuer) FulfillVtx(id ids.ID) {
-	i.vtxDeps.Remove(id)
-	i.Update()
-}
+func (i *issuer) Dependencies() events.KeySet { return i.deps }
 
-// Register that a transaction we were waiting on has been issued to consensus.
-func (i *issuer) FulfillTx(id ids.ID) {
-	i.txDeps.Remove(id)
+// Register that a vertex or transaction we were waiting on has been issued to
+// consensus.
+func (i *issuer) Fulfill(key events.Key) {
+	i.deps.Remove(key)
 	i.Update()
 }
 
 // Abandon this attempt to issue
-func (i *issuer) Abandon() {
+func (i *issuer) Abandon(events.Key) {
 	if !i.abandoned {
 		vtxID := i.vtx.ID()
 		i.t.pending.Remove(vtxID)
 		i.abandoned = true
-		i.t.vtxBlocked.Abandon(vtxID) // Inform vertices waiting on this vtx that it won't be issued
+		i.t.blocked.Abandon(events.VertexKey(vtxID)) // Inform vertices waiting on this vtx that it won't be issued
 	}
 }
 
 // Issue the poll when all dependencies are met
 func (i *issuer) Update() {
-	if i.abandoned || i.issued || i.vtxDeps.Len() != 0 || i.txDeps.Len() != 0 || i.t.Consensus.VertexIssued(i.vtx) || i.t.errs.Errored() {
+	if i.abandoned || i.issued || i.deps.Len() != 0 || i.t.Consensus.VertexIssued(i.vtx) || i.t.errs.Errored() {
 		return
 	}
 	// All dependencies have been met
@@ -53,13 +54,23 @@ func (i *issuer) Update() {
 	// Make sure the transactions in this vertex are valid
 	txs, err := i.vtx.Txs()
 	if err != nil {
-		i.t.errs.Add(err)
+		i.t.errs.Add(i.t.holdVtx(i.vtx, err))
 		return
 	}
 	validTxs := make([]snowstorm.Tx, 0, len(txs))
+	// IDs of the txs that must be accepted before the invalid txs may be valid.
+	// Only set if every invalid tx failed due to missing dependencies.
+	missing := []ids.ID(nil)
+	transient := true
 	for _, tx := range txs {
 		if err := tx.Verify(); err != nil {
 			i.t.Ctx.Log.Debug("Transaction %s failed verification due to %s", tx.ID(), err)
+			var missingErr *snowstorm.MissingDependenciesError
+			if errors.As(err, &missingErr) {
+				missing = append(missing, missingErr.TxIDs...)
+			} else {
+				transient = false
+			}
 		} else {
 			validTxs = append(validTxs, tx)
 		}
@@ -69,10 +80,13 @@ func (i *issuer) Update() {
 	// Take the valid transactions and issue a new vertex with them.
 	if len(validTxs) != len(txs) {
 		i.t.Ctx.Log.Debug("Abandoning %s due to failed transaction verification", vtxID)
+		if transient && len(missing) > 0 {
+			i.t.deferUnverified(vtxID, missing)
+		}
 		if _, err := i.t.batch(validTxs, false /*=force*/, false /*=empty*/, false /*=limit*/); err != nil {
 			i.t.errs.Add(err)
 		}
-		i.t.vtxBlocked.Abandon(vtxID)
+		i.t.blocked.Abandon(events.VertexKey(vtxID))
 		return
 	}
 
@@ -83,10 +97,12 @@ func (i *issuer) Update() {
 		i.t.errs.Add(err)
 		return
 	}
+	i.t.processing.Add(vtxID)
+	i.t.repolls.Issued(txs)
 
 	// Issue a poll for this vertex.
 	p := i.t.Consensus.Parameters()
-	vdrs, err := i.t.Validators.Sample(p.K) // Validators to sample
+	vdrs, err := i.t.Validators.SampleWithSource(p.K, i.t.Ctx.RandomSource()) // Validators to sample
 
 	vdrBag := ids.ShortBag{} // Validators to sample repr. as a set
 	for _, vdr := range vdrs {
@@ -104,25 +120,55 @@ func (i *issuer) Update() {
 	}
 
 	// Notify vertices waiting on this one that it (and its transactions) have been issued.
-	i.t.vtxBlocked.Fulfill(vtxID)
+	i.t.blocked.Fulfill(events.VertexKey(vtxID))
 	for _, tx := range txs {
-		i.t.txBlocked.Fulfill(tx.ID())
+		i.t.blocked.Fulfill(events.TxKey(tx.ID()))
+	}
+
+	// Issue the txs that were deferred until the txs in this vertex were issued
+	if err := i.t.issueReadyTxs(); err != nil {
+		i.t.errs.Add(err)
+		return
 	}
 
 	// Issue a repoll
 	i.t.repoll()
 }
 
-type vtxIssuer struct{ i *issuer }
+// txDeferrer holds [tx] back from being built into a vertex until its
+// dependencies are issued to consensus.
+type txDeferrer struct {
+	t                *Transitive
+	tx               snowstorm.Tx
+	ready, abandoned bool
+	// Transactions that must be issued before [tx]
+	deps events.KeySet
+}
 
-func (vi *vtxIssuer) Dependencies() ids.Set { return vi.i.vtxDeps }
-func (vi *vtxIssuer) Fulfill(id ids.ID)     { vi.i.FulfillVtx(id) }
-func (vi *vtxIssuer) Abandon(ids.ID)        { vi.i.Abandon() }
-func (vi *vtxIssuer) Update()               { vi.i.Update() }
+func (d *txDeferrer) Dependencies() events.KeySet { return d.deps }
 
-type txIssuer struct{ i *issuer }
+// Register that a transaction we were waiting on has been issued to consensus.
+func (d *txDeferrer) Fulfill(key events.Key) {
+	d.deps.Remove(key)
+	d.Update()
+}
 
-func (ti *txIssuer) Dependencies() ids.Set { return ti.i.txDeps }
-func (ti *txIssuer) Fulfill(id ids.ID)     { ti.i.FulfillTx(id) }
-func (ti *txIssuer) Abandon(ids.ID)        { ti.i.Abandon() }
-func (ti *txIssuer) Update()               { ti.i.Update() }
+// Abandon the deferred tx, as one of its dependencies won't be issued
+func (d *txDeferrer) Abandon(events.Key) {
+	if !d.abandoned && !d.ready {
+		txID := d.tx.ID()
+		d.t.deferredTxs.Remove(txID)
+		d.abandoned = true
+		d.t.Ctx.Log.Debug("dropping deferred transaction %s as one of its dependencies won't be issued", txID)
+	}
+}
+
+// Mark the tx as ready to be issued when all dependencies are met
+func (d *txDeferrer) Update() {
+	if d.abandoned || d.ready || d.deps.Len() != 0 {
+		return
+	}
+	d.ready = true
+	d.t.deferredTxs.Remove(d.tx.ID())
+	d.t.readyTxs = append(d.t.readyTxs, d.tx)
+}
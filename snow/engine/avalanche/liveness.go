@@ -0,0 +1,62 @@
+// (c) 2019-2020, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package avalanche
+
+import (
+	"time"
+
+	"github.com/ava-labs/avalanchego/utils/math"
+	"github.com/ava-labs/avalanchego/utils/timer"
+)
+
+const (
+	// Halflife of the moving average of the fraction of queries that were
+	// answered
+	queryAnsweredHalflife = time.Minute
+)
+
+// liveness tracks whether consensus is making progress: when the accepted
+// frontier last advanced and how many of the recent queries were answered
+type liveness struct {
+	clock timer.Clock
+
+	// Time the accepted frontier last advanced. Zero if it never has.
+	lastAccepted time.Time
+	// Moving average of the fraction of queries that were answered, rather
+	// than having failed. Nil until the first query finishes.
+	answered math.Averager
+}
+
+// Accepted records that the accepted frontier advanced
+func (l *liveness) Accepted() { l.lastAccepted = l.clock.Time() }
+
+// QueryFinished records that a query was answered, or failed if [answered] is
+// false
+func (l *liveness) QueryFinished(answered bool) {
+	value := 0.
+	if answered {
+		value = 1
+	}
+	now := l.clock.Time()
+	if l.answered == nil {
+		l.answered = math.NewAverager(value, queryAnsweredHalflife, now)
+		return
+	}
+	l.answered.Observe(value, now)
+}
+
+// HealthCheck reports the liveness of consensus along with the given
+// measurements of the engine's state
+func (l *liveness) HealthCheck(details map[string]interface{}) interface{} {
+	answered := 1.
+	if l.answered != nil {
+		answered = l.answered.Read()
+	}
+	details["queriesAnsweredPercentage"] = 100 * answered
+	if !l.lastAccepted.IsZero() {
+		details["lastAccepted"] = l.lastAccepted
+		details["timeSinceLastAccepted"] = l.clock.Time().Sub(l.lastAccepted).String()
+	}
+	return details
+}
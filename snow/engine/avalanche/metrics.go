@@ -10,8 +10,31 @@ import (
 )
 
 type metrics struct {
-	numVtxRequests, numPendingVts, numMissingTxs prometheus.Gauge
-	getAncestorsVtxs                             prometheus.Histogram
+	numVtxRequests, numQueuedVtxRequests prometheus.Gauge
+	numAncestorsRequests                 prometheus.Gauge
+	numDroppedVtxRequests                prometheus.Counter
+	numPeerLimitedVtxRequests            prometheus.Counter
+	numPendingVts, numMissingTxs         prometheus.Gauge
+	getAncestorsVtxs                     prometheus.Histogram
+	unjustifiedChits                     prometheus.Counter
+	numVtxBuildFailures                  prometheus.Counter
+	numDroppedRetryTxs                   prometheus.Counter
+	degradedGauge                        prometheus.Gauge
+	numDelayedTxs                        prometheus.Gauge
+	numDroppedDelayedTxs                 prometheus.Counter
+	acceptedCacheHits                    prometheus.Counter
+	concurrentRepolls                    prometheus.Gauge
+	heartbeatPolls                       prometheus.Counter
+	numUnverifiedVtxs                    prometheus.Gauge
+	retriedUnverifiedVtxs                prometheus.Counter
+	numDroppedUnverifiedVtxs             prometheus.Counter
+	numUnparseableVtxs                   prometheus.Counter
+	numWrongRequestPuts                  prometheus.Counter
+	numMisbehavingBenched                prometheus.Counter
+	numStaleVtxs                         prometheus.Counter
+	numStaleParentsSkipped               prometheus.Counter
+	numTxPersistFailures                 prometheus.Counter
+	numDroppedRetryVtxs                  prometheus.Counter
 }
 
 // Initialize implements the Engine interface
@@ -21,6 +44,26 @@ func (m *metrics) Initialize(namespace string, registerer prometheus.Registerer)
 		Name:      "vtx_requests",
 		Help:      "Number of outstanding vertex requests",
 	})
+	m.numAncestorsRequests = prometheus.NewGauge(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "ancestors_requests",
+		Help:      "Number of outstanding requests for the ancestry of a vertex with several missing parents",
+	})
+	m.numQueuedVtxRequests = prometheus.NewGauge(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "vtx_requests_queued",
+		Help:      "Number of vertex requests waiting for an outstanding vertex request to finish",
+	})
+	m.numDroppedVtxRequests = prometheus.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "vtx_requests_dropped",
+		Help:      "Number of queued vertex requests dropped because too many requests were queued",
+	})
+	m.numPeerLimitedVtxRequests = prometheus.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "vtx_requests_peer_limited",
+		Help:      "Number of vertex requests queued because too many requests to the validator were outstanding",
+	})
 	m.numPendingVts = prometheus.NewGauge(prometheus.GaugeOpts{
 		Namespace: namespace,
 		Name:      "pending_vts",
@@ -48,12 +91,132 @@ func (m *metrics) Initialize(namespace string, registerer prometheus.Registerer)
 		},
 	})
 
+	m.unjustifiedChits = prometheus.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "unjustified_chits",
+		Help:      "Number of chits dropped because their justification contradicted the local DAG",
+	})
+
+	m.numVtxBuildFailures = prometheus.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "vtx_build_failures",
+		Help:      "Number of failures to build a new vertex",
+	})
+	m.numDroppedRetryTxs = prometheus.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "retry_txs_dropped",
+		Help:      "Number of txs dropped because too many txs were held while issuance was paused",
+	})
+	m.degradedGauge = prometheus.NewGauge(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "degraded",
+		Help:      "1 if issuance is paused due to failures to build a new vertex, 0 otherwise",
+	})
+	m.numDelayedTxs = prometheus.NewGauge(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "delayed_txs",
+		Help:      "Number of txs held until the time the VM allows them to be issued",
+	})
+	m.numDroppedDelayedTxs = prometheus.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "delayed_txs_dropped",
+		Help:      "Number of txs dropped because too many txs were held until the time the VM allows them to be issued",
+	})
+	m.acceptedCacheHits = prometheus.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "accepted_cache_hits",
+		Help:      "Number of vertex requests served from the cache of recently accepted vertices",
+	})
+	m.concurrentRepolls = prometheus.NewGauge(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "concurrent_repolls",
+		Help:      "Number of polls that are kept outstanding at once to finalize consensus",
+	})
+	m.heartbeatPolls = prometheus.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "heartbeat_polls",
+		Help:      "Number of times preferences were re-polled because no poll finished within the heartbeat frequency",
+	})
+	m.numUnverifiedVtxs = prometheus.NewGauge(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "unverified_vtxs",
+		Help:      "Number of vertices waiting for the missing dependencies of their txs to be accepted",
+	})
+	m.retriedUnverifiedVtxs = prometheus.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "unverified_vtxs_retried",
+		Help:      "Number of vertices issued again after the missing dependencies of their txs were accepted",
+	})
+	m.numDroppedUnverifiedVtxs = prometheus.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "unverified_vtxs_dropped",
+		Help:      "Number of vertices dropped while waiting for missing dependencies, because too many vertices were waiting or a dependency was rejected",
+	})
+	m.numUnparseableVtxs = prometheus.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "misbehavior_unparseable_vtxs",
+		Help:      "Number of vertices received from validators that couldn't be parsed",
+	})
+	m.numWrongRequestPuts = prometheus.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "misbehavior_wrong_request_puts",
+		Help:      "Number of Puts whose vertex wasn't the one requested with their request ID",
+	})
+	m.numMisbehavingBenched = prometheus.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "misbehavior_benched",
+		Help:      "Number of times a validator was benched because it repeatedly misbehaved",
+	})
+	m.numStaleVtxs = prometheus.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "stale_vtxs",
+		Help:      "Number of vertices issued whose parents were far behind the accepted frontier",
+	})
+	m.numStaleParentsSkipped = prometheus.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "stale_parents_skipped",
+		Help:      "Number of times a stale vertex wasn't chosen as a parent of a new vertex",
+	})
+	m.numTxPersistFailures = prometheus.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "tx_persist_failures",
+		Help:      "Number of times the VM failed to persist the txs of a vertex being issued",
+	})
+	m.numDroppedRetryVtxs = prometheus.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "retry_vtxs_dropped",
+		Help:      "Number of vertices dropped because too many vertices were held while issuance was paused",
+	})
+
 	errs := wrappers.Errs{}
 	errs.Add(
 		registerer.Register(m.numVtxRequests),
+		registerer.Register(m.numQueuedVtxRequests),
+		registerer.Register(m.numAncestorsRequests),
+		registerer.Register(m.numDroppedVtxRequests),
+		registerer.Register(m.numPeerLimitedVtxRequests),
 		registerer.Register(m.numPendingVts),
 		registerer.Register(m.numMissingTxs),
 		registerer.Register(m.getAncestorsVtxs),
+		registerer.Register(m.unjustifiedChits),
+		registerer.Register(m.numVtxBuildFailures),
+		registerer.Register(m.numDroppedRetryTxs),
+		registerer.Register(m.degradedGauge),
+		registerer.Register(m.numDelayedTxs),
+		registerer.Register(m.numDroppedDelayedTxs),
+		registerer.Register(m.acceptedCacheHits),
+		registerer.Register(m.concurrentRepolls),
+		registerer.Register(m.heartbeatPolls),
+		registerer.Register(m.numUnverifiedVtxs),
+		registerer.Register(m.retriedUnverifiedVtxs),
+		registerer.Register(m.numDroppedUnverifiedVtxs),
+		registerer.Register(m.numUnparseableVtxs),
+		registerer.Register(m.numWrongRequestPuts),
+		registerer.Register(m.numMisbehavingBenched),
+		registerer.Register(m.numStaleVtxs),
+		registerer.Register(m.numStaleParentsSkipped),
+		registerer.Register(m.numTxPersistFailures),
+		registerer.Register(m.numDroppedRetryVtxs),
 	)
 	return errs.Err
 }
@@ -0,0 +1,112 @@
+// (c) 2019-2020, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package avalanche
+
+import (
+	"time"
+
+	"github.com/ava-labs/avalanchego/ids"
+	"github.com/ava-labs/avalanchego/utils/timer"
+)
+
+const (
+	// Number of offenses a validator may commit within [misbehaviorWindow]
+	// before it's benched
+	misbehaviorThreshold = 8
+	// Amount of time after the first of a validator's recent offenses after
+	// which its offenses are forgotten
+	misbehaviorWindow = time.Minute
+	// A misbehaving validator is benched for between [misbehaviorBenchDuration]/2
+	// and [misbehaviorBenchDuration]
+	misbehaviorBenchDuration = 10 * time.Minute
+)
+
+// offense is a kind of message that a correct validator never sends
+type offense int
+
+const (
+	// A vertex that can't be parsed
+	unparseableVtx offense = iota
+	// A Put whose vertex isn't the one requested with its request ID
+	wrongRequestPut
+	// Chits whose justification contradicts the local DAG
+	conflictingChits
+)
+
+func (o offense) String() string {
+	switch o {
+	case unparseableVtx:
+		return "unparseable vertex"
+	case wrongRequestPut:
+		return "Put of an unrequested vertex"
+	case conflictingChits:
+		return "conflicting chits"
+	default:
+		return "unknown offense"
+	}
+}
+
+// offenseStreak is the recent misbehavior of a validator
+type offenseStreak struct {
+	// Time of the first offense in the streak
+	first time.Time
+	// Number of offenses in the streak
+	offenses int
+}
+
+// misbehavior scores validators by the offenses they committed recently, so
+// that validators that repeatedly misbehave can be benched. A single offense
+// isn't punished, as it may be caused by a bug or an incompatible version
+// rather than by malice.
+type misbehavior struct {
+	clock timer.Clock
+
+	// Validator ID --> offenses committed within [misbehaviorWindow] of the
+	// first of them
+	streaks map[ids.ShortID]offenseStreak
+}
+
+// Offend records an offense by [vdr] and returns true if [vdr] should be
+// benched. Once [vdr] should be benched, its offenses are forgotten.
+func (m *misbehavior) Offend(vdr ids.ShortID) bool {
+	if m.streaks == nil {
+		m.streaks = make(map[ids.ShortID]offenseStreak)
+	}
+
+	now := m.clock.Time()
+	streak, ok := m.streaks[vdr]
+	if !ok || now.Sub(streak.first) > misbehaviorWindow {
+		// Offenses older than the window are forgotten, and expired streaks of
+		// other validators are pruned so that the map doesn't grow
+		m.prune(now)
+		streak = offenseStreak{first: now}
+	}
+	streak.offenses++
+
+	if streak.offenses >= misbehaviorThreshold {
+		delete(m.streaks, vdr)
+		return true
+	}
+	m.streaks[vdr] = streak
+	return false
+}
+
+// Offenses returns the number of recent offenses by [vdr]
+func (m *misbehavior) Offenses(vdr ids.ShortID) int {
+	streak, ok := m.streaks[vdr]
+	if !ok || m.clock.Time().Sub(streak.first) > misbehaviorWindow {
+		return 0
+	}
+	return streak.offenses
+}
+
+// prune removes the streaks that started more than [misbehaviorWindow] before
+// [now]
+func (m *misbehavior) prune(now time.Time) {
+	for vdr, streak := range m.streaks {
+		if now.Sub(streak.first) > misbehaviorWindow {
+			delete(m.streaks, vdr)
+		}
+	}
+}
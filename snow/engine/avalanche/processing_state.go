@@ -0,0 +1,96 @@
+// (c) 2019-2020, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package avalanche
+
+import (
+	"github.com/ava-labs/avalanchego/snow/choices"
+	"github.com/ava-labs/avalanchego/snow/consensus/avalanche"
+)
+
+// writeState persists the bytes of the vertices that are processing in
+// consensus, replacing the vertices persisted previously, so that they can be
+// issued again after a restart without being fetched
+func (t *Transitive) writeState() error {
+	if t.processingDB == nil || !t.Ctx.IsBootstrapped() {
+		return nil
+	}
+
+	batch := t.processingDB.NewBatch()
+	iter := t.processingDB.NewIterator()
+	for iter.Next() {
+		if err := batch.Delete(iter.Key()); err != nil {
+			iter.Release()
+			return err
+		}
+	}
+	iter.Release()
+	if err := iter.Error(); err != nil {
+		return err
+	}
+
+	numWritten := 0
+	for vtxID := range t.processing {
+		vtx, err := t.Manager.Get(vtxID)
+		if err != nil || vtx.Status() != choices.Processing {
+			// The vertex has been decided
+			t.processing.Remove(vtxID)
+			continue
+		}
+		if err := batch.Put(vtxID[:], vtx.Bytes()); err != nil {
+			return err
+		}
+		numWritten++
+	}
+	if err := batch.Write(); err != nil {
+		return err
+	}
+	t.Ctx.Log.Info("persisted %d processing vertices", numWritten)
+	return nil
+}
+
+// readState issues the vertices that were processing when the engine last
+// shut down. Assumes consensus has been initialized.
+func (t *Transitive) readState() error {
+	if t.processingDB == nil {
+		return nil
+	}
+
+	// All the vertices are parsed before any is issued, so that the vertices
+	// they depend on aren't fetched from peers
+	vtxs := []avalanche.Vertex(nil)
+	iter := t.processingDB.NewIterator()
+	for iter.Next() {
+		vtx, err := t.Manager.Parse(iter.Value())
+		if err != nil {
+			t.Ctx.Log.Debug("failed to parse persisted processing vertex due to %s", err)
+			continue
+		}
+		vtxs = append(vtxs, vtx)
+	}
+	iter.Release()
+	if err := iter.Error(); err != nil {
+		return err
+	}
+	if len(vtxs) == 0 {
+		return nil
+	}
+
+	// Any vertex that is still missing is fetched from a validator
+	vdr := t.Ctx.NodeID
+	if sampled, err := t.Validators.Sample(1); err == nil {
+		vdr = sampled[0].ID()
+	}
+	numIssued := 0
+	for _, vtx := range vtxs {
+		if vtx.Status().Decided() {
+			continue
+		}
+		if _, err := t.issueFrom(vdr, vtx); err != nil {
+			return err
+		}
+		numIssued++
+	}
+	t.Ctx.Log.Info("restored %d processing vertices", numIssued)
+	return t.errs.Err
+}
@@ -0,0 +1,238 @@
+// (c) 2019-2020, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package avalanche
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/ava-labs/avalanchego/ids"
+	"github.com/ava-labs/avalanchego/snow/consensus/avalanche"
+	"github.com/ava-labs/avalanchego/snow/consensus/snowstorm"
+	"github.com/ava-labs/avalanchego/utils/math"
+	"github.com/ava-labs/avalanchego/utils/timer"
+)
+
+const (
+	// Duration after which a processing tx is considered stalled. Preferences
+	// containing stalled txs are re-polled before any other preferences.
+	stalledTxDuration = 5 * time.Second
+
+	// Minimum number of tracked txs before decided txs are pruned
+	minRepollTxsPruned = 1024
+
+	// Halflife of the moving average of the fraction of queries that failed,
+	// which the number of concurrent re-polls adapts to
+	repollFailureHalflife = 30 * time.Second
+
+	// The number of concurrent re-polls is raised when the fraction of recent
+	// queries that failed is above [raiseRepollsFailureRate], and lowered
+	// when it's below [lowerRepollsFailureRate]
+	raiseRepollsFailureRate = .2
+	lowerRepollsFailureRate = .05
+)
+
+// issuedTx is a tx that was issued into consensus at [time]
+type issuedTx struct {
+	tx   snowstorm.Tx
+	time time.Time
+}
+
+// repollPriority is the priority of re-polling a preference
+type repollPriority struct {
+	// Time the oldest processing tx in the preference was issued
+	oldest time.Time
+	// Highest fee paid by a processing tx in the preference
+	fee uint64
+}
+
+// repollScheduler chooses which preferences to re-poll when there are more
+// preferences than polls that may be issued. Preferences containing stalled
+// txs are re-polled first, oldest first. The remaining preferences are
+// re-polled in order of the highest fee they contain, then by age.
+type repollScheduler struct {
+	clock timer.Clock
+
+	// Tx ID --> the tx and the time it was first issued into consensus. Txs
+	// are removed once they're decided.
+	txs map[ids.ID]issuedTx
+	// Number of txs that were tracked after they were last pruned
+	pruned int
+
+	// Vertex ID --> priority of re-polling the vertex, for the preferences
+	// that were last scheduled. Priorities are cached until the tracked txs
+	// are pruned, so that preferences don't have to be fetched for every
+	// re-poll.
+	priorities map[ids.ID]repollPriority
+}
+
+// Issued records that [txs] were issued into consensus
+func (s *repollScheduler) Issued(txs []snowstorm.Tx) {
+	if s.txs == nil {
+		s.txs = make(map[ids.ID]issuedTx)
+	}
+	now := s.clock.Time()
+	for _, tx := range txs {
+		txID := tx.ID()
+		if _, ok := s.txs[txID]; !ok {
+			s.txs[txID] = issuedTx{
+				tx:   tx,
+				time: now,
+			}
+		}
+	}
+	if len(s.txs) >= minRepollTxsPruned && len(s.txs) >= 2*s.pruned {
+		s.prune()
+	}
+}
+
+// Schedule returns the IDs of the [n] preferences in [vtxIDs] that should be
+// re-polled, highest priority first. [getVertex] is only called for the
+// preferences whose priority isn't cached.
+func (s *repollScheduler) Schedule(
+	vtxIDs []ids.ID,
+	n int,
+	getVertex func(ids.ID) (avalanche.Vertex, error),
+) ([]ids.ID, error) {
+	priorities := make(map[ids.ID]repollPriority, len(vtxIDs))
+	for _, vtxID := range vtxIDs {
+		priority, ok := s.priorities[vtxID]
+		if !ok {
+			vtx, err := getVertex(vtxID)
+			if err != nil {
+				return nil, fmt.Errorf("couldn't get preferred vertex %s: %w", vtxID, err)
+			}
+			priority, err = s.priority(vtx)
+			if err != nil {
+				return nil, err
+			}
+		}
+		priorities[vtxID] = priority
+	}
+	// Only the priorities of the current preferences are kept
+	s.priorities = priorities
+
+	now := s.clock.Time()
+	sorted := make([]ids.ID, len(vtxIDs))
+	copy(sorted, vtxIDs)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return priorities[sorted[i]].Less(priorities[sorted[j]], now)
+	})
+
+	if n > len(sorted) {
+		n = len(sorted)
+	}
+	return sorted[:n], nil
+}
+
+// priority returns the priority of re-polling [vtx]
+func (s *repollScheduler) priority(vtx avalanche.Vertex) (repollPriority, error) {
+	priority := repollPriority{oldest: s.clock.Time()}
+	txs, err := vtx.Txs()
+	if err != nil {
+		return priority, err
+	}
+	for _, tx := range txs {
+		issued, ok := s.txs[tx.ID()]
+		if !ok || tx.Status().Decided() {
+			continue
+		}
+		if issued.time.Before(priority.oldest) {
+			priority.oldest = issued.time
+		}
+		if feeTx, ok := tx.(snowstorm.FeeTx); ok && feeTx.Fee() > priority.fee {
+			priority.fee = feeTx.Fee()
+		}
+	}
+	return priority, nil
+}
+
+// prune stops tracking txs that have been decided. The cached priorities may
+// depend on the decided txs, so they're dropped.
+func (s *repollScheduler) prune() {
+	for txID, issued := range s.txs {
+		if issued.tx.Status().Decided() {
+			delete(s.txs, txID)
+		}
+	}
+	s.pruned = len(s.txs)
+	s.priorities = nil
+}
+
+// Less returns true if [p] should be re-polled before [o] at [now]
+func (p repollPriority) Less(o repollPriority, now time.Time) bool {
+	pStalled := now.Sub(p.oldest) >= stalledTxDuration
+	oStalled := now.Sub(o.oldest) >= stalledTxDuration
+	switch {
+	case pStalled != oStalled:
+		return pStalled
+	case !pStalled && p.fee != o.fee:
+		return p.fee > o.fee
+	default:
+		return p.oldest.Before(o.oldest)
+	}
+}
+
+// repollLimit adapts the number of concurrent re-polls to the recent query
+// failure rate. While queries fail, more polls are kept outstanding so that
+// consensus still makes progress under churn. Once queries succeed again, or
+// consensus quiesces, the number of polls falls back to the minimum.
+type repollLimit struct {
+	clock timer.Clock
+
+	// Bounds on the number of concurrent re-polls
+	min, max int
+	// Current number of concurrent re-polls
+	limit int
+
+	// Moving average of the fraction of queries that failed. Nil until the
+	// first query finishes.
+	failures math.Averager
+}
+
+// Initialize sets the bounds on the number of concurrent re-polls. If [max]
+// isn't greater than [min], the number of concurrent re-polls is fixed.
+func (l *repollLimit) Initialize(min, max int) {
+	if max < min {
+		max = min
+	}
+	l.min = min
+	l.max = max
+	l.limit = min
+	l.failures = nil
+}
+
+// Limit returns the number of polls that should be outstanding at once
+func (l *repollLimit) Limit() int { return l.limit }
+
+// QueryFinished records that a query was answered, or failed if [answered] is
+// false, and adjusts the limit by at most one
+func (l *repollLimit) QueryFinished(answered bool) {
+	if l.max == l.min {
+		return
+	}
+
+	value := 1.
+	if answered {
+		value = 0
+	}
+	now := l.clock.Time()
+	if l.failures == nil {
+		l.failures = math.NewAverager(value, repollFailureHalflife, now)
+	} else {
+		l.failures.Observe(value, now)
+	}
+
+	switch failures := l.failures.Read(); {
+	case failures > raiseRepollsFailureRate && l.limit < l.max:
+		l.limit++
+	case failures < lowerRepollsFailureRate && l.limit > l.min:
+		l.limit--
+	}
+}
+
+// Quiesced records that consensus has no processing vertices left, so there
+// is nothing to aggressively re-poll
+func (l *repollLimit) Quiesced() { l.limit = l.min }
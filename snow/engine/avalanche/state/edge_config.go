@@ -0,0 +1,70 @@
+// (c) 2019-2020, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package state
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+var errUnknownEdgePolicy = errors.New("unknown edge persistence policy")
+
+// EdgePolicy determines when the accepted frontier is written to the database.
+// The frontier is never written more than once per poll, however many vertices
+// the poll accepted.
+//
+// A vertex's acceptance is always committed along with the vertex, so a node
+// that stops before writing the frontier restarts with an older frontier. The
+// older frontier only contains accepted vertices.
+type EdgePolicy uint8
+
+const (
+	// EdgeAlways writes the frontier at the end of every poll that changed it
+	EdgeAlways EdgePolicy = iota
+
+	// EdgeInterval writes the frontier at the end of a poll that changed it,
+	// if it wasn't written within the configured interval
+	EdgeInterval
+
+	// EdgeOnShutdown writes the frontier only when the chain shuts down
+	EdgeOnShutdown
+)
+
+// ParseEdgePolicy returns the policy named [name]
+func ParseEdgePolicy(name string) (EdgePolicy, error) {
+	switch name {
+	case "always":
+		return EdgeAlways, nil
+	case "interval":
+		return EdgeInterval, nil
+	case "on-shutdown":
+		return EdgeOnShutdown, nil
+	default:
+		return 0, fmt.Errorf("%w: %q", errUnknownEdgePolicy, name)
+	}
+}
+
+func (p EdgePolicy) String() string {
+	switch p {
+	case EdgeAlways:
+		return "always"
+	case EdgeInterval:
+		return "interval"
+	case EdgeOnShutdown:
+		return "on-shutdown"
+	default:
+		return "unknown"
+	}
+}
+
+// EdgeConfig configures the persistence of the accepted frontier. The zero
+// value writes the frontier at the end of every poll that changed it.
+type EdgeConfig struct {
+	Policy EdgePolicy
+
+	// Minimum amount of time between writes of the frontier when [Policy] is
+	// EdgeInterval
+	Interval time.Duration
+}
@@ -7,6 +7,8 @@ package state
 
 import (
 	"errors"
+	"fmt"
+	"time"
 
 	"github.com/ava-labs/avalanchego/cache"
 	"github.com/ava-labs/avalanchego/database"
@@ -18,6 +20,7 @@ import (
 	"github.com/ava-labs/avalanchego/snow/consensus/snowstorm"
 	"github.com/ava-labs/avalanchego/snow/engine/avalanche/vertex"
 	"github.com/ava-labs/avalanchego/utils/math"
+	"github.com/ava-labs/avalanchego/utils/timer"
 )
 
 const (
@@ -28,15 +31,28 @@ const (
 var (
 	errUnknownVertex = errors.New("unknown vertex")
 	errWrongChainID  = errors.New("wrong ChainID in vertex")
+	errUnacceptedTx  = errors.New("can't accept a vertex containing a tx that isn't accepted")
+
+	_ vertex.EdgePersister = &Serializer{}
 )
 
 // Serializer manages the state of multiple vertices
 type Serializer struct {
+	// EdgeConfig determines when the accepted frontier is persisted. It must
+	// be set before Initialize is called.
+	EdgeConfig EdgeConfig
+
 	ctx   *snow.Context
 	vm    vertex.DAGVM
 	state *prefixedState
 	db    *versiondb.Database
 	edge  ids.Set
+	clock timer.Clock
+
+	// True if [edge] changed since it was last persisted
+	edgeDirty bool
+	// The last time [edge] was persisted
+	edgeWritten time.Time
 }
 
 // Initialize implements the avalanche.State interface
@@ -55,6 +71,15 @@ func (s *Serializer) Initialize(ctx *snow.Context, vm vertex.DAGVM, db database.
 	s.db = vdb
 
 	s.edge.Add(s.state.Edge()...)
+	s.edgeWritten = s.clock.Time()
+}
+
+// FlushCaches implements the common.CacheFlusher interface. Unique vertices
+// aren't flushed, as they may still be referenced by consensus.
+func (s *Serializer) FlushCaches() {
+	s.state.state.dbCache.Flush()
+	s.state.vtx.Flush()
+	s.state.status.Flush()
 }
 
 // Parse implements the avalanche.State interface
@@ -84,6 +109,7 @@ func (s *Serializer) Build(
 	}
 
 	vtx, err := vertex.Build(
+		s.ctx.HashFunction(),
 		s.ctx.ChainID,
 		height,
 		epoch,
@@ -110,8 +136,43 @@ func (s *Serializer) Get(vtxID ids.ID) (avalanche.Vertex, error) { return s.getV
 // Edge implements the avalanche.State interface
 func (s *Serializer) Edge() []ids.ID { return s.edge.List() }
 
+// FinishPoll implements the vertex.EdgePersister interface
+func (s *Serializer) FinishPoll() error {
+	switch s.EdgeConfig.Policy {
+	case EdgeInterval:
+		if s.clock.Time().Sub(s.edgeWritten) < s.EdgeConfig.Interval {
+			return nil
+		}
+	case EdgeOnShutdown:
+		return nil
+	}
+	return s.FlushEdge()
+}
+
+// FlushEdge implements the vertex.EdgePersister interface
+func (s *Serializer) FlushEdge() error {
+	if !s.edgeDirty {
+		return nil
+	}
+
+	defer s.db.Abort()
+
+	if err := s.state.SetEdge(s.edge.List()); err != nil {
+		// The frontier may have been cached before the write was aborted
+		s.state.state.dbCache.Flush()
+		return fmt.Errorf("failed to set edge due to %w", err)
+	}
+	if err := s.db.Commit(); err != nil {
+		s.state.state.dbCache.Flush()
+		return fmt.Errorf("failed to commit edge due to %w", err)
+	}
+	s.edgeDirty = false
+	s.edgeWritten = s.clock.Time()
+	return nil
+}
+
 func (s *Serializer) parseVertex(b []byte) (vertex.StatelessVertex, error) {
-	vtx, err := vertex.Parse(b)
+	vtx, err := vertex.Parse(s.ctx.HashFunction(), b)
 	if err != nil {
 		return nil, err
 	}
@@ -0,0 +1,139 @@
+// (c) 2019-2020, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package state
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/ava-labs/avalanchego/database"
+	"github.com/ava-labs/avalanchego/database/memdb"
+	"github.com/ava-labs/avalanchego/ids"
+	"github.com/ava-labs/avalanchego/snow"
+	"github.com/ava-labs/avalanchego/snow/choices"
+	"github.com/ava-labs/avalanchego/snow/consensus/avalanche"
+	"github.com/ava-labs/avalanchego/snow/consensus/snowstorm"
+	"github.com/ava-labs/avalanchego/snow/engine/avalanche/vertex"
+)
+
+// newEdgeTest returns a serializer persisting its edge according to [config],
+// and a function that returns a serializer reopened from the same database, as
+// a node that stopped without shutting down the chain would
+func newEdgeTest(t *testing.T, config EdgeConfig) (*Serializer, func() *Serializer) {
+	testTx := &snowstorm.TestTx{
+		TestDecidable: choices.TestDecidable{
+			IDV:     ids.ID{1},
+			StatusV: choices.Accepted,
+		},
+		BytesV: []byte{0},
+	}
+	vm := vertex.TestVM{}
+	vm.T = t
+	vm.Default(true)
+	vm.ParseF = func([]byte) (snowstorm.Tx, error) { return testTx, nil }
+
+	db := memdb.New()
+	open := func() *Serializer {
+		s := &Serializer{EdgeConfig: config}
+		s.Initialize(snow.DefaultContextTest(), &vm, db)
+		return s
+	}
+	return open(), open
+}
+
+// acceptChain builds and accepts a vertex and a child of it, returning the
+// child
+func acceptChain(t *testing.T, s *Serializer) avalanche.Vertex {
+	tx, err := s.vm.Parse([]byte{0})
+	assert.NoError(t, err)
+	parent, err := s.Build(0, nil, []snowstorm.Tx{tx}, nil)
+	assert.NoError(t, err)
+	child, err := s.Build(0, []ids.ID{parent.ID()}, []snowstorm.Tx{tx}, nil)
+	assert.NoError(t, err)
+
+	assert.NoError(t, parent.Accept())
+	assert.NoError(t, child.Accept())
+	return child
+}
+
+func TestSerializerEdgeWrittenOncePerPoll(t *testing.T) {
+	assert := assert.New(t)
+
+	s, reopen := newEdgeTest(t, EdgeConfig{Policy: EdgeAlways})
+	child := acceptChain(t, s)
+	assert.Equal([]ids.ID{child.ID()}, s.Edge())
+
+	// The accepted vertices are persisted before the frontier is, so a node
+	// that stops mid-poll restarts with an older frontier
+	restarted := reopen()
+	assert.Empty(restarted.Edge())
+	assert.Equal(choices.Accepted, restarted.state.Status(child.ID()))
+
+	assert.NoError(s.FinishPoll())
+	assert.Equal([]ids.ID{child.ID()}, reopen().Edge())
+}
+
+func TestSerializerEdgeInterval(t *testing.T) {
+	assert := assert.New(t)
+
+	s, reopen := newEdgeTest(t, EdgeConfig{
+		Policy:   EdgeInterval,
+		Interval: time.Minute,
+	})
+	now := time.Now()
+	s.clock.Set(now)
+	s.edgeWritten = now
+
+	child := acceptChain(t, s)
+	assert.NoError(s.FinishPoll())
+	assert.Empty(reopen().Edge())
+
+	s.clock.Set(now.Add(time.Minute))
+	assert.NoError(s.FinishPoll())
+	assert.Equal([]ids.ID{child.ID()}, reopen().Edge())
+}
+
+func TestSerializerEdgeOnShutdown(t *testing.T) {
+	assert := assert.New(t)
+
+	s, reopen := newEdgeTest(t, EdgeConfig{Policy: EdgeOnShutdown})
+	child := acceptChain(t, s)
+	assert.NoError(s.FinishPoll())
+	assert.Empty(reopen().Edge())
+
+	assert.NoError(s.FlushEdge())
+	assert.Equal([]ids.ID{child.ID()}, reopen().Edge())
+}
+
+func TestSerializerFailedEdgeFlush(t *testing.T) {
+	assert := assert.New(t)
+
+	s, _ := newEdgeTest(t, EdgeConfig{Policy: EdgeAlways})
+	child := acceptChain(t, s)
+
+	assert.NoError(s.db.Close())
+	assert.True(errors.Is(s.FlushEdge(), database.ErrClosed))
+
+	// The frontier is still pending, and an aborted write isn't cached
+	assert.True(s.edgeDirty)
+	assert.Equal([]ids.ID{child.ID()}, s.Edge())
+	_, found := s.state.state.dbCache.Get(uniqueEdgeID)
+	assert.False(found)
+}
+
+func TestParseEdgePolicy(t *testing.T) {
+	assert := assert.New(t)
+
+	for _, policy := range []EdgePolicy{EdgeAlways, EdgeInterval, EdgeOnShutdown} {
+		parsed, err := ParseEdgePolicy(policy.String())
+		assert.NoError(err)
+		assert.Equal(policy, parsed)
+	}
+
+	_, err := ParseEdgePolicy("sometimes")
+	assert.True(errors.Is(err, errUnknownEdgePolicy))
+}
@@ -13,7 +13,6 @@ import (
 	"github.com/ava-labs/avalanchego/snow/consensus/snowstorm"
 	"github.com/ava-labs/avalanchego/snow/engine/avalanche/vertex"
 	"github.com/ava-labs/avalanchego/utils/formatting"
-	"github.com/ava-labs/avalanchego/utils/hashing"
 )
 
 // uniqueVertex acts as a cache for vertices in the database.
@@ -33,7 +32,7 @@ type uniqueVertex struct {
 // and then parsing the vertex bytes on a cache miss.
 func newUniqueVertex(s *Serializer, b []byte) (*uniqueVertex, error) {
 	vtx := &uniqueVertex{
-		vtxID:      hashing.ComputeHash256Array(b),
+		vtxID:      s.ctx.HashFunction().ComputeHash256Array(b),
 		serializer: s,
 	}
 	vtx.shallowRefresh()
@@ -141,7 +140,29 @@ func (vtx *uniqueVertex) setStatus(status choices.Status) error {
 func (vtx *uniqueVertex) ID() ids.ID       { return vtx.vtxID }
 func (vtx *uniqueVertex) Key() interface{} { return vtx.vtxID }
 
+// Accept marks the vertex as accepted and adds it to the accepted frontier.
+// Every tx in the vertex must have already been accepted by the VM, so that an
+// accepted vertex never references txs that weren't persisted as accepted. The
+// vertex's status is committed immediately, while the new frontier is
+// persisted according to the serializer's EdgeConfig. If the accept fails, the
+// uncommitted writes are aborted and the in-memory status, frontier, and
+// caches are restored to match the database.
 func (vtx *uniqueVertex) Accept() error {
+	return vtx.decide(vtx.accept)
+}
+
+func (vtx *uniqueVertex) accept() error {
+	txs, err := vtx.Txs()
+	if err != nil {
+		return err
+	}
+	for _, tx := range txs {
+		if status := tx.Status(); status != choices.Accepted {
+			return fmt.Errorf("%w: tx %s of vertex %s has status %s",
+				errUnacceptedTx, tx.ID(), vtx.vtxID, status)
+		}
+	}
+
 	if err := vtx.setStatus(choices.Accepted); err != nil {
 		return err
 	}
@@ -155,28 +176,49 @@ func (vtx *uniqueVertex) Accept() error {
 	for _, parent := range parents {
 		vtx.serializer.edge.Remove(parent.ID())
 	}
-
-	if err := vtx.serializer.state.SetEdge(vtx.serializer.edge.List()); err != nil {
-		return fmt.Errorf("failed to set edge while accepting vertex %s due to %w", vtx.vtxID, err)
-	}
-
-	// Should never traverse into parents of a decided vertex. Allows for the
-	// parents to be garbage collected
-	vtx.v.parents = nil
+	vtx.serializer.edgeDirty = true
 
 	return vtx.serializer.db.Commit()
 }
 
+// Reject marks the vertex as rejected. If the reject fails, the vertex's
+// status is restored to match the database.
 func (vtx *uniqueVertex) Reject() error {
-	if err := vtx.setStatus(choices.Rejected); err != nil {
+	return vtx.decide(func() error {
+		if err := vtx.setStatus(choices.Rejected); err != nil {
+			return err
+		}
+		return vtx.serializer.db.Commit()
+	})
+}
+
+// decide runs [decision], which must commit its writes to the database only
+// once every write has succeeded. If [decision] fails, the state it modified
+// in memory is restored.
+func (vtx *uniqueVertex) decide(decision func() error) error {
+	defer vtx.serializer.db.Abort()
+
+	vtx.shallowRefresh()
+	prevStatus := vtx.v.status
+	prevEdge := vtx.serializer.edge.List()
+	prevEdgeDirty := vtx.serializer.edgeDirty
+
+	if err := decision(); err != nil {
+		vtx.serializer.db.Abort()
+		vtx.v.status = prevStatus
+		vtx.serializer.edge.Clear()
+		vtx.serializer.edge.Add(prevEdge...)
+		vtx.serializer.edgeDirty = prevEdgeDirty
+		// The status and frontier may have been cached before the writes were
+		// aborted
+		vtx.serializer.state.state.dbCache.Flush()
 		return err
 	}
 
 	// Should never traverse into parents of a decided vertex. Allows for the
 	// parents to be garbage collected
 	vtx.v.parents = nil
-
-	return vtx.serializer.db.Commit()
+	return nil
 }
 
 // TODO: run performance test to see if shallow refreshing
@@ -234,14 +276,17 @@ func (vtx *uniqueVertex) Txs() ([]snowstorm.Tx, error) {
 
 	txs := vtx.v.vtx.Txs()
 	if len(txs) != len(vtx.v.txs) {
-		vtx.v.txs = make([]snowstorm.Tx, len(txs))
+		// Only cache the txs once they have all been parsed, so that a failure
+		// to parse them can be retried
+		parsedTxs := make([]snowstorm.Tx, len(txs))
 		for i, txBytes := range txs {
 			tx, err := vtx.serializer.vm.Parse(txBytes)
 			if err != nil {
 				return nil, err
 			}
-			vtx.v.txs[i] = tx
+			parsedTxs[i] = tx
 		}
+		vtx.v.txs = parsedTxs
 	}
 
 	return vtx.v.txs, nil
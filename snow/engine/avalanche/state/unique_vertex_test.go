@@ -5,6 +5,7 @@ package state
 
 import (
 	"bytes"
+	"errors"
 	"testing"
 
 	"github.com/ava-labs/avalanchego/database/memdb"
@@ -13,6 +14,7 @@ import (
 	"github.com/ava-labs/avalanchego/snow/choices"
 	"github.com/ava-labs/avalanchego/snow/consensus/snowstorm"
 	"github.com/ava-labs/avalanchego/snow/engine/avalanche/vertex"
+	"github.com/ava-labs/avalanchego/utils/hashing"
 )
 
 func newSerializer(t *testing.T, parse func([]byte) (snowstorm.Tx, error)) *Serializer {
@@ -75,6 +77,7 @@ func TestUniqueVertexCacheHit(t *testing.T) {
 	chainID := ids.ID{} // Same as chainID of serializer
 	height := uint64(1)
 	vtx, err := vertex.Build(
+		hashing.Default,
 		chainID,
 		height,
 		0,
@@ -155,6 +158,7 @@ func TestUniqueVertexCacheMiss(t *testing.T) {
 	chainID := ids.ID{}
 	height := uint64(1)
 	innerVertex, err := vertex.Build(
+		hashing.Default,
 		chainID,
 		height,
 		0,
@@ -264,3 +268,164 @@ func TestUniqueVertexCacheMiss(t *testing.T) {
 	}
 	validateVertex(vtx, choices.Processing)
 }
+
+func TestUniqueVertexAcceptRequiresAcceptedTxs(t *testing.T) {
+	testTx := &snowstorm.TestTx{TestDecidable: choices.TestDecidable{
+		IDV:     ids.ID{1},
+		StatusV: choices.Processing,
+	}}
+
+	s := newSerializer(t, func(b []byte) (snowstorm.Tx, error) {
+		if !bytes.Equal(b, []byte{0}) {
+			t.Fatal("unknown tx")
+		}
+		return testTx, nil
+	})
+
+	vtx, err := vertex.Build(
+		hashing.Default,
+		ids.ID{}, // Same as chainID of serializer
+		0,
+		0,
+		nil,
+		[][]byte{{0}},
+		nil,
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	uVtx := &uniqueVertex{
+		vtxID:      vtx.ID(),
+		serializer: s,
+	}
+	if err := uVtx.setVertex(vtx); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := uVtx.Accept(); !errors.Is(err, errUnacceptedTx) {
+		t.Fatalf("Should have refused to accept a vertex with a processing tx, but got %v", err)
+	}
+	if status := s.state.Status(vtx.ID()); status != choices.Processing {
+		t.Fatalf("Vertex status should have stayed %s, but was %s", choices.Processing, status)
+	}
+	if len(s.Edge()) != 0 {
+		t.Fatalf("Vertex shouldn't have been added to the accepted frontier")
+	}
+
+	testTx.StatusV = choices.Accepted
+	if err := uVtx.Accept(); err != nil {
+		t.Fatal(err)
+	}
+	if status := s.state.Status(vtx.ID()); status != choices.Accepted {
+		t.Fatalf("Vertex status should have been %s, but was %s", choices.Accepted, status)
+	}
+	if edge := s.Edge(); len(edge) != 1 || edge[0] != vtx.ID() {
+		t.Fatalf("Vertex should have been added to the accepted frontier")
+	}
+}
+
+func TestUniqueVertexFailedAcceptRestoresState(t *testing.T) {
+	testTx := &snowstorm.TestTx{TestDecidable: choices.TestDecidable{
+		IDV:     ids.ID{1},
+		StatusV: choices.Accepted,
+	}}
+
+	vm := vertex.TestVM{}
+	vm.T = t
+	vm.Default(true)
+	vm.ParseF = func([]byte) (snowstorm.Tx, error) { return testTx, nil }
+
+	baseDB := memdb.New()
+	s := &Serializer{}
+	s.Initialize(snow.DefaultContextTest(), &vm, baseDB)
+
+	vtx, err := vertex.Build(
+		hashing.Default,
+		ids.ID{}, // Same as chainID of serializer
+		0,
+		0,
+		nil,
+		[][]byte{{0}},
+		nil,
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	uVtx := &uniqueVertex{
+		vtxID:      vtx.ID(),
+		serializer: s,
+	}
+	if err := uVtx.setVertex(vtx); err != nil {
+		t.Fatal(err)
+	}
+
+	// Closing the database causes the accept's commit to fail
+	if err := baseDB.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if err := uVtx.Accept(); err == nil {
+		t.Fatalf("Should have failed to accept the vertex")
+	}
+	if status := uVtx.Status(); status != choices.Processing {
+		t.Fatalf("Vertex status should have been restored to %s, but was %s", choices.Processing, status)
+	}
+	if len(s.Edge()) != 0 {
+		t.Fatalf("Accepted frontier should have been restored")
+	}
+	if _, found := s.state.state.dbCache.Get(uniqueEdgeID); found {
+		t.Fatalf("Aborted accepted frontier shouldn't be cached")
+	}
+}
+
+func TestSerializerIdentifiesVerticesWithChainHasher(t *testing.T) {
+	testTx := &snowstorm.TestTx{TestDecidable: choices.TestDecidable{
+		IDV: ids.ID{1},
+	}}
+	parseTx := func(b []byte) (snowstorm.Tx, error) {
+		if !bytes.Equal(b, []byte{0}) {
+			t.Fatal("unknown tx")
+		}
+		return testTx, nil
+	}
+
+	vtx, err := vertex.Build(
+		hashing.SHA3,
+		ids.ID{}, // Same as chainID of serializer
+		1,
+		0,
+		[]ids.ID{{'p', 'a', 'r', 'e', 'n', 't'}},
+		[][]byte{{0}},
+		nil,
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	vtxBytes := vtx.Bytes()
+
+	sha3Serializer := newSerializer(t, parseTx)
+	sha3Serializer.ctx.Hasher = hashing.SHA3
+	sha3Vtx, err := sha3Serializer.Parse(vtxBytes)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if sha3Vtx.ID() != vtx.ID() || sha3Vtx.ID() != ids.ID(hashing.SHA3.ComputeHash256Array(vtxBytes)) {
+		t.Fatalf("vertex should be identified by its SHA-3 hash")
+	}
+
+	// A chain using a different hash function identifies the same bytes
+	// differently, so its vertices can't reference the vertices of the SHA-3
+	// chain
+	defaultSerializer := newSerializer(t, parseTx)
+	defaultVtx, err := defaultSerializer.Parse(vtxBytes)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if defaultVtx.ID() != ids.ID(hashing.ComputeHash256Array(vtxBytes)) {
+		t.Fatalf("vertex should be identified by its default hash")
+	}
+	if defaultVtx.ID() == sha3Vtx.ID() {
+		t.Fatalf("vertex shouldn't have the same ID under different hash functions")
+	}
+}
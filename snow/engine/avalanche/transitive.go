@@ -4,9 +4,14 @@
 package avalanche
 
 import (
+	"errors"
 	"fmt"
+	"sort"
+	"strings"
 	"time"
 
+	"github.com/ava-labs/avalanchego/cache"
+	"github.com/ava-labs/avalanchego/database"
 	"github.com/ava-labs/avalanchego/ids"
 	"github.com/ava-labs/avalanchego/network"
 	"github.com/ava-labs/avalanchego/snow/choices"
@@ -27,6 +32,51 @@ const (
 	// TODO define this constant in one place rather than here and in snowman
 	// Max containers size in a MultiPut message
 	maxContainersLen = int(4 * network.DefaultMaxMessageSize / 5)
+
+	// Maximum number of tx IDs in a tx gossip message. Larger messages are
+	// dropped.
+	maxGossipTxs = 64
+
+	// Default maximum number of vertex requests that may be queued. Once
+	// exceeded, the least recently requested queued vertices are dropped.
+	defaultMaxQueuedVtxRequests = 4096
+
+	// Maximum number of vertex requests that inbound tx gossip may trigger
+	// between two gossip rounds of this node
+	maxGossipFetches = 8
+
+	// Maximum number of txs held while issuance is paused. Once exceeded, the
+	// txs that failed to be issued most recently are dropped.
+	maxRetryTxs = 8192
+
+	// Maximum number of vertices held while issuance is paused because the VM
+	// failed to persist their txs. Once exceeded, newly held vertices are
+	// dropped.
+	maxRetryVtxs = 1024
+
+	// Maximum number of txs deferred until their dependencies are issued. Once
+	// exceeded, newly deferred txs are dropped.
+	maxDeferredTxs = 8192
+
+	// Maximum number of txs held until the time the VM allows them to be
+	// issued. Once exceeded, newly delayed txs are dropped.
+	maxDelayedTxs = 8192
+
+	// Maximum number of ancestors sent, or accepted, as hints along with a
+	// vertex
+	maxParentHints = 16
+
+	// Minimum number of missing parents of a vertex that are fetched with a
+	// single GetAncestors request rather than a Get request each
+	minAncestorsFetchParents = 2
+
+	// Number of recently accepted vertices whose bytes are kept in memory, so
+	// that requests for them don't need to load them
+	acceptedCacheSize = 512
+
+	// Maximum number of vertices waiting for the missing dependencies of their
+	// txs to be accepted. Once exceeded, newly abandoned vertices are dropped.
+	maxUnverifiedVtxs = 1024
 )
 
 // Transitive implements the Engine interface by attempting to fetch all
@@ -38,11 +88,31 @@ type Transitive struct {
 	Params    avalanche.Parameters
 	Consensus avalanche.Consensus
 
+	// Holds the processing vertices across restarts. May be nil.
+	processingDB database.Database
+
 	polls poll.Set // track people I have asked for their preference
 
 	// The set of vertices that have been requested in Get messages but not yet received
 	outstandingVtxReqs common.Requests
 
+	// Vertex requests that are waiting for the number of outstanding vertex
+	// requests, to either all validators or the requested validator, to drop
+	// below the maximum. Ordered from least to most recently requested. A
+	// request is only sent if it's still the latest request for its vertex in
+	// [queuedVtxs] when it is dequeued.
+	queuedVtxReqs []vtxReq
+	// Vertex ID --> sequence number of the latest queued request for it
+	queuedVtxs map[ids.ID]uint64
+	// Sequence number of the most recently queued vertex request
+	queuedVtxSeq uint64
+
+	// Request ID --> outstanding GetAncestors request for the missing parents
+	// of a vertex
+	ancestorsReqs map[uint32]ancestorsReq
+	// IDs of the vertices being fetched by [ancestorsReqs]
+	ancestorsVtxIDs ids.Set
+
 	// missingTxs tracks transaction that are missing
 	missingTxs ids.Set
 
@@ -50,24 +120,100 @@ type Transitive struct {
 	// because of missing dependencies
 	pending ids.Set
 
-	// vtxBlocked tracks operations that are blocked on vertices
-	// txBlocked tracks operations that are blocked on transactions
-	vtxBlocked, txBlocked events.Blocker
+	// IDs of vertices that have been added to consensus. Vertices that have
+	// since been decided are only removed when the processing vertices are
+	// persisted.
+	processing ids.Set
+
+	// blocked tracks operations that are blocked on vertices and transactions
+	blocked events.TypedBlocker
 
 	// transactions that have been provided from the VM but that are pending to
 	// be issued once the number of processing vertices has gone below the
 	// optimal number.
 	pendingTxs []snowstorm.Tx
 
+	// Tracks failures to build vertices. While degraded, txs that couldn't be
+	// issued are held in [retryTxs] until issuance is retried.
+	degraded degradedMode
+
+	// Tracks whether consensus is making progress
+	liveness liveness
+	retryTxs []snowstorm.Tx
+	// Vertices that are issued again once issuance resumes, as the VM failed
+	// to persist their txs
+	retryVtxs []avalanche.Vertex
+
+	// IDs of the txs that are deferred until their dependencies are issued into
+	// consensus, and the deferred txs whose dependencies have since been issued
+	deferredTxs ids.Set
+	readyTxs    []snowstorm.Tx
+
+	// Txs that the VM doesn't allow to be issued yet
+	delayed delayedQueue
+
+	// Vertices that are retried once the missing dependencies of their txs are
+	// accepted
+	unverified unverifiedVtxs
+
+	// Chooses which preferences to re-poll when the number of concurrent
+	// re-polls is limited
+	repolls repollScheduler
+
+	// Number of re-polls that are kept outstanding at once
+	repollLimit repollLimit
+
+	// Tracks when a poll last finished, so that stalled preferences are
+	// re-polled even if no new txs are issued
+	heartbeat heartbeat
+
+	// Scores validators by their recent offenses, so that misbehaving
+	// validators are benched
+	misbehavior misbehavior
+
+	// Number of vertex requests that inbound tx gossip triggered since this
+	// node last gossiped
+	gossipFetches int
+
+	// Accepted frontier that was last published to the consensus dispatcher
+	publishedFrontier ids.Set
+	// Maximum height of the vertices in [publishedFrontier]
+	frontierHeight uint64
+
+	// Vertex ID --> bytes of recently accepted vertices
+	acceptedVtxs cache.LRU
+
 	errs wrappers.Errs
 }
 
+// vtxReq is a request for a vertex from a validator
+type vtxReq struct {
+	vdr   ids.ShortID
+	vtxID ids.ID
+	// sequence number of the request, if it's queued
+	seq uint64
+}
+
+// ancestorsReq is a request to a validator for the ancestry of a vertex whose
+// parents are missing
+type ancestorsReq struct {
+	vdr     ids.ShortID
+	vtx     avalanche.Vertex
+	missing []ids.ID
+}
+
 // Initialize implements the Engine interface
 func (t *Transitive) Initialize(config Config) error {
 	config.Ctx.Log.Info("initializing consensus engine")
 
 	t.Params = config.Params
+	t.processingDB = config.ProcessingDB
 	t.Consensus = config.Consensus
+	t.acceptedVtxs.Size = acceptedCacheSize
+	t.ancestorsReqs = make(map[uint32]ancestorsReq)
+	t.queuedVtxs = make(map[ids.ID]uint64)
+	t.repollLimit.Initialize(config.Params.ConcurrentRepolls, config.Params.MaxConcurrentRepolls)
+	t.heartbeat.Initialize(config.HeartbeatFrequency)
 
 	factory := poll.NewEarlyTermNoTraversalFactory(config.Params.Alpha)
 	t.polls = poll.NewSet(factory,
@@ -79,6 +225,7 @@ func (t *Transitive) Initialize(config Config) error {
 	if err := t.metrics.Initialize(config.Params.Namespace, config.Params.Metrics); err != nil {
 		return err
 	}
+	t.concurrentRepolls.Set(float64(t.repollLimit.Limit()))
 
 	return t.Bootstrapper.Initialize(
 		config.Config,
@@ -101,11 +248,74 @@ func (t *Transitive) finishBootstrapping() error {
 	}
 
 	t.Ctx.Log.Info("bootstrapping finished with %d vertices in the accepted frontier", len(frontier))
-	return t.Consensus.Initialize(t.Ctx, t.Params, frontier)
+	if err := t.Consensus.Initialize(t.Ctx, t.Params, frontier); err != nil {
+		return err
+	}
+	if err := t.persistEdge(false); err != nil {
+		return err
+	}
+	t.publishFrontier()
+	return t.readState()
+}
+
+// persistEdge gives the vertex storage a chance to persist the accepted
+// frontier, if it defers doing so. If [flush] is true, the frontier must be
+// persisted now.
+func (t *Transitive) persistEdge(flush bool) error {
+	persister, ok := t.Manager.(vertex.EdgePersister)
+	if !ok {
+		return nil
+	}
+	if flush {
+		return persister.FlushEdge()
+	}
+	return persister.FinishPoll()
+}
+
+// publishFrontier notifies the consensus dispatcher of the accepted frontier,
+// if it changed since it was last published, so that components that depend
+// on the accepted frontier don't need to poll it
+func (t *Transitive) publishFrontier() {
+	edge := t.Manager.Edge()
+	frontier := ids.Set{}
+	frontier.Add(edge...)
+	if frontier.Equals(t.publishedFrontier) {
+		return
+	}
+
+	height := uint64(0)
+	for _, vtxID := range edge {
+		vtx, err := t.Manager.Get(vtxID)
+		if err != nil {
+			t.Ctx.Log.Debug("couldn't load vertex %s of the accepted frontier due to %s", vtxID, err)
+			continue
+		}
+		// Peers are likely to request the vertices that were just accepted
+		t.cacheAccepted(vtx)
+		if vtxHeight, err := vtx.Height(); err == nil && vtxHeight > height {
+			height = vtxHeight
+		}
+	}
+
+	t.publishedFrontier = frontier
+	t.frontierHeight = height
+	t.liveness.Accepted()
+	t.Ctx.ConsensusDispatcher.AdvanceFrontier(t.Ctx, height, edge)
 }
 
 // Gossip implements the Engine interface
 func (t *Transitive) Gossip() error {
+	// Gossip is sent periodically, so it's used to retry issuance while
+	// degraded
+	if err := t.retryIssuance(); err != nil {
+		return err
+	}
+	if err := t.issueDelayedTxs(); err != nil {
+		return err
+	}
+	t.issueHeartbeat()
+	t.gossipFetches = 0
+
 	edge := t.Manager.Edge()
 	if len(edge) == 0 {
 		t.Ctx.Log.Verbo("dropping gossip request as no vertices have been accepted")
@@ -116,7 +326,7 @@ func (t *Transitive) Gossip() error {
 	if err := s.Initialize(uint64(len(edge))); err != nil {
 		return err // Should never really happen
 	}
-	indices, err := s.Sample(1)
+	indices, err := s.SampleWithSource(1, t.Ctx.RandomSource())
 	if err != nil {
 		return err // Also should never really happen because the edge has positive length
 	}
@@ -129,24 +339,162 @@ func (t *Transitive) Gossip() error {
 
 	t.Ctx.Log.Verbo("gossiping %s as accepted to the network", vtxID)
 	t.Sender.Gossip(vtxID, vtx.Bytes())
+
+	if t.Config.GossipAcceptedTxs {
+		t.gossipTxs(vtx)
+		if err := t.gossipPendingTxs(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// gossipPendingTxs gossips the IDs of the txs in a random preferred vertex
+func (t *Transitive) gossipPendingTxs() error {
+	preferences := t.Consensus.Preferences().List()
+	if len(preferences) == 0 {
+		return nil
+	}
+
+	s := sampler.NewUniform()
+	if err := s.Initialize(uint64(len(preferences))); err != nil {
+		return err // Should never really happen
+	}
+	indices, err := s.SampleWithSource(1, t.Ctx.RandomSource())
+	if err != nil {
+		return err // Also should never really happen because there are preferences
+	}
+	vtxID := preferences[int(indices[0])]
+	vtx, err := t.Manager.Get(vtxID)
+	if err != nil {
+		t.Ctx.Log.Warn("dropping tx gossip as %s couldn't be loaded due to: %s", vtxID, err)
+		return nil
+	}
+	t.gossipTxs(vtx)
 	return nil
 }
 
+// gossipTxs gossips the IDs of at most [maxGossipTxs] of the txs in [vtx],
+// preferring the txs that pay the highest fees
+func (t *Transitive) gossipTxs(vtx avalanche.Vertex) {
+	vtxID := vtx.ID()
+	txs, err := vtx.Txs()
+	if err != nil {
+		t.Ctx.Log.Warn("dropping tx gossip as the txs of %s couldn't be loaded due to: %s", vtxID, err)
+		return
+	}
+	if len(txs) == 0 {
+		return
+	}
+
+	if len(txs) > maxGossipTxs {
+		txs = append([]snowstorm.Tx(nil), txs...)
+		sort.SliceStable(txs, func(i, j int) bool { return txFee(txs[i]) > txFee(txs[j]) })
+		txs = txs[:maxGossipTxs]
+	}
+
+	txIDs := make([]ids.ID, len(txs))
+	for i, tx := range txs {
+		txIDs[i] = tx.ID()
+	}
+	t.Ctx.Log.Verbo("gossiping %d txs in %s to the network", len(txIDs), vtxID)
+	t.Sender.GossipTxs(vtxID, txIDs)
+}
+
+// txFee returns the fee paid by [tx], or 0 if it doesn't report its fee
+func txFee(tx snowstorm.Tx) uint64 {
+	if feeTx, ok := tx.(snowstorm.FeeTx); ok {
+		return feeTx.Fee()
+	}
+	return 0
+}
+
 // Shutdown implements the Engine interface
 func (t *Transitive) Shutdown() error {
 	t.Ctx.Log.Info("shutting down consensus engine")
+	if err := t.persistEdge(true); err != nil {
+		t.Ctx.Log.Error("failed to persist the accepted frontier due to %s", err)
+	}
+	if err := t.writeState(); err != nil {
+		t.Ctx.Log.Error("failed to persist the processing vertices due to %s", err)
+	}
 	return t.VM.Shutdown()
 }
 
 // Get implements the Engine interface
 func (t *Transitive) Get(vdr ids.ShortID, requestID uint32, vtxID ids.ID) error {
+	// The ancestors of an accepted vertex are accepted, so there are no hints
+	// to send along with it
+	if vtxBytes, ok := t.acceptedVtxs.Get(vtxID); ok {
+		t.metrics.acceptedCacheHits.Inc()
+		t.Sender.Put(vdr, requestID, vtxID, vtxBytes.([]byte))
+		return nil
+	}
+
 	// If this engine has access to the requested vertex, provide it
-	if vtx, err := t.Manager.Get(vtxID); err == nil {
+	vtx, err := t.Manager.Get(vtxID)
+	if err != nil {
+		return nil
+	}
+	t.cacheAccepted(vtx)
+	// The requester is likely missing the undecided ancestors of the vertex
+	// too, so they're sent along with it to save the requester a round trip
+	// per ancestor
+	hints, err := t.parentHints(vtx)
+	if err != nil {
+		return err
+	}
+	if len(hints) == 0 {
 		t.Sender.Put(vdr, requestID, vtxID, vtx.Bytes())
+	} else {
+		t.Sender.HintedPut(vdr, requestID, vtxID, vtx.Bytes(), hints)
 	}
 	return nil
 }
 
+// cacheAccepted keeps the bytes of [vtx] in memory, if it's accepted
+func (t *Transitive) cacheAccepted(vtx avalanche.Vertex) {
+	if vtx.Status() == choices.Accepted {
+		t.acceptedVtxs.Put(vtx.ID(), vtx.Bytes())
+	}
+}
+
+// parentHints returns the bytes of the processing ancestors of [vtx], in BFS
+// order, that fit in a message along with [vtx]
+func (t *Transitive) parentHints(vtx avalanche.Vertex) ([][]byte, error) {
+	var (
+		hints    [][]byte
+		hintsLen = len(vtx.Bytes())
+		queue    = []avalanche.Vertex{vtx}
+		visited  = ids.Set{}
+	)
+	for len(queue) > 0 && len(hints) < maxParentHints {
+		var current avalanche.Vertex
+		current, queue = queue[0], queue[1:]
+		parents, err := current.Parents()
+		if err != nil {
+			return nil, err
+		}
+		for _, parent := range parents {
+			parentID := parent.ID()
+			if visited.Contains(parentID) || parent.Status() != choices.Processing {
+				continue
+			}
+			visited.Add(parentID)
+
+			parentBytes := parent.Bytes()
+			newLen := wrappers.IntLen + hintsLen + len(parentBytes)
+			if newLen >= maxContainersLen || len(hints) >= maxParentHints {
+				return hints, nil
+			}
+			hints = append(hints, parentBytes)
+			hintsLen = newLen
+			queue = append(queue, parent)
+		}
+	}
+	return hints, nil
+}
+
 // GetAncestors implements the Engine interface
 func (t *Transitive) GetAncestors(vdr ids.ShortID, requestID uint32, vtxID ids.ID) error {
 	startTime := time.Now()
@@ -199,7 +547,18 @@ func (t *Transitive) GetAncestors(vdr ids.ShortID, requestID uint32, vtxID ids.I
 // Put implements the Engine interface
 func (t *Transitive) Put(vdr ids.ShortID, requestID uint32, vtxID ids.ID, vtxBytes []byte) error {
 	t.Ctx.Log.Verbo("Put(%s, %d, %s) called", vdr, requestID, vtxID)
+	return t.put(vdr, requestID, vtxID, vtxBytes, nil)
+}
+
+// HintedPut implements the Engine interface
+func (t *Transitive) HintedPut(vdr ids.ShortID, requestID uint32, vtxID ids.ID, vtxBytes []byte, hints [][]byte) error {
+	t.Ctx.Log.Verbo("HintedPut(%s, %d, %s) called with %d hints", vdr, requestID, vtxID, len(hints))
+	return t.put(vdr, requestID, vtxID, vtxBytes, hints)
+}
 
+// put parses [vtxBytes] and issues the vertex once its ancestors are fetched.
+// Ancestors that are missing are taken from [hints] rather than requested.
+func (t *Transitive) put(vdr ids.ShortID, requestID uint32, vtxID ids.ID, vtxBytes []byte, hints [][]byte) error {
 	if !t.Ctx.IsBootstrapped() { // Bootstrapping unfinished --> didn't call Get --> this message is invalid
 		if requestID == constants.GossipMsgRequestID {
 			t.Ctx.Log.Verbo("dropping gossip Put(%s, %d, %s) due to bootstrapping", vdr, requestID, vtxID)
@@ -213,14 +572,114 @@ func (t *Transitive) Put(vdr ids.ShortID, requestID uint32, vtxID ids.ID, vtxByt
 	if err != nil {
 		t.Ctx.Log.Debug("failed to parse vertex %s due to: %s", vtxID, err)
 		t.Ctx.Log.Verbo("vertex:\n%s", formatting.DumpBytes{Bytes: vtxBytes})
+		t.misbehaved(vdr, unparseableVtx)
 		return t.GetFailed(vdr, requestID)
 	}
+	if requestedID, ok := t.outstandingVtxReqs.Get(vdr, requestID); ok && requestedID != vtx.ID() {
+		t.Ctx.Log.Debug("Put(%s, %d) sent vertex %s but %s was requested", vdr, requestID, vtx.ID(), requestedID)
+		t.misbehaved(vdr, wrongRequestPut)
+	}
+	if err := t.parseHints(vtx, hints); err != nil {
+		return err
+	}
 	if _, err := t.issueFrom(vdr, vtx); err != nil {
 		return err
 	}
 	return t.attemptToIssueTxs()
 }
 
+// parseHints parses the hints that are missing ancestors of [vtx], so that
+// they don't need to be requested. Hints are only parsed if they're needed, so
+// a peer can't use them to make this engine store arbitrary vertices.
+func (t *Transitive) parseHints(vtx avalanche.Vertex, hints [][]byte) error {
+	if len(hints) > maxParentHints {
+		hints = hints[:maxParentHints]
+	}
+	return t.parseAncestors(vtx, hints)
+}
+
+// parseAncestors parses the vertices in [ancestors], which are in BFS order,
+// that are missing ancestors of [vtx]. Other vertices are skipped.
+func (t *Transitive) parseAncestors(vtx avalanche.Vertex, ancestors [][]byte) error {
+	if len(ancestors) == 0 {
+		return nil
+	}
+
+	missing := ids.Set{}
+	addMissingParents := func(vtx avalanche.Vertex) error {
+		parents, err := vtx.Parents()
+		if err != nil {
+			return err
+		}
+		for _, parent := range parents {
+			if !parent.Status().Fetched() {
+				missing.Add(parent.ID())
+			}
+		}
+		return nil
+	}
+	if err := addMissingParents(vtx); err != nil {
+		return err
+	}
+
+	// Ancestors are in BFS order, so an ancestor's missing parents are after it
+	for _, ancestor := range ancestors {
+		ancestorID := t.Ctx.HashFunction().ComputeHash256Array(ancestor)
+		if !missing.Contains(ancestorID) {
+			continue
+		}
+		parent, err := t.Manager.Parse(ancestor)
+		if err != nil {
+			t.Ctx.Log.Debug("failed to parse ancestor vertex %s due to: %s", ancestorID, err)
+			continue
+		}
+		missing.Remove(ancestorID)
+		if err := addMissingParents(parent); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// GossipTxs implements the Engine interface
+func (t *Transitive) GossipTxs(vdr ids.ShortID, vtxID ids.ID, txIDs []ids.ID) error {
+	if !t.Ctx.IsBootstrapped() {
+		t.Ctx.Log.Verbo("dropping GossipTxs(%s, %s) due to bootstrapping", vdr, vtxID)
+		return nil
+	}
+
+	// Only validators are trusted to trigger vertex requests, and a single
+	// message may not list more txs than a gossip message would
+	if !t.Validators.Contains(vdr) {
+		t.Ctx.Log.Verbo("dropping GossipTxs(%s, %s) from a non-validator", vdr, vtxID)
+		return nil
+	}
+	if len(txIDs) > maxGossipTxs {
+		t.Ctx.Log.Debug("dropping GossipTxs(%s, %s) with %d txs", vdr, vtxID, len(txIDs))
+		return nil
+	}
+
+	// If we already have the vertex, we already have its txs
+	if vtx, err := t.Manager.Get(vtxID); err == nil && vtx.Status().Fetched() {
+		return nil
+	}
+
+	for _, txID := range txIDs {
+		if tx, err := t.VM.Get(txID); err == nil && tx.Status() != choices.Unknown {
+			continue
+		}
+		if t.gossipFetches >= maxGossipFetches {
+			t.Ctx.Log.Verbo("not fetching %s from %s because %d gossiped vertices were already requested", vtxID, vdr, t.gossipFetches)
+			return nil
+		}
+		t.Ctx.Log.Verbo("fetching %s from %s because gossiped tx %s is unknown", vtxID, vdr, txID)
+		t.gossipFetches++
+		t.sendRequest(vdr, vtxID)
+		break
+	}
+	return nil
+}
+
 // GetFailed implements the Engine interface
 func (t *Transitive) GetFailed(vdr ids.ShortID, requestID uint32) error {
 	if !t.Ctx.IsBootstrapped() { // Bootstrapping unfinished --> didn't call Get --> this message is invalid
@@ -234,11 +693,12 @@ func (t *Transitive) GetFailed(vdr ids.ShortID, requestID uint32) error {
 		return nil
 	}
 
-	t.vtxBlocked.Abandon(vtxID)
+	t.blocked.Abandon(events.VertexKey(vtxID))
+	t.sendQueuedRequests()
 
-	if t.outstandingVtxReqs.Len() == 0 {
+	if t.outstandingVtxReqs.Len() == 0 && len(t.ancestorsReqs) == 0 {
 		for txID := range t.missingTxs {
-			t.txBlocked.Abandon(txID)
+			t.blocked.Abandon(events.TxKey(txID))
 		}
 		t.missingTxs.Clear()
 	}
@@ -260,9 +720,11 @@ func (t *Transitive) PullQuery(vdr ids.ShortID, requestID uint32, vtxID ids.ID)
 	// Will send chits to [vdr] once we have [vtxID] and its dependencies
 	c := &convincer{
 		consensus: t.Consensus,
+		manager:   t.Manager,
 		sender:    t.Sender,
 		vdr:       vdr,
 		requestID: requestID,
+		justify:   t.Config.JustifyChits,
 		errs:      &t.errs,
 	}
 
@@ -275,11 +737,11 @@ func (t *Transitive) PullQuery(vdr ids.ShortID, requestID uint32, vtxID ids.ID)
 
 	// [vtxID] isn't in consensus yet because we don't have it or a dependency.
 	if !inConsensus {
-		c.deps.Add(vtxID) // Don't send chits until [vtxID] is in consensus.
+		c.deps.Add(events.VertexKey(vtxID)) // Don't send chits until [vtxID] is in consensus.
 	}
 
 	// Wait until [vtxID] and its dependencies have been added to consensus before sending chits
-	t.vtxBlocked.Register(c)
+	t.blocked.Register(c)
 	return t.attemptToIssueTxs()
 }
 
@@ -295,6 +757,7 @@ func (t *Transitive) PushQuery(vdr ids.ShortID, requestID uint32, vtxID ids.ID,
 	if err != nil {
 		t.Ctx.Log.Debug("failed to parse vertex %s due to: %s", vtxID, err)
 		t.Ctx.Log.Verbo("vertex:\n%s", formatting.DumpBytes{Bytes: vtxBytes})
+		t.misbehaved(vdr, unparseableVtx)
 		return nil
 	}
 
@@ -307,10 +770,20 @@ func (t *Transitive) PushQuery(vdr ids.ShortID, requestID uint32, vtxID ids.ID,
 
 // Chits implements the Engine interface
 func (t *Transitive) Chits(vdr ids.ShortID, requestID uint32, votes []ids.ID) error {
+	return t.chits(vdr, requestID, votes, true)
+}
+
+// chits applies the [votes] of [vdr] in response to the query [requestID]. If
+// [answered] is false, the query failed.
+func (t *Transitive) chits(vdr ids.ShortID, requestID uint32, votes []ids.ID, answered bool) error {
 	if !t.Ctx.IsBootstrapped() {
 		t.Ctx.Log.Debug("dropping Chits(%s, %d) due to bootstrapping", vdr, requestID)
 		return nil
 	}
+	t.liveness.QueryFinished(answered)
+	t.repollLimit.QueryFinished(answered)
+	t.heartbeat.Polled()
+	t.concurrentRepolls.Set(float64(t.repollLimit.Limit()))
 
 	v := &voter{
 		t:         t,
@@ -322,17 +795,85 @@ func (t *Transitive) Chits(vdr ids.ShortID, requestID uint32, votes []ids.ID) er
 		if added, err := t.issueFromByID(vdr, vote); err != nil {
 			return err
 		} else if !added {
-			v.deps.Add(vote)
+			v.deps.Add(events.VertexKey(vote))
 		}
 	}
 
-	t.vtxBlocked.Register(v)
+	t.blocked.Register(v)
 	return t.attemptToIssueTxs()
 }
 
+// JustifiedChits implements the Engine interface
+func (t *Transitive) JustifiedChits(vdr ids.ShortID, requestID uint32, votes []ids.ID, heights []uint64) error {
+	if !t.Ctx.IsBootstrapped() {
+		t.Ctx.Log.Debug("dropping JustifiedChits(%s, %d) due to bootstrapping", vdr, requestID)
+		return nil
+	}
+
+	if err := t.justify(votes, heights); err != nil {
+		t.Ctx.Log.Debug("dropping JustifiedChits(%s, %d) due to: %s", vdr, requestID, err)
+		t.misbehaved(vdr, conflictingChits)
+		return t.QueryFailed(vdr, requestID)
+	}
+	return t.Chits(vdr, requestID, votes)
+}
+
+// justify returns an error if the claimed [heights] of the vertices in [votes]
+// contradict the vertices known locally. Votes for unknown vertices can't be
+// checked, so they are assumed to be justified.
+func (t *Transitive) justify(votes []ids.ID, heights []uint64) error {
+	if len(votes) != len(heights) {
+		return fmt.Errorf("expected %d heights but got %d", len(votes), len(heights))
+	}
+	for i, vtxID := range votes {
+		vtx, err := t.Manager.Get(vtxID)
+		if err != nil {
+			continue
+		}
+		switch vtx.Status() {
+		case choices.Unknown:
+			continue
+		case choices.Rejected:
+			return fmt.Errorf("voted for rejected vertex %s", vtxID)
+		}
+		height, err := vtx.Height()
+		if err != nil {
+			continue
+		}
+		if height != heights[i] {
+			return fmt.Errorf("voted for vertex %s with height %d but claimed height %d",
+				vtxID, height, heights[i])
+		}
+	}
+	return nil
+}
+
+// misbehaved records that [vdr] committed offense [o], and benches [vdr] if it
+// has misbehaved repeatedly
+func (t *Transitive) misbehaved(vdr ids.ShortID, o offense) {
+	switch o {
+	case unparseableVtx:
+		t.numUnparseableVtxs.Inc()
+	case wrongRequestPut:
+		t.numWrongRequestPuts.Inc()
+	case conflictingChits:
+		t.unjustifiedChits.Inc()
+	}
+	if vdr == t.Ctx.NodeID || !t.misbehavior.Offend(vdr) {
+		return
+	}
+
+	t.Ctx.Log.Info("benching %s after %d offenses within %s, most recently: %s",
+		vdr, misbehaviorThreshold, misbehaviorWindow, o)
+	t.numMisbehavingBenched.Inc()
+	if t.Config.Benchlist != nil {
+		t.Config.Benchlist.Bench(t.Ctx.ChainID, vdr, misbehaviorBenchDuration)
+	}
+}
+
 // QueryFailed implements the Engine interface
 func (t *Transitive) QueryFailed(vdr ids.ShortID, requestID uint32) error {
-	return t.Chits(vdr, requestID, nil)
+	return t.chits(vdr, requestID, nil, false)
 }
 
 // Notify implements the Engine interface
@@ -344,7 +885,7 @@ func (t *Transitive) Notify(msg common.Message) error {
 
 	switch msg {
 	case common.PendingTxs:
-		t.pendingTxs = append(t.pendingTxs, t.VM.Pending()...)
+		t.pendingTxs = append(t.pendingTxs, t.delayTxs(t.VM.Pending())...)
 		return t.attemptToIssueTxs()
 	default:
 		t.Ctx.Log.Warn("unexpected message from the VM: %s", msg)
@@ -357,18 +898,146 @@ func (t *Transitive) attemptToIssueTxs() error {
 	if err != nil {
 		return err
 	}
+	if !t.degraded.CanIssue() {
+		return nil
+	}
 
+	if len(t.retryTxs) > 0 {
+		t.pendingTxs = append(t.retryTxs, t.pendingTxs...)
+		t.retryTxs = nil
+	}
+	if t.delayed.HasReady() {
+		t.pendingTxs = append(t.pendingTxs, t.delayed.Ready()...)
+		t.numDelayedTxs.Set(float64(t.delayed.Len()))
+	}
 	t.pendingTxs, err = t.batch(t.pendingTxs, false /*=force*/, false /*=empty*/, true /*=limit*/)
 	return err
 }
 
+// delayTxs returns the txs in [txs] that may be issued now, and holds the
+// others until the time the VM allows them to be issued
+func (t *Transitive) delayTxs(txs []snowstorm.Tx) []snowstorm.Tx {
+	ready, numDropped := t.delayed.Split(txs)
+	if numDropped > 0 {
+		t.Ctx.Log.Debug("dropping %d transactions as %d transactions are already delayed",
+			numDropped, t.delayed.Len())
+		t.numDroppedDelayedTxs.Add(float64(numDropped))
+	}
+	t.numDelayedTxs.Set(float64(t.delayed.Len()))
+	return ready
+}
+
+// issueDelayedTxs attempts to issue the delayed txs that may now be issued.
+// It's called periodically, and after each poll, so that delayed txs are
+// issued even if the VM doesn't notify the engine again.
+func (t *Transitive) issueDelayedTxs() error {
+	if !t.delayed.HasReady() || !t.Ctx.IsBootstrapped() {
+		return nil
+	}
+	return t.attemptToIssueTxs()
+}
+
+// retryIssuance attempts to issue txs if the engine is degraded. It's called
+// periodically so that issuance resumes even if no messages are received.
+func (t *Transitive) retryIssuance() error {
+	if !t.degraded.Degraded() || !t.Ctx.IsBootstrapped() || !t.degraded.CanIssue() {
+		return nil
+	}
+
+	failures := t.degraded.failures
+	vtxs := t.retryVtxs
+	t.retryVtxs = nil
+	for _, vtx := range vtxs {
+		if _, err := t.issueFrom(t.Ctx.NodeID, vtx); err != nil {
+			return err
+		}
+	}
+	if t.degraded.failures == failures && len(t.retryTxs) == 0 {
+		// Every held vertex was issued and there are no held txs, so no
+		// vertex needs to be built to recover
+		t.recoverIssuance()
+	}
+	return t.attemptToIssueTxs()
+}
+
+// issueHeartbeat re-polls the preferences if no poll finished within the
+// heartbeat frequency, consensus isn't finalized, and fewer polls than the
+// limit are outstanding. It's called periodically so that consensus makes
+// progress even if no new txs are issued.
+func (t *Transitive) issueHeartbeat() {
+	if !t.Ctx.IsBootstrapped() || !t.heartbeat.Due() || t.Consensus.Quiesce() {
+		return
+	}
+	t.heartbeat.Polled()
+
+	numPolls := t.repollLimit.Limit() - t.polls.Len()
+	if numPolls <= 0 {
+		return
+	}
+	t.Ctx.Log.Debug("issuing %d heartbeat polls as no poll finished in %s", numPolls, t.heartbeat.frequency)
+	t.heartbeatPolls.Inc()
+	t.issueRepolls(numPolls)
+}
+
+// deferUnverified records that [vtxID] should be issued again once [txIDs],
+// which its txs failed verification without, are accepted
+func (t *Transitive) deferUnverified(vtxID ids.ID, txIDs []ids.ID) {
+	if !t.unverified.Add(vtxID, txIDs, maxUnverifiedVtxs) {
+		t.Ctx.Log.Debug("dropping %s as too many vertices are waiting for missing dependencies", vtxID)
+		t.numDroppedUnverifiedVtxs.Inc()
+		return
+	}
+	t.Ctx.Log.Debug("retrying %s once its %d missing dependencies are accepted", vtxID, len(txIDs))
+	t.numUnverifiedVtxs.Set(float64(t.unverified.Len()))
+}
+
+// retryUnverified issues the vertices whose txs failed verification due to
+// missing dependencies that have since been accepted. Vertices waiting on
+// rejected dependencies are dropped. It's called after each poll.
+func (t *Transitive) retryUnverified() error {
+	if t.unverified.Len() == 0 {
+		return nil
+	}
+
+	ready := []ids.ID(nil)
+	for _, txID := range t.unverified.Dependencies() {
+		tx, err := t.VM.Get(txID)
+		if err != nil {
+			continue
+		}
+		switch tx.Status() {
+		case choices.Accepted:
+			ready = append(ready, t.unverified.Accept(txID)...)
+		case choices.Rejected:
+			dropped := t.unverified.Reject(txID)
+			t.numDroppedUnverifiedVtxs.Add(float64(len(dropped)))
+		}
+	}
+	t.numUnverifiedVtxs.Set(float64(t.unverified.Len()))
+
+	for _, vtxID := range ready {
+		vtx, err := t.Manager.Get(vtxID)
+		if err != nil {
+			t.Ctx.Log.Debug("couldn't retry %s due to %s", vtxID, err)
+			continue
+		}
+		if vtx.Status().Decided() || t.Consensus.VertexIssued(vtx) {
+			continue
+		}
+		t.Ctx.Log.Debug("retrying %s as its missing dependencies were accepted", vtxID)
+		t.retriedUnverifiedVtxs.Inc()
+		if _, err := t.issueFrom(t.Ctx.NodeID, vtx); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 // If there are pending transactions from the VM, issue them.
 // If we're not already at the limit for number of concurrent polls, issue a new
 // query.
 func (t *Transitive) repoll() {
-	for i := t.polls.Len(); i < t.Params.ConcurrentRepolls && !t.errs.Errored(); i++ {
-		t.issueRepoll()
-	}
+	t.issueRepolls(t.repollLimit.Limit() - t.polls.Len())
 }
 
 // issueFromByID issues the branch ending with vertex [vtxID] to consensus.
@@ -412,10 +1081,11 @@ func (t *Transitive) issueFrom(vdr ids.ShortID, vtx avalanche.Vertex) (bool, err
 			return false, err
 		}
 		// Ensure we have ancestors of this vertex
+		missing := []ids.ID(nil)
 		for _, parent := range parents {
 			if !parent.Status().Fetched() {
 				// We don't have the parent. Request it.
-				t.sendRequest(vdr, parent.ID())
+				missing = append(missing, parent.ID())
 				// We're missing an ancestor so we can't have issued the vtx in this method's argument
 				issued = false
 			} else {
@@ -423,6 +1093,7 @@ func (t *Transitive) issueFrom(vdr ids.ShortID, vtx avalanche.Vertex) (bool, err
 				ancestry.Push(parent)
 			}
 		}
+		t.requestParents(vdr, vtx, missing)
 
 		// Queue up this vertex to be issued once its dependencies are met
 		if err := t.issue(vtx); err != nil {
@@ -440,6 +1111,8 @@ func (t *Transitive) issue(vtx avalanche.Vertex) error {
 	// Add to set of vertices that have been queued up to be issued but haven't been yet
 	t.pending.Add(vtxID)
 	t.outstandingVtxReqs.RemoveAny(vtxID)
+	delete(t.queuedVtxs, vtxID)
+	t.sendQueuedRequests()
 
 	// Will put [vtx] into consensus once dependencies are met
 	i := &issuer{
@@ -454,13 +1127,22 @@ func (t *Transitive) issue(vtx avalanche.Vertex) error {
 	for _, parent := range parents {
 		if !t.Consensus.VertexIssued(parent) {
 			// This parent hasn't been issued yet. Add it as a dependency.
-			i.vtxDeps.Add(parent.ID())
+			i.deps.Add(events.VertexKey(parent.ID()))
+		}
+	}
+	if len(parents) > 0 {
+		// The height of a vertex is one more than the height of its tallest
+		// parent
+		if height, err := vtx.Height(); err == nil && height > 0 && t.isStale(height-1) {
+			t.Ctx.Log.Debug("vertex %s at height %d builds on parents more than %d heights behind the accepted frontier at height %d",
+				vtxID, height, t.Config.StaleVertexDepth, t.frontierHeight)
+			t.numStaleVtxs.Inc()
 		}
 	}
 
 	txs, err := vtx.Txs()
 	if err != nil {
-		return err
+		return t.holdVtx(vtx, err)
 	}
 	txIDs := ids.Set{}
 	for _, tx := range txs {
@@ -473,23 +1155,22 @@ func (t *Transitive) issue(vtx avalanche.Vertex) error {
 			if !txIDs.Contains(depID) && !t.Consensus.TxIssued(dep) {
 				// This transaction hasn't been issued yet. Add it as a dependency.
 				t.missingTxs.Add(depID)
-				i.txDeps.Add(depID)
+				i.deps.Add(events.TxKey(depID))
 			}
 		}
 	}
 
 	t.Ctx.Log.Verbo("vertex %s is blocking on %d vertices and %d transactions",
-		vtxID, i.vtxDeps.Len(), i.txDeps.Len())
+		vtxID, i.deps.CountKind(events.VertexKind), i.deps.CountKind(events.TxKind))
 
-	// Wait until all the parents of [vtx] are added to consensus before adding [vtx]
-	t.vtxBlocked.Register(&vtxIssuer{i: i})
-	// Wait until all the parents of [tx] are added to consensus before adding [vtx]
-	t.txBlocked.Register(&txIssuer{i: i})
+	// Wait until all the parents of [vtx] and of its transactions are added to
+	// consensus before adding [vtx]
+	t.blocked.Register(i)
 
-	if t.outstandingVtxReqs.Len() == 0 {
+	if t.outstandingVtxReqs.Len() == 0 && len(t.ancestorsReqs) == 0 {
 		// There are no outstanding vertex requests but we don't have these transactions, so we're not getting them.
 		for txID := range t.missingTxs {
-			t.txBlocked.Abandon(txID)
+			t.blocked.Abandon(events.TxKey(txID))
 		}
 		t.missingTxs.Clear()
 	}
@@ -509,6 +1190,10 @@ func (t *Transitive) batch(txs []snowstorm.Tx, force, empty, limit bool) ([]snow
 	if limit && t.Params.OptimalProcessing <= t.Consensus.NumProcessing() {
 		return txs, nil
 	}
+	if !t.degraded.CanIssue() {
+		t.holdTxs(txs)
+		return nil, nil
+	}
 	issuedTxs := ids.Set{}
 	consumed := ids.Set{}
 	issued := false
@@ -524,6 +1209,10 @@ func (t *Transitive) batch(txs []snowstorm.Tx, force, empty, limit bool) ([]snow
 			if err := t.issueBatch(txs[start:end]); err != nil {
 				return nil, err
 			}
+			if !t.degraded.CanIssue() {
+				t.holdTxs(txs[end:])
+				return nil, nil
+			}
 			if limit && t.Params.OptimalProcessing <= t.Consensus.NumProcessing() {
 				return txs[end:], nil
 			}
@@ -552,22 +1241,45 @@ func (t *Transitive) batch(txs []snowstorm.Tx, force, empty, limit bool) ([]snow
 		return txs[end:], t.issueBatch(txs[start:end])
 	}
 	if empty && !issued {
-		t.issueRepoll()
+		t.issueRepolls(1)
 	}
 	return txs[end:], nil
 }
 
-// Issues a new poll for a preferred vertex in order to move consensus along
-func (t *Transitive) issueRepoll() {
+// Issues [numPolls] new polls for preferred vertices in order to move consensus
+// along. If there are more preferred vertices than polls, the vertices to poll
+// are chosen by [t.repolls].
+func (t *Transitive) issueRepolls(numPolls int) {
+	if numPolls <= 0 || t.errs.Errored() {
+		return
+	}
+
 	preferredIDs := t.Consensus.Preferences()
 	if preferredIDs.Len() == 0 {
 		t.Ctx.Log.Error("re-query attempt was dropped due to no pending vertices")
 		return
 	}
 
-	vtxID := preferredIDs.CappedList(1)[0]
-	vdrs, err := t.Validators.Sample(t.Params.K) // Validators to sample
-	vdrBag := ids.ShortBag{}                     // IDs of validators to be sampled
+	vtxIDs := preferredIDs.List()
+	if len(vtxIDs) > numPolls {
+		scheduled, err := t.repolls.Schedule(vtxIDs, numPolls, t.Manager.Get)
+		if err != nil {
+			t.errs.Add(err)
+			return
+		}
+		vtxIDs = scheduled
+	}
+
+	for i := 0; i < numPolls && !t.errs.Errored(); i++ {
+		t.issueRepoll(vtxIDs[i%len(vtxIDs)])
+	}
+}
+
+// Issues a new poll for the preferred vertex [vtxID]
+func (t *Transitive) issueRepoll(vtxID ids.ID) {
+	// Validators to sample
+	vdrs, err := t.Validators.SampleWithSource(t.Params.K, t.Ctx.RandomSource())
+	vdrBag := ids.ShortBag{} // IDs of validators to be sampled
 	for _, vdr := range vdrs {
 		vdrBag.Add(vdr.ID())
 	}
@@ -586,17 +1298,24 @@ func (t *Transitive) issueRepoll() {
 
 // Puts a batch of transactions into a vertex and issues it into consensus.
 func (t *Transitive) issueBatch(txs []snowstorm.Tx) error {
+	txs = t.issuableTxs(txs)
+	if len(txs) == 0 {
+		return nil
+	}
 	t.Ctx.Log.Verbo("batching %d transactions into a new vertex", len(txs))
 
 	// Randomly select parents of this vertex from among the virtuous set
 	virtuousIDs := t.Consensus.Virtuous().CappedList(t.Params.Parents)
+	if t.Config.RefuseStaleParents {
+		virtuousIDs = t.freshParents(virtuousIDs)
+	}
 	numVirtuousIDs := len(virtuousIDs)
 	s := sampler.NewUniform()
 	if err := s.Initialize(uint64(numVirtuousIDs)); err != nil {
 		return err
 	}
 
-	indices, err := s.Sample(numVirtuousIDs)
+	indices, err := s.SampleWithSource(numVirtuousIDs, t.Ctx.RandomSource())
 	if err != nil {
 		return err
 	}
@@ -608,25 +1327,417 @@ func (t *Transitive) issueBatch(txs []snowstorm.Tx) error {
 
 	vtx, err := t.Manager.Build(0, parentIDs, txs, nil)
 	if err != nil {
-		t.Ctx.Log.Warn("error building new vertex with %d parents and %d transactions",
-			len(parentIDs), len(txs))
+		backoff := t.degraded.Fail(err)
+		t.numVtxBuildFailures.Inc()
+		t.degradedGauge.Set(1)
+		t.Ctx.Log.Warn("error building new vertex with %d parents and %d transactions, pausing issuance for %s due to: %s",
+			len(parentIDs), len(txs), backoff, err)
+		t.holdTxs(txs)
 		return nil
 	}
+	t.recoverIssuance()
 	return t.issue(vtx)
 }
 
+// recoverIssuance records that issuance succeeded, if the engine is degraded
+func (t *Transitive) recoverIssuance() {
+	if !t.degraded.Degraded() {
+		return
+	}
+	t.Ctx.Log.Info("resuming issuance after %d failures", t.degraded.failures)
+	t.degraded.Recover()
+	t.degradedGauge.Set(0)
+}
+
+// holdVtx handles the failure, [err], to load the txs of [vtx]. If the VM
+// failed to persist them, rather than halting, [vtx] is abandoned, issuance
+// is paused, and [vtx] is issued again once issuance resumes. Other failures
+// are returned.
+func (t *Transitive) holdVtx(vtx avalanche.Vertex, err error) error {
+	var persistErr *snowstorm.PersistenceError
+	if !errors.As(err, &persistErr) {
+		return err
+	}
+
+	vtxID := vtx.ID()
+	backoff := t.degraded.Fail(err)
+	t.numTxPersistFailures.Inc()
+	t.degradedGauge.Set(1)
+	t.Ctx.Log.Warn("error persisting the transactions of vertex %s, pausing issuance for %s due to: %s",
+		vtxID, backoff, err)
+
+	if len(t.retryVtxs) < maxRetryVtxs {
+		t.retryVtxs = append(t.retryVtxs, vtx)
+	} else {
+		t.Ctx.Log.Debug("dropping vertex %s as issuance is paused and %d vertices are already held",
+			vtxID, len(t.retryVtxs))
+		t.numDroppedRetryVtxs.Inc()
+	}
+	t.pending.Remove(vtxID)
+	t.numPendingVts.Set(float64(t.pending.Len()))
+	// Vertices waiting on [vtx] will be fetched again once it is issued
+	t.blocked.Abandon(events.VertexKey(vtxID))
+	return nil
+}
+
+// isStale returns true if a vertex at [height] is too far behind the accepted
+// frontier to be built on
+func (t *Transitive) isStale(height uint64) bool {
+	depth := t.Config.StaleVertexDepth
+	return depth > 0 && height+depth < t.frontierHeight
+}
+
+// freshParents returns the vertices in [vtxIDs] that aren't stale. If they all
+// are, the accepted frontier is returned instead, so that new vertices don't
+// need to build on lagging validators' vertices.
+func (t *Transitive) freshParents(vtxIDs []ids.ID) []ids.ID {
+	fresh := make([]ids.ID, 0, len(vtxIDs))
+	for _, vtxID := range vtxIDs {
+		vtx, err := t.Manager.Get(vtxID)
+		if err != nil {
+			// The vertex can't be checked, so it's assumed to be fresh
+			fresh = append(fresh, vtxID)
+			continue
+		}
+		if height, err := vtx.Height(); err == nil && t.isStale(height) {
+			t.Ctx.Log.Verbo("not building on stale vertex %s at height %d", vtxID, height)
+			t.numStaleParentsSkipped.Inc()
+			continue
+		}
+		fresh = append(fresh, vtxID)
+	}
+	if len(fresh) > 0 || len(vtxIDs) == 0 {
+		return fresh
+	}
+
+	edge := t.Manager.Edge()
+	if len(edge) > t.Params.Parents {
+		edge = edge[:t.Params.Parents]
+	}
+	return edge
+}
+
+// holdTxs adds [txs] to the txs whose issuance will be retried once issuance
+// resumes. Txs beyond [maxRetryTxs] are dropped, so that a long outage doesn't
+// grow the held txs without bound.
+func (t *Transitive) holdTxs(txs []snowstorm.Tx) {
+	if numDropped := len(t.retryTxs) + len(txs) - maxRetryTxs; numDropped > 0 {
+		if numDropped > len(txs) {
+			numDropped = len(txs)
+		}
+		t.Ctx.Log.Debug("dropping %d transactions as issuance is paused and %d are already held",
+			numDropped, len(t.retryTxs))
+		t.numDroppedRetryTxs.Add(float64(numDropped))
+		txs = txs[:len(txs)-numDropped]
+	}
+	t.retryTxs = append(t.retryTxs, txs...)
+}
+
+// issuableTxs returns [txs], ordered so that each tx comes after its
+// dependencies, without the txs that have a dependency that isn't processing
+// or accepted and isn't earlier in [txs]. A vertex built with those txs would
+// be blocked until the dependency is issued, so they're deferred until their
+// dependencies are issued. Txs with a rejected dependency can never be
+// accepted, so they're dropped.
+func (t *Transitive) issuableTxs(txs []snowstorm.Tx) []snowstorm.Tx {
+	sortedTxs, err := snowstorm.TopologicalSort(txs)
+	if err != nil {
+		t.Ctx.Log.Warn("dropping %d transactions due to: %s", len(txs), err)
+		return nil
+	}
+
+	included := ids.Set{}
+	issuable := make([]snowstorm.Tx, 0, len(sortedTxs))
+	for _, tx := range sortedTxs {
+		txID := tx.ID()
+		rejected := false
+		deps := events.KeySet{}
+		for _, dep := range tx.Dependencies() {
+			depID := dep.ID()
+			if included.Contains(depID) {
+				continue
+			}
+			switch status := dep.Status(); {
+			case status == choices.Rejected:
+				rejected = true
+			case status == choices.Accepted:
+			case status == choices.Processing && t.Consensus.TxIssued(dep):
+			default:
+				deps.Add(events.TxKey(depID))
+			}
+		}
+		switch {
+		case rejected:
+			t.Ctx.Log.Debug("dropping transaction %s as one of its dependencies was rejected", txID)
+		case deps.Len() != 0:
+			t.deferTx(tx, deps)
+		default:
+			included.Add(txID)
+			issuable = append(issuable, tx)
+		}
+	}
+	return issuable
+}
+
+// deferTx defers the issuance of [tx] until the txs in [deps] are issued into
+// consensus
+func (t *Transitive) deferTx(tx snowstorm.Tx, deps events.KeySet) {
+	txID := tx.ID()
+	if t.deferredTxs.Contains(txID) {
+		return
+	}
+	if t.deferredTxs.Len() >= maxDeferredTxs {
+		t.Ctx.Log.Debug("dropping transaction %s as %d transactions are already deferred", txID, t.deferredTxs.Len())
+		return
+	}
+	t.Ctx.Log.Verbo("deferring transaction %s until its %d dependencies are issued", txID, deps.Len())
+	t.deferredTxs.Add(txID)
+	t.blocked.Register(&txDeferrer{
+		t:    t,
+		tx:   tx,
+		deps: deps,
+	})
+}
+
+// issueReadyTxs issues the deferred txs whose dependencies have been issued
+func (t *Transitive) issueReadyTxs() error {
+	if len(t.readyTxs) == 0 {
+		return nil
+	}
+	txs := t.readyTxs
+	t.readyTxs = nil
+	_, err := t.batch(txs, false /*=force*/, false /*=empty*/, false /*=limit*/)
+	return err
+}
+
 // Send a request to [vdr] asking them to send us vertex [vtxID]
 func (t *Transitive) sendRequest(vdr ids.ShortID, vtxID ids.ID) {
 	if t.outstandingVtxReqs.Contains(vtxID) {
 		t.Ctx.Log.Debug("not sending request for vertex %s because there is already an outstanding request for it", vtxID)
 		return
 	}
+	if _, queued := t.queuedVtxs[vtxID]; queued {
+		// The vertex is needed again, so its request is moved to the back of
+		// the queue, and it will be requested from the latest validator
+		t.Ctx.Log.Verbo("refreshing queued request for vertex %s", vtxID)
+		t.queueRequest(vdr, vtxID)
+		return
+	}
+	if maxGets := t.Config.MaxOutstandingGets; maxGets > 0 && t.outstandingVtxReqs.Len() >= maxGets {
+		t.Ctx.Log.Verbo("queueing request for vertex %s because there are %d outstanding requests", vtxID, maxGets)
+		t.queueRequest(vdr, vtxID)
+		return
+	}
+	if t.peerAtMaxGets(vdr) {
+		t.Ctx.Log.Verbo("queueing request for vertex %s because there are %d outstanding requests to %s", vtxID, t.Config.MaxOutstandingGetsPerPeer, vdr)
+		t.numPeerLimitedVtxRequests.Inc()
+		t.queueRequest(vdr, vtxID)
+		return
+	}
 	t.RequestID++
 	t.outstandingVtxReqs.Add(vdr, t.RequestID, vtxID) // Mark that there is an outstanding request for this vertex
 	t.Sender.Get(vdr, t.RequestID, vtxID)
 	t.numVtxRequests.Set(float64(t.outstandingVtxReqs.Len())) // Tracks performance statistics
 }
 
+// requestParents requests the missing parents, [missing], of [vtx] from
+// [vdr]. If enough of them aren't already being fetched, the ancestry of [vtx]
+// is requested with a single GetAncestors request rather than requesting each
+// parent with a Get request.
+func (t *Transitive) requestParents(vdr ids.ShortID, vtx avalanche.Vertex, missing []ids.ID) {
+	needed := make([]ids.ID, 0, len(missing))
+	for _, vtxID := range missing {
+		if _, queued := t.queuedVtxs[vtxID]; !queued && !t.outstandingVtxReqs.Contains(vtxID) && !t.ancestorsVtxIDs.Contains(vtxID) {
+			needed = append(needed, vtxID)
+		}
+	}
+	if len(needed) < minAncestorsFetchParents {
+		for _, vtxID := range needed {
+			t.sendRequest(vdr, vtxID)
+		}
+		return
+	}
+
+	t.RequestID++
+	t.ancestorsReqs[t.RequestID] = ancestorsReq{
+		vdr:     vdr,
+		vtx:     vtx,
+		missing: needed,
+	}
+	t.ancestorsVtxIDs.Add(needed...)
+	t.Sender.GetAncestors(vdr, t.RequestID, vtx.ID())
+	t.numAncestorsRequests.Set(float64(len(t.ancestorsReqs)))
+}
+
+// MultiPut implements the Engine interface. Once bootstrapped, it's received
+// in response to a GetAncestors request for the missing parents of a vertex.
+func (t *Transitive) MultiPut(vdr ids.ShortID, requestID uint32, vtxs [][]byte) error {
+	if !t.Ctx.IsBootstrapped() {
+		return t.Bootstrapper.MultiPut(vdr, requestID, vtxs)
+	}
+
+	req, ok := t.removeAncestorsReq(vdr, requestID)
+	if !ok {
+		t.Ctx.Log.Debug("MultiPut(%s, %d) called without having sent corresponding GetAncestors", vdr, requestID)
+		return nil
+	}
+	if len(vtxs) > common.MaxContainersPerMultiPut {
+		vtxs = vtxs[:common.MaxContainersPerMultiPut]
+	}
+	if err := t.parseAncestors(req.vtx, vtxs); err != nil {
+		return err
+	}
+	if err := t.issueAncestors(req); err != nil {
+		return err
+	}
+	return t.attemptToIssueTxs()
+}
+
+// GetAncestorsFailed implements the Engine interface
+func (t *Transitive) GetAncestorsFailed(vdr ids.ShortID, requestID uint32) error {
+	if !t.Ctx.IsBootstrapped() {
+		return t.Bootstrapper.GetAncestorsFailed(vdr, requestID)
+	}
+
+	req, ok := t.removeAncestorsReq(vdr, requestID)
+	if !ok {
+		t.Ctx.Log.Debug("GetAncestorsFailed(%s, %d) called without having sent corresponding GetAncestors", vdr, requestID)
+		return nil
+	}
+	// Fall back to requesting each missing parent
+	if err := t.issueAncestors(req); err != nil {
+		return err
+	}
+	return t.attemptToIssueTxs()
+}
+
+// removeAncestorsReq removes, and returns, the outstanding GetAncestors
+// request with ID [requestID] that was sent to [vdr]
+func (t *Transitive) removeAncestorsReq(vdr ids.ShortID, requestID uint32) (ancestorsReq, bool) {
+	req, ok := t.ancestorsReqs[requestID]
+	if !ok || req.vdr != vdr {
+		return ancestorsReq{}, false
+	}
+	delete(t.ancestorsReqs, requestID)
+	t.ancestorsVtxIDs.Remove(req.missing...)
+	t.numAncestorsRequests.Set(float64(len(t.ancestorsReqs)))
+	return req, true
+}
+
+// issueAncestors issues the parents that [req] fetched, and requests each of
+// the parents that it didn't fetch with a Get request
+func (t *Transitive) issueAncestors(req ancestorsReq) error {
+	for _, vtxID := range req.missing {
+		if _, err := t.issueFromByID(req.vdr, vtxID); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// peerAtMaxGets returns true if no more vertex requests may be sent to [vdr]
+// until one of its outstanding requests finishes
+func (t *Transitive) peerAtMaxGets(vdr ids.ShortID) bool {
+	maxGets := t.Config.MaxOutstandingGetsPerPeer
+	return maxGets > 0 && t.outstandingVtxReqs.LenOf(vdr) >= maxGets
+}
+
+// maxQueuedGets returns the maximum number of vertex requests that may be
+// queued
+func (t *Transitive) maxQueuedGets() int {
+	if t.Config.MaxQueuedGets > 0 {
+		return t.Config.MaxQueuedGets
+	}
+	return defaultMaxQueuedVtxRequests
+}
+
+// queueRequest queues a request to [vdr] for [vtxID] as the most recent
+// request, replacing any request already queued for [vtxID]
+func (t *Transitive) queueRequest(vdr ids.ShortID, vtxID ids.ID) {
+	t.queuedVtxSeq++
+	t.queuedVtxReqs = append(t.queuedVtxReqs, vtxReq{
+		vdr:   vdr,
+		vtxID: vtxID,
+		seq:   t.queuedVtxSeq,
+	})
+	t.queuedVtxs[vtxID] = t.queuedVtxSeq
+	t.dropQueuedRequests()
+	t.numQueuedVtxRequests.Set(float64(len(t.queuedVtxs)))
+}
+
+// isQueued returns true if [req] is the latest queued request for its vertex
+func (t *Transitive) isQueued(req vtxReq) bool {
+	seq, ok := t.queuedVtxs[req.vtxID]
+	return ok && seq == req.seq
+}
+
+// dropQueuedRequests drops the least recently requested queued vertices until
+// no more than the maximum number of requests are queued. Anything waiting on
+// a dropped vertex is abandoned, as if the request had failed.
+func (t *Transitive) dropQueuedRequests() {
+	maxQueued := t.maxQueuedGets()
+	for len(t.queuedVtxs) > maxQueued {
+		req := t.queuedVtxReqs[0]
+		t.queuedVtxReqs = t.queuedVtxReqs[1:]
+
+		if !t.isQueued(req) {
+			// The vertex was issued, or requested again, while this request
+			// was queued
+			continue
+		}
+		delete(t.queuedVtxs, req.vtxID)
+		t.numDroppedVtxRequests.Inc()
+		t.Ctx.Log.Debug("dropping queued request for vertex %s because %d requests are queued", req.vtxID, maxQueued)
+		t.blocked.Abandon(events.VertexKey(req.vtxID))
+	}
+
+	// Requests that were replaced while queued are only removed once
+	// dequeued, so they're removed here if they dominate the queue
+	if len(t.queuedVtxReqs) > 2*maxQueued {
+		t.queuedVtxReqs = t.liveQueuedRequests(t.queuedVtxReqs)
+	}
+}
+
+// liveQueuedRequests returns the requests in [reqs] that are still queued
+func (t *Transitive) liveQueuedRequests(reqs []vtxReq) []vtxReq {
+	queued := make([]vtxReq, 0, len(t.queuedVtxs))
+	for _, req := range reqs {
+		if t.isQueued(req) {
+			queued = append(queued, req)
+		}
+	}
+	return queued
+}
+
+// sendQueuedRequests sends queued vertex requests, least recently requested
+// first, until either there are no more queued requests or the maximum number
+// of requests are outstanding. Requests to validators that have the maximum
+// number of outstanding requests remain queued.
+func (t *Transitive) sendQueuedRequests() {
+	maxGets := t.Config.MaxOutstandingGets
+	deferred := []vtxReq(nil)
+	for len(t.queuedVtxReqs) > 0 && (maxGets <= 0 || t.outstandingVtxReqs.Len() < maxGets) {
+		req := t.queuedVtxReqs[0]
+		t.queuedVtxReqs = t.queuedVtxReqs[1:]
+
+		if !t.isQueued(req) {
+			// The vertex was issued, or requested again, while this request
+			// was queued
+			continue
+		}
+		if t.peerAtMaxGets(req.vdr) {
+			deferred = append(deferred, req)
+			continue
+		}
+		delete(t.queuedVtxs, req.vtxID)
+		t.sendRequest(req.vdr, req.vtxID)
+	}
+	if len(deferred) > 0 {
+		t.queuedVtxReqs = append(deferred, t.liveQueuedRequests(t.queuedVtxReqs)...)
+	}
+	t.numQueuedVtxRequests.Set(float64(len(t.queuedVtxs)))
+}
+
 // Health implements the common.Engine interface
 func (t *Transitive) HealthCheck() (interface{}, error) {
 	var (
@@ -637,15 +1748,39 @@ func (t *Transitive) HealthCheck() (interface{}, error) {
 		consensusIntf, consensusErr = t.Consensus.HealthCheck()
 	}
 	vmIntf, vmErr := t.VM.HealthCheck()
+	issuanceIntf, issuanceErr := t.degraded.HealthCheck()
+	engineIntf := t.liveness.HealthCheck(map[string]interface{}{
+		"outstandingPolls":       t.polls.Len(),
+		"concurrentRepolls":      t.repollLimit.Limit(),
+		"blockedVertices":        t.blocked.Len(events.VertexKind),
+		"pendingVertices":        t.pending.Len(),
+		"outstandingVtxRequests": t.outstandingVtxReqs.Len(),
+	})
 	intf := map[string]interface{}{
 		"consensus": consensusIntf,
 		"vm":        vmIntf,
+		"issuance":  issuanceIntf,
+		"engine":    engineIntf,
 	}
-	if consensusErr == nil {
-		return intf, vmErr
+
+	checks := []struct {
+		name string
+		err  error
+	}{
+		{name: "vm", err: vmErr},
+		{name: "consensus", err: consensusErr},
+		{name: "issuance", err: issuanceErr},
+	}
+	failed := []string(nil)
+	var err error
+	for _, check := range checks {
+		if check.err != nil {
+			err = check.err
+			failed = append(failed, fmt.Sprintf("%s: %s", check.name, check.err))
+		}
 	}
-	if vmErr == nil {
-		return intf, consensusErr
+	if len(failed) > 1 {
+		err = errors.New(strings.Join(failed, " ; "))
 	}
-	return intf, fmt.Errorf("vm: %s ; consensus: %s", vmErr, consensusErr)
+	return intf, err
 }
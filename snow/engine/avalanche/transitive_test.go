@@ -6,20 +6,29 @@ package avalanche
 import (
 	"bytes"
 	"errors"
+	"fmt"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 
 	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
 
+	"github.com/ava-labs/avalanchego/database/memdb"
 	"github.com/ava-labs/avalanchego/ids"
+	"github.com/ava-labs/avalanchego/snow"
 	"github.com/ava-labs/avalanchego/snow/choices"
 	"github.com/ava-labs/avalanchego/snow/consensus/avalanche"
 	"github.com/ava-labs/avalanchego/snow/consensus/snowball"
 	"github.com/ava-labs/avalanchego/snow/consensus/snowstorm"
 	"github.com/ava-labs/avalanchego/snow/engine/avalanche/vertex"
 	"github.com/ava-labs/avalanchego/snow/engine/common"
+	"github.com/ava-labs/avalanchego/snow/events"
+	"github.com/ava-labs/avalanchego/snow/networking/benchlist"
 	"github.com/ava-labs/avalanchego/snow/validators"
+	"github.com/ava-labs/avalanchego/utils/constants"
+	"github.com/ava-labs/avalanchego/utils/hashing"
 	"github.com/ava-labs/avalanchego/utils/wrappers"
 )
 
@@ -131,7 +140,7 @@ func TestEngineAdd(t *testing.T) {
 		t.Fatalf("Didn't ask for a missing vertex")
 	}
 
-	if len(te.vtxBlocked) != 1 {
+	if te.blocked.Len(events.VertexKind) != 1 {
 		t.Fatalf("Should have been blocking on request")
 	}
 
@@ -143,7 +152,7 @@ func TestEngineAdd(t *testing.T) {
 
 	manager.ParseF = nil
 
-	if len(te.vtxBlocked) != 0 {
+	if te.blocked.Len(events.VertexKind) != 0 {
 		t.Fatalf("Should have finished blocking issue")
 	}
 }
@@ -393,7 +402,7 @@ func TestEngineQuery(t *testing.T) {
 	if vtx0.Status() != choices.Accepted {
 		t.Fatalf("Should have executed vertex")
 	}
-	if len(te.vtxBlocked) != 0 {
+	if te.blocked.Len(events.VertexKind) != 0 {
 		t.Fatalf("Should have finished blocking")
 	}
 
@@ -402,7 +411,7 @@ func TestEngineQuery(t *testing.T) {
 	if err := te.QueryFailed(vdr, *queryRequestID); err != nil {
 		t.Fatal(err)
 	}
-	if len(te.vtxBlocked) != 0 {
+	if te.blocked.Len(events.VertexKind) != 0 {
 		t.Fatalf("Should have finished blocking")
 	}
 }
@@ -587,7 +596,7 @@ func TestEngineMultipleQuery(t *testing.T) {
 	if vtx0.Status() != choices.Accepted {
 		t.Fatalf("Should have executed vertex")
 	}
-	if len(te.vtxBlocked) != 0 {
+	if te.blocked.Len(events.VertexKind) != 0 {
 		t.Fatalf("Should have finished blocking")
 	}
 }
@@ -736,7 +745,7 @@ func TestEngineAbandonResponse(t *testing.T) {
 		t.Fatal(err)
 	}
 
-	if len(te.vtxBlocked) != 0 {
+	if te.blocked.Len(events.VertexKind) != 0 {
 		t.Fatalf("Should have removed blocking event")
 	}
 }
@@ -1418,7 +1427,7 @@ func TestEngineGetVertex(t *testing.T) {
 	sender.Default(true)
 	sender.CantGetAcceptedFrontier = false
 
-	vdr := validators.GenerateRandomValidator(1)
+	vdr := validators.NewTestSetBuilder(0).Add(1).Validators(constants.PrimaryNetworkID)[0]
 
 	manager := vertex.NewTestManager(t)
 	config.Manager = manager
@@ -1465,6 +1474,68 @@ func TestEngineGetVertex(t *testing.T) {
 	}
 }
 
+func TestEngineGetAcceptedVertexFromCache(t *testing.T) {
+	config := DefaultConfig()
+
+	sender := &common.SenderTest{}
+	sender.T = t
+	config.Sender = sender
+
+	sender.Default(true)
+	sender.CantGetAcceptedFrontier = false
+
+	vdr := validators.NewTestSetBuilder(0).Add(1).Validators(constants.PrimaryNetworkID)[0]
+
+	manager := vertex.NewTestManager(t)
+	config.Manager = manager
+
+	manager.Default(true)
+
+	gVtx := &avalanche.TestVertex{
+		TestDecidable: choices.TestDecidable{
+			IDV:     ids.GenerateTestID(),
+			StatusV: choices.Accepted,
+		},
+		BytesV: []byte{0},
+	}
+
+	manager.EdgeF = func() []ids.ID { return []ids.ID{gVtx.ID()} }
+	manager.GetF = func(id ids.ID) (avalanche.Vertex, error) {
+		if id != gVtx.ID() {
+			t.Fatalf("Unknown vertex")
+		}
+		return gVtx, nil
+	}
+
+	te := &Transitive{}
+	if err := te.Initialize(config); err != nil {
+		t.Fatal(err)
+	}
+
+	// The accepted frontier was loaded while initializing, so it's served
+	// without loading it again
+	manager.CantGet = true
+	manager.GetF = nil
+
+	put := false
+	sender.PutF = func(v ids.ShortID, _ uint32, vtxID ids.ID, vtx []byte) {
+		put = true
+		if v != vdr.ID() {
+			t.Fatalf("Wrong validator")
+		}
+		if vtxID != gVtx.ID() || !bytes.Equal(vtx, gVtx.Bytes()) {
+			t.Fatalf("Wrong vertex")
+		}
+	}
+
+	if err := te.Get(vdr.ID(), 0, gVtx.ID()); err != nil {
+		t.Fatal(err)
+	}
+	if !put {
+		t.Fatalf("Should have sent the vertex")
+	}
+}
+
 func TestEngineInsufficientValidators(t *testing.T) {
 	config := DefaultConfig()
 
@@ -1781,7 +1852,7 @@ func TestEngineParentBlockingInsert(t *testing.T) {
 		t.Fatal(err)
 	}
 
-	if len(te.vtxBlocked) != 2 {
+	if te.blocked.Len(events.VertexKind) != 2 {
 		t.Fatalf("Both inserts should be blocking")
 	}
 
@@ -1792,7 +1863,7 @@ func TestEngineParentBlockingInsert(t *testing.T) {
 		t.Fatal(err)
 	}
 
-	if len(te.vtxBlocked) != 0 {
+	if te.blocked.Len(events.VertexKind) != 0 {
 		t.Fatalf("Both inserts should not longer be blocking")
 	}
 }
@@ -1901,7 +1972,7 @@ func TestEngineBlockingChitRequest(t *testing.T) {
 		t.Fatal(err)
 	}
 
-	if len(te.vtxBlocked) != 3 {
+	if te.blocked.Len(events.VertexKind) != 3 {
 		t.Fatalf("Both inserts and the query should be blocking")
 	}
 
@@ -1913,7 +1984,7 @@ func TestEngineBlockingChitRequest(t *testing.T) {
 		t.Fatal(err)
 	}
 
-	if len(te.vtxBlocked) != 0 {
+	if te.blocked.Len(events.VertexKind) != 0 {
 		t.Fatalf("Both inserts should not longer be blocking")
 	}
 }
@@ -2032,7 +2103,7 @@ func TestEngineBlockingChitResponse(t *testing.T) {
 		t.Fatal(err)
 	}
 
-	if len(te.vtxBlocked) != 2 {
+	if te.blocked.Len(events.VertexKind) != 2 {
 		t.Fatalf("The insert should be blocking, as well as the chit response")
 	}
 
@@ -2045,7 +2116,7 @@ func TestEngineBlockingChitResponse(t *testing.T) {
 		t.Fatal(err)
 	}
 
-	if len(te.vtxBlocked) != 0 {
+	if te.blocked.Len(events.VertexKind) != 0 {
 		t.Fatalf("Both inserts should not longer be blocking")
 	}
 }
@@ -2164,7 +2235,7 @@ func TestEngineMissingTx(t *testing.T) {
 		t.Fatal(err)
 	}
 
-	if len(te.vtxBlocked) != 2 {
+	if te.blocked.Len(events.VertexKind) != 2 {
 		t.Fatalf("The insert should be blocking, as well as the chit response")
 	}
 
@@ -2177,7 +2248,7 @@ func TestEngineMissingTx(t *testing.T) {
 		t.Fatal(err)
 	}
 
-	if len(te.vtxBlocked) != 0 {
+	if te.blocked.Len(events.VertexKind) != 0 {
 		t.Fatalf("Both inserts should not longer be blocking")
 	}
 }
@@ -3275,71 +3346,29 @@ func TestEngineGossip(t *testing.T) {
 	}
 }
 
-func TestEngineInvalidVertexIgnoredFromUnexpectedPeer(t *testing.T) {
+func TestEngineGossipAcceptedTxs(t *testing.T) {
 	config := DefaultConfig()
-
-	vals := validators.NewSet()
-	config.Validators = vals
-
-	vdr := ids.GenerateTestShortID()
-	secondVdr := ids.GenerateTestShortID()
-
-	if err := vals.AddWeight(vdr, 1); err != nil {
-		t.Fatal(err)
-	}
-	if err := vals.AddWeight(secondVdr, 1); err != nil {
-		t.Fatal(err)
-	}
+	config.GossipAcceptedTxs = true
 
 	sender := &common.SenderTest{}
 	sender.T = t
 	config.Sender = sender
 
+	sender.Default(true)
+
 	manager := vertex.NewTestManager(t)
 	config.Manager = manager
 
-	gVtx := &avalanche.TestVertex{
-		TestDecidable: choices.TestDecidable{
-			IDV:     ids.GenerateTestID(),
-			StatusV: choices.Accepted,
-		},
-		BytesV: []byte{0},
-	}
-
-	vts := []avalanche.Vertex{gVtx}
-	utxos := []ids.ID{ids.GenerateTestID(), ids.GenerateTestID()}
-
-	tx0 := &snowstorm.TestTx{TestDecidable: choices.TestDecidable{
-		IDV:     ids.GenerateTestID(),
-		StatusV: choices.Processing,
-	}}
-	tx0.InputIDsV = append(tx0.InputIDsV, utxos[0])
-
-	tx1 := &snowstorm.TestTx{TestDecidable: choices.TestDecidable{
+	tx := &snowstorm.TestTx{TestDecidable: choices.TestDecidable{
 		IDV:     ids.GenerateTestID(),
-		StatusV: choices.Processing,
+		StatusV: choices.Accepted,
 	}}
-	tx1.InputIDsV = append(tx1.InputIDsV, utxos[1])
-
-	vtx0 := &avalanche.TestVertex{
-		TestDecidable: choices.TestDecidable{
-			IDV:     ids.GenerateTestID(),
-			StatusV: choices.Unknown,
-		},
-		ParentsV: vts,
-		HeightV:  1,
-		TxsV:     []snowstorm.Tx{tx0},
-		BytesV:   []byte{1},
-	}
-	vtx1 := &avalanche.TestVertex{
+	gVtx := &avalanche.TestVertex{
 		TestDecidable: choices.TestDecidable{
 			IDV:     ids.GenerateTestID(),
-			StatusV: choices.Processing,
+			StatusV: choices.Accepted,
 		},
-		ParentsV: []avalanche.Vertex{vtx0},
-		HeightV:  2,
-		TxsV:     []snowstorm.Tx{tx1},
-		BytesV:   []byte{2},
+		TxsV: []snowstorm.Tx{tx},
 	}
 
 	te := &Transitive{}
@@ -3347,86 +3376,42 @@ func TestEngineInvalidVertexIgnoredFromUnexpectedPeer(t *testing.T) {
 		t.Fatal(err)
 	}
 
-	parsed := new(bool)
-	manager.ParseF = func(b []byte) (avalanche.Vertex, error) {
-		if bytes.Equal(b, vtx1.Bytes()) {
-			*parsed = true
-			return vtx1, nil
-		}
-		return nil, errUnknownVertex
-	}
-
+	manager.EdgeF = func() []ids.ID { return []ids.ID{gVtx.ID()} }
 	manager.GetF = func(vtxID ids.ID) (avalanche.Vertex, error) {
-		if !*parsed {
-			return nil, errUnknownVertex
-		}
-
-		if vtxID == vtx1.ID() {
-			return vtx1, nil
-		}
-		return nil, errUnknownVertex
-	}
-
-	reqID := new(uint32)
-	sender.GetF = func(reqVdr ids.ShortID, requestID uint32, vtxID ids.ID) {
-		*reqID = requestID
-		if reqVdr != vdr {
-			t.Fatalf("Wrong validator requested")
-		}
-		if vtxID != vtx0.ID() {
-			t.Fatalf("Wrong vertex requested")
-		}
-	}
-
-	if err := te.PushQuery(vdr, 0, vtx1.ID(), vtx1.Bytes()); err != nil {
-		t.Fatal(err)
-	}
-
-	if err := te.Put(secondVdr, *reqID, vtx0.ID(), []byte{3}); err != nil {
-		t.Fatal(err)
-	}
-
-	*parsed = false
-	manager.ParseF = func(b []byte) (avalanche.Vertex, error) {
-		if bytes.Equal(b, vtx0.Bytes()) {
-			*parsed = true
-			return vtx0, nil
+		if vtxID == gVtx.ID() {
+			return gVtx, nil
 		}
+		t.Fatal(errUnknownVertex)
 		return nil, errUnknownVertex
 	}
 
-	manager.GetF = func(vtxID ids.ID) (avalanche.Vertex, error) {
-		if !*parsed {
-			return nil, errUnknownVertex
+	sender.GossipF = func(ids.ID, []byte) {}
+	called := new(bool)
+	sender.GossipTxsF = func(vtxID ids.ID, txIDs []ids.ID) {
+		*called = true
+		if vtxID != gVtx.ID() {
+			t.Fatal(errUnknownVertex)
 		}
-
-		if vtxID == vtx0.ID() {
-			return vtx0, nil
+		if len(txIDs) != 1 || txIDs[0] != tx.ID() {
+			t.Fatalf("Should have gossiped the ID of the accepted tx")
 		}
-		return nil, errUnknownVertex
 	}
-	sender.CantPushQuery = false
-	sender.CantChits = false
-
-	vtx0.StatusV = choices.Processing
 
-	if err := te.Put(vdr, *reqID, vtx0.ID(), vtx0.Bytes()); err != nil {
+	if err := te.Gossip(); err != nil {
 		t.Fatal(err)
 	}
 
-	prefs := te.Consensus.Preferences()
-	if !prefs.Contains(vtx1.ID()) {
-		t.Fatalf("Shouldn't have abandoned the pending vertex")
+	if !*called {
+		t.Fatalf("Should have gossiped the accepted txs")
 	}
 }
 
-func TestEnginePushQueryRequestIDConflict(t *testing.T) {
+func TestEngineGossipTxsFetchesUnknownVertex(t *testing.T) {
 	config := DefaultConfig()
 
+	vdr := ids.GenerateTestShortID()
 	vals := validators.NewSet()
 	config.Validators = vals
-
-	vdr := ids.GenerateTestShortID()
 	if err := vals.AddWeight(vdr, 1); err != nil {
 		t.Fatal(err)
 	}
@@ -3435,150 +3420,404 @@ func TestEnginePushQueryRequestIDConflict(t *testing.T) {
 	sender.T = t
 	config.Sender = sender
 
+	sender.Default(true)
+
 	manager := vertex.NewTestManager(t)
 	config.Manager = manager
 
-	gVtx := &avalanche.TestVertex{
-		TestDecidable: choices.TestDecidable{
-			IDV:     ids.GenerateTestID(),
-			StatusV: choices.Accepted,
-		},
-		BytesV: []byte{0},
-	}
-
-	vts := []avalanche.Vertex{gVtx}
-	utxos := []ids.ID{ids.GenerateTestID(), ids.GenerateTestID()}
+	vm := &vertex.TestVM{}
+	vm.T = t
+	config.VM = vm
 
-	tx0 := &snowstorm.TestTx{TestDecidable: choices.TestDecidable{
+	gVtx := &avalanche.TestVertex{TestDecidable: choices.TestDecidable{
 		IDV:     ids.GenerateTestID(),
-		StatusV: choices.Processing,
+		StatusV: choices.Accepted,
 	}}
-	tx0.InputIDsV = append(tx0.InputIDsV, utxos[0])
 
-	tx1 := &snowstorm.TestTx{TestDecidable: choices.TestDecidable{
+	te := &Transitive{}
+	if err := te.Initialize(config); err != nil {
+		t.Fatal(err)
+	}
+
+	knownTx := &snowstorm.TestTx{TestDecidable: choices.TestDecidable{
 		IDV:     ids.GenerateTestID(),
-		StatusV: choices.Processing,
+		StatusV: choices.Accepted,
 	}}
-	tx1.InputIDsV = append(tx1.InputIDsV, utxos[1])
+	unknownTxID := ids.GenerateTestID()
+	vtxID := ids.GenerateTestID()
 
-	vtx0 := &avalanche.TestVertex{
-		TestDecidable: choices.TestDecidable{
-			IDV:     ids.GenerateTestID(),
-			StatusV: choices.Unknown,
-		},
-		ParentsV: vts,
-		HeightV:  1,
-		TxsV:     []snowstorm.Tx{tx0},
-		BytesV:   []byte{1},
+	manager.GetF = func(id ids.ID) (avalanche.Vertex, error) {
+		if id == gVtx.ID() {
+			return gVtx, nil
+		}
+		return nil, errUnknownVertex
 	}
-
-	vtx1 := &avalanche.TestVertex{
-		TestDecidable: choices.TestDecidable{
-			IDV:     ids.GenerateTestID(),
-			StatusV: choices.Processing,
-		},
-		ParentsV: []avalanche.Vertex{vtx0},
-		HeightV:  2,
-		TxsV:     []snowstorm.Tx{tx1},
-		BytesV:   []byte{2},
+	vm.GetF = func(txID ids.ID) (snowstorm.Tx, error) {
+		if txID == knownTx.ID() {
+			return knownTx, nil
+		}
+		return nil, errUnknownVertex
 	}
 
-	randomVtxID := ids.GenerateTestID()
-
-	te := &Transitive{}
-	if err := te.Initialize(config); err != nil {
+	// All of the txs are known, so the vertex shouldn't be requested
+	if err := te.GossipTxs(vdr, vtxID, []ids.ID{knownTx.ID()}); err != nil {
 		t.Fatal(err)
 	}
 
-	parsed := new(bool)
-	manager.ParseF = func(b []byte) (avalanche.Vertex, error) {
-		if bytes.Equal(b, vtx1.Bytes()) {
-			*parsed = true
-			return vtx1, nil
+	reqVtxID := new(ids.ID)
+	sender.GetF = func(inVdr ids.ShortID, _ uint32, id ids.ID) {
+		if inVdr != vdr {
+			t.Fatalf("Requested the vertex from the wrong validator")
 		}
-		return nil, errUnknownVertex
+		*reqVtxID = id
 	}
 
-	manager.GetF = func(vtxID ids.ID) (avalanche.Vertex, error) {
-		if !*parsed {
-			return nil, errUnknownVertex
-		}
+	if err := te.GossipTxs(vdr, vtxID, []ids.ID{knownTx.ID(), unknownTxID}); err != nil {
+		t.Fatal(err)
+	}
 
-		if vtxID == vtx1.ID() {
-			return vtx1, nil
-		}
-		return nil, errUnknownVertex
+	if *reqVtxID != vtxID {
+		t.Fatalf("Should have requested the vertex containing the unknown tx")
 	}
+}
 
-	reqID := new(uint32)
-	sender.GetF = func(reqVdr ids.ShortID, requestID uint32, vtxID ids.ID) {
-		*reqID = requestID
-		if reqVdr != vdr {
-			t.Fatalf("Wrong validator requested")
+func TestEngineGossipTxsPrefersHighFees(t *testing.T) {
+	config := DefaultConfig()
+
+	sender := &common.SenderTest{}
+	sender.T = t
+	config.Sender = sender
+
+	sender.Default(true)
+
+	te := &Transitive{}
+	if err := te.Initialize(config); err != nil {
+		t.Fatal(err)
+	}
+
+	txs := make([]snowstorm.Tx, 2*maxGossipTxs)
+	for i := range txs {
+		txs[i] = &testFeeTx{
+			TestTx: &snowstorm.TestTx{TestDecidable: choices.TestDecidable{
+				IDV:     ids.GenerateTestID(),
+				StatusV: choices.Processing,
+			}},
+			fee: uint64(i),
 		}
-		if vtxID != vtx0.ID() {
-			t.Fatalf("Wrong vertex requested")
+	}
+	vtx := &avalanche.TestVertex{
+		TestDecidable: choices.TestDecidable{
+			IDV:     ids.GenerateTestID(),
+			StatusV: choices.Processing,
+		},
+		TxsV: txs,
+	}
+
+	gossiped := []ids.ID(nil)
+	sender.GossipTxsF = func(_ ids.ID, txIDs []ids.ID) { gossiped = txIDs }
+	te.gossipTxs(vtx)
+
+	if len(gossiped) != maxGossipTxs {
+		t.Fatalf("Should have gossiped %d txs, gossiped %d", maxGossipTxs, len(gossiped))
+	}
+	for i, txID := range gossiped {
+		if expected := txs[len(txs)-1-i].ID(); txID != expected {
+			t.Fatalf("Should have gossiped the txs with the highest fees first")
 		}
 	}
+}
 
-	if err := te.PushQuery(vdr, 0, vtx1.ID(), vtx1.Bytes()); err != nil {
+func TestEngineGossipTxsLimitsFetches(t *testing.T) {
+	config := DefaultConfig()
+
+	vdr := ids.GenerateTestShortID()
+	vals := validators.NewSet()
+	config.Validators = vals
+	if err := vals.AddWeight(vdr, 1); err != nil {
 		t.Fatal(err)
 	}
 
-	sender.GetF = nil
-	sender.CantGet = false
+	sender := &common.SenderTest{}
+	sender.T = t
+	config.Sender = sender
 
-	if err := te.PushQuery(vdr, *reqID, randomVtxID, []byte{3}); err != nil {
+	sender.Default(true)
+
+	manager := vertex.NewTestManager(t)
+	config.Manager = manager
+
+	vm := &vertex.TestVM{}
+	vm.T = t
+	config.VM = vm
+
+	te := &Transitive{}
+	if err := te.Initialize(config); err != nil {
 		t.Fatal(err)
 	}
 
-	*parsed = false
-	manager.ParseF = func(b []byte) (avalanche.Vertex, error) {
-		if bytes.Equal(b, vtx0.Bytes()) {
-			*parsed = true
-			return vtx0, nil
-		}
-		return nil, errUnknownVertex
+	manager.GetF = func(ids.ID) (avalanche.Vertex, error) { return nil, errUnknownVertex }
+	vm.GetF = func(ids.ID) (snowstorm.Tx, error) { return nil, errUnknownVertex }
+
+	requested := ids.Set{}
+	sender.GetF = func(_ ids.ShortID, _ uint32, vtxID ids.ID) { requested.Add(vtxID) }
+
+	// Gossip from non-validators is dropped
+	if err := te.GossipTxs(ids.GenerateTestShortID(), ids.GenerateTestID(), []ids.ID{ids.GenerateTestID()}); err != nil {
+		t.Fatal(err)
+	}
+	if requested.Len() != 0 {
+		t.Fatalf("Shouldn't have requested a vertex gossiped by a non-validator")
 	}
 
-	manager.GetF = func(vtxID ids.ID) (avalanche.Vertex, error) {
-		if !*parsed {
-			return nil, errUnknownVertex
+	// Gossip listing too many txs is dropped
+	txIDs := make([]ids.ID, maxGossipTxs+1)
+	for i := range txIDs {
+		txIDs[i] = ids.GenerateTestID()
+	}
+	if err := te.GossipTxs(vdr, ids.GenerateTestID(), txIDs); err != nil {
+		t.Fatal(err)
+	}
+	if requested.Len() != 0 {
+		t.Fatalf("Shouldn't have requested a vertex from oversized gossip")
+	}
+
+	// Only a limited number of vertices are requested between gossip rounds
+	for i := 0; i < 2*maxGossipFetches; i++ {
+		if err := te.GossipTxs(vdr, ids.GenerateTestID(), []ids.ID{ids.GenerateTestID()}); err != nil {
+			t.Fatal(err)
 		}
+	}
+	if requested.Len() != maxGossipFetches {
+		t.Fatalf("Should have requested %d vertices, requested %d", maxGossipFetches, requested.Len())
+	}
+}
 
-		if vtxID == vtx0.ID() {
-			return vtx0, nil
+func TestEngineMaxQueuedGets(t *testing.T) {
+	config := DefaultConfig()
+	config.MaxOutstandingGets = 1
+
+	vdr := ids.GenerateTestShortID()
+
+	sender := &common.SenderTest{}
+	sender.T = t
+	config.Sender = sender
+
+	sender.Default(true)
+	sender.CantGet = false
+
+	te := &Transitive{}
+	if err := te.Initialize(config); err != nil {
+		t.Fatal(err)
+	}
+
+	// The first request is sent, and the rest are queued
+	te.sendRequest(vdr, ids.GenerateTestID())
+	queued := make([]ids.ID, defaultMaxQueuedVtxRequests+1)
+	for i := range queued {
+		queued[i] = ids.GenerateTestID()
+		te.sendRequest(vdr, queued[i])
+	}
+
+	if numQueued := len(te.queuedVtxs); numQueued != defaultMaxQueuedVtxRequests {
+		t.Fatalf("Should have queued %d requests, queued %d", defaultMaxQueuedVtxRequests, numQueued)
+	}
+	if _, ok := te.queuedVtxs[queued[0]]; ok {
+		t.Fatalf("Should have dropped the oldest queued request")
+	}
+	if _, ok := te.queuedVtxs[queued[len(queued)-1]]; !ok {
+		t.Fatalf("Should have queued the newest request")
+	}
+}
+
+func TestEngineMaxOutstandingGets(t *testing.T) {
+	config := DefaultConfig()
+	config.MaxOutstandingGets = 1
+
+	vdr := ids.GenerateTestShortID()
+
+	sender := &common.SenderTest{}
+	sender.T = t
+	config.Sender = sender
+
+	sender.Default(true)
+
+	manager := vertex.NewTestManager(t)
+	config.Manager = manager
+
+	te := &Transitive{}
+	if err := te.Initialize(config); err != nil {
+		t.Fatal(err)
+	}
+
+	requested := []ids.ID(nil)
+	reqIDs := []uint32(nil)
+	sender.GetF = func(inVdr ids.ShortID, reqID uint32, vtxID ids.ID) {
+		if inVdr != vdr {
+			t.Fatalf("Requested the vertex from the wrong validator")
 		}
-		return nil, errUnknownVertex
+		requested = append(requested, vtxID)
+		reqIDs = append(reqIDs, reqID)
 	}
-	sender.CantPushQuery = false
-	sender.CantChits = false
 
-	vtx0.StatusV = choices.Processing
+	vtxID0 := ids.GenerateTestID()
+	vtxID1 := ids.GenerateTestID()
+	te.sendRequest(vdr, vtxID0)
+	te.sendRequest(vdr, vtxID1)
+	te.sendRequest(vdr, vtxID1)
 
-	if err := te.Put(vdr, *reqID, vtx0.ID(), vtx0.Bytes()); err != nil {
+	if len(requested) != 1 || requested[0] != vtxID0 {
+		t.Fatalf("Should have only requested the first vertex")
+	}
+	if len(te.queuedVtxs) != 1 {
+		t.Fatalf("Should have queued the second vertex once")
+	}
+
+	if err := te.GetFailed(vdr, reqIDs[0]); err != nil {
 		t.Fatal(err)
 	}
 
-	prefs := te.Consensus.Preferences()
-	if !prefs.Contains(vtx1.ID()) {
-		t.Fatalf("Shouldn't have abandoned the pending vertex")
+	if len(requested) != 2 || requested[1] != vtxID1 {
+		t.Fatalf("Should have requested the queued vertex once the outstanding request failed")
+	}
+	if len(te.queuedVtxs) != 0 || len(te.queuedVtxReqs) != 0 {
+		t.Fatalf("Should have emptied the request queue")
 	}
 }
 
-func TestEngineAggressivePolling(t *testing.T) {
+func TestEngineQueuedGetsEvictedLeastRecentlyRequested(t *testing.T) {
 	config := DefaultConfig()
+	config.MaxOutstandingGets = 1
+	config.MaxQueuedGets = 2
 
-	config.Params.ConcurrentRepolls = 3
-	config.Params.BetaRogue = 3
+	vdr0 := ids.GenerateTestShortID()
+	vdr1 := ids.GenerateTestShortID()
+
+	sender := &common.SenderTest{}
+	sender.T = t
+	config.Sender = sender
+
+	sender.Default(true)
+
+	manager := vertex.NewTestManager(t)
+	config.Manager = manager
+
+	te := &Transitive{}
+	if err := te.Initialize(config); err != nil {
+		t.Fatal(err)
+	}
+
+	requested := map[ids.ID]ids.ShortID{}
+	reqIDs := []uint32(nil)
+	sender.GetF = func(vdr ids.ShortID, reqID uint32, vtxID ids.ID) {
+		requested[vtxID] = vdr
+		reqIDs = append(reqIDs, reqID)
+	}
+
+	vtxID0 := ids.GenerateTestID()
+	vtxID1 := ids.GenerateTestID()
+	vtxID2 := ids.GenerateTestID()
+	vtxID3 := ids.GenerateTestID()
+	te.sendRequest(vdr0, vtxID0)
+	te.sendRequest(vdr0, vtxID1)
+	te.sendRequest(vdr0, vtxID2)
+	// Requesting [vtxID1] again makes [vtxID2] the least recently requested
+	te.sendRequest(vdr1, vtxID1)
+	te.sendRequest(vdr0, vtxID3)
+
+	if _, ok := te.queuedVtxs[vtxID2]; ok {
+		t.Fatalf("Should have dropped the least recently requested vertex")
+	}
+	if len(te.queuedVtxs) != 2 {
+		t.Fatalf("Should have queued 2 requests, queued %d", len(te.queuedVtxs))
+	}
+
+	if err := te.GetFailed(vdr0, reqIDs[0]); err != nil {
+		t.Fatal(err)
+	}
+	if vdr, ok := requested[vtxID1]; !ok || vdr != vdr1 {
+		t.Fatalf("Should have requested the refreshed vertex from the latest validator")
+	}
+	if _, ok := requested[vtxID3]; ok {
+		t.Fatalf("Should have requested the vertices in the order they were last requested")
+	}
+}
+
+func TestEngineMaxOutstandingGetsPerPeer(t *testing.T) {
+	config := DefaultConfig()
+	config.MaxOutstandingGetsPerPeer = 1
+
+	vdr0 := ids.GenerateTestShortID()
+	vdr1 := ids.GenerateTestShortID()
+
+	sender := &common.SenderTest{}
+	sender.T = t
+	config.Sender = sender
+
+	sender.Default(true)
+
+	manager := vertex.NewTestManager(t)
+	config.Manager = manager
+
+	te := &Transitive{}
+	if err := te.Initialize(config); err != nil {
+		t.Fatal(err)
+	}
+
+	requested := []ids.ID(nil)
+	reqIDs := []uint32(nil)
+	sender.GetF = func(_ ids.ShortID, reqID uint32, vtxID ids.ID) {
+		requested = append(requested, vtxID)
+		reqIDs = append(reqIDs, reqID)
+	}
+
+	vtxID0 := ids.GenerateTestID()
+	vtxID1 := ids.GenerateTestID()
+	vtxID2 := ids.GenerateTestID()
+	te.sendRequest(vdr0, vtxID0)
+	te.sendRequest(vdr0, vtxID1)
+	te.sendRequest(vdr1, vtxID2)
+
+	if len(requested) != 2 || requested[0] != vtxID0 || requested[1] != vtxID2 {
+		t.Fatalf("Should have only requested one vertex from each validator")
+	}
+	if len(te.queuedVtxs) != 1 {
+		t.Fatalf("Should have queued the request to the limited validator")
+	}
+
+	// A response from another validator doesn't free up [vdr0]
+	if err := te.GetFailed(vdr1, reqIDs[1]); err != nil {
+		t.Fatal(err)
+	}
+	if len(requested) != 2 || len(te.queuedVtxs) != 1 || len(te.queuedVtxReqs) != 1 {
+		t.Fatalf("Should have kept the request to the limited validator queued")
+	}
+
+	if err := te.GetFailed(vdr0, reqIDs[0]); err != nil {
+		t.Fatal(err)
+	}
+	if len(requested) != 3 || requested[2] != vtxID1 {
+		t.Fatalf("Should have requested the queued vertex once the validator's request failed")
+	}
+	if len(te.queuedVtxs) != 0 || len(te.queuedVtxReqs) != 0 {
+		t.Fatalf("Should have emptied the request queue")
+	}
+}
+
+func TestEngineInvalidVertexIgnoredFromUnexpectedPeer(t *testing.T) {
+	config := DefaultConfig()
 
 	vals := validators.NewSet()
 	config.Validators = vals
 
 	vdr := ids.GenerateTestShortID()
+	secondVdr := ids.GenerateTestShortID()
+
 	if err := vals.AddWeight(vdr, 1); err != nil {
 		t.Fatal(err)
 	}
+	if err := vals.AddWeight(secondVdr, 1); err != nil {
+		t.Fatal(err)
+	}
 
 	sender := &common.SenderTest{}
 	sender.T = t
@@ -3587,12 +3826,6 @@ func TestEngineAggressivePolling(t *testing.T) {
 	manager := vertex.NewTestManager(t)
 	config.Manager = manager
 
-	vm := &vertex.TestVM{}
-	vm.T = t
-	config.VM = vm
-
-	vm.Default(true)
-
 	gVtx := &avalanche.TestVertex{
 		TestDecidable: choices.TestDecidable{
 			IDV:     ids.GenerateTestID(),
@@ -3616,73 +3849,2637 @@ func TestEngineAggressivePolling(t *testing.T) {
 	}}
 	tx1.InputIDsV = append(tx1.InputIDsV, utxos[1])
 
-	vtx := &avalanche.TestVertex{
+	vtx0 := &avalanche.TestVertex{
 		TestDecidable: choices.TestDecidable{
 			IDV:     ids.GenerateTestID(),
-			StatusV: choices.Processing,
+			StatusV: choices.Unknown,
 		},
 		ParentsV: vts,
 		HeightV:  1,
 		TxsV:     []snowstorm.Tx{tx0},
 		BytesV:   []byte{1},
 	}
+	vtx1 := &avalanche.TestVertex{
+		TestDecidable: choices.TestDecidable{
+			IDV:     ids.GenerateTestID(),
+			StatusV: choices.Processing,
+		},
+		ParentsV: []avalanche.Vertex{vtx0},
+		HeightV:  2,
+		TxsV:     []snowstorm.Tx{tx1},
+		BytesV:   []byte{2},
+	}
 
-	vm.CantBootstrapping = false
-	vm.CantBootstrapped = false
+	te := &Transitive{}
+	if err := te.Initialize(config); err != nil {
+		t.Fatal(err)
+	}
+
+	parsed := new(bool)
+	manager.ParseF = func(b []byte) (avalanche.Vertex, error) {
+		if bytes.Equal(b, vtx1.Bytes()) {
+			*parsed = true
+			return vtx1, nil
+		}
+		return nil, errUnknownVertex
+	}
+
+	manager.GetF = func(vtxID ids.ID) (avalanche.Vertex, error) {
+		if !*parsed {
+			return nil, errUnknownVertex
+		}
+
+		if vtxID == vtx1.ID() {
+			return vtx1, nil
+		}
+		return nil, errUnknownVertex
+	}
+
+	reqID := new(uint32)
+	sender.GetF = func(reqVdr ids.ShortID, requestID uint32, vtxID ids.ID) {
+		*reqID = requestID
+		if reqVdr != vdr {
+			t.Fatalf("Wrong validator requested")
+		}
+		if vtxID != vtx0.ID() {
+			t.Fatalf("Wrong vertex requested")
+		}
+	}
+
+	if err := te.PushQuery(vdr, 0, vtx1.ID(), vtx1.Bytes()); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := te.Put(secondVdr, *reqID, vtx0.ID(), []byte{3}); err != nil {
+		t.Fatal(err)
+	}
+
+	*parsed = false
+	manager.ParseF = func(b []byte) (avalanche.Vertex, error) {
+		if bytes.Equal(b, vtx0.Bytes()) {
+			*parsed = true
+			return vtx0, nil
+		}
+		return nil, errUnknownVertex
+	}
+
+	manager.GetF = func(vtxID ids.ID) (avalanche.Vertex, error) {
+		if !*parsed {
+			return nil, errUnknownVertex
+		}
+
+		if vtxID == vtx0.ID() {
+			return vtx0, nil
+		}
+		return nil, errUnknownVertex
+	}
+	sender.CantPushQuery = false
+	sender.CantChits = false
+
+	vtx0.StatusV = choices.Processing
+
+	if err := te.Put(vdr, *reqID, vtx0.ID(), vtx0.Bytes()); err != nil {
+		t.Fatal(err)
+	}
+
+	prefs := te.Consensus.Preferences()
+	if !prefs.Contains(vtx1.ID()) {
+		t.Fatalf("Shouldn't have abandoned the pending vertex")
+	}
+}
+
+func TestEnginePushQueryRequestIDConflict(t *testing.T) {
+	config := DefaultConfig()
+
+	vals := validators.NewSet()
+	config.Validators = vals
+
+	vdr := ids.GenerateTestShortID()
+	if err := vals.AddWeight(vdr, 1); err != nil {
+		t.Fatal(err)
+	}
+
+	sender := &common.SenderTest{}
+	sender.T = t
+	config.Sender = sender
+
+	manager := vertex.NewTestManager(t)
+	config.Manager = manager
+
+	gVtx := &avalanche.TestVertex{
+		TestDecidable: choices.TestDecidable{
+			IDV:     ids.GenerateTestID(),
+			StatusV: choices.Accepted,
+		},
+		BytesV: []byte{0},
+	}
+
+	vts := []avalanche.Vertex{gVtx}
+	utxos := []ids.ID{ids.GenerateTestID(), ids.GenerateTestID()}
+
+	tx0 := &snowstorm.TestTx{TestDecidable: choices.TestDecidable{
+		IDV:     ids.GenerateTestID(),
+		StatusV: choices.Processing,
+	}}
+	tx0.InputIDsV = append(tx0.InputIDsV, utxos[0])
+
+	tx1 := &snowstorm.TestTx{TestDecidable: choices.TestDecidable{
+		IDV:     ids.GenerateTestID(),
+		StatusV: choices.Processing,
+	}}
+	tx1.InputIDsV = append(tx1.InputIDsV, utxos[1])
+
+	vtx0 := &avalanche.TestVertex{
+		TestDecidable: choices.TestDecidable{
+			IDV:     ids.GenerateTestID(),
+			StatusV: choices.Unknown,
+		},
+		ParentsV: vts,
+		HeightV:  1,
+		TxsV:     []snowstorm.Tx{tx0},
+		BytesV:   []byte{1},
+	}
+
+	vtx1 := &avalanche.TestVertex{
+		TestDecidable: choices.TestDecidable{
+			IDV:     ids.GenerateTestID(),
+			StatusV: choices.Processing,
+		},
+		ParentsV: []avalanche.Vertex{vtx0},
+		HeightV:  2,
+		TxsV:     []snowstorm.Tx{tx1},
+		BytesV:   []byte{2},
+	}
+
+	randomVtxID := ids.GenerateTestID()
+
+	te := &Transitive{}
+	if err := te.Initialize(config); err != nil {
+		t.Fatal(err)
+	}
+
+	parsed := new(bool)
+	manager.ParseF = func(b []byte) (avalanche.Vertex, error) {
+		if bytes.Equal(b, vtx1.Bytes()) {
+			*parsed = true
+			return vtx1, nil
+		}
+		return nil, errUnknownVertex
+	}
+
+	manager.GetF = func(vtxID ids.ID) (avalanche.Vertex, error) {
+		if !*parsed {
+			return nil, errUnknownVertex
+		}
+
+		if vtxID == vtx1.ID() {
+			return vtx1, nil
+		}
+		return nil, errUnknownVertex
+	}
+
+	reqID := new(uint32)
+	sender.GetF = func(reqVdr ids.ShortID, requestID uint32, vtxID ids.ID) {
+		*reqID = requestID
+		if reqVdr != vdr {
+			t.Fatalf("Wrong validator requested")
+		}
+		if vtxID != vtx0.ID() {
+			t.Fatalf("Wrong vertex requested")
+		}
+	}
+
+	if err := te.PushQuery(vdr, 0, vtx1.ID(), vtx1.Bytes()); err != nil {
+		t.Fatal(err)
+	}
+
+	sender.GetF = nil
+	sender.CantGet = false
+
+	if err := te.PushQuery(vdr, *reqID, randomVtxID, []byte{3}); err != nil {
+		t.Fatal(err)
+	}
+
+	*parsed = false
+	manager.ParseF = func(b []byte) (avalanche.Vertex, error) {
+		if bytes.Equal(b, vtx0.Bytes()) {
+			*parsed = true
+			return vtx0, nil
+		}
+		return nil, errUnknownVertex
+	}
+
+	manager.GetF = func(vtxID ids.ID) (avalanche.Vertex, error) {
+		if !*parsed {
+			return nil, errUnknownVertex
+		}
+
+		if vtxID == vtx0.ID() {
+			return vtx0, nil
+		}
+		return nil, errUnknownVertex
+	}
+	sender.CantPushQuery = false
+	sender.CantChits = false
+
+	vtx0.StatusV = choices.Processing
+
+	if err := te.Put(vdr, *reqID, vtx0.ID(), vtx0.Bytes()); err != nil {
+		t.Fatal(err)
+	}
+
+	prefs := te.Consensus.Preferences()
+	if !prefs.Contains(vtx1.ID()) {
+		t.Fatalf("Shouldn't have abandoned the pending vertex")
+	}
+}
+
+func TestEngineAggressivePolling(t *testing.T) {
+	config := DefaultConfig()
+
+	config.Params.ConcurrentRepolls = 3
+	config.Params.BetaRogue = 3
+
+	vals := validators.NewSet()
+	config.Validators = vals
+
+	vdr := ids.GenerateTestShortID()
+	if err := vals.AddWeight(vdr, 1); err != nil {
+		t.Fatal(err)
+	}
+
+	sender := &common.SenderTest{}
+	sender.T = t
+	config.Sender = sender
+
+	manager := vertex.NewTestManager(t)
+	config.Manager = manager
+
+	vm := &vertex.TestVM{}
+	vm.T = t
+	config.VM = vm
+
+	vm.Default(true)
+
+	gVtx := &avalanche.TestVertex{
+		TestDecidable: choices.TestDecidable{
+			IDV:     ids.GenerateTestID(),
+			StatusV: choices.Accepted,
+		},
+		BytesV: []byte{0},
+	}
+
+	vts := []avalanche.Vertex{gVtx}
+	utxos := []ids.ID{ids.GenerateTestID(), ids.GenerateTestID()}
+
+	tx0 := &snowstorm.TestTx{TestDecidable: choices.TestDecidable{
+		IDV:     ids.GenerateTestID(),
+		StatusV: choices.Processing,
+	}}
+	tx0.InputIDsV = append(tx0.InputIDsV, utxos[0])
+
+	tx1 := &snowstorm.TestTx{TestDecidable: choices.TestDecidable{
+		IDV:     ids.GenerateTestID(),
+		StatusV: choices.Processing,
+	}}
+	tx1.InputIDsV = append(tx1.InputIDsV, utxos[1])
+
+	vtx := &avalanche.TestVertex{
+		TestDecidable: choices.TestDecidable{
+			IDV:     ids.GenerateTestID(),
+			StatusV: choices.Processing,
+		},
+		ParentsV: vts,
+		HeightV:  1,
+		TxsV:     []snowstorm.Tx{tx0},
+		BytesV:   []byte{1},
+	}
+
+	vm.CantBootstrapping = false
+	vm.CantBootstrapped = false
+
+	te := &Transitive{}
+	if err := te.Initialize(config); err != nil {
+		t.Fatal(err)
+	}
+
+	vm.CantBootstrapping = true
+	vm.CantBootstrapped = true
+
+	parsed := new(bool)
+	manager.ParseF = func(b []byte) (avalanche.Vertex, error) {
+		if bytes.Equal(b, vtx.Bytes()) {
+			*parsed = true
+			return vtx, nil
+		}
+		return nil, errUnknownVertex
+	}
+
+	manager.GetF = func(vtxID ids.ID) (avalanche.Vertex, error) {
+		if !*parsed {
+			return nil, errUnknownVertex
+		}
+
+		if vtxID == vtx.ID() {
+			return vtx, nil
+		}
+		return nil, errUnknownVertex
+	}
+
+	numPushQueries := new(int)
+	sender.PushQueryF = func(ids.ShortSet, uint32, ids.ID, []byte) { *numPushQueries++ }
+
+	numPullQueries := new(int)
+	sender.PullQueryF = func(ids.ShortSet, uint32, ids.ID) { *numPullQueries++ }
+
+	vm.CantPending = false
+
+	if err := te.Put(vdr, 0, vtx.ID(), vtx.Bytes()); err != nil {
+		t.Fatal(err)
+	}
+
+	if *numPushQueries != 1 {
+		t.Fatalf("should have issued one push query")
+	}
+	if *numPullQueries != 2 {
+		t.Fatalf("should have issued two pull queries")
+	}
+}
+
+func TestEngineDuplicatedIssuance(t *testing.T) {
+	config := DefaultConfig()
+	config.Params.BatchSize = 1
+	config.Params.BetaVirtuous = 5
+	config.Params.BetaRogue = 5
+
+	sender := &common.SenderTest{}
+	sender.T = t
+	config.Sender = sender
+
+	sender.Default(true)
+	sender.CantGetAcceptedFrontier = false
+
+	vals := validators.NewSet()
+	config.Validators = vals
+
+	vdr := ids.GenerateTestShortID()
+	if err := vals.AddWeight(vdr, 1); err != nil {
+		t.Fatal(err)
+	}
+
+	manager := vertex.NewTestManager(t)
+	config.Manager = manager
+
+	manager.Default(true)
+
+	vm := &vertex.TestVM{}
+	vm.T = t
+	config.VM = vm
+
+	vm.Default(true)
+
+	gVtx := &avalanche.TestVertex{TestDecidable: choices.TestDecidable{
+		IDV:     ids.GenerateTestID(),
+		StatusV: choices.Accepted,
+	}}
+	mVtx := &avalanche.TestVertex{TestDecidable: choices.TestDecidable{
+		IDV:     ids.GenerateTestID(),
+		StatusV: choices.Accepted,
+	}}
+
+	gTx := &snowstorm.TestTx{TestDecidable: choices.TestDecidable{
+		IDV:     ids.GenerateTestID(),
+		StatusV: choices.Accepted,
+	}}
+
+	utxos := []ids.ID{ids.GenerateTestID(), ids.GenerateTestID()}
+
+	tx := &snowstorm.TestTx{
+		TestDecidable: choices.TestDecidable{
+			IDV:     ids.GenerateTestID(),
+			StatusV: choices.Processing,
+		},
+		DependenciesV: []snowstorm.Tx{gTx},
+	}
+	tx.InputIDsV = append(tx.InputIDsV, utxos[0])
+
+	manager.EdgeF = func() []ids.ID { return []ids.ID{gVtx.ID(), mVtx.ID()} }
+	manager.GetF = func(id ids.ID) (avalanche.Vertex, error) {
+		switch id {
+		case gVtx.ID():
+			return gVtx, nil
+		case mVtx.ID():
+			return mVtx, nil
+		}
+		t.Fatalf("Unknown vertex")
+		panic("Should have errored")
+	}
+
+	vm.CantBootstrapping = false
+	vm.CantBootstrapped = false
+
+	te := &Transitive{}
+	if err := te.Initialize(config); err != nil {
+		t.Fatal(err)
+	}
+
+	vm.CantBootstrapping = true
+	vm.CantBootstrapped = true
+
+	lastVtx := new(avalanche.TestVertex)
+	manager.BuildF = func(_ uint32, _ []ids.ID, txs []snowstorm.Tx, _ []ids.ID) (avalanche.Vertex, error) {
+		lastVtx = &avalanche.TestVertex{
+			TestDecidable: choices.TestDecidable{
+				IDV:     ids.GenerateTestID(),
+				StatusV: choices.Processing,
+			},
+			ParentsV: []avalanche.Vertex{gVtx, mVtx},
+			HeightV:  1,
+			TxsV:     txs,
+			BytesV:   []byte{1},
+		}
+		return lastVtx, nil
+	}
+
+	sender.CantPushQuery = false
+
+	vm.PendingF = func() []snowstorm.Tx { return []snowstorm.Tx{tx} }
+	if err := te.Notify(common.PendingTxs); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(lastVtx.TxsV) != 1 || lastVtx.TxsV[0].ID() != tx.ID() {
+		t.Fatalf("Should have issued txs differently")
+	}
+
+	manager.BuildF = func(uint32, []ids.ID, []snowstorm.Tx, []ids.ID) (avalanche.Vertex, error) {
+		t.Fatalf("shouldn't have attempted to issue a duplicated tx")
+		return nil, nil
+	}
+
+	if err := te.Notify(common.PendingTxs); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestEngineDoubleChit(t *testing.T) {
+	config := DefaultConfig()
+
+	config.Params.Alpha = 2
+	config.Params.K = 2
+
+	vals := validators.NewSet()
+	config.Validators = vals
+
+	vdr0 := ids.GenerateTestShortID()
+	vdr1 := ids.GenerateTestShortID()
+
+	if err := vals.AddWeight(vdr0, 1); err != nil {
+		t.Fatal(err)
+	}
+	if err := vals.AddWeight(vdr1, 1); err != nil {
+		t.Fatal(err)
+	}
+
+	sender := &common.SenderTest{}
+	sender.T = t
+	config.Sender = sender
+
+	sender.Default(true)
+	sender.CantGetAcceptedFrontier = false
+
+	manager := vertex.NewTestManager(t)
+	config.Manager = manager
+
+	manager.Default(true)
+
+	gVtx := &avalanche.TestVertex{TestDecidable: choices.TestDecidable{
+		IDV:     ids.GenerateTestID(),
+		StatusV: choices.Accepted,
+	}}
+	mVtx := &avalanche.TestVertex{TestDecidable: choices.TestDecidable{
+		IDV:     ids.GenerateTestID(),
+		StatusV: choices.Accepted,
+	}}
+
+	vts := []avalanche.Vertex{gVtx, mVtx}
+	utxos := []ids.ID{ids.GenerateTestID()}
+
+	tx := &snowstorm.TestTx{TestDecidable: choices.TestDecidable{
+		IDV:     ids.GenerateTestID(),
+		StatusV: choices.Processing,
+	}}
+	tx.InputIDsV = append(tx.InputIDsV, utxos[0])
+
+	vtx := &avalanche.TestVertex{
+		TestDecidable: choices.TestDecidable{
+			IDV:     ids.GenerateTestID(),
+			StatusV: choices.Processing,
+		},
+		ParentsV: vts,
+		HeightV:  1,
+		TxsV:     []snowstorm.Tx{tx},
+		BytesV:   []byte{1, 1, 2, 3},
+	}
+
+	manager.EdgeF = func() []ids.ID { return []ids.ID{vts[0].ID(), vts[1].ID()} }
+	manager.GetF = func(id ids.ID) (avalanche.Vertex, error) {
+		switch id {
+		case gVtx.ID():
+			return gVtx, nil
+		case mVtx.ID():
+			return mVtx, nil
+		}
+		t.Fatalf("Unknown vertex")
+		panic("Should have errored")
+	}
+
+	te := &Transitive{}
+	if err := te.Initialize(config); err != nil {
+		t.Fatal(err)
+	}
+
+	reqID := new(uint32)
+	sender.PushQueryF = func(inVdrs ids.ShortSet, requestID uint32, vtxID ids.ID, _ []byte) {
+		*reqID = requestID
+		if inVdrs.Len() != 2 {
+			t.Fatalf("Wrong number of validators")
+		}
+		if vtxID != vtx.ID() {
+			t.Fatalf("Wrong vertex requested")
+		}
+	}
+	manager.GetF = func(id ids.ID) (avalanche.Vertex, error) {
+		if id == vtx.ID() {
+			return vtx, nil
+		}
+		t.Fatalf("Unknown vertex")
+		panic("Should have errored")
+	}
+
+	if err := te.issue(vtx); err != nil {
+		t.Fatal(err)
+	}
+
+	votes := []ids.ID{vtx.ID()}
+
+	if status := tx.Status(); status != choices.Processing {
+		t.Fatalf("Wrong tx status: %s ; expected: %s", status, choices.Processing)
+	}
+
+	if err := te.Chits(vdr0, *reqID, votes); err != nil {
+		t.Fatal(err)
+	}
+
+	if status := tx.Status(); status != choices.Processing {
+		t.Fatalf("Wrong tx status: %s ; expected: %s", status, choices.Processing)
+	}
+
+	if err := te.Chits(vdr0, *reqID, votes); err != nil {
+		t.Fatal(err)
+	}
+
+	if status := tx.Status(); status != choices.Processing {
+		t.Fatalf("Wrong tx status: %s ; expected: %s", status, choices.Processing)
+	}
+
+	if err := te.Chits(vdr1, *reqID, votes); err != nil {
+		t.Fatal(err)
+	}
+
+	if status := tx.Status(); status != choices.Accepted {
+		t.Fatalf("Wrong tx status: %s ; expected: %s", status, choices.Accepted)
+	}
+}
+
+func TestEngineBubbleVotes(t *testing.T) {
+	config := DefaultConfig()
+
+	vals := validators.NewSet()
+	config.Validators = vals
+
+	vdr := ids.GenerateTestShortID()
+	err := vals.AddWeight(vdr, 1)
+	assert.NoError(t, err)
+
+	sender := &common.SenderTest{}
+	sender.T = t
+	config.Sender = sender
+
+	sender.Default(true)
+	sender.CantGetAcceptedFrontier = false
+
+	manager := vertex.NewTestManager(t)
+	config.Manager = manager
+
+	manager.Default(true)
+
+	utxos := []ids.ID{
+		ids.GenerateTestID(),
+		ids.GenerateTestID(),
+		ids.GenerateTestID(),
+	}
+
+	tx0 := &snowstorm.TestTx{
+		TestDecidable: choices.TestDecidable{
+			IDV:     ids.GenerateTestID(),
+			StatusV: choices.Processing,
+		},
+		InputIDsV: utxos[:1],
+	}
+	tx1 := &snowstorm.TestTx{
+		TestDecidable: choices.TestDecidable{
+			IDV:     ids.GenerateTestID(),
+			StatusV: choices.Processing,
+		},
+		InputIDsV: utxos[1:2],
+	}
+	tx2 := &snowstorm.TestTx{
+		TestDecidable: choices.TestDecidable{
+			IDV:     ids.GenerateTestID(),
+			StatusV: choices.Processing,
+		},
+		InputIDsV: utxos[1:2],
+	}
+
+	vtx := &avalanche.TestVertex{
+		TestDecidable: choices.TestDecidable{
+			IDV:     ids.GenerateTestID(),
+			StatusV: choices.Processing,
+		},
+		HeightV: 0,
+		TxsV:    []snowstorm.Tx{tx0},
+		BytesV:  []byte{0},
+	}
+
+	missingVtx := &avalanche.TestVertex{TestDecidable: choices.TestDecidable{
+		IDV:     ids.GenerateTestID(),
+		StatusV: choices.Unknown,
+	}}
+
+	pendingVtx0 := &avalanche.TestVertex{
+		TestDecidable: choices.TestDecidable{
+			IDV:     ids.GenerateTestID(),
+			StatusV: choices.Processing,
+		},
+		ParentsV: []avalanche.Vertex{vtx, missingVtx},
+		HeightV:  1,
+		TxsV:     []snowstorm.Tx{tx1},
+		BytesV:   []byte{1},
+	}
+
+	pendingVtx1 := &avalanche.TestVertex{
+		TestDecidable: choices.TestDecidable{
+			IDV:     ids.GenerateTestID(),
+			StatusV: choices.Processing,
+		},
+		ParentsV: []avalanche.Vertex{pendingVtx0},
+		HeightV:  2,
+		TxsV:     []snowstorm.Tx{tx2},
+		BytesV:   []byte{2},
+	}
+
+	manager.EdgeF = func() []ids.ID { return nil }
+	manager.GetF = func(id ids.ID) (avalanche.Vertex, error) {
+		switch id {
+		case vtx.ID():
+			return vtx, nil
+		case missingVtx.ID():
+			return nil, errMissing
+		case pendingVtx0.ID():
+			return pendingVtx0, nil
+		case pendingVtx1.ID():
+			return pendingVtx1, nil
+		}
+		assert.FailNow(t, "unknown vertex", "vtxID: %s", id)
+		panic("should have errored")
+	}
+
+	te := &Transitive{}
+	err = te.Initialize(config)
+	assert.NoError(t, err)
+
+	queryReqID := new(uint32)
+	queried := new(bool)
+	sender.PushQueryF = func(inVdrs ids.ShortSet, requestID uint32, vtxID ids.ID, _ []byte) {
+		assert.Len(t, inVdrs, 1, "wrong number of validators")
+		*queryReqID = requestID
+		assert.Equal(t, vtx.ID(), vtxID, "wrong vertex requested")
+		*queried = true
+	}
+
+	getReqID := new(uint32)
+	fetched := new(bool)
+	sender.GetF = func(inVdr ids.ShortID, requestID uint32, vtxID ids.ID) {
+		assert.Equal(t, vdr, inVdr, "wrong validator")
+		*getReqID = requestID
+		assert.Equal(t, missingVtx.ID(), vtxID, "wrong vertex requested")
+		*fetched = true
+	}
+
+	issued, err := te.issueFrom(vdr, pendingVtx1)
+	assert.NoError(t, err)
+	assert.False(t, issued, "shouldn't have been able to issue %s", pendingVtx1.ID())
+	assert.True(t, *queried, "should have queried for %s", vtx.ID())
+	assert.True(t, *fetched, "should have fetched %s", missingVtx.ID())
+
+	// can't apply votes yet because pendingVtx0 isn't issued because missingVtx
+	// is missing
+	err = te.Chits(vdr, *queryReqID, []ids.ID{pendingVtx1.ID()})
+	assert.NoError(t, err)
+	assert.Equal(t, choices.Processing, tx0.Status(), "wrong tx status")
+	assert.Equal(t, choices.Processing, tx1.Status(), "wrong tx status")
+
+	// vote for pendingVtx1 should be bubbled up to pendingVtx0 and then to vtx
+	err = te.GetFailed(vdr, *getReqID)
+	assert.NoError(t, err)
+	assert.Equal(t, choices.Accepted, tx0.Status(), "wrong tx status")
+	assert.Equal(t, choices.Processing, tx1.Status(), "wrong tx status")
+}
+
+func TestEngineIssue(t *testing.T) {
+	config := DefaultConfig()
+	config.Params.BatchSize = 1
+	config.Params.BetaVirtuous = 1
+	config.Params.BetaRogue = 1
+	config.Params.OptimalProcessing = 1
+
+	sender := &common.SenderTest{}
+	sender.T = t
+	config.Sender = sender
+
+	sender.Default(true)
+	sender.CantGetAcceptedFrontier = false
+
+	vals := validators.NewSet()
+	config.Validators = vals
+
+	vdr := ids.GenerateTestShortID()
+	if err := vals.AddWeight(vdr, 1); err != nil {
+		t.Fatal(err)
+	}
+
+	manager := vertex.NewTestManager(t)
+	config.Manager = manager
+
+	manager.Default(true)
+
+	vm := &vertex.TestVM{}
+	vm.T = t
+	config.VM = vm
+
+	vm.Default(true)
+
+	gVtx := &avalanche.TestVertex{TestDecidable: choices.TestDecidable{
+		IDV:     ids.GenerateTestID(),
+		StatusV: choices.Accepted,
+	}}
+	mVtx := &avalanche.TestVertex{TestDecidable: choices.TestDecidable{
+		IDV:     ids.GenerateTestID(),
+		StatusV: choices.Accepted,
+	}}
+
+	gTx := &snowstorm.TestTx{TestDecidable: choices.TestDecidable{
+		IDV:     ids.GenerateTestID(),
+		StatusV: choices.Accepted,
+	}}
+
+	utxos := []ids.ID{ids.GenerateTestID(), ids.GenerateTestID()}
+
+	tx0 := &snowstorm.TestTx{
+		TestDecidable: choices.TestDecidable{
+			IDV:     ids.GenerateTestID(),
+			StatusV: choices.Processing,
+		},
+		DependenciesV: []snowstorm.Tx{gTx},
+		InputIDsV:     utxos[:1],
+	}
+	tx1 := &snowstorm.TestTx{
+		TestDecidable: choices.TestDecidable{
+			IDV:     ids.GenerateTestID(),
+			StatusV: choices.Processing,
+		},
+		DependenciesV: []snowstorm.Tx{gTx},
+		InputIDsV:     utxos[1:],
+	}
+
+	manager.EdgeF = func() []ids.ID { return []ids.ID{gVtx.ID(), mVtx.ID()} }
+	manager.GetF = func(id ids.ID) (avalanche.Vertex, error) {
+		switch id {
+		case gVtx.ID():
+			return gVtx, nil
+		case mVtx.ID():
+			return mVtx, nil
+		}
+		t.Fatalf("Unknown vertex")
+		panic("Should have errored")
+	}
+
+	vm.CantBootstrapping = false
+	vm.CantBootstrapped = false
+
+	te := &Transitive{}
+	if err := te.Initialize(config); err != nil {
+		t.Fatal(err)
+	}
+
+	vm.CantBootstrapping = true
+	vm.CantBootstrapped = true
+
+	numBuilt := 0
+	manager.BuildF = func(_ uint32, _ []ids.ID, txs []snowstorm.Tx, _ []ids.ID) (avalanche.Vertex, error) {
+		numBuilt++
+		vtx := &avalanche.TestVertex{
+			TestDecidable: choices.TestDecidable{
+				IDV:     ids.GenerateTestID(),
+				StatusV: choices.Processing,
+			},
+			ParentsV: []avalanche.Vertex{gVtx, mVtx},
+			HeightV:  1,
+			TxsV:     txs,
+			BytesV:   []byte{1},
+		}
+
+		manager.GetF = func(id ids.ID) (avalanche.Vertex, error) {
+			switch id {
+			case gVtx.ID():
+				return gVtx, nil
+			case mVtx.ID():
+				return mVtx, nil
+			case vtx.ID():
+				return vtx, nil
+			}
+			t.Fatalf("Unknown vertex")
+			panic("Should have errored")
+		}
+
+		return vtx, nil
+	}
+
+	var (
+		vtxID          ids.ID
+		queryRequestID uint32
+	)
+	sender.PushQueryF = func(inVdrs ids.ShortSet, requestID uint32, vID ids.ID, vtx []byte) {
+		vtxID = vID
+		queryRequestID = requestID
+	}
+
+	vm.PendingF = func() []snowstorm.Tx { return []snowstorm.Tx{tx0, tx1} }
+	if err := te.Notify(common.PendingTxs); err != nil {
+		t.Fatal(err)
+	}
+
+	if numBuilt != 1 {
+		t.Fatalf("Should have issued txs differently")
+	}
+
+	if err := te.Chits(vdr, queryRequestID, []ids.ID{vtxID}); err != nil {
+		t.Fatal(err)
+	}
+
+	if numBuilt != 2 {
+		t.Fatalf("Should have issued txs differently")
+	}
+}
+
+// Test that after a restart where consensus doesn't have any preferences, the
+// engine votes for the persisted accepted frontier.
+func TestEngineRestartChitsFromEdge(t *testing.T) {
+	config := DefaultConfig()
+
+	vals := validators.NewSet()
+	config.Validators = vals
+
+	vdr := ids.GenerateTestShortID()
+	if err := vals.AddWeight(vdr, 1); err != nil {
+		t.Fatal(err)
+	}
+
+	sender := &common.SenderTest{}
+	sender.T = t
+	config.Sender = sender
+
+	sender.Default(true)
+	sender.CantGetAcceptedFrontier = false
+
+	manager := vertex.NewTestManager(t)
+	config.Manager = manager
+
+	manager.Default(true)
+
+	gVtx := &avalanche.TestVertex{TestDecidable: choices.TestDecidable{
+		IDV:     ids.GenerateTestID(),
+		StatusV: choices.Accepted,
+	}}
+	mVtx := &avalanche.TestVertex{TestDecidable: choices.TestDecidable{
+		IDV:     ids.GenerateTestID(),
+		StatusV: choices.Accepted,
+	}}
+
+	// The persisted frontier can't be loaded while restarting, so consensus is
+	// initialized without any vertices.
+	manager.EdgeF = func() []ids.ID { return []ids.ID{gVtx.ID(), mVtx.ID()} }
+	manager.GetF = func(ids.ID) (avalanche.Vertex, error) { return nil, errUnknownVertex }
+
+	te := &Transitive{}
+	if err := te.Initialize(config); err != nil {
+		t.Fatal(err)
+	}
+
+	if prefs := te.Consensus.Preferences(); prefs.Len() != 0 {
+		t.Fatalf("Consensus shouldn't have any preferences")
+	}
+
+	manager.GetF = func(id ids.ID) (avalanche.Vertex, error) {
+		switch id {
+		case gVtx.ID():
+			return gVtx, nil
+		case mVtx.ID():
+			return mVtx, nil
+		}
+		t.Fatalf("Unknown vertex")
+		panic("Should have errored")
+	}
+
+	chitted := new(bool)
+	sender.ChitsF = func(inVdr ids.ShortID, _ uint32, prefs []ids.ID) {
+		if *chitted {
+			t.Fatalf("Sent multiple chits")
+		}
+		*chitted = true
+		if inVdr != vdr {
+			t.Fatalf("Sent chits to the wrong validator")
+		}
+
+		expected := ids.Set{}
+		expected.Add(gVtx.ID(), mVtx.ID())
+		returned := ids.Set{}
+		returned.Add(prefs...)
+		if !expected.Equals(returned) {
+			t.Fatalf("Should have voted for the persisted frontier")
+		}
+	}
+
+	if err := te.PullQuery(vdr, 0, gVtx.ID()); err != nil {
+		t.Fatal(err)
+	}
+
+	if !*chitted {
+		t.Fatalf("Should have sent chits")
+	}
+}
+
+func TestEngineJustifiedChitsSent(t *testing.T) {
+	config := DefaultConfig()
+	config.JustifyChits = true
+
+	vals := validators.NewSet()
+	config.Validators = vals
+
+	vdr := ids.GenerateTestShortID()
+	if err := vals.AddWeight(vdr, 1); err != nil {
+		t.Fatal(err)
+	}
+
+	sender := &common.SenderTest{}
+	sender.T = t
+	config.Sender = sender
+
+	sender.Default(true)
+	sender.CantGetAcceptedFrontier = false
+
+	manager := vertex.NewTestManager(t)
+	config.Manager = manager
+
+	manager.Default(true)
+
+	gVtx := &avalanche.TestVertex{
+		TestDecidable: choices.TestDecidable{
+			IDV:     ids.GenerateTestID(),
+			StatusV: choices.Accepted,
+		},
+		HeightV: 3,
+	}
+
+	manager.EdgeF = func() []ids.ID { return []ids.ID{gVtx.ID()} }
+	manager.GetF = func(id ids.ID) (avalanche.Vertex, error) {
+		if id == gVtx.ID() {
+			return gVtx, nil
+		}
+		t.Fatalf("Unknown vertex")
+		panic("Should have errored")
+	}
+
+	te := &Transitive{}
+	if err := te.Initialize(config); err != nil {
+		t.Fatal(err)
+	}
+
+	chitted := new(bool)
+	sender.JustifiedChitsF = func(inVdr ids.ShortID, _ uint32, prefs []ids.ID, heights []uint64) {
+		if *chitted {
+			t.Fatalf("Sent multiple chits")
+		}
+		*chitted = true
+		if len(prefs) != 1 || prefs[0] != gVtx.ID() {
+			t.Fatalf("Wrong chits preferences")
+		}
+		if len(heights) != 1 || heights[0] != 3 {
+			t.Fatalf("Wrong chits heights")
+		}
+	}
+
+	if err := te.PullQuery(vdr, 0, gVtx.ID()); err != nil {
+		t.Fatal(err)
+	}
+
+	if !*chitted {
+		t.Fatalf("Should have sent justified chits")
+	}
+}
+
+func TestEngineJustify(t *testing.T) {
+	config := DefaultConfig()
+
+	manager := vertex.NewTestManager(t)
+	config.Manager = manager
+
+	manager.Default(true)
+
+	acceptedVtx := &avalanche.TestVertex{
+		TestDecidable: choices.TestDecidable{
+			IDV:     ids.GenerateTestID(),
+			StatusV: choices.Accepted,
+		},
+		HeightV: 1,
+	}
+	rejectedVtx := &avalanche.TestVertex{
+		TestDecidable: choices.TestDecidable{
+			IDV:     ids.GenerateTestID(),
+			StatusV: choices.Rejected,
+		},
+		HeightV: 1,
+	}
+	unknownVtxID := ids.GenerateTestID()
+
+	manager.EdgeF = func() []ids.ID { return nil }
+	manager.GetF = func(id ids.ID) (avalanche.Vertex, error) {
+		switch id {
+		case acceptedVtx.ID():
+			return acceptedVtx, nil
+		case rejectedVtx.ID():
+			return rejectedVtx, nil
+		}
+		return nil, errUnknownVertex
+	}
+
+	te := &Transitive{}
+	if err := te.Initialize(config); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := te.justify([]ids.ID{acceptedVtx.ID(), unknownVtxID}, []uint64{1, 100}); err != nil {
+		t.Fatalf("Should have justified votes consistent with the local DAG: %s", err)
+	}
+	if err := te.justify([]ids.ID{acceptedVtx.ID()}, []uint64{2}); err == nil {
+		t.Fatalf("Should have rejected a vote with the wrong height")
+	}
+	if err := te.justify([]ids.ID{rejectedVtx.ID()}, []uint64{1}); err == nil {
+		t.Fatalf("Should have rejected a vote for a rejected vertex")
+	}
+	if err := te.justify([]ids.ID{acceptedVtx.ID()}, nil); err == nil {
+		t.Fatalf("Should have rejected votes without heights")
+	}
+}
+
+func TestEngineDegradedIssuance(t *testing.T) {
+	config := DefaultConfig()
+	config.Params.BatchSize = 1
+
+	sender := &common.SenderTest{}
+	sender.T = t
+	config.Sender = sender
+
+	sender.Default(true)
+	sender.CantGetAcceptedFrontier = false
+
+	vals := validators.NewSet()
+	config.Validators = vals
+
+	vdr := ids.GenerateTestShortID()
+	if err := vals.AddWeight(vdr, 1); err != nil {
+		t.Fatal(err)
+	}
+
+	manager := vertex.NewTestManager(t)
+	config.Manager = manager
+
+	manager.Default(true)
+
+	vm := &vertex.TestVM{}
+	vm.T = t
+	config.VM = vm
+
+	vm.Default(true)
+	vm.HealthCheckF = func() (interface{}, error) { return nil, nil }
+
+	gVtx := &avalanche.TestVertex{TestDecidable: choices.TestDecidable{
+		IDV:     ids.GenerateTestID(),
+		StatusV: choices.Accepted,
+	}}
+
+	tx := &snowstorm.TestTx{TestDecidable: choices.TestDecidable{
+		IDV:     ids.GenerateTestID(),
+		StatusV: choices.Processing,
+	}}
+	tx.InputIDsV = append(tx.InputIDsV, ids.GenerateTestID())
+
+	manager.EdgeF = func() []ids.ID { return []ids.ID{gVtx.ID()} }
+	manager.GetF = func(id ids.ID) (avalanche.Vertex, error) {
+		if id == gVtx.ID() {
+			return gVtx, nil
+		}
+		t.Fatalf("Unknown vertex")
+		panic("Should have errored")
+	}
+
+	vm.CantBootstrapping = false
+	vm.CantBootstrapped = false
+
+	te := &Transitive{}
+	if err := te.Initialize(config); err != nil {
+		t.Fatal(err)
+	}
+
+	vm.CantBootstrapping = true
+	vm.CantBootstrapped = true
+
+	errBuild := errors.New("disk failure")
+	manager.BuildF = func(uint32, []ids.ID, []snowstorm.Tx, []ids.ID) (avalanche.Vertex, error) {
+		return nil, errBuild
+	}
+
+	vm.PendingF = func() []snowstorm.Tx { return []snowstorm.Tx{tx} }
+	if err := te.Notify(common.PendingTxs); err != nil {
+		t.Fatalf("failing to build a vertex shouldn't halt the engine: %s", err)
+	}
+	if !te.degraded.Degraded() {
+		t.Fatalf("should be degraded after failing to build a vertex")
+	}
+	if _, err := te.HealthCheck(); err == nil {
+		t.Fatalf("should be unhealthy while degraded")
+	}
+
+	// Issuance is paused until the backoff elapses
+	manager.BuildF = func(uint32, []ids.ID, []snowstorm.Tx, []ids.ID) (avalanche.Vertex, error) {
+		t.Fatalf("shouldn't have retried issuance before the backoff elapsed")
+		return nil, nil
+	}
+	vm.PendingF = func() []snowstorm.Tx { return nil }
+	if err := te.Notify(common.PendingTxs); err != nil {
+		t.Fatal(err)
+	}
+
+	// Once the backoff has elapsed, gossip retries issuance of the held txs
+	te.degraded.clock.Set(te.degraded.retryTime)
+
+	var builtTxs []snowstorm.Tx
+	manager.BuildF = func(_ uint32, _ []ids.ID, txs []snowstorm.Tx, _ []ids.ID) (avalanche.Vertex, error) {
+		builtTxs = txs
+		return &avalanche.TestVertex{
+			TestDecidable: choices.TestDecidable{
+				IDV:     ids.GenerateTestID(),
+				StatusV: choices.Processing,
+			},
+			ParentsV: []avalanche.Vertex{gVtx},
+			HeightV:  1,
+			TxsV:     txs,
+			BytesV:   []byte{1},
+		}, nil
+	}
+	sender.CantGossip = false
+	sender.CantPushQuery = false
+	gVtx.BytesV = []byte{0}
+
+	if err := te.Gossip(); err != nil {
+		t.Fatal(err)
+	}
+	if len(builtTxs) != 1 || builtTxs[0].ID() != tx.ID() {
+		t.Fatalf("should have retried issuing the held tx")
+	}
+	if te.degraded.Degraded() {
+		t.Fatalf("should have recovered after building a vertex")
+	}
+	if _, err := te.degraded.HealthCheck(); err != nil {
+		t.Fatalf("issuance should be healthy after recovering: %s", err)
+	}
+}
+
+func TestEngineHoldsVertexOnPersistenceError(t *testing.T) {
+	config := DefaultConfig()
+
+	sender := &common.SenderTest{}
+	sender.T = t
+	config.Sender = sender
+
+	sender.Default(true)
+	sender.CantGetAcceptedFrontier = false
+
+	vals := validators.NewSet()
+	config.Validators = vals
+
+	vdr := ids.GenerateTestShortID()
+	if err := vals.AddWeight(vdr, 1); err != nil {
+		t.Fatal(err)
+	}
+
+	manager := vertex.NewTestManager(t)
+	config.Manager = manager
+
+	manager.Default(true)
+
+	vm := &vertex.TestVM{}
+	vm.T = t
+	config.VM = vm
+
+	vm.Default(true)
+	vm.HealthCheckF = func() (interface{}, error) { return nil, nil }
+
+	gVtx := &avalanche.TestVertex{TestDecidable: choices.TestDecidable{
+		IDV:     ids.GenerateTestID(),
+		StatusV: choices.Accepted,
+	}}
+
+	tx := &snowstorm.TestTx{TestDecidable: choices.TestDecidable{
+		IDV:     ids.GenerateTestID(),
+		StatusV: choices.Processing,
+	}}
+	tx.InputIDsV = append(tx.InputIDsV, ids.GenerateTestID())
+
+	vtx := &avalanche.TestVertex{
+		TestDecidable: choices.TestDecidable{
+			IDV:     ids.GenerateTestID(),
+			StatusV: choices.Processing,
+		},
+		ParentsV: []avalanche.Vertex{gVtx},
+		HeightV:  1,
+		TxsErrV:  &snowstorm.PersistenceError{Err: errors.New("disk failure")},
+		BytesV:   []byte{1},
+	}
+
+	manager.EdgeF = func() []ids.ID { return []ids.ID{gVtx.ID()} }
+	manager.GetF = func(id ids.ID) (avalanche.Vertex, error) {
+		if id == gVtx.ID() {
+			return gVtx, nil
+		}
+		t.Fatalf("Unknown vertex")
+		panic("Should have errored")
+	}
+
+	vm.CantBootstrapping = false
+	vm.CantBootstrapped = false
+
+	te := &Transitive{}
+	if err := te.Initialize(config); err != nil {
+		t.Fatal(err)
+	}
+
+	vm.CantBootstrapping = true
+	vm.CantBootstrapped = true
+
+	if _, err := te.issueFrom(vdr, vtx); err != nil {
+		t.Fatalf("failing to persist the txs of a vertex shouldn't halt the engine: %s", err)
+	}
+	if !te.degraded.Degraded() {
+		t.Fatalf("should be degraded after failing to persist the txs of a vertex")
+	}
+	if te.Consensus.VertexIssued(vtx) || te.pending.Contains(vtx.ID()) {
+		t.Fatalf("vertex shouldn't have been issued")
+	}
+	if len(te.retryVtxs) != 1 || te.retryVtxs[0].ID() != vtx.ID() {
+		t.Fatalf("should have held the vertex")
+	}
+	if failures := testutil.ToFloat64(te.numTxPersistFailures); failures != 1 {
+		t.Fatalf("should have reported 1 persistence failure, but reported %f", failures)
+	}
+
+	// Once the backoff has elapsed, gossip issues the held vertex again
+	te.degraded.clock.Set(te.degraded.retryTime)
+	vtx.TxsErrV = nil
+	vtx.TxsV = []snowstorm.Tx{tx}
+
+	queried := false
+	sender.CantGossip = false
+	sender.PushQueryF = func(_ ids.ShortSet, _ uint32, vtxID ids.ID, _ []byte) {
+		if vtxID != vtx.ID() {
+			t.Fatalf("queried the wrong vertex")
+		}
+		queried = true
+	}
+	vm.PendingF = func() []snowstorm.Tx { return nil }
+	gVtx.BytesV = []byte{0}
+
+	if err := te.Gossip(); err != nil {
+		t.Fatal(err)
+	}
+	if !te.Consensus.VertexIssued(vtx) || !queried {
+		t.Fatalf("should have issued the held vertex")
+	}
+	if len(te.retryVtxs) != 0 {
+		t.Fatalf("shouldn't hold the vertex after issuing it")
+	}
+	if te.degraded.Degraded() {
+		t.Fatalf("should have recovered after issuing the held vertex")
+	}
+}
+
+func TestEngineHoldsBoundedRetryTxs(t *testing.T) {
+	config := DefaultConfig()
+
+	te := &Transitive{}
+	if err := te.Initialize(config); err != nil {
+		t.Fatal(err)
+	}
+
+	newTx := func() snowstorm.Tx {
+		return &snowstorm.TestTx{TestDecidable: choices.TestDecidable{
+			IDV:     ids.GenerateTestID(),
+			StatusV: choices.Processing,
+		}}
+	}
+
+	for i := 0; i < maxRetryTxs-1; i++ {
+		te.holdTxs([]snowstorm.Tx{newTx()})
+	}
+	tx0, tx1 := newTx(), newTx()
+	te.holdTxs([]snowstorm.Tx{tx0, tx1})
+
+	if len(te.retryTxs) != maxRetryTxs {
+		t.Fatalf("should have held %d txs, but held %d", maxRetryTxs, len(te.retryTxs))
+	}
+	if te.retryTxs[maxRetryTxs-1].ID() != tx0.ID() {
+		t.Fatalf("should have dropped the most recent tx")
+	}
+	if dropped := testutil.ToFloat64(te.numDroppedRetryTxs); dropped != 1 {
+		t.Fatalf("should have reported 1 dropped tx, but reported %f", dropped)
+	}
+}
+
+func TestDegradedModeBackoff(t *testing.T) {
+	d := degradedMode{}
+	d.clock.Set(time.Unix(0, 0))
+
+	expected := minDegradedBackoff
+	for i := 0; i < 64; i++ {
+		if backoff := d.Fail(errMissing); backoff != expected {
+			t.Fatalf("failure %d should have backed off for %s, but backed off for %s", i, expected, backoff)
+		}
+		if d.CanIssue() {
+			t.Fatalf("shouldn't be able to issue during the backoff")
+		}
+		expected *= 2
+		if expected > maxDegradedBackoff {
+			expected = maxDegradedBackoff
+		}
+	}
+
+	d.clock.Set(d.retryTime)
+	if !d.CanIssue() {
+		t.Fatalf("should be able to issue once the backoff has elapsed")
+	}
+
+	d.Recover()
+	if d.Degraded() {
+		t.Fatalf("shouldn't be degraded after recovering")
+	}
+	if backoff := d.Fail(errMissing); backoff != minDegradedBackoff {
+		t.Fatalf("backoff should be reset after recovering, but backed off for %s", backoff)
+	}
+}
+
+type testFeeTx struct {
+	*snowstorm.TestTx
+	fee uint64
+}
+
+func (tx *testFeeTx) Fee() uint64 { return tx.fee }
+
+func TestRepollSchedulerPriority(t *testing.T) {
+	s := repollScheduler{}
+	s.clock.Set(time.Unix(0, 0))
+
+	newVtx := func(txs ...snowstorm.Tx) *avalanche.TestVertex {
+		return &avalanche.TestVertex{
+			TestDecidable: choices.TestDecidable{
+				IDV:     ids.GenerateTestID(),
+				StatusV: choices.Processing,
+			},
+			TxsV: txs,
+		}
+	}
+	newTx := func(fee uint64) *testFeeTx {
+		return &testFeeTx{
+			TestTx: &snowstorm.TestTx{TestDecidable: choices.TestDecidable{
+				IDV:     ids.GenerateTestID(),
+				StatusV: choices.Processing,
+			}},
+			fee: fee,
+		}
+	}
+
+	oldTx := newTx(0)
+	s.Issued([]snowstorm.Tx{oldTx})
+
+	s.clock.Set(time.Unix(0, 0).Add(stalledTxDuration))
+	lowFeeTx := newTx(1)
+	highFeeTx := newTx(100)
+	s.Issued([]snowstorm.Tx{lowFeeTx, highFeeTx})
+
+	oldVtx := newVtx(oldTx)
+	lowFeeVtx := newVtx(lowFeeTx)
+	highFeeVtx := newVtx(lowFeeTx, highFeeTx)
+
+	vtxs := map[ids.ID]avalanche.Vertex{
+		oldVtx.ID():     oldVtx,
+		lowFeeVtx.ID():  lowFeeVtx,
+		highFeeVtx.ID(): highFeeVtx,
+	}
+	gets := 0
+	getVertex := func(vtxID ids.ID) (avalanche.Vertex, error) {
+		gets++
+		return vtxs[vtxID], nil
+	}
+
+	vtxIDs, err := s.Schedule([]ids.ID{lowFeeVtx.ID(), highFeeVtx.ID(), oldVtx.ID()}, 2, getVertex)
+	if err != nil {
+		t.Fatal(err)
+	}
+	switch {
+	case len(vtxIDs) != 2:
+		t.Fatalf("should have scheduled 2 re-polls, but scheduled %d", len(vtxIDs))
+	case vtxIDs[0] != oldVtx.ID():
+		t.Fatalf("the vertex with a stalled tx should be re-polled first")
+	case vtxIDs[1] != highFeeVtx.ID():
+		t.Fatalf("the vertex with the highest fee should be re-polled next")
+	}
+
+	// The priorities are cached, so the preferences aren't fetched again
+	gets = 0
+	if _, err := s.Schedule([]ids.ID{oldVtx.ID(), lowFeeVtx.ID(), highFeeVtx.ID()}, 1, getVertex); err != nil {
+		t.Fatal(err)
+	}
+	if gets != 0 {
+		t.Fatalf("shouldn't have fetched preferences with cached priorities")
+	}
+
+	// Once the stalled tx is decided and pruned, it no longer affects the
+	// priority
+	oldTx.StatusV = choices.Accepted
+	s.prune()
+	vtxIDs, err = s.Schedule([]ids.ID{oldVtx.ID(), lowFeeVtx.ID(), highFeeVtx.ID()}, 1, getVertex)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(vtxIDs) != 1 || vtxIDs[0] != highFeeVtx.ID() {
+		t.Fatalf("the vertex with the highest fee should be re-polled first")
+	}
+	if _, ok := s.txs[oldTx.ID()]; ok {
+		t.Fatalf("decided txs should have been pruned")
+	}
+	if gets != 3 {
+		t.Fatalf("should have recomputed the priorities after pruning")
+	}
+}
+
+// Test that txs aren't built into a vertex before their dependencies are
+// issued
+func TestEngineDefersTxsWithUnissuedDependencies(t *testing.T) {
+	config := DefaultConfig()
+	config.Params.BatchSize = 2
+
+	sender := &common.SenderTest{}
+	sender.T = t
+	config.Sender = sender
+
+	sender.Default(true)
+	sender.CantGetAcceptedFrontier = false
+	sender.CantPushQuery = false
+
+	vals := validators.NewSet()
+	config.Validators = vals
+
+	vdr := ids.GenerateTestShortID()
+	if err := vals.AddWeight(vdr, 1); err != nil {
+		t.Fatal(err)
+	}
+
+	manager := vertex.NewTestManager(t)
+	config.Manager = manager
+
+	manager.Default(true)
+
+	vm := &vertex.TestVM{}
+	vm.T = t
+	config.VM = vm
+
+	vm.Default(true)
+
+	gVtx := &avalanche.TestVertex{TestDecidable: choices.TestDecidable{
+		IDV:     ids.GenerateTestID(),
+		StatusV: choices.Accepted,
+	}}
+
+	tx0 := &snowstorm.TestTx{
+		TestDecidable: choices.TestDecidable{
+			IDV:     ids.GenerateTestID(),
+			StatusV: choices.Processing,
+		},
+		InputIDsV: []ids.ID{ids.GenerateTestID()},
+	}
+	tx1 := &snowstorm.TestTx{
+		TestDecidable: choices.TestDecidable{
+			IDV:     ids.GenerateTestID(),
+			StatusV: choices.Processing,
+		},
+		DependenciesV: []snowstorm.Tx{tx0},
+		InputIDsV:     []ids.ID{ids.GenerateTestID()},
+	}
+
+	manager.EdgeF = func() []ids.ID { return []ids.ID{gVtx.ID()} }
+	manager.GetF = func(id ids.ID) (avalanche.Vertex, error) {
+		if id == gVtx.ID() {
+			return gVtx, nil
+		}
+		t.Fatalf("Unknown vertex")
+		panic("Should have errored")
+	}
+
+	vm.CantBootstrapping = false
+	vm.CantBootstrapped = false
+
+	te := &Transitive{}
+	if err := te.Initialize(config); err != nil {
+		t.Fatal(err)
+	}
+
+	vm.CantBootstrapping = true
+	vm.CantBootstrapped = true
+
+	var builtTxs [][]snowstorm.Tx
+	manager.BuildF = func(_ uint32, _ []ids.ID, txs []snowstorm.Tx, _ []ids.ID) (avalanche.Vertex, error) {
+		builtTxs = append(builtTxs, txs)
+		return &avalanche.TestVertex{
+			TestDecidable: choices.TestDecidable{
+				IDV:     ids.GenerateTestID(),
+				StatusV: choices.Processing,
+			},
+			ParentsV: []avalanche.Vertex{gVtx},
+			HeightV:  1,
+			TxsV:     txs,
+			BytesV:   []byte{1},
+		}, nil
+	}
+
+	// [tx0] hasn't been issued, so [tx1] is deferred
+	vm.PendingF = func() []snowstorm.Tx { return []snowstorm.Tx{tx1} }
+	if err := te.Notify(common.PendingTxs); err != nil {
+		t.Fatal(err)
+	}
+	if len(builtTxs) != 0 {
+		t.Fatalf("Shouldn't have built a vertex blocked on an unissued tx")
+	}
+	if !te.deferredTxs.Contains(tx1.ID()) || len(te.retryTxs) != 0 {
+		t.Fatalf("Should have deferred the tx until its dependency is issued")
+	}
+
+	// Once [tx0] is issued, [tx1] is issued without waiting to be retried
+	vm.PendingF = func() []snowstorm.Tx { return []snowstorm.Tx{tx0} }
+	if err := te.Notify(common.PendingTxs); err != nil {
+		t.Fatal(err)
+	}
+	if len(builtTxs) != 2 {
+		t.Fatalf("Should have built %d vertices, but built %d", 2, len(builtTxs))
+	}
+	if txs := builtTxs[0]; len(txs) != 1 || txs[0] != tx0 {
+		t.Fatalf("Should have built the dependency first")
+	}
+	if txs := builtTxs[1]; len(txs) != 1 || txs[0] != tx1 {
+		t.Fatalf("Should have built the deferred tx once its dependency was issued")
+	}
+	if te.deferredTxs.Len() != 0 || len(te.readyTxs) != 0 {
+		t.Fatalf("Shouldn't have any deferred txs left")
+	}
+
+	// A tx is built in the same vertex as its dependency when both are pending
+	tx2 := &snowstorm.TestTx{
+		TestDecidable: choices.TestDecidable{
+			IDV:     ids.GenerateTestID(),
+			StatusV: choices.Processing,
+		},
+		InputIDsV: []ids.ID{ids.GenerateTestID()},
+	}
+	tx3 := &snowstorm.TestTx{
+		TestDecidable: choices.TestDecidable{
+			IDV:     ids.GenerateTestID(),
+			StatusV: choices.Processing,
+		},
+		DependenciesV: []snowstorm.Tx{tx2},
+		InputIDsV:     []ids.ID{ids.GenerateTestID()},
+	}
+	vm.PendingF = func() []snowstorm.Tx { return []snowstorm.Tx{tx3, tx2} }
+	if err := te.Notify(common.PendingTxs); err != nil {
+		t.Fatal(err)
+	}
+	if len(builtTxs) != 3 {
+		t.Fatalf("Should have built %d vertices, but built %d", 3, len(builtTxs))
+	}
+	if txs := builtTxs[2]; len(txs) != 2 || txs[0] != tx2 || txs[1] != tx3 {
+		t.Fatalf("Should have ordered the tx after its dependency")
+	}
+}
+
+// Test that txs whose dependencies were rejected are dropped rather than
+// deferred
+func TestEngineDropsTxsWithRejectedDependencies(t *testing.T) {
+	config := DefaultConfig()
+
+	sender := &common.SenderTest{}
+	sender.T = t
+	config.Sender = sender
+
+	sender.Default(true)
+	sender.CantGetAcceptedFrontier = false
+
+	vals := validators.NewSet()
+	config.Validators = vals
+
+	vdr := ids.GenerateTestShortID()
+	if err := vals.AddWeight(vdr, 1); err != nil {
+		t.Fatal(err)
+	}
+
+	manager := vertex.NewTestManager(t)
+	config.Manager = manager
+
+	manager.Default(true)
+
+	vm := &vertex.TestVM{}
+	vm.T = t
+	config.VM = vm
+
+	vm.Default(true)
+
+	gVtx := &avalanche.TestVertex{TestDecidable: choices.TestDecidable{
+		IDV:     ids.GenerateTestID(),
+		StatusV: choices.Accepted,
+	}}
+
+	tx0 := &snowstorm.TestTx{
+		TestDecidable: choices.TestDecidable{
+			IDV:     ids.GenerateTestID(),
+			StatusV: choices.Rejected,
+		},
+		InputIDsV: []ids.ID{ids.GenerateTestID()},
+	}
+	tx1 := &snowstorm.TestTx{
+		TestDecidable: choices.TestDecidable{
+			IDV:     ids.GenerateTestID(),
+			StatusV: choices.Processing,
+		},
+		DependenciesV: []snowstorm.Tx{tx0},
+		InputIDsV:     []ids.ID{ids.GenerateTestID()},
+	}
+
+	manager.EdgeF = func() []ids.ID { return []ids.ID{gVtx.ID()} }
+	manager.GetF = func(id ids.ID) (avalanche.Vertex, error) {
+		if id == gVtx.ID() {
+			return gVtx, nil
+		}
+		t.Fatalf("Unknown vertex")
+		panic("Should have errored")
+	}
+
+	vm.CantBootstrapping = false
+	vm.CantBootstrapped = false
+
+	te := &Transitive{}
+	if err := te.Initialize(config); err != nil {
+		t.Fatal(err)
+	}
+
+	vm.CantBootstrapping = true
+	vm.CantBootstrapped = true
+
+	manager.BuildF = func(uint32, []ids.ID, []snowstorm.Tx, []ids.ID) (avalanche.Vertex, error) {
+		t.Fatalf("Shouldn't have built a vertex with a tx whose dependency was rejected")
+		return nil, nil
+	}
+
+	vm.PendingF = func() []snowstorm.Tx { return []snowstorm.Tx{tx1} }
+	if err := te.Notify(common.PendingTxs); err != nil {
+		t.Fatal(err)
+	}
+	if te.deferredTxs.Len() != 0 || len(te.retryTxs) != 0 {
+		t.Fatalf("Shouldn't have held the tx")
+	}
+}
+
+func TestEngineFinishesPollAfterReenabling(t *testing.T) {
+	config := DefaultConfig()
+
+	vals := validators.NewSet()
+	config.Validators = vals
+
+	vdr := ids.GenerateTestShortID()
+	if err := vals.AddWeight(vdr, 1); err != nil {
+		t.Fatal(err)
+	}
+
+	gVtx := &avalanche.TestVertex{TestDecidable: choices.TestDecidable{
+		IDV:     ids.GenerateTestID(),
+		StatusV: choices.Accepted,
+	}}
+
+	tx0 := &snowstorm.TestTx{TestDecidable: choices.TestDecidable{
+		IDV:     ids.GenerateTestID(),
+		StatusV: choices.Processing,
+	}}
+	tx0.InputIDsV = append(tx0.InputIDsV, ids.GenerateTestID())
+
+	vtx := &avalanche.TestVertex{
+		TestDecidable: choices.TestDecidable{
+			IDV:     ids.GenerateTestID(),
+			StatusV: choices.Processing,
+		},
+		ParentsV: []avalanche.Vertex{gVtx},
+		HeightV:  1,
+		TxsV:     []snowstorm.Tx{tx0},
+	}
+
+	manager := vertex.NewTestManager(t)
+	config.Manager = manager
+
+	manager.Default(true)
+	manager.CantEdge = false
+
+	sender := &common.SenderTest{}
+	sender.T = t
+	config.Sender = sender
+
+	sender.Default(true)
+	sender.CantGetAcceptedFrontier = false
+
+	te := &Transitive{}
+	if err := te.Initialize(config); err != nil {
+		t.Fatal(err)
+	}
+
+	requestID := new(uint32)
+	sender.PushQueryF = func(_ ids.ShortSet, reqID uint32, _ ids.ID, _ []byte) {
+		*requestID = reqID
+	}
+
+	if err := te.issue(vtx); err != nil {
+		t.Fatal(err)
+	}
+
+	sender.PushQueryF = nil
+
+	// The chain is disabled while the poll is outstanding, so the response
+	// isn't passed to the engine
+	disabled := &common.DisabledEngine{
+		Engine: te,
+		Sender: sender,
+	}
+	if err := disabled.Chits(vdr, *requestID, []ids.ID{vtx.ID()}); err != nil {
+		t.Fatal(err)
+	}
+	if te.polls.Len() != 1 {
+		t.Fatalf("Poll shouldn't have finished while the chain is disabled")
+	}
+
+	repolled := new(bool)
+	sender.PullQueryF = func(_ ids.ShortSet, reqID uint32, vtxID ids.ID) {
+		*repolled = true
+		if reqID == *requestID {
+			t.Fatalf("Should have issued a new poll")
+		}
+		if vtxID != vtx.ID() {
+			t.Fatalf("Wrong vertex queried")
+		}
+	}
+
+	if err := disabled.Enable(); err != nil {
+		t.Fatal(err)
+	}
+	if !*repolled {
+		t.Fatalf("Poll should have finished, and the vertex should have been repolled")
+	}
+}
+
+// frontierDispatcher records the accepted frontiers that were published
+type frontierDispatcher struct {
+	heights   []uint64
+	frontiers [][]ids.ID
+}
+
+func (*frontierDispatcher) Issue(*snow.Context, ids.ID, []byte)  {}
+func (*frontierDispatcher) Accept(*snow.Context, ids.ID, []byte) {}
+func (*frontierDispatcher) Reject(*snow.Context, ids.ID, []byte) {}
+func (d *frontierDispatcher) AdvanceFrontier(_ *snow.Context, height uint64, frontier []ids.ID) {
+	d.heights = append(d.heights, height)
+	d.frontiers = append(d.frontiers, frontier)
+}
+
+func TestEnginePublishesAcceptedFrontier(t *testing.T) {
+	config := DefaultConfig()
+
+	dispatcher := &frontierDispatcher{}
+	config.Ctx.ConsensusDispatcher = dispatcher
+
+	vals := validators.NewSet()
+	config.Validators = vals
+
+	vdr := ids.GenerateTestShortID()
+	if err := vals.AddWeight(vdr, 1); err != nil {
+		t.Fatal(err)
+	}
+
+	sender := &common.SenderTest{}
+	sender.T = t
+	config.Sender = sender
+
+	sender.Default(true)
+	sender.CantGetAcceptedFrontier = false
+
+	manager := vertex.NewTestManager(t)
+	config.Manager = manager
+
+	manager.Default(true)
+
+	gVtx := &avalanche.TestVertex{TestDecidable: choices.TestDecidable{
+		IDV:     ids.GenerateTestID(),
+		StatusV: choices.Accepted,
+	}}
+	vtx := &avalanche.TestVertex{
+		TestDecidable: choices.TestDecidable{
+			IDV:     ids.GenerateTestID(),
+			StatusV: choices.Accepted,
+		},
+		ParentsV: []avalanche.Vertex{gVtx},
+		HeightV:  1,
+	}
+
+	edge := []ids.ID{gVtx.ID()}
+	manager.EdgeF = func() []ids.ID { return edge }
+	manager.GetF = func(vtxID ids.ID) (avalanche.Vertex, error) {
+		switch vtxID {
+		case gVtx.ID():
+			return gVtx, nil
+		case vtx.ID():
+			return vtx, nil
+		}
+		t.Fatalf("Unknown vertex")
+		panic("Should have failed")
+	}
+
+	te := &Transitive{}
+	if err := te.Initialize(config); err != nil {
+		t.Fatal(err)
+	}
+
+	switch {
+	case len(dispatcher.frontiers) != 1:
+		t.Fatalf("Should have published the frontier after bootstrapping")
+	case dispatcher.heights[0] != 0:
+		t.Fatalf("Should have published height %d, but published %d", 0, dispatcher.heights[0])
+	}
+
+	edge = []ids.ID{vtx.ID()}
+	te.publishFrontier()
+
+	switch {
+	case len(dispatcher.frontiers) != 2:
+		t.Fatalf("Should have published the advanced frontier")
+	case dispatcher.heights[1] != 1:
+		t.Fatalf("Should have published height %d, but published %d", 1, dispatcher.heights[1])
+	case len(dispatcher.frontiers[1]) != 1 || dispatcher.frontiers[1][0] != vtx.ID():
+		t.Fatalf("Should have published the new vertex as the frontier")
+	}
+
+	te.publishFrontier()
+	if len(dispatcher.frontiers) != 2 {
+		t.Fatalf("Shouldn't have published an unchanged frontier")
+	}
+}
+
+func TestEngineParsesParentHints(t *testing.T) {
+	config := DefaultConfig()
+
+	vals := validators.NewSet()
+	config.Validators = vals
+
+	vdr := ids.GenerateTestShortID()
+	if err := vals.AddWeight(vdr, 1); err != nil {
+		t.Fatal(err)
+	}
+
+	sender := &common.SenderTest{}
+	sender.T = t
+	config.Sender = sender
+
+	manager := vertex.NewTestManager(t)
+	config.Manager = manager
+
+	gVtx := &avalanche.TestVertex{
+		TestDecidable: choices.TestDecidable{
+			IDV:     ids.GenerateTestID(),
+			StatusV: choices.Accepted,
+		},
+		BytesV: []byte{0},
+	}
+
+	utxos := []ids.ID{ids.GenerateTestID(), ids.GenerateTestID()}
+
+	tx0 := &snowstorm.TestTx{TestDecidable: choices.TestDecidable{
+		IDV:     ids.GenerateTestID(),
+		StatusV: choices.Processing,
+	}}
+	tx0.InputIDsV = append(tx0.InputIDsV, utxos[0])
+
+	tx1 := &snowstorm.TestTx{TestDecidable: choices.TestDecidable{
+		IDV:     ids.GenerateTestID(),
+		StatusV: choices.Processing,
+	}}
+	tx1.InputIDsV = append(tx1.InputIDsV, utxos[1])
+
+	vtx0Bytes := []byte{1}
+	vtx0 := &avalanche.TestVertex{
+		TestDecidable: choices.TestDecidable{
+			IDV:     hashing.ComputeHash256Array(vtx0Bytes),
+			StatusV: choices.Unknown,
+		},
+		ParentsV: []avalanche.Vertex{gVtx},
+		HeightV:  1,
+		TxsV:     []snowstorm.Tx{tx0},
+		BytesV:   vtx0Bytes,
+	}
+	vtx1 := &avalanche.TestVertex{
+		TestDecidable: choices.TestDecidable{
+			IDV:     ids.GenerateTestID(),
+			StatusV: choices.Processing,
+		},
+		ParentsV: []avalanche.Vertex{vtx0},
+		HeightV:  2,
+		TxsV:     []snowstorm.Tx{tx1},
+		BytesV:   []byte{2},
+	}
+	unneeded := []byte{3}
+
+	te := &Transitive{}
+	if err := te.Initialize(config); err != nil {
+		t.Fatal(err)
+	}
+
+	manager.ParseF = func(b []byte) (avalanche.Vertex, error) {
+		switch {
+		case bytes.Equal(b, vtx0.Bytes()):
+			vtx0.StatusV = choices.Processing
+			return vtx0, nil
+		case bytes.Equal(b, vtx1.Bytes()):
+			return vtx1, nil
+		}
+		t.Fatalf("Shouldn't have parsed an unneeded hint")
+		panic("Should have failed")
+	}
+	manager.GetF = func(vtxID ids.ID) (avalanche.Vertex, error) {
+		switch {
+		case vtxID == gVtx.ID():
+			return gVtx, nil
+		case vtxID == vtx0.ID() && vtx0.StatusV.Fetched():
+			return vtx0, nil
+		case vtxID == vtx1.ID():
+			return vtx1, nil
+		}
+		return nil, errUnknownVertex
+	}
+	sender.GetF = func(ids.ShortID, uint32, ids.ID) {
+		t.Fatalf("Shouldn't have requested the hinted parent")
+	}
+	sender.CantPushQuery = false
+
+	if err := te.HintedPut(vdr, 0, vtx1.ID(), vtx1.Bytes(), [][]byte{unneeded, vtx0.Bytes()}); err != nil {
+		t.Fatal(err)
+	}
+
+	prefs := te.Consensus.Preferences()
+	switch {
+	case !prefs.Contains(vtx1.ID()):
+		t.Fatalf("Should have issued the vertex")
+	case !te.Consensus.VertexIssued(vtx0):
+		t.Fatalf("Should have issued the hinted parent")
+	}
+
+	hinted := new(bool)
+	sender.HintedPutF = func(reqVdr ids.ShortID, requestID uint32, vtxID ids.ID, vtxBytes []byte, hints [][]byte) {
+		*hinted = true
+		switch {
+		case vtxID != vtx1.ID():
+			t.Fatalf("Wrong vertex sent")
+		case len(hints) != 1 || !bytes.Equal(hints[0], vtx0.Bytes()):
+			t.Fatalf("Should have hinted the processing parent")
+		}
+	}
+
+	if err := te.Get(vdr, 1, vtx1.ID()); err != nil {
+		t.Fatal(err)
+	}
+	if !*hinted {
+		t.Fatalf("Should have sent the vertex with its processing parent")
+	}
+}
+
+func TestEngineFetchesMissingAncestry(t *testing.T) {
+	config := DefaultConfig()
+
+	vals := validators.NewSet()
+	config.Validators = vals
+
+	vdr := ids.GenerateTestShortID()
+	if err := vals.AddWeight(vdr, 1); err != nil {
+		t.Fatal(err)
+	}
+
+	sender := &common.SenderTest{}
+	sender.T = t
+	config.Sender = sender
+
+	manager := vertex.NewTestManager(t)
+	config.Manager = manager
+
+	gVtx := &avalanche.TestVertex{
+		TestDecidable: choices.TestDecidable{
+			IDV:     ids.GenerateTestID(),
+			StatusV: choices.Accepted,
+		},
+		BytesV: []byte{0},
+	}
+
+	newTx := func() *snowstorm.TestTx {
+		tx := &snowstorm.TestTx{TestDecidable: choices.TestDecidable{
+			IDV:     ids.GenerateTestID(),
+			StatusV: choices.Processing,
+		}}
+		tx.InputIDsV = append(tx.InputIDsV, ids.GenerateTestID())
+		return tx
+	}
+	newVtx := func(b byte, status choices.Status, height uint64, parents ...avalanche.Vertex) *avalanche.TestVertex {
+		vtxBytes := []byte{b}
+		return &avalanche.TestVertex{
+			TestDecidable: choices.TestDecidable{
+				IDV:     hashing.ComputeHash256Array(vtxBytes),
+				StatusV: status,
+			},
+			ParentsV: parents,
+			HeightV:  height,
+			TxsV:     []snowstorm.Tx{newTx()},
+			BytesV:   vtxBytes,
+		}
+	}
+	vtx0 := newVtx(1, choices.Unknown, 1, gVtx)
+	vtx1 := newVtx(2, choices.Unknown, 1, gVtx)
+	vtx2 := newVtx(3, choices.Processing, 2, vtx0, vtx1)
+
+	te := &Transitive{}
+	if err := te.Initialize(config); err != nil {
+		t.Fatal(err)
+	}
+
+	manager.ParseF = func(b []byte) (avalanche.Vertex, error) {
+		for _, vtx := range []*avalanche.TestVertex{vtx0, vtx1, vtx2} {
+			if bytes.Equal(b, vtx.Bytes()) {
+				if vtx.StatusV == choices.Unknown {
+					vtx.StatusV = choices.Processing
+				}
+				return vtx, nil
+			}
+		}
+		t.Fatalf("Unknown vertex")
+		panic("Should have failed")
+	}
+	manager.GetF = func(vtxID ids.ID) (avalanche.Vertex, error) {
+		for _, vtx := range []*avalanche.TestVertex{gVtx, vtx0, vtx1, vtx2} {
+			if vtxID == vtx.ID() && vtx.StatusV.Fetched() {
+				return vtx, nil
+			}
+		}
+		return nil, errUnknownVertex
+	}
+	sender.GetF = func(ids.ShortID, uint32, ids.ID) {
+		t.Fatalf("Shouldn't have requested each missing parent")
+	}
+	reqID := new(uint32)
+	sender.GetAncestorsF = func(reqVdr ids.ShortID, requestID uint32, vtxID ids.ID) {
+		*reqID = requestID
+		switch {
+		case reqVdr != vdr:
+			t.Fatalf("Wrong validator requested")
+		case vtxID != vtx2.ID():
+			t.Fatalf("Should have requested the ancestry of the vertex")
+		}
+	}
+	sender.CantPushQuery = false
+
+	if err := te.Put(vdr, 0, vtx2.ID(), vtx2.Bytes()); err != nil {
+		t.Fatal(err)
+	}
+	if *reqID == 0 {
+		t.Fatalf("Should have requested the missing ancestry")
+	}
+
+	// A response to a different request is ignored
+	if err := te.MultiPut(vdr, *reqID+1, [][]byte{vtx2.Bytes(), vtx0.Bytes(), vtx1.Bytes()}); err != nil {
+		t.Fatal(err)
+	}
+	if te.Consensus.VertexIssued(vtx2) {
+		t.Fatalf("Shouldn't have issued the vertex")
+	}
+
+	if err := te.MultiPut(vdr, *reqID, [][]byte{vtx2.Bytes(), vtx0.Bytes(), vtx1.Bytes()}); err != nil {
+		t.Fatal(err)
+	}
+	switch {
+	case !te.Consensus.VertexIssued(vtx0), !te.Consensus.VertexIssued(vtx1):
+		t.Fatalf("Should have issued the fetched parents")
+	case !te.Consensus.VertexIssued(vtx2):
+		t.Fatalf("Should have issued the vertex")
+	case len(te.ancestorsReqs) != 0, te.ancestorsVtxIDs.Len() != 0:
+		t.Fatalf("Shouldn't have any outstanding GetAncestors requests")
+	}
+}
+
+func TestEngineGetAncestorsFailedFallsBackToGet(t *testing.T) {
+	config := DefaultConfig()
+
+	vals := validators.NewSet()
+	config.Validators = vals
+
+	vdr := ids.GenerateTestShortID()
+	if err := vals.AddWeight(vdr, 1); err != nil {
+		t.Fatal(err)
+	}
+
+	sender := &common.SenderTest{}
+	sender.T = t
+	config.Sender = sender
+
+	manager := vertex.NewTestManager(t)
+	config.Manager = manager
+
+	gVtx := &avalanche.TestVertex{
+		TestDecidable: choices.TestDecidable{
+			IDV:     ids.GenerateTestID(),
+			StatusV: choices.Accepted,
+		},
+	}
+	vtx0 := &avalanche.TestVertex{
+		TestDecidable: choices.TestDecidable{
+			IDV:     ids.GenerateTestID(),
+			StatusV: choices.Unknown,
+		},
+		ParentsV: []avalanche.Vertex{gVtx},
+		HeightV:  1,
+	}
+	vtx1 := &avalanche.TestVertex{
+		TestDecidable: choices.TestDecidable{
+			IDV:     ids.GenerateTestID(),
+			StatusV: choices.Unknown,
+		},
+		ParentsV: []avalanche.Vertex{gVtx},
+		HeightV:  1,
+	}
+	vtx2 := &avalanche.TestVertex{
+		TestDecidable: choices.TestDecidable{
+			IDV:     ids.GenerateTestID(),
+			StatusV: choices.Processing,
+		},
+		ParentsV: []avalanche.Vertex{vtx0, vtx1},
+		HeightV:  2,
+		BytesV:   []byte{2},
+	}
+
+	te := &Transitive{}
+	if err := te.Initialize(config); err != nil {
+		t.Fatal(err)
+	}
+
+	manager.ParseF = func(b []byte) (avalanche.Vertex, error) {
+		if bytes.Equal(b, vtx2.Bytes()) {
+			return vtx2, nil
+		}
+		t.Fatalf("Unknown vertex")
+		panic("Should have failed")
+	}
+	manager.GetF = func(vtxID ids.ID) (avalanche.Vertex, error) {
+		switch vtxID {
+		case gVtx.ID():
+			return gVtx, nil
+		case vtx2.ID():
+			return vtx2, nil
+		}
+		return nil, errUnknownVertex
+	}
+	reqID := new(uint32)
+	sender.GetAncestorsF = func(_ ids.ShortID, requestID uint32, _ ids.ID) {
+		*reqID = requestID
+	}
+
+	if err := te.Put(vdr, 0, vtx2.ID(), vtx2.Bytes()); err != nil {
+		t.Fatal(err)
+	}
+
+	requested := ids.Set{}
+	sender.GetF = func(reqVdr ids.ShortID, _ uint32, vtxID ids.ID) {
+		if reqVdr != vdr {
+			t.Fatalf("Wrong validator requested")
+		}
+		requested.Add(vtxID)
+	}
+	if err := te.GetAncestorsFailed(vdr, *reqID); err != nil {
+		t.Fatal(err)
+	}
+	switch {
+	case requested.Len() != 2, !requested.Contains(vtx0.ID()), !requested.Contains(vtx1.ID()):
+		t.Fatalf("Should have requested each missing parent")
+	case len(te.ancestorsReqs) != 0:
+		t.Fatalf("Shouldn't have any outstanding GetAncestors requests")
+	}
+}
+
+type testDelayedTx struct {
+	*snowstorm.TestTx
+	notBefore time.Time
+}
+
+func (tx *testDelayedTx) NotBefore() time.Time { return tx.notBefore }
+
+func TestDelayedQueue(t *testing.T) {
+	q := delayedQueue{}
+	q.clock.Set(time.Unix(10, 0))
+
+	newTx := func(notBefore int64) *testDelayedTx {
+		return &testDelayedTx{
+			TestTx: &snowstorm.TestTx{TestDecidable: choices.TestDecidable{
+				IDV:     ids.GenerateTestID(),
+				StatusV: choices.Processing,
+			}},
+			notBefore: time.Unix(notBefore, 0),
+		}
+	}
+	plainTx := &snowstorm.TestTx{TestDecidable: choices.TestDecidable{
+		IDV:     ids.GenerateTestID(),
+		StatusV: choices.Processing,
+	}}
+	pastTx, laterTx, soonTx := newTx(5), newTx(30), newTx(20)
+
+	ready, numDropped := q.Split([]snowstorm.Tx{plainTx, laterTx, pastTx, soonTx, laterTx})
+	if numDropped != 0 {
+		t.Fatalf("shouldn't have dropped any txs")
+	}
+	if len(ready) != 2 || ready[0] != plainTx || ready[1] != pastTx {
+		t.Fatalf("only the txs without a delay, or whose delay has passed, should be ready")
+	}
+	if q.Len() != 2 {
+		t.Fatalf("should have held 2 txs, but held %d", q.Len())
+	}
+	if q.HasReady() {
+		t.Fatalf("no held txs should be ready yet")
+	}
+
+	q.clock.Set(time.Unix(30, 0))
+	ready = q.Ready()
+	if len(ready) != 2 || ready[0] != soonTx || ready[1] != laterTx {
+		t.Fatalf("held txs should be ready in the order they became issuable")
+	}
+	if q.Len() != 0 {
+		t.Fatalf("shouldn't hold any txs after they're ready")
+	}
+
+	for i := 0; i < maxDelayedTxs; i++ {
+		if _, numDropped := q.Split([]snowstorm.Tx{newTx(60)}); numDropped != 0 {
+			t.Fatalf("shouldn't have dropped a tx before the limit was reached")
+		}
+	}
+	if _, numDropped := q.Split([]snowstorm.Tx{newTx(60)}); numDropped != 1 {
+		t.Fatalf("should have dropped the tx beyond the limit")
+	}
+}
+
+func TestEngineIssuesDelayedTxs(t *testing.T) {
+	config := DefaultConfig()
+	config.Params.BatchSize = 1
+
+	sender := &common.SenderTest{}
+	sender.T = t
+	config.Sender = sender
+
+	sender.Default(true)
+	sender.CantGetAcceptedFrontier = false
+
+	vals := validators.NewSet()
+	config.Validators = vals
+
+	vdr := ids.GenerateTestShortID()
+	if err := vals.AddWeight(vdr, 1); err != nil {
+		t.Fatal(err)
+	}
+
+	manager := vertex.NewTestManager(t)
+	config.Manager = manager
+
+	manager.Default(true)
+
+	vm := &vertex.TestVM{}
+	vm.T = t
+	config.VM = vm
+
+	vm.Default(true)
+
+	gVtx := &avalanche.TestVertex{
+		TestDecidable: choices.TestDecidable{
+			IDV:     ids.GenerateTestID(),
+			StatusV: choices.Accepted,
+		},
+		BytesV: []byte{0},
+	}
+
+	tx := &testDelayedTx{
+		TestTx: &snowstorm.TestTx{TestDecidable: choices.TestDecidable{
+			IDV:     ids.GenerateTestID(),
+			StatusV: choices.Processing,
+		}},
+		notBefore: time.Unix(100, 0),
+	}
+	tx.InputIDsV = append(tx.InputIDsV, ids.GenerateTestID())
+
+	manager.EdgeF = func() []ids.ID { return []ids.ID{gVtx.ID()} }
+	manager.GetF = func(id ids.ID) (avalanche.Vertex, error) {
+		if id == gVtx.ID() {
+			return gVtx, nil
+		}
+		t.Fatalf("Unknown vertex")
+		panic("Should have errored")
+	}
+
+	vm.CantBootstrapping = false
+	vm.CantBootstrapped = false
+
+	te := &Transitive{}
+	if err := te.Initialize(config); err != nil {
+		t.Fatal(err)
+	}
+	te.delayed.clock.Set(time.Unix(0, 0))
+
+	vm.CantBootstrapping = true
+	vm.CantBootstrapped = true
+
+	manager.BuildF = func(uint32, []ids.ID, []snowstorm.Tx, []ids.ID) (avalanche.Vertex, error) {
+		t.Fatalf("shouldn't have issued the tx before the time the VM allows")
+		return nil, nil
+	}
+	vm.PendingF = func() []snowstorm.Tx { return []snowstorm.Tx{tx} }
+	if err := te.Notify(common.PendingTxs); err != nil {
+		t.Fatal(err)
+	}
+	if held := testutil.ToFloat64(te.numDelayedTxs); held != 1 {
+		t.Fatalf("should have reported 1 delayed tx, but reported %f", held)
+	}
+
+	sender.CantGossip = false
+	if err := te.Gossip(); err != nil {
+		t.Fatal(err)
+	}
+
+	// Once the VM allows it, gossip issues the delayed tx without the VM
+	// notifying the engine again
+	te.delayed.clock.Set(tx.notBefore)
+
+	var builtTxs []snowstorm.Tx
+	manager.BuildF = func(_ uint32, _ []ids.ID, txs []snowstorm.Tx, _ []ids.ID) (avalanche.Vertex, error) {
+		builtTxs = txs
+		return &avalanche.TestVertex{
+			TestDecidable: choices.TestDecidable{
+				IDV:     ids.GenerateTestID(),
+				StatusV: choices.Processing,
+			},
+			ParentsV: []avalanche.Vertex{gVtx},
+			HeightV:  1,
+			TxsV:     txs,
+			BytesV:   []byte{1},
+		}, nil
+	}
+	sender.CantPushQuery = false
+
+	if err := te.Gossip(); err != nil {
+		t.Fatal(err)
+	}
+	if len(builtTxs) != 1 || builtTxs[0].ID() != tx.ID() {
+		t.Fatalf("should have issued the delayed tx")
+	}
+	if held := testutil.ToFloat64(te.numDelayedTxs); held != 0 {
+		t.Fatalf("should have reported 0 delayed txs, but reported %f", held)
+	}
+}
+
+func TestEngineRestoresProcessingVertices(t *testing.T) {
+	db := memdb.New()
+
+	gVtx := &avalanche.TestVertex{
+		TestDecidable: choices.TestDecidable{
+			IDV:     ids.GenerateTestID(),
+			StatusV: choices.Accepted,
+		},
+	}
+	newVtx := func(b byte) *avalanche.TestVertex {
+		tx := &snowstorm.TestTx{TestDecidable: choices.TestDecidable{
+			IDV:     ids.GenerateTestID(),
+			StatusV: choices.Processing,
+		}}
+		tx.InputIDsV = append(tx.InputIDsV, ids.GenerateTestID())
+		return &avalanche.TestVertex{
+			TestDecidable: choices.TestDecidable{
+				IDV:     ids.GenerateTestID(),
+				StatusV: choices.Processing,
+			},
+			ParentsV: []avalanche.Vertex{gVtx},
+			HeightV:  1,
+			TxsV:     []snowstorm.Tx{tx},
+			BytesV:   []byte{b},
+		}
+	}
+	processingVtx := newVtx(1)
+	decidedVtx := newVtx(2)
+	vtxs := []*avalanche.TestVertex{gVtx, processingVtx, decidedVtx}
+
+	vdr := ids.GenerateTestShortID()
+	newEngine := func() *Transitive {
+		config := DefaultConfig()
+		config.Params.Metrics = prometheus.NewRegistry()
+		config.ProcessingDB = db
+
+		vals := validators.NewSet()
+		config.Validators = vals
+		assert.NoError(t, vals.AddWeight(vdr, 1))
+
+		sender := &common.SenderTest{}
+		sender.T = t
+		sender.Default(true)
+		sender.CantPushQuery = false
+		config.Sender = sender
+
+		manager := vertex.NewTestManager(t)
+		manager.CantEdge = false
+		manager.GetF = func(vtxID ids.ID) (avalanche.Vertex, error) {
+			for _, vtx := range vtxs {
+				if vtx.ID() == vtxID {
+					return vtx, nil
+				}
+			}
+			return nil, errUnknownVertex
+		}
+		manager.ParseF = func(b []byte) (avalanche.Vertex, error) {
+			for _, vtx := range vtxs {
+				if bytes.Equal(vtx.Bytes(), b) {
+					return vtx, nil
+				}
+			}
+			return nil, errFailedParsing
+		}
+		config.Manager = manager
+
+		vm := &vertex.TestVM{}
+		vm.T = t
+		vm.ShutdownF = func() error { return nil }
+		config.VM = vm
+
+		te := &Transitive{}
+		assert.NoError(t, te.Initialize(config))
+		return te
+	}
+
+	te := newEngine()
+	for _, vtx := range []avalanche.Vertex{processingVtx, decidedVtx} {
+		assert.NoError(t, te.Put(vdr, 0, vtx.ID(), vtx.Bytes()))
+		assert.True(t, te.Consensus.VertexIssued(vtx))
+	}
+	decidedVtx.StatusV = choices.Accepted
+	assert.NoError(t, te.Shutdown())
+
+	// Only the vertex that was still processing is persisted
+	persisted, err := db.Get(processingVtx.IDV[:])
+	assert.NoError(t, err)
+	assert.Equal(t, processingVtx.Bytes(), persisted)
+	has, err := db.Has(decidedVtx.IDV[:])
+	assert.NoError(t, err)
+	assert.False(t, has)
+
+	// The restarted engine issues the persisted vertex without fetching it
+	te = newEngine()
+	assert.True(t, te.Consensus.VertexIssued(processingVtx))
+	assert.True(t, te.processing.Contains(processingVtx.ID()))
+}
+
+func TestEngineHealthCheckReportsLiveness(t *testing.T) {
+	config := DefaultConfig()
+
+	manager := vertex.NewTestManager(t)
+	manager.CantEdge = false
+	config.Manager = manager
+
+	vm := &vertex.TestVM{}
+	vm.T = t
+	vm.HealthCheckF = func() (interface{}, error) { return nil, nil }
+	config.VM = vm
 
 	te := &Transitive{}
 	if err := te.Initialize(config); err != nil {
 		t.Fatal(err)
 	}
 
-	vm.CantBootstrapping = true
-	vm.CantBootstrapped = true
+	now := time.Unix(100, 0)
+	te.liveness.clock.Set(now)
+	te.liveness.Accepted()
 
-	parsed := new(bool)
-	manager.ParseF = func(b []byte) (avalanche.Vertex, error) {
-		if bytes.Equal(b, vtx.Bytes()) {
-			*parsed = true
-			return vtx, nil
-		}
-		return nil, errUnknownVertex
+	if err := te.Chits(ids.GenerateTestShortID(), 1, nil); err != nil {
+		t.Fatal(err)
 	}
-
-	manager.GetF = func(vtxID ids.ID) (avalanche.Vertex, error) {
-		if !*parsed {
-			return nil, errUnknownVertex
-		}
-
-		if vtxID == vtx.ID() {
-			return vtx, nil
-		}
-		return nil, errUnknownVertex
+	if err := te.QueryFailed(ids.GenerateTestShortID(), 2); err != nil {
+		t.Fatal(err)
 	}
+	te.liveness.clock.Set(now.Add(time.Minute))
 
-	numPushQueries := new(int)
-	sender.PushQueryF = func(ids.ShortSet, uint32, ids.ID, []byte) { *numPushQueries++ }
+	intf, err := te.HealthCheck()
+	assert.NoError(t, err)
+	details := intf.(map[string]interface{})["engine"].(map[string]interface{})
+	assert.Equal(t, 0, details["outstandingPolls"])
+	assert.Equal(t, 0, details["blockedVertices"])
+	assert.Equal(t, now, details["lastAccepted"])
+	assert.Equal(t, time.Minute.String(), details["timeSinceLastAccepted"])
+	answered := details["queriesAnsweredPercentage"].(float64)
+	assert.True(t, answered > 0 && answered < 100, "should have reported that only some queries were answered")
+}
 
-	numPullQueries := new(int)
-	sender.PullQueryF = func(ids.ShortSet, uint32, ids.ID) { *numPullQueries++ }
+func TestRepollLimitAdapts(t *testing.T) {
+	l := repollLimit{}
+	l.clock.Set(time.Unix(0, 0))
+	l.Initialize(1, 3)
 
-	vm.CantPending = false
+	if limit := l.Limit(); limit != 1 {
+		t.Fatalf("limit should start at the minimum, but is %d", limit)
+	}
+	for i := 0; i < 5; i++ {
+		l.QueryFinished(false)
+	}
+	if limit := l.Limit(); limit != 3 {
+		t.Fatalf("limit should have risen to the maximum while queries failed, but is %d", limit)
+	}
 
-	if err := te.Put(vdr, 0, vtx.ID(), vtx.Bytes()); err != nil {
-		t.Fatal(err)
+	for i := 0; i < 20; i++ {
+		l.clock.Set(l.clock.Time().Add(repollFailureHalflife))
+		l.QueryFinished(true)
+	}
+	if limit := l.Limit(); limit != 1 {
+		t.Fatalf("limit should have fallen to the minimum once queries succeeded, but is %d", limit)
 	}
 
-	if *numPushQueries != 1 {
-		t.Fatalf("should have issued one push query")
+	l.QueryFinished(false)
+	if limit := l.Limit(); limit != 2 {
+		t.Fatalf("limit should have risen after a query failed, but is %d", limit)
 	}
-	if *numPullQueries != 2 {
-		t.Fatalf("should have issued two pull queries")
+	l.Quiesced()
+	if limit := l.Limit(); limit != 1 {
+		t.Fatalf("limit should have been reset once consensus quiesced, but is %d", limit)
 	}
 }
 
-func TestEngineDuplicatedIssuance(t *testing.T) {
+func TestRepollLimitFixed(t *testing.T) {
+	l := repollLimit{}
+	l.clock.Set(time.Unix(0, 0))
+	l.Initialize(2, 0)
+
+	for i := 0; i < 5; i++ {
+		l.QueryFinished(false)
+	}
+	if limit := l.Limit(); limit != 2 {
+		t.Fatalf("limit shouldn't adapt without a greater maximum, but is %d", limit)
+	}
+}
+
+func TestEngineIssuesHeartbeatPolls(t *testing.T) {
 	config := DefaultConfig()
 	config.Params.BatchSize = 1
-	config.Params.BetaVirtuous = 5
-	config.Params.BetaRogue = 5
+	config.HeartbeatFrequency = time.Minute
 
 	sender := &common.SenderTest{}
 	sender.T = t
@@ -3710,38 +6507,38 @@ func TestEngineDuplicatedIssuance(t *testing.T) {
 
 	vm.Default(true)
 
-	gVtx := &avalanche.TestVertex{TestDecidable: choices.TestDecidable{
-		IDV:     ids.GenerateTestID(),
-		StatusV: choices.Accepted,
-	}}
-	mVtx := &avalanche.TestVertex{TestDecidable: choices.TestDecidable{
-		IDV:     ids.GenerateTestID(),
-		StatusV: choices.Accepted,
-	}}
+	gVtx := &avalanche.TestVertex{
+		TestDecidable: choices.TestDecidable{
+			IDV:     ids.GenerateTestID(),
+			StatusV: choices.Accepted,
+		},
+		BytesV: []byte{0},
+	}
 
-	gTx := &snowstorm.TestTx{TestDecidable: choices.TestDecidable{
+	tx := &snowstorm.TestTx{TestDecidable: choices.TestDecidable{
 		IDV:     ids.GenerateTestID(),
-		StatusV: choices.Accepted,
+		StatusV: choices.Processing,
 	}}
+	tx.InputIDsV = append(tx.InputIDsV, ids.GenerateTestID())
 
-	utxos := []ids.ID{ids.GenerateTestID(), ids.GenerateTestID()}
-
-	tx := &snowstorm.TestTx{
+	vtx := &avalanche.TestVertex{
 		TestDecidable: choices.TestDecidable{
 			IDV:     ids.GenerateTestID(),
 			StatusV: choices.Processing,
 		},
-		DependenciesV: []snowstorm.Tx{gTx},
+		ParentsV: []avalanche.Vertex{gVtx},
+		HeightV:  1,
+		TxsV:     []snowstorm.Tx{tx},
+		BytesV:   []byte{1},
 	}
-	tx.InputIDsV = append(tx.InputIDsV, utxos[0])
 
-	manager.EdgeF = func() []ids.ID { return []ids.ID{gVtx.ID(), mVtx.ID()} }
+	manager.EdgeF = func() []ids.ID { return []ids.ID{gVtx.ID()} }
 	manager.GetF = func(id ids.ID) (avalanche.Vertex, error) {
 		switch id {
 		case gVtx.ID():
 			return gVtx, nil
-		case mVtx.ID():
-			return mVtx, nil
+		case vtx.ID():
+			return vtx, nil
 		}
 		t.Fatalf("Unknown vertex")
 		panic("Should have errored")
@@ -3754,116 +6551,120 @@ func TestEngineDuplicatedIssuance(t *testing.T) {
 	if err := te.Initialize(config); err != nil {
 		t.Fatal(err)
 	}
+	te.heartbeat.clock.Set(time.Unix(0, 0))
 
 	vm.CantBootstrapping = true
 	vm.CantBootstrapped = true
 
-	lastVtx := new(avalanche.TestVertex)
-	manager.BuildF = func(_ uint32, _ []ids.ID, txs []snowstorm.Tx, _ []ids.ID) (avalanche.Vertex, error) {
-		lastVtx = &avalanche.TestVertex{
-			TestDecidable: choices.TestDecidable{
-				IDV:     ids.GenerateTestID(),
-				StatusV: choices.Processing,
-			},
-			ParentsV: []avalanche.Vertex{gVtx, mVtx},
-			HeightV:  1,
-			TxsV:     txs,
-			BytesV:   []byte{1},
-		}
-		return lastVtx, nil
+	manager.BuildF = func(uint32, []ids.ID, []snowstorm.Tx, []ids.ID) (avalanche.Vertex, error) {
+		return vtx, nil
+	}
+	reqID := new(uint32)
+	sender.PushQueryF = func(_ ids.ShortSet, requestID uint32, _ ids.ID, _ []byte) {
+		*reqID = requestID
 	}
-
-	sender.CantPushQuery = false
-
 	vm.PendingF = func() []snowstorm.Tx { return []snowstorm.Tx{tx} }
 	if err := te.Notify(common.PendingTxs); err != nil {
 		t.Fatal(err)
 	}
 
-	if len(lastVtx.TxsV) != 1 || lastVtx.TxsV[0].ID() != tx.ID() {
-		t.Fatalf("Should have issued txs differently")
+	// The re-poll after the query fails is dropped, as there are no validators
+	// to sample, so no polls are outstanding
+	if err := vals.RemoveWeight(vdr, 1); err != nil {
+		t.Fatal(err)
+	}
+	if err := te.QueryFailed(vdr, *reqID); err != nil {
+		t.Fatal(err)
+	}
+	if te.polls.Len() != 0 {
+		t.Fatalf("shouldn't have any outstanding polls")
+	}
+	if err := vals.AddWeight(vdr, 1); err != nil {
+		t.Fatal(err)
 	}
 
-	manager.BuildF = func(uint32, []ids.ID, []snowstorm.Tx, []ids.ID) (avalanche.Vertex, error) {
-		t.Fatalf("shouldn't have attempted to issue a duplicated tx")
-		return nil, nil
+	sender.CantGossip = false
+	te.heartbeat.clock.Set(time.Unix(0, 0).Add(config.HeartbeatFrequency - 1))
+	if err := te.Gossip(); err != nil {
+		t.Fatal(err)
 	}
 
-	if err := te.Notify(common.PendingTxs); err != nil {
+	polled := false
+	sender.PullQueryF = func(_ ids.ShortSet, _ uint32, vtxID ids.ID) {
+		if vtxID != vtx.ID() {
+			t.Fatalf("should have re-polled the preferred vertex")
+		}
+		polled = true
+	}
+	te.heartbeat.clock.Set(time.Unix(0, 0).Add(config.HeartbeatFrequency))
+	if err := te.Gossip(); err != nil {
 		t.Fatal(err)
 	}
+	if !polled {
+		t.Fatalf("should have issued a heartbeat poll")
+	}
+	if heartbeats := testutil.ToFloat64(te.heartbeatPolls); heartbeats != 1 {
+		t.Fatalf("should have reported 1 heartbeat, but reported %f", heartbeats)
+	}
 }
 
-func TestEngineDoubleChit(t *testing.T) {
+func TestEngineRetriesUnverifiedVertex(t *testing.T) {
 	config := DefaultConfig()
 
-	config.Params.Alpha = 2
-	config.Params.K = 2
-
 	vals := validators.NewSet()
 	config.Validators = vals
 
-	vdr0 := ids.GenerateTestShortID()
-	vdr1 := ids.GenerateTestShortID()
-
-	if err := vals.AddWeight(vdr0, 1); err != nil {
-		t.Fatal(err)
-	}
-	if err := vals.AddWeight(vdr1, 1); err != nil {
+	vdr := ids.GenerateTestShortID()
+	if err := vals.AddWeight(vdr, 1); err != nil {
 		t.Fatal(err)
 	}
 
-	sender := &common.SenderTest{}
-	sender.T = t
-	config.Sender = sender
-
-	sender.Default(true)
-	sender.CantGetAcceptedFrontier = false
-
 	manager := vertex.NewTestManager(t)
 	config.Manager = manager
 
-	manager.Default(true)
+	vm := &vertex.TestVM{}
+	vm.T = t
+	config.VM = vm
 
 	gVtx := &avalanche.TestVertex{TestDecidable: choices.TestDecidable{
 		IDV:     ids.GenerateTestID(),
 		StatusV: choices.Accepted,
 	}}
-	mVtx := &avalanche.TestVertex{TestDecidable: choices.TestDecidable{
-		IDV:     ids.GenerateTestID(),
-		StatusV: choices.Accepted,
-	}}
 
-	vts := []avalanche.Vertex{gVtx, mVtx}
-	utxos := []ids.ID{ids.GenerateTestID()}
-
-	tx := &snowstorm.TestTx{TestDecidable: choices.TestDecidable{
+	tx0 := &snowstorm.TestTx{TestDecidable: choices.TestDecidable{
 		IDV:     ids.GenerateTestID(),
 		StatusV: choices.Processing,
 	}}
-	tx.InputIDsV = append(tx.InputIDsV, utxos[0])
+	tx0.InputIDsV = append(tx0.InputIDsV, ids.GenerateTestID())
 
-	vtx := &avalanche.TestVertex{
+	tx1 := &snowstorm.TestTx{
 		TestDecidable: choices.TestDecidable{
 			IDV:     ids.GenerateTestID(),
 			StatusV: choices.Processing,
 		},
-		ParentsV: vts,
-		HeightV:  1,
-		TxsV:     []snowstorm.Tx{tx},
-		BytesV:   []byte{1, 1, 2, 3},
+		VerifyV: fmt.Errorf("couldn't verify: %w", &snowstorm.MissingDependenciesError{
+			TxIDs: []ids.ID{tx0.ID()},
+		}),
 	}
+	tx1.InputIDsV = append(tx1.InputIDsV, ids.GenerateTestID())
 
-	manager.EdgeF = func() []ids.ID { return []ids.ID{vts[0].ID(), vts[1].ID()} }
-	manager.GetF = func(id ids.ID) (avalanche.Vertex, error) {
-		switch id {
-		case gVtx.ID():
-			return gVtx, nil
-		case mVtx.ID():
-			return mVtx, nil
-		}
-		t.Fatalf("Unknown vertex")
-		panic("Should have errored")
+	vtx0 := &avalanche.TestVertex{
+		TestDecidable: choices.TestDecidable{
+			IDV:     ids.GenerateTestID(),
+			StatusV: choices.Processing,
+		},
+		ParentsV: []avalanche.Vertex{gVtx},
+		HeightV:  1,
+		TxsV:     []snowstorm.Tx{tx0},
+	}
+	vtx1 := &avalanche.TestVertex{
+		TestDecidable: choices.TestDecidable{
+			IDV:     ids.GenerateTestID(),
+			StatusV: choices.Processing,
+		},
+		ParentsV: []avalanche.Vertex{gVtx},
+		HeightV:  1,
+		TxsV:     []snowstorm.Tx{tx1},
 	}
 
 	te := &Transitive{}
@@ -3871,68 +6672,185 @@ func TestEngineDoubleChit(t *testing.T) {
 		t.Fatal(err)
 	}
 
+	sender := &common.SenderTest{}
+	sender.T = t
+	te.Sender = sender
+
 	reqID := new(uint32)
-	sender.PushQueryF = func(inVdrs ids.ShortSet, requestID uint32, vtxID ids.ID, _ []byte) {
+	sender.PushQueryF = func(_ ids.ShortSet, requestID uint32, _ ids.ID, _ []byte) {
 		*reqID = requestID
-		if inVdrs.Len() != 2 {
-			t.Fatalf("Wrong number of validators")
+	}
+	if err := te.issue(vtx0); err != nil {
+		t.Fatal(err)
+	}
+
+	sender.PushQueryF = func(ids.ShortSet, uint32, ids.ID, []byte) {
+		t.Fatalf("should have failed verification")
+	}
+	if err := te.issue(vtx1); err != nil {
+		t.Fatal(err)
+	}
+	if te.Consensus.VertexIssued(vtx1) {
+		t.Fatalf("shouldn't have issued the vertex with missing dependencies")
+	}
+	if waiting := testutil.ToFloat64(te.numUnverifiedVtxs); waiting != 1 {
+		t.Fatalf("should have reported 1 unverified vertex, but reported %f", waiting)
+	}
+
+	manager.GetF = func(vtxID ids.ID) (avalanche.Vertex, error) {
+		switch vtxID {
+		case vtx0.ID():
+			return vtx0, nil
+		case vtx1.ID():
+			return vtx1, nil
 		}
-		if vtxID != vtx.ID() {
-			t.Fatalf("Wrong vertex requested")
+		return nil, errUnknownVertex
+	}
+	vm.GetF = func(txID ids.ID) (snowstorm.Tx, error) {
+		if txID == tx0.ID() {
+			return tx0, nil
 		}
+		return nil, errMissing
 	}
-	manager.GetF = func(id ids.ID) (avalanche.Vertex, error) {
-		if id == vtx.ID() {
-			return vtx, nil
+
+	// Once the dependency is accepted, the vertex is issued again
+	tx1.VerifyV = nil
+	retried := false
+	sender.PushQueryF = func(_ ids.ShortSet, _ uint32, vtxID ids.ID, _ []byte) {
+		if vtxID != vtx1.ID() {
+			t.Fatalf("should have retried the unverified vertex")
 		}
-		t.Fatalf("Unknown vertex")
-		panic("Should have errored")
+		retried = true
+	}
+	if err := te.Chits(vdr, *reqID, []ids.ID{vtx0.ID()}); err != nil {
+		t.Fatal(err)
+	}
+	if status := tx0.Status(); status != choices.Accepted {
+		t.Fatalf("should have accepted the dependency")
 	}
+	if !retried || !te.Consensus.VertexIssued(vtx1) {
+		t.Fatalf("should have issued the vertex once its dependency was accepted")
+	}
+	if waiting := testutil.ToFloat64(te.numUnverifiedVtxs); waiting != 0 {
+		t.Fatalf("should have reported 0 unverified vertices, but reported %f", waiting)
+	}
+	if retries := testutil.ToFloat64(te.retriedUnverifiedVtxs); retries != 1 {
+		t.Fatalf("should have reported 1 retried vertex, but reported %f", retries)
+	}
+}
+
+func TestUnverifiedVtxs(t *testing.T) {
+	u := unverifiedVtxs{}
+	vtxID0 := ids.GenerateTestID()
+	vtxID1 := ids.GenerateTestID()
+	txID0 := ids.GenerateTestID()
+	txID1 := ids.GenerateTestID()
+
+	assert.True(t, u.Add(vtxID0, []ids.ID{txID0, txID1}, 2))
+	assert.True(t, u.Add(vtxID1, []ids.ID{txID1}, 2))
+	assert.False(t, u.Add(ids.GenerateTestID(), []ids.ID{txID0}, 2), "should have dropped the vertex beyond the limit")
+	assert.Equal(t, 2, u.Len())
+	assert.Len(t, u.Dependencies(), 2)
+
+	assert.Empty(t, u.Accept(txID0), "vertex should still be waiting on its other dependency")
+	assert.ElementsMatch(t, []ids.ID{vtxID0, vtxID1}, u.Reject(txID1), "should have dropped the vertices waiting on the rejected tx")
+	assert.Equal(t, 0, u.Len())
+	assert.Empty(t, u.Dependencies())
+
+	assert.True(t, u.Add(vtxID0, []ids.ID{txID0}, 2))
+	assert.Equal(t, []ids.ID{vtxID0}, u.Accept(txID0), "vertex should be ready once its dependencies are accepted")
+	assert.Equal(t, 0, u.Len())
+	assert.Empty(t, u.Dependencies())
+}
+
+// benchlistTest records the validators that were benched
+type benchlistTest struct {
+	benchlist.Manager
+
+	benched map[ids.ShortID]time.Duration
+}
+
+func (b *benchlistTest) Bench(_ ids.ID, vdr ids.ShortID, duration time.Duration) {
+	b.benched[vdr] = duration
+}
+
+func TestEngineBenchesMisbehavingValidator(t *testing.T) {
+	config := DefaultConfig()
+
+	vals := validators.NewSet()
+	config.Validators = vals
 
-	if err := te.issue(vtx); err != nil {
+	vdr := ids.GenerateTestShortID()
+	if err := vals.AddWeight(vdr, 1); err != nil {
 		t.Fatal(err)
 	}
 
-	votes := []ids.ID{vtx.ID()}
+	sender := &common.SenderTest{}
+	sender.T = t
+	config.Sender = sender
 
-	if status := tx.Status(); status != choices.Processing {
-		t.Fatalf("Wrong tx status: %s ; expected: %s", status, choices.Processing)
-	}
+	sender.Default(true)
+	sender.CantGetAcceptedFrontier = false
 
-	if err := te.Chits(vdr0, *reqID, votes); err != nil {
-		t.Fatal(err)
-	}
+	manager := vertex.NewTestManager(t)
+	config.Manager = manager
 
-	if status := tx.Status(); status != choices.Processing {
-		t.Fatalf("Wrong tx status: %s ; expected: %s", status, choices.Processing)
+	manager.Default(true)
+	manager.CantEdge = false
+
+	bench := &benchlistTest{
+		Manager: benchlist.NewNoBenchlist(),
+		benched: make(map[ids.ShortID]time.Duration),
 	}
+	config.Benchlist = bench
 
-	if err := te.Chits(vdr0, *reqID, votes); err != nil {
+	te := &Transitive{}
+	if err := te.Initialize(config); err != nil {
 		t.Fatal(err)
 	}
+	now := time.Now()
+	te.misbehavior.clock.Set(now)
 
-	if status := tx.Status(); status != choices.Processing {
-		t.Fatalf("Wrong tx status: %s ; expected: %s", status, choices.Processing)
+	manager.ParseF = func(b []byte) (avalanche.Vertex, error) { return nil, errFailedParsing }
+	for i := 0; i < misbehaviorThreshold-1; i++ {
+		if err := te.PushQuery(vdr, uint32(i), ids.GenerateTestID(), []byte{1}); err != nil {
+			t.Fatal(err)
+		}
 	}
+	assert.Equal(t, misbehaviorThreshold-1, te.misbehavior.Offenses(vdr))
+	assert.Empty(t, bench.benched, "shouldn't have benched the validator before it reached the threshold")
 
-	if err := te.Chits(vdr1, *reqID, votes); err != nil {
+	// Offenses outside of the window are forgotten
+	now = now.Add(misbehaviorWindow + time.Second)
+	te.misbehavior.clock.Set(now)
+	assert.Equal(t, 0, te.misbehavior.Offenses(vdr))
+	if err := te.PushQuery(vdr, 0, ids.GenerateTestID(), []byte{1}); err != nil {
 		t.Fatal(err)
 	}
+	assert.Equal(t, 1, te.misbehavior.Offenses(vdr))
+	assert.Empty(t, bench.benched, "shouldn't have benched the validator for old offenses")
 
-	if status := tx.Status(); status != choices.Accepted {
-		t.Fatalf("Wrong tx status: %s ; expected: %s", status, choices.Accepted)
+	for i := 1; i < misbehaviorThreshold; i++ {
+		if err := te.PushQuery(vdr, uint32(i), ids.GenerateTestID(), []byte{1}); err != nil {
+			t.Fatal(err)
+		}
 	}
+	assert.Equal(t, map[ids.ShortID]time.Duration{vdr: misbehaviorBenchDuration}, bench.benched)
+	assert.Equal(t, 0, te.misbehavior.Offenses(vdr), "should have forgotten the offenses of the benched validator")
+	assert.Equal(t, float64(2*misbehaviorThreshold-1), testutil.ToFloat64(te.numUnparseableVtxs))
+	assert.Equal(t, float64(1), testutil.ToFloat64(te.numMisbehavingBenched))
 }
 
-func TestEngineBubbleVotes(t *testing.T) {
+func TestEngineWrongRequestPut(t *testing.T) {
 	config := DefaultConfig()
 
 	vals := validators.NewSet()
 	config.Validators = vals
 
 	vdr := ids.GenerateTestShortID()
-	err := vals.AddWeight(vdr, 1)
-	assert.NoError(t, err)
+	if err := vals.AddWeight(vdr, 1); err != nil {
+		t.Fatal(err)
+	}
 
 	sender := &common.SenderTest{}
 	sender.T = t
@@ -3945,136 +6863,48 @@ func TestEngineBubbleVotes(t *testing.T) {
 	config.Manager = manager
 
 	manager.Default(true)
+	manager.CantEdge = false
 
-	utxos := []ids.ID{
-		ids.GenerateTestID(),
-		ids.GenerateTestID(),
-		ids.GenerateTestID(),
-	}
-
-	tx0 := &snowstorm.TestTx{
-		TestDecidable: choices.TestDecidable{
-			IDV:     ids.GenerateTestID(),
-			StatusV: choices.Processing,
-		},
-		InputIDsV: utxos[:1],
-	}
-	tx1 := &snowstorm.TestTx{
-		TestDecidable: choices.TestDecidable{
-			IDV:     ids.GenerateTestID(),
-			StatusV: choices.Processing,
-		},
-		InputIDsV: utxos[1:2],
-	}
-	tx2 := &snowstorm.TestTx{
-		TestDecidable: choices.TestDecidable{
-			IDV:     ids.GenerateTestID(),
-			StatusV: choices.Processing,
-		},
-		InputIDsV: utxos[1:2],
-	}
-
-	vtx := &avalanche.TestVertex{
-		TestDecidable: choices.TestDecidable{
-			IDV:     ids.GenerateTestID(),
-			StatusV: choices.Processing,
-		},
-		HeightV: 0,
-		TxsV:    []snowstorm.Tx{tx0},
-		BytesV:  []byte{0},
+	te := &Transitive{}
+	if err := te.Initialize(config); err != nil {
+		t.Fatal(err)
 	}
 
-	missingVtx := &avalanche.TestVertex{TestDecidable: choices.TestDecidable{
-		IDV:     ids.GenerateTestID(),
-		StatusV: choices.Unknown,
-	}}
-
-	pendingVtx0 := &avalanche.TestVertex{
+	requestedVtx := &avalanche.TestVertex{
 		TestDecidable: choices.TestDecidable{
 			IDV:     ids.GenerateTestID(),
-			StatusV: choices.Processing,
+			StatusV: choices.Unknown,
 		},
-		ParentsV: []avalanche.Vertex{vtx, missingVtx},
-		HeightV:  1,
-		TxsV:     []snowstorm.Tx{tx1},
-		BytesV:   []byte{1},
 	}
-
-	pendingVtx1 := &avalanche.TestVertex{
+	otherVtx := &avalanche.TestVertex{
 		TestDecidable: choices.TestDecidable{
 			IDV:     ids.GenerateTestID(),
 			StatusV: choices.Processing,
 		},
-		ParentsV: []avalanche.Vertex{pendingVtx0},
-		HeightV:  2,
-		TxsV:     []snowstorm.Tx{tx2},
-		BytesV:   []byte{2},
-	}
-
-	manager.EdgeF = func() []ids.ID { return nil }
-	manager.GetF = func(id ids.ID) (avalanche.Vertex, error) {
-		switch id {
-		case vtx.ID():
-			return vtx, nil
-		case missingVtx.ID():
-			return nil, errMissing
-		case pendingVtx0.ID():
-			return pendingVtx0, nil
-		case pendingVtx1.ID():
-			return pendingVtx1, nil
-		}
-		assert.FailNow(t, "unknown vertex", "vtxID: %s", id)
-		panic("should have errored")
+		BytesV: []byte{2},
 	}
 
-	te := &Transitive{}
-	err = te.Initialize(config)
-	assert.NoError(t, err)
-
-	queryReqID := new(uint32)
-	queried := new(bool)
-	sender.PushQueryF = func(inVdrs ids.ShortSet, requestID uint32, vtxID ids.ID, _ []byte) {
-		assert.Len(t, inVdrs, 1, "wrong number of validators")
-		*queryReqID = requestID
-		assert.Equal(t, vtx.ID(), vtxID, "wrong vertex requested")
-		*queried = true
-	}
+	reqID := new(uint32)
+	sender.GetF = func(_ ids.ShortID, requestID uint32, _ ids.ID) { *reqID = requestID }
+	te.sendRequest(vdr, requestedVtx.ID())
 
-	getReqID := new(uint32)
-	fetched := new(bool)
-	sender.GetF = func(inVdr ids.ShortID, requestID uint32, vtxID ids.ID) {
-		assert.Equal(t, vdr, inVdr, "wrong validator")
-		*getReqID = requestID
-		assert.Equal(t, missingVtx.ID(), vtxID, "wrong vertex requested")
-		*fetched = true
+	// The unrequested vertex is still issued
+	sender.CantPushQuery = false
+	manager.ParseF = func(b []byte) (avalanche.Vertex, error) { return otherVtx, nil }
+	manager.GetF = func(vtxID ids.ID) (avalanche.Vertex, error) { return nil, errUnknownVertex }
+	if err := te.Put(vdr, *reqID, requestedVtx.ID(), otherVtx.Bytes()); err != nil {
+		t.Fatal(err)
 	}
 
-	issued, err := te.issueFrom(vdr, pendingVtx1)
-	assert.NoError(t, err)
-	assert.False(t, issued, "shouldn't have been able to issue %s", pendingVtx1.ID())
-	assert.True(t, *queried, "should have queried for %s", vtx.ID())
-	assert.True(t, *fetched, "should have fetched %s", missingVtx.ID())
-
-	// can't apply votes yet because pendingVtx0 isn't issued because missingVtx
-	// is missing
-	err = te.Chits(vdr, *queryReqID, []ids.ID{pendingVtx1.ID()})
-	assert.NoError(t, err)
-	assert.Equal(t, choices.Processing, tx0.Status(), "wrong tx status")
-	assert.Equal(t, choices.Processing, tx1.Status(), "wrong tx status")
-
-	// vote for pendingVtx1 should be bubbled up to pendingVtx0 and then to vtx
-	err = te.GetFailed(vdr, *getReqID)
-	assert.NoError(t, err)
-	assert.Equal(t, choices.Accepted, tx0.Status(), "wrong tx status")
-	assert.Equal(t, choices.Processing, tx1.Status(), "wrong tx status")
+	assert.Equal(t, float64(1), testutil.ToFloat64(te.numWrongRequestPuts))
+	assert.Equal(t, 1, te.misbehavior.Offenses(vdr))
 }
 
-func TestEngineIssue(t *testing.T) {
+func TestEngineStaleVertices(t *testing.T) {
 	config := DefaultConfig()
 	config.Params.BatchSize = 1
-	config.Params.BetaVirtuous = 1
-	config.Params.BetaRogue = 1
-	config.Params.OptimalProcessing = 1
+	config.StaleVertexDepth = 2
+	config.RefuseStaleParents = true
 
 	sender := &common.SenderTest{}
 	sender.T = t
@@ -4102,46 +6932,60 @@ func TestEngineIssue(t *testing.T) {
 
 	vm.Default(true)
 
-	gVtx := &avalanche.TestVertex{TestDecidable: choices.TestDecidable{
-		IDV:     ids.GenerateTestID(),
-		StatusV: choices.Accepted,
-	}}
-	mVtx := &avalanche.TestVertex{TestDecidable: choices.TestDecidable{
-		IDV:     ids.GenerateTestID(),
-		StatusV: choices.Accepted,
-	}}
-
-	gTx := &snowstorm.TestTx{TestDecidable: choices.TestDecidable{
-		IDV:     ids.GenerateTestID(),
-		StatusV: choices.Accepted,
-	}}
-
-	utxos := []ids.ID{ids.GenerateTestID(), ids.GenerateTestID()}
-
-	tx0 := &snowstorm.TestTx{
+	gVtx := &avalanche.TestVertex{
+		TestDecidable: choices.TestDecidable{
+			IDV:     ids.GenerateTestID(),
+			StatusV: choices.Accepted,
+		},
+		HeightV: 10,
+		BytesV:  []byte{0},
+	}
+	oldVtx := &avalanche.TestVertex{
+		TestDecidable: choices.TestDecidable{
+			IDV:     ids.GenerateTestID(),
+			StatusV: choices.Accepted,
+		},
+		HeightV: 2,
+		BytesV:  []byte{1},
+	}
+	staleVtx := &avalanche.TestVertex{
 		TestDecidable: choices.TestDecidable{
 			IDV:     ids.GenerateTestID(),
 			StatusV: choices.Processing,
 		},
-		DependenciesV: []snowstorm.Tx{gTx},
-		InputIDsV:     utxos[:1],
+		ParentsV: []avalanche.Vertex{oldVtx},
+		HeightV:  3,
+		BytesV:   []byte{2},
 	}
-	tx1 := &snowstorm.TestTx{
+
+	tx := &snowstorm.TestTx{TestDecidable: choices.TestDecidable{
+		IDV:     ids.GenerateTestID(),
+		StatusV: choices.Processing,
+	}}
+	tx.InputIDsV = append(tx.InputIDsV, ids.GenerateTestID())
+
+	newVtx := &avalanche.TestVertex{
 		TestDecidable: choices.TestDecidable{
 			IDV:     ids.GenerateTestID(),
 			StatusV: choices.Processing,
 		},
-		DependenciesV: []snowstorm.Tx{gTx},
-		InputIDsV:     utxos[1:],
+		ParentsV: []avalanche.Vertex{gVtx},
+		HeightV:  11,
+		TxsV:     []snowstorm.Tx{tx},
+		BytesV:   []byte{3},
 	}
 
-	manager.EdgeF = func() []ids.ID { return []ids.ID{gVtx.ID(), mVtx.ID()} }
+	manager.EdgeF = func() []ids.ID { return []ids.ID{gVtx.ID()} }
 	manager.GetF = func(id ids.ID) (avalanche.Vertex, error) {
 		switch id {
 		case gVtx.ID():
 			return gVtx, nil
-		case mVtx.ID():
-			return mVtx, nil
+		case oldVtx.ID():
+			return oldVtx, nil
+		case staleVtx.ID():
+			return staleVtx, nil
+		case newVtx.ID():
+			return newVtx, nil
 		}
 		t.Fatalf("Unknown vertex")
 		panic("Should have errored")
@@ -4158,59 +7002,27 @@ func TestEngineIssue(t *testing.T) {
 	vm.CantBootstrapping = true
 	vm.CantBootstrapped = true
 
-	numBuilt := 0
-	manager.BuildF = func(_ uint32, _ []ids.ID, txs []snowstorm.Tx, _ []ids.ID) (avalanche.Vertex, error) {
-		numBuilt++
-		vtx := &avalanche.TestVertex{
-			TestDecidable: choices.TestDecidable{
-				IDV:     ids.GenerateTestID(),
-				StatusV: choices.Processing,
-			},
-			ParentsV: []avalanche.Vertex{gVtx, mVtx},
-			HeightV:  1,
-			TxsV:     txs,
-			BytesV:   []byte{1},
-		}
-
-		manager.GetF = func(id ids.ID) (avalanche.Vertex, error) {
-			switch id {
-			case gVtx.ID():
-				return gVtx, nil
-			case mVtx.ID():
-				return mVtx, nil
-			case vtx.ID():
-				return vtx, nil
-			}
-			t.Fatalf("Unknown vertex")
-			panic("Should have errored")
-		}
-
-		return vtx, nil
-	}
-
-	var (
-		vtxID          ids.ID
-		queryRequestID uint32
-	)
-	sender.PushQueryF = func(inVdrs ids.ShortSet, requestID uint32, vID ids.ID, vtx []byte) {
-		vtxID = vID
-		queryRequestID = requestID
-	}
+	assert.Equal(t, uint64(10), te.frontierHeight)
 
-	vm.PendingF = func() []snowstorm.Tx { return []snowstorm.Tx{tx0, tx1} }
-	if err := te.Notify(common.PendingTxs); err != nil {
+	// [staleVtx] is still issued, but it's counted as stale
+	sender.CantPushQuery = false
+	if _, err := te.issueFrom(vdr, staleVtx); err != nil {
 		t.Fatal(err)
 	}
+	assert.True(t, te.Consensus.VertexIssued(staleVtx))
+	assert.Equal(t, float64(1), testutil.ToFloat64(te.numStaleVtxs))
 
-	if numBuilt != 1 {
-		t.Fatalf("Should have issued txs differently")
+	// New vertices build on the accepted frontier rather than on [staleVtx]
+	var parentIDs []ids.ID
+	manager.BuildF = func(_ uint32, parents []ids.ID, _ []snowstorm.Tx, _ []ids.ID) (avalanche.Vertex, error) {
+		parentIDs = parents
+		return newVtx, nil
 	}
-
-	if err := te.Chits(vdr, queryRequestID, []ids.ID{vtxID}); err != nil {
+	vm.PendingF = func() []snowstorm.Tx { return []snowstorm.Tx{tx} }
+	if err := te.Notify(common.PendingTxs); err != nil {
 		t.Fatal(err)
 	}
-
-	if numBuilt != 2 {
-		t.Fatalf("Should have issued txs differently")
-	}
+	assert.Equal(t, []ids.ID{gVtx.ID()}, parentIDs)
+	assert.Equal(t, float64(1), testutil.ToFloat64(te.numStaleParentsSkipped))
+	assert.Equal(t, float64(1), testutil.ToFloat64(te.numStaleVtxs), "shouldn't have counted the new vertex as stale")
 }
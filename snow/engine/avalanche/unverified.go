@@ -0,0 +1,91 @@
+// (c) 2019-2020, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package avalanche
+
+import (
+	"github.com/ava-labs/avalanchego/ids"
+)
+
+// unverifiedVtxs tracks vertices that were abandoned because some of their
+// txs failed verification due to missing dependencies, so that the vertices
+// can be issued again once the dependencies are accepted.
+type unverifiedVtxs struct {
+	// Vertex ID --> IDs of the txs that must still be accepted before the
+	// vertex is retried
+	vtxs map[ids.ID]ids.Set
+	// Tx ID --> IDs of the vertices waiting on the tx to be accepted
+	waiting map[ids.ID]ids.Set
+}
+
+// Len returns the number of vertices waiting to be retried
+func (u *unverifiedVtxs) Len() int { return len(u.vtxs) }
+
+// Add records that [vtxID] should be retried once [txIDs] are accepted.
+// Returns false if more than [max] vertices would be waiting, in which case
+// [vtxID] isn't added.
+func (u *unverifiedVtxs) Add(vtxID ids.ID, txIDs []ids.ID, max int) bool {
+	if u.vtxs == nil {
+		u.vtxs = make(map[ids.ID]ids.Set)
+		u.waiting = make(map[ids.ID]ids.Set)
+	}
+	deps, ok := u.vtxs[vtxID]
+	if !ok {
+		if len(u.vtxs) >= max {
+			return false
+		}
+		deps = ids.Set{}
+		u.vtxs[vtxID] = deps
+	}
+	for _, txID := range txIDs {
+		deps.Add(txID)
+		waiting := u.waiting[txID]
+		waiting.Add(vtxID)
+		u.waiting[txID] = waiting
+	}
+	return true
+}
+
+// Dependencies returns the IDs of the txs that vertices are waiting on
+func (u *unverifiedVtxs) Dependencies() []ids.ID {
+	txIDs := make([]ids.ID, 0, len(u.waiting))
+	for txID := range u.waiting {
+		txIDs = append(txIDs, txID)
+	}
+	return txIDs
+}
+
+// Accept records that [txID] was accepted and returns the IDs of the vertices
+// that are no longer waiting on any tx. They are no longer tracked.
+func (u *unverifiedVtxs) Accept(txID ids.ID) []ids.ID {
+	ready := []ids.ID(nil)
+	for vtxID := range u.waiting[txID] {
+		deps := u.vtxs[vtxID]
+		deps.Remove(txID)
+		if deps.Len() == 0 {
+			delete(u.vtxs, vtxID)
+			ready = append(ready, vtxID)
+		}
+	}
+	delete(u.waiting, txID)
+	return ready
+}
+
+// Reject records that [txID] was rejected and returns the IDs of the vertices
+// that were waiting on it. As they can never be verified, they are no longer
+// tracked.
+func (u *unverifiedVtxs) Reject(txID ids.ID) []ids.ID {
+	dropped := u.waiting[txID].List()
+	for _, vtxID := range dropped {
+		for depID := range u.vtxs[vtxID] {
+			waiting := u.waiting[depID]
+			waiting.Remove(vtxID)
+			if waiting.Len() == 0 {
+				delete(u.waiting, depID)
+			}
+		}
+		delete(u.vtxs, vtxID)
+	}
+	delete(u.waiting, txID)
+	return dropped
+}
@@ -21,8 +21,10 @@ type Builder interface {
 	) (avalanche.Vertex, error)
 }
 
-// Build a new stateless vertex from the contents of a vertex
+// Build a new stateless vertex from the contents of a vertex. The vertex is
+// identified by its hash under [hasher].
 func Build(
+	hasher hashing.Hasher,
 	chainID ids.ID,
 	height uint64,
 	epoch uint32,
@@ -50,7 +52,7 @@ func Build(
 	vtxBytes, err := Codec.Marshal(innerVtx.Version, innerVtx)
 	vtx := statelessVertex{
 		innerStatelessVertex: innerVtx,
-		id:                   hashing.ComputeHash256Array(vtxBytes),
+		id:                   hasher.ComputeHash256Array(vtxBytes),
 		bytes:                vtxBytes,
 	}
 	return vtx, err
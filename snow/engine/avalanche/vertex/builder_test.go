@@ -6,6 +6,7 @@ import (
 	"github.com/stretchr/testify/assert"
 
 	"github.com/ava-labs/avalanchego/ids"
+	"github.com/ava-labs/avalanchego/utils/hashing"
 )
 
 func TestBuildInvalid(t *testing.T) {
@@ -16,6 +17,7 @@ func TestBuildInvalid(t *testing.T) {
 	txs := [][]byte{{7}, {6}}
 	restrictions := []ids.ID{{8}, {9}}
 	_, err := Build(
+		hashing.Default,
 		chainID,
 		height,
 		epoch,
@@ -34,6 +36,7 @@ func TestBuildValid(t *testing.T) {
 	txs := [][]byte{{7}, {6}}
 	restrictions := []ids.ID{}
 	vtx, err := Build(
+		hashing.Default,
 		chainID,
 		height,
 		epoch,
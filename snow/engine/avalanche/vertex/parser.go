@@ -14,14 +14,15 @@ type Parser interface {
 	Parse(vertex []byte) (avalanche.Vertex, error)
 }
 
-// Parse the provided vertex bytes into a stateless vertex
-func Parse(vertex []byte) (StatelessVertex, error) {
+// Parse the provided vertex bytes into a stateless vertex, identified by its
+// hash under [hasher]
+func Parse(hasher hashing.Hasher, vertex []byte) (StatelessVertex, error) {
 	vtx := innerStatelessVertex{}
 	version, err := Codec.Unmarshal(vertex, &vtx)
 	vtx.Version = version
 	return statelessVertex{
 		innerStatelessVertex: vtx,
-		id:                   hashing.ComputeHash256Array(vertex),
+		id:                   hasher.ComputeHash256Array(vertex),
 		bytes:                vertex,
 	}, err
 }
@@ -4,12 +4,13 @@ import (
 	"testing"
 
 	"github.com/ava-labs/avalanchego/ids"
+	"github.com/ava-labs/avalanchego/utils/hashing"
 	"github.com/stretchr/testify/assert"
 )
 
 func TestParseInvalid(t *testing.T) {
 	vtxBytes := []byte{}
-	_, err := Parse(vtxBytes)
+	_, err := Parse(hashing.Default, vtxBytes)
 	assert.Error(t, err, "parse on an invalid vertex should have errored")
 }
 
@@ -21,6 +22,7 @@ func TestParseValid(t *testing.T) {
 	txs := [][]byte{{6}, {7}}
 	restrictions := []ids.ID(nil)
 	vtx, err := Build(
+		hashing.Default,
 		chainID,
 		height,
 		epoch,
@@ -31,7 +33,32 @@ func TestParseValid(t *testing.T) {
 	assert.NoError(t, err)
 
 	vtxBytes := vtx.Bytes()
-	parsedVtx, err := Parse(vtxBytes)
+	parsedVtx, err := Parse(hashing.Default, vtxBytes)
 	assert.NoError(t, err)
 	assert.Equal(t, vtx, parsedVtx)
 }
+
+func TestParseWithHasher(t *testing.T) {
+	vtx, err := Build(
+		hashing.SHA3,
+		ids.ID{1},
+		2,
+		0,
+		[]ids.ID{{4}, {5}},
+		[][]byte{{6}, {7}},
+		nil,
+	)
+	assert.NoError(t, err)
+
+	vtxBytes := vtx.Bytes()
+	assert.Equal(t, ids.ID(hashing.SHA3.ComputeHash256Array(vtxBytes)), vtx.ID())
+
+	parsedVtx, err := Parse(hashing.SHA3, vtxBytes)
+	assert.NoError(t, err)
+	assert.Equal(t, vtx.ID(), parsedVtx.ID())
+
+	// Parsing with a different hash function gives a different ID
+	parsedVtx, err = Parse(hashing.Default, vtxBytes)
+	assert.NoError(t, err)
+	assert.NotEqual(t, vtx.ID(), parsedVtx.ID())
+}
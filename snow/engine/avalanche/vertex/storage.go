@@ -17,3 +17,16 @@ type Storage interface {
 	// Edge returns a list of accepted vertex IDs with no accepted children.
 	Edge() (vtxIDs []ids.ID)
 }
+
+// EdgePersister is implemented by Storage that defers persisting the edge when
+// vertices are accepted, so that the edge isn't rewritten for every accepted
+// vertex.
+type EdgePersister interface {
+	// FinishPoll is called once the engine has finished handling a poll,
+	// during which any number of vertices may have been accepted. The edge may
+	// be persisted, depending on the storage's policy.
+	FinishPoll() error
+
+	// FlushEdge persists the edge, if it changed since it was last persisted
+	FlushEdge() error
+}
@@ -7,6 +7,7 @@ import (
 	"github.com/ava-labs/avalanchego/ids"
 	"github.com/ava-labs/avalanchego/snow/consensus/snowstorm"
 	"github.com/ava-labs/avalanchego/snow/engine/avalanche/vertex"
+	"github.com/ava-labs/avalanchego/snow/events"
 )
 
 // Voter records chits received from [vdr] once its dependencies are met.
@@ -15,19 +16,19 @@ type voter struct {
 	vdr       ids.ShortID
 	requestID uint32
 	response  []ids.ID
-	deps      ids.Set
+	deps      events.KeySet
 }
 
-func (v *voter) Dependencies() ids.Set { return v.deps }
+func (v *voter) Dependencies() events.KeySet { return v.deps }
 
 // Mark that a dependency has been met.
-func (v *voter) Fulfill(id ids.ID) {
-	v.deps.Remove(id)
+func (v *voter) Fulfill(key events.Key) {
+	v.deps.Remove(key)
 	v.Update()
 }
 
 // Abandon this attempt to record chits.
-func (v *voter) Abandon(id ids.ID) { v.Fulfill(id) }
+func (v *voter) Abandon(key events.Key) { v.Fulfill(key) }
 
 func (v *voter) Update() {
 	if v.deps.Len() != 0 || v.t.errs.Errored() {
@@ -49,6 +50,15 @@ func (v *voter) Update() {
 		v.t.errs.Add(err)
 		return
 	}
+	if err := v.t.persistEdge(false); err != nil {
+		v.t.errs.Add(err)
+		return
+	}
+	v.t.publishFrontier()
+	if err := v.t.retryUnverified(); err != nil {
+		v.t.errs.Add(err)
+		return
+	}
 
 	orphans := v.t.Consensus.Orphans()
 	txs := make([]snowstorm.Tx, 0, orphans.Len())
@@ -62,13 +72,26 @@ func (v *voter) Update() {
 	if len(txs) > 0 {
 		v.t.Ctx.Log.Debug("Re-issuing %d transactions", len(txs))
 	}
+	// Re-issue orphans after the orphans they depend on, in a reproducible
+	// order
+	if sortedTxs, err := snowstorm.TopologicalSort(txs); err == nil {
+		txs = sortedTxs
+	} else {
+		v.t.Ctx.Log.Warn("Re-issuing transactions in an arbitrary order due to: %s", err)
+	}
 	if _, err := v.t.batch(txs, true /*=force*/, false /*empty*/, false /*=limit*/); err != nil {
 		v.t.errs.Add(err)
 		return
 	}
+	if err := v.t.issueDelayedTxs(); err != nil {
+		v.t.errs.Add(err)
+		return
+	}
 
 	if v.t.Consensus.Quiesce() {
 		v.t.Ctx.Log.Debug("Avalanche engine can quiesce")
+		v.t.repollLimit.Quiesced()
+		v.t.concurrentRepolls.Set(float64(v.t.repollLimit.Limit()))
 		return
 	}
 
@@ -7,10 +7,9 @@ import (
 	"fmt"
 	"time"
 
-	stdmath "math"
-
 	"github.com/ava-labs/avalanchego/ids"
 	"github.com/ava-labs/avalanchego/snow/validators"
+	"github.com/ava-labs/avalanchego/snow/validators/quorum"
 	"github.com/ava-labs/avalanchego/utils/math"
 )
 
@@ -46,7 +45,7 @@ type Bootstrapper struct {
 	// holds the beacons that were sampled for the accepted frontier
 	sampledBeacons  validators.Set
 	pendingAccepted ids.ShortSet
-	acceptedVotes   map[ids.ID]uint64
+	acceptedVotes   map[ids.ID]*quorum.Tally
 
 	// current weight
 	started bool
@@ -89,7 +88,7 @@ func (b *Bootstrapper) Initialize(config Config) error {
 		b.pendingAccepted.Add(vdrID)
 	}
 
-	b.acceptedVotes = make(map[ids.ID]uint64)
+	b.acceptedVotes = make(map[ids.ID]*quorum.Tally)
 	if b.Config.StartupAlpha > 0 {
 		return nil
 	}
@@ -127,11 +126,7 @@ func (b *Bootstrapper) GetAcceptedFrontier(validatorID ids.ShortID, requestID ui
 // GetAcceptedFrontierFailed implements the Engine interface.
 func (b *Bootstrapper) GetAcceptedFrontierFailed(validatorID ids.ShortID, requestID uint32) error {
 	// ignores any late responses
-	if requestID != b.RequestID {
-		b.Ctx.Log.Debug("Received an Out-of-Sync GetAcceptedFrontierFailed - validator: %v - expectedRequestID: %v, requestID: %v",
-			validatorID,
-			b.RequestID,
-			requestID)
+	if b.stale("GetAcceptedFrontierFailed", validatorID, requestID) {
 		return nil
 	}
 
@@ -144,11 +139,7 @@ func (b *Bootstrapper) GetAcceptedFrontierFailed(validatorID ids.ShortID, reques
 // AcceptedFrontier implements the Engine interface.
 func (b *Bootstrapper) AcceptedFrontier(validatorID ids.ShortID, requestID uint32, containerIDs []ids.ID) error {
 	// ignores any late responses
-	if requestID != b.RequestID {
-		b.Ctx.Log.Debug("Received an Out-of-Sync AcceptedFrontier - validator: %v - expectedRequestID: %v, requestID: %v",
-			validatorID,
-			b.RequestID,
-			requestID)
+	if b.stale("AcceptedFrontier", validatorID, requestID) {
 		return nil
 	}
 
@@ -215,11 +206,7 @@ func (b *Bootstrapper) GetAccepted(validatorID ids.ShortID, requestID uint32, co
 // GetAcceptedFailed implements the Engine interface.
 func (b *Bootstrapper) GetAcceptedFailed(validatorID ids.ShortID, requestID uint32) error {
 	// ignores any late responses
-	if requestID != b.RequestID {
-		b.Ctx.Log.Debug("Received an Out-of-Sync GetAcceptedFailed - validator: %v - expectedRequestID: %v, requestID: %v",
-			validatorID,
-			b.RequestID,
-			requestID)
+	if b.stale("GetAcceptedFailed", validatorID, requestID) {
 		return nil
 	}
 
@@ -232,11 +219,7 @@ func (b *Bootstrapper) GetAcceptedFailed(validatorID ids.ShortID, requestID uint
 // Accepted implements the Engine interface.
 func (b *Bootstrapper) Accepted(validatorID ids.ShortID, requestID uint32, containerIDs []ids.ID) error {
 	// ignores any late responses
-	if requestID != b.RequestID {
-		b.Ctx.Log.Debug("Received an Out-of-Sync Accepted - validator: %v - expectedRequestID: %v, requestID: %v",
-			validatorID,
-			b.RequestID,
-			requestID)
+	if b.stale("Accepted", validatorID, requestID) {
 		return nil
 	}
 
@@ -247,19 +230,13 @@ func (b *Bootstrapper) Accepted(validatorID ids.ShortID, requestID uint32, conta
 	// Mark that we received a response from [validatorID]
 	b.pendingAccepted.Remove(validatorID)
 
-	weight := uint64(0)
-	if w, ok := b.Beacons.GetWeight(validatorID); ok {
-		weight = w
-	}
-
 	for _, containerID := range containerIDs {
-		previousWeight := b.acceptedVotes[containerID]
-		newWeight, err := math.Add64(weight, previousWeight)
-		if err != nil {
-			b.Ctx.Log.Error("Error calculating the Accepted votes - weight: %v, previousWeight: %v", weight, previousWeight)
-			newWeight = stdmath.MaxUint64
+		votes, ok := b.acceptedVotes[containerID]
+		if !ok {
+			votes = quorum.NewTally(b.Beacons, b.Alpha)
+			b.acceptedVotes[containerID] = votes
 		}
-		b.acceptedVotes[containerID] = newWeight
+		votes.Add(validatorID)
 	}
 
 	// wait on pending responses
@@ -270,8 +247,8 @@ func (b *Bootstrapper) Accepted(validatorID ids.ShortID, requestID uint32, conta
 	// We've received the filtered accepted frontier from every bootstrap validator
 	// Accept all containers that have a sufficient weight behind them
 	accepted := make([]ids.ID, 0, len(b.acceptedVotes))
-	for containerID, weight := range b.acceptedVotes {
-		if weight >= b.Alpha {
+	for containerID, votes := range b.acceptedVotes {
+		if votes.Reached() {
 			accepted = append(accepted, containerID)
 		}
 	}
@@ -298,6 +275,27 @@ func (b *Bootstrapper) Accepted(validatorID ids.ShortID, requestID uint32, conta
 	return b.Bootstrapable.ForceAccepted(accepted)
 }
 
+// stale returns true if [requestID], of a response [op] from [validatorID],
+// belongs to an earlier round of requests.
+//
+// The router already drops responses that don't match an outstanding request,
+// so this is the only request ID check left in the bootstrapper. It's still
+// needed because restarting bootstrapping starts a new round of requests
+// without cancelling the previous round's, which stay outstanding in the
+// router until they're answered or time out. Only the bootstrapper knows which
+// round is current.
+func (b *Bootstrapper) stale(op string, validatorID ids.ShortID, requestID uint32) bool {
+	if requestID == b.RequestID {
+		return false
+	}
+	b.Ctx.Log.Debug("Received an Out-of-Sync %s - validator: %v - expectedRequestID: %v, requestID: %v",
+		op,
+		validatorID,
+		b.RequestID,
+		requestID)
+	return true
+}
+
 // Connected implements the Engine interface.
 func (b *Bootstrapper) Connected(validatorID ids.ShortID) error {
 	if b.started {
@@ -371,6 +369,6 @@ func (b *Bootstrapper) RestartBootstrap(reset bool) error {
 		b.pendingAccepted.Add(vdrID)
 	}
 
-	b.acceptedVotes = make(map[ids.ID]uint64)
+	b.acceptedVotes = make(map[ids.ID]*quorum.Tally)
 	return b.Startup()
 }
@@ -4,7 +4,10 @@
 package common
 
 import (
+	"time"
+
 	"github.com/ava-labs/avalanchego/snow"
+	"github.com/ava-labs/avalanchego/snow/networking/benchlist"
 	"github.com/ava-labs/avalanchego/snow/validators"
 )
 
@@ -27,6 +30,44 @@ type Config struct {
 	RetryBootstrap bool
 	// Max number of times to retry bootstrap
 	RetryBootstrapMaxAttempts int
+
+	// Should the heights of voted containers be sent along with chits
+	JustifyChits bool
+
+	// Should the IDs of accepted and pending transactions be gossiped
+	GossipAcceptedTxs bool
+
+	// Maximum number of Get requests that may be outstanding at once. Any
+	// additional requests are queued. 0 means no limit.
+	MaxOutstandingGets int
+
+	// Maximum number of Get requests that may be outstanding to a validator at
+	// once. Any additional requests are queued. 0 means no limit.
+	MaxOutstandingGetsPerPeer int
+
+	// Maximum number of Get requests that may be queued. Once exceeded, the
+	// least recently requested containers are dropped. 0 means the engine's
+	// default.
+	MaxQueuedGets int
+
+	// Amount of time without a poll finishing after which the preferred
+	// containers are re-polled, if consensus hasn't finalized them. It's
+	// checked each time the engine gossips. 0 disables heartbeat polls.
+	HeartbeatFrequency time.Duration
+
+	// Number of heights the parents of a vertex may be behind the accepted
+	// frontier before the vertex is considered stale. Stale vertices are
+	// usually issued by lagging validators, and building on them widens the
+	// DAG. 0 disables stale vertex detection.
+	StaleVertexDepth uint64
+
+	// Should stale vertices be excluded from the parents of new vertices
+	RefuseStaleParents bool
+
+	// Benches validators that repeatedly misbehave, such as by sending
+	// containers that can't be parsed. May be nil, in which case misbehaving
+	// validators aren't benched.
+	Benchlist benchlist.Manager
 }
 
 // Context implements the Engine interface
@@ -0,0 +1,235 @@
+// (c) 2019-2020, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package common
+
+import (
+	"github.com/ava-labs/avalanchego/ids"
+	"github.com/ava-labs/avalanchego/snow"
+)
+
+// DisabledEngine is an Engine for a chain that the operator has opted out of
+// tracking. It doesn't participate in consensus, but it responds to requests
+// with empty replies so that requesters don't have to wait for their requests
+// to time out. Requests that can't be answered, gossip, and notifications are
+// dropped.
+//
+// [Engine] is the engine that was running the chain before it was disabled. It
+// isn't sent any messages, other than Shutdown, so that it doesn't send any
+// requests of its own while the chain is disabled. The responses to the
+// requests that [Engine] sent before the chain was disabled are recorded as
+// failures, and are reported to [Engine] by Enable. Peers connecting and
+// disconnecting are recorded too, so that [Engine] knows which peers are
+// connected once the chain is enabled.
+type DisabledEngine struct {
+	Engine Engine
+	Sender Sender
+
+	// connection events that will be reported to [Engine], in the order they
+	// happened, when the chain is enabled
+	connectionEvents []func() error
+
+	// failures that will be reported to [Engine] when the chain is enabled
+	failures []func() error
+}
+
+// Enable reports the peers that connected and disconnected while the chain was
+// disabled to [Engine]. Then, every response and failure that was received
+// while the chain was disabled is reported to [Engine] as a failed request, so
+// that [Engine] doesn't wait on requests that will never be answered.
+func (e *DisabledEngine) Enable() error {
+	connectionEvents := e.connectionEvents
+	e.connectionEvents = nil
+	for _, connectionEvent := range connectionEvents {
+		if err := connectionEvent(); err != nil {
+			return err
+		}
+	}
+
+	failures := e.failures
+	e.failures = nil
+	for _, failure := range failures {
+		if err := failure(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Context implements the Engine interface
+func (e *DisabledEngine) Context() *snow.Context { return e.Engine.Context() }
+
+// IsBootstrapped implements the Engine interface
+func (e *DisabledEngine) IsBootstrapped() bool { return e.Engine.IsBootstrapped() }
+
+// HealthCheck implements the Engine interface
+func (e *DisabledEngine) HealthCheck() (interface{}, error) {
+	return map[string]interface{}{"disabled": true}, nil
+}
+
+// GetAcceptedFrontier implements the Engine interface. An empty frontier is
+// sent in response.
+func (e *DisabledEngine) GetAcceptedFrontier(validatorID ids.ShortID, requestID uint32) error {
+	e.Sender.AcceptedFrontier(validatorID, requestID, nil)
+	return nil
+}
+
+// AcceptedFrontier implements the Engine interface
+func (e *DisabledEngine) AcceptedFrontier(validatorID ids.ShortID, requestID uint32, _ []ids.ID) error {
+	return e.fail("AcceptedFrontier", validatorID, requestID, func() error {
+		return e.Engine.GetAcceptedFrontierFailed(validatorID, requestID)
+	})
+}
+
+// GetAcceptedFrontierFailed implements the Engine interface
+func (e *DisabledEngine) GetAcceptedFrontierFailed(validatorID ids.ShortID, requestID uint32) error {
+	return e.fail("GetAcceptedFrontierFailed", validatorID, requestID, func() error {
+		return e.Engine.GetAcceptedFrontierFailed(validatorID, requestID)
+	})
+}
+
+// GetAccepted implements the Engine interface. No containers are reported as
+// accepted in response.
+func (e *DisabledEngine) GetAccepted(validatorID ids.ShortID, requestID uint32, _ []ids.ID) error {
+	e.Sender.Accepted(validatorID, requestID, nil)
+	return nil
+}
+
+// Accepted implements the Engine interface
+func (e *DisabledEngine) Accepted(validatorID ids.ShortID, requestID uint32, _ []ids.ID) error {
+	return e.fail("Accepted", validatorID, requestID, func() error {
+		return e.Engine.GetAcceptedFailed(validatorID, requestID)
+	})
+}
+
+// GetAcceptedFailed implements the Engine interface
+func (e *DisabledEngine) GetAcceptedFailed(validatorID ids.ShortID, requestID uint32) error {
+	return e.fail("GetAcceptedFailed", validatorID, requestID, func() error {
+		return e.Engine.GetAcceptedFailed(validatorID, requestID)
+	})
+}
+
+// Get implements the Engine interface. There is no way to respond to a Get
+// without providing the container, so the request is dropped.
+func (e *DisabledEngine) Get(validatorID ids.ShortID, requestID uint32, _ ids.ID) error {
+	return e.drop("Get", validatorID, requestID)
+}
+
+// GetAncestors implements the Engine interface. No containers are sent in
+// response.
+func (e *DisabledEngine) GetAncestors(validatorID ids.ShortID, requestID uint32, _ ids.ID) error {
+	e.Sender.MultiPut(validatorID, requestID, nil)
+	return nil
+}
+
+// Put implements the Engine interface
+func (e *DisabledEngine) Put(validatorID ids.ShortID, requestID uint32, _ ids.ID, _ []byte) error {
+	return e.fail("Put", validatorID, requestID, func() error {
+		return e.Engine.GetFailed(validatorID, requestID)
+	})
+}
+
+// HintedPut implements the Engine interface
+func (e *DisabledEngine) HintedPut(validatorID ids.ShortID, requestID uint32, _ ids.ID, _ []byte, _ [][]byte) error {
+	return e.fail("HintedPut", validatorID, requestID, func() error {
+		return e.Engine.GetFailed(validatorID, requestID)
+	})
+}
+
+// MultiPut implements the Engine interface
+func (e *DisabledEngine) MultiPut(validatorID ids.ShortID, requestID uint32, _ [][]byte) error {
+	return e.fail("MultiPut", validatorID, requestID, func() error {
+		return e.Engine.GetAncestorsFailed(validatorID, requestID)
+	})
+}
+
+// GetFailed implements the Engine interface
+func (e *DisabledEngine) GetFailed(validatorID ids.ShortID, requestID uint32) error {
+	return e.fail("GetFailed", validatorID, requestID, func() error {
+		return e.Engine.GetFailed(validatorID, requestID)
+	})
+}
+
+// GetAncestorsFailed implements the Engine interface
+func (e *DisabledEngine) GetAncestorsFailed(validatorID ids.ShortID, requestID uint32) error {
+	return e.fail("GetAncestorsFailed", validatorID, requestID, func() error {
+		return e.Engine.GetAncestorsFailed(validatorID, requestID)
+	})
+}
+
+// PullQuery implements the Engine interface. No votes are sent in response.
+func (e *DisabledEngine) PullQuery(validatorID ids.ShortID, requestID uint32, _ ids.ID) error {
+	e.Sender.Chits(validatorID, requestID, nil)
+	return nil
+}
+
+// PushQuery implements the Engine interface. No votes are sent in response.
+func (e *DisabledEngine) PushQuery(validatorID ids.ShortID, requestID uint32, _ ids.ID, _ []byte) error {
+	e.Sender.Chits(validatorID, requestID, nil)
+	return nil
+}
+
+// Chits implements the Engine interface
+func (e *DisabledEngine) Chits(validatorID ids.ShortID, requestID uint32, _ []ids.ID) error {
+	return e.fail("Chits", validatorID, requestID, func() error {
+		return e.Engine.QueryFailed(validatorID, requestID)
+	})
+}
+
+// JustifiedChits implements the Engine interface
+func (e *DisabledEngine) JustifiedChits(validatorID ids.ShortID, requestID uint32, _ []ids.ID, _ []uint64) error {
+	return e.fail("JustifiedChits", validatorID, requestID, func() error {
+		return e.Engine.QueryFailed(validatorID, requestID)
+	})
+}
+
+// QueryFailed implements the Engine interface
+func (e *DisabledEngine) QueryFailed(validatorID ids.ShortID, requestID uint32) error {
+	return e.fail("QueryFailed", validatorID, requestID, func() error {
+		return e.Engine.QueryFailed(validatorID, requestID)
+	})
+}
+
+// GossipTxs implements the Engine interface
+func (e *DisabledEngine) GossipTxs(ids.ShortID, ids.ID, []ids.ID) error { return nil }
+
+// Startup implements the Engine interface
+func (e *DisabledEngine) Startup() error { return nil }
+
+// Gossip implements the Engine interface
+func (e *DisabledEngine) Gossip() error { return nil }
+
+// Shutdown implements the Engine interface
+func (e *DisabledEngine) Shutdown() error { return e.Engine.Shutdown() }
+
+// Notify implements the Engine interface
+func (e *DisabledEngine) Notify(Message) error { return nil }
+
+// Connected implements the Engine interface
+func (e *DisabledEngine) Connected(validatorID ids.ShortID) error {
+	e.connectionEvents = append(e.connectionEvents, func() error {
+		return e.Engine.Connected(validatorID)
+	})
+	return nil
+}
+
+// Disconnected implements the Engine interface
+func (e *DisabledEngine) Disconnected(validatorID ids.ShortID) error {
+	e.connectionEvents = append(e.connectionEvents, func() error {
+		return e.Engine.Disconnected(validatorID)
+	})
+	return nil
+}
+
+// fail records that the request [requestID] to [validatorID] has been answered
+// by [msg], so that [failure] reports it as failed once the chain is enabled
+func (e *DisabledEngine) fail(msg string, validatorID ids.ShortID, requestID uint32, failure func() error) error {
+	e.Engine.Context().Log.Verbo("deferring %s(%s, %d) as the chain is disabled", msg, validatorID, requestID)
+	e.failures = append(e.failures, failure)
+	return nil
+}
+
+func (e *DisabledEngine) drop(msg string, validatorID ids.ShortID, requestID uint32) error {
+	e.Engine.Context().Log.Verbo("dropping %s(%s, %d) as the chain is disabled", msg, validatorID, requestID)
+	return nil
+}
@@ -0,0 +1,221 @@
+// (c) 2019-2020, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package common
+
+import (
+	"testing"
+
+	"github.com/ava-labs/avalanchego/ids"
+	"github.com/ava-labs/avalanchego/snow"
+	"github.com/ava-labs/avalanchego/snow/validators"
+)
+
+func TestDisabledEngine(t *testing.T) {
+	engine := &EngineTest{T: t}
+	engine.Default(true)
+	engine.ContextF = snow.DefaultContextTest
+
+	sender := &SenderTest{T: t}
+	sender.Default(true)
+
+	e := &DisabledEngine{
+		Engine: engine,
+		Sender: sender,
+	}
+
+	vdr := ids.GenerateTestShortID()
+	requestID := uint32(5)
+
+	responses := 0
+	sender.AcceptedFrontierF = func(inVdr ids.ShortID, inRequestID uint32, containerIDs []ids.ID) {
+		if inVdr != vdr || inRequestID != requestID || len(containerIDs) != 0 {
+			t.Fatalf("Should have responded with an empty frontier")
+		}
+		responses++
+	}
+	sender.AcceptedF = func(inVdr ids.ShortID, inRequestID uint32, containerIDs []ids.ID) {
+		if inVdr != vdr || inRequestID != requestID || len(containerIDs) != 0 {
+			t.Fatalf("Should have responded with no accepted containers")
+		}
+		responses++
+	}
+	sender.MultiPutF = func(inVdr ids.ShortID, inRequestID uint32, containers [][]byte) {
+		if inVdr != vdr || inRequestID != requestID || len(containers) != 0 {
+			t.Fatalf("Should have responded with no containers")
+		}
+		responses++
+	}
+	sender.ChitsF = func(inVdr ids.ShortID, inRequestID uint32, votes []ids.ID) {
+		if inVdr != vdr || inRequestID != requestID || len(votes) != 0 {
+			t.Fatalf("Should have responded with no votes")
+		}
+		responses++
+	}
+
+	containerID := ids.GenerateTestID()
+	if err := e.GetAcceptedFrontier(vdr, requestID); err != nil {
+		t.Fatal(err)
+	}
+	if err := e.GetAccepted(vdr, requestID, []ids.ID{containerID}); err != nil {
+		t.Fatal(err)
+	}
+	if err := e.GetAncestors(vdr, requestID, containerID); err != nil {
+		t.Fatal(err)
+	}
+	if err := e.PullQuery(vdr, requestID, containerID); err != nil {
+		t.Fatal(err)
+	}
+	if err := e.PushQuery(vdr, requestID, containerID, nil); err != nil {
+		t.Fatal(err)
+	}
+	if responses != 5 {
+		t.Fatalf("Should have responded to 5 requests but responded to %d", responses)
+	}
+
+	// Responses and failures shouldn't be passed to the disabled engine before
+	// the chain is enabled, which would fail the test as it isn't expecting any
+	// calls yet
+	if err := e.Get(vdr, requestID, containerID); err != nil {
+		t.Fatal(err)
+	}
+	if err := e.Put(vdr, requestID, containerID, nil); err != nil {
+		t.Fatal(err)
+	}
+	if err := e.Chits(vdr, requestID, nil); err != nil {
+		t.Fatal(err)
+	}
+	if err := e.QueryFailed(vdr, requestID); err != nil {
+		t.Fatal(err)
+	}
+	if err := e.Gossip(); err != nil {
+		t.Fatal(err)
+	}
+	if err := e.Notify(PendingTxs); err != nil {
+		t.Fatal(err)
+	}
+
+	// The responses and failures are reported as failed requests once the
+	// chain is enabled
+	getFailures, queryFailures := 0, 0
+	engine.GetFailedF = func(inVdr ids.ShortID, inRequestID uint32) error {
+		if inVdr != vdr || inRequestID != requestID {
+			t.Fatalf("Wrong request reported as failed")
+		}
+		getFailures++
+		return nil
+	}
+	engine.QueryFailedF = func(inVdr ids.ShortID, inRequestID uint32) error {
+		if inVdr != vdr || inRequestID != requestID {
+			t.Fatalf("Wrong request reported as failed")
+		}
+		queryFailures++
+		return nil
+	}
+	if err := e.Enable(); err != nil {
+		t.Fatal(err)
+	}
+	if getFailures != 1 || queryFailures != 2 {
+		t.Fatalf("Should have reported 1 failed get and 2 failed queries, but reported %d and %d", getFailures, queryFailures)
+	}
+	if err := e.Enable(); err != nil {
+		t.Fatal(err)
+	}
+	if getFailures != 1 || queryFailures != 2 {
+		t.Fatalf("Shouldn't have reported the failures again")
+	}
+
+	shutdown := false
+	engine.ShutdownF = func() error { shutdown = true; return nil }
+	if err := e.Shutdown(); err != nil {
+		t.Fatal(err)
+	}
+	if !shutdown {
+		t.Fatalf("Should have shutdown the disabled engine")
+	}
+}
+
+func TestDisabledEngineReplaysConnections(t *testing.T) {
+	ctx := snow.DefaultContextTest()
+
+	beacon := ids.GenerateTestShortID()
+	beacons := validators.NewSet()
+	if err := beacons.AddWeight(beacon, 1); err != nil {
+		t.Fatal(err)
+	}
+
+	sender := &SenderTest{T: t}
+	sender.Default(true)
+
+	// The bootstrapper waits for the beacon to connect before it starts
+	bootstrapper := &Bootstrapper{}
+	if err := bootstrapper.Initialize(Config{
+		Ctx:          ctx,
+		Beacons:      beacons,
+		SampleK:      1,
+		StartupAlpha: 1,
+		Sender:       sender,
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	engine := &EngineTest{T: t}
+	engine.Default(true)
+	engine.ContextF = func() *snow.Context { return ctx }
+
+	e := &DisabledEngine{
+		Engine: engine,
+		Sender: sender,
+	}
+
+	// Connection events shouldn't be passed to the disabled engine before the
+	// chain is enabled, which would fail the test as it isn't expecting any
+	// calls yet
+	peer := ids.GenerateTestShortID()
+	if err := e.Connected(peer); err != nil {
+		t.Fatal(err)
+	}
+	if err := e.Connected(beacon); err != nil {
+		t.Fatal(err)
+	}
+	if err := e.Disconnected(peer); err != nil {
+		t.Fatal(err)
+	}
+
+	connectionEvents := []string(nil)
+	engine.ConnectedF = func(validatorID ids.ShortID) error {
+		connectionEvents = append(connectionEvents, "connected "+validatorID.String())
+		return bootstrapper.Connected(validatorID)
+	}
+	engine.DisconnectedF = func(validatorID ids.ShortID) error {
+		connectionEvents = append(connectionEvents, "disconnected "+validatorID.String())
+		return bootstrapper.Disconnected(validatorID)
+	}
+	started := false
+	sender.GetAcceptedFrontierF = func(vdrs ids.ShortSet, _ uint32) {
+		if !vdrs.Contains(beacon) {
+			t.Fatalf("Should have asked the beacon for its accepted frontier")
+		}
+		started = true
+	}
+	if err := e.Enable(); err != nil {
+		t.Fatal(err)
+	}
+
+	expected := []string{
+		"connected " + peer.String(),
+		"connected " + beacon.String(),
+		"disconnected " + peer.String(),
+	}
+	if len(connectionEvents) != len(expected) {
+		t.Fatalf("Expected connection events %v but got %v", expected, connectionEvents)
+	}
+	for i, event := range expected {
+		if connectionEvents[i] != event {
+			t.Fatalf("Expected connection events %v but got %v", expected, connectionEvents)
+		}
+	}
+	if !started {
+		t.Fatalf("Bootstrapping should have started once the beacon's connection was reported")
+	}
+}
@@ -37,6 +37,7 @@ type ExternalHandler interface {
 	AcceptedHandler
 	FetchHandler
 	QueryHandler
+	GossipHandler
 }
 
 // FrontierHandler defines how a consensus engine reacts to frontier messages
@@ -176,6 +177,21 @@ type FetchHandler interface {
 		container []byte,
 	) error
 
+	// Notify this engine of a container, along with ancestors of the container
+	// that the sender expects this engine to be missing.
+	//
+	// This function can be called by any validator. It is not safe to assume
+	// that [hints] are ancestors of the container, so the engine should ignore
+	// any hint that it didn't need. However, the validatorID is assumed to be
+	// authenticated.
+	HintedPut(
+		validatorID ids.ShortID,
+		requestID uint32,
+		containerID ids.ID,
+		container []byte,
+		hints [][]byte,
+	) error
+
 	// Notify this engine of multiple containers.
 	// Each element of [containers] is the byte representation of a container.
 	//
@@ -265,6 +281,16 @@ type QueryHandler interface {
 	// However, the validatorID is assumed to be authenticated.
 	Chits(validatorID ids.ShortID, requestID uint32, containerIDs []ids.ID) error
 
+	// Notify this engine of the specified validators preferences, along with
+	// the height of each of the preferred containers.
+	//
+	// This function can be called by any validator. It is not safe to assume
+	// this message is in response to a PullQuery or a PushQuery message, or
+	// that the provided heights are correct. The engine should treat votes
+	// that contradict its local knowledge as if the query had failed.
+	// However, the validatorID is assumed to be authenticated.
+	JustifiedChits(validatorID ids.ShortID, requestID uint32, containerIDs []ids.ID, heights []uint64) error
+
 	// Notify this engine that a query it issued has failed.
 	//
 	// This function will be called if the engine sent a PullQuery or PushQuery
@@ -277,6 +303,22 @@ type QueryHandler interface {
 	QueryFailed(validatorID ids.ShortID, requestID uint32) error
 }
 
+// GossipHandler defines how a consensus engine reacts to gossip messages from
+// other validators. Returned errors should be treated as fatal and require the
+// chain to shutdown.
+type GossipHandler interface {
+	// Notify this engine that the specified transactions were accepted in the
+	// specified container by another validator.
+	//
+	// This function can be called by any validator. It is not safe to assume
+	// that the transactions are actually in the container. However, the
+	// validatorID is assumed to be authenticated.
+	//
+	// If any of the transactions are unknown, this engine may request the
+	// container from the validator.
+	GossipTxs(validatorID ids.ShortID, containerID ids.ID, txIDs []ids.ID) error
+}
+
 // InternalHandler defines how this consensus engine reacts to messages from
 // other components of this validator. Functions only return fatal errors if
 // they occur.
@@ -304,3 +346,20 @@ type InternalHandler interface {
 	// Notify this engine of a removed peer.
 	Disconnected(validatorID ids.ShortID) error
 }
+
+// CrossChainHandler defines how this consensus engine reacts to messages from
+// the other chains that this node validates. Engines aren't required to
+// implement it; cross-chain messages sent to engines that don't are dropped.
+// Functions only return fatal errors if they occur.
+type CrossChainHandler interface {
+	// Notify this engine of a request from the chain [requestingChainID].
+	//
+	// The router only delivers requests from chains that are allowed to make
+	// them: chains of the same subnet, or any chain if this chain is in the
+	// primary network.
+	CrossChainRequest(requestingChainID ids.ID, requestID uint32, request []byte) error
+
+	// Notify this engine of a response from the chain [respondingChainID] to
+	// the request with ID [requestID] that this engine made.
+	CrossChainResponse(respondingChainID ids.ID, requestID uint32, response []byte) error
+}
@@ -9,12 +9,12 @@ import (
 	"github.com/ava-labs/avalanchego/database"
 	"github.com/ava-labs/avalanchego/database/versiondb"
 	"github.com/ava-labs/avalanchego/ids"
+	"github.com/ava-labs/avalanchego/utils/hashing"
 	"github.com/ava-labs/avalanchego/utils/wrappers"
 )
 
 var (
-	errEmpty     = errors.New("no available containers")
-	errDuplicate = errors.New("duplicated container")
+	errEmpty = errors.New("no available containers")
 )
 
 // Jobs ...
@@ -44,17 +44,35 @@ func New(db database.Database) (*Jobs, error) {
 // SetParser ...
 func (j *Jobs) SetParser(parser Parser) { j.parser = parser }
 
-// Push ...
-func (j *Jobs) Push(job Job) error {
+// Push adds a new job to the queue. Returns true if the job was added to the
+// queue and false if the queue already contained the job.
+//
+// A job is considered to be in the queue if either a job with the same ID or a
+// job with the same bytes was previously pushed. This prevents identical
+// containers that arrive concurrently from being written more than once.
+func (j *Jobs) Push(job Job) (bool, error) {
+	if has, err := j.Has(job); err != nil || has {
+		return false, err
+	}
+
 	deps, err := job.MissingDependencies()
 	if err != nil {
-		return err
+		return false, err
 	}
 	if deps.Len() != 0 {
-		return j.block(job, deps)
+		return true, j.block(job, deps)
 	}
 
-	return j.push(job)
+	return true, j.push(job)
+}
+
+// Has returns true if a job with the same ID or the same bytes as [job] was
+// previously pushed.
+func (j *Jobs) Has(job Job) (bool, error) {
+	if has, err := j.state.HasJob(j.db, job.ID()); err != nil || has {
+		return has, err
+	}
+	return j.state.HasContent(j.db, hashing.ComputeHash256Array(job.Bytes()))
 }
 
 // Pop ...
@@ -131,13 +149,7 @@ func (j *Jobs) Execute(job Job) error {
 func (j *Jobs) Commit() error { return j.db.Commit() }
 
 func (j *Jobs) push(job Job) error {
-	if has, err := j.state.HasJob(j.db, job.ID()); err != nil {
-		return err
-	} else if has {
-		return errDuplicate
-	}
-
-	if err := j.state.SetJob(j.db, job); err != nil {
+	if err := j.setJob(job); err != nil {
 		return err
 	}
 
@@ -152,13 +164,7 @@ func (j *Jobs) push(job Job) error {
 }
 
 func (j *Jobs) block(job Job, deps ids.Set) error {
-	if has, err := j.state.HasJob(j.db, job.ID()); err != nil {
-		return err
-	} else if has {
-		return errDuplicate
-	}
-
-	if err := j.state.SetJob(j.db, job); err != nil {
+	if err := j.setJob(job); err != nil {
 		return err
 	}
 
@@ -171,3 +177,11 @@ func (j *Jobs) block(job Job, deps ids.Set) error {
 
 	return nil
 }
+
+// setJob stores [job] by its ID and marks its bytes as seen
+func (j *Jobs) setJob(job Job) error {
+	if err := j.state.SetJob(j.db, job); err != nil {
+		return err
+	}
+	return j.state.AddContent(j.db, hashing.ComputeHash256Array(job.Bytes()))
+}
@@ -54,7 +54,7 @@ func TestPushPop(t *testing.T) {
 		BytesF:               func() []byte { return []byte{0} },
 	}
 
-	if err := jobs.Push(job); err != nil {
+	if _, err := jobs.Push(job); err != nil {
 		t.Fatal(err)
 	}
 
@@ -133,11 +133,11 @@ func TestExecute(t *testing.T) {
 		BytesF:               func() []byte { return []byte{1} },
 	}
 
-	if err := jobs.Push(job0); err != nil {
+	if _, err := jobs.Push(job0); err != nil {
 		t.Fatal(err)
 	}
 
-	if err := jobs.Push(job1); err != nil {
+	if _, err := jobs.Push(job1); err != nil {
 		t.Fatal(err)
 	}
 
@@ -210,12 +210,14 @@ func TestDuplicatedExecutablePush(t *testing.T) {
 		BytesF:               func() []byte { return []byte{0} },
 	}
 
-	if err := jobs.Push(job); err != nil {
+	if _, err := jobs.Push(job); err != nil {
 		t.Fatal(err)
 	}
 
-	if err := jobs.Push(job); err == nil {
-		t.Fatalf("Should have failed on push")
+	if pushed, err := jobs.Push(job); err != nil {
+		t.Fatal(err)
+	} else if pushed {
+		t.Fatalf("Shouldn't have pushed a duplicated job")
 	}
 
 	if err := jobs.Commit(); err != nil {
@@ -296,12 +298,14 @@ func TestDuplicatedNotExecutablePush(t *testing.T) {
 		BytesF: func() []byte { return []byte{0} },
 	}
 
-	if err := jobs.Push(job1); err != nil {
+	if _, err := jobs.Push(job1); err != nil {
 		t.Fatal(err)
 	}
 
-	if err := jobs.Push(job1); err == nil {
-		t.Fatalf("should have errored on pushing a duplicate job")
+	if pushed, err := jobs.Push(job1); err != nil {
+		t.Fatal(err)
+	} else if pushed {
+		t.Fatalf("Shouldn't have pushed a duplicated job")
 	}
 
 	if err := jobs.Commit(); err != nil {
@@ -315,7 +319,7 @@ func TestDuplicatedNotExecutablePush(t *testing.T) {
 
 	jobs.SetParser(parser)
 
-	if err := jobs.Push(job0); err != nil {
+	if _, err := jobs.Push(job0); err != nil {
 		t.Fatal(err)
 	}
 
@@ -370,3 +374,163 @@ func TestDuplicatedNotExecutablePush(t *testing.T) {
 		t.Fatalf("Shouldn't have a container ready to pop")
 	}
 }
+
+// Test that a job with the same bytes as a previously pushed job, but a
+// different ID, is only added once
+func TestDuplicatedContentPush(t *testing.T) {
+	parser := &TestParser{T: t}
+	db := memdb.New()
+
+	jobs, err := New(db)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	jobs.SetParser(parser)
+
+	id0 := ids.Empty.Prefix(0)
+	id1 := ids.Empty.Prefix(1)
+	job0 := &TestJob{
+		T: t,
+
+		IDF:                  func() ids.ID { return id0 },
+		MissingDependenciesF: func() (ids.Set, error) { return ids.Set{}, nil },
+		BytesF:               func() []byte { return []byte{0} },
+	}
+	job1 := &TestJob{
+		T: t,
+
+		IDF:                  func() ids.ID { return id1 },
+		MissingDependenciesF: func() (ids.Set, error) { return ids.Set{}, nil },
+		BytesF:               func() []byte { return []byte{0} },
+	}
+
+	if pushed, err := jobs.Push(job0); err != nil {
+		t.Fatal(err)
+	} else if !pushed {
+		t.Fatalf("Should have pushed the job")
+	}
+
+	if has, err := jobs.Has(job1); err != nil {
+		t.Fatal(err)
+	} else if !has {
+		t.Fatalf("Should have reported a job with the same bytes")
+	}
+
+	if pushed, err := jobs.Push(job1); err != nil {
+		t.Fatal(err)
+	} else if pushed {
+		t.Fatalf("Shouldn't have pushed a job with duplicated bytes")
+	}
+
+	if err := jobs.Commit(); err != nil {
+		t.Fatal(err)
+	}
+
+	jobs, err = New(db)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	jobs.SetParser(parser)
+
+	if pushed, err := jobs.Push(job1); err != nil {
+		t.Fatal(err)
+	} else if pushed {
+		t.Fatalf("Shouldn't have pushed a job with duplicated bytes after a restart")
+	}
+
+	parser.ParseF = func(b []byte) (Job, error) { return job0, nil }
+
+	if _, err := jobs.Pop(); err != nil {
+		t.Fatal(err)
+	}
+
+	if hasNext, err := jobs.HasNext(); err != nil {
+		t.Fatal(err)
+	} else if hasNext {
+		t.Fatalf("Shouldn't have a container ready to pop")
+	}
+}
+
+// Test that jobs are popped in order when they're read ahead of being popped,
+// including when jobs are pushed between pops.
+func TestPopReadsAhead(t *testing.T) {
+	parser := &TestParser{T: t}
+	db := memdb.New()
+
+	jobs, err := New(db)
+	if err != nil {
+		t.Fatal(err)
+	}
+	jobs.SetParser(parser)
+
+	newJob := func(i int) *TestJob {
+		id := ids.Empty.Prefix(uint64(i))
+		b := id[:]
+		return &TestJob{
+			T: t,
+
+			IDF:                  func() ids.ID { return id },
+			MissingDependenciesF: func() (ids.Set, error) { return ids.Set{}, nil },
+			ExecuteF:             func() error { return nil },
+			BytesF:               func() []byte { return b },
+		}
+	}
+	numJobs := 2*stackReadAhead + 1
+	jobsByBytes := map[string]*TestJob{}
+	for i := 0; i < numJobs+1; i++ {
+		job := newJob(i)
+		jobsByBytes[string(job.Bytes())] = job
+	}
+	parser.ParseF = func(b []byte) (Job, error) {
+		job, ok := jobsByBytes[string(b)]
+		if !ok {
+			t.Fatalf("Unknown job")
+		}
+		return job, nil
+	}
+
+	for i := 0; i < numJobs; i++ {
+		if _, err := jobs.Push(newJob(i)); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	job, err := jobs.Pop()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if job.ID() != ids.Empty.Prefix(uint64(numJobs-1)) {
+		t.Fatalf("Popped the wrong job")
+	}
+	if len(jobs.state.stackCache) != stackReadAhead-1 {
+		t.Fatalf("Should have read ahead %d jobs, read %d", stackReadAhead-1, len(jobs.state.stackCache))
+	}
+
+	// A job pushed after reading ahead is popped next
+	if _, err := jobs.Push(newJob(numJobs)); err != nil {
+		t.Fatal(err)
+	}
+	expected := []int{numJobs}
+	for i := numJobs - 2; i >= 0; i-- {
+		expected = append(expected, i)
+	}
+	for _, i := range expected {
+		job, err := jobs.Pop()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if job.ID() != ids.Empty.Prefix(uint64(i)) {
+			t.Fatalf("Popped the wrong job")
+		}
+	}
+	if hasNext, err := jobs.HasNext(); err != nil {
+		t.Fatal(err)
+	} else if hasNext {
+		t.Fatalf("Should have popped every job")
+	}
+	if len(jobs.state.stackCache) != 0 {
+		t.Fatalf("Shouldn't have kept popped jobs")
+	}
+}
@@ -16,13 +16,28 @@ const (
 	stackID
 	jobID
 	blockingID
+	contentID
+)
+
+const (
+	// Number of stack entries that are read, in a single pass over the
+	// database, when an entry that wasn't read ahead is needed. As the stack
+	// is popped in order, this turns most of the reads of its entries into
+	// sequential reads.
+	stackReadAhead = 64
 )
 
 var (
 	stackSize = []byte{stackSizeID}
 )
 
-type prefixedState struct{ state }
+type prefixedState struct {
+	state
+
+	// stack index --> bytes of the job at that index, for the entries that
+	// were read ahead of being needed
+	stackCache map[uint32][]byte
+}
 
 func (ps *prefixedState) SetStackSize(db database.Database, size uint32) error {
 	return ps.state.SetInt(db, stackSize, size)
@@ -33,30 +48,59 @@ func (ps *prefixedState) StackSize(db database.Database) (uint32, error) {
 }
 
 func (ps *prefixedState) SetStackIndex(db database.Database, index uint32, job Job) error {
-	p := wrappers.Packer{Bytes: make([]byte, 1+wrappers.IntLen)}
-
-	p.PackByte(stackID)
-	p.PackInt(index)
-
-	return ps.state.SetJob(db, p.Bytes, job)
+	delete(ps.stackCache, index)
+	return ps.state.SetJob(db, stackKey(index), job)
 }
 
 func (ps *prefixedState) DeleteStackIndex(db database.Database, index uint32) error {
-	p := wrappers.Packer{Bytes: make([]byte, 1+wrappers.IntLen)}
-
-	p.PackByte(stackID)
-	p.PackInt(index)
-
-	return db.Delete(p.Bytes)
+	delete(ps.stackCache, index)
+	return db.Delete(stackKey(index))
 }
 
+// StackIndex returns the job at [index] of the stack. As the stack is popped
+// from the top, the entries below [index] are read ahead along with it.
 func (ps *prefixedState) StackIndex(db database.Database, index uint32) (Job, error) {
-	p := wrappers.Packer{Bytes: make([]byte, 1+wrappers.IntLen)}
+	jobBytes, ok := ps.stackCache[index]
+	if !ok {
+		if err := ps.readStackAhead(db, index); err != nil {
+			return nil, err
+		}
+		if jobBytes, ok = ps.stackCache[index]; !ok {
+			return nil, database.ErrNotFound
+		}
+	}
+	return ps.state.jobs.parser.Parse(jobBytes)
+}
 
-	p.PackByte(stackID)
-	p.PackInt(index)
+// readStackAhead caches the stack entries from [index] down to
+// [stackReadAhead] entries below it. The entries are read in ascending order
+// of their keys, with a single iterator.
+func (ps *prefixedState) readStackAhead(db database.Database, index uint32) error {
+	if ps.stackCache == nil {
+		ps.stackCache = make(map[uint32][]byte, stackReadAhead)
+	}
 
-	return ps.state.Job(db, p.Bytes)
+	start := uint32(0)
+	if index >= stackReadAhead {
+		start = index - stackReadAhead + 1
+	}
+	iter := db.NewIteratorWithStartAndPrefix(stackKey(start), []byte{stackID})
+	defer iter.Release()
+
+	for iter.Next() {
+		p := wrappers.Packer{Bytes: iter.Key()}
+		p.UnpackByte()
+		entryIndex := p.UnpackInt()
+		if p.Errored() || entryIndex > index {
+			break
+		}
+		// The iterator may reuse its buffers, so the value is copied
+		value := iter.Value()
+		jobBytes := make([]byte, len(value))
+		copy(jobBytes, value)
+		ps.stackCache[entryIndex] = jobBytes
+	}
+	return iter.Error()
 }
 
 func (ps *prefixedState) SetJob(db database.Database, job Job) error {
@@ -128,3 +172,31 @@ func (ps *prefixedState) Blocking(db database.Database, id ids.ID) ([]ids.ID, er
 
 	return ps.state.IDs(db, p.Bytes)
 }
+
+// stackKey returns the key of [index] of the stack
+func stackKey(index uint32) []byte {
+	p := wrappers.Packer{Bytes: make([]byte, 1+wrappers.IntLen)}
+
+	p.PackByte(stackID)
+	p.PackInt(index)
+
+	return p.Bytes
+}
+
+func (ps *prefixedState) AddContent(db database.Database, contentHash hashing.Hash256) error {
+	p := wrappers.Packer{Bytes: make([]byte, 1+hashing.HashLen)}
+
+	p.PackByte(contentID)
+	p.PackFixedBytes(contentHash[:])
+
+	return db.Put(p.Bytes, nil)
+}
+
+func (ps *prefixedState) HasContent(db database.Database, contentHash hashing.Hash256) (bool, error) {
+	p := wrappers.Packer{Bytes: make([]byte, 1+hashing.HashLen)}
+
+	p.PackByte(contentID)
+	p.PackFixedBytes(contentHash[:])
+
+	return db.Has(p.Bytes)
+}
@@ -48,6 +48,19 @@ func TestRequests(t *testing.T) {
 	length = req.Len()
 	assert.Equal(t, 2, length, "should have had two outstanding requests")
 
+	length = req.LenOf(ids.ShortEmpty)
+	assert.Equal(t, 2, length, "should have had two outstanding requests to the validator")
+
+	length = req.LenOf(ids.ShortID{1})
+	assert.Equal(t, 0, length, "shouldn't have had outstanding requests to the validator")
+
+	requestedID, ok := req.Get(ids.ShortEmpty, 10)
+	assert.True(t, ok, "should have had the outstanding request")
+	assert.Equal(t, ids.Empty.Prefix(0), requestedID, "should have returned the requested ID")
+
+	_, ok = req.Get(ids.ShortID{1}, 10)
+	assert.False(t, ok, "shouldn't have had the outstanding request")
+
 	_, removed = req.Remove(ids.ShortEmpty, 1)
 	assert.False(t, removed, "shouldn't have removed the request")
 
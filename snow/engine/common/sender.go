@@ -58,6 +58,11 @@ type FetchSender interface {
 	// has body <container>
 	Put(validatorID ids.ShortID, requestID uint32, containerID ids.ID, container []byte)
 
+	// Tell the specified validator that the container whose ID is <containerID>
+	// has body <container>, along with the ancestors of the container that
+	// the validator is likely missing
+	HintedPut(validatorID ids.ShortID, requestID uint32, containerID ids.ID, container []byte, hints [][]byte)
+
 	// Give the specified validator several containers at once
 	// Should be in response to a GetAncestors message with request ID [requestID] from the validator
 	MultiPut(validatorID ids.ShortID, requestID uint32, containers [][]byte)
@@ -78,6 +83,11 @@ type QuerySender interface {
 
 	// Chits sends chits to the specified validator
 	Chits(validatorID ids.ShortID, requestID uint32, votes []ids.ID)
+
+	// JustifiedChits sends chits to the specified validator along with the
+	// height of each voted container, so that the validator can detect
+	// nonsensical votes
+	JustifiedChits(validatorID ids.ShortID, requestID uint32, votes []ids.ID, heights []uint64)
 }
 
 // Gossiper defines how a consensus engine gossips a container on the accepted
@@ -85,4 +95,13 @@ type QuerySender interface {
 type Gossiper interface {
 	// Gossip gossips the provided container throughout the network
 	Gossip(containerID ids.ID, container []byte)
+
+	// GossipToValidators gossips the provided container only to peers that
+	// are validators, even if the network gossips to all peers by default
+	GossipToValidators(containerID ids.ID, container []byte)
+
+	// GossipTxs gossips the IDs of the txs in the provided container
+	// throughout the network, so that peers missing them can fetch the
+	// container
+	GossipTxs(containerID ids.ID, txIDs []ids.ID)
 }
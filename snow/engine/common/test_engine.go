@@ -37,12 +37,16 @@ type EngineTest struct {
 	CantGetFailed,
 	CantGetAncestorsFailed,
 	CantPut,
+	CantHintedPut,
 	CantMultiPut,
 
 	CantPushQuery,
 	CantPullQuery,
 	CantQueryFailed,
 	CantChits,
+	CantJustifiedChits,
+
+	CantGossipTxs,
 
 	CantConnected,
 	CantDisconnected,
@@ -56,9 +60,12 @@ type EngineTest struct {
 	GetF, GetAncestorsF, PullQueryF                    func(validatorID ids.ShortID, requestID uint32, containerID ids.ID) error
 	PutF, PushQueryF                                   func(validatorID ids.ShortID, requestID uint32, containerID ids.ID, container []byte) error
 	MultiPutF                                          func(validatorID ids.ShortID, requestID uint32, containers [][]byte) error
+	HintedPutF                                         func(validatorID ids.ShortID, requestID uint32, containerID ids.ID, container []byte, hints [][]byte) error
 	AcceptedFrontierF, GetAcceptedF, AcceptedF, ChitsF func(validatorID ids.ShortID, requestID uint32, containerIDs []ids.ID) error
 	GetAcceptedFrontierF, GetFailedF, GetAncestorsFailedF,
 	QueryFailedF, GetAcceptedFrontierFailedF, GetAcceptedFailedF func(validatorID ids.ShortID, requestID uint32) error
+	JustifiedChitsF           func(validatorID ids.ShortID, requestID uint32, containerIDs []ids.ID, heights []uint64) error
+	GossipTxsF                func(validatorID ids.ShortID, containerID ids.ID, txIDs []ids.ID) error
 	ConnectedF, DisconnectedF func(validatorID ids.ShortID) error
 	HealthF                   func() (interface{}, error)
 }
@@ -90,12 +97,16 @@ func (e *EngineTest) Default(cant bool) {
 	e.CantGetAncestorsFailed = cant
 	e.CantGetFailed = cant
 	e.CantPut = cant
+	e.CantHintedPut = cant
 	e.CantMultiPut = cant
 
 	e.CantPushQuery = cant
 	e.CantPullQuery = cant
 	e.CantQueryFailed = cant
 	e.CantChits = cant
+	e.CantJustifiedChits = cant
+
+	e.CantGossipTxs = cant
 
 	e.CantConnected = cant
 	e.CantDisconnected = cant
@@ -325,6 +336,20 @@ func (e *EngineTest) Put(validatorID ids.ShortID, requestID uint32, containerID
 	return errors.New("unexpectedly called Put")
 }
 
+// HintedPut ...
+func (e *EngineTest) HintedPut(validatorID ids.ShortID, requestID uint32, containerID ids.ID, container []byte, hints [][]byte) error {
+	if e.HintedPutF != nil {
+		return e.HintedPutF(validatorID, requestID, containerID, container, hints)
+	}
+	if !e.CantHintedPut {
+		return nil
+	}
+	if e.T != nil {
+		e.T.Fatalf("Unexpectedly called HintedPut")
+	}
+	return errors.New("unexpectedly called HintedPut")
+}
+
 // MultiPut ...
 func (e *EngineTest) MultiPut(validatorID ids.ShortID, requestID uint32, containers [][]byte) error {
 	if e.MultiPutF != nil {
@@ -395,6 +420,34 @@ func (e *EngineTest) Chits(validatorID ids.ShortID, requestID uint32, containerI
 	return errors.New("unexpectedly called Chits")
 }
 
+// JustifiedChits ...
+func (e *EngineTest) JustifiedChits(validatorID ids.ShortID, requestID uint32, containerIDs []ids.ID, heights []uint64) error {
+	if e.JustifiedChitsF != nil {
+		return e.JustifiedChitsF(validatorID, requestID, containerIDs, heights)
+	}
+	if !e.CantJustifiedChits {
+		return nil
+	}
+	if e.T != nil {
+		e.T.Fatalf("Unexpectedly called JustifiedChits")
+	}
+	return errors.New("unexpectedly called JustifiedChits")
+}
+
+// GossipTxs ...
+func (e *EngineTest) GossipTxs(validatorID ids.ShortID, containerID ids.ID, txIDs []ids.ID) error {
+	if e.GossipTxsF != nil {
+		return e.GossipTxsF(validatorID, containerID, txIDs)
+	}
+	if !e.CantGossipTxs {
+		return nil
+	}
+	if e.T != nil {
+		e.T.Fatalf("Unexpectedly called GossipTxs")
+	}
+	return errors.New("unexpectedly called GossipTxs")
+}
+
 // Connected ...
 func (e *EngineTest) Connected(validatorID ids.ShortID) error {
 	if e.ConnectedF != nil {
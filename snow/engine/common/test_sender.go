@@ -15,9 +15,9 @@ type SenderTest struct {
 
 	CantGetAcceptedFrontier, CantAcceptedFrontier,
 	CantGetAccepted, CantAccepted,
-	CantGet, CantGetAncestors, CantPut, CantMultiPut,
-	CantPullQuery, CantPushQuery, CantChits,
-	CantGossip bool
+	CantGet, CantGetAncestors, CantPut, CantHintedPut, CantMultiPut,
+	CantPullQuery, CantPushQuery, CantChits, CantJustifiedChits,
+	CantGossip, CantGossipToValidators, CantGossipTxs bool
 
 	GetAcceptedFrontierF func(ids.ShortSet, uint32)
 	AcceptedFrontierF    func(ids.ShortID, uint32, []ids.ID)
@@ -26,11 +26,15 @@ type SenderTest struct {
 	GetF                 func(ids.ShortID, uint32, ids.ID)
 	GetAncestorsF        func(ids.ShortID, uint32, ids.ID)
 	PutF                 func(ids.ShortID, uint32, ids.ID, []byte)
+	HintedPutF           func(ids.ShortID, uint32, ids.ID, []byte, [][]byte)
 	MultiPutF            func(ids.ShortID, uint32, [][]byte)
 	PushQueryF           func(ids.ShortSet, uint32, ids.ID, []byte)
 	PullQueryF           func(ids.ShortSet, uint32, ids.ID)
 	ChitsF               func(ids.ShortID, uint32, []ids.ID)
+	JustifiedChitsF      func(ids.ShortID, uint32, []ids.ID, []uint64)
 	GossipF              func(ids.ID, []byte)
+	GossipToValidatorsF  func(ids.ID, []byte)
+	GossipTxsF           func(ids.ID, []ids.ID)
 }
 
 // Default set the default callable value to [cant]
@@ -42,11 +46,15 @@ func (s *SenderTest) Default(cant bool) {
 	s.CantGet = cant
 	s.CantGetAccepted = cant
 	s.CantPut = cant
+	s.CantHintedPut = cant
 	s.CantMultiPut = cant
 	s.CantPullQuery = cant
 	s.CantPushQuery = cant
 	s.CantChits = cant
+	s.CantJustifiedChits = cant
 	s.CantGossip = cant
+	s.CantGossipToValidators = cant
+	s.CantGossipTxs = cant
 }
 
 // GetAcceptedFrontier calls GetAcceptedFrontierF if it was initialized. If it
@@ -126,6 +134,17 @@ func (s *SenderTest) Put(vdr ids.ShortID, requestID uint32, vtxID ids.ID, vtx []
 	}
 }
 
+// HintedPut calls HintedPutF if it was initialized. If it wasn't initialized
+// and this function shouldn't be called and testing was initialized, then
+// testing will fail.
+func (s *SenderTest) HintedPut(vdr ids.ShortID, requestID uint32, vtxID ids.ID, vtx []byte, hints [][]byte) {
+	if s.HintedPutF != nil {
+		s.HintedPutF(vdr, requestID, vtxID, vtx, hints)
+	} else if s.CantHintedPut && s.T != nil {
+		s.T.Fatalf("Unexpectedly called HintedPut")
+	}
+}
+
 // MultiPut calls MultiPutF if it was initialized. If it wasn't initialized and this
 // function shouldn't be called and testing was initialized, then testing will
 // fail.
@@ -170,6 +189,17 @@ func (s *SenderTest) Chits(vdr ids.ShortID, requestID uint32, votes []ids.ID) {
 	}
 }
 
+// JustifiedChits calls JustifiedChitsF if it was initialized. If it wasn't
+// initialized and this function shouldn't be called and testing was
+// initialized, then testing will fail.
+func (s *SenderTest) JustifiedChits(vdr ids.ShortID, requestID uint32, votes []ids.ID, heights []uint64) {
+	if s.JustifiedChitsF != nil {
+		s.JustifiedChitsF(vdr, requestID, votes, heights)
+	} else if s.CantJustifiedChits && s.T != nil {
+		s.T.Fatalf("Unexpectedly called JustifiedChits")
+	}
+}
+
 // Gossip calls GossipF if it was initialized. If it wasn't initialized and this
 // function shouldn't be called and testing was initialized, then testing will
 // fail.
@@ -180,3 +210,25 @@ func (s *SenderTest) Gossip(containerID ids.ID, container []byte) {
 		s.T.Fatalf("Unexpectedly called Gossip")
 	}
 }
+
+// GossipToValidators calls GossipToValidatorsF if it was initialized. If it
+// wasn't initialized and this function shouldn't be called and testing was
+// initialized, then testing will fail.
+func (s *SenderTest) GossipToValidators(containerID ids.ID, container []byte) {
+	if s.GossipToValidatorsF != nil {
+		s.GossipToValidatorsF(containerID, container)
+	} else if s.CantGossipToValidators && s.T != nil {
+		s.T.Fatalf("Unexpectedly called GossipToValidators")
+	}
+}
+
+// GossipTxs calls GossipTxsF if it was initialized. If it wasn't initialized
+// and this function shouldn't be called and testing was initialized, then
+// testing will fail.
+func (s *SenderTest) GossipTxs(containerID ids.ID, txIDs []ids.ID) {
+	if s.GossipTxsF != nil {
+		s.GossipTxsF(containerID, txIDs)
+	} else if s.CantGossipTxs && s.T != nil {
+		s.T.Fatalf("Unexpectedly called GossipTxs")
+	}
+}
@@ -83,3 +83,11 @@ type StaticVM interface {
 	// genesis bytes this VM can interpret.
 	CreateStaticHandlers() (map[string]*HTTPHandler, error)
 }
+
+// CacheFlusher describes a component, such as a VM, that is able to drop its
+// in-memory caches. Flushing must not affect correctness, only performance.
+type CacheFlusher interface {
+	// FlushCaches removes all entries from the caches. The context lock is
+	// held while this is called.
+	FlushCaches()
+}
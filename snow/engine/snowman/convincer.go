@@ -7,15 +7,18 @@ import (
 	"github.com/ava-labs/avalanchego/ids"
 	"github.com/ava-labs/avalanchego/snow/consensus/snowman"
 	"github.com/ava-labs/avalanchego/snow/engine/common"
+	"github.com/ava-labs/avalanchego/snow/engine/snowman/block"
 	"github.com/ava-labs/avalanchego/utils/wrappers"
 )
 
 // convincer sends chits to [vdr] once all its dependencies are met
 type convincer struct {
 	consensus snowman.Consensus
+	vm        block.ChainVM
 	sender    common.Sender
 	vdr       ids.ShortID
 	requestID uint32
+	justify   bool
 	sent      bool
 	abandoned bool
 	deps      ids.Set
@@ -42,6 +45,16 @@ func (c *convincer) Update() {
 	}
 	c.sent = true
 
-	pref := []ids.ID{c.consensus.Preference()}
-	c.sender.Chits(c.vdr, c.requestID, pref)
+	prefID := c.consensus.Preference()
+	pref := []ids.ID{prefID}
+	if !c.justify {
+		c.sender.Chits(c.vdr, c.requestID, pref)
+		return
+	}
+	blk, err := c.vm.GetBlock(prefID)
+	if err != nil {
+		c.sender.Chits(c.vdr, c.requestID, pref)
+		return
+	}
+	c.sender.JustifiedChits(c.vdr, c.requestID, pref, []uint64{blk.Height()})
 }
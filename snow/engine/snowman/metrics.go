@@ -12,6 +12,7 @@ import (
 type metrics struct {
 	numRequests, numBlocked prometheus.Gauge
 	getAncestorsBlks        prometheus.Histogram
+	unjustifiedChits        prometheus.Counter
 }
 
 // Initialize the metrics
@@ -43,11 +44,18 @@ func (m *metrics) Initialize(namespace string, registerer prometheus.Registerer)
 		},
 	})
 
+	m.unjustifiedChits = prometheus.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "unjustified_chits",
+		Help:      "Number of chits dropped because their justification contradicted the local chain",
+	})
+
 	errs := wrappers.Errs{}
 	errs.Add(
 		registerer.Register(m.numRequests),
 		registerer.Register(m.numBlocked),
 		registerer.Register(m.getAncestorsBlks),
+		registerer.Register(m.unjustifiedChits),
 	)
 	return errs.Err
 }
@@ -53,6 +53,9 @@ type Transitive struct {
 	// processing blocks has gone below the optimal number.
 	pendingBuildBlocks int
 
+	// Last accepted block that was last published to the consensus dispatcher
+	publishedFrontier ids.ID
+
 	// errs tracks if an error has occurred in a callback
 	errs wrappers.Errs
 }
@@ -124,9 +127,23 @@ func (t *Transitive) finishBootstrapping() error {
 	}
 
 	t.Ctx.Log.Info("bootstrapping finished with %s as the last accepted block", lastAcceptedID)
+	t.publishFrontier()
 	return nil
 }
 
+// publishFrontier notifies the consensus dispatcher of the last accepted
+// block, if it changed since it was last published, so that components that
+// depend on the accepted frontier don't need to poll it
+func (t *Transitive) publishFrontier() {
+	lastAcceptedID, height := t.Consensus.LastAccepted()
+	if lastAcceptedID == t.publishedFrontier {
+		return
+	}
+
+	t.publishedFrontier = lastAcceptedID
+	t.Ctx.ConsensusDispatcher.AdvanceFrontier(t.Ctx, height, []ids.ID{lastAcceptedID})
+}
+
 // Gossip implements the Engine interface
 func (t *Transitive) Gossip() error {
 	blkID, err := t.VM.LastAccepted()
@@ -199,6 +216,13 @@ func (t *Transitive) GetAncestors(vdr ids.ShortID, requestID uint32, blkID ids.I
 	return nil
 }
 
+// HintedPut implements the Engine interface. Parsed blocks aren't persisted
+// until they're issued, so the hints are ignored and missing ancestors are
+// requested as usual.
+func (t *Transitive) HintedPut(vdr ids.ShortID, requestID uint32, blkID ids.ID, blkBytes []byte, _ [][]byte) error {
+	return t.Put(vdr, requestID, blkID, blkBytes)
+}
+
 // Put implements the Engine interface
 func (t *Transitive) Put(vdr ids.ShortID, requestID uint32, blkID ids.ID, blkBytes []byte) error {
 	// bootstrapping isn't done --> we didn't send any gets --> this put is invalid
@@ -233,6 +257,12 @@ func (t *Transitive) Put(vdr ids.ShortID, requestID uint32, blkID ids.ID, blkByt
 	return t.buildBlocks()
 }
 
+// GossipTxs implements the Engine interface. Blocks aren't fetched based on the
+// txs they contain, so gossiped tx IDs are ignored.
+func (t *Transitive) GossipTxs(vdr ids.ShortID, blkID ids.ID, txIDs []ids.ID) error {
+	return nil
+}
+
 // GetFailed implements the Engine interface
 func (t *Transitive) GetFailed(vdr ids.ShortID, requestID uint32) error {
 	// not done bootstrapping --> didn't send a get --> this message is invalid
@@ -265,9 +295,11 @@ func (t *Transitive) PullQuery(vdr ids.ShortID, requestID uint32, blkID ids.ID)
 	// Will send chits once we've issued block [blkID] into consensus
 	c := &convincer{
 		consensus: t.Consensus,
+		vm:        t.VM,
 		sender:    t.Sender,
 		vdr:       vdr,
 		requestID: requestID,
+		justify:   t.Config.JustifyChits,
 		errs:      &t.errs,
 	}
 
@@ -358,6 +390,48 @@ func (t *Transitive) Chits(vdr ids.ShortID, requestID uint32, votes []ids.ID) er
 	return t.buildBlocks()
 }
 
+// JustifiedChits implements the Engine interface
+func (t *Transitive) JustifiedChits(vdr ids.ShortID, requestID uint32, votes []ids.ID, heights []uint64) error {
+	// if the engine hasn't been bootstrapped, we shouldn't be receiving chits
+	if !t.Ctx.IsBootstrapped() {
+		t.Ctx.Log.Debug("dropping JustifiedChits(%s, %d) due to bootstrapping", vdr, requestID)
+		return nil
+	}
+
+	if err := t.justify(votes, heights); err != nil {
+		t.Ctx.Log.Debug("dropping JustifiedChits(%s, %d) due to: %s", vdr, requestID, err)
+		t.unjustifiedChits.Inc()
+		return t.QueryFailed(vdr, requestID)
+	}
+	return t.Chits(vdr, requestID, votes)
+}
+
+// justify returns an error if the claimed [heights] of the blocks in [votes]
+// contradict the blocks known locally. Votes for unknown blocks can't be
+// checked, so they are assumed to be justified.
+func (t *Transitive) justify(votes []ids.ID, heights []uint64) error {
+	if len(votes) != len(heights) {
+		return fmt.Errorf("expected %d heights but got %d", len(votes), len(heights))
+	}
+	for i, blkID := range votes {
+		blk, err := t.VM.GetBlock(blkID)
+		if err != nil {
+			continue
+		}
+		switch blk.Status() {
+		case choices.Unknown:
+			continue
+		case choices.Rejected:
+			return fmt.Errorf("voted for rejected block %s", blkID)
+		}
+		if height := blk.Height(); height != heights[i] {
+			return fmt.Errorf("voted for block %s with height %d but claimed height %d",
+				blkID, height, heights[i])
+		}
+	}
+	return nil
+}
+
 // QueryFailed implements the Engine interface
 func (t *Transitive) QueryFailed(vdr ids.ShortID, requestID uint32) error {
 	// If the engine hasn't been bootstrapped, we didn't issue a query
@@ -583,7 +657,7 @@ func (t *Transitive) sendRequest(vdr ids.ShortID, blkID ids.ID) {
 func (t *Transitive) pullQuery(blkID ids.ID) {
 	t.Ctx.Log.Verbo("about to sample from: %s", t.Validators)
 	// The validators we will query
-	vdrs, err := t.Validators.Sample(t.Params.K)
+	vdrs, err := t.Validators.SampleWithSource(t.Params.K, t.Ctx.RandomSource())
 	vdrBag := ids.ShortBag{}
 	for _, vdr := range vdrs {
 		vdrBag.Add(vdr.ID())
@@ -603,7 +677,7 @@ func (t *Transitive) pullQuery(blkID ids.ID) {
 // send a push query for this block
 func (t *Transitive) pushQuery(blk snowman.Block) {
 	t.Ctx.Log.Verbo("about to sample from: %s", t.Validators)
-	vdrs, err := t.Validators.Sample(t.Params.K)
+	vdrs, err := t.Validators.SampleWithSource(t.Params.K, t.Ctx.RandomSource())
 	vdrBag := ids.ShortBag{}
 	for _, vdr := range vdrs {
 		vdrBag.Add(vdr.ID())
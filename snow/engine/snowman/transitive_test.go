@@ -11,6 +11,7 @@ import (
 	"github.com/prometheus/client_golang/prometheus"
 
 	"github.com/ava-labs/avalanchego/ids"
+	"github.com/ava-labs/avalanchego/snow"
 	"github.com/ava-labs/avalanchego/snow/choices"
 	"github.com/ava-labs/avalanchego/snow/consensus/snowball"
 	"github.com/ava-labs/avalanchego/snow/consensus/snowman"
@@ -2474,3 +2475,98 @@ func TestEngineNonPreferredAmplification(t *testing.T) {
 		t.Fatal(err)
 	}
 }
+
+func TestEngineJustify(t *testing.T) {
+	_, _, _, vm, te, gBlk := setup(t)
+
+	rejectedBlk := &snowman.TestBlock{
+		TestDecidable: choices.TestDecidable{
+			IDV:     ids.GenerateTestID(),
+			StatusV: choices.Rejected,
+		},
+		ParentV: gBlk,
+		HeightV: 1,
+	}
+	unknownBlkID := ids.GenerateTestID()
+
+	vm.GetBlockF = func(blkID ids.ID) (snowman.Block, error) {
+		switch blkID {
+		case gBlk.ID():
+			return gBlk, nil
+		case rejectedBlk.ID():
+			return rejectedBlk, nil
+		}
+		return nil, errUnknownBlock
+	}
+
+	if err := te.justify([]ids.ID{gBlk.ID()}, []uint64{gBlk.Height()}); err != nil {
+		t.Fatalf("Should have justified a vote consistent with the local chain: %s", err)
+	}
+	if err := te.justify([]ids.ID{unknownBlkID}, []uint64{100}); err != nil {
+		t.Fatalf("Should have justified a vote for an unknown block: %s", err)
+	}
+	if err := te.justify([]ids.ID{gBlk.ID()}, []uint64{gBlk.Height() + 1}); err == nil {
+		t.Fatalf("Should have rejected a vote with the wrong height")
+	}
+	if err := te.justify([]ids.ID{rejectedBlk.ID()}, []uint64{1}); err == nil {
+		t.Fatalf("Should have rejected a vote for a rejected block")
+	}
+}
+
+// frontierDispatcher records the accepted frontiers that were published
+type frontierDispatcher struct {
+	heights   []uint64
+	frontiers [][]ids.ID
+}
+
+func (*frontierDispatcher) Issue(*snow.Context, ids.ID, []byte)  {}
+func (*frontierDispatcher) Accept(*snow.Context, ids.ID, []byte) {}
+func (*frontierDispatcher) Reject(*snow.Context, ids.ID, []byte) {}
+func (d *frontierDispatcher) AdvanceFrontier(_ *snow.Context, height uint64, frontier []ids.ID) {
+	d.heights = append(d.heights, height)
+	d.frontiers = append(d.frontiers, frontier)
+}
+
+func TestEnginePublishesAcceptedFrontier(t *testing.T) {
+	_, _, _, _, te, gBlk := setup(t)
+
+	dispatcher := &frontierDispatcher{}
+	te.Ctx.ConsensusDispatcher = dispatcher
+
+	// The frontier was published once bootstrapping finished
+	te.publishFrontier()
+	if len(dispatcher.frontiers) != 0 {
+		t.Fatalf("Shouldn't have published an unchanged frontier")
+	}
+	if te.publishedFrontier != gBlk.ID() {
+		t.Fatalf("Should have published the last accepted block after bootstrapping")
+	}
+
+	blk := &snowman.TestBlock{
+		TestDecidable: choices.TestDecidable{
+			IDV:     ids.GenerateTestID(),
+			StatusV: choices.Processing,
+		},
+		ParentV: gBlk,
+		HeightV: 1,
+		BytesV:  []byte{1},
+	}
+	if err := te.Consensus.Add(blk); err != nil {
+		t.Fatal(err)
+	}
+	votes := ids.Bag{}
+	votes.Add(blk.ID())
+	if err := te.Consensus.RecordPoll(votes); err != nil {
+		t.Fatal(err)
+	}
+
+	te.publishFrontier()
+	switch {
+	case len(dispatcher.frontiers) != 1:
+		t.Fatalf("Should have published the advanced frontier once")
+	case dispatcher.heights[0] != 1:
+		t.Fatalf("Should have published height %d, but published %d", 1, dispatcher.heights[0])
+	case len(dispatcher.frontiers[0]) != 1 || dispatcher.frontiers[0][0] != blk.ID():
+		t.Fatalf("Should have published the accepted block as the frontier")
+	}
+}
@@ -53,6 +53,7 @@ func (v *voter) Update() {
 		v.t.errs.Add(err)
 		return
 	}
+	v.t.publishFrontier()
 
 	if err := v.t.VM.SetPreference(v.t.Consensus.Preference()); err != nil {
 		v.t.errs.Add(err)
@@ -0,0 +1,93 @@
+// (c) 2019-2020, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package events
+
+import (
+	"fmt"
+
+	"github.com/ava-labs/avalanchego/ids"
+)
+
+const (
+	minKeySetSize = 16
+)
+
+// Kind is the kind of object an event is about
+type Kind byte
+
+// Kinds of objects that can be blocked on
+const (
+	VertexKind Kind = iota + 1
+	TxKind
+)
+
+func (k Kind) String() string {
+	switch k {
+	case VertexKind:
+		return "vertex"
+	case TxKind:
+		return "tx"
+	default:
+		return fmt.Sprintf("Unknown(%d)", byte(k))
+	}
+}
+
+// Key identifies an event by the kind and ID of the object it's about, so that
+// events about different kinds of objects with the same ID are distinct
+type Key struct {
+	Kind Kind
+	ID   ids.ID
+}
+
+// VertexKey returns the key of events about the vertex [vtxID]
+func VertexKey(vtxID ids.ID) Key { return Key{Kind: VertexKind, ID: vtxID} }
+
+// TxKey returns the key of events about the tx [txID]
+func TxKey(txID ids.ID) Key { return Key{Kind: TxKind, ID: txID} }
+
+func (k Key) String() string { return fmt.Sprintf("%s %s", k.Kind, k.ID) }
+
+// KeySet is a set of keys
+type KeySet map[Key]struct{}
+
+func (s *KeySet) init() {
+	if *s == nil {
+		*s = make(map[Key]struct{}, minKeySetSize)
+	}
+}
+
+// Add all the keys to this set
+func (s *KeySet) Add(keys ...Key) {
+	s.init()
+	for _, key := range keys {
+		(*s)[key] = struct{}{}
+	}
+}
+
+// Contains returns true if the set contains [key]
+func (s *KeySet) Contains(key Key) bool {
+	_, contains := (*s)[key]
+	return contains
+}
+
+// Remove all the keys from this set
+func (s *KeySet) Remove(keys ...Key) {
+	for _, key := range keys {
+		delete(*s, key)
+	}
+}
+
+// Len returns the number of keys in this set
+func (s KeySet) Len() int { return len(s) }
+
+// CountKind returns the number of keys in this set of kind [kind]
+func (s KeySet) CountKind(kind Kind) int {
+	count := 0
+	for key := range s {
+		if key.Kind == kind {
+			count++
+		}
+	}
+	return count
+}
@@ -0,0 +1,103 @@
+// (c) 2019-2020, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package events
+
+import (
+	"fmt"
+	"strings"
+)
+
+// TypedBlockable defines what an object must implement to be able to block on
+// events about different kinds of objects
+type TypedBlockable interface {
+	// Keys of the events that this object is blocking on
+	Dependencies() KeySet
+	// Notify this object that an event has been fulfilled
+	Fulfill(Key)
+	// Notify this object that an event has been abandoned
+	Abandon(Key)
+	// Update the state of this object without changing the status of any events
+	Update()
+}
+
+// TypedBlocker tracks objects that are blocked on events about different kinds
+// of objects. Unlike Blocker, fulfilling or abandoning an event about one kind
+// of object never notifies objects blocked on another kind of object with the
+// same ID.
+type TypedBlocker map[Key][]TypedBlockable
+
+func (b *TypedBlocker) init() {
+	if *b == nil {
+		*b = make(map[Key][]TypedBlockable, minBlockerSize)
+	}
+}
+
+// Fulfill notifies all objects blocking on the event [key] that the event has
+// happened
+func (b *TypedBlocker) Fulfill(key Key) {
+	b.init()
+
+	blocking := (*b)[key]
+	delete(*b, key)
+
+	for _, pending := range blocking {
+		pending.Fulfill(key)
+	}
+}
+
+// Abandon notifies all objects blocking on the event [key] that the event has
+// been abandoned
+func (b *TypedBlocker) Abandon(key Key) {
+	b.init()
+
+	blocking := (*b)[key]
+	delete(*b, key)
+
+	for _, pending := range blocking {
+		pending.Abandon(key)
+	}
+}
+
+// Register a new TypedBlockable and its dependencies
+func (b *TypedBlocker) Register(pending TypedBlockable) {
+	b.init()
+
+	for key := range pending.Dependencies() {
+		(*b)[key] = append((*b)[key], pending)
+	}
+
+	pending.Update()
+}
+
+// Len returns the number of events of kind [kind] that objects are blocking on
+func (b TypedBlocker) Len(kind Kind) int {
+	count := 0
+	for key := range b {
+		if key.Kind == kind {
+			count++
+		}
+	}
+	return count
+}
+
+// PrefixedString returns the same value as the String function, with all the
+// new lines prefixed by [prefix]
+func (b *TypedBlocker) PrefixedString(prefix string) string {
+	b.init()
+
+	s := strings.Builder{}
+
+	s.WriteString(fmt.Sprintf("Blocking on %d events:", len(*b)))
+
+	for key, value := range *b {
+		s.WriteString(fmt.Sprintf("\n%s%s: %d",
+			prefix,
+			key,
+			len(value)))
+	}
+
+	return strings.TrimSuffix(s.String(), "\n")
+}
+
+func (b *TypedBlocker) String() string { return b.PrefixedString("") }
@@ -0,0 +1,64 @@
+// (c) 2019-2020, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package events
+
+import (
+	"testing"
+)
+
+type typedBlockable struct {
+	deps      KeySet
+	fulfilled []Key
+	abandoned []Key
+	updates   int
+}
+
+func (b *typedBlockable) Dependencies() KeySet { return b.deps }
+func (b *typedBlockable) Fulfill(key Key)      { b.fulfilled = append(b.fulfilled, key) }
+func (b *typedBlockable) Abandon(key Key)      { b.abandoned = append(b.abandoned, key) }
+func (b *typedBlockable) Update()              { b.updates++ }
+
+func TestTypedBlocker(t *testing.T) {
+	b := TypedBlocker(nil)
+
+	id := GenerateID()
+	vtxBlocked := &typedBlockable{}
+	vtxBlocked.deps.Add(VertexKey(id))
+	txBlocked := &typedBlockable{}
+	txBlocked.deps.Add(TxKey(id))
+
+	b.Register(vtxBlocked)
+	b.Register(txBlocked)
+
+	switch {
+	case vtxBlocked.updates != 1, txBlocked.updates != 1:
+		t.Fatalf("registering should have updated the blockable")
+	case b.Len(VertexKind) != 1, b.Len(TxKind) != 1:
+		t.Fatalf("should be blocking on a vertex and a tx")
+	}
+
+	// A tx with the same ID as a vertex doesn't fulfill the vertex dependency
+	b.Fulfill(TxKey(id))
+	switch {
+	case len(txBlocked.fulfilled) != 1 || txBlocked.fulfilled[0] != TxKey(id):
+		t.Fatalf("tx dependency should have been fulfilled")
+	case len(vtxBlocked.fulfilled) != 0:
+		t.Fatalf("vertex dependency shouldn't have been fulfilled by a tx")
+	case b.Len(TxKind) != 0, b.Len(VertexKind) != 1:
+		t.Fatalf("should only be blocking on the vertex")
+	}
+
+	b.Abandon(TxKey(id))
+	if len(vtxBlocked.abandoned) != 0 {
+		t.Fatalf("vertex dependency shouldn't have been abandoned by a tx")
+	}
+
+	b.Abandon(VertexKey(id))
+	switch {
+	case len(vtxBlocked.abandoned) != 1 || vtxBlocked.abandoned[0] != VertexKey(id):
+		t.Fatalf("vertex dependency should have been abandoned")
+	case len(b) != 0:
+		t.Fatalf("shouldn't be blocking on anything")
+	}
+}
@@ -35,6 +35,9 @@ type Benchlist interface {
 	// IsBenched returns true if messages to [validatorID]
 	// should not be sent over the network and should immediately fail.
 	IsBenched(validatorID ids.ShortID) bool
+	// Bench benches [validatorID] for between [duration]/2 and [duration],
+	// regardless of its recent failures, such as when it's misbehaving
+	Bench(validatorID ids.ShortID, duration time.Duration)
 }
 
 // Data about a validator who is benched
@@ -259,14 +262,27 @@ func (b *benchlist) RegisterFailure(validatorID ids.ShortID) {
 	b.failureStreaks[validatorID] = failureStreak
 
 	if failureStreak.consecutive >= b.threshold && now.After(failureStreak.firstFailure.Add(b.minimumFailingDuration)) {
-		b.bench(validatorID)
+		b.log.Debug("benching validator %s after %d consecutive failed queries", validatorID, b.threshold)
+		b.bench(validatorID, b.duration)
 	}
 
 }
 
+// Bench implements the Benchlist interface
+func (b *benchlist) Bench(validatorID ids.ShortID, duration time.Duration) {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+
+	if b.benchlistSet.Contains(validatorID) {
+		return
+	}
+	b.log.Debug("benching validator %s on request", validatorID)
+	b.bench(validatorID, duration)
+}
+
 // Assumes [b.lock] is held
 // Assumes [validatorID] is not already benched
-func (b *benchlist) bench(validatorID ids.ShortID) {
+func (b *benchlist) bench(validatorID ids.ShortID, duration time.Duration) {
 	benchedStake, err := b.vdrs.SubsetWeight(b.benchlistSet)
 	if err != nil {
 		// This should never happen
@@ -301,11 +317,11 @@ func (b *benchlist) bench(validatorID ids.ShortID) {
 		return
 	}
 
-	// Validator is benched for between [b.duration]/2 and [b.duration]
+	// Validator is benched for between [duration]/2 and [duration]
 	now := b.clock.Time()
-	minBenchDuration := b.duration / 2
+	minBenchDuration := duration / 2
 	minBenchedUntil := now.Add(minBenchDuration)
-	maxBenchedUntil := now.Add(b.duration)
+	maxBenchedUntil := now.Add(duration)
 	diff := maxBenchedUntil.Sub(minBenchedUntil)
 	benchedUntil := minBenchedUntil.Add(time.Duration(rand.Float64() * float64(diff))) // #nosec G404
 
@@ -317,10 +333,9 @@ func (b *benchlist) bench(validatorID ids.ShortID) {
 		&benchData{validatorID: validatorID, benchedUntil: benchedUntil},
 	)
 	b.log.Debug(
-		"benching validator %s for %s after %d consecutive failed queries.",
+		"benching validator %s for %s",
 		validatorID,
 		benchedUntil.Sub(now),
-		b.threshold,
 	)
 
 	// Set [b.timer] to fire when next validator should leave bench
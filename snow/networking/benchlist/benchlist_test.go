@@ -6,8 +6,8 @@ import (
 
 	"github.com/ava-labs/avalanchego/ids"
 	"github.com/ava-labs/avalanchego/snow/validators"
+	"github.com/ava-labs/avalanchego/utils/constants"
 	"github.com/ava-labs/avalanchego/utils/logging"
-	"github.com/ava-labs/avalanchego/utils/wrappers"
 	"github.com/prometheus/client_golang/prometheus"
 	"github.com/stretchr/testify/assert"
 )
@@ -18,24 +18,13 @@ var (
 
 // Test that validators are properly added to the bench
 func TestBenchlistAdd(t *testing.T) {
-	vdrs := validators.NewSet()
-	vdr0 := validators.GenerateRandomValidator(50)
-	vdr1 := validators.GenerateRandomValidator(50)
-	vdr2 := validators.GenerateRandomValidator(50)
-	vdr3 := validators.GenerateRandomValidator(50)
-	vdr4 := validators.GenerateRandomValidator(50)
-
-	errs := wrappers.Errs{}
-	errs.Add(
-		vdrs.AddWeight(vdr0.ID(), vdr0.Weight()),
-		vdrs.AddWeight(vdr1.ID(), vdr1.Weight()),
-		vdrs.AddWeight(vdr2.ID(), vdr2.Weight()),
-		vdrs.AddWeight(vdr3.ID(), vdr3.Weight()),
-		vdrs.AddWeight(vdr4.ID(), vdr4.Weight()),
-	)
-	if errs.Errored() {
-		t.Fatal(errs.Err)
+	fixtures := validators.NewTestSetBuilder(0).Add(50, 50, 50, 50, 50)
+	vdrs, err := fixtures.Set(constants.PrimaryNetworkID)
+	if err != nil {
+		t.Fatal(err)
 	}
+	vdrList := fixtures.Validators(constants.PrimaryNetworkID)
+	vdr0, vdr1, vdr2, vdr3, vdr4 := vdrList[0], vdrList[1], vdrList[2], vdrList[3], vdrList[4]
 
 	threshold := 3
 	duration := time.Minute
@@ -151,25 +140,14 @@ func TestBenchlistAdd(t *testing.T) {
 
 // Test that the benchlist won't bench more than the maximum portion of stake
 func TestBenchlistMaxStake(t *testing.T) {
-	vdrs := validators.NewSet()
-	vdr0 := validators.GenerateRandomValidator(1000)
-	vdr1 := validators.GenerateRandomValidator(1000)
-	vdr2 := validators.GenerateRandomValidator(1000)
-	vdr3 := validators.GenerateRandomValidator(2000)
-	vdr4 := validators.GenerateRandomValidator(100)
+	fixtures := validators.NewTestSetBuilder(0).Add(1000, 1000, 1000, 2000, 100)
 	// Total weight is 5100
-
-	errs := wrappers.Errs{}
-	errs.Add(
-		vdrs.AddWeight(vdr0.ID(), vdr0.Weight()),
-		vdrs.AddWeight(vdr1.ID(), vdr1.Weight()),
-		vdrs.AddWeight(vdr2.ID(), vdr2.Weight()),
-		vdrs.AddWeight(vdr3.ID(), vdr3.Weight()),
-		vdrs.AddWeight(vdr4.ID(), vdr4.Weight()),
-	)
-	if errs.Errored() {
-		t.Fatal(errs.Err)
+	vdrs, err := fixtures.Set(constants.PrimaryNetworkID)
+	if err != nil {
+		t.Fatal(err)
 	}
+	vdrList := fixtures.Validators(constants.PrimaryNetworkID)
+	vdr0, vdr1, vdr2, _, vdr4 := vdrList[0], vdrList[1], vdrList[2], vdrList[3], vdrList[4]
 
 	threshold := 3
 	duration := 1 * time.Hour
@@ -282,25 +260,14 @@ func TestBenchlistMaxStake(t *testing.T) {
 
 // Test validators are removed from the bench correctly
 func TestBenchlistRemove(t *testing.T) {
-	vdrs := validators.NewSet()
-	vdr0 := validators.GenerateRandomValidator(1000)
-	vdr1 := validators.GenerateRandomValidator(1000)
-	vdr2 := validators.GenerateRandomValidator(1000)
-	vdr3 := validators.GenerateRandomValidator(1000)
-	vdr4 := validators.GenerateRandomValidator(1000)
-	// Total weight is 5100
-
-	errs := wrappers.Errs{}
-	errs.Add(
-		vdrs.AddWeight(vdr0.ID(), vdr0.Weight()),
-		vdrs.AddWeight(vdr1.ID(), vdr1.Weight()),
-		vdrs.AddWeight(vdr2.ID(), vdr2.Weight()),
-		vdrs.AddWeight(vdr3.ID(), vdr3.Weight()),
-		vdrs.AddWeight(vdr4.ID(), vdr4.Weight()),
-	)
-	if errs.Errored() {
-		t.Fatal(errs.Err)
+	fixtures := validators.NewTestSetBuilder(0).Add(1000, 1000, 1000, 1000, 1000)
+	// Total weight is 5000
+	vdrs, err := fixtures.Set(constants.PrimaryNetworkID)
+	if err != nil {
+		t.Fatal(err)
 	}
+	vdrList := fixtures.Validators(constants.PrimaryNetworkID)
+	vdr0, vdr1, vdr2 := vdrList[0], vdrList[1], vdrList[2]
 
 	threshold := 3
 	duration := 2 * time.Second
@@ -393,3 +360,64 @@ func TestBenchlistRemove(t *testing.T) {
 	)
 
 }
+
+// Test that validators can be benched without failing queries
+func TestBenchlistBench(t *testing.T) {
+	fixtures := validators.NewTestSetBuilder(0).Add(1000, 1000, 1000, 1000, 1000)
+	vdrs, err := fixtures.Set(constants.PrimaryNetworkID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	vdrList := fixtures.Validators(constants.PrimaryNetworkID)
+	vdr0, vdr1, vdr2 := vdrList[0], vdrList[1], vdrList[2]
+
+	threshold := 3
+	duration := time.Minute
+	maxPortion := 0.5 // can bench 2 of the 5 validators
+	benchIntf, err := NewBenchlist(
+		logging.NoLog{},
+		vdrs,
+		threshold,
+		minimumFailingDuration,
+		duration,
+		maxPortion,
+		"",
+		prometheus.NewRegistry(),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	b := benchIntf.(*benchlist)
+	defer b.timer.Stop()
+	now := time.Now()
+	b.lock.Lock()
+	b.clock.Set(now)
+	b.lock.Unlock()
+
+	benchDuration := 10 * time.Minute
+	b.Bench(vdr0.ID(), benchDuration)
+	b.Bench(vdr1.ID(), benchDuration)
+	assert.True(t, b.IsBenched(vdr0.ID()))
+	assert.True(t, b.IsBenched(vdr1.ID()))
+
+	// Benching [vdr2] would exceed the max portion of benched stake
+	b.Bench(vdr2.ID(), benchDuration)
+	assert.False(t, b.IsBenched(vdr2.ID()))
+
+	// Benching an already benched validator doesn't change its bench time
+	b.lock.Lock()
+	firstBenched := b.benchedQueue[0].validatorID
+	benchedUntil := b.benchedQueue[0].benchedUntil
+	b.lock.Unlock()
+	b.Bench(firstBenched, 2*benchDuration)
+
+	b.lock.Lock()
+	defer b.lock.Unlock()
+	assert.Equal(t, 2, b.benchedQueue.Len())
+	assert.Equal(t, benchedUntil, b.benchedQueue[0].benchedUntil)
+	for _, benched := range b.benchedQueue {
+		// The requested duration is used rather than the benchlist's
+		assert.False(t, benched.benchedUntil.Before(now.Add(benchDuration/2)))
+		assert.False(t, benched.benchedUntil.After(now.Add(benchDuration)))
+	}
+}
@@ -25,6 +25,10 @@ type Manager interface {
 	// RegisterFailure registers that a request to [validatorID] regarding
 	// [chainID] timed out
 	RegisterFailure(chainID ids.ID, validatorID ids.ShortID)
+	// Bench benches [validatorID] regarding [chainID] for between
+	// [duration]/2 and [duration], such as when it misbehaves. Has no effect
+	// if the chain is unknown.
+	Bench(chainID ids.ID, validatorID ids.ShortID, duration time.Duration)
 	// RegisterChain registers a new chain with metrics under [namespace]
 	RegisterChain(ctx *snow.Context, namespace string) error
 	// IsBenched returns true if messages to [validatorID] regarding chain [chainID]
@@ -155,6 +159,18 @@ func (m *manager) RegisterFailure(chainID ids.ID, validatorID ids.ShortID) {
 	benchlist.RegisterFailure(validatorID)
 }
 
+// Bench implements the Manager interface
+func (m *manager) Bench(chainID ids.ID, validatorID ids.ShortID, duration time.Duration) {
+	m.lock.RLock()
+	benchlist, exists := m.chainBenchlists[chainID]
+	m.lock.RUnlock()
+
+	if !exists {
+		return
+	}
+	benchlist.Bench(validatorID, duration)
+}
+
 type noBenchlist struct{}
 
 // NewNoBenchlist returns an empty benchlist that will never stop any queries
@@ -163,5 +179,6 @@ func NewNoBenchlist() Manager { return &noBenchlist{} }
 func (noBenchlist) RegisterChain(*snow.Context, string) error { return nil }
 func (noBenchlist) RegisterResponse(ids.ID, ids.ShortID)      {}
 func (noBenchlist) RegisterFailure(ids.ID, ids.ShortID)       {}
+func (noBenchlist) Bench(ids.ID, ids.ShortID, time.Duration)  {}
 func (noBenchlist) IsBenched(ids.ShortID, ids.ID) bool        { return false }
 func (noBenchlist) GetBenched(ids.ShortID) []ids.ID           { return nil }
@@ -119,6 +119,56 @@ func (cr *ChainRouter) removeRequest(id ids.ID) {
 	cr.metrics.outstandingRequests.Set(float64(cr.timedRequests.Len()))
 }
 
+// fulfillRequest marks the outstanding request with ID [requestID], sent to
+// [validatorID] about chain [chainID], as fulfilled by a response of type
+// [op]. Returns false, and drops the response, if no such request is
+// outstanding or if the request wasn't of one of the [expected] types.
+//
+// This only drops unsolicited responses early, and counts them uniformly. The
+// engines still match each response against their own state, such as the
+// current round of bootstrapping requests or the vertex that a request asked
+// for, as the router doesn't track which requests are still relevant to them.
+// Assumes [cr.lock] is held.
+func (cr *ChainRouter) fulfillRequest(
+	validatorID ids.ShortID,
+	chainID ids.ID,
+	requestID uint32,
+	op constants.MsgType,
+	expected ...constants.MsgType,
+) bool {
+	uniqueRequestID := createRequestID(validatorID, chainID, requestID)
+
+	requestIntf, exists := cr.timedRequests.Get(uniqueRequestID)
+	if !exists {
+		// We didn't request this message
+		cr.log.Debug("%s(%s, %s, %d) dropped due to no outstanding request", op, validatorID, chainID, requestID)
+		cr.metrics.unrequestedResponses.WithLabelValues(op.String()).Inc()
+		return false
+	}
+	request := requestIntf.(requestEntry)
+	expectedType := false
+	for _, msgType := range expected {
+		if request.msgType == msgType {
+			expectedType = true
+			break
+		}
+	}
+	if !expectedType {
+		// We got back a reply of wrong type
+		cr.log.Debug("%s(%s, %s, %d) dropped due to being a reply to %s", op, validatorID, chainID, requestID, request.msgType)
+		cr.metrics.unrequestedResponses.WithLabelValues(op.String()).Inc()
+		return false
+	}
+	cr.removeRequest(uniqueRequestID)
+
+	// Calculate how long it took [validatorID] to reply
+	latency := cr.clock.Time().Sub(request.time)
+
+	// Tell the timeout manager we got a response
+	cr.timeoutManager.RegisterResponse(validatorID, chainID, uniqueRequestID, request.msgType, latency)
+	return true
+}
+
 // RegisterRequests marks that we should expect to receive a reply from the given validator
 // regarding the given chain and the reply should have the given requestID.
 // The type of message we sent the validator was [msgType].
@@ -275,26 +325,10 @@ func (cr *ChainRouter) AcceptedFrontier(validatorID ids.ShortID, chainID ids.ID,
 		return
 	}
 
-	uniqueRequestID := createRequestID(validatorID, chainID, requestID)
-
 	// Mark that an outstanding request has been fulfilled
-	requestIntf, exists := cr.timedRequests.Get(uniqueRequestID)
-	if !exists {
-		// We didn't request this message. Ignore.
+	if !cr.fulfillRequest(validatorID, chainID, requestID, constants.AcceptedFrontierMsg, constants.GetAcceptedFrontierMsg) {
 		return
 	}
-	request := requestIntf.(requestEntry)
-	if request.msgType != constants.GetAcceptedFrontierMsg {
-		// We got back a reply of wrong type. Ignore.
-		return
-	}
-	cr.timedRequests.Delete(uniqueRequestID)
-
-	// Calculate how long it took [validatorID] to reply
-	latency := cr.clock.Time().Sub(request.time)
-
-	// Tell the timeout manager we got a response
-	cr.timeoutManager.RegisterResponse(validatorID, chainID, uniqueRequestID, constants.GetAcceptedFrontierMsg, latency)
 
 	// Pass the response to the chain
 	dropped := !chain.AcceptedFrontier(validatorID, requestID, containerIDs)
@@ -366,26 +400,10 @@ func (cr *ChainRouter) Accepted(validatorID ids.ShortID, chainID ids.ID, request
 		return
 	}
 
-	uniqueRequestID := createRequestID(validatorID, chainID, requestID)
-
 	// Mark that an outstanding request has been fulfilled
-	requestIntf, exists := cr.timedRequests.Get(uniqueRequestID)
-	if !exists {
-		// We didn't request this message. Ignore.
+	if !cr.fulfillRequest(validatorID, chainID, requestID, constants.AcceptedMsg, constants.GetAcceptedMsg) {
 		return
 	}
-	request := requestIntf.(requestEntry)
-	if request.msgType != constants.GetAcceptedMsg {
-		// We got back a reply of wrong type. Ignore.
-		return
-	}
-	cr.timedRequests.Delete(uniqueRequestID)
-
-	// Calculate how long it took [validatorID] to reply
-	latency := cr.clock.Time().Sub(request.time)
-
-	// Tell the timeout manager we got a response
-	cr.timeoutManager.RegisterResponse(validatorID, chainID, uniqueRequestID, constants.GetAcceptedMsg, latency)
 
 	// Pass the response to the chain
 	dropped := !chain.Accepted(validatorID, requestID, containerIDs)
@@ -457,26 +475,10 @@ func (cr *ChainRouter) MultiPut(validatorID ids.ShortID, chainID ids.ID, request
 		return
 	}
 
-	uniqueRequestID := createRequestID(validatorID, chainID, requestID)
-
 	// Mark that an outstanding request has been fulfilled
-	requestIntf, exists := cr.timedRequests.Get(uniqueRequestID)
-	if !exists {
-		// We didn't request this message. Ignore.
+	if !cr.fulfillRequest(validatorID, chainID, requestID, constants.MultiPutMsg, constants.GetAncestorsMsg) {
 		return
 	}
-	request := requestIntf.(requestEntry)
-	if request.msgType != constants.GetAncestorsMsg {
-		// We got back a reply of wrong type. Ignore.
-		return
-	}
-	cr.timedRequests.Delete(uniqueRequestID)
-
-	// Calculate how long it took [validatorID] to reply
-	latency := cr.clock.Time().Sub(request.time)
-
-	// Tell the timeout manager we got a response
-	cr.timeoutManager.RegisterResponse(validatorID, chainID, uniqueRequestID, constants.GetAncestorsMsg, latency)
 
 	// Pass the response to the chain
 	dropped := !chain.MultiPut(validatorID, requestID, containers)
@@ -536,6 +538,18 @@ func (cr *ChainRouter) Get(validatorID ids.ShortID, chainID ids.ID, requestID ui
 // Put routes an incoming Put request from the validator with ID [validatorID]
 // to the consensus engine working on the chain with ID [chainID]
 func (cr *ChainRouter) Put(validatorID ids.ShortID, chainID ids.ID, requestID uint32, containerID ids.ID, container []byte) {
+	cr.put(validatorID, chainID, requestID, containerID, container, nil)
+}
+
+// HintedPut routes an incoming HintedPut request from the validator with ID
+// [validatorID] to the consensus engine working on the chain with ID [chainID]
+func (cr *ChainRouter) HintedPut(validatorID ids.ShortID, chainID ids.ID, requestID uint32, containerID ids.ID, container []byte, hints [][]byte) {
+	cr.put(validatorID, chainID, requestID, containerID, container, hints)
+}
+
+// put routes an incoming container. If [hints] is nil, the container wasn't
+// sent with any of its ancestors.
+func (cr *ChainRouter) put(validatorID ids.ShortID, chainID ids.ID, requestID uint32, containerID ids.ID, container []byte, hints [][]byte) {
 	cr.lock.Lock()
 	defer cr.lock.Unlock()
 
@@ -556,7 +570,7 @@ func (cr *ChainRouter) Put(validatorID ids.ShortID, chainID ids.ID, requestID ui
 	// If this is a gossip message, pass to the chain
 	if requestID == constants.GossipMsgRequestID {
 		// It's ok to drop this message.
-		dropped := !chain.Put(validatorID, requestID, containerID, container)
+		dropped := !chain.put(validatorID, requestID, containerID, container, hints)
 		if dropped {
 			cr.registerMsgDrop(chain.ctx.IsBootstrapped())
 		} else {
@@ -565,29 +579,13 @@ func (cr *ChainRouter) Put(validatorID ids.ShortID, chainID ids.ID, requestID ui
 		return
 	}
 
-	uniqueRequestID := createRequestID(validatorID, chainID, requestID)
-
 	// Mark that an outstanding request has been fulfilled
-	requestIntf, exists := cr.timedRequests.Get(uniqueRequestID)
-	if !exists {
-		// We didn't request this message. Ignore.
-		return
-	}
-	request := requestIntf.(requestEntry)
-	if request.msgType != constants.GetMsg {
-		// We got back a reply of wrong type. Ignore.
+	if !cr.fulfillRequest(validatorID, chainID, requestID, constants.PutMsg, constants.GetMsg) {
 		return
 	}
-	cr.timedRequests.Delete(uniqueRequestID)
-
-	// Calculate how long it took [validatorID] to reply
-	latency := cr.clock.Time().Sub(request.time)
-
-	// Tell the timeout manager we got a response
-	cr.timeoutManager.RegisterResponse(validatorID, chainID, uniqueRequestID, constants.GetMsg, latency)
 
 	// Pass the response to the chain
-	dropped := !chain.Put(validatorID, requestID, containerID, container)
+	dropped := !chain.put(validatorID, requestID, containerID, container, hints)
 	if dropped {
 		// We weren't able to pass the response to the chain
 		chain.GetFailed(validatorID, requestID)
@@ -665,6 +663,19 @@ func (cr *ChainRouter) PullQuery(validatorID ids.ShortID, chainID ids.ID, reques
 // Chits routes an incoming Chits message from the validator with ID [validatorID]
 // to the consensus engine working on the chain with ID [chainID]
 func (cr *ChainRouter) Chits(validatorID ids.ShortID, chainID ids.ID, requestID uint32, votes []ids.ID) {
+	cr.chits(validatorID, chainID, requestID, votes, nil)
+}
+
+// JustifiedChits routes an incoming JustifiedChits message from the validator
+// with ID [validatorID] to the consensus engine working on the chain with ID
+// [chainID]
+func (cr *ChainRouter) JustifiedChits(validatorID ids.ShortID, chainID ids.ID, requestID uint32, votes []ids.ID, heights []uint64) {
+	cr.chits(validatorID, chainID, requestID, votes, heights)
+}
+
+// chits routes an incoming set of votes. If [heights] is nil, the votes
+// weren't justified.
+func (cr *ChainRouter) chits(validatorID ids.ShortID, chainID ids.ID, requestID uint32, votes []ids.ID, heights []uint64) {
 	cr.lock.Lock()
 	defer cr.lock.Unlock()
 
@@ -675,29 +686,18 @@ func (cr *ChainRouter) Chits(validatorID ids.ShortID, chainID ids.ID, requestID
 		return
 	}
 
-	uniqueRequestID := createRequestID(validatorID, chainID, requestID)
-
 	// Mark that an outstanding request has been fulfilled
-	requestIntf, exists := cr.timedRequests.Get(uniqueRequestID)
-	if !exists {
-		// We didn't request this message. Ignore.
-		return
-	}
-	request := requestIntf.(requestEntry)
-	if request.msgType != constants.PullQueryMsg && request.msgType != constants.PushQueryMsg {
-		// We got back a reply of wrong type. Ignore.
+	if !cr.fulfillRequest(validatorID, chainID, requestID, constants.ChitsMsg, constants.PullQueryMsg, constants.PushQueryMsg) {
 		return
 	}
-	cr.timedRequests.Delete(uniqueRequestID)
-
-	// Calculate how long it took [validatorID] to reply
-	latency := cr.clock.Time().Sub(request.time)
-
-	// Tell the timeout manager we got a response
-	cr.timeoutManager.RegisterResponse(validatorID, chainID, uniqueRequestID, request.msgType, latency)
 
 	// Pass the response to the chain
-	dropped := !chain.Chits(validatorID, requestID, votes)
+	var dropped bool
+	if heights == nil {
+		dropped = !chain.Chits(validatorID, requestID, votes)
+	} else {
+		dropped = !chain.JustifiedChits(validatorID, requestID, votes, heights)
+	}
 	if dropped {
 		// We weren't able to pass the response to the chain
 		chain.QueryFailed(validatorID, requestID)
@@ -749,6 +749,90 @@ func (cr *ChainRouter) Disconnected(validatorID ids.ShortID) {
 	}
 }
 
+// CrossChainRequest routes a request from the chain [requestingChainID] on this
+// node to the consensus engine working on the chain with ID [chainID]. The
+// request is dropped unless [requestingChainID] may make requests to
+// [chainID].
+func (cr *ChainRouter) CrossChainRequest(requestingChainID ids.ID, chainID ids.ID, requestID uint32, request []byte) {
+	cr.lock.Lock()
+	defer cr.lock.Unlock()
+
+	requester, responder, ok := cr.crossChainHandlers(requestingChainID, chainID)
+	if !ok {
+		cr.log.Debug("CrossChainRequest(%s, %s, %d) dropped due to unknown chain", requestingChainID, chainID, requestID)
+		return
+	}
+	if !crossChainAllowed(requester, responder) {
+		cr.log.Debug("CrossChainRequest(%s, %s, %d) dropped because %s may not make requests to %s",
+			requestingChainID, chainID, requestID, requestingChainID, chainID)
+		return
+	}
+	responder.CrossChainRequest(requestingChainID, requestID, request)
+}
+
+// CrossChainResponse routes a response from the chain [respondingChainID] on
+// this node to the consensus engine working on the chain with ID [chainID].
+// The response is dropped unless [chainID] may make requests to
+// [respondingChainID].
+func (cr *ChainRouter) CrossChainResponse(respondingChainID ids.ID, chainID ids.ID, requestID uint32, response []byte) {
+	cr.lock.Lock()
+	defer cr.lock.Unlock()
+
+	responder, requester, ok := cr.crossChainHandlers(respondingChainID, chainID)
+	if !ok {
+		cr.log.Debug("CrossChainResponse(%s, %s, %d) dropped due to unknown chain", respondingChainID, chainID, requestID)
+		return
+	}
+	if !crossChainAllowed(requester, responder) {
+		cr.log.Debug("CrossChainResponse(%s, %s, %d) dropped because %s may not make requests to %s",
+			respondingChainID, chainID, requestID, chainID, respondingChainID)
+		return
+	}
+	requester.CrossChainResponse(respondingChainID, requestID, response)
+}
+
+// crossChainHandlers returns the handlers of the chains [sourceChainID] and
+// [destinationChainID]. Returns false if either chain isn't registered.
+// Assumes [cr.lock] is held.
+func (cr *ChainRouter) crossChainHandlers(sourceChainID, destinationChainID ids.ID) (*Handler, *Handler, bool) {
+	source, exists := cr.chains[sourceChainID]
+	if !exists {
+		return nil, nil, false
+	}
+	destination, exists := cr.chains[destinationChainID]
+	return source, destination, exists
+}
+
+// crossChainAllowed returns true if the chain of [requester] may make requests
+// to the chain of [responder]. A chain may make requests to the chains of its
+// own subnet and to the chains of the primary network.
+func crossChainAllowed(requester, responder *Handler) bool {
+	responderSubnetID := responder.ctx.SubnetID
+	return responderSubnetID == constants.PrimaryNetworkID || responderSubnetID == requester.ctx.SubnetID
+}
+
+// GossipTxs routes an incoming GossipTxs message from the validator with ID
+// [validatorID] to the consensus engine working on the chain with ID [chainID]
+func (cr *ChainRouter) GossipTxs(validatorID ids.ShortID, chainID ids.ID, containerID ids.ID, txIDs []ids.ID) {
+	cr.lock.Lock()
+	defer cr.lock.Unlock()
+
+	// Get the chain, if it exists
+	chain, exists := cr.chains[chainID]
+	if !exists {
+		cr.log.Verbo("GossipTxs(%s, %s, %s) dropped due to unknown chain", validatorID, chainID, containerID)
+		return
+	}
+
+	// It's ok to drop this message.
+	dropped := !chain.GossipTxs(validatorID, containerID, txIDs)
+	if dropped {
+		cr.registerMsgDrop(chain.ctx.IsBootstrapped())
+	} else {
+		cr.registerMsgSuccess(chain.ctx.IsBootstrapped())
+	}
+}
+
 // Gossip accepted containers
 func (cr *ChainRouter) Gossip() {
 	cr.lock.Lock()
@@ -13,6 +13,7 @@ type routerMetrics struct {
 	outstandingRequests   prometheus.Gauge
 	msgDropRate           prometheus.Gauge
 	longestRunningRequest prometheus.Gauge
+	unrequestedResponses  *prometheus.CounterVec
 }
 
 func newRouterMetrics(namespace string, registerer prometheus.Registerer) (*routerMetrics, error) {
@@ -38,12 +39,21 @@ func newRouterMetrics(namespace string, registerer prometheus.Registerer) (*rout
 			Help:      "Time the longest request took in milliseconds",
 		},
 	)
+	rMetrics.unrequestedResponses = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "unrequested_responses",
+			Help:      "Number of responses dropped because they didn't match an outstanding request",
+		},
+		[]string{"op"},
+	)
 
 	errs := wrappers.Errs{}
 	errs.Add(
 		registerer.Register(rMetrics.outstandingRequests),
 		registerer.Register(rMetrics.msgDropRate),
 		registerer.Register(rMetrics.longestRunningRequest),
+		registerer.Register(rMetrics.unrequestedResponses),
 	)
 	return rMetrics, errs.Err
 }
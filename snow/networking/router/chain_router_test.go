@@ -9,6 +9,7 @@ import (
 	"time"
 
 	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
 	"github.com/stretchr/testify/assert"
 
 	"github.com/ava-labs/avalanchego/ids"
@@ -61,6 +62,7 @@ func TestShutdown(t *testing.T) {
 		DefaultMaxNonStakerPendingMsgs,
 		DefaultStakerPortion,
 		DefaultStakerPortion,
+		nil,
 		"",
 		prometheus.NewRegistry(),
 		&Delay{},
@@ -136,6 +138,7 @@ func TestShutdownTimesOut(t *testing.T) {
 		DefaultMaxNonStakerPendingMsgs,
 		DefaultStakerPortion,
 		DefaultStakerPortion,
+		nil,
 		"",
 		prometheus.NewRegistry(),
 		&Delay{},
@@ -236,6 +239,7 @@ func TestRouterTimeout(t *testing.T) {
 		DefaultMaxNonStakerPendingMsgs,
 		DefaultStakerPortion,
 		DefaultStakerPortion,
+		nil,
 		"",
 		prometheus.NewRegistry(),
 		&Delay{},
@@ -304,6 +308,7 @@ func TestRouterClearTimeouts(t *testing.T) {
 		DefaultMaxNonStakerPendingMsgs,
 		DefaultStakerPortion,
 		DefaultStakerPortion,
+		nil,
 		"",
 		prometheus.NewRegistry(),
 		&Delay{},
@@ -339,3 +344,205 @@ func TestRouterClearTimeouts(t *testing.T) {
 
 	assert.Equal(t, chainRouter.timedRequests.Len(), 0)
 }
+
+func TestRouterDropsUnrequestedResponses(t *testing.T) {
+	// Create a timeout manager
+	tm := timeout.Manager{}
+	err := tm.Initialize(&timer.AdaptiveTimeoutConfig{
+		InitialTimeout:     3 * time.Second,
+		MinimumTimeout:     3 * time.Second,
+		MaximumTimeout:     5 * time.Minute,
+		TimeoutCoefficient: 1,
+		TimeoutHalflife:    5 * time.Minute,
+		MetricsNamespace:   "",
+		Registerer:         prometheus.NewRegistry(),
+	}, benchlist.NewNoBenchlist())
+	if err != nil {
+		t.Fatal(err)
+	}
+	go tm.Dispatch()
+
+	// Create a router
+	chainRouter := ChainRouter{}
+	err = chainRouter.Initialize(ids.ShortEmpty, logging.NoLog{}, &tm, time.Hour, time.Millisecond, ids.Set{}, nil, HealthConfig{}, "", prometheus.NewRegistry())
+	assert.NoError(t, err)
+
+	// Create an engine and handler
+	engine := common.EngineTest{T: t}
+	engine.Default(false)
+
+	engine.ContextF = snow.DefaultContextTest
+
+	handler := &Handler{}
+	err = handler.Initialize(
+		&engine,
+		validators.NewSet(),
+		nil,
+		DefaultMaxNonStakerPendingMsgs,
+		DefaultMaxNonStakerPendingMsgs,
+		DefaultStakerPortion,
+		DefaultStakerPortion,
+		nil,
+		"",
+		prometheus.NewRegistry(),
+		&Delay{},
+	)
+	assert.NoError(t, err)
+
+	puts := make(chan ids.ShortID, 4)
+	engine.PutF = func(validatorID ids.ShortID, _ uint32, _ ids.ID, _ []byte) error {
+		puts <- validatorID
+		return nil
+	}
+
+	chainRouter.AddChain(handler)
+	go handler.Dispatch()
+
+	vID := ids.GenerateTestShortID()
+	chainRouter.RegisterRequest(vID, handler.ctx.ChainID, 0, constants.GetMsg)
+
+	// A response from a validator that wasn't asked
+	chainRouter.Put(ids.GenerateTestShortID(), handler.ctx.ChainID, 0, ids.GenerateTestID(), nil)
+	// A response with an unknown request ID
+	chainRouter.Put(vID, handler.ctx.ChainID, 1, ids.GenerateTestID(), nil)
+	// A response of the wrong type
+	chainRouter.Chits(vID, handler.ctx.ChainID, 0, nil)
+
+	assert.Equal(t, 1, chainRouter.timedRequests.Len(), "unrequested responses shouldn't fulfill the request")
+	assert.Equal(t, 2.0, testutil.ToFloat64(chainRouter.metrics.unrequestedResponses.WithLabelValues(constants.PutMsg.String())))
+	assert.Equal(t, 1.0, testutil.ToFloat64(chainRouter.metrics.unrequestedResponses.WithLabelValues(constants.ChitsMsg.String())))
+
+	chainRouter.Put(vID, handler.ctx.ChainID, 0, ids.GenerateTestID(), nil)
+	assert.Equal(t, 0, chainRouter.timedRequests.Len())
+	assert.Equal(t, 0.0, testutil.ToFloat64(chainRouter.metrics.outstandingRequests))
+
+	// Only the requested response should be passed to the chain
+	assert.Equal(t, vID, <-puts)
+	assert.Len(t, puts, 0)
+}
+
+// crossChainEngine is an engine that records the cross-chain messages passed
+// to it
+type crossChainEngine struct {
+	*common.EngineTest
+
+	requests, responses []ids.ID
+}
+
+func (e *crossChainEngine) CrossChainRequest(requestingChainID ids.ID, _ uint32, _ []byte) error {
+	e.requests = append(e.requests, requestingChainID)
+	return nil
+}
+
+func (e *crossChainEngine) CrossChainResponse(respondingChainID ids.ID, _ uint32, _ []byte) error {
+	e.responses = append(e.responses, respondingChainID)
+	return nil
+}
+
+func TestRouterCrossChainPermissions(t *testing.T) {
+	tm := timeout.Manager{}
+	err := tm.Initialize(&timer.AdaptiveTimeoutConfig{
+		InitialTimeout:     time.Second,
+		MinimumTimeout:     time.Second,
+		MaximumTimeout:     time.Minute,
+		TimeoutCoefficient: 1,
+		TimeoutHalflife:    5 * time.Minute,
+		MetricsNamespace:   "",
+		Registerer:         prometheus.NewRegistry(),
+	}, benchlist.NewNoBenchlist())
+	assert.NoError(t, err)
+
+	chainRouter := ChainRouter{}
+	err = chainRouter.Initialize(ids.ShortEmpty, logging.NoLog{}, &tm, time.Hour, time.Millisecond, ids.Set{}, nil, HealthConfig{}, "", prometheus.NewRegistry())
+	assert.NoError(t, err)
+
+	newChain := func(subnetID ids.ID) (*Handler, *crossChainEngine) {
+		ctx := snow.DefaultContextTest()
+		ctx.ChainID = ids.GenerateTestID()
+		ctx.SubnetID = subnetID
+
+		engine := &crossChainEngine{EngineTest: &common.EngineTest{T: t}}
+		engine.Default(false)
+		engine.ContextF = func() *snow.Context { return ctx }
+
+		handler := &Handler{}
+		err := handler.Initialize(
+			engine,
+			validators.NewSet(),
+			nil,
+			DefaultMaxNonStakerPendingMsgs,
+			DefaultMaxNonStakerPendingMsgs,
+			DefaultStakerPortion,
+			DefaultStakerPortion,
+			nil,
+			"",
+			prometheus.NewRegistry(),
+			&Delay{},
+		)
+		assert.NoError(t, err)
+		chainRouter.AddChain(handler)
+		return handler, engine
+	}
+
+	subnetA, subnetB := ids.GenerateTestID(), ids.GenerateTestID()
+	primary, primaryEngine := newChain(constants.PrimaryNetworkID)
+	a0, a0Engine := newChain(subnetA)
+	a1, a1Engine := newChain(subnetA)
+	b, bEngine := newChain(subnetB)
+	primaryID, a0ID, a1ID, bID := primary.ctx.ChainID, a0.ctx.ChainID, a1.ctx.ChainID, b.ctx.ChainID
+
+	// Chains may make requests to the primary network and to their own subnet
+	chainRouter.CrossChainRequest(a0ID, primaryID, 0, nil)
+	chainRouter.CrossChainRequest(a0ID, a1ID, 0, nil)
+	chainRouter.CrossChainRequest(bID, primaryID, 0, nil)
+	// but not to other subnets
+	chainRouter.CrossChainRequest(primaryID, a0ID, 0, nil)
+	chainRouter.CrossChainRequest(bID, a0ID, 0, nil)
+	// or from unknown chains
+	chainRouter.CrossChainRequest(ids.GenerateTestID(), primaryID, 0, nil)
+
+	// Responses are allowed if the request would have been
+	chainRouter.CrossChainResponse(primaryID, a0ID, 0, nil)
+	chainRouter.CrossChainResponse(a0ID, primaryID, 0, nil)
+	chainRouter.CrossChainResponse(a1ID, bID, 0, nil)
+
+	for _, handler := range []*Handler{primary, a0, a1, b} {
+		for _, msg := range handler.reliableMsgs {
+			handler.dispatchMsg(msg)
+		}
+	}
+	assert.Equal(t, []ids.ID{a0ID, bID}, primaryEngine.requests)
+	assert.Empty(t, primaryEngine.responses)
+	assert.Empty(t, a0Engine.requests)
+	assert.Equal(t, []ids.ID{primaryID}, a0Engine.responses)
+	assert.Equal(t, []ids.ID{a0ID}, a1Engine.requests)
+	assert.Empty(t, bEngine.requests)
+	assert.Empty(t, bEngine.responses)
+}
+
+func TestHandlerDropsCrossChainMsgsWithoutHandler(t *testing.T) {
+	engine := common.EngineTest{T: t}
+	engine.Default(true)
+	engine.ContextF = snow.DefaultContextTest
+
+	handler := &Handler{}
+	err := handler.Initialize(
+		&engine,
+		validators.NewSet(),
+		nil,
+		DefaultMaxNonStakerPendingMsgs,
+		DefaultMaxNonStakerPendingMsgs,
+		DefaultStakerPortion,
+		DefaultStakerPortion,
+		nil,
+		"",
+		prometheus.NewRegistry(),
+		&Delay{},
+	)
+	assert.NoError(t, err)
+
+	handler.CrossChainRequest(ids.GenerateTestID(), 0, nil)
+	handler.dispatchMsg(handler.reliableMsgs[0])
+	assert.Equal(t, 1.0, testutil.ToFloat64(handler.metrics.dropped))
+	assert.False(t, handler.closing.GetValue())
+}
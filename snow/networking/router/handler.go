@@ -103,7 +103,8 @@ type Handler struct {
 	closed           chan struct{}
 	msgChan          <-chan common.Message
 
-	cpuTracker tracker.TimeTracker
+	cpuTracker  tracker.TimeTracker
+	costTracker tracker.CostTracker
 
 	clock timer.Clock
 
@@ -116,11 +117,19 @@ type Handler struct {
 	toClose func()
 	closing utils.AtomicBool
 
+	// restart, if non-nil, returns a new engine to replace one that hit a
+	// fatal error. The engine is replaced at most [restartBudget] times.
+	restart       func() (common.Engine, error)
+	restartBudget int
+	restarts      int
+
 	delay *Delay
 }
 
 // Initialize this consensus handler
 // engine must be initialized before initializing the handler
+// [costTracker] is shared between the chains so that a peer's parse budget
+// covers all of them. If it's nil, peers aren't charged for their messages.
 func (h *Handler) Initialize(
 	engine common.Engine,
 	validators validators.Set,
@@ -129,6 +138,7 @@ func (h *Handler) Initialize(
 	maxNonStakerPendingMsgs uint32,
 	stakerMsgPortion,
 	stakerCPUPortion float64,
+	costTracker tracker.CostTracker,
 	namespace string,
 	metrics prometheus.Registerer,
 	delay *Delay,
@@ -161,12 +171,17 @@ func (h *Handler) Initialize(
 	}
 
 	h.cpuTracker = tracker.NewCPUTracker(uptime.IntervalFactory{}, cpuInterval)
+	if costTracker == nil {
+		costTracker = tracker.NewCostTracker(0, 0)
+	}
+	h.costTracker = costTracker
 	msgTracker := tracker.NewMessageTracker()
 	msgManager, err := NewMsgManager(
 		validators,
 		h.ctx.Log,
 		msgTracker,
 		h.cpuTracker,
+		h.costTracker,
 		maxPendingMsgs,
 		maxNonStakerPendingMsgs,
 		stakerMsgPortion,
@@ -193,14 +208,24 @@ func (h *Handler) Initialize(
 }
 
 // Context of this Handler
-func (h *Handler) Context() *snow.Context { return h.engine.Context() }
+func (h *Handler) Context() *snow.Context { return h.ctx }
 
-// Engine returns the engine this handler dispatches to
+// Engine returns the engine this handler dispatches to. The context lock must
+// be held while calling this method.
 func (h *Handler) Engine() common.Engine { return h.engine }
 
-// SetEngine sets the engine for this handler to dispatch to
+// SetEngine sets the engine for this handler to dispatch to. The context lock
+// must be held while calling this method.
 func (h *Handler) SetEngine(engine common.Engine) { h.engine = engine }
 
+// SetRestarter allows the engine to be replaced by one returned by [restart]
+// when it hits a fatal error, rather than shutting down the chain, up to
+// [budget] times. The context lock must be held while calling this method.
+func (h *Handler) SetRestarter(restart func() (common.Engine, error), budget int) {
+	h.restart = restart
+	h.restartBudget = budget
+}
+
 // Dispatch waits for incoming messages from the network
 // and, when they arrive, sends them to the consensus engine
 func (h *Handler) Dispatch() {
@@ -292,6 +317,9 @@ func (h *Handler) dispatchMsg(msg message) {
 	case constants.GossipMsg:
 		err = h.engine.Gossip()
 		h.metrics.gossip.Observe(float64(h.clock.Time().Sub(startTime)))
+	case constants.CrossChainRequestMsg, constants.CrossChainResponseMsg:
+		err = h.handleCrossChainMsg(msg)
+		h.metrics.getMSGHistogram(msg.messageType).Observe(float64(h.clock.Time().Sub(startTime)))
 	default:
 		err = h.handleValidatorMsg(msg, startTime)
 	}
@@ -302,12 +330,34 @@ func (h *Handler) dispatchMsg(msg message) {
 		h.ctx.Log.Debug("Finished sending message to consensus: %s", msg.messageType)
 	}
 
-	if err != nil {
+	if err != nil && !h.restartEngine(err) {
 		h.ctx.Log.Fatal("forcing chain to shutdown due to: %s", err)
 		h.closing.SetValue(true)
 	}
 }
 
+// restartEngine replaces the engine after it hit the fatal error [cause].
+// Returns false if the engine wasn't replaced. The failed engine isn't shut
+// down, as that would shut down the VM, which the new engine keeps using.
+// assumes the context lock is held.
+func (h *Handler) restartEngine(cause error) bool {
+	if h.restart == nil || h.restarts >= h.restartBudget {
+		return false
+	}
+	h.restarts++
+	h.ctx.Log.Error("restarting chain (%d of %d restarts) due to: %s",
+		h.restarts, h.restartBudget, cause)
+
+	engine, err := h.restart()
+	if err != nil {
+		h.ctx.Log.Error("couldn't restart chain: %s", err)
+		return false
+	}
+	h.engine = engine
+	h.metrics.restarts.Inc()
+	return true
+}
+
 // GetAcceptedFrontier passes a GetAcceptedFrontier message received from the
 // network to the consensus engine.
 func (h *Handler) GetAcceptedFrontier(validatorID ids.ShortID, requestID uint32, deadline time.Time) bool {
@@ -423,12 +473,25 @@ func (h *Handler) Get(validatorID ids.ShortID, requestID uint32, deadline time.T
 
 // Put passes a Put message received from the network to the consensus engine.
 func (h *Handler) Put(validatorID ids.ShortID, requestID uint32, containerID ids.ID, container []byte) bool {
+	return h.put(validatorID, requestID, containerID, container, nil)
+}
+
+// HintedPut passes a HintedPut message received from the network to the
+// consensus engine.
+func (h *Handler) HintedPut(validatorID ids.ShortID, requestID uint32, containerID ids.ID, container []byte, hints [][]byte) bool {
+	return h.put(validatorID, requestID, containerID, container, hints)
+}
+
+// put passes a container to the consensus engine. If [hints] is nil, the
+// container wasn't sent with any of its ancestors.
+func (h *Handler) put(validatorID ids.ShortID, requestID uint32, containerID ids.ID, container []byte, hints [][]byte) bool {
 	return h.serviceQueue.PushMessage(message{
 		messageType: constants.PutMsg,
 		validatorID: validatorID,
 		requestID:   requestID,
 		containerID: containerID,
 		container:   container,
+		containers:  hints,
 		received:    h.clock.Time(),
 	})
 }
@@ -478,6 +541,32 @@ func (h *Handler) Chits(validatorID ids.ShortID, requestID uint32, votes []ids.I
 	})
 }
 
+// JustifiedChits passes a JustifiedChits message received from the network to
+// the consensus engine.
+func (h *Handler) JustifiedChits(validatorID ids.ShortID, requestID uint32, votes []ids.ID, heights []uint64) bool {
+	return h.serviceQueue.PushMessage(message{
+		messageType:  constants.ChitsMsg,
+		validatorID:  validatorID,
+		requestID:    requestID,
+		containerIDs: votes,
+		heights:      heights,
+		received:     h.clock.Time(),
+	})
+}
+
+// GossipTxs passes a GossipTxs message received from the network to the
+// consensus engine.
+func (h *Handler) GossipTxs(validatorID ids.ShortID, containerID ids.ID, txIDs []ids.ID) bool {
+	return h.serviceQueue.PushMessage(message{
+		messageType:  constants.GossipTxsMsg,
+		validatorID:  validatorID,
+		requestID:    constants.GossipMsgRequestID,
+		containerID:  containerID,
+		containerIDs: txIDs,
+		received:     h.clock.Time(),
+	})
+}
+
 // QueryFailed passes a QueryFailed message received from the network to the consensus engine.
 func (h *Handler) QueryFailed(validatorID ids.ShortID, requestID uint32) {
 	h.sendReliableMsg(message{
@@ -503,6 +592,28 @@ func (h *Handler) Disconnected(validatorID ids.ShortID) {
 	})
 }
 
+// CrossChainRequest passes a request from the chain [requestingChainID] on
+// this node to the consensus engine
+func (h *Handler) CrossChainRequest(requestingChainID ids.ID, requestID uint32, request []byte) {
+	h.sendReliableMsg(message{
+		messageType:   constants.CrossChainRequestMsg,
+		requestID:     requestID,
+		container:     request,
+		sourceChainID: requestingChainID,
+	})
+}
+
+// CrossChainResponse passes a response from the chain [respondingChainID] on
+// this node to the consensus engine
+func (h *Handler) CrossChainResponse(respondingChainID ids.ID, requestID uint32, response []byte) {
+	h.sendReliableMsg(message{
+		messageType:   constants.CrossChainResponseMsg,
+		requestID:     requestID,
+		container:     response,
+		sourceChainID: respondingChainID,
+	})
+}
+
 // Gossip passes a gossip request to the consensus engine
 func (h *Handler) Gossip() {
 	if !h.ctx.IsBootstrapped() {
@@ -574,7 +685,11 @@ func (h *Handler) handleValidatorMsg(msg message, startTime time.Time) error {
 	case constants.GetFailedMsg:
 		err = h.engine.GetFailed(msg.validatorID, msg.requestID)
 	case constants.PutMsg:
-		err = h.engine.Put(msg.validatorID, msg.requestID, msg.containerID, msg.container)
+		if msg.containers == nil {
+			err = h.engine.Put(msg.validatorID, msg.requestID, msg.containerID, msg.container)
+		} else {
+			err = h.engine.HintedPut(msg.validatorID, msg.requestID, msg.containerID, msg.container, msg.containers)
+		}
 	case constants.PushQueryMsg:
 		err = h.engine.PushQuery(msg.validatorID, msg.requestID, msg.containerID, msg.container)
 	case constants.PullQueryMsg:
@@ -582,11 +697,17 @@ func (h *Handler) handleValidatorMsg(msg message, startTime time.Time) error {
 	case constants.QueryFailedMsg:
 		err = h.engine.QueryFailed(msg.validatorID, msg.requestID)
 	case constants.ChitsMsg:
-		err = h.engine.Chits(msg.validatorID, msg.requestID, msg.containerIDs)
+		if msg.heights == nil {
+			err = h.engine.Chits(msg.validatorID, msg.requestID, msg.containerIDs)
+		} else {
+			err = h.engine.JustifiedChits(msg.validatorID, msg.requestID, msg.containerIDs, msg.heights)
+		}
 	case constants.ConnectedMsg:
 		err = h.engine.Connected(msg.validatorID)
 	case constants.DisconnectedMsg:
 		err = h.engine.Disconnected(msg.validatorID)
+	case constants.GossipTxsMsg:
+		err = h.engine.GossipTxs(msg.validatorID, msg.containerID, msg.containerIDs)
 	}
 	endTime := h.clock.Time()
 	timeConsumed := endTime.Sub(startTime)
@@ -595,10 +716,27 @@ func (h *Handler) handleValidatorMsg(msg message, startTime time.Time) error {
 	histogram.Observe(float64(timeConsumed))
 
 	h.cpuTracker.UtilizeTime(msg.validatorID, startTime, endTime)
+	h.costTracker.Charge(msg.validatorID, msg.ParseCost(), endTime)
 	h.serviceQueue.UtilizeCPU(msg.validatorID, timeConsumed)
 	return err
 }
 
+// handleCrossChainMsg passes a message from another chain on this node to the
+// consensus engine, if the engine handles cross-chain messages.
+// assumes the context lock is held.
+func (h *Handler) handleCrossChainMsg(msg message) error {
+	engine, ok := h.engine.(common.CrossChainHandler)
+	if !ok {
+		h.ctx.Log.Debug("dropping %s because the engine doesn't handle cross-chain messages", msg)
+		h.metrics.dropped.Inc()
+		return nil
+	}
+	if msg.messageType == constants.CrossChainRequestMsg {
+		return engine.CrossChainRequest(msg.sourceChainID, msg.requestID, msg.container)
+	}
+	return engine.CrossChainResponse(msg.sourceChainID, msg.requestID, msg.container)
+}
+
 func (h *Handler) sendReliableMsg(msg message) {
 	h.reliableMsgsLock.Lock()
 	defer h.reliableMsgsLock.Unlock()
@@ -37,6 +37,7 @@ type handlerMetrics struct {
 	registerer       prometheus.Registerer
 	pending          prometheus.Gauge
 	dropped, expired prometheus.Counter
+	restarts         prometheus.Counter
 	getAcceptedFrontier, acceptedFrontier, getAcceptedFrontierFailed,
 	getAccepted, accepted, getAcceptedFailed,
 	getAncestors, multiPut, getAncestorsFailed,
@@ -44,7 +45,8 @@ type handlerMetrics struct {
 	pushQuery, pullQuery, chits, queryFailed,
 	connected, disconnected,
 	notify,
-	gossip,
+	gossip, gossipTxs,
+	crossChainRequest, crossChainResponse,
 	cpu,
 	shutdown prometheus.Histogram
 }
@@ -83,6 +85,15 @@ func (m *handlerMetrics) Initialize(namespace string, registerer prometheus.Regi
 		errs.Add(fmt.Errorf("failed to register expired statistics due to %w", err))
 	}
 
+	m.restarts = prometheus.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "restarts",
+		Help:      "Number of times the engine was restarted after a fatal error",
+	})
+	if err := registerer.Register(m.restarts); err != nil {
+		errs.Add(fmt.Errorf("failed to register restarts statistics due to %w", err))
+	}
+
 	m.getAcceptedFrontier = initHistogram(namespace, "get_accepted_frontier", registerer, &errs)
 	m.acceptedFrontier = initHistogram(namespace, "accepted_frontier", registerer, &errs)
 	m.getAcceptedFrontierFailed = initHistogram(namespace, "get_accepted_frontier_failed", registerer, &errs)
@@ -103,6 +114,9 @@ func (m *handlerMetrics) Initialize(namespace string, registerer prometheus.Regi
 	m.disconnected = initHistogram(namespace, "disconnected", registerer, &errs)
 	m.notify = initHistogram(namespace, "notify", registerer, &errs)
 	m.gossip = initHistogram(namespace, "gossip", registerer, &errs)
+	m.gossipTxs = initHistogram(namespace, "gossip_txs", registerer, &errs)
+	m.crossChainRequest = initHistogram(namespace, "cross_chain_request", registerer, &errs)
+	m.crossChainResponse = initHistogram(namespace, "cross_chain_response", registerer, &errs)
 
 	m.cpu = prometheus.NewHistogram(prometheus.HistogramOpts{
 		Namespace: namespace,
@@ -188,6 +202,12 @@ func (m *handlerMetrics) getMSGHistogram(msg constants.MsgType) prometheus.Histo
 		return m.connected
 	case constants.DisconnectedMsg:
 		return m.disconnected
+	case constants.GossipTxsMsg:
+		return m.gossipTxs
+	case constants.CrossChainRequestMsg:
+		return m.crossChainRequest
+	case constants.CrossChainResponseMsg:
+		return m.crossChainResponse
 	default:
 		panic(fmt.Sprintf("unknown message type %s", msg))
 	}
@@ -47,6 +47,7 @@ func TestHandlerDropsTimedOutMessages(t *testing.T) {
 		DefaultMaxNonStakerPendingMsgs,
 		DefaultStakerPortion,
 		DefaultStakerPortion,
+		nil,
 		"",
 		prometheus.NewRegistry(),
 		&Delay{},
@@ -92,6 +93,7 @@ func TestHandlerDoesntDrop(t *testing.T) {
 		DefaultMaxNonStakerPendingMsgs,
 		DefaultStakerPortion,
 		DefaultStakerPortion,
+		nil,
 		"",
 		prometheus.NewRegistry(),
 		&Delay{},
@@ -130,6 +132,7 @@ func TestHandlerClosesOnError(t *testing.T) {
 		DefaultMaxNonStakerPendingMsgs,
 		DefaultStakerPortion,
 		DefaultStakerPortion,
+		nil,
 		"",
 		prometheus.NewRegistry(),
 		&Delay{},
@@ -176,6 +179,7 @@ func TestHandlerDropsGossipDuringBootstrapping(t *testing.T) {
 		DefaultMaxNonStakerPendingMsgs,
 		DefaultStakerPortion,
 		DefaultStakerPortion,
+		nil,
 		"",
 		prometheus.NewRegistry(),
 		&Delay{},
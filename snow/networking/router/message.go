@@ -21,9 +21,13 @@ type message struct {
 	container    []byte
 	containers   [][]byte
 	containerIDs []ids.ID
+	heights      []uint64 // Heights justifying the votes in a Chits message
 	notification common.Message
 	received     time.Time // Time this message was received
 	deadline     time.Time // Time this message must be responded to
+
+	// Chain on this node that sent a cross-chain message
+	sourceChainID ids.ID
 }
 
 // IsPeriodic returns true if this message is of a type that is sent on a
@@ -33,6 +37,17 @@ func (m message) IsPeriodic() bool {
 		m.messageType == constants.GossipMsg
 }
 
+// ParseCost returns the cost charged to the sender of this message for the
+// containers in it. Parsing a container, and verifying the signatures in it,
+// takes time proportional to its size.
+func (m message) ParseCost() uint64 {
+	cost := uint64(len(m.container))
+	for _, container := range m.containers {
+		cost += uint64(len(container))
+	}
+	return cost
+}
+
 func (m message) String() string {
 	sb := strings.Builder{}
 	sb.WriteString(fmt.Sprintf("(%s, ValidatorID: %s, RequestID: %d", m.messageType, m.validatorID, m.requestID))
@@ -51,6 +66,8 @@ func (m message) String() string {
 		sb.WriteString(fmt.Sprintf(", NumContainers: %d)", len(m.containers)))
 	case constants.NotifyMsg:
 		sb.WriteString(fmt.Sprintf(", Notification: %s)", m.notification))
+	case constants.CrossChainRequestMsg, constants.CrossChainResponseMsg:
+		sb.WriteString(fmt.Sprintf(", SourceChainID: %s, Size: %d)", m.sourceChainID, len(m.container)))
 	default:
 		sb.WriteString(")")
 	}
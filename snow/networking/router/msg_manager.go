@@ -21,6 +21,12 @@ const (
 	DefaultMaxNonStakerPendingMsgs uint32 = 20
 	// DefaultStakerPortion is the default portion of resources to reserve for stakers
 	DefaultStakerPortion float64 = 0.375
+	// DefaultParseBudgetRate is the default number of container bytes per
+	// second a peer's parse budget is replenished by
+	DefaultParseBudgetRate uint64 = 8 << 20
+	// DefaultParseBudgetBurst is the default number of container bytes a peer
+	// can send at once before it's throttled
+	DefaultParseBudgetBurst uint64 = 32 << 20
 )
 
 // MsgManager manages incoming messages. It should be called when an incoming message
@@ -44,6 +50,7 @@ type msgManager struct {
 	msgTracker                     tracker.CountingTracker
 	stakerCPUPortion               float64
 	cpuTracker                     tracker.TimeTracker
+	costTracker                    tracker.CostTracker
 	clock                          timer.Clock
 	metrics                        msgManagerMetrics
 }
@@ -52,6 +59,7 @@ type msgManager struct {
 // [vdrs] is the network validator set
 // [msgTracker] tracks how many messages we've received from each peer
 // [cpuTracker] tracks how much time we spend processing messages from each peer
+// [costTracker] tracks the parse budget of each peer
 // [maxPendingMsgs] is the maximum number of pending messages (those we have
 //   received but not processed.)
 // [maxNonStakerPendingMsgs] is the maximum number of pending messages from non-validators.
@@ -60,6 +68,7 @@ func NewMsgManager(
 	log logging.Logger,
 	msgTracker tracker.CountingTracker,
 	cpuTracker tracker.TimeTracker,
+	costTracker tracker.CostTracker,
 	maxPendingMsgs,
 	maxNonStakerPendingMsgs uint32,
 	stakerMsgPortion,
@@ -79,6 +88,7 @@ func NewMsgManager(
 		vdrs:                    vdrs,
 		msgTracker:              msgTracker,
 		cpuTracker:              cpuTracker,
+		costTracker:             costTracker,
 		log:                     log,
 		reservedMessages:        reservedMessages,
 		poolMessages:            poolMessages,
@@ -91,6 +101,14 @@ func NewMsgManager(
 // AddPending marks that there is a message from [vdr] ready to be processed.
 // Return true if the message was added to the processing list.
 func (rm *msgManager) AddPending(vdr ids.ShortID) bool {
+	// Peers that have spent their parse budget are throttled until it's
+	// replenished, regardless of their stake
+	if rm.costTracker.Exhausted(vdr, rm.clock.Time()) {
+		rm.metrics.throttledBudgetExhausted.Inc()
+		rm.log.Verbo("Throttling message from %s due to an exhausted parse budget", vdr)
+		return false
+	}
+
 	// Attempt to take the message from the pool
 	outstandingPoolMessages := rm.msgTracker.PoolCount()
 	totalPeerMessages, peerPoolMessages := rm.msgTracker.OutstandingCount(vdr)
@@ -162,7 +180,8 @@ type msgManagerMetrics struct {
 	poolMsgsAvailable prometheus.Gauge
 	throttledPoolEmpty,
 	throttledPoolAllocExhausted,
-	throttledVdrAllocExhausted prometheus.Counter
+	throttledVdrAllocExhausted,
+	throttledBudgetExhausted prometheus.Counter
 }
 
 func (m *msgManagerMetrics) initialize(namespace string, registerer prometheus.Registerer) error {
@@ -202,5 +221,14 @@ func (m *msgManagerMetrics) initialize(namespace string, registerer prometheus.R
 	if err := registerer.Register(m.throttledVdrAllocExhausted); err != nil {
 		errs.Add(fmt.Errorf("failed to register throttled statistics due to %w", err))
 	}
+
+	m.throttledBudgetExhausted = prometheus.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "throttled_parse_budget_exhausted",
+		Help:      "Number of incoming messages dropped because a peer spent its parse budget",
+	})
+	if err := registerer.Register(m.throttledBudgetExhausted); err != nil {
+		errs.Add(fmt.Errorf("failed to register throttled statistics due to %w", err))
+	}
 	return errs.Err
 }
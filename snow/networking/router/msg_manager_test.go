@@ -36,6 +36,7 @@ func TestAddPending(t *testing.T) {
 		logging.NoLog{},
 		msgTracker,
 		cpuTracker,
+		tracker.NewCostTracker(0, 0),
 		uint32(bufferSize),
 		1,   // Allow each peer to take at most one message from pool
 		0.5, // Allot half of message queue to stakers
@@ -66,6 +67,40 @@ func TestAddPending(t *testing.T) {
 	}
 }
 
+func TestExhaustedParseBudgetGetsThrottled(t *testing.T) {
+	vdr := validators.GenerateRandomValidator(1)
+	vdrs := validators.NewSet()
+	if err := vdrs.AddWeight(vdr.ID(), vdr.Weight()); err != nil {
+		t.Fatal(err)
+	}
+
+	costTracker := tracker.NewCostTracker(1, 100)
+	resourceManager, err := NewMsgManager(
+		vdrs,
+		logging.NoLog{},
+		tracker.NewMessageTracker(),
+		tracker.NewCPUTracker(uptime.IntervalFactory{}, time.Second),
+		costTracker,
+		16,
+		4,
+		0.5,
+		0.5,
+		"",
+		prometheus.NewRegistry(),
+	)
+	assert.NoError(t, err)
+
+	if success := resourceManager.AddPending(vdr.ID()); !success {
+		t.Fatal("Failed to take a message before the parse budget was spent")
+	}
+	resourceManager.RemovePending(vdr.ID())
+
+	costTracker.Charge(vdr.ID(), 101, time.Now())
+	if success := resourceManager.AddPending(vdr.ID()); success {
+		t.Fatal("Should have throttled a staker that spent its parse budget")
+	}
+}
+
 func TestStakerGetsThrottled(t *testing.T) {
 	bufferSize := 8
 	vdrList := make([]validators.Validator, 0, bufferSize)
@@ -85,6 +120,7 @@ func TestStakerGetsThrottled(t *testing.T) {
 		logging.NoLog{},
 		msgTracker,
 		cpuTracker,
+		tracker.NewCostTracker(0, 0),
 		uint32(bufferSize),
 		1,   // Allow each peer to take at most one message from pool
 		0.5, // Allot half of message queue to stakers
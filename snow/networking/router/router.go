@@ -50,9 +50,12 @@ type ExternalRouter interface {
 	MultiPut(validatorID ids.ShortID, chainID ids.ID, requestID uint32, containers [][]byte)
 	Get(validatorID ids.ShortID, chainID ids.ID, requestID uint32, deadline time.Time, containerID ids.ID)
 	Put(validatorID ids.ShortID, chainID ids.ID, requestID uint32, containerID ids.ID, container []byte)
+	HintedPut(validatorID ids.ShortID, chainID ids.ID, requestID uint32, containerID ids.ID, container []byte, hints [][]byte)
 	PushQuery(validatorID ids.ShortID, chainID ids.ID, requestID uint32, deadline time.Time, containerID ids.ID, container []byte)
 	PullQuery(validatorID ids.ShortID, chainID ids.ID, requestID uint32, deadline time.Time, containerID ids.ID)
 	Chits(validatorID ids.ShortID, chainID ids.ID, requestID uint32, votes []ids.ID)
+	JustifiedChits(validatorID ids.ShortID, chainID ids.ID, requestID uint32, votes []ids.ID, heights []uint64)
+	GossipTxs(validatorID ids.ShortID, chainID ids.ID, containerID ids.ID, txIDs []ids.ID)
 }
 
 // InternalRouter deals with messages internal to this node
@@ -64,4 +67,6 @@ type InternalRouter interface {
 	QueryFailed(validatorID ids.ShortID, chainID ids.ID, requestID uint32)
 	Connected(validatorID ids.ShortID)
 	Disconnected(validatorID ids.ShortID)
+	CrossChainRequest(requestingChainID ids.ID, chainID ids.ID, requestID uint32, request []byte)
+	CrossChainResponse(respondingChainID ids.ID, chainID ids.ID, requestID uint32, response []byte)
 }
@@ -165,6 +165,7 @@ func TestMultiLevelQueuePrioritizes(t *testing.T) {
 		logging.NoLog{},
 		msgTracker,
 		cpuTracker,
+		tracker.NewCostTracker(0, 0),
 		bufferSize,
 		DefaultMaxNonStakerPendingMsgs,
 		DefaultStakerPortion,
@@ -282,6 +283,7 @@ func TestMultiLevelQueuePushesDownOldMessages(t *testing.T) {
 		logging.NoLog{},
 		msgTracker,
 		cpuTracker,
+		tracker.NewCostTracker(0, 0),
 		bufferSize,
 		DefaultMaxNonStakerPendingMsgs,
 		DefaultStakerPortion,
@@ -389,6 +391,7 @@ func TestMultiLevelQueueFreesSpace(t *testing.T) {
 		logging.NoLog{},
 		msgTracker,
 		cpuTracker,
+		tracker.NewCostTracker(0, 0),
 		bufferSize,
 		DefaultMaxNonStakerPendingMsgs,
 		DefaultStakerPortion,
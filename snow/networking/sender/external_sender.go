@@ -32,10 +32,14 @@ type ExternalSender interface {
 
 	Get(validatorID ids.ShortID, chainID ids.ID, requestID uint32, deadline time.Duration, containerID ids.ID) bool
 	Put(validatorID ids.ShortID, chainID ids.ID, requestID uint32, containerID ids.ID, container []byte)
+	HintedPut(validatorID ids.ShortID, chainID ids.ID, requestID uint32, containerID ids.ID, container []byte, hints [][]byte)
 
 	PushQuery(validatorIDs ids.ShortSet, chainID ids.ID, requestID uint32, deadline time.Duration, containerID ids.ID, container []byte) []ids.ShortID
 	PullQuery(validatorIDs ids.ShortSet, chainID ids.ID, requestID uint32, deadline time.Duration, containerID ids.ID) []ids.ShortID
 	Chits(validatorID ids.ShortID, chainID ids.ID, requestID uint32, votes []ids.ID)
+	JustifiedChits(validatorID ids.ShortID, chainID ids.ID, requestID uint32, votes []ids.ID, heights []uint64)
 
 	Gossip(chainID ids.ID, containerID ids.ID, container []byte)
+	GossipToValidators(chainID ids.ID, containerID ids.ID, container []byte)
+	GossipTxs(chainID ids.ID, containerID ids.ID, txIDs []ids.ID)
 }
@@ -92,7 +92,7 @@ func (s *Sender) GetAcceptedFrontier(validatorIDs ids.ShortSet, requestID uint32
 		if s.timeouts.IsBenched(validatorID, s.ctx.ChainID) {
 			s.failedDueToBench[constants.GetAcceptedFrontierMsg].Inc() // update metric
 			validatorIDs.Remove(validatorID)
-			s.timeouts.RegisterRequestToUnreachableValidator()
+			s.timeouts.RegisterRequestToUnreachableValidator(validatorID, s.ctx.ChainID, constants.GetAcceptedFrontierMsg, timeout.Benched)
 			// Immediately register a failure. Do so asynchronously to avoid deadlock.
 			go s.router.GetAcceptedFrontierFailed(s.ctx.NodeID, s.ctx.ChainID, requestID)
 		}
@@ -115,7 +115,7 @@ func (s *Sender) GetAcceptedFrontier(validatorIDs ids.ShortSet, requestID uint32
 	for validatorID := range validatorIDs {
 		// Note: The call to RegisterRequestToUnreachableValidator is not strictly necessary.
 		// This call causes the reported network latency look larger than it actually is.
-		s.timeouts.RegisterRequestToUnreachableValidator()
+		s.timeouts.RegisterRequestToUnreachableValidator(validatorID, s.ctx.ChainID, constants.GetAcceptedFrontierMsg, timeout.Unreachable)
 		go s.router.GetAcceptedFrontierFailed(validatorID, s.ctx.ChainID, requestID)
 	}
 }
@@ -148,7 +148,7 @@ func (s *Sender) GetAccepted(validatorIDs ids.ShortSet, requestID uint32, contai
 		if s.timeouts.IsBenched(validatorID, s.ctx.ChainID) {
 			s.failedDueToBench[constants.GetAcceptedMsg].Inc() // update metric
 			validatorIDs.Remove(validatorID)
-			s.timeouts.RegisterRequestToUnreachableValidator()
+			s.timeouts.RegisterRequestToUnreachableValidator(validatorID, s.ctx.ChainID, constants.GetAcceptedMsg, timeout.Benched)
 			// Immediately register a failure. Do so asynchronously to avoid deadlock.
 			go s.router.GetAcceptedFailed(validatorID, s.ctx.ChainID, requestID)
 		}
@@ -169,7 +169,7 @@ func (s *Sender) GetAccepted(validatorIDs ids.ShortSet, requestID uint32, contai
 
 	// Register failures for validators we didn't even send a request to.
 	for validatorID := range validatorIDs {
-		s.timeouts.RegisterRequestToUnreachableValidator()
+		s.timeouts.RegisterRequestToUnreachableValidator(validatorID, s.ctx.ChainID, constants.GetAcceptedMsg, timeout.Unreachable)
 		go s.router.GetAcceptedFailed(validatorID, s.ctx.ChainID, requestID)
 	}
 }
@@ -196,7 +196,7 @@ func (s *Sender) GetAncestors(validatorID ids.ShortID, requestID uint32, contain
 	// so we don't even bother sending requests to them. We just have them immediately fail.
 	if s.timeouts.IsBenched(validatorID, s.ctx.ChainID) {
 		s.failedDueToBench[constants.GetAncestorsMsg].Inc() // update metric
-		s.timeouts.RegisterRequestToUnreachableValidator()
+		s.timeouts.RegisterRequestToUnreachableValidator(validatorID, s.ctx.ChainID, constants.GetAncestorsMsg, timeout.Benched)
 		go s.router.GetAncestorsFailed(validatorID, s.ctx.ChainID, requestID)
 		return
 	}
@@ -210,7 +210,7 @@ func (s *Sender) GetAncestors(validatorID ids.ShortID, requestID uint32, contain
 		s.router.RegisterRequest(validatorID, s.ctx.ChainID, requestID, constants.GetAncestorsMsg)
 		return
 	}
-	s.timeouts.RegisterRequestToUnreachableValidator()
+	s.timeouts.RegisterRequestToUnreachableValidator(validatorID, s.ctx.ChainID, constants.GetAncestorsMsg, timeout.Unreachable)
 	go s.router.GetAncestorsFailed(validatorID, s.ctx.ChainID, requestID)
 }
 
@@ -239,7 +239,7 @@ func (s *Sender) Get(validatorID ids.ShortID, requestID uint32, containerID ids.
 	// so we don't even bother sending requests to them. We just have them immediately fail.
 	if s.timeouts.IsBenched(validatorID, s.ctx.ChainID) {
 		s.failedDueToBench[constants.GetMsg].Inc() // update metric
-		s.timeouts.RegisterRequestToUnreachableValidator()
+		s.timeouts.RegisterRequestToUnreachableValidator(validatorID, s.ctx.ChainID, constants.GetMsg, timeout.Benched)
 		go s.router.GetFailed(validatorID, s.ctx.ChainID, requestID)
 		return
 	}
@@ -253,7 +253,7 @@ func (s *Sender) Get(validatorID ids.ShortID, requestID uint32, containerID ids.
 		s.router.RegisterRequest(validatorID, s.ctx.ChainID, requestID, constants.GetMsg)
 		return
 	}
-	s.timeouts.RegisterRequestToUnreachableValidator()
+	s.timeouts.RegisterRequestToUnreachableValidator(validatorID, s.ctx.ChainID, constants.GetMsg, timeout.Unreachable)
 	go s.router.GetFailed(validatorID, s.ctx.ChainID, requestID)
 }
 
@@ -266,6 +266,16 @@ func (s *Sender) Put(validatorID ids.ShortID, requestID uint32, containerID ids.
 	s.sender.Put(validatorID, s.ctx.ChainID, requestID, containerID, container)
 }
 
+// HintedPut sends a HintedPut message to the consensus engine running on the
+// specified chain on the specified validator.
+// The HintedPut message signifies that this consensus engine is giving to the
+// recipient the contents of the specified container, along with ancestors of
+// the container that the recipient is likely missing.
+func (s *Sender) HintedPut(validatorID ids.ShortID, requestID uint32, containerID ids.ID, container []byte, hints [][]byte) {
+	s.ctx.Log.Verbo("Sending HintedPut to validator %s. RequestID: %d. ContainerID: %s. NumHints: %d", validatorID, requestID, containerID, len(hints))
+	s.sender.HintedPut(validatorID, s.ctx.ChainID, requestID, containerID, container, hints)
+}
+
 // PushQuery sends a PushQuery message to the consensus engines running on the specified chains
 // on the specified validators.
 // The PushQuery message signifies that this consensus engine would like each validator to send
@@ -291,7 +301,7 @@ func (s *Sender) PushQuery(validatorIDs ids.ShortSet, requestID uint32, containe
 		if s.timeouts.IsBenched(validatorID, s.ctx.ChainID) {
 			s.failedDueToBench[constants.PushQueryMsg].Inc() // update metric
 			validatorIDs.Remove(validatorID)
-			s.timeouts.RegisterRequestToUnreachableValidator()
+			s.timeouts.RegisterRequestToUnreachableValidator(validatorID, s.ctx.ChainID, constants.PushQueryMsg, timeout.Benched)
 			// Immediately register a failure. Do so asynchronously to avoid deadlock.
 			go s.router.QueryFailed(validatorID, s.ctx.ChainID, requestID)
 		}
@@ -311,7 +321,7 @@ func (s *Sender) PushQuery(validatorIDs ids.ShortSet, requestID uint32, containe
 
 	// Register failures for validators we didn't even send a request to.
 	for validatorID := range validatorIDs {
-		s.timeouts.RegisterRequestToUnreachableValidator()
+		s.timeouts.RegisterRequestToUnreachableValidator(validatorID, s.ctx.ChainID, constants.PushQueryMsg, timeout.Unreachable)
 		go s.router.QueryFailed(validatorID, s.ctx.ChainID, requestID)
 	}
 }
@@ -341,7 +351,7 @@ func (s *Sender) PullQuery(validatorIDs ids.ShortSet, requestID uint32, containe
 		if s.timeouts.IsBenched(validatorID, s.ctx.ChainID) {
 			s.failedDueToBench[constants.PullQueryMsg].Inc() // update metric
 			validatorIDs.Remove(validatorID)
-			s.timeouts.RegisterRequestToUnreachableValidator()
+			s.timeouts.RegisterRequestToUnreachableValidator(validatorID, s.ctx.ChainID, constants.PullQueryMsg, timeout.Benched)
 			// Immediately register a failure. Do so asynchronously to avoid deadlock.
 			go s.router.QueryFailed(validatorID, s.ctx.ChainID, requestID)
 		}
@@ -360,7 +370,7 @@ func (s *Sender) PullQuery(validatorIDs ids.ShortSet, requestID uint32, containe
 
 	// Register failures for validators we didn't even send a request to.
 	for validatorID := range validatorIDs {
-		s.timeouts.RegisterRequestToUnreachableValidator()
+		s.timeouts.RegisterRequestToUnreachableValidator(validatorID, s.ctx.ChainID, constants.PullQueryMsg, timeout.Unreachable)
 		go s.router.QueryFailed(validatorID, s.ctx.ChainID, requestID)
 	}
 }
@@ -377,8 +387,32 @@ func (s *Sender) Chits(validatorID ids.ShortID, requestID uint32, votes []ids.ID
 	}
 }
 
+// JustifiedChits sends chits along with the heights of the voted containers
+func (s *Sender) JustifiedChits(validatorID ids.ShortID, requestID uint32, votes []ids.ID, heights []uint64) {
+	s.ctx.Log.Verbo("Sending JustifiedChits to validator %s. RequestID: %d. Votes: %s. Heights: %v", validatorID, requestID, votes, heights)
+	// If [validatorID] is myself, send this message directly
+	// to my own router rather than sending it over the network
+	if validatorID == s.ctx.NodeID {
+		go s.router.JustifiedChits(validatorID, s.ctx.ChainID, requestID, votes, heights)
+	} else {
+		s.sender.JustifiedChits(validatorID, s.ctx.ChainID, requestID, votes, heights)
+	}
+}
+
 // Gossip the provided container
 func (s *Sender) Gossip(containerID ids.ID, container []byte) {
 	s.ctx.Log.Verbo("Gossiping %s", containerID)
 	s.sender.Gossip(s.ctx.ChainID, containerID, container)
 }
+
+// GossipToValidators gossips the provided container only to validators
+func (s *Sender) GossipToValidators(containerID ids.ID, container []byte) {
+	s.ctx.Log.Verbo("Gossiping %s to validators", containerID)
+	s.sender.GossipToValidators(s.ctx.ChainID, containerID, container)
+}
+
+// GossipTxs gossips the IDs of the txs in the provided container
+func (s *Sender) GossipTxs(containerID ids.ID, txIDs []ids.ID) {
+	s.ctx.Log.Verbo("Gossiping %d tx IDs from %s", len(txIDs), containerID)
+	s.sender.GossipTxs(s.ctx.ChainID, containerID, txIDs)
+}
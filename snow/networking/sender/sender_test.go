@@ -93,6 +93,7 @@ func TestTimeout(t *testing.T) {
 		router.DefaultMaxNonStakerPendingMsgs,
 		router.DefaultStakerPortion,
 		router.DefaultStakerPortion,
+		nil,
 		"",
 		prometheus.NewRegistry(),
 		&router.Delay{},
@@ -169,6 +170,7 @@ func TestReliableMessages(t *testing.T) {
 		router.DefaultMaxNonStakerPendingMsgs,
 		router.DefaultStakerPortion,
 		router.DefaultStakerPortion,
+		nil,
 		"",
 		prometheus.NewRegistry(),
 		&router.Delay{},
@@ -254,6 +256,7 @@ func TestReliableMessagesToMyself(t *testing.T) {
 		router.DefaultMaxNonStakerPendingMsgs,
 		router.DefaultStakerPortion,
 		router.DefaultStakerPortion,
+		nil,
 		"",
 		prometheus.NewRegistry(),
 		&router.Delay{},
@@ -18,9 +18,9 @@ type ExternalSenderTest struct {
 	CantGetAcceptedFrontier, CantAcceptedFrontier,
 	CantGetAccepted, CantAccepted,
 	CantGetAncestors, CantMultiPut,
-	CantGet, CantPut,
-	CantPullQuery, CantPushQuery, CantChits,
-	CantGossip bool
+	CantGet, CantPut, CantHintedPut,
+	CantPullQuery, CantPushQuery, CantChits, CantJustifiedChits,
+	CantGossip, CantGossipToValidators, CantGossipTxs bool
 
 	GetAcceptedFrontierF func(validatorIDs ids.ShortSet, chainID ids.ID, requestID uint32, deadline time.Duration) []ids.ShortID
 	AcceptedFrontierF    func(validatorID ids.ShortID, chainID ids.ID, requestID uint32, containerIDs []ids.ID)
@@ -34,11 +34,17 @@ type ExternalSenderTest struct {
 	GetF func(validatorID ids.ShortID, chainID ids.ID, requestID uint32, deadline time.Duration, containerID ids.ID) bool
 	PutF func(validatorID ids.ShortID, chainID ids.ID, requestID uint32, containerID ids.ID, container []byte)
 
+	HintedPutF func(validatorID ids.ShortID, chainID ids.ID, requestID uint32, containerID ids.ID, container []byte, hints [][]byte)
+
 	PushQueryF func(validatorIDs ids.ShortSet, chainID ids.ID, requestID uint32, deadline time.Duration, containerID ids.ID, container []byte) []ids.ShortID
 	PullQueryF func(validatorIDs ids.ShortSet, chainID ids.ID, requestID uint32, deadline time.Duration, containerID ids.ID) []ids.ShortID
 	ChitsF     func(validatorID ids.ShortID, chainID ids.ID, requestID uint32, votes []ids.ID)
 
-	GossipF func(chainID ids.ID, containerID ids.ID, container []byte)
+	JustifiedChitsF func(validatorID ids.ShortID, chainID ids.ID, requestID uint32, votes []ids.ID, heights []uint64)
+
+	GossipF             func(chainID ids.ID, containerID ids.ID, container []byte)
+	GossipToValidatorsF func(chainID ids.ID, containerID ids.ID, container []byte)
+	GossipTxsF          func(chainID ids.ID, containerID ids.ID, txIDs []ids.ID)
 }
 
 // Default set the default callable value to [cant]
@@ -54,12 +60,16 @@ func (s *ExternalSenderTest) Default(cant bool) {
 
 	s.CantGet = cant
 	s.CantPut = cant
+	s.CantHintedPut = cant
 
 	s.CantPullQuery = cant
 	s.CantPushQuery = cant
 	s.CantChits = cant
+	s.CantJustifiedChits = cant
 
 	s.CantGossip = cant
+	s.CantGossipToValidators = cant
+	s.CantGossipTxs = cant
 }
 
 // GetAcceptedFrontier calls GetAcceptedFrontierF if it was initialized. If it
@@ -178,6 +188,20 @@ func (s *ExternalSenderTest) Put(vdr ids.ShortID, chainID ids.ID, requestID uint
 	}
 }
 
+// HintedPut calls HintedPutF if it was initialized. If it wasn't initialized
+// and this function shouldn't be called and testing was initialized, then
+// testing will fail.
+func (s *ExternalSenderTest) HintedPut(vdr ids.ShortID, chainID ids.ID, requestID uint32, vtxID ids.ID, vtx []byte, hints [][]byte) {
+	switch {
+	case s.HintedPutF != nil:
+		s.HintedPutF(vdr, chainID, requestID, vtxID, vtx, hints)
+	case s.CantHintedPut && s.T != nil:
+		s.T.Fatalf("Unexpectedly called HintedPut")
+	case s.CantHintedPut && s.B != nil:
+		s.B.Fatalf("Unexpectedly called HintedPut")
+	}
+}
+
 // PushQuery calls PushQueryF if it was initialized. If it wasn't initialized
 // and this function shouldn't be called and testing was initialized, then
 // testing will fail.
@@ -222,6 +246,20 @@ func (s *ExternalSenderTest) Chits(vdr ids.ShortID, chainID ids.ID, requestID ui
 	}
 }
 
+// JustifiedChits calls JustifiedChitsF if it was initialized. If it wasn't
+// initialized and this function shouldn't be called and testing was
+// initialized, then testing will fail.
+func (s *ExternalSenderTest) JustifiedChits(vdr ids.ShortID, chainID ids.ID, requestID uint32, votes []ids.ID, heights []uint64) {
+	switch {
+	case s.JustifiedChitsF != nil:
+		s.JustifiedChitsF(vdr, chainID, requestID, votes, heights)
+	case s.CantJustifiedChits && s.T != nil:
+		s.T.Fatalf("Unexpectedly called JustifiedChits")
+	case s.CantJustifiedChits && s.B != nil:
+		s.B.Fatalf("Unexpectedly called JustifiedChits")
+	}
+}
+
 // Gossip calls GossipF if it was initialized. If it wasn't initialized and this
 // function shouldn't be called and testing was initialized, then testing will
 // fail.
@@ -235,3 +273,31 @@ func (s *ExternalSenderTest) Gossip(chainID ids.ID, containerID ids.ID, containe
 		s.B.Fatalf("Unexpectedly called Gossip")
 	}
 }
+
+// GossipToValidators calls GossipToValidatorsF if it was initialized. If it
+// wasn't initialized and this function shouldn't be called and testing was
+// initialized, then testing will fail.
+func (s *ExternalSenderTest) GossipToValidators(chainID ids.ID, containerID ids.ID, container []byte) {
+	switch {
+	case s.GossipToValidatorsF != nil:
+		s.GossipToValidatorsF(chainID, containerID, container)
+	case s.CantGossipToValidators && s.T != nil:
+		s.T.Fatalf("Unexpectedly called GossipToValidators")
+	case s.CantGossipToValidators && s.B != nil:
+		s.B.Fatalf("Unexpectedly called GossipToValidators")
+	}
+}
+
+// GossipTxs calls GossipTxsF if it was initialized. If it wasn't initialized
+// and this function shouldn't be called and testing was initialized, then
+// testing will fail.
+func (s *ExternalSenderTest) GossipTxs(chainID ids.ID, containerID ids.ID, txIDs []ids.ID) {
+	switch {
+	case s.GossipTxsF != nil:
+		s.GossipTxsF(chainID, containerID, txIDs)
+	case s.CantGossipTxs && s.T != nil:
+		s.T.Fatalf("Unexpectedly called GossipTxs")
+	case s.CantGossipTxs && s.B != nil:
+		s.B.Fatalf("Unexpectedly called GossipTxs")
+	}
+}
@@ -15,6 +15,33 @@ import (
 	"github.com/ava-labs/avalanchego/utils/timer"
 )
 
+// FailureCause describes why a request failed
+type FailureCause byte
+
+const (
+	// TimedOut means that no response was received in time
+	TimedOut FailureCause = iota
+	// Benched means that the request wasn't sent because the validator is
+	// benched
+	Benched
+	// Unreachable means that the request couldn't be sent, such as because
+	// the validator isn't connected
+	Unreachable
+)
+
+func (c FailureCause) String() string {
+	switch c {
+	case TimedOut:
+		return "timeout"
+	case Benched:
+		return "benched"
+	case Unreachable:
+		return "unreachable"
+	default:
+		return "unknown"
+	}
+}
+
 // Manager registers and fires timeouts for the snow API.
 type Manager struct {
 	lock         sync.Mutex
@@ -69,6 +96,7 @@ func (m *Manager) RegisterRequest(
 	newTimeoutHandler := func() {
 		// If this request timed out, tell the benchlist manager
 		m.benchlistMgr.RegisterFailure(chainID, validatorID)
+		m.registerFailure(validatorID, chainID, msgType, TimedOut)
 		timeoutHandler()
 	}
 	return m.tm.Put(uniqueRequestID, msgType, newTimeoutHandler), true
@@ -95,6 +123,26 @@ func (m *Manager) RegisterResponse(
 // or because of network conditions (e.g. we're not connected), so we didn't
 // send the query. For the sake// of calculating the average latency and
 // network timeout, we act as though we sent the validator a request and it timed out.
-func (m *Manager) RegisterRequestToUnreachableValidator() {
+// The failure is reported with [cause].
+func (m *Manager) RegisterRequestToUnreachableValidator(
+	validatorID ids.ShortID,
+	chainID ids.ID,
+	msgType constants.MsgType,
+	cause FailureCause,
+) {
 	m.tm.ObserveLatency(m.TimeoutDuration())
+	m.registerFailure(validatorID, chainID, msgType, cause)
+}
+
+// registerFailure records that a request of type [msgType] to [validatorID]
+// regarding chain [chainID] failed due to [cause]
+func (m *Manager) registerFailure(
+	validatorID ids.ShortID,
+	chainID ids.ID,
+	msgType constants.MsgType,
+	cause FailureCause,
+) {
+	m.lock.Lock()
+	m.metrics.fail(chainID, validatorID, msgType, cause)
+	m.lock.Unlock()
 }
@@ -9,8 +9,10 @@ import (
 	"time"
 
 	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
 
 	"github.com/ava-labs/avalanchego/ids"
+	"github.com/ava-labs/avalanchego/snow"
 	"github.com/ava-labs/avalanchego/snow/networking/benchlist"
 	"github.com/ava-labs/avalanchego/utils/constants"
 	"github.com/ava-labs/avalanchego/utils/timer"
@@ -76,3 +78,53 @@ func TestManagerCancel(t *testing.T) {
 		t.Fatalf("Should have cancelled the function")
 	}
 }
+
+func TestManagerCountsFailuresByCause(t *testing.T) {
+	manager := Manager{}
+	benchlist := benchlist.NewNoBenchlist()
+	err := manager.Initialize(&timer.AdaptiveTimeoutConfig{
+		InitialTimeout:     time.Millisecond,
+		MinimumTimeout:     time.Millisecond,
+		MaximumTimeout:     10 * time.Second,
+		TimeoutCoefficient: 1.25,
+		TimeoutHalflife:    5 * time.Minute,
+		MetricsNamespace:   "",
+		Registerer:         prometheus.NewRegistry(),
+	}, benchlist)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx := snow.DefaultContextTest()
+	if err := manager.RegisterChain(ctx, "test"); err != nil {
+		t.Fatal(err)
+	}
+	go manager.Dispatch()
+
+	vdr := ids.GenerateTestShortID()
+
+	wg := sync.WaitGroup{}
+	wg.Add(1)
+	manager.RegisterRequest(vdr, ctx.ChainID, constants.PullQueryMsg, ids.GenerateTestID(), wg.Done)
+	wg.Wait()
+
+	manager.RegisterRequestToUnreachableValidator(vdr, ctx.ChainID, constants.GetMsg, Benched)
+	manager.RegisterRequestToUnreachableValidator(vdr, ctx.ChainID, constants.GetMsg, Unreachable)
+	manager.RegisterRequestToUnreachableValidator(vdr, ctx.ChainID, constants.GetMsg, Unreachable)
+
+	failures := manager.metrics.chainToMetrics[ctx.ChainID].failures
+	for _, test := range []struct {
+		msgType  constants.MsgType
+		cause    FailureCause
+		expected float64
+	}{
+		{constants.PullQueryMsg, TimedOut, 1},
+		{constants.GetMsg, TimedOut, 0},
+		{constants.GetMsg, Benched, 1},
+		{constants.GetMsg, Unreachable, 2},
+	} {
+		count := testutil.ToFloat64(failures.WithLabelValues(test.msgType.String(), test.cause.String(), vdr.String()))
+		if count != test.expected {
+			t.Fatalf("Expected %v %s failures due to %s but got %v", test.expected, test.msgType, test.cause, count)
+		}
+	}
+}
@@ -16,6 +16,8 @@ import (
 const (
 	defaultRequestHelpMsg = "Time spent waiting for a response to this message in milliseconds"
 	validatorIDLabel      = "validatorID"
+	opLabel               = "op"
+	causeLabel            = "cause"
 )
 
 func initHistogram(
@@ -85,6 +87,16 @@ func (m *metrics) observe(chainID ids.ID, msgType constants.MsgType, latency tim
 	cm.observe(ids.ShortEmpty, msgType, latency)
 }
 
+// Record that a request of type [msgType] to [validatorID] regarding chain
+// [chainID] failed due to [cause]
+func (m *metrics) fail(chainID ids.ID, validatorID ids.ShortID, msgType constants.MsgType, cause FailureCause) {
+	cm, exists := m.chainToMetrics[chainID]
+	if !exists {
+		return
+	}
+	cm.failures.WithLabelValues(msgType.String(), cause.String(), validatorID.String()).Inc()
+}
+
 // chainMetrics contains message response time metrics for a chain
 type chainMetrics struct {
 	ctx *snow.Context
@@ -98,6 +110,9 @@ type chainMetrics struct {
 	getAcceptedFrontier, getAccepted,
 	getAncestors, get,
 	pushQuery, pullQuery prometheus.Histogram
+
+	// failures counts the failed requests by op, cause, and validator
+	failures *prometheus.CounterVec
 }
 
 // Initialize implements the Engine interface
@@ -121,6 +136,15 @@ func (cm *chainMetrics) Initialize(ctx *snow.Context, namespace string, summaryE
 	cm.pushQuery = initHistogram(queryLatencyNamespace, "push_query", ctx.Metrics, &errs)
 	cm.pullQuery = initHistogram(queryLatencyNamespace, "pull_query", ctx.Metrics, &errs)
 
+	cm.failures = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "request_failures",
+		Help:      "Number of requests that failed, by the type of request, why it failed, and the validator it was sent to",
+	}, []string{opLabel, causeLabel, validatorIDLabel})
+	if err := ctx.Metrics.Register(cm.failures); err != nil {
+		errs.Add(fmt.Errorf("failed to register request_failures statistics: %w", err))
+	}
+
 	return errs.Err
 }
 
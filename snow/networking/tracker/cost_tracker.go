@@ -0,0 +1,101 @@
+// (c) 2019-2020, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package tracker
+
+import (
+	"sync"
+	"time"
+
+	"github.com/ava-labs/avalanchego/ids"
+)
+
+// CostTracker is an interface for charging peers for the expensive work their
+// messages cause, such as parsing and verifying the signatures of the
+// containers they send
+type CostTracker interface {
+	// Charge [cost] to the budget of [vdr] at [currentTime]
+	Charge(vdr ids.ShortID, cost uint64, currentTime time.Time)
+	// Exhausted returns true if [vdr] has spent its budget at [currentTime]
+	Exhausted(vdr ids.ShortID, currentTime time.Time) bool
+}
+
+// costTracker implements CostTracker by giving each peer a budget of [burst]
+// that is replenished at [rate] per second
+type costTracker struct {
+	lock sync.Mutex
+
+	rate, burst float64
+	budgets     map[ids.ShortID]*budget
+}
+
+type budget struct {
+	remaining float64
+	updated   time.Time
+}
+
+// NewCostTracker returns a CostTracker that allows each peer to spend [burst]
+// at once and replenishes their budget at [rate] per second. If [rate] is 0,
+// peers are never charged.
+func NewCostTracker(rate, burst uint64) CostTracker {
+	return &costTracker{
+		rate:    float64(rate),
+		burst:   float64(burst),
+		budgets: make(map[ids.ShortID]*budget),
+	}
+}
+
+// Charge implements CostTracker
+func (ct *costTracker) Charge(vdr ids.ShortID, cost uint64, currentTime time.Time) {
+	if ct.rate == 0 {
+		return
+	}
+
+	ct.lock.Lock()
+	defer ct.lock.Unlock()
+
+	b, exists := ct.budgets[vdr]
+	if !exists {
+		b = &budget{
+			remaining: ct.burst,
+			updated:   currentTime,
+		}
+		ct.budgets[vdr] = b
+	}
+	ct.replenish(b, currentTime)
+
+	// The budget may go negative, so that a peer that sent one very expensive
+	// message is throttled until it has paid for it
+	b.remaining -= float64(cost)
+}
+
+// Exhausted implements CostTracker
+func (ct *costTracker) Exhausted(vdr ids.ShortID, currentTime time.Time) bool {
+	ct.lock.Lock()
+	defer ct.lock.Unlock()
+
+	b, exists := ct.budgets[vdr]
+	if !exists {
+		return false
+	}
+	ct.replenish(b, currentTime)
+
+	// Peers with a full budget don't need to be tracked anymore
+	if b.remaining >= ct.burst {
+		delete(ct.budgets, vdr)
+		return false
+	}
+	return b.remaining <= 0
+}
+
+// replenish the budget for the time since it was last updated
+// assumes the lock is held
+func (ct *costTracker) replenish(b *budget, currentTime time.Time) {
+	if elapsed := currentTime.Sub(b.updated); elapsed > 0 {
+		b.remaining += elapsed.Seconds() * ct.rate
+		if b.remaining > ct.burst {
+			b.remaining = ct.burst
+		}
+	}
+	b.updated = currentTime
+}
@@ -0,0 +1,56 @@
+// (c) 2019-2020, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package tracker
+
+import (
+	"testing"
+	"time"
+
+	"github.com/ava-labs/avalanchego/ids"
+)
+
+func TestCostTracker(t *testing.T) {
+	costTracker := NewCostTracker(100, 1000)
+	vdr1 := ids.ShortID{1}
+	vdr2 := ids.ShortID{2}
+
+	currentTime := time.Now()
+	if costTracker.Exhausted(vdr1, currentTime) {
+		t.Fatalf("A peer that wasn't charged shouldn't have exhausted its budget")
+	}
+
+	costTracker.Charge(vdr1, 999, currentTime)
+	if costTracker.Exhausted(vdr1, currentTime) {
+		t.Fatalf("Budget shouldn't have been exhausted before it was spent")
+	}
+
+	costTracker.Charge(vdr1, 501, currentTime)
+	if !costTracker.Exhausted(vdr1, currentTime) {
+		t.Fatalf("Budget should have been exhausted")
+	}
+	if costTracker.Exhausted(vdr2, currentTime) {
+		t.Fatalf("Budgets should be tracked per peer")
+	}
+
+	// The peer owes 500, so it stays throttled until that's replenished
+	currentTime = currentTime.Add(5 * time.Second)
+	if !costTracker.Exhausted(vdr1, currentTime) {
+		t.Fatalf("Budget should have still been exhausted")
+	}
+	currentTime = currentTime.Add(time.Second)
+	if costTracker.Exhausted(vdr1, currentTime) {
+		t.Fatalf("Budget should have been replenished")
+	}
+}
+
+func TestCostTrackerUnlimited(t *testing.T) {
+	costTracker := NewCostTracker(0, 0)
+	vdr := ids.ShortID{1}
+
+	currentTime := time.Now()
+	costTracker.Charge(vdr, 1000, currentTime)
+	if costTracker.Exhausted(vdr, currentTime) {
+		t.Fatalf("Peers shouldn't be charged without a rate")
+	}
+}
@@ -0,0 +1,24 @@
+// (c) 2019-2020, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package snow
+
+import (
+	"encoding/binary"
+	"math/rand"
+
+	"github.com/ava-labs/avalanchego/ids"
+	"github.com/ava-labs/avalanchego/utils/hashing"
+)
+
+// NewRandom returns the source of randomness of the chain [chainID], seeded
+// from the chain ID and [seed]. Chains created with the same seed make
+// the same random choices, so consensus simulations are reproducible. Nodes
+// should use different seeds, so that they don't sample the same peers.
+func NewRandom(chainID ids.ID, seed []byte) *rand.Rand {
+	preimage := make([]byte, 0, len(chainID)+len(seed))
+	preimage = append(preimage, chainID[:]...)
+	preimage = append(preimage, seed...)
+	hash := hashing.ComputeHash256(preimage)
+	return rand.New(rand.NewSource(int64(binary.BigEndian.Uint64(hash)))) // #nosec G404
+}
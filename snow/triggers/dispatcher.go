@@ -123,6 +123,38 @@ func (ed *EventDispatcher) Issue(ctx *snow.Context, containerID ids.ID, containe
 	}
 }
 
+// AdvanceFrontier is called when the accepted frontier of a chain changes
+func (ed *EventDispatcher) AdvanceFrontier(ctx *snow.Context, height uint64, frontier []ids.ID) {
+	ed.lock.Lock()
+	defer ed.lock.Unlock()
+
+	for id, handler := range ed.handlers {
+		handler, ok := handler.(FrontierAdvancer)
+		if !ok {
+			continue
+		}
+
+		if err := handler.AdvanceFrontier(ctx, height, frontier); err != nil {
+			ed.log.Error("unable to AdvanceFrontier on %s for chainID %s: %s", id, ctx.ChainID, err)
+		}
+	}
+
+	events, exist := ed.chainHandlers[ctx.ChainID]
+	if !exist {
+		return
+	}
+	for id, handler := range events {
+		handler, ok := handler.(FrontierAdvancer)
+		if !ok {
+			continue
+		}
+
+		if err := handler.AdvanceFrontier(ctx, height, frontier); err != nil {
+			ed.log.Error("unable to AdvanceFrontier on %s for chainID %s: %s", id, ctx.ChainID, err)
+		}
+	}
+}
+
 // RegisterChain places a new chain handler into the system
 func (ed *EventDispatcher) RegisterChain(chainID ids.ID, identifier string, handler interface{}) error {
 	ed.lock.Lock()
@@ -22,3 +22,10 @@ type Rejector interface {
 type Issuer interface {
 	Issue(ctx *snow.Context, containerID ids.ID, container []byte) error
 }
+
+// FrontierAdvancer is implemented when a struct is monitoring the accepted
+// frontier of a chain. [height] is the greatest height of the containers in
+// [frontier].
+type FrontierAdvancer interface {
+	AdvanceFrontier(ctx *snow.Context, height uint64, frontier []ids.ID) error
+}
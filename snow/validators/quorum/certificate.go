@@ -0,0 +1,152 @@
+// (c) 2019-2020, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package quorum
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/ava-labs/avalanchego/ids"
+	"github.com/ava-labs/avalanchego/snow/validators"
+)
+
+var (
+	errNotValidator      = errors.New("signer isn't a validator")
+	errInvalidSignature  = errors.New("invalid signature")
+	errQuorumNotReached  = errors.New("signers don't have enough weight")
+	errWrongSignersLen   = errors.New("signers bitset has the wrong length")
+	errUnknownSigner     = errors.New("signers bitset includes an unknown validator")
+	errWrongSignatureLen = errors.New("number of signatures doesn't match the number of signers")
+)
+
+// Verifier checks the signatures of validators
+type Verifier interface {
+	// Verify returns true if [signature] is [vdrID]'s signature of [message]
+	Verify(vdrID ids.ShortID, message, signature []byte) bool
+}
+
+// Certificate proves that validators with enough weight signed a message.
+//
+// Rather than listing the IDs of the signers, it marks them in a bitset over
+// the IDs of the validators in ascending order, so it's only valid with
+// respect to the validator set that it was created with.
+type Certificate struct {
+	// Bit i is set if the i'th validator, in ascending order of ID, signed
+	Signers []byte `serialize:"true" json:"signers"`
+	// Signatures of the signers, in the order of the signers
+	Signatures [][]byte `serialize:"true" json:"signatures"`
+}
+
+// Signatures collects validators' signatures of a message until they form a
+// quorum
+type Signatures struct {
+	tally    *Tally
+	message  []byte
+	verifier Verifier
+	// validator ID --> signature of [message]
+	signatures map[ids.ShortID][]byte
+}
+
+// NewSignatures returns an empty collection of the signatures of [message] by
+// [vdrs], which reaches quorum once the signers have a weight of at least
+// [alpha]
+func NewSignatures(vdrs validators.Set, alpha uint64, message []byte, verifier Verifier) *Signatures {
+	return &Signatures{
+		tally:      NewTally(vdrs, alpha),
+		message:    message,
+		verifier:   verifier,
+		signatures: make(map[ids.ShortID][]byte),
+	}
+}
+
+// Add [vdrID]'s signature. Additional signatures by the same validator are
+// ignored.
+func (s *Signatures) Add(vdrID ids.ShortID, signature []byte) error {
+	if _, exists := s.signatures[vdrID]; exists {
+		return nil
+	}
+	if !s.tally.vdrs.Contains(vdrID) {
+		return fmt.Errorf("%w: %s", errNotValidator, vdrID)
+	}
+	if !s.verifier.Verify(vdrID, s.message, signature) {
+		return fmt.Errorf("%w from %s", errInvalidSignature, vdrID)
+	}
+	s.tally.Add(vdrID)
+	s.signatures[vdrID] = signature
+	return nil
+}
+
+// Reached returns true if the signers have enough weight
+func (s *Signatures) Reached() bool { return s.tally.Reached() }
+
+// Certificate returns a certificate of the collected signatures. Errors if the
+// signers don't have enough weight.
+func (s *Signatures) Certificate() (*Certificate, error) {
+	if !s.tally.Reached() {
+		return nil, errQuorumNotReached
+	}
+	vdrIDs := sortedIDs(s.tally.vdrs)
+	cert := &Certificate{
+		Signers:    make([]byte, signersLen(len(vdrIDs))),
+		Signatures: make([][]byte, 0, len(s.signatures)),
+	}
+	for i, vdrID := range vdrIDs {
+		if signature, ok := s.signatures[vdrID]; ok {
+			cert.Signers[i/8] |= 1 << (i % 8)
+			cert.Signatures = append(cert.Signatures, signature)
+		}
+	}
+	return cert, nil
+}
+
+// Verify that [cert] proves that validators in [vdrs] with a weight of at
+// least [alpha] signed [message]
+func Verify(cert *Certificate, vdrs validators.Set, alpha uint64, message []byte, verifier Verifier) error {
+	vdrIDs := sortedIDs(vdrs)
+	if len(cert.Signers) != signersLen(len(vdrIDs)) {
+		return errWrongSignersLen
+	}
+
+	tally := NewTally(vdrs, alpha)
+	numSigners := 0
+	for i := 0; i < 8*len(cert.Signers); i++ {
+		if cert.Signers[i/8]&(1<<(i%8)) == 0 {
+			continue
+		}
+		if i >= len(vdrIDs) {
+			return errUnknownSigner
+		}
+		if numSigners >= len(cert.Signatures) {
+			return errWrongSignatureLen
+		}
+		vdrID := vdrIDs[i]
+		if !verifier.Verify(vdrID, message, cert.Signatures[numSigners]) {
+			return fmt.Errorf("%w from %s", errInvalidSignature, vdrID)
+		}
+		tally.Add(vdrID)
+		numSigners++
+	}
+	if numSigners != len(cert.Signatures) {
+		return errWrongSignatureLen
+	}
+	if !tally.Reached() {
+		return errQuorumNotReached
+	}
+	return nil
+}
+
+// sortedIDs returns the IDs of [vdrs] in ascending order
+func sortedIDs(vdrs validators.Set) []ids.ShortID {
+	vdrList := vdrs.List()
+	vdrIDs := make([]ids.ShortID, len(vdrList))
+	for i, vdr := range vdrList {
+		vdrIDs[i] = vdr.ID()
+	}
+	ids.SortShortIDs(vdrIDs)
+	return vdrIDs
+}
+
+// signersLen returns the length of a signers bitset over [numValidators]
+// validators
+func signersLen(numValidators int) int { return (numValidators + 7) / 8 }
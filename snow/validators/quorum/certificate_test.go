@@ -0,0 +1,105 @@
+// (c) 2019-2020, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package quorum
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/ava-labs/avalanchego/ids"
+	"github.com/ava-labs/avalanchego/snow/validators"
+)
+
+// testVerifier accepts signatures that are the signer's ID followed by the
+// message
+type testVerifier struct{}
+
+func (testVerifier) Verify(vdrID ids.ShortID, message, signature []byte) bool {
+	return bytes.Equal(signature, testSign(vdrID, message))
+}
+
+func testSign(vdrID ids.ShortID, message []byte) []byte {
+	return append(vdrID.Bytes(), message...)
+}
+
+func newTestValidators(t *testing.T, weights ...uint64) (validators.Set, []ids.ShortID) {
+	vdrs := validators.NewSet()
+	vdrIDs := make([]ids.ShortID, len(weights))
+	for i, weight := range weights {
+		vdrIDs[i] = ids.GenerateTestShortID()
+		assert.NoError(t, vdrs.AddWeight(vdrIDs[i], weight))
+	}
+	return vdrs, vdrIDs
+}
+
+func TestCertificate(t *testing.T) {
+	vdrs, vdrIDs := newTestValidators(t, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1)
+	message := []byte("checkpoint")
+
+	sigs := NewSignatures(vdrs, 6, message, testVerifier{})
+	assert.True(t, errors.Is(sigs.Add(ids.GenerateTestShortID(), nil), errNotValidator))
+	assert.True(t, errors.Is(sigs.Add(vdrIDs[0], []byte("forged")), errInvalidSignature))
+	for _, vdrID := range vdrIDs[:5] {
+		assert.NoError(t, sigs.Add(vdrID, testSign(vdrID, message)))
+	}
+	// Duplicate signatures are ignored
+	assert.NoError(t, sigs.Add(vdrIDs[0], testSign(vdrIDs[0], message)))
+	assert.False(t, sigs.Reached())
+	_, err := sigs.Certificate()
+	assert.Equal(t, errQuorumNotReached, err)
+
+	assert.NoError(t, sigs.Add(vdrIDs[9], testSign(vdrIDs[9], message)))
+	assert.True(t, sigs.Reached())
+	cert, err := sigs.Certificate()
+	assert.NoError(t, err)
+	assert.Len(t, cert.Signers, 2)
+	assert.Len(t, cert.Signatures, 6)
+
+	assert.NoError(t, Verify(cert, vdrs, 6, message, testVerifier{}))
+	assert.Equal(t, errQuorumNotReached, Verify(cert, vdrs, 7, message, testVerifier{}))
+	assert.True(t, errors.Is(Verify(cert, vdrs, 6, []byte("other"), testVerifier{}), errInvalidSignature))
+}
+
+func TestVerifyMalformedCertificate(t *testing.T) {
+	vdrs, vdrIDs := newTestValidators(t, 1, 1, 1)
+	message := []byte("frontier")
+
+	sigs := NewSignatures(vdrs, 2, message, testVerifier{})
+	for _, vdrID := range vdrIDs {
+		assert.NoError(t, sigs.Add(vdrID, testSign(vdrID, message)))
+	}
+	cert, err := sigs.Certificate()
+	assert.NoError(t, err)
+	assert.NoError(t, Verify(cert, vdrs, 2, message, testVerifier{}))
+
+	tests := map[string]struct {
+		cert *Certificate
+		err  error
+	}{
+		"wrong signers length": {
+			cert: &Certificate{Signers: []byte{0x07, 0x00}, Signatures: cert.Signatures},
+			err:  errWrongSignersLen,
+		},
+		"unknown signer": {
+			cert: &Certificate{Signers: []byte{0x0F}, Signatures: cert.Signatures},
+			err:  errUnknownSigner,
+		},
+		"missing signature": {
+			cert: &Certificate{Signers: []byte{0x07}, Signatures: cert.Signatures[:2]},
+			err:  errWrongSignatureLen,
+		},
+		"extra signature": {
+			cert: &Certificate{Signers: []byte{0x03}, Signatures: cert.Signatures},
+			err:  errWrongSignatureLen,
+		},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			assert.Equal(t, test.err, Verify(test.cert, vdrs, 2, message, testVerifier{}))
+		})
+	}
+}
@@ -0,0 +1,61 @@
+// (c) 2019-2020, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+// Package quorum determines whether enough of a validator set's stake has
+// endorsed something, and produces compact certificates of such endorsements.
+package quorum
+
+import (
+	"math"
+
+	"github.com/ava-labs/avalanchego/ids"
+	"github.com/ava-labs/avalanchego/snow/validators"
+
+	safemath "github.com/ava-labs/avalanchego/utils/math"
+)
+
+// Tally accumulates the weight of the validators that voted for something.
+// Each validator is counted at most once.
+type Tally struct {
+	vdrs   validators.Set
+	alpha  uint64
+	voters ids.ShortSet
+	weight uint64
+}
+
+// NewTally returns a tally of the votes of [vdrs] that reaches quorum once the
+// voters have a weight of at least [alpha]
+func NewTally(vdrs validators.Set, alpha uint64) *Tally {
+	return &Tally{
+		vdrs:  vdrs,
+		alpha: alpha,
+	}
+}
+
+// Add the vote of [vdrID]. Returns true if the vote was counted, which is the
+// case if [vdrID] is a validator that hadn't voted yet.
+func (t *Tally) Add(vdrID ids.ShortID) bool {
+	if t.voters.Contains(vdrID) {
+		return false
+	}
+	weight, ok := t.vdrs.GetWeight(vdrID)
+	if !ok {
+		return false
+	}
+	t.voters.Add(vdrID)
+	newWeight, err := safemath.Add64(t.weight, weight)
+	if err != nil {
+		newWeight = math.MaxUint64
+	}
+	t.weight = newWeight
+	return true
+}
+
+// Weight returns the total weight of the counted votes
+func (t *Tally) Weight() uint64 { return t.weight }
+
+// Reached returns true if the counted votes have a weight of at least alpha
+func (t *Tally) Reached() bool { return t.weight >= t.alpha }
+
+// Voters returns the validators whose votes were counted
+func (t *Tally) Voters() ids.ShortSet { return t.voters }
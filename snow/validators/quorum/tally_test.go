@@ -0,0 +1,33 @@
+// (c) 2019-2020, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package quorum
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/ava-labs/avalanchego/ids"
+	"github.com/ava-labs/avalanchego/snow/validators"
+)
+
+func TestTally(t *testing.T) {
+	vdrs := validators.NewSet()
+	vdr0, vdr1, vdr2 := ids.ShortID{1}, ids.ShortID{2}, ids.ShortID{3}
+	assert.NoError(t, vdrs.AddWeight(vdr0, 1))
+	assert.NoError(t, vdrs.AddWeight(vdr1, 2))
+	assert.NoError(t, vdrs.AddWeight(vdr2, 3))
+
+	tally := NewTally(vdrs, 4)
+	assert.True(t, tally.Add(vdr2))
+	assert.False(t, tally.Add(vdr2), "should only count a validator once")
+	assert.False(t, tally.Add(ids.ShortID{4}), "shouldn't count a non-validator")
+	assert.Equal(t, uint64(3), tally.Weight())
+	assert.False(t, tally.Reached())
+
+	assert.True(t, tally.Add(vdr0))
+	assert.Equal(t, uint64(4), tally.Weight())
+	assert.True(t, tally.Reached())
+	assert.Equal(t, 2, tally.Voters().Len())
+}
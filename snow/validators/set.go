@@ -65,6 +65,11 @@ type Set interface {
 	// If sampling the requested size isn't possible, an error will be returned.
 	Sample(size int) ([]Validator, error)
 
+	// SampleWithSource is Sample, drawing randomness from [source] rather than
+	// the global source, so that the sample is reproducible. If [source] is
+	// nil, the global source is used.
+	SampleWithSource(size int, source sampler.Source) ([]Validator, error)
+
 	// MaskValidator hides the named validator from future samplings
 	MaskValidator(ids.ShortID) error
 
@@ -376,11 +381,18 @@ func (s *set) Sample(size int) ([]Validator, error) {
 	s.lock.Lock()
 	defer s.lock.Unlock()
 
-	return s.sample(size)
+	return s.sample(size, nil)
+}
+
+func (s *set) SampleWithSource(size int, source sampler.Source) ([]Validator, error) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	return s.sample(size, source)
 }
 
-func (s *set) sample(size int) ([]Validator, error) {
-	indices, err := s.sampler.Sample(size)
+func (s *set) sample(size int, source sampler.Source) ([]Validator, error) {
+	indices, err := s.sampler.SampleWithSource(size, source)
 	if err != nil {
 		return nil, err
 	}
@@ -5,6 +5,7 @@ package validators
 
 import (
 	"math"
+	"math/rand"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -75,6 +76,20 @@ func TestSamplerSample(t *testing.T) {
 	assert.Equal(t, vdr1, sampled[2].ID(), "should have sampled vdr1")
 }
 
+func TestSamplerSampleWithSource(t *testing.T) {
+	s := NewSet()
+	for i := 0; i < 100; i++ {
+		err := s.AddWeight(ids.GenerateTestShortID(), uint64(i+1))
+		assert.NoError(t, err)
+	}
+
+	expected, err := s.SampleWithSource(20, rand.New(rand.NewSource(1)))
+	assert.NoError(t, err)
+	sampled, err := s.SampleWithSource(20, rand.New(rand.NewSource(1)))
+	assert.NoError(t, err)
+	assert.Equal(t, expected, sampled, "sources in the same state should have sampled the same validators")
+}
+
 func TestSamplerDuplicate(t *testing.T) {
 	vdr0 := ids.GenerateTestShortID()
 	vdr1 := ids.GenerateTestShortID()
@@ -0,0 +1,122 @@
+// (c) 2019-2020, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package validators
+
+import (
+	"github.com/ava-labs/avalanchego/ids"
+	"github.com/ava-labs/avalanchego/utils/constants"
+	"github.com/ava-labs/avalanchego/utils/wrappers"
+)
+
+// TestNodeID returns the [index]th node ID generated from [seed]. Unlike
+// ids.GenerateTestShortID, the result doesn't depend on which tests have
+// already run. It should only be used for testing.
+func TestNodeID(seed, index uint64) ids.ShortID {
+	id := ids.Empty.Prefix(seed, index)
+	nodeID, _ := ids.ToShortID(id[:20])
+	return nodeID
+}
+
+// TestSetBuilder builds reproducible validator sets for testing. Validators are
+// given node IDs generated from the builder's seed, in the order they're added,
+// so the same calls to the same builder always produce the same validators.
+//
+// Validators are added to the primary network unless another subnet is
+// selected with Subnet.
+type TestSetBuilder struct {
+	seed, nextIndex uint64
+	subnetID        ids.ID
+	// subnetIDs is the order that subnets were first added to
+	subnetIDs []ids.ID
+	subnets   map[ids.ID][]Validator
+}
+
+// NewTestSetBuilder returns a builder whose node IDs are generated from [seed]
+func NewTestSetBuilder(seed uint64) *TestSetBuilder {
+	return &TestSetBuilder{
+		seed:     seed,
+		subnetID: constants.PrimaryNetworkID,
+		subnets:  make(map[ids.ID][]Validator),
+	}
+}
+
+// NodeID returns a new node ID that hasn't been returned by this builder
+func (b *TestSetBuilder) NodeID() ids.ShortID {
+	nodeID := TestNodeID(b.seed, b.nextIndex)
+	b.nextIndex++
+	return nodeID
+}
+
+// Subnet causes validators to be added to [subnetID] until Subnet is called
+// again
+func (b *TestSetBuilder) Subnet(subnetID ids.ID) *TestSetBuilder {
+	b.subnetID = subnetID
+	return b
+}
+
+// Add adds a new validator for each of [weights], with the given weights
+func (b *TestSetBuilder) Add(weights ...uint64) *TestSetBuilder {
+	for _, weight := range weights {
+		b.AddID(b.NodeID(), weight)
+	}
+	return b
+}
+
+// AddN adds [n] new validators that each have weight [weight]
+func (b *TestSetBuilder) AddN(n int, weight uint64) *TestSetBuilder {
+	for i := 0; i < n; i++ {
+		b.AddID(b.NodeID(), weight)
+	}
+	return b
+}
+
+// AddID adds a validator with the given node ID and weight. This can be used
+// to add a node that validates multiple subnets.
+func (b *TestSetBuilder) AddID(nodeID ids.ShortID, weight uint64) *TestSetBuilder {
+	if _, exists := b.subnets[b.subnetID]; !exists {
+		b.subnetIDs = append(b.subnetIDs, b.subnetID)
+	}
+	b.subnets[b.subnetID] = append(b.subnets[b.subnetID], NewValidator(nodeID, weight))
+	return b
+}
+
+// Validators returns the validators of [subnetID], in the order they were
+// added
+func (b *TestSetBuilder) Validators(subnetID ids.ID) []Validator {
+	return b.subnets[subnetID]
+}
+
+// NodeIDs returns the node IDs of the validators of [subnetID], in the order
+// they were added
+func (b *TestSetBuilder) NodeIDs(subnetID ids.ID) []ids.ShortID {
+	vdrs := b.subnets[subnetID]
+	nodeIDs := make([]ids.ShortID, len(vdrs))
+	for i, vdr := range vdrs {
+		nodeIDs[i] = vdr.ID()
+	}
+	return nodeIDs
+}
+
+// Set returns a new validator set containing the validators of [subnetID]
+func (b *TestSetBuilder) Set(subnetID ids.ID) (Set, error) {
+	s := NewSet()
+	errs := wrappers.Errs{}
+	for _, vdr := range b.subnets[subnetID] {
+		errs.Add(s.AddWeight(vdr.ID(), vdr.Weight()))
+	}
+	return s, errs.Err
+}
+
+// Manager returns a new validator manager containing the validators of every
+// subnet that validators were added to
+func (b *TestSetBuilder) Manager() (Manager, error) {
+	m := NewManager()
+	errs := wrappers.Errs{}
+	for _, subnetID := range b.subnetIDs {
+		for _, vdr := range b.subnets[subnetID] {
+			errs.Add(m.AddWeight(subnetID, vdr.ID(), vdr.Weight()))
+		}
+	}
+	return m, errs.Err
+}
@@ -0,0 +1,56 @@
+// (c) 2019-2020, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package validators
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/ava-labs/avalanchego/ids"
+	"github.com/ava-labs/avalanchego/utils/constants"
+)
+
+func TestTestSetBuilderDeterministic(t *testing.T) {
+	build := func(seed uint64) *TestSetBuilder {
+		return NewTestSetBuilder(seed).Add(1, 2).AddN(3, 4)
+	}
+
+	nodeIDs := build(0).NodeIDs(constants.PrimaryNetworkID)
+	assert.Equal(t, nodeIDs, build(0).NodeIDs(constants.PrimaryNetworkID))
+	assert.NotEqual(t, nodeIDs, build(1).NodeIDs(constants.PrimaryNetworkID))
+
+	nodeIDSet := ids.ShortSet{}
+	nodeIDSet.Add(nodeIDs...)
+	assert.Equal(t, 5, nodeIDSet.Len(), "should have generated unique node IDs")
+}
+
+func TestTestSetBuilderSubnets(t *testing.T) {
+	subnetID := ids.GenerateTestID()
+
+	b := NewTestSetBuilder(0).Add(10, 20)
+	sharedNodeID := b.NodeIDs(constants.PrimaryNetworkID)[0]
+	b.Subnet(subnetID).AddID(sharedNodeID, 5).Add(15)
+
+	primary, err := b.Set(constants.PrimaryNetworkID)
+	assert.NoError(t, err)
+	assert.Equal(t, 2, primary.Len())
+	assert.Equal(t, uint64(30), primary.Weight())
+
+	subnet, err := b.Set(subnetID)
+	assert.NoError(t, err)
+	assert.Equal(t, 2, subnet.Len())
+	assert.Equal(t, uint64(20), subnet.Weight())
+	weight, ok := subnet.GetWeight(sharedNodeID)
+	assert.True(t, ok)
+	assert.Equal(t, uint64(5), weight)
+
+	m, err := b.Manager()
+	assert.NoError(t, err)
+	for _, id := range []ids.ID{constants.PrimaryNetworkID, subnetID} {
+		vdrs, ok := m.GetValidators(id)
+		assert.True(t, ok)
+		assert.Equal(t, 2, vdrs.Len())
+	}
+}
@@ -59,7 +59,9 @@ func NewValidator(
 	}
 }
 
-// GenerateRandomValidator creates a random validator with the provided weight
+// GenerateRandomValidator creates a random validator with the provided weight.
+// Its node ID depends on the test IDs that have already been generated, so
+// TestSetBuilder should be used when reproducible validators are needed.
 func GenerateRandomValidator(weight uint64) Validator {
 	nodeID := ids.GenerateTestShortID()
 	return NewValidator(
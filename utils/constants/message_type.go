@@ -30,6 +30,9 @@ const (
 	GetAncestorsMsg
 	MultiPutMsg
 	GetAncestorsFailedMsg
+	GossipTxsMsg
+	CrossChainRequestMsg
+	CrossChainResponseMsg
 )
 
 func (t MsgType) String() string {
@@ -76,6 +79,12 @@ func (t MsgType) String() string {
 		return "Notify"
 	case GossipMsg:
 		return "Gossip"
+	case GossipTxsMsg:
+		return "Gossip Txs"
+	case CrossChainRequestMsg:
+		return "Cross Chain Request"
+	case CrossChainResponseMsg:
+		return "Cross Chain Response"
 	default:
 		return fmt.Sprintf("Unknown Message Type: %d", t)
 	}
@@ -0,0 +1,120 @@
+// (c) 2019-2020, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package hashing
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+
+	"golang.org/x/crypto/sha3"
+)
+
+const (
+	// SHA256Name is the name of the default hash function
+	SHA256Name = "sha256"
+
+	// SHA3Name is the name of the SHA-3 hash function
+	SHA3Name = "sha3"
+)
+
+var (
+	errDuplicateHasher = errors.New("duplicate hash function")
+	errUnknownHasher   = errors.New("unknown hash function")
+
+	// Default is the hash function used by chains that don't specify one. It
+	// computes the same hashes as ComputeHash256 and ComputeHash160.
+	Default Hasher = sha256Hasher{}
+
+	// SHA3 hashes with SHA3-256. Its 160 bit hash is the last 20 bytes of the
+	// SHA3-256 hash.
+	SHA3 Hasher = sha3Hasher{}
+
+	hashersLock sync.RWMutex
+	hashers     = map[string]Hasher{
+		SHA256Name: Default,
+		SHA3Name:   SHA3,
+	}
+)
+
+// Hasher computes the hashes a chain uses to identify its data. A chain must
+// use the same Hasher for its entire lifetime.
+type Hasher interface {
+	// Name this hash function is registered under
+	Name() string
+
+	// ComputeHash256Array Compute a 256 bit hash of the input byte slice.
+	ComputeHash256Array(buf []byte) Hash256
+
+	// ComputeHash256 Compute a 256 bit hash of the input byte slice.
+	ComputeHash256(buf []byte) []byte
+
+	// ComputeHash160Array Compute a 160 bit hash of the input byte slice.
+	ComputeHash160Array(buf []byte) Hash160
+
+	// ComputeHash160 Compute a 160 bit hash of the input byte slice.
+	ComputeHash160(buf []byte) []byte
+}
+
+// RegisterHasher makes [hasher] available to chains under [hasher.Name()].
+// Returns an error if a hash function is already registered under that name.
+func RegisterHasher(hasher Hasher) error {
+	hashersLock.Lock()
+	defer hashersLock.Unlock()
+
+	name := hasher.Name()
+	if _, exists := hashers[name]; exists {
+		return fmt.Errorf("%w: %q", errDuplicateHasher, name)
+	}
+	hashers[name] = hasher
+	return nil
+}
+
+// GetHasher returns the hash function registered under [name]. If [name] is
+// empty, Default is returned.
+func GetHasher(name string) (Hasher, error) {
+	if name == "" {
+		return Default, nil
+	}
+
+	hashersLock.RLock()
+	defer hashersLock.RUnlock()
+
+	hasher, exists := hashers[name]
+	if !exists {
+		return nil, fmt.Errorf("%w: %q", errUnknownHasher, name)
+	}
+	return hasher, nil
+}
+
+type sha256Hasher struct{}
+
+func (sha256Hasher) Name() string                           { return SHA256Name }
+func (sha256Hasher) ComputeHash256Array(buf []byte) Hash256 { return ComputeHash256Array(buf) }
+func (sha256Hasher) ComputeHash256(buf []byte) []byte       { return ComputeHash256(buf) }
+func (sha256Hasher) ComputeHash160Array(buf []byte) Hash160 { return ComputeHash160Array(buf) }
+func (sha256Hasher) ComputeHash160(buf []byte) []byte       { return ComputeHash160(buf) }
+
+type sha3Hasher struct{}
+
+func (sha3Hasher) Name() string { return SHA3Name }
+
+func (sha3Hasher) ComputeHash256Array(buf []byte) Hash256 { return sha3.Sum256(buf) }
+
+func (h sha3Hasher) ComputeHash256(buf []byte) []byte {
+	arr := h.ComputeHash256Array(buf)
+	return arr[:]
+}
+
+func (h sha3Hasher) ComputeHash160Array(buf []byte) Hash160 {
+	hash := h.ComputeHash256Array(buf)
+	hash160 := Hash160{}
+	copy(hash160[:], hash[HashLen-AddrLen:])
+	return hash160
+}
+
+func (h sha3Hasher) ComputeHash160(buf []byte) []byte {
+	arr := h.ComputeHash160Array(buf)
+	return arr[:]
+}
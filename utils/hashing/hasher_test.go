@@ -0,0 +1,56 @@
+// (c) 2019-2020, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package hashing
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDefaultHasherMatchesComputeHash(t *testing.T) {
+	buf := []byte("avalanche")
+
+	assert.Equal(t, ComputeHash256(buf), Default.ComputeHash256(buf))
+	assert.Equal(t, ComputeHash256Array(buf), Default.ComputeHash256Array(buf))
+	assert.Equal(t, ComputeHash160(buf), Default.ComputeHash160(buf))
+	assert.Equal(t, ComputeHash160Array(buf), Default.ComputeHash160Array(buf))
+}
+
+func TestHashersDisagree(t *testing.T) {
+	buf := []byte("avalanche")
+
+	// A container hashed by one chain must never be mistaken for a container
+	// hashed by a chain using another hash function.
+	assert.NotEqual(t, Default.ComputeHash256(buf), SHA3.ComputeHash256(buf))
+	assert.NotEqual(t, Default.ComputeHash160(buf), SHA3.ComputeHash160(buf))
+
+	assert.Len(t, SHA3.ComputeHash256(buf), HashLen)
+	assert.Len(t, SHA3.ComputeHash160(buf), AddrLen)
+
+	hash256 := SHA3.ComputeHash256Array(buf)
+	hash160 := SHA3.ComputeHash160Array(buf)
+	assert.Equal(t, hash256[HashLen-AddrLen:], hash160[:])
+}
+
+func TestGetHasher(t *testing.T) {
+	hasher, err := GetHasher("")
+	assert.NoError(t, err)
+	assert.Equal(t, Default, hasher)
+
+	hasher, err = GetHasher(SHA256Name)
+	assert.NoError(t, err)
+	assert.Equal(t, Default, hasher)
+
+	hasher, err = GetHasher(SHA3Name)
+	assert.NoError(t, err)
+	assert.Equal(t, SHA3, hasher)
+
+	_, err = GetHasher("md5")
+	assert.Error(t, err)
+}
+
+func TestRegisterHasherRejectsDuplicates(t *testing.T) {
+	assert.Error(t, RegisterHasher(SHA3))
+}
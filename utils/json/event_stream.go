@@ -0,0 +1,213 @@
+// (c) 2019-2020, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package json
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+const (
+	// Number of recently published events kept so that event streams can
+	// resume from the last event they received
+	eventHistorySize = 1024
+
+	// Header that an event stream client sends, when it reconnects, with the
+	// ID of the last event that it received
+	lastEventIDHeader = "Last-Event-ID"
+)
+
+// event is a message that was published to a channel
+type event struct {
+	id      uint64
+	channel string
+	value   interface{}
+}
+
+// eventStream is a server-sent events client
+type eventStream struct {
+	// channels the client is streaming. Never empty.
+	channels map[string]struct{}
+	// if non-empty, only events whose value is formatted as one of these are
+	// streamed
+	values map[string]struct{}
+
+	// Buffered channel of outbound events. Closed if the client falls too far
+	// behind, in which case it's expected to reconnect and resume.
+	send chan *event
+}
+
+func (e *eventStream) matches(ev *event) bool {
+	if _, ok := e.channels[ev.channel]; !ok {
+		return false
+	}
+	if len(e.values) == 0 {
+		return true
+	}
+	_, ok := e.values[fmt.Sprint(ev.value)]
+	return ok
+}
+
+// EventStream returns a handler that streams the messages published to this
+// server as server-sent events, for clients that can't use websockets.
+//
+// The streamed channels can be filtered with repeated [channel] query
+// parameters, and the streamed values with repeated [value] query parameters.
+// By default, every channel and value is streamed. Each event has the ID of
+// the message, so a client that reconnects with the Last-Event-ID header, or
+// the [lastEventID] query parameter, is sent the recent messages it missed.
+func (s *PubSubServer) EventStream() http.Handler {
+	return http.HandlerFunc(s.serveEventStream)
+}
+
+func (s *PubSubServer) serveEventStream(w http.ResponseWriter, r *http.Request) {
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		http.Error(w, "streaming isn't supported", http.StatusInternalServerError)
+		return
+	}
+
+	query := r.URL.Query()
+	stream := &eventStream{
+		channels: make(map[string]struct{}),
+		values:   make(map[string]struct{}),
+		send:     make(chan *event, maxPendingMessages),
+	}
+	for _, value := range query["value"] {
+		stream.values[value] = struct{}{}
+	}
+
+	lastEventIDStr := r.Header.Get(lastEventIDHeader)
+	if lastEventIDStr == "" {
+		lastEventIDStr = query.Get("lastEventID")
+	}
+	lastEventID := uint64(0)
+	if lastEventIDStr != "" {
+		var err error
+		lastEventID, err = strconv.ParseUint(lastEventIDStr, 10, 64)
+		if err != nil {
+			http.Error(w, fmt.Sprintf("invalid last event ID %q", lastEventIDStr), http.StatusBadRequest)
+			return
+		}
+	}
+
+	missed, err := s.addEventStream(stream, query["channel"], lastEventID)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	defer s.removeEventStream(stream)
+
+	header := w.Header()
+	header.Set("Content-Type", "text/event-stream")
+	header.Set("Cache-Control", "no-cache")
+	header.Set("Connection", "keep-alive")
+	w.WriteHeader(http.StatusOK)
+
+	for _, ev := range missed {
+		if err := writeEvent(w, ev); err != nil {
+			return
+		}
+	}
+	flusher.Flush()
+
+	ticker := time.NewTicker(pingPeriod)
+	defer ticker.Stop()
+	for {
+		select {
+		case ev, ok := <-stream.send:
+			if !ok {
+				// The client fell too far behind
+				return
+			}
+			if err := writeEvent(w, ev); err != nil {
+				return
+			}
+		case <-ticker.C:
+			// Comments keep idle connections from being closed by proxies
+			if _, err := fmt.Fprint(w, ": ping\n\n"); err != nil {
+				return
+			}
+		case <-r.Context().Done():
+			return
+		}
+		flusher.Flush()
+	}
+}
+
+// addEventStream starts streaming [channels] to [stream]. If [channels] is
+// empty, every channel is streamed. Returns the retained events after
+// [lastEventID] that [stream] should be sent first.
+func (s *PubSubServer) addEventStream(stream *eventStream, channels []string, lastEventID uint64) ([]*event, error) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	for _, channel := range channels {
+		if _, exists := s.channels[channel]; !exists {
+			return nil, fmt.Errorf("unknown channel %q", channel)
+		}
+		stream.channels[channel] = struct{}{}
+	}
+	if len(stream.channels) == 0 {
+		for channel := range s.channels {
+			stream.channels[channel] = struct{}{}
+		}
+	}
+
+	var missed []*event
+	if lastEventID != 0 {
+		for _, ev := range s.history {
+			if ev.id > lastEventID && stream.matches(ev) {
+				missed = append(missed, ev)
+			}
+		}
+	}
+	s.streams[stream] = struct{}{}
+	return missed, nil
+}
+
+func (s *PubSubServer) removeEventStream(stream *eventStream) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	delete(s.streams, stream)
+}
+
+// publishEvent records [ev] and sends it to the matching event streams.
+// Assumes [s.lock] is held.
+func (s *PubSubServer) publishEvent(ev *event) {
+	if len(s.history) == eventHistorySize {
+		copy(s.history, s.history[1:])
+		s.history = s.history[:eventHistorySize-1]
+	}
+	s.history = append(s.history, ev)
+
+	for stream := range s.streams {
+		if !stream.matches(ev) {
+			continue
+		}
+		select {
+		case stream.send <- ev:
+		default:
+			// Rather than silently skipping this event, end the stream so
+			// that the client reconnects and resumes from the last event it
+			// received
+			s.ctx.Log.Verbo("ending event stream due to too many pending messages")
+			close(stream.send)
+			delete(s.streams, stream)
+		}
+	}
+}
+
+func writeEvent(w http.ResponseWriter, ev *event) error {
+	data, err := json.Marshal(ev.value)
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintf(w, "id: %d\nevent: %s\ndata: %s\n\n", ev.id, ev.channel, data)
+	return err
+}
@@ -0,0 +1,143 @@
+// (c) 2019-2020, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package json
+
+import (
+	"bufio"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/ava-labs/avalanchego/snow"
+)
+
+// readEvent returns the next event, without its trailing blank line, read from
+// [r]
+func readEvent(t *testing.T, r *bufio.Reader) string {
+	lines := []string(nil)
+	for {
+		line, err := r.ReadString('\n')
+		if !assert.NoError(t, err) {
+			return ""
+		}
+		if line == "\n" {
+			return strings.Join(lines, "")
+		}
+		lines = append(lines, line)
+	}
+}
+
+func newTestEventStreamServer(t *testing.T) (*PubSubServer, *httptest.Server) {
+	s := NewPubSubServer(snow.DefaultContextTest())
+	assert.NoError(t, s.Register("accepted"))
+	assert.NoError(t, s.Register("rejected"))
+	return s, httptest.NewServer(s.EventStream())
+}
+
+// waitForStreams waits until [s] has [n] event streams
+func waitForStreams(s *PubSubServer, n int) {
+	for {
+		s.lock.Lock()
+		numStreams := len(s.streams)
+		s.lock.Unlock()
+		if numStreams == n {
+			return
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+func TestEventStreamFilters(t *testing.T) {
+	s, server := newTestEventStreamServer(t)
+	defer server.Close()
+
+	resp, err := http.Get(server.URL + "?channel=accepted&value=b&value=c")
+	assert.NoError(t, err)
+	defer resp.Body.Close()
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, "text/event-stream", resp.Header.Get("Content-Type"))
+	waitForStreams(s, 1)
+
+	s.Publish("accepted", "a")
+	s.Publish("rejected", "b")
+	s.Publish("accepted", "b")
+	s.Publish("accepted", "c")
+
+	r := bufio.NewReader(resp.Body)
+	assert.Equal(t, "id: 3\nevent: accepted\ndata: \"b\"\n", readEvent(t, r))
+	assert.Equal(t, "id: 4\nevent: accepted\ndata: \"c\"\n", readEvent(t, r))
+}
+
+func TestEventStreamResumes(t *testing.T) {
+	s, server := newTestEventStreamServer(t)
+	defer server.Close()
+
+	s.Publish("accepted", "a")
+	s.Publish("rejected", "b")
+	s.Publish("accepted", "c")
+
+	req, err := http.NewRequest(http.MethodGet, server.URL, nil)
+	assert.NoError(t, err)
+	req.Header.Set(lastEventIDHeader, "1")
+	resp, err := http.DefaultClient.Do(req)
+	assert.NoError(t, err)
+	defer resp.Body.Close()
+
+	r := bufio.NewReader(resp.Body)
+	assert.Equal(t, "id: 2\nevent: rejected\ndata: \"b\"\n", readEvent(t, r))
+	assert.Equal(t, "id: 3\nevent: accepted\ndata: \"c\"\n", readEvent(t, r))
+
+	s.Publish("rejected", "d")
+	assert.Equal(t, "id: 4\nevent: rejected\ndata: \"d\"\n", readEvent(t, r))
+}
+
+func TestEventStreamInvalidRequests(t *testing.T) {
+	_, server := newTestEventStreamServer(t)
+	defer server.Close()
+
+	for _, query := range []string{"?channel=unknown", "?lastEventID=abc"} {
+		resp, err := http.Get(server.URL + query)
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusBadRequest, resp.StatusCode, query)
+		assert.NoError(t, resp.Body.Close())
+	}
+}
+
+func TestEventStreamHistoryIsBounded(t *testing.T) {
+	s := NewPubSubServer(snow.DefaultContextTest())
+	assert.NoError(t, s.Register("accepted"))
+
+	for i := 0; i < eventHistorySize+10; i++ {
+		s.Publish("accepted", i)
+	}
+	assert.Len(t, s.history, eventHistorySize)
+	assert.Equal(t, uint64(11), s.history[0].id)
+	assert.Equal(t, uint64(eventHistorySize+10), s.history[eventHistorySize-1].id)
+}
+
+func TestEventStreamEndsWhenBehind(t *testing.T) {
+	s := NewPubSubServer(snow.DefaultContextTest())
+	assert.NoError(t, s.Register("accepted"))
+
+	stream := &eventStream{
+		channels: make(map[string]struct{}),
+		send:     make(chan *event, 1),
+	}
+	_, err := s.addEventStream(stream, nil, 0)
+	assert.NoError(t, err)
+
+	s.Publish("accepted", "a")
+	s.Publish("accepted", "b")
+
+	ev, ok := <-stream.send
+	assert.True(t, ok)
+	assert.Equal(t, "a", ev.value)
+	_, ok = <-stream.send
+	assert.False(t, ok)
+	assert.Empty(t, s.streams)
+}
@@ -54,6 +54,13 @@ type PubSubServer struct {
 	lock     sync.Mutex
 	conns    map[*Connection]map[string]struct{}
 	channels map[string]map[*Connection]struct{}
+
+	// ID of the most recently published message
+	lastEventID uint64
+	// most recently published messages, oldest first
+	history []*event
+	// server-sent events clients
+	streams map[*eventStream]struct{}
 }
 
 // NewPubSubServer ...
@@ -62,6 +69,7 @@ func NewPubSubServer(ctx *snow.Context) *PubSubServer {
 		ctx:      ctx,
 		conns:    make(map[*Connection]map[string]struct{}),
 		channels: make(map[string]map[*Connection]struct{}),
+		streams:  make(map[*eventStream]struct{}),
 	}
 }
 
@@ -98,6 +106,13 @@ func (s *PubSubServer) Publish(channel string, msg interface{}) {
 			s.ctx.Log.Verbo("dropping message to subscribed connection due to too many pending messages")
 		}
 	}
+
+	s.lastEventID++
+	s.publishEvent(&event{
+		id:      s.lastEventID,
+		channel: channel,
+		value:   msg,
+	})
 }
 
 // Register ...
@@ -0,0 +1,162 @@
+// (c) 2019-2020, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package lifecycle
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/ava-labs/avalanchego/utils/logging"
+	"github.com/ava-labs/avalanchego/utils/wrappers"
+)
+
+var (
+	errDuplicateComponent = errors.New("duplicate component")
+	errUnknownDependency  = errors.New("unknown dependency")
+	errAlreadyShutdown    = errors.New("already shut down")
+	errShutdownTimeout    = errors.New("timed out while shutting down")
+)
+
+type component struct {
+	name     string
+	timeout  time.Duration
+	shutdown func() error
+	// Components that must be shut down before this one
+	after []*component
+	// Closed once this component has shut down, or timed out doing so
+	done chan struct{}
+}
+
+// Manager shuts down the components of the node in dependency order. Each
+// component is given a deadline to shut down, after which the components that
+// depend on it are shut down regardless, so that a stuck component can't
+// prevent the node from exiting. Once every component has shut down, the
+// registered flushes are run.
+type Manager struct {
+	log logging.Logger
+
+	lock       sync.Mutex
+	components []*component
+	byName     map[string]*component
+	flushes    []func()
+	shutdown   bool
+}
+
+// NewManager returns a manager with no components
+func NewManager(log logging.Logger) *Manager {
+	return &Manager{
+		log:    log,
+		byName: make(map[string]*component),
+	}
+}
+
+// Register [shutdown] to be called, with a deadline of [timeout], once every
+// component named in [after] has shut down. The components in [after] must
+// already be registered, which ensures that the dependencies are acyclic.
+func (m *Manager) Register(name string, timeout time.Duration, shutdown func() error, after ...string) error {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	if m.shutdown {
+		return errAlreadyShutdown
+	}
+	if _, exists := m.byName[name]; exists {
+		return fmt.Errorf("%w: %s", errDuplicateComponent, name)
+	}
+
+	c := &component{
+		name:     name,
+		timeout:  timeout,
+		shutdown: shutdown,
+		after:    make([]*component, len(after)),
+		done:     make(chan struct{}),
+	}
+	for i, dependency := range after {
+		dep, exists := m.byName[dependency]
+		if !exists {
+			return fmt.Errorf("%w: %s depends on %s", errUnknownDependency, name, dependency)
+		}
+		c.after[i] = dep
+	}
+	m.components = append(m.components, c)
+	m.byName[name] = c
+	return nil
+}
+
+// OnFlush registers [flush] to be called after every component has shut
+// down. Flushes are called in the order they were registered.
+func (m *Manager) OnFlush(flush func()) {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	m.flushes = append(m.flushes, flush)
+}
+
+// Shutdown the registered components and then run the flushes. Returns the
+// first error reported by a component. Only the first call has any effect.
+func (m *Manager) Shutdown() error {
+	m.lock.Lock()
+	if m.shutdown {
+		m.lock.Unlock()
+		return nil
+	}
+	m.shutdown = true
+	components := m.components
+	flushes := m.flushes
+	m.lock.Unlock()
+
+	var (
+		errsLock sync.Mutex
+		errs     wrappers.Errs
+		wg       sync.WaitGroup
+	)
+	wg.Add(len(components))
+	for _, c := range components {
+		go func(c *component) {
+			defer wg.Done()
+			defer close(c.done)
+
+			for _, dep := range c.after {
+				<-dep.done
+			}
+
+			if err := m.shutdownComponent(c); err != nil {
+				m.log.Warn("failed to shut down %s: %s", c.name, err)
+
+				errsLock.Lock()
+				errs.Add(fmt.Errorf("couldn't shut down %s: %w", c.name, err))
+				errsLock.Unlock()
+			}
+		}(c)
+	}
+	wg.Wait()
+
+	for _, flush := range flushes {
+		flush()
+	}
+	return errs.Err
+}
+
+// shutdownComponent calls [c.shutdown], returning early if it doesn't return
+// within [c.timeout]
+func (m *Manager) shutdownComponent(c *component) error {
+	m.log.Debug("shutting down %s", c.name)
+
+	result := make(chan error, 1)
+	go func() {
+		result <- c.shutdown()
+	}()
+
+	timer := time.NewTimer(c.timeout)
+	defer timer.Stop()
+
+	select {
+	case err := <-result:
+		return err
+	case <-timer.C:
+		return fmt.Errorf("%w after %s", errShutdownTimeout, c.timeout)
+	}
+}
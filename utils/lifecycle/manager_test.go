@@ -0,0 +1,90 @@
+// (c) 2019-2020, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package lifecycle
+
+import (
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/ava-labs/avalanchego/utils/logging"
+)
+
+func TestManagerShutsDownInDependencyOrder(t *testing.T) {
+	m := NewManager(logging.NoLog{})
+
+	var (
+		lock  sync.Mutex
+		order []string
+	)
+	shutdown := func(name string) func() error {
+		return func() error {
+			lock.Lock()
+			defer lock.Unlock()
+
+			order = append(order, name)
+			return nil
+		}
+	}
+
+	assert.NoError(t, m.Register("api", time.Second, shutdown("api")))
+	assert.NoError(t, m.Register("router", time.Second, shutdown("router"), "api"))
+	assert.NoError(t, m.Register("network", time.Second, shutdown("network"), "router"))
+	assert.NoError(t, m.Register("database", time.Second, shutdown("database"), "network", "api"))
+	m.OnFlush(func() { order = append(order, "flush") })
+
+	assert.NoError(t, m.Shutdown())
+	assert.Equal(t, []string{"api", "router", "network", "database", "flush"}, order)
+
+	// Only the first shutdown has any effect
+	assert.NoError(t, m.Shutdown())
+	assert.Len(t, order, 5)
+}
+
+func TestManagerRegisterErrors(t *testing.T) {
+	m := NewManager(logging.NoLog{})
+	noop := func() error { return nil }
+
+	assert.NoError(t, m.Register("api", time.Second, noop))
+
+	err := m.Register("api", time.Second, noop)
+	assert.True(t, errors.Is(err, errDuplicateComponent))
+
+	// Dependencies must be registered first, so cycles are impossible
+	err = m.Register("router", time.Second, noop, "network")
+	assert.True(t, errors.Is(err, errUnknownDependency))
+
+	assert.NoError(t, m.Shutdown())
+
+	err = m.Register("network", time.Second, noop)
+	assert.Equal(t, errAlreadyShutdown, err)
+}
+
+func TestManagerShutdownDeadline(t *testing.T) {
+	m := NewManager(logging.NoLog{})
+
+	stuck := make(chan struct{})
+	defer close(stuck)
+
+	errFailed := errors.New("failed")
+	networkShutdown := false
+	assert.NoError(t, m.Register("router", 10*time.Millisecond, func() error {
+		<-stuck
+		return nil
+	}))
+	assert.NoError(t, m.Register("engines", time.Second, func() error { return errFailed }))
+	assert.NoError(t, m.Register("network", time.Second, func() error {
+		networkShutdown = true
+		return nil
+	}, "router", "engines"))
+
+	// A stuck or failing component doesn't prevent its dependents from
+	// shutting down
+	err := m.Shutdown()
+	assert.Error(t, err)
+	assert.True(t, networkShutdown)
+}
@@ -3,7 +3,10 @@
 
 package logging
 
-import "path/filepath"
+import (
+	"path/filepath"
+	"sync"
+)
 
 // Factory ...
 type Factory interface {
@@ -17,6 +20,8 @@ type Factory interface {
 type factory struct {
 	config Config
 
+	// Chains may be created concurrently, so [loggers] is guarded by [lock]
+	lock    sync.Mutex
 	loggers []Logger
 }
 
@@ -31,7 +36,7 @@ func NewFactory(config Config) Factory {
 func (f *factory) Make() (Logger, error) {
 	l, err := New(f.config)
 	if err == nil {
-		f.loggers = append(f.loggers, l)
+		f.add(l)
 	}
 	return l, err
 }
@@ -44,7 +49,7 @@ func (f *factory) MakeChain(chainID string, subdir string) (Logger, error) {
 
 	log, err := New(config)
 	if err == nil {
-		f.loggers = append(f.loggers, log)
+		f.add(log)
 	}
 	return log, err
 }
@@ -56,15 +61,25 @@ func (f *factory) MakeSubdir(subdir string) (Logger, error) {
 
 	log, err := New(config)
 	if err == nil {
-		f.loggers = append(f.loggers, log)
+		f.add(log)
 	}
 	return log, err
 }
 
 // Close ...
 func (f *factory) Close() {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+
 	for _, log := range f.loggers {
 		log.Stop()
 	}
 	f.loggers = nil
 }
+
+func (f *factory) add(log Logger) {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+
+	f.loggers = append(f.loggers, log)
+}
@@ -9,7 +9,13 @@ import (
 )
 
 var (
-	errOverflow = errors.New("overflow occurred")
+	// ErrOverflow is returned when the result of an operation is larger than
+	// the maximum value of its type
+	ErrOverflow = errors.New("overflow occurred")
+
+	// ErrUnderflow is returned when the result of an operation is smaller than
+	// the minimum value of its type
+	ErrUnderflow = errors.New("underflow occurred")
 )
 
 // Max64 ...
@@ -28,32 +34,60 @@ func Min64(a, b uint64) uint64 {
 	return b
 }
 
-// Add64 ...
+// Add64 returns:
+// 1) a + b
+// 2) If there is overflow, ErrOverflow
 func Add64(a, b uint64) (uint64, error) {
 	if a > math.MaxUint64-b {
-		return 0, errOverflow
+		return 0, ErrOverflow
 	}
 	return a + b, nil
 }
 
 // Sub64 returns:
 // 1) a - b
-// 2) If there is underflow, an error
+// 2) If there is underflow, ErrUnderflow
 func Sub64(a, b uint64) (uint64, error) {
 	if a < b {
-		return 0, errOverflow
+		return 0, ErrUnderflow
 	}
 	return a - b, nil
 }
 
-// Mul64 ...
+// Mul64 returns:
+// 1) a * b
+// 2) If there is overflow, ErrOverflow
 func Mul64(a, b uint64) (uint64, error) {
 	if b != 0 && a > math.MaxUint64/b {
-		return 0, errOverflow
+		return 0, ErrOverflow
 	}
 	return a * b, nil
 }
 
+// SaturatingAdd64 returns a + b, or math.MaxUint64 if there is overflow
+func SaturatingAdd64(a, b uint64) uint64 {
+	if a > math.MaxUint64-b {
+		return math.MaxUint64
+	}
+	return a + b
+}
+
+// SaturatingSub64 returns a - b, or 0 if there is underflow
+func SaturatingSub64(a, b uint64) uint64 {
+	if a < b {
+		return 0
+	}
+	return a - b
+}
+
+// SaturatingMul64 returns a * b, or math.MaxUint64 if there is overflow
+func SaturatingMul64(a, b uint64) uint64 {
+	if b != 0 && a > math.MaxUint64/b {
+		return math.MaxUint64
+	}
+	return a * b
+}
+
 // Diff64 ...
 func Diff64(a, b uint64) uint64 {
 	return Max64(a, b) - Min64(a, b)
@@ -68,8 +68,8 @@ func TestAdd64(t *testing.T) {
 	}
 
 	_, err = Add64(maxUint64, maxUint64)
-	if err == nil {
-		t.Fatalf("Add64 succeeded unexpectedly")
+	if err != ErrOverflow {
+		t.Fatalf("Expected %s, got %v", ErrOverflow, err)
 	}
 }
 
@@ -82,8 +82,8 @@ func TestSub64(t *testing.T) {
 	}
 
 	_, err = Sub64(1, 2)
-	if err == nil {
-		t.Fatalf("Sub64 did not fail in the manner expected")
+	if err != ErrUnderflow {
+		t.Fatalf("Expected %s, got %v", ErrUnderflow, err)
 	}
 }
 
@@ -100,8 +100,38 @@ func TestMul64(t *testing.T) {
 		t.Fatalf("Mul64 returned wrong value")
 	}
 
-	if _, err := Mul64(maxUint64-1, 2); err == nil {
-		t.Fatalf("Mul64 overflowed")
+	if _, err := Mul64(maxUint64-1, 2); err != ErrOverflow {
+		t.Fatalf("Expected %s, got %v", ErrOverflow, err)
+	}
+}
+
+func TestSaturatingAdd64(t *testing.T) {
+	if sum := SaturatingAdd64(1, 2); sum != 3 {
+		t.Fatalf("Expected %d, got %d", 3, sum)
+	}
+	if sum := SaturatingAdd64(maxUint64, 1); sum != maxUint64 {
+		t.Fatalf("Expected %d, got %d", maxUint64, sum)
+	}
+}
+
+func TestSaturatingSub64(t *testing.T) {
+	if diff := SaturatingSub64(2, 1); diff != 1 {
+		t.Fatalf("Expected %d, got %d", 1, diff)
+	}
+	if diff := SaturatingSub64(1, 2); diff != 0 {
+		t.Fatalf("Expected %d, got %d", 0, diff)
+	}
+}
+
+func TestSaturatingMul64(t *testing.T) {
+	if prod := SaturatingMul64(maxUint64, 0); prod != 0 {
+		t.Fatalf("Expected %d, got %d", 0, prod)
+	}
+	if prod := SaturatingMul64(2, 3); prod != 6 {
+		t.Fatalf("Expected %d, got %d", 6, prod)
+	}
+	if prod := SaturatingMul64(maxUint64-1, 2); prod != maxUint64 {
+		t.Fatalf("Expected %d, got %d", maxUint64, prod)
 	}
 }
 
@@ -5,6 +5,7 @@ package rpc
 
 import (
 	"bytes"
+	"errors"
 	"fmt"
 	"net/http"
 	"strings"
@@ -13,6 +14,10 @@ import (
 	rpc "github.com/gorilla/rpc/v2/json2"
 )
 
+// ErrMethodNotSupported is returned when a request is made for a method that
+// the node doesn't serve, such as a method added in a later version
+var ErrMethodNotSupported = errors.New("method not supported")
+
 // Requester ...
 type Requester interface {
 	SendJSONRPCRequest(endpoint string, method string, params interface{}, reply interface{}) error
@@ -59,6 +64,11 @@ func (requester jsonRPCRequester) SendJSONRPCRequest(endpoint string, method str
 	}
 
 	if err := rpc.DecodeClientResponse(resp.Body, reply); err != nil {
+		// Drop any error during close to report the original error
+		_ = resp.Body.Close()
+		if isMethodNotFound(err) {
+			return fmt.Errorf("%w: %s", ErrMethodNotSupported, method)
+		}
 		return err
 	}
 	return resp.Body.Close()
@@ -91,3 +101,17 @@ func (e *avalancheEndpointRequester) SendRequest(method string, params interface
 		reply,
 	)
 }
+
+// isMethodNotFound returns true if [err] was returned by a server that doesn't
+// have the requested service or method
+func isMethodNotFound(err error) bool {
+	rpcErr, ok := err.(*rpc.Error)
+	if !ok {
+		return false
+	}
+	if rpcErr.Code == rpc.E_NO_METHOD {
+		return true
+	}
+	return strings.HasPrefix(rpcErr.Message, "rpc: can't find service") ||
+		strings.HasPrefix(rpcErr.Message, "rpc: can't find method")
+}
@@ -0,0 +1,31 @@
+// (c) 2019-2020, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package sampler
+
+import (
+	"math/rand"
+)
+
+// Source is a source of randomness that samples may be drawn from, so that
+// the samples are reproducible from the state of the source. *rand.Rand is a
+// Source.
+type Source interface {
+	// Int63n returns a pseudo-random number in [0, n)
+	Int63n(n int64) int64
+}
+
+// globalSource draws from the global source of math/rand
+type globalSource struct{}
+
+// We don't use a cryptographically secure source of randomness here, as
+// there's no need to ensure a truly random sampling.
+func (globalSource) Int63n(n int64) int64 { return rand.Int63n(n) } // #nosec G404
+
+// sourceOrGlobal returns [source], or the global source if [source] is nil
+func sourceOrGlobal(source Source) Source {
+	if source == nil {
+		return globalSource{}
+	}
+	return source
+}
@@ -7,6 +7,9 @@ package sampler
 type Uniform interface {
 	Initialize(sampleRange uint64) error
 	Sample(length int) ([]uint64, error)
+	// SampleWithSource is Sample, drawing randomness from [source] rather than
+	// the global source. If [source] is nil, the global source is used.
+	SampleWithSource(length int, source Source) ([]uint64, error)
 }
 
 // NewUniform returns a new sampler
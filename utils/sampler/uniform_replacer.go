@@ -45,6 +45,10 @@ func (s *uniformReplacer) Initialize(length uint64) error {
 }
 
 func (s *uniformReplacer) Sample(count int) ([]uint64, error) {
+	return s.SampleWithSource(count, nil)
+}
+
+func (s *uniformReplacer) SampleWithSource(count int, source Source) ([]uint64, error) {
 	if count < 0 || s.length < uint64(count) {
 		return nil, errOutOfRange
 	}
@@ -57,11 +61,10 @@ func (s *uniformReplacer) Sample(count int) ([]uint64, error) {
 		}
 	}
 
+	source = sourceOrGlobal(source)
 	results := make([]uint64, count)
 	for i := 0; i < count; i++ {
-		// We don't use a cryptographically secure source of randomness here, as
-		// there's no need to ensure a truly random sampling.
-		draw := uint64(rand.Int63n(int64(s.length-uint64(i)))) + uint64(i) // #nosec G404
+		draw := uint64(source.Int63n(int64(s.length-uint64(i)))) + uint64(i)
 
 		ret := s.drawn.get(draw, draw)
 		s.drawn[draw] = s.drawn.get(uint64(i), uint64(i))
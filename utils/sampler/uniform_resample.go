@@ -34,6 +34,10 @@ func (s *uniformResample) Initialize(length uint64) error {
 }
 
 func (s *uniformResample) Sample(count int) ([]uint64, error) {
+	return s.SampleWithSource(count, nil)
+}
+
+func (s *uniformResample) SampleWithSource(count int, source Source) ([]uint64, error) {
 	if count < 0 || s.length < uint64(count) {
 		return nil, errOutOfRange
 	}
@@ -46,11 +50,10 @@ func (s *uniformResample) Sample(count int) ([]uint64, error) {
 		}
 	}
 
+	source = sourceOrGlobal(source)
 	results := make([]uint64, count)
 	for i := 0; i < count; {
-		// We don't use a cryptographically secure source of randomness here, as
-		// there's no need to ensure a truly random sampling.
-		draw := uint64(rand.Int63n(int64(s.length))) // #nosec G404
+		draw := uint64(source.Int63n(int64(s.length)))
 		if _, ok := s.drawn[draw]; ok {
 			continue
 		}
@@ -6,6 +6,7 @@ package sampler
 import (
 	"fmt"
 	"math"
+	"math/rand"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -59,6 +60,10 @@ var (
 			name: "over sample",
 			test: UniformOverSampleTest,
 		},
+		{
+			name: "source",
+			test: UniformSourceTest,
+		},
 	}
 )
 
@@ -126,3 +131,14 @@ func UniformOverSampleTest(t *testing.T, s Uniform) {
 	_, err = s.Sample(4)
 	assert.Error(t, err, "should have returned an out of range error")
 }
+
+func UniformSourceTest(t *testing.T, s Uniform) {
+	err := s.Initialize(1000)
+	assert.NoError(t, err)
+
+	expected, err := s.SampleWithSource(10, rand.New(rand.NewSource(1)))
+	assert.NoError(t, err)
+	val, err := s.SampleWithSource(10, rand.New(rand.NewSource(1)))
+	assert.NoError(t, err)
+	assert.Equal(t, expected, val, "sources in the same state should have produced the same sample")
+}
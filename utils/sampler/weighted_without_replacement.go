@@ -9,6 +9,9 @@ package sampler
 type WeightedWithoutReplacement interface {
 	Initialize(weights []uint64) error
 	Sample(count int) ([]int, error)
+	// SampleWithSource is Sample, drawing randomness from [source] rather than
+	// the global source. If [source] is nil, the global source is used.
+	SampleWithSource(count int, source Source) ([]int, error)
 }
 
 // NewWeightedWithoutReplacement returns a new sampler
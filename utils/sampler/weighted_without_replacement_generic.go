@@ -28,7 +28,11 @@ func (s *weightedWithoutReplacementGeneric) Initialize(weights []uint64) error {
 }
 
 func (s *weightedWithoutReplacementGeneric) Sample(count int) ([]int, error) {
-	weights, err := s.u.Sample(count)
+	return s.SampleWithSource(count, nil)
+}
+
+func (s *weightedWithoutReplacementGeneric) SampleWithSource(count int, source Source) ([]int, error) {
+	weights, err := s.u.SampleWithSource(count, source)
 	if err != nil {
 		return nil, err
 	}
@@ -5,6 +5,7 @@ package wrappers
 
 import (
 	"bytes"
+	"math"
 	"reflect"
 	"testing"
 )
@@ -581,3 +582,36 @@ func TestPacker2DByteSlice(t *testing.T) {
 		t.Fatal("should match")
 	}
 }
+
+func TestPackerLongs(t *testing.T) {
+	p := Packer{MaxSize: 1024}
+	vals := []uint64{0, 1, math.MaxUint64}
+	p.PackLongs(vals)
+	if p.Errored() {
+		t.Fatal(p.Err)
+	}
+	if size := len(p.Bytes); size != IntLen+3*LongLen {
+		t.Fatalf("Packer.PackLongs wrote %d byte(s) but expected %d byte(s)", size, IntLen+3*LongLen)
+	}
+
+	p = Packer{Bytes: p.Bytes}
+	unpacked := p.UnpackLongs()
+	if p.Errored() {
+		t.Fatal(p.Err)
+	}
+	if len(unpacked) != len(vals) {
+		t.Fatalf("Packer.UnpackLongs returned %d value(s) but expected %d value(s)", len(unpacked), len(vals))
+	}
+	for i, val := range vals {
+		if unpacked[i] != val {
+			t.Fatalf("Packer.UnpackLongs returned %d at index %d but expected %d", unpacked[i], i, val)
+		}
+	}
+
+	// Case: The length prefix claims more values than there are bytes
+	p = Packer{Bytes: []byte{0x00, 0x00, 0x00, 0x02, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x01}}
+	p.UnpackLongs()
+	if !p.Errored() {
+		t.Fatal("Packer.UnpackLongs should have errored on a truncated slice")
+	}
+}
@@ -0,0 +1,95 @@
+// (c) 2019-2020, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package avm
+
+import (
+	"math"
+
+	"github.com/ava-labs/avalanchego/ids"
+	"github.com/ava-labs/avalanchego/vms/secp256k1fx"
+
+	safemath "github.com/ava-labs/avalanchego/utils/math"
+)
+
+// addressBalances summarizes the balances of an address. It's cached until a
+// UTXO that references the address is spent or created, or until one of the
+// address's UTXOs unlocks.
+type addressBalances struct {
+	// Asset ID --> amount held solely by the address that's unlocked
+	owned map[ids.ID]uint64
+	// Asset ID --> amount held, fully or partially, by the address, including
+	// amounts that are locked
+	all map[ids.ID]uint64
+	// Unix time at which a UTXO held solely by the address unlocks, after
+	// which [owned] is stale
+	unlockTime uint64
+}
+
+// Balances returns the balances of the address. If [includePartial], the
+// balances include UTXOs held only partially by the address, and UTXOs that
+// are locked.
+func (b *addressBalances) Balances(includePartial bool) map[ids.ID]uint64 {
+	if includePartial {
+		return b.all
+	}
+	return b.owned
+}
+
+// getAddressBalances returns the balances of [addr] as of the last acceptance
+// boundary. The balances are cached until they may have changed.
+func (vm *VM) getAddressBalances(addr ids.ShortID) (*addressBalances, error) {
+	now := vm.clock.Unix()
+	if balancesIntf, ok := vm.snapshot.balances.Get(addr); ok {
+		if balances := balancesIntf.(*addressBalances); now < balances.unlockTime {
+			return balances, nil
+		}
+	}
+
+	addrSet := ids.ShortSet{}
+	addrSet.Add(addr)
+	utxos, _, _, err := vm.GetUTXOs(addrSet, ids.ShortEmpty, ids.Empty, -1, false)
+	if err != nil {
+		return nil, err
+	}
+
+	balances := &addressBalances{
+		owned:      make(map[ids.ID]uint64),
+		all:        make(map[ids.ID]uint64),
+		unlockTime: math.MaxUint64,
+	}
+	for _, utxo := range utxos {
+		// TODO make this not specific to *secp256k1fx.TransferOutput
+		transferable, ok := utxo.Out.(*secp256k1fx.TransferOutput)
+		if !ok {
+			continue
+		}
+		assetID := utxo.AssetID()
+		amount := transferable.Amount()
+		balances.all[assetID] = addBalance(balances.all[assetID], amount)
+
+		owners := transferable.OutputOwners
+		if len(owners.Addrs) != 1 {
+			continue
+		}
+		if owners.Locktime > now {
+			if owners.Locktime < balances.unlockTime {
+				balances.unlockTime = owners.Locktime
+			}
+			continue
+		}
+		balances.owned[assetID] = addBalance(balances.owned[assetID], amount)
+	}
+
+	vm.snapshot.balances.Put(addr, balances)
+	return balances, nil
+}
+
+// addBalance returns [balance] + [amount], capped at the maximum uint64
+func addBalance(balance, amount uint64) uint64 {
+	sum, err := safemath.Add64(balance, amount)
+	if err != nil {
+		return math.MaxUint64
+	}
+	return sum
+}
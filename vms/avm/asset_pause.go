@@ -0,0 +1,112 @@
+// (c) 2019-2020, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package avm
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/ava-labs/avalanchego/ids"
+	"github.com/ava-labs/avalanchego/vms/pausefx"
+)
+
+const (
+	// pubsub channels that the IDs of paused and unpaused assets are
+	// published on
+	pausedChannel   = "paused"
+	unpausedChannel = "unpaused"
+)
+
+var (
+	errAssetPaused = errors.New("transfers of the asset are paused")
+)
+
+// assetPause is a change to whether transfers of an asset are paused
+type assetPause struct {
+	assetID ids.ID
+	paused  bool
+}
+
+// assetPauses returns the changes to assets' pause states that [utx] makes,
+// in the order that they're made
+func assetPauses(utx UnsignedTx) []assetPause {
+	opTx, ok := utx.(*OperationTx)
+	if !ok {
+		return nil
+	}
+	pauses := []assetPause(nil)
+	for _, op := range opTx.Ops {
+		if pauseOp, ok := op.Op.(*pausefx.PauseOperation); ok {
+			pauses = append(pauses, assetPause{
+				assetID: op.AssetID(),
+				paused:  pauseOp.Paused,
+			})
+		}
+	}
+	return pauses
+}
+
+// transferredAssets returns the IDs of the assets that [utx] transfers. Pause
+// operations aren't transfers, so that a paused asset can be unpaused.
+func transferredAssets(utx UnsignedTx) ids.Set {
+	opTx, ok := utx.(*OperationTx)
+	if !ok {
+		return utx.ConsumedAssetIDs()
+	}
+	assets := opTx.BaseTx.AssetIDs()
+	for _, op := range opTx.Ops {
+		if _, ok := op.Op.(*pausefx.PauseOperation); !ok {
+			assets.Add(op.AssetID())
+		}
+	}
+	return assets
+}
+
+// verifyNotPaused returns an error if [utx] transfers an asset whose transfers
+// are paused.
+//
+// Pauses aren't ordered against transfers by consensus, so whether a transfer
+// was valid can't depend on them. A pause is only enforced when a tx is issued
+// by this node, and never when a tx is verified, so that every node, including
+// one replaying the accepted txs while bootstrapping, agrees on which txs are
+// valid.
+func (vm *VM) verifyNotPaused(utx UnsignedTx) error {
+	for assetID := range transferredAssets(utx) {
+		paused, err := vm.state.AssetPaused(assetID)
+		switch {
+		case err != nil:
+			return err
+		case paused:
+			return fmt.Errorf("%w: %s", errAssetPaused, assetID)
+		}
+	}
+	return nil
+}
+
+// applyAssetPauses records the pause state changes made by the accepted tx
+// [utx], and returns them so they can be published once the acceptance is
+// committed
+func (vm *VM) applyAssetPauses(utx UnsignedTx) ([]assetPause, error) {
+	pauses := assetPauses(utx)
+	for _, pause := range pauses {
+		if err := vm.state.SetAssetPaused(pause.assetID, pause.paused); err != nil {
+			return nil, err
+		}
+	}
+	return pauses, nil
+}
+
+// publishAssetPauses publishes the IDs of the assets in [pauses] on the
+// paused and unpaused channels
+func (vm *VM) publishAssetPauses(pauses []assetPause) {
+	for _, pause := range pauses {
+		if pause.paused {
+			vm.ctx.Log.Info("transfers of asset %s were paused", pause.assetID)
+			vm.pubsub.Publish(pausedChannel, pause.assetID)
+		} else {
+			vm.ctx.Log.Info("transfers of asset %s were unpaused", pause.assetID)
+			vm.pubsub.Publish(unpausedChannel, pause.assetID)
+		}
+	}
+}
@@ -0,0 +1,272 @@
+// (c) 2019-2020, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package avm
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/ava-labs/avalanchego/database/memdb"
+	"github.com/ava-labs/avalanchego/ids"
+	"github.com/ava-labs/avalanchego/snow/engine/common"
+	"github.com/ava-labs/avalanchego/utils/crypto"
+	"github.com/ava-labs/avalanchego/utils/hashing"
+	"github.com/ava-labs/avalanchego/vms/components/avax"
+	"github.com/ava-labs/avalanchego/vms/components/verify"
+	"github.com/ava-labs/avalanchego/vms/nftfx"
+	"github.com/ava-labs/avalanchego/vms/pausefx"
+	"github.com/ava-labs/avalanchego/vms/propertyfx"
+	"github.com/ava-labs/avalanchego/vms/secp256k1fx"
+)
+
+// signPauseFx signs [tx] with a pausefx credential for each of [signers]
+func signPauseFx(t *testing.T, vm *VM, tx *Tx, signers ...*crypto.PrivateKeySECP256K1R) {
+	unsignedBytes, err := vm.codec.Marshal(codecVersion, &tx.UnsignedTx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	hash := hashing.ComputeHash256(unsignedBytes)
+	for _, key := range signers {
+		sig, err := key.SignHash(hash)
+		if err != nil {
+			t.Fatal(err)
+		}
+		cred := &pausefx.Credential{Credential: secp256k1fx.Credential{
+			Sigs: make([][crypto.SECP256K1RSigLen]byte, 1),
+		}}
+		copy(cred.Sigs[0][:], sig)
+		tx.Creds = append(tx.Creds, cred)
+	}
+	signedBytes, err := vm.codec.Marshal(codecVersion, tx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tx.Initialize(unsignedBytes, signedBytes)
+}
+
+// acceptTx parses, verifies and accepts [tx]
+func acceptTx(t *testing.T, vm *VM, tx *Tx) {
+	parsedTx, err := vm.Parse(tx.Bytes())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := parsedTx.Verify(); err != nil {
+		t.Fatal(err)
+	}
+	if err := parsedTx.Accept(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// setupPausableAsset initializes a VM with the pausefx and accepts an asset
+// that keys[0] can transfer and pause. If [bootstrapped], the VM finishes
+// bootstrapping. Returns the VM and the asset's ID.
+func setupPausableAsset(t *testing.T, bootstrapped bool) (*VM, ids.ID) {
+	vm := &VM{}
+	ctx := NewContext(t)
+	ctx.Lock.Lock()
+
+	genesisBytes := BuildGenesisTest(t)
+	issuer := make(chan common.Message, 1)
+	err := vm.Initialize(
+		ctx,
+		memdb.New(),
+		genesisBytes,
+		issuer,
+		[]*common.Fx{
+			{
+				ID: ids.Empty.Prefix(0),
+				Fx: &secp256k1fx.Fx{},
+			},
+			{
+				ID: ids.Empty.Prefix(1),
+				Fx: &nftfx.Fx{},
+			},
+			{
+				ID: ids.Empty.Prefix(2),
+				Fx: &propertyfx.Fx{},
+			},
+			{
+				ID: ids.Empty.Prefix(3),
+				Fx: &pausefx.Fx{},
+			},
+		},
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	vm.batchTimeout = 0
+
+	if err := vm.Bootstrapping(); err != nil {
+		t.Fatal(err)
+	}
+	if bootstrapped {
+		if err := vm.Bootstrapped(); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	createAssetTx := &Tx{UnsignedTx: &CreateAssetTx{
+		BaseTx: BaseTx{BaseTx: avax.BaseTx{
+			NetworkID:    networkID,
+			BlockchainID: chainID,
+		}},
+		Name:         "Team Rocket",
+		Symbol:       "TR",
+		Denomination: 0,
+		States: []*InitialState{
+			{
+				FxID: 0,
+				Outs: []verify.State{
+					&secp256k1fx.TransferOutput{
+						Amt:          100,
+						OutputOwners: pauseOwners(),
+					},
+				},
+			},
+			{
+				FxID: 3,
+				Outs: []verify.State{
+					&pausefx.PauseOutput{OutputOwners: pauseOwners()},
+				},
+			},
+		},
+	}}
+	if err := createAssetTx.SignSECP256K1Fx(vm.codec, nil); err != nil {
+		t.Fatal(err)
+	}
+	acceptTx(t, vm, createAssetTx)
+	return vm, createAssetTx.ID()
+}
+
+// shutdownVM shuts down [vm] and releases its context's lock
+func shutdownVM(t *testing.T, vm *VM) {
+	if err := vm.Shutdown(); err != nil {
+		t.Fatal(err)
+	}
+	vm.ctx.Lock.Unlock()
+}
+
+// pauseOwners returns the owners of the pausable asset's initial outputs
+func pauseOwners() secp256k1fx.OutputOwners {
+	return secp256k1fx.OutputOwners{
+		Threshold: 1,
+		Addrs:     []ids.ShortID{keys[0].PublicKey().Address()},
+	}
+}
+
+// newPauseTx returns a tx that spends the pause output [utxoID] of [assetID]
+// to set whether its transfers are [paused]
+func newPauseTx(t *testing.T, vm *VM, assetID ids.ID, utxoID avax.UTXOID, paused bool) *Tx {
+	tx := &Tx{UnsignedTx: &OperationTx{
+		BaseTx: BaseTx{BaseTx: avax.BaseTx{
+			NetworkID:    networkID,
+			BlockchainID: chainID,
+		}},
+		Ops: []*Operation{{
+			Asset:   avax.Asset{ID: assetID},
+			UTXOIDs: []*avax.UTXOID{&utxoID},
+			Op: &pausefx.PauseOperation{
+				Input: secp256k1fx.Input{
+					SigIndices: []uint32{0},
+				},
+				Paused:      paused,
+				PauseOutput: pausefx.PauseOutput{OutputOwners: pauseOwners()},
+			},
+		}},
+	}}
+	signPauseFx(t, vm, tx, keys[0])
+	return tx
+}
+
+// newTransferTx returns a tx that sends all of the initial supply of [assetID]
+// to keys[1]
+func newTransferTx(t *testing.T, vm *VM, assetID ids.ID) *Tx {
+	tx := &Tx{UnsignedTx: &BaseTx{BaseTx: avax.BaseTx{
+		NetworkID:    networkID,
+		BlockchainID: chainID,
+		Ins: []*avax.TransferableInput{{
+			UTXOID: avax.UTXOID{TxID: assetID, OutputIndex: 0},
+			Asset:  avax.Asset{ID: assetID},
+			In: &secp256k1fx.TransferInput{
+				Amt: 100,
+				Input: secp256k1fx.Input{
+					SigIndices: []uint32{0},
+				},
+			},
+		}},
+		Outs: []*avax.TransferableOutput{{
+			Asset: avax.Asset{ID: assetID},
+			Out: &secp256k1fx.TransferOutput{
+				Amt: 100,
+				OutputOwners: secp256k1fx.OutputOwners{
+					Threshold: 1,
+					Addrs:     []ids.ShortID{keys[1].PublicKey().Address()},
+				},
+			},
+		}},
+	}}}
+	if err := tx.SignSECP256K1Fx(vm.codec, [][]*crypto.PrivateKeySECP256K1R{{keys[0]}}); err != nil {
+		t.Fatal(err)
+	}
+	return tx
+}
+
+func TestAssetPause(t *testing.T) {
+	vm, assetID := setupPausableAsset(t, true)
+	defer shutdownVM(t, vm)
+
+	pauseTx := newPauseTx(t, vm, assetID, avax.UTXOID{TxID: assetID, OutputIndex: 1}, true)
+	acceptTx(t, vm, pauseTx)
+	if paused, err := vm.state.AssetPaused(assetID); err != nil {
+		t.Fatal(err)
+	} else if !paused {
+		t.Fatalf("asset should have been paused")
+	}
+
+	transferTx := newTransferTx(t, vm, assetID)
+	if _, err := vm.IssueTx(transferTx.Bytes()); !errors.Is(err, errAssetPaused) {
+		t.Fatalf("expected %s but got %v", errAssetPaused, err)
+	}
+
+	// The asset can be unpaused while it's paused
+	unpauseTx := newPauseTx(t, vm, assetID, avax.UTXOID{TxID: pauseTx.ID(), OutputIndex: 0}, false)
+	if _, err := vm.IssueTx(unpauseTx.Bytes()); err != nil {
+		t.Fatal(err)
+	}
+	acceptTx(t, vm, unpauseTx)
+	if paused, err := vm.state.AssetPaused(assetID); err != nil {
+		t.Fatal(err)
+	} else if paused {
+		t.Fatalf("asset should have been unpaused")
+	}
+
+	if _, err := vm.IssueTx(transferTx.Bytes()); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// A transfer that the network accepted before a pause must still be valid when
+// a bootstrapping node replays it after the pause has been applied, as the
+// order in which they're replayed isn't the order in which they were accepted.
+func TestAssetPauseKeepsAcceptedTransfersValid(t *testing.T) {
+	vm, assetID := setupPausableAsset(t, false)
+	defer shutdownVM(t, vm)
+
+	pauseTx := newPauseTx(t, vm, assetID, avax.UTXOID{TxID: assetID, OutputIndex: 1}, true)
+	acceptTx(t, vm, pauseTx)
+	if paused, err := vm.state.AssetPaused(assetID); err != nil {
+		t.Fatal(err)
+	} else if !paused {
+		t.Fatalf("asset should have been paused")
+	}
+
+	transferTx := newTransferTx(t, vm, assetID)
+	acceptTx(t, vm, transferTx)
+
+	utxoID := &avax.UTXOID{TxID: transferTx.ID(), OutputIndex: 0}
+	if _, err := vm.getUTXO(utxoID); err != nil {
+		t.Fatalf("transfer should have been accepted: %s", err)
+	}
+}
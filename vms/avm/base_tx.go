@@ -68,5 +68,8 @@ func (t *BaseTx) SemanticVerify(vm *VM, tx UnsignedTx, creds []verify.Verifiable
 	return nil
 }
 
+// AtomicOperations returns nil, as a BaseTx has no shared memory operations
+func (t *BaseTx) AtomicOperations(*VM) (*atomicOperations, error) { return nil, nil }
+
 // ExecuteWithSideEffects writes the batch with any additional side effects
 func (t *BaseTx) ExecuteWithSideEffects(_ *VM, batch database.Batch) error { return batch.Write() }
@@ -4,7 +4,9 @@
 package avm
 
 import (
+	"errors"
 	"fmt"
+	"sync"
 	"time"
 
 	"github.com/ava-labs/avalanchego/api"
@@ -12,6 +14,7 @@ import (
 	"github.com/ava-labs/avalanchego/snow/choices"
 	"github.com/ava-labs/avalanchego/utils/formatting"
 	"github.com/ava-labs/avalanchego/utils/rpc"
+	"github.com/ava-labs/avalanchego/vms/components/avax"
 
 	cjson "github.com/ava-labs/avalanchego/utils/json"
 )
@@ -19,6 +22,10 @@ import (
 // Client ...
 type Client struct {
 	requester rpc.EndpointRequester
+
+	versionLock sync.Mutex
+	// Version of the node's API, or nil if it hasn't been negotiated yet
+	version *uint32
 }
 
 // NewClient returns an AVM client for interacting with avm [chain]
@@ -51,6 +58,153 @@ func (c *Client) GetTxStatus(txID ids.ID) (choices.Status, error) {
 	return res.Status, err
 }
 
+// APIVersion returns the version of the node's API. Nodes that predate API
+// versions are reported as version 0. The version is only requested once.
+func (c *Client) APIVersion() (uint32, error) {
+	c.versionLock.Lock()
+	defer c.versionLock.Unlock()
+
+	if c.version != nil {
+		return *c.version, nil
+	}
+
+	res := &GetAPIVersionReply{}
+	err := c.requester.SendRequest("getAPIVersion", struct{}{}, res)
+	switch {
+	case errors.Is(err, rpc.ErrMethodNotSupported):
+		res.Version = 0
+	case err != nil:
+		return 0, err
+	}
+
+	version := uint32(res.Version)
+	c.version = &version
+	return version, nil
+}
+
+// downgrade records that the node doesn't serve methods added after
+// [version], which can happen if the node was downgraded since the version
+// was negotiated
+func (c *Client) downgrade(version uint32) {
+	c.versionLock.Lock()
+	defer c.versionLock.Unlock()
+
+	if c.version == nil || *c.version > version {
+		c.version = &version
+	}
+}
+
+// GetTxStatuses returns the status of each of [txIDs]. If the node doesn't
+// support fetching statuses in batches, they're fetched one at a time.
+func (c *Client) GetTxStatuses(txIDs []ids.ID) ([]choices.Status, error) {
+	version, err := c.APIVersion()
+	if err != nil {
+		return nil, err
+	}
+	if version < 1 {
+		return c.getTxStatusesIndividually(txIDs)
+	}
+
+	statuses := make([]choices.Status, 0, len(txIDs))
+	for start := 0; start < len(txIDs); start += maxGetTxStatusesTxIDs {
+		end := start + maxGetTxStatusesTxIDs
+		if end > len(txIDs) {
+			end = len(txIDs)
+		}
+
+		res := &GetTxStatusesReply{}
+		err := c.requester.SendRequest("getTxStatuses", &GetTxStatusesArgs{
+			TxIDs: txIDs[start:end],
+		}, res)
+		if errors.Is(err, rpc.ErrMethodNotSupported) {
+			c.downgrade(0)
+			return c.getTxStatusesIndividually(txIDs)
+		}
+		if err != nil {
+			return nil, err
+		}
+		statuses = append(statuses, res.Statuses...)
+	}
+	return statuses, nil
+}
+
+func (c *Client) getTxStatusesIndividually(txIDs []ids.ID) ([]choices.Status, error) {
+	statuses := make([]choices.Status, len(txIDs))
+	for i, txID := range txIDs {
+		status, err := c.GetTxStatus(txID)
+		if err != nil {
+			return nil, err
+		}
+		statuses[i] = status
+	}
+	return statuses, nil
+}
+
+// EstimateFee returns the fee that a tx should pay, and the congestion of recent
+// tx issuance at [percentile]. If [percentile] is 0, the node's default is used.
+func (c *Client) EstimateFee(percentile float32, createsAsset bool) (*EstimateFeeReply, error) {
+	version, err := c.APIVersion()
+	if err != nil {
+		return nil, err
+	}
+	if version < 2 {
+		return nil, fmt.Errorf("%w: avm.estimateFee", rpc.ErrMethodNotSupported)
+	}
+
+	res := &EstimateFeeReply{}
+	err = c.requester.SendRequest("estimateFee", &EstimateFeeArgs{
+		Percentile:   cjson.Float32(percentile),
+		CreatesAsset: createsAsset,
+	}, res)
+	return res, err
+}
+
+// SearchMemos returns the accepted txs whose memo is [memo] or, if [prefix] is
+// true, starts with [memo]. If [startIndex] is non-nil, only the matches after
+// it are returned.
+func (c *Client) SearchMemos(memo string, prefix bool, limit uint32, startIndex *MemoIndex) (*SearchMemosReply, error) {
+	version, err := c.APIVersion()
+	if err != nil {
+		return nil, err
+	}
+	if version < 3 {
+		return nil, fmt.Errorf("%w: avm.searchMemos", rpc.ErrMethodNotSupported)
+	}
+
+	res := &SearchMemosReply{}
+	err = c.requester.SendRequest("searchMemos", &SearchMemosArgs{
+		Memo:       memo,
+		Prefix:     prefix,
+		Limit:      cjson.Uint32(limit),
+		StartIndex: startIndex,
+	}, res)
+	return res, err
+}
+
+// GetConflicts returns the processing txs that conflict with [txID]
+func (c *Client) GetConflicts(txID ids.ID) (*GetConflictsReply, error) {
+	return c.getConflicts(&GetConflictsArgs{TxID: txID})
+}
+
+// GetUTXOConflicts returns the processing txs that spend [utxoID]
+func (c *Client) GetUTXOConflicts(utxoID *avax.UTXOID) (*GetConflictsReply, error) {
+	return c.getConflicts(&GetConflictsArgs{UTXOID: utxoID})
+}
+
+func (c *Client) getConflicts(args *GetConflictsArgs) (*GetConflictsReply, error) {
+	version, err := c.APIVersion()
+	if err != nil {
+		return nil, err
+	}
+	if version < 4 {
+		return nil, fmt.Errorf("%w: avm.getConflicts", rpc.ErrMethodNotSupported)
+	}
+
+	res := &GetConflictsReply{}
+	err = c.requester.SendRequest("getConflicts", args, res)
+	return res, err
+}
+
 // ConfirmTx attempts to confirm [txID] by checking its status [attempts] times
 // with a [delay] in between each attempt. If the transaction has not been decided
 // by the final attempt, it returns the status of the last attempt.
@@ -0,0 +1,139 @@
+// (c) 2019-2020, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package avm
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/ava-labs/avalanchego/api"
+	"github.com/ava-labs/avalanchego/ids"
+	"github.com/ava-labs/avalanchego/snow/choices"
+	"github.com/ava-labs/avalanchego/utils/rpc"
+)
+
+// mockRequester serves the methods of an API of version [version]
+type mockRequester struct {
+	version  uint32
+	statuses map[ids.ID]choices.Status
+	// Method --> number of times it was requested
+	requests map[string]int
+}
+
+func newMockRequester(version uint32) *mockRequester {
+	return &mockRequester{
+		version:  version,
+		statuses: make(map[ids.ID]choices.Status),
+		requests: make(map[string]int),
+	}
+}
+
+func (r *mockRequester) SendRequest(method string, params interface{}, reply interface{}) error {
+	r.requests[method]++
+	switch method {
+	case "getTxStatus":
+		reply.(*GetTxStatusReply).Status = r.statuses[params.(*api.JSONTxID).TxID]
+		return nil
+	case "getAPIVersion":
+		if r.version >= 1 {
+			reply.(*GetAPIVersionReply).Version = 1
+			return nil
+		}
+	case "getTxStatuses":
+		if r.version >= 1 {
+			res := reply.(*GetTxStatusesReply)
+			for _, txID := range params.(*GetTxStatusesArgs).TxIDs {
+				res.Statuses = append(res.Statuses, r.statuses[txID])
+			}
+			return nil
+		}
+	}
+	return fmt.Errorf("%w: avm.%s", rpc.ErrMethodNotSupported, method)
+}
+
+func TestClientGetTxStatuses(t *testing.T) {
+	txID0 := ids.GenerateTestID()
+	txID1 := ids.GenerateTestID()
+	expected := []choices.Status{choices.Accepted, choices.Processing}
+
+	tests := []struct {
+		name               string
+		version            uint32
+		batchedRequests    int
+		individualRequests int
+	}{
+		{
+			name:               "batched",
+			version:            1,
+			batchedRequests:    1,
+			individualRequests: 0,
+		},
+		{
+			name:               "legacy node",
+			version:            0,
+			batchedRequests:    0,
+			individualRequests: 2,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			assert := assert.New(t)
+
+			requester := newMockRequester(test.version)
+			requester.statuses[txID0] = choices.Accepted
+			requester.statuses[txID1] = choices.Processing
+			c := &Client{requester: requester}
+
+			version, err := c.APIVersion()
+			assert.NoError(err)
+			assert.Equal(test.version, version)
+
+			statuses, err := c.GetTxStatuses([]ids.ID{txID0, txID1})
+			assert.NoError(err)
+			assert.Equal(expected, statuses)
+			assert.Equal(test.batchedRequests, requester.requests["getTxStatuses"])
+			assert.Equal(test.individualRequests, requester.requests["getTxStatus"])
+			assert.Equal(1, requester.requests["getAPIVersion"], "the version should only be negotiated once")
+		})
+	}
+}
+
+func TestClientFallsBackAfterDowngrade(t *testing.T) {
+	assert := assert.New(t)
+
+	txID := ids.GenerateTestID()
+	requester := newMockRequester(1)
+	requester.statuses[txID] = choices.Accepted
+	c := &Client{requester: requester}
+
+	_, err := c.APIVersion()
+	assert.NoError(err)
+
+	// The node is downgraded after the version was negotiated
+	requester.version = 0
+	statuses, err := c.GetTxStatuses([]ids.ID{txID})
+	assert.NoError(err)
+	assert.Equal([]choices.Status{choices.Accepted}, statuses)
+
+	version, err := c.APIVersion()
+	assert.NoError(err)
+	assert.Equal(uint32(0), version)
+
+	// Later requests don't attempt the batched method again
+	_, err = c.GetTxStatuses([]ids.ID{txID})
+	assert.NoError(err)
+	assert.Equal(1, requester.requests["getTxStatuses"])
+}
+
+func TestClientEstimateFeeUnsupported(t *testing.T) {
+	requester := newMockRequester(1)
+	c := &Client{requester: requester}
+
+	_, err := c.EstimateFee(50, false)
+	assert.True(t, errors.Is(err, rpc.ErrMethodNotSupported))
+	assert.Zero(t, requester.requests["estimateFee"], "nodes that predate estimateFee shouldn't be asked for estimates")
+}
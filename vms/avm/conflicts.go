@@ -0,0 +1,90 @@
+// (c) 2019-2020, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package avm
+
+import (
+	"github.com/ava-labs/avalanchego/ids"
+	"github.com/ava-labs/avalanchego/snow/choices"
+)
+
+// processingTxs indexes the txs that have been issued into consensus, and
+// aren't yet decided, by the UTXOs they spend. This allows double spends to
+// be reported before either tx is decided.
+type processingTxs struct {
+	// UTXO ID --> IDs of the processing txs that spend it
+	spenders map[ids.ID]ids.Set
+	// Tx ID --> IDs of the UTXOs it spends
+	inputs map[ids.ID][]ids.ID
+}
+
+func newProcessingTxs() *processingTxs {
+	return &processingTxs{
+		spenders: make(map[ids.ID]ids.Set),
+		inputs:   make(map[ids.ID][]ids.ID),
+	}
+}
+
+// Add [txID], which spends [inputIDs], to the index. Adding a tx that is
+// already indexed has no effect.
+func (p *processingTxs) Add(txID ids.ID, inputIDs []ids.ID) {
+	if _, exists := p.inputs[txID]; exists {
+		return
+	}
+	p.inputs[txID] = inputIDs
+	for _, inputID := range inputIDs {
+		spenders := p.spenders[inputID]
+		spenders.Add(txID)
+		p.spenders[inputID] = spenders
+	}
+}
+
+// Remove [txID] from the index
+func (p *processingTxs) Remove(txID ids.ID) {
+	inputIDs, exists := p.inputs[txID]
+	if !exists {
+		return
+	}
+	delete(p.inputs, txID)
+	for _, inputID := range inputIDs {
+		spenders := p.spenders[inputID]
+		spenders.Remove(txID)
+		if spenders.Len() == 0 {
+			delete(p.spenders, inputID)
+		} else {
+			p.spenders[inputID] = spenders
+		}
+	}
+}
+
+// Spenders returns the IDs of the processing txs that spend [inputID]
+func (p *processingTxs) Spenders(inputID ids.ID) []ids.ID {
+	return p.spenders[inputID].List()
+}
+
+// trackProcessing adds [tx] to the processing tx index, if it's processing
+func (vm *VM) trackProcessing(tx *UniqueTx) {
+	if tx.Status() == choices.Processing {
+		vm.processing.Add(tx.ID(), tx.InputIDs())
+	}
+}
+
+// Conflicts returns the IDs of the processing txs that spend a UTXO that
+// [txID] spends, other than [txID] itself
+func (vm *VM) Conflicts(txID ids.ID) ([]ids.ID, error) {
+	tx := &UniqueTx{
+		vm:   vm,
+		txID: txID,
+	}
+	tx.refresh()
+	if tx.Tx == nil {
+		return nil, errUnknownTx
+	}
+
+	conflicts := ids.Set{}
+	for _, inputID := range tx.InputIDs() {
+		conflicts.Add(vm.processing.Spenders(inputID)...)
+	}
+	conflicts.Remove(txID)
+	return conflicts.List(), nil
+}
@@ -0,0 +1,73 @@
+// (c) 2019-2020, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package avm
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/ava-labs/avalanchego/ids"
+	"github.com/ava-labs/avalanchego/vms/components/avax"
+)
+
+func TestGetConflicts(t *testing.T) {
+	assert := assert.New(t)
+
+	genesisBytes, vm, s, _ := setup(t)
+	defer func() {
+		if err := vm.Shutdown(); err != nil {
+			t.Fatal(err)
+		}
+		vm.ctx.Lock.Unlock()
+	}()
+
+	// Both txs spend the same genesis UTXO
+	first, err := vm.parseTx(NewTx(t, genesisBytes, vm).Bytes())
+	assert.NoError(err)
+	second, err := vm.parseTx(newTxWithOutput(t, genesisBytes, vm).Bytes())
+	assert.NoError(err)
+
+	// Only txs issued into consensus are considered
+	conflicts, err := vm.Conflicts(first.ID())
+	assert.NoError(err)
+	assert.Empty(conflicts)
+
+	assert.NoError(first.Verify())
+	assert.NoError(second.Verify())
+
+	conflicts, err = vm.Conflicts(first.ID())
+	assert.NoError(err)
+	assert.Equal([]ids.ID{second.ID()}, conflicts)
+
+	reply := &GetConflictsReply{}
+	assert.NoError(s.GetConflicts(nil, &GetConflictsArgs{TxID: second.ID()}, reply))
+	assert.True(reply.Conflicting)
+	assert.Equal([]ids.ID{first.ID()}, reply.TxIDs)
+
+	avaxTx := GetAVAXTxFromGenesisTest(genesisBytes, t)
+	utxoID := &avax.UTXOID{TxID: avaxTx.ID(), OutputIndex: 2}
+	reply = &GetConflictsReply{}
+	assert.NoError(s.GetConflicts(nil, &GetConflictsArgs{UTXOID: utxoID}, reply))
+	assert.True(reply.Conflicting)
+	assert.ElementsMatch([]ids.ID{first.ID(), second.ID()}, reply.TxIDs)
+
+	// Decided txs no longer conflict
+	assert.NoError(first.Accept())
+	assert.NoError(second.Reject())
+
+	reply = &GetConflictsReply{}
+	assert.NoError(s.GetConflicts(nil, &GetConflictsArgs{UTXOID: utxoID}, reply))
+	assert.False(reply.Conflicting)
+	assert.Empty(reply.TxIDs)
+
+	_, err = vm.Conflicts(ids.GenerateTestID())
+	assert.True(errors.Is(err, errUnknownTx))
+
+	err = s.GetConflicts(nil, &GetConflictsArgs{TxID: first.ID(), UTXOID: utxoID}, &GetConflictsReply{})
+	assert.Equal(errTxIDAndUTXOID, err)
+	err = s.GetConflicts(nil, &GetConflictsArgs{}, &GetConflictsReply{})
+	assert.Equal(errTxIDAndUTXOID, err)
+}
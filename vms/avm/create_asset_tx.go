@@ -84,31 +84,11 @@ func (t *CreateAssetTx) SyntacticVerify(
 	switch {
 	case t == nil:
 		return errNilTx
-	case len(t.Name) < minNameLen:
-		return errNameTooShort
-	case len(t.Name) > maxNameLen:
-		return errNameTooLong
-	case len(t.Symbol) < minSymbolLen:
-		return errSymbolTooShort
-	case len(t.Symbol) > maxSymbolLen:
-		return errSymbolTooLong
 	case len(t.States) == 0:
 		return errNoFxs
-	case t.Denomination > maxDenomination:
-		return errDenominationTooLarge
-	case strings.TrimSpace(t.Name) != t.Name:
-		return errUnexpectedWhitespace
-	}
-
-	for _, r := range t.Name {
-		if r > unicode.MaxASCII || !(unicode.IsLetter(r) || unicode.IsNumber(r) || r == ' ') {
-			return errIllegalNameCharacter
-		}
 	}
-	for _, r := range t.Symbol {
-		if r > unicode.MaxASCII || !unicode.IsUpper(r) {
-			return errIllegalSymbolCharacter
-		}
+	if err := VerifyAssetDescription(t.Name, t.Symbol, t.Denomination); err != nil {
+		return err
 	}
 
 	if err := t.BaseTx.SyntacticVerify(ctx, c, txFeeAssetID, txFee, txFee, numFxs); err != nil {
@@ -128,3 +108,34 @@ func (t *CreateAssetTx) SyntacticVerify(
 
 // Sort ...
 func (t *CreateAssetTx) Sort() { sortInitialStates(t.States) }
+
+// VerifyAssetDescription returns an error if an asset can't be created with
+// [name], [symbol], and [denomination]
+func VerifyAssetDescription(name, symbol string, denomination byte) error {
+	switch {
+	case len(name) < minNameLen:
+		return errNameTooShort
+	case len(name) > maxNameLen:
+		return errNameTooLong
+	case len(symbol) < minSymbolLen:
+		return errSymbolTooShort
+	case len(symbol) > maxSymbolLen:
+		return errSymbolTooLong
+	case denomination > maxDenomination:
+		return errDenominationTooLarge
+	case strings.TrimSpace(name) != name:
+		return errUnexpectedWhitespace
+	}
+
+	for _, r := range name {
+		if r > unicode.MaxASCII || !(unicode.IsLetter(r) || unicode.IsNumber(r) || r == ' ') {
+			return errIllegalNameCharacter
+		}
+	}
+	for _, r := range symbol {
+		if r > unicode.MaxASCII || !unicode.IsUpper(r) {
+			return errIllegalSymbolCharacter
+		}
+	}
+	return nil
+}
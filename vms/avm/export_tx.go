@@ -92,8 +92,8 @@ func (t *ExportTx) SemanticVerify(vm *VM, tx UnsignedTx, creds []verify.Verifiab
 	return t.BaseTx.SemanticVerify(vm, tx, creds)
 }
 
-// ExecuteWithSideEffects writes the batch with any additional side effects
-func (t *ExportTx) ExecuteWithSideEffects(vm *VM, batch database.Batch) error {
+// AtomicOperations returns the exported UTXOs to put into shared memory
+func (t *ExportTx) AtomicOperations(vm *VM) (*atomicOperations, error) {
 	txID := t.ID()
 
 	elems := make([]*atomic.Element, len(t.ExportedOuts))
@@ -109,7 +109,7 @@ func (t *ExportTx) ExecuteWithSideEffects(vm *VM, batch database.Batch) error {
 
 		utxoBytes, err := vm.codec.Marshal(codecVersion, utxo)
 		if err != nil {
-			return err
+			return nil, err
 		}
 
 		inputID := utxo.InputID()
@@ -124,5 +124,17 @@ func (t *ExportTx) ExecuteWithSideEffects(vm *VM, batch database.Batch) error {
 		elems[i] = elem
 	}
 
-	return vm.ctx.SharedMemory.Put(t.DestinationChain, elems, batch)
+	return &atomicOperations{
+		PeerChainID: t.DestinationChain,
+		Puts:        elems,
+	}, nil
+}
+
+// ExecuteWithSideEffects writes the batch with any additional side effects
+func (t *ExportTx) ExecuteWithSideEffects(vm *VM, batch database.Batch) error {
+	ops, err := t.AtomicOperations(vm)
+	if err != nil {
+		return err
+	}
+	return ops.Apply(vm.ctx.SharedMemory, batch)
 }
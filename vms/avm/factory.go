@@ -17,12 +17,37 @@ var (
 type Factory struct {
 	CreationFee uint64
 	Fee         uint64
+
+	// Maximum size, in bytes, of a tx issued to this node. 0 means no limit.
+	MaxTxSize int
+	// Maximum number of inputs a tx issued to this node may consume. 0 means
+	// no limit.
+	MaxTxInputs int
+	// Maximum number of outputs a tx issued to this node may produce. 0 means
+	// no limit.
+	MaxTxOutputs int
+
+	// Rebuild the status and UTXO indexes from the accepted txs on startup
+	Reindex bool
+
+	// Index the memos of accepted txs, so that txs can be searched by memo
+	IndexMemos bool
+
+	// Apply the shared memory operations of accepted txs in the background,
+	// rather than while accepting them
+	AsyncSideEffects bool
 }
 
 // New ...
 func (f *Factory) New(*snow.Context) (interface{}, error) {
 	return &VM{
-		creationTxFee: f.CreationFee,
-		txFee:         f.Fee,
+		creationTxFee:    f.CreationFee,
+		txFee:            f.Fee,
+		maxTxSize:        f.MaxTxSize,
+		maxTxInputs:      f.MaxTxInputs,
+		maxTxOutputs:     f.MaxTxOutputs,
+		reindex:          f.Reindex,
+		indexMemos:       f.IndexMemos,
+		asyncSideEffects: f.AsyncSideEffects,
 	}, nil
 }
@@ -0,0 +1,73 @@
+// (c) 2019-2020, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package avm
+
+import (
+	"math"
+	"sort"
+)
+
+const (
+	// Number of recent batches of txs used to measure congestion
+	feeStatsWindow = 128
+
+	// Percentile of recent batches targeted by congestion reports when no
+	// percentile is specified
+	defaultFeePercentile = 50
+)
+
+// feeStats tracks how full the recent batches of txs passed to the consensus
+// engine were. A batch is full when it has [batchSize] txs, which is when the
+// engine builds vertices as fast as txs can be issued.
+type feeStats struct {
+	// Fill ratio, in [0, 1], of each of the most recent batches. Once
+	// [feeStatsWindow] batches have been observed, [next] is the index of the
+	// oldest batch.
+	fills []float64
+	next  int
+}
+
+// Observe a batch of [numTxs] txs
+func (s *feeStats) Observe(numTxs int) {
+	fill := float64(numTxs) / batchSize
+	if fill > 1 {
+		fill = 1
+	}
+	if len(s.fills) < feeStatsWindow {
+		s.fills = append(s.fills, fill)
+		return
+	}
+	s.fills[s.next] = fill
+	s.next = (s.next + 1) % feeStatsWindow
+}
+
+// Fill returns the fill ratio of the recent batches at [percentile], which
+// must be in (0, 100]. Returns 0 if no batches have been observed.
+func (s *feeStats) Fill(percentile float64) float64 {
+	if len(s.fills) == 0 {
+		return 0
+	}
+	fills := make([]float64, len(s.fills))
+	copy(fills, s.fills)
+	sort.Float64s(fills)
+	index := int(math.Ceil(percentile/100*float64(len(fills)))) - 1
+	if index < 0 {
+		index = 0
+	}
+	return fills[index]
+}
+
+// congestion returns how congested tx issuance is, in [0, 1], based on how
+// full recent batches were at [percentile] and how full the txs waiting to be
+// batched are.
+func (vm *VM) congestion(percentile float64) float64 {
+	congestion := vm.feeStats.Fill(percentile)
+	if pending := float64(len(vm.txs)) / batchSize; pending > congestion {
+		congestion = pending
+	}
+	if congestion > 1 {
+		congestion = 1
+	}
+	return congestion
+}
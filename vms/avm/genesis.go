@@ -4,10 +4,17 @@
 package avm
 
 import (
+	"fmt"
+	"math"
 	"sort"
 	"strings"
 
+	"github.com/ava-labs/avalanchego/codec"
+	"github.com/ava-labs/avalanchego/codec/linearcodec"
+	"github.com/ava-labs/avalanchego/codec/reflectcodec"
 	"github.com/ava-labs/avalanchego/utils"
+	"github.com/ava-labs/avalanchego/utils/wrappers"
+	"github.com/ava-labs/avalanchego/vms/secp256k1fx"
 )
 
 // Genesis ...
@@ -35,3 +42,47 @@ type GenesisAsset struct {
 	Alias         string `serialize:"true"`
 	CreateAssetTx `serialize:"true"`
 }
+
+// MarshalGenesis returns the serialization of [g]. The assets of [g], and
+// their initial states, are sorted first, so the serialization doesn't depend
+// on the order they were added in.
+func MarshalGenesis(g *Genesis) ([]byte, error) {
+	manager, err := newGenesisCodec()
+	if err != nil {
+		return nil, err
+	}
+	for _, asset := range g.Txs {
+		for _, state := range asset.States {
+			state.Sort(manager)
+		}
+		asset.Sort()
+	}
+	g.Sort()
+
+	b, err := manager.Marshal(codecVersion, g)
+	if err != nil {
+		return nil, fmt.Errorf("problem marshaling genesis: %w", err)
+	}
+	return b, nil
+}
+
+// newGenesisCodec returns the codec that genesis states are built with
+func newGenesisCodec() (codec.Manager, error) {
+	c := linearcodec.New(reflectcodec.DefaultTagName, 1<<20)
+	manager := codec.NewManager(math.MaxUint32)
+	errs := wrappers.Errs{}
+	errs.Add(
+		c.RegisterType(&BaseTx{}),
+		c.RegisterType(&CreateAssetTx{}),
+		c.RegisterType(&OperationTx{}),
+		c.RegisterType(&ImportTx{}),
+		c.RegisterType(&ExportTx{}),
+		c.RegisterType(&secp256k1fx.TransferInput{}),
+		c.RegisterType(&secp256k1fx.MintOutput{}),
+		c.RegisterType(&secp256k1fx.TransferOutput{}),
+		c.RegisterType(&secp256k1fx.MintOperation{}),
+		c.RegisterType(&secp256k1fx.Credential{}),
+		manager.RegisterCodec(codecVersion, c),
+	)
+	return manager, errs.Err
+}
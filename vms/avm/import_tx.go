@@ -15,7 +15,8 @@ import (
 )
 
 var (
-	errNoImportInputs = errors.New("no import inputs")
+	errNoImportInputs  = errors.New("no import inputs")
+	errAtomicUTXOSpent = errors.New("imported UTXO was already spent")
 )
 
 // ImportTx is a transaction that imports an asset from another blockchain.
@@ -113,6 +114,11 @@ func (t *ImportTx) SemanticVerify(vm *VM, tx UnsignedTx, creds []verify.Verifiab
 	utxoIDs := make([][]byte, len(t.ImportedIns))
 	for i, in := range t.ImportedIns {
 		inputID := in.UTXOID.InputID()
+		// The UTXO may have been spent by an accepted tx whose atomic
+		// operations haven't been applied yet
+		if vm.sideEffects.PendingRemoval(t.SourceChain, inputID) {
+			return errAtomicUTXOSpent
+		}
 		utxoIDs[i] = inputID[:]
 	}
 	allUTXOBytes, err := vm.ctx.SharedMemory.Get(t.SourceChain, utxoIDs)
@@ -136,12 +142,24 @@ func (t *ImportTx) SemanticVerify(vm *VM, tx UnsignedTx, creds []verify.Verifiab
 	return nil
 }
 
-// ExecuteWithSideEffects writes the batch with any additional side effects
-func (t *ImportTx) ExecuteWithSideEffects(vm *VM, batch database.Batch) error {
+// AtomicOperations returns the imported UTXOs to remove from shared memory
+func (t *ImportTx) AtomicOperations(*VM) (*atomicOperations, error) {
 	utxoIDs := make([][]byte, len(t.ImportedIns))
 	for i, in := range t.ImportedIns {
 		inputID := in.UTXOID.InputID()
 		utxoIDs[i] = inputID[:]
 	}
-	return vm.ctx.SharedMemory.Remove(t.SourceChain, utxoIDs, batch)
+	return &atomicOperations{
+		PeerChainID: t.SourceChain,
+		Removes:     utxoIDs,
+	}, nil
+}
+
+// ExecuteWithSideEffects writes the batch with any additional side effects
+func (t *ImportTx) ExecuteWithSideEffects(vm *VM, batch database.Batch) error {
+	ops, err := t.AtomicOperations(vm)
+	if err != nil {
+		return err
+	}
+	return ops.Apply(vm.ctx.SharedMemory, batch)
 }
@@ -0,0 +1,104 @@
+// (c) 2019-2020, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package avm
+
+import (
+	"errors"
+
+	"github.com/ava-labs/avalanchego/ids"
+)
+
+const (
+	// Maximum size, in bytes, of an indexed memo. Longer memos aren't indexed.
+	maxIndexedMemoSize = 64
+
+	// Maximum number of txs returned by a memo search
+	maxMemoSearchResults = 1024
+)
+
+var (
+	errMemoIndexDisabled = errors.New("memo index is disabled")
+	errMemoTooLong       = errors.New("memo is longer than the longest indexed memo")
+)
+
+// MemoMatch is a tx whose memo matched a memo search
+type MemoMatch struct {
+	Memo []byte
+	TxID ids.ID
+}
+
+// txMemo returns the memo of [utx], or nil if it doesn't have one
+func txMemo(utx UnsignedTx) []byte {
+	switch utx := utx.(type) {
+	case *BaseTx:
+		return utx.Memo
+	case *CreateAssetTx:
+		return utx.Memo
+	case *OperationTx:
+		return utx.Memo
+	case *ImportTx:
+		return utx.Memo
+	case *ExportTx:
+		return utx.Memo
+	default:
+		return nil
+	}
+}
+
+// indexMemo adds the accepted tx [txID] to the memo index, if the index is
+// enabled and the tx has a memo short enough to be indexed
+func (vm *VM) indexMemo(txID ids.ID, utx UnsignedTx) error {
+	memo := txMemo(utx)
+	if !vm.indexMemos || len(memo) == 0 || len(memo) > maxIndexedMemoSize {
+		return nil
+	}
+	return vm.state.AddMemo(memo, txID)
+}
+
+// SearchMemos returns the accepted txs whose memo is [memo] or, if [prefix] is
+// true, starts with [memo]. Matches are ordered by the length of their memo,
+// then by their memo, then by their tx ID. Only the matches after
+// [startMemo], [startTxID] are returned, so a search can be resumed from its
+// last match. Returns at most [limit] matches. If [limit] <= 0 or [limit] >
+// maxMemoSearchResults, it is set to [maxMemoSearchResults].
+func (vm *VM) SearchMemos(
+	memo []byte,
+	prefix bool,
+	startMemo []byte,
+	startTxID ids.ID,
+	limit int,
+) ([]MemoMatch, error) {
+	if !vm.indexMemos {
+		return nil, errMemoIndexDisabled
+	}
+	if len(memo) > maxIndexedMemoSize {
+		return nil, errMemoTooLong
+	}
+	if limit <= 0 || limit > maxMemoSearchResults {
+		limit = maxMemoSearchResults
+	}
+
+	maxSize := len(memo)
+	if prefix {
+		maxSize = maxIndexedMemoSize
+	}
+	matches := []MemoMatch(nil)
+	for size := len(memo); size <= maxSize && len(matches) < limit; size++ {
+		var start []byte
+		switch {
+		case len(startMemo) > size:
+			// The search was resumed after the memos of this size
+			continue
+		case len(startMemo) == size:
+			start = startMemo
+		}
+
+		sizeMatches, err := vm.snapshot.Memos(memo, size, start, startTxID, limit-len(matches))
+		if err != nil {
+			return nil, err
+		}
+		matches = append(matches, sizeMatches...)
+	}
+	return matches, nil
+}
@@ -0,0 +1,76 @@
+// (c) 2019-2020, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package avm
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/ava-labs/avalanchego/ids"
+	"github.com/ava-labs/avalanchego/vms/components/avax"
+)
+
+func TestSearchMemos(t *testing.T) {
+	assert := assert.New(t)
+
+	_, vm, s, _ := setup(t)
+	defer func() {
+		if err := vm.Shutdown(); err != nil {
+			t.Fatal(err)
+		}
+		vm.ctx.Lock.Unlock()
+	}()
+
+	_, err := vm.SearchMemos([]byte("deposit"), false, nil, ids.Empty, 0)
+	assert.True(errors.Is(err, errMemoIndexDisabled))
+
+	vm.indexMemos = true
+	txIDs := []ids.ID{ids.GenerateTestID(), ids.GenerateTestID(), ids.GenerateTestID(), ids.GenerateTestID()}
+	txs := []*BaseTx{
+		{BaseTx: avax.BaseTx{Memo: []byte("deposit-1")}},
+		{BaseTx: avax.BaseTx{Memo: []byte("deposit-12")}},
+		{BaseTx: avax.BaseTx{Memo: []byte("deposit-1")}},
+		{BaseTx: avax.BaseTx{Memo: make([]byte, maxIndexedMemoSize+1)}},
+	}
+	for i, tx := range txs {
+		assert.NoError(vm.indexMemo(txIDs[i], tx))
+	}
+	assert.NoError(vm.db.Commit())
+
+	// Exact matches don't include longer memos
+	matches, err := vm.SearchMemos([]byte("deposit-1"), false, nil, ids.Empty, 0)
+	assert.NoError(err)
+	assert.Len(matches, 2)
+	assert.ElementsMatch([]ids.ID{txIDs[0], txIDs[2]}, []ids.ID{matches[0].TxID, matches[1].TxID})
+
+	// Prefix matches are ordered by the length of the memo
+	matches, err = vm.SearchMemos([]byte("deposit-1"), true, nil, ids.Empty, 0)
+	assert.NoError(err)
+	assert.Len(matches, 3)
+	assert.Equal(txIDs[1], matches[2].TxID)
+	assert.Equal([]byte("deposit-12"), matches[2].Memo)
+
+	// A search resumes after its last match
+	first, err := vm.SearchMemos([]byte("deposit"), true, nil, ids.Empty, 2)
+	assert.NoError(err)
+	assert.Len(first, 2)
+	rest, err := vm.SearchMemos([]byte("deposit"), true, first[1].Memo, first[1].TxID, 2)
+	assert.NoError(err)
+	assert.Equal(matches, append(first, rest...))
+
+	// Memos that are too long aren't indexed
+	_, err = vm.SearchMemos(make([]byte, maxIndexedMemoSize+1), false, nil, ids.Empty, 0)
+	assert.True(errors.Is(err, errMemoTooLong))
+	matches, err = vm.SearchMemos(nil, true, nil, ids.Empty, 0)
+	assert.NoError(err)
+	assert.Len(matches, 3)
+
+	reply := &SearchMemosReply{}
+	err = s.SearchMemos(nil, &SearchMemosArgs{Memo: "deposit-12"}, reply)
+	assert.NoError(err)
+	assert.Equal(uint64(1), uint64(reply.NumFetched))
+	assert.Equal(MemoIndex{Memo: "deposit-12", TxID: txIDs[1]}, reply.EndIndex)
+}
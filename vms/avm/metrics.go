@@ -24,6 +24,8 @@ type metrics struct {
 	numPendingCalls, numParseCalls, numGetCalls prometheus.Counter
 
 	numTxRefreshes, numTxRefreshHits, numTxRefreshMisses prometheus.Counter
+
+	numVerificationCacheHits, numVerificationCacheMisses prometheus.Counter
 }
 
 func (m *metrics) Initialize(
@@ -53,6 +55,17 @@ func (m *metrics) Initialize(
 		Help:      "Number of times unique txs have not been unique and weren't cached",
 	})
 
+	m.numVerificationCacheHits = prometheus.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "verification_cache_hits",
+		Help:      "Number of times a tx's verification result was reused",
+	})
+	m.numVerificationCacheMisses = prometheus.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "verification_cache_misses",
+		Help:      "Number of times a tx had to be semantically verified",
+	})
+
 	errs := wrappers.Errs{}
 	errs.Add(
 		registerer.Register(m.numBootstrappingCalls),
@@ -64,6 +77,8 @@ func (m *metrics) Initialize(
 		registerer.Register(m.numTxRefreshes),
 		registerer.Register(m.numTxRefreshHits),
 		registerer.Register(m.numTxRefreshMisses),
+		registerer.Register(m.numVerificationCacheHits),
+		registerer.Register(m.numVerificationCacheMisses),
 	)
 	return errs.Err
 }
@@ -4,9 +4,15 @@
 package avm
 
 import (
+	"bytes"
+	"encoding/binary"
+
 	"github.com/ava-labs/avalanchego/cache"
+	"github.com/ava-labs/avalanchego/database"
+	"github.com/ava-labs/avalanchego/database/prefixdb"
 	"github.com/ava-labs/avalanchego/ids"
 	"github.com/ava-labs/avalanchego/snow/choices"
+	"github.com/ava-labs/avalanchego/snow/consensus/snowstorm"
 	"github.com/ava-labs/avalanchego/vms/components/avax"
 )
 
@@ -15,10 +21,29 @@ const (
 	utxoID
 	txStatusID
 	dbInitializedID
+	acceptedTxsID
+	acceptedTxCountID
+	reindexProgressID
+	sideEffectIntentsID
+	legacyAcceptedTxsID
+	legacyAcceptedTxCountID
+	reindexClearingID
+	memoIndexID
+	txRejectionID
+	assetPausedID
 )
 
 var (
-	dbInitialized = ids.Empty.Prefix(dbInitializedID)
+	dbInitialized     = ids.Empty.Prefix(dbInitializedID)
+	acceptedTxs       = ids.Empty.Prefix(acceptedTxsID)
+	acceptedTxCount   = ids.Empty.Prefix(acceptedTxCountID)
+	reindexProgress   = ids.Empty.Prefix(reindexProgressID)
+	sideEffectIntents = ids.Empty.Prefix(sideEffectIntentsID)
+
+	legacyAcceptedTxs     = ids.Empty.Prefix(legacyAcceptedTxsID)
+	legacyAcceptedTxCount = ids.Empty.Prefix(legacyAcceptedTxCountID)
+	reindexClearing       = ids.Empty.Prefix(reindexClearingID)
+	memoIndex             = ids.Empty.Prefix(memoIndexID)
 )
 
 // prefixedState wraps a state object. By prefixing the state, there will be no
@@ -28,6 +53,14 @@ type prefixedState struct {
 
 	tx, utxo, txStatus cache.Cacher
 	uniqueTx           cache.Deduplicator
+
+	// Address --> *addressBalances. Evicted whenever a UTXO that references
+	// the address is spent or created. Only used by the VM's snapshot.
+	balances cache.Cacher
+
+	// Addresses referenced by UTXOs that were spent or created since the
+	// VM's snapshot was last advanced
+	staleBalances ids.ShortSet
 }
 
 // UniqueTx de-duplicates the transaction.
@@ -103,6 +136,7 @@ func (s *prefixedState) removeUTXO(addrs [][]byte, utxoID ids.ID) error {
 			return err
 		}
 	}
+	s.evictBalances(addrs)
 	return nil
 }
 
@@ -127,5 +161,282 @@ func (s *prefixedState) addUTXO(addrs [][]byte, utxoID ids.ID) error {
 			return err
 		}
 	}
+	s.evictBalances(addrs)
 	return nil
 }
+
+// evictBalances marks the balances of [addrs] as stale. They're evicted from
+// the VM's snapshot once the change is committed.
+func (s *prefixedState) evictBalances(addrs [][]byte) {
+	for _, addrBytes := range addrs {
+		if addr, err := ids.ToShortID(addrBytes); err == nil {
+			s.staleBalances.Add(addr)
+		}
+	}
+}
+
+// AcceptedTxCount returns the number of txs that have been added to the
+// accepted tx index.
+func (s *prefixedState) AcceptedTxCount() (uint64, error) {
+	return s.getUInt64(acceptedTxCount)
+}
+
+// AcceptedTxID returns the ID of the [index]'th tx added to the accepted tx
+// index.
+func (s *prefixedState) AcceptedTxID(index uint64) (ids.ID, error) {
+	db := prefixdb.NewNested(acceptedTxs[:], s.state.DB)
+	txIDBytes, err := db.Get(uint64ToBytes(index))
+	if err != nil {
+		return ids.ID{}, err
+	}
+	return ids.ToID(txIDBytes)
+}
+
+// AddAcceptedTx appends [txID] to the accepted tx index.
+func (s *prefixedState) AddAcceptedTx(txID ids.ID) error {
+	count, err := s.AcceptedTxCount()
+	if err != nil {
+		return err
+	}
+	db := prefixdb.NewNested(acceptedTxs[:], s.state.DB)
+	if err := db.Put(uint64ToBytes(count), txID[:]); err != nil {
+		return err
+	}
+	return s.state.DB.Put(acceptedTxCount[:], uint64ToBytes(count+1))
+}
+
+// LegacyAcceptedTxCount returns the number of txs that were accepted before the
+// accepted tx index was introduced. If those txs haven't been indexed yet,
+// false is returned.
+func (s *prefixedState) LegacyAcceptedTxCount() (uint64, bool, error) {
+	has, err := s.state.DB.Has(legacyAcceptedTxCount[:])
+	if err != nil || !has {
+		return 0, false, err
+	}
+	count, err := s.getUInt64(legacyAcceptedTxCount)
+	return count, true, err
+}
+
+// LegacyAcceptedTxID returns the ID of the [index]'th tx that was accepted
+// before the accepted tx index was introduced.
+func (s *prefixedState) LegacyAcceptedTxID(index uint64) (ids.ID, error) {
+	db := prefixdb.NewNested(legacyAcceptedTxs[:], s.state.DB)
+	txIDBytes, err := db.Get(uint64ToBytes(index))
+	if err != nil {
+		return ids.ID{}, err
+	}
+	return ids.ToID(txIDBytes)
+}
+
+// SetLegacyAcceptedTxs records [txIDs] as the txs, in the order they were
+// accepted, that were accepted before the accepted tx index was introduced.
+func (s *prefixedState) SetLegacyAcceptedTxs(txIDs []ids.ID) error {
+	db := prefixdb.NewNested(legacyAcceptedTxs[:], s.state.DB)
+	for i, txID := range txIDs {
+		txID := txID // The database may retain the value it's given
+		if err := db.Put(uint64ToBytes(uint64(i)), txID[:]); err != nil {
+			return err
+		}
+	}
+	return s.state.DB.Put(legacyAcceptedTxCount[:], uint64ToBytes(uint64(len(txIDs))))
+}
+
+// ReindexClearing returns true if a reindex was interrupted while clearing
+// the indexes.
+func (s *prefixedState) ReindexClearing() (bool, error) {
+	return s.state.DB.Has(reindexClearing[:])
+}
+
+// SetReindexClearing records whether a reindex is clearing the indexes.
+func (s *prefixedState) SetReindexClearing(clearing bool) error {
+	if !clearing {
+		return s.state.DB.Delete(reindexClearing[:])
+	}
+	return s.state.DB.Put(reindexClearing[:], nil)
+}
+
+// ReindexProgress returns the index of the next accepted tx to be replayed by
+// an interrupted reindex. If no reindex is in progress, false is returned.
+func (s *prefixedState) ReindexProgress() (uint64, bool, error) {
+	has, err := s.state.DB.Has(reindexProgress[:])
+	if err != nil || !has {
+		return 0, false, err
+	}
+	progress, err := s.getUInt64(reindexProgress)
+	return progress, true, err
+}
+
+// SetReindexProgress records that the accepted txs before [index] have been
+// replayed.
+func (s *prefixedState) SetReindexProgress(index uint64) error {
+	return s.state.DB.Put(reindexProgress[:], uint64ToBytes(index))
+}
+
+// DeleteReindexProgress records that no reindex is in progress.
+func (s *prefixedState) DeleteReindexProgress() error {
+	return s.state.DB.Delete(reindexProgress[:])
+}
+
+// AddSideEffectIntent records that the atomic operations of [txID], which has
+// accepted tx index [index], haven't been applied yet.
+func (s *prefixedState) AddSideEffectIntent(index uint64, txID ids.ID) error {
+	db := prefixdb.NewNested(sideEffectIntents[:], s.state.DB)
+	return db.Put(uint64ToBytes(index), txID[:])
+}
+
+// SideEffectIntents returns the accepted tx indices and IDs of the txs whose
+// atomic operations haven't been applied yet, in the order they were accepted.
+func (s *prefixedState) SideEffectIntents() ([]uint64, []ids.ID, error) {
+	iter := prefixdb.NewNested(sideEffectIntents[:], s.state.DB).NewIterator()
+	defer iter.Release()
+
+	indices := []uint64(nil)
+	txIDs := []ids.ID(nil)
+	for iter.Next() {
+		if len(iter.Key()) != 8 {
+			return nil, nil, errWrongUInt64Length
+		}
+		txID, err := ids.ToID(iter.Value())
+		if err != nil {
+			return nil, nil, err
+		}
+		indices = append(indices, binary.BigEndian.Uint64(iter.Key()))
+		txIDs = append(txIDs, txID)
+	}
+	return indices, txIDs, iter.Error()
+}
+
+// Rejection returns why the tx [txID] was rejected. If the reason wasn't
+// recorded, false is returned.
+func (s *prefixedState) Rejection(txID ids.ID) (snowstorm.Rejection, bool, error) {
+	key := txID.Prefix(txRejectionID)
+	bytes, err := s.state.DB.Get(key[:])
+	switch {
+	case err == database.ErrNotFound:
+		return snowstorm.Rejection{}, false, nil
+	case err != nil:
+		return snowstorm.Rejection{}, false, err
+	case len(bytes) != 1+len(ids.ID{}):
+		return snowstorm.Rejection{}, false, errWrongRejectionLength
+	}
+	cause, err := ids.ToID(bytes[1:])
+	return snowstorm.Rejection{
+		Cause: snowstorm.RejectionCause(bytes[0]),
+		TxID:  cause,
+	}, true, err
+}
+
+// SetRejection records that the tx [txID] was rejected because of [reason].
+func (s *prefixedState) SetRejection(txID ids.ID, reason snowstorm.Rejection) error {
+	key := txID.Prefix(txRejectionID)
+	bytes := make([]byte, 0, 1+len(reason.TxID))
+	bytes = append(bytes, byte(reason.Cause))
+	bytes = append(bytes, reason.TxID[:]...)
+	return s.state.DB.Put(key[:], bytes)
+}
+
+// DeleteRejection removes the reason that the tx [txID] was rejected.
+func (s *prefixedState) DeleteRejection(txID ids.ID) error {
+	key := txID.Prefix(txRejectionID)
+	return s.state.DB.Delete(key[:])
+}
+
+// AssetPaused returns true if transfers of the asset [assetID] are paused.
+func (s *prefixedState) AssetPaused(assetID ids.ID) (bool, error) {
+	key := assetID.Prefix(assetPausedID)
+	return s.state.DB.Has(key[:])
+}
+
+// SetAssetPaused records whether transfers of the asset [assetID] are paused.
+func (s *prefixedState) SetAssetPaused(assetID ids.ID, paused bool) error {
+	key := assetID.Prefix(assetPausedID)
+	if !paused {
+		return s.state.DB.Delete(key[:])
+	}
+	return s.state.DB.Put(key[:], nil)
+}
+
+// AddMemo adds the accepted tx [txID], whose memo is [memo], to the memo
+// index. [memo] must be at most 255 bytes.
+func (s *prefixedState) AddMemo(memo []byte, txID ids.ID) error {
+	db := prefixdb.NewNested(memoIndex[:], s.state.DB)
+	return db.Put(memoKey(memo, txID), nil)
+}
+
+// RemoveMemo removes the tx [txID], whose memo is [memo], from the memo index.
+func (s *prefixedState) RemoveMemo(memo []byte, txID ids.ID) error {
+	db := prefixdb.NewNested(memoIndex[:], s.state.DB)
+	return db.Delete(memoKey(memo, txID))
+}
+
+// Memos returns the txs in the memo index whose memo is [size] bytes long and
+// starts with [prefix], ordered by memo and then by tx ID. If [startMemo] is
+// non-nil, only the txs after [startMemo], [startTxID] are returned. Returns
+// at most [limit] txs.
+func (s *prefixedState) Memos(
+	prefix []byte,
+	size int,
+	startMemo []byte,
+	startTxID ids.ID,
+	limit int,
+) ([]MemoMatch, error) {
+	keyPrefix := append([]byte{byte(size)}, prefix...)
+	start := keyPrefix
+	if startMemo != nil {
+		start = memoKey(startMemo, startTxID)
+	}
+
+	iter := prefixdb.NewNested(memoIndex[:], s.state.DB).NewIteratorWithStartAndPrefix(start, keyPrefix)
+	defer iter.Release()
+
+	matches := []MemoMatch(nil)
+	for len(matches) < limit && iter.Next() {
+		key := iter.Key()
+		if startMemo != nil && bytes.Equal(key, start) {
+			continue
+		}
+		if len(key) != 1+size+len(ids.ID{}) {
+			return nil, errWrongMemoKeyLength
+		}
+		txID, err := ids.ToID(key[1+size:])
+		if err != nil {
+			return nil, err
+		}
+		matches = append(matches, MemoMatch{
+			Memo: append([]byte(nil), key[1:1+size]...),
+			TxID: txID,
+		})
+	}
+	return matches, iter.Error()
+}
+
+// getUInt64 returns the uint64 stored at [key], or 0 if there isn't one.
+func (s *prefixedState) getUInt64(key ids.ID) (uint64, error) {
+	bytes, err := s.state.DB.Get(key[:])
+	switch {
+	case err == database.ErrNotFound:
+		return 0, nil
+	case err != nil:
+		return 0, err
+	case len(bytes) != 8:
+		return 0, errWrongUInt64Length
+	default:
+		return binary.BigEndian.Uint64(bytes), nil
+	}
+}
+
+// memoKey returns the key of the tx [txID], whose memo is [memo], in the memo
+// index. Keys are prefixed by the length of the memo, so that the txs with a
+// given memo are contiguous.
+func memoKey(memo []byte, txID ids.ID) []byte {
+	key := make([]byte, 0, 1+len(memo)+len(txID))
+	key = append(key, byte(len(memo)))
+	key = append(key, memo...)
+	return append(key, txID[:]...)
+}
+
+func uint64ToBytes(n uint64) []byte {
+	bytes := make([]byte, 8)
+	binary.BigEndian.PutUint64(bytes, n)
+	return bytes
+}
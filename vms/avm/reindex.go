@@ -0,0 +1,324 @@
+// (c) 2019-2020, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package avm
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+
+	"github.com/ava-labs/avalanchego/ids"
+	"github.com/ava-labs/avalanchego/snow/choices"
+	"github.com/ava-labs/avalanchego/utils/hashing"
+	"github.com/ava-labs/avalanchego/vms/components/avax"
+)
+
+const (
+	// Number of txs to clear or replay between commits of the reindex's
+	// progress
+	reindexBatchSize = 1024
+)
+
+var errCyclicAcceptedTxs = errors.New("accepted txs have cyclic dependencies")
+
+// reindexAcceptedTxs rebuilds the tx status and UTXO indexes, and the memo
+// index if it's enabled, from the accepted txs.
+//
+// First, the status of every stored tx and every UTXO produced by a stored tx
+// are removed from the indexes, so that stale or corrupt entries don't
+// survive the reindex. The statuses of rejected txs aren't restored.
+//
+// Then, the accepted txs are replayed in the order they were accepted. The
+// txs accepted before the accepted tx index was introduced are replayed
+// first, followed by the txs in the accepted tx index.
+//
+// The progress of the reindex is committed periodically, so that an
+// interrupted reindex resumes where it left off rather than starting over.
+// Replaying a tx only performs absolute writes, so replaying a tx more than
+// once is safe.
+func (vm *VM) reindexAcceptedTxs() error {
+	start, reindexing, err := vm.state.ReindexProgress()
+	if err != nil {
+		return err
+	}
+	if !reindexing {
+		if err := vm.clearIndexes(); err != nil {
+			return fmt.Errorf("couldn't clear the indexes: %w", err)
+		}
+	}
+
+	legacyCount, _, err := vm.state.LegacyAcceptedTxCount()
+	if err != nil {
+		return err
+	}
+	indexedCount, err := vm.state.AcceptedTxCount()
+	if err != nil {
+		return err
+	}
+	count := legacyCount + indexedCount
+
+	vm.ctx.Log.Info("reindexing %d accepted txs, starting at %d", count, start)
+	for index := start; index < count; index++ {
+		var txID ids.ID
+		if index < legacyCount {
+			txID, err = vm.state.LegacyAcceptedTxID(index)
+		} else {
+			txID, err = vm.state.AcceptedTxID(index - legacyCount)
+		}
+		if err != nil {
+			return fmt.Errorf("couldn't get accepted tx %d: %w", index, err)
+		}
+		if err := vm.replayAcceptedTx(txID); err != nil {
+			return fmt.Errorf("couldn't replay accepted tx %s: %w", txID, err)
+		}
+
+		if replayed := index + 1; replayed%reindexBatchSize == 0 {
+			if err := vm.state.SetReindexProgress(replayed); err != nil {
+				return err
+			}
+			if err := vm.db.Commit(); err != nil {
+				return err
+			}
+			vm.ctx.Log.Info("reindexed %d/%d accepted txs", replayed, count)
+		}
+	}
+
+	if err := vm.state.DeleteReindexProgress(); err != nil {
+		return err
+	}
+	if err := vm.db.Commit(); err != nil {
+		return err
+	}
+	vm.ctx.Log.Info("finished reindexing %d accepted txs", count)
+	return nil
+}
+
+// clearIndexes removes the status of every stored tx, and every UTXO produced
+// by a stored tx, from the indexes. Once the indexes are cleared, the reindex
+// progress is set to replay every accepted tx.
+func (vm *VM) clearIndexes() error {
+	txIDs, err := vm.storedTxIDs()
+	if err != nil {
+		return err
+	}
+
+	// The statuses are about to be cleared, so the txs that were accepted
+	// before the accepted tx index was introduced must be recorded first
+	if _, indexed, err := vm.state.LegacyAcceptedTxCount(); err != nil {
+		return err
+	} else if !indexed {
+		if err := vm.indexLegacyAcceptedTxs(txIDs); err != nil {
+			return fmt.Errorf("couldn't index legacy accepted txs: %w", err)
+		}
+	}
+
+	if err := vm.state.SetReindexClearing(true); err != nil {
+		return err
+	}
+	if err := vm.db.Commit(); err != nil {
+		return err
+	}
+
+	vm.ctx.Log.Info("clearing the indexes of %d stored txs", len(txIDs))
+	for i, txID := range txIDs {
+		if err := vm.clearTx(txID); err != nil {
+			return fmt.Errorf("couldn't clear tx %s: %w", txID, err)
+		}
+
+		if cleared := i + 1; cleared%reindexBatchSize == 0 {
+			if err := vm.db.Commit(); err != nil {
+				return err
+			}
+			vm.ctx.Log.Info("cleared %d/%d stored txs", cleared, len(txIDs))
+		}
+	}
+
+	if err := vm.state.SetReindexClearing(false); err != nil {
+		return err
+	}
+	if err := vm.state.SetReindexProgress(0); err != nil {
+		return err
+	}
+	return vm.db.Commit()
+}
+
+// clearTx removes the status of [txID], and the UTXOs it produced, from the
+// indexes
+func (vm *VM) clearTx(txID ids.ID) error {
+	tx, err := vm.state.Tx(txID)
+	if err != nil {
+		return err
+	}
+	if err := vm.state.SetStatus(txID, choices.Unknown); err != nil {
+		return err
+	}
+	if err := vm.state.DeleteRejection(txID); err != nil {
+		return err
+	}
+	if memo := txMemo(tx.UnsignedTx); len(memo) != 0 && len(memo) <= maxIndexedMemoSize {
+		if err := vm.state.RemoveMemo(memo, txID); err != nil {
+			return err
+		}
+	}
+	for _, utxo := range tx.UTXOs() {
+		utxoID := utxo.InputID()
+		if err := vm.state.SetUTXO(utxoID, nil); err != nil {
+			return err
+		}
+		if addressable, ok := utxo.Out.(avax.Addressable); ok {
+			if err := vm.state.removeUTXO(addressable.Addresses(), utxoID); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// storedTxIDs returns the IDs of every tx in the database. Txs are keyed by
+// their prefixed ID, which is derived from the hash of their bytes, so every
+// entry whose key matches the hash of its value is a tx.
+func (vm *VM) storedTxIDs() ([]ids.ID, error) {
+	iter := vm.db.NewIterator()
+	defer iter.Release()
+
+	txIDs := []ids.ID(nil)
+	for iter.Next() {
+		id := ids.ID(hashing.ComputeHash256Array(iter.Value()))
+		if key := id.Prefix(txID); bytes.Equal(iter.Key(), key[:]) {
+			txIDs = append(txIDs, id)
+		}
+	}
+	return txIDs, iter.Error()
+}
+
+// indexLegacyAcceptedTxs records the txs of [txIDs] that are accepted, but
+// aren't in the accepted tx index, as having been accepted before the index
+// was introduced. The txs are ordered so that each tx comes after the txs
+// that produced the UTXOs it consumes.
+func (vm *VM) indexLegacyAcceptedTxs(txIDs []ids.ID) error {
+	indexed := ids.Set{}
+	indexedCount, err := vm.state.AcceptedTxCount()
+	if err != nil {
+		return err
+	}
+	for index := uint64(0); index < indexedCount; index++ {
+		indexedTxID, err := vm.state.AcceptedTxID(index)
+		if err != nil {
+			return err
+		}
+		indexed.Add(indexedTxID)
+	}
+
+	legacy := ids.Set{}
+	for _, txID := range txIDs {
+		if indexed.Contains(txID) {
+			continue
+		}
+		if status, err := vm.state.Status(txID); err == nil && status == choices.Accepted {
+			legacy.Add(txID)
+		}
+	}
+
+	// Kahn's algorithm, where each tx depends on the legacy txs that produced
+	// its inputs
+	dependents := make(map[ids.ID][]ids.ID, legacy.Len())
+	numDependencies := make(map[ids.ID]int, legacy.Len())
+	for txID := range legacy {
+		tx, err := vm.state.Tx(txID)
+		if err != nil {
+			return err
+		}
+		dependencies := ids.Set{}
+		for _, in := range tx.InputUTXOs() {
+			if !in.Symbolic() && legacy.Contains(in.TxID) {
+				dependencies.Add(in.TxID)
+			}
+		}
+		for dependency := range dependencies {
+			dependents[dependency] = append(dependents[dependency], txID)
+		}
+		numDependencies[txID] = dependencies.Len()
+	}
+
+	ordered := make([]ids.ID, 0, legacy.Len())
+	for txID := range legacy {
+		if numDependencies[txID] == 0 {
+			ordered = append(ordered, txID)
+		}
+	}
+	for i := 0; i < len(ordered); i++ {
+		for _, dependent := range dependents[ordered[i]] {
+			if numDependencies[dependent]--; numDependencies[dependent] == 0 {
+				ordered = append(ordered, dependent)
+			}
+		}
+	}
+	if len(ordered) != legacy.Len() {
+		return errCyclicAcceptedTxs
+	}
+
+	vm.ctx.Log.Info("indexing %d txs accepted before the accepted tx index was introduced", len(ordered))
+	if err := vm.state.SetLegacyAcceptedTxs(ordered); err != nil {
+		return err
+	}
+	return vm.db.Commit()
+}
+
+// replayAcceptedTx re-applies the state transition of the accepted tx [txID]
+func (vm *VM) replayAcceptedTx(txID ids.ID) error {
+	tx, err := vm.state.Tx(txID)
+	if err != nil {
+		return err
+	}
+	if err := vm.state.SetStatus(txID, choices.Accepted); err != nil {
+		return err
+	}
+	if err := vm.indexMemo(txID, tx.UnsignedTx); err != nil {
+		return err
+	}
+
+	for _, in := range tx.InputUTXOs() {
+		if in.Symbolic() {
+			// If the UTXO is symbolic, it was never added to the UTXO set
+			continue
+		}
+		if err := vm.unindexSpentUTXO(in); err != nil {
+			return err
+		}
+	}
+
+	for _, utxo := range tx.UTXOs() {
+		if err := vm.state.FundUTXO(utxo); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// unindexSpentUTXO removes the UTXO referenced by [in] from the UTXO set. The
+// UTXO may have already been removed, so its addresses are looked up from the
+// tx that produced it.
+func (vm *VM) unindexSpentUTXO(in *avax.UTXOID) error {
+	utxoID := in.InputID()
+	if err := vm.state.SetUTXO(utxoID, nil); err != nil {
+		return err
+	}
+
+	producer, err := vm.state.Tx(in.TxID)
+	if err != nil {
+		vm.ctx.Log.Debug("couldn't find tx %s that produced utxo %s", in.TxID, utxoID)
+		return nil
+	}
+	for _, utxo := range producer.UTXOs() {
+		if utxo.InputID() != utxoID {
+			continue
+		}
+		addressable, ok := utxo.Out.(avax.Addressable)
+		if !ok {
+			return nil
+		}
+		return vm.state.removeUTXO(addressable.Addresses(), utxoID)
+	}
+	return nil
+}
@@ -0,0 +1,287 @@
+// (c) 2019-2020, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package avm
+
+import (
+	"testing"
+
+	"github.com/ava-labs/avalanchego/ids"
+	"github.com/ava-labs/avalanchego/snow/choices"
+	"github.com/ava-labs/avalanchego/utils/crypto"
+	"github.com/ava-labs/avalanchego/vms/components/avax"
+	"github.com/ava-labs/avalanchego/vms/secp256k1fx"
+)
+
+func TestReindexAcceptedTxs(t *testing.T) {
+	genesisBytes, _, vm, _ := GenesisVM(t)
+	ctx := vm.ctx
+	defer func() {
+		if err := vm.Shutdown(); err != nil {
+			t.Fatal(err)
+		}
+		ctx.Lock.Unlock()
+	}()
+
+	numGenesisTxs, err := vm.state.AcceptedTxCount()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if numGenesisTxs == 0 {
+		t.Fatalf("Should have indexed the genesis txs")
+	}
+
+	newTx := NewTx(t, genesisBytes, vm)
+	spentUTXOID := newTx.InputUTXOs()[0].InputID()
+	spentUTXO, err := vm.state.UTXO(spentUTXOID)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	parsedTx, err := vm.Parse(newTx.Bytes())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := parsedTx.Verify(); err != nil {
+		t.Fatal(err)
+	}
+	if err := parsedTx.Accept(); err != nil {
+		t.Fatal(err)
+	}
+
+	if count, err := vm.state.AcceptedTxCount(); err != nil {
+		t.Fatal(err)
+	} else if count != numGenesisTxs+1 {
+		t.Fatalf("Expected %d indexed txs but got %d", numGenesisTxs+1, count)
+	}
+	if txID, err := vm.state.AcceptedTxID(numGenesisTxs); err != nil {
+		t.Fatal(err)
+	} else if txID != newTx.ID() {
+		t.Fatalf("Should have indexed the accepted tx")
+	}
+
+	// Corrupt the indexes by restoring the spent UTXO and forgetting that the
+	// tx was accepted
+	corrupt := func() {
+		if err := vm.state.FundUTXO(spentUTXO); err != nil {
+			t.Fatal(err)
+		}
+		if err := vm.state.SetStatus(newTx.ID(), choices.Processing); err != nil {
+			t.Fatal(err)
+		}
+		if err := vm.db.Commit(); err != nil {
+			t.Fatal(err)
+		}
+	}
+	corrupt()
+
+	if err := vm.reindexAcceptedTxs(); err != nil {
+		t.Fatal(err)
+	}
+
+	if status, err := vm.state.Status(newTx.ID()); err != nil {
+		t.Fatal(err)
+	} else if status != choices.Accepted {
+		t.Fatalf("Should have restored the tx's status")
+	}
+	if _, err := vm.state.UTXO(spentUTXOID); err == nil {
+		t.Fatalf("Should have removed the spent UTXO")
+	}
+	addr := keys[0].PublicKey().Address()
+	utxoIDs, err := vm.state.Funds(addr.Bytes(), ids.Empty, 1024)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, utxoID := range utxoIDs {
+		if utxoID == spentUTXOID {
+			t.Fatalf("Should have removed the spent UTXO from the address index")
+		}
+	}
+	if _, reindexing, err := vm.state.ReindexProgress(); err != nil {
+		t.Fatal(err)
+	} else if reindexing {
+		t.Fatalf("Should have cleared the reindex progress")
+	}
+
+	// A reindex interrupted after replaying every tx should resume without
+	// replaying any of them
+	corrupt()
+	if err := vm.state.SetReindexProgress(numGenesisTxs + 1); err != nil {
+		t.Fatal(err)
+	}
+	if err := vm.reindexAcceptedTxs(); err != nil {
+		t.Fatal(err)
+	}
+	if status, err := vm.state.Status(newTx.ID()); err != nil {
+		t.Fatal(err)
+	} else if status != choices.Processing {
+		t.Fatalf("Shouldn't have replayed txs before the reindex progress")
+	}
+}
+
+// newTxWithOutput returns a tx that spends the genesis AVAX UTXO of keys[0]
+// and returns the change to keys[0]
+func newTxWithOutput(t *testing.T, genesisBytes []byte, vm *VM) *Tx {
+	avaxTx := GetAVAXTxFromGenesisTest(genesisBytes, t)
+
+	newTx := &Tx{UnsignedTx: &BaseTx{BaseTx: avax.BaseTx{
+		NetworkID:    networkID,
+		BlockchainID: chainID,
+		Outs: []*avax.TransferableOutput{{
+			Asset: avax.Asset{ID: avaxTx.ID()},
+			Out: &secp256k1fx.TransferOutput{
+				Amt: startBalance - vm.txFee,
+				OutputOwners: secp256k1fx.OutputOwners{
+					Threshold: 1,
+					Addrs:     []ids.ShortID{keys[0].PublicKey().Address()},
+				},
+			},
+		}},
+		Ins: []*avax.TransferableInput{{
+			UTXOID: avax.UTXOID{
+				TxID:        avaxTx.ID(),
+				OutputIndex: 2,
+			},
+			Asset: avax.Asset{ID: avaxTx.ID()},
+			In: &secp256k1fx.TransferInput{
+				Amt: startBalance,
+				Input: secp256k1fx.Input{
+					SigIndices: []uint32{
+						0,
+					},
+				},
+			},
+		}},
+	}}}
+	if err := newTx.SignSECP256K1Fx(vm.codec, [][]*crypto.PrivateKeySECP256K1R{{keys[0]}}); err != nil {
+		t.Fatal(err)
+	}
+	return newTx
+}
+
+func TestReindexClearsStaleEntries(t *testing.T) {
+	genesisBytes, _, vm, _ := GenesisVM(t)
+	ctx := vm.ctx
+	defer func() {
+		if err := vm.Shutdown(); err != nil {
+			t.Fatal(err)
+		}
+		ctx.Lock.Unlock()
+	}()
+
+	// A stored, but never accepted, tx whose status and UTXOs were indexed
+	newTx := newTxWithOutput(t, genesisBytes, vm)
+	if err := vm.state.SetTx(newTx.ID(), newTx); err != nil {
+		t.Fatal(err)
+	}
+	if err := vm.state.SetStatus(newTx.ID(), choices.Processing); err != nil {
+		t.Fatal(err)
+	}
+	staleUTXO := newTx.UTXOs()[0]
+	if err := vm.state.FundUTXO(staleUTXO); err != nil {
+		t.Fatal(err)
+	}
+	if err := vm.db.Commit(); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := vm.reindexAcceptedTxs(); err != nil {
+		t.Fatal(err)
+	}
+
+	if status, err := vm.state.Status(newTx.ID()); err == nil {
+		t.Fatalf("Should have cleared the status of the tx, but it's %s", status)
+	}
+	if _, err := vm.state.UTXO(staleUTXO.InputID()); err == nil {
+		t.Fatalf("Should have cleared the UTXO produced by the tx")
+	}
+	addr := keys[0].PublicKey().Address()
+	utxoIDs, err := vm.state.Funds(addr.Bytes(), ids.Empty, 1024)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, utxoID := range utxoIDs {
+		if utxoID == staleUTXO.InputID() {
+			t.Fatalf("Should have removed the UTXO from the address index")
+		}
+	}
+	if clearing, err := vm.state.ReindexClearing(); err != nil {
+		t.Fatal(err)
+	} else if clearing {
+		t.Fatalf("Should have finished clearing the indexes")
+	}
+}
+
+func TestReindexLegacyAcceptedTxs(t *testing.T) {
+	genesisBytes, _, vm, _ := GenesisVM(t)
+	ctx := vm.ctx
+	defer func() {
+		if err := vm.Shutdown(); err != nil {
+			t.Fatal(err)
+		}
+		ctx.Lock.Unlock()
+	}()
+
+	newTx := newTxWithOutput(t, genesisBytes, vm)
+	parsedTx, err := vm.Parse(newTx.Bytes())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := parsedTx.Verify(); err != nil {
+		t.Fatal(err)
+	}
+	if err := parsedTx.Accept(); err != nil {
+		t.Fatal(err)
+	}
+
+	// Simulate a database created before the accepted tx index was introduced
+	if err := vm.db.Delete(acceptedTxCount[:]); err != nil {
+		t.Fatal(err)
+	}
+	if err := vm.db.Delete(legacyAcceptedTxCount[:]); err != nil {
+		t.Fatal(err)
+	}
+	if err := vm.db.Commit(); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := vm.reindexAcceptedTxs(); err != nil {
+		t.Fatal(err)
+	}
+
+	legacyCount, indexed, err := vm.state.LegacyAcceptedTxCount()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !indexed {
+		t.Fatalf("Should have indexed the legacy accepted txs")
+	}
+	legacyTxIDs := ids.Set{}
+	for index := uint64(0); index < legacyCount; index++ {
+		txID, err := vm.state.LegacyAcceptedTxID(index)
+		if err != nil {
+			t.Fatal(err)
+		}
+		legacyTxIDs.Add(txID)
+	}
+	if !legacyTxIDs.Contains(newTx.ID()) {
+		t.Fatalf("Should have indexed the accepted tx as a legacy tx")
+	}
+	for _, in := range newTx.InputUTXOs() {
+		if !legacyTxIDs.Contains(in.TxID) {
+			t.Fatalf("Should have indexed the genesis tx as a legacy tx")
+		}
+	}
+
+	if status, err := vm.state.Status(newTx.ID()); err != nil {
+		t.Fatal(err)
+	} else if status != choices.Accepted {
+		t.Fatalf("Should have restored the tx's status")
+	}
+	if _, err := vm.state.UTXO(newTx.InputUTXOs()[0].InputID()); err == nil {
+		t.Fatalf("Should have removed the spent UTXO")
+	}
+	if _, err := vm.state.UTXO(newTx.UTXOs()[0].InputID()); err != nil {
+		t.Fatalf("Should have restored the produced UTXO: %s", err)
+	}
+}
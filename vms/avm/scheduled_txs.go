@@ -0,0 +1,136 @@
+// (c) 2019-2020, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package avm
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/ava-labs/avalanchego/vms/secp256k1fx"
+)
+
+const (
+	// maxScheduleHorizon is how far in the future the inputs of a tx may
+	// unlock for the tx to be held until they do
+	maxScheduleHorizon = 10 * time.Minute
+
+	// maxScheduledTxs is the maximum number of txs held until their inputs
+	// unlock
+	maxScheduledTxs = 1024
+)
+
+var (
+	errTooManyScheduledTxs = errors.New("too many txs are already scheduled")
+	errAlreadyScheduled    = errors.New("tx is already scheduled")
+)
+
+// scheduledTx is a tx held until the inputs it spends unlock
+type scheduledTx struct {
+	tx         *UniqueTx
+	unlockTime uint64
+}
+
+// unlockTime returns the time at which the last of the inputs of [tx] unlocks.
+// Inputs that can't be loaded, such as imported inputs, are ignored.
+func (vm *VM) unlockTime(tx *UniqueTx) uint64 {
+	unlockTime := uint64(0)
+	for _, utxoID := range tx.InputUTXOs() {
+		if utxoID.Symbolic() {
+			continue
+		}
+		utxo, err := vm.getUTXO(utxoID)
+		if err != nil {
+			continue
+		}
+
+		var locktime uint64
+		switch out := utxo.Out.(type) {
+		case *secp256k1fx.TransferOutput:
+			locktime = out.Locktime
+		case *secp256k1fx.MintOutput:
+			locktime = out.Locktime
+		}
+		if locktime > unlockTime {
+			unlockTime = locktime
+		}
+	}
+	return unlockTime
+}
+
+// verifyAt verifies [tx] as if the current time were [unlockTime], so that a tx
+// is only held if it will be valid once its inputs unlock
+func (vm *VM) verifyAt(tx *UniqueTx, unlockTime uint64) error {
+	clock := vm.clock
+	defer func() { vm.clock = clock }()
+
+	// The verification cache is bypassed, as the result only holds at
+	// [unlockTime]
+	vm.clock.Set(time.Unix(int64(unlockTime), 0))
+	return tx.Tx.SemanticVerify(vm, tx.UnsignedTx)
+}
+
+// scheduleTx holds [tx] until [unlockTime], when it's verified again and
+// issued if it's valid.
+func (vm *VM) scheduleTx(tx *UniqueTx, unlockTime uint64) error {
+	txID := tx.ID()
+	if _, exists := vm.scheduledTxs[txID]; exists {
+		return errAlreadyScheduled
+	}
+	if len(vm.scheduledTxs) >= maxScheduledTxs {
+		return fmt.Errorf("%w: %d", errTooManyScheduledTxs, maxScheduledTxs)
+	}
+
+	vm.scheduledTxs[txID] = &scheduledTx{
+		tx:         tx,
+		unlockTime: unlockTime,
+	}
+	vm.ctx.Log.Debug("scheduled tx %s to be issued at %d", txID, unlockTime)
+	vm.resetScheduleTimer()
+	return nil
+}
+
+// promoteScheduledTxs issues the scheduled txs whose inputs have unlocked.
+// Txs that are no longer valid once their inputs unlock are dropped.
+func (vm *VM) promoteScheduledTxs() {
+	now := vm.clock.Unix()
+	for txID, scheduled := range vm.scheduledTxs {
+		if scheduled.unlockTime > now {
+			continue
+		}
+		delete(vm.scheduledTxs, txID)
+
+		if err := vm.verifyNotPaused(scheduled.tx.UnsignedTx); err != nil {
+			vm.ctx.Log.Debug("dropping scheduled tx %s due to %s", txID, err)
+			continue
+		}
+		if err := scheduled.tx.verifyWithoutCacheWrites(); err != nil {
+			vm.ctx.Log.Debug("dropping scheduled tx %s due to %s", txID, err)
+			continue
+		}
+		vm.issueTx(scheduled.tx)
+	}
+	vm.resetScheduleTimer()
+}
+
+// resetScheduleTimer sets the schedule timer to fire when the next scheduled
+// tx unlocks
+func (vm *VM) resetScheduleTimer() {
+	if len(vm.scheduledTxs) == 0 {
+		vm.scheduleTimer.Cancel()
+		return
+	}
+
+	next := uint64(0)
+	for _, scheduled := range vm.scheduledTxs {
+		if next == 0 || scheduled.unlockTime < next {
+			next = scheduled.unlockTime
+		}
+	}
+	wait := time.Duration(0)
+	if now := vm.clock.Unix(); next > now {
+		wait = time.Duration(next-now) * time.Second
+	}
+	vm.scheduleTimer.SetTimeoutIn(wait)
+}
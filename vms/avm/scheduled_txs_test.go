@@ -0,0 +1,154 @@
+// (c) 2019-2020, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package avm
+
+import (
+	"testing"
+	"time"
+
+	"github.com/ava-labs/avalanchego/ids"
+	"github.com/ava-labs/avalanchego/utils/crypto"
+	"github.com/ava-labs/avalanchego/vms/components/avax"
+	"github.com/ava-labs/avalanchego/vms/secp256k1fx"
+)
+
+func TestScheduledTxs(t *testing.T) {
+	genesisBytes, _, vm, _ := GenesisVM(t)
+	ctx := vm.ctx
+	defer func() {
+		if err := vm.Shutdown(); err != nil {
+			t.Fatal(err)
+		}
+		ctx.Lock.Unlock()
+	}()
+
+	now := time.Unix(1000000, 0)
+	vm.clock.Set(now)
+
+	avaxTx := GetAVAXTxFromGenesisTest(genesisBytes, t)
+	addr := keys[0].PublicKey().Address()
+	lockedOut := func(amount uint64, locktime time.Time) *avax.TransferableOutput {
+		return &avax.TransferableOutput{
+			Asset: avax.Asset{ID: avaxTx.ID()},
+			Out: &secp256k1fx.TransferOutput{
+				Amt: amount,
+				OutputOwners: secp256k1fx.OutputOwners{
+					Locktime:  uint64(locktime.Unix()),
+					Threshold: 1,
+					Addrs:     []ids.ShortID{addr},
+				},
+			},
+		}
+	}
+
+	soonAmount := uint64(startBalance / 2)
+	laterAmount := startBalance - soonAmount - vm.txFee
+	soon := now.Add(time.Minute)
+	later := now.Add(maxScheduleHorizon + time.Minute)
+
+	outs := []*avax.TransferableOutput{
+		lockedOut(soonAmount, soon),
+		lockedOut(laterAmount, later),
+	}
+	avax.SortTransferableOutputs(outs, vm.codec)
+	lockingTx := &Tx{UnsignedTx: &BaseTx{BaseTx: avax.BaseTx{
+		NetworkID:    networkID,
+		BlockchainID: chainID,
+		Outs:         outs,
+		Ins: []*avax.TransferableInput{{
+			UTXOID: avax.UTXOID{
+				TxID:        avaxTx.ID(),
+				OutputIndex: 2,
+			},
+			Asset: avax.Asset{ID: avaxTx.ID()},
+			In: &secp256k1fx.TransferInput{
+				Amt:   startBalance,
+				Input: secp256k1fx.Input{SigIndices: []uint32{0}},
+			},
+		}},
+	}}}
+	if err := lockingTx.SignSECP256K1Fx(vm.codec, [][]*crypto.PrivateKeySECP256K1R{{keys[0]}}); err != nil {
+		t.Fatal(err)
+	}
+	parsedTx, err := vm.Parse(lockingTx.Bytes())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := parsedTx.Verify(); err != nil {
+		t.Fatal(err)
+	}
+	if err := parsedTx.Accept(); err != nil {
+		t.Fatal(err)
+	}
+
+	spend := func(amount uint64, key *crypto.PrivateKeySECP256K1R) *Tx {
+		var spent *avax.UTXO
+		for _, utxo := range lockingTx.UTXOs() {
+			if utxo.Out.(*secp256k1fx.TransferOutput).Amt == amount {
+				spent = utxo
+			}
+		}
+		tx := &Tx{UnsignedTx: &BaseTx{BaseTx: avax.BaseTx{
+			NetworkID:    networkID,
+			BlockchainID: chainID,
+			Ins: []*avax.TransferableInput{{
+				UTXOID: spent.UTXOID,
+				Asset:  avax.Asset{ID: avaxTx.ID()},
+				In: &secp256k1fx.TransferInput{
+					Amt:   amount,
+					Input: secp256k1fx.Input{SigIndices: []uint32{0}},
+				},
+			}},
+		}}}
+		if err := tx.SignSECP256K1Fx(vm.codec, [][]*crypto.PrivateKeySECP256K1R{{key}}); err != nil {
+			t.Fatal(err)
+		}
+		return tx
+	}
+
+	// Inputs that unlock too far in the future aren't held
+	if _, err := vm.IssueTx(spend(laterAmount, keys[0]).Bytes()); err == nil {
+		t.Fatalf("Should have refused a tx whose inputs unlock beyond the horizon")
+	}
+
+	// Txs that won't be valid once their inputs unlock aren't held
+	if _, err := vm.IssueTx(spend(soonAmount, keys[1]).Bytes()); err == nil {
+		t.Fatalf("Should have refused a tx with a bad signature")
+	}
+	if len(vm.scheduledTxs) != 0 {
+		t.Fatalf("Shouldn't have scheduled a tx with a bad signature")
+	}
+	if !vm.clock.Time().Equal(now) {
+		t.Fatalf("Should have restored the clock after verifying the tx")
+	}
+
+	soonTx := spend(soonAmount, keys[0])
+	txID, err := vm.IssueTx(soonTx.Bytes())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if txID != soonTx.ID() {
+		t.Fatalf("Issue Tx returned wrong TxID")
+	}
+	if len(vm.txs) != 0 {
+		t.Fatalf("Shouldn't have issued a tx whose inputs are locked")
+	}
+	if _, err := vm.IssueTx(soonTx.Bytes()); err == nil {
+		t.Fatalf("Shouldn't have scheduled a tx twice")
+	}
+
+	vm.promoteScheduledTxs()
+	if len(vm.txs) != 0 {
+		t.Fatalf("Shouldn't have issued a tx before its inputs unlocked")
+	}
+
+	vm.clock.Set(soon)
+	vm.promoteScheduledTxs()
+	if len(vm.txs) != 1 || vm.txs[0].ID() != soonTx.ID() {
+		t.Fatalf("Should have issued the tx once its inputs unlocked")
+	}
+	if len(vm.scheduledTxs) != 0 {
+		t.Fatalf("Should have removed the issued tx from the scheduled txs")
+	}
+}
@@ -6,7 +6,6 @@ package avm
 import (
 	"errors"
 	"fmt"
-	"math"
 	"net/http"
 	"strings"
 
@@ -31,6 +30,18 @@ const (
 
 	// Max number of addresses allowed for a single keystore user
 	maxKeystoreAddresses = 5000
+
+	// Max number of tx IDs that can be passed in as argument to GetTxStatuses
+	maxGetTxStatusesTxIDs = 1024
+
+	// APIVersion is the version of this API. It's incremented when methods are
+	// added, so that clients can tell which methods a node serves.
+	//
+	// Version 1 added getAPIVersion and getTxStatuses.
+	// Version 2 added estimateFee.
+	// Version 3 added searchMemos.
+	// Version 4 added getConflicts.
+	APIVersion = 4
 )
 
 var (
@@ -47,6 +58,9 @@ var (
 	errNilTxID                = errors.New("nil transaction ID")
 	errNoAddresses            = errors.New("no addresses provided")
 	errNoKeys                 = errors.New("from addresses have no keys or funds")
+	errTooManyTxIDs           = fmt.Errorf("number of tx IDs given exceeds maximum of %d", maxGetTxStatusesTxIDs)
+	errInvalidPercentile      = errors.New("percentile must be in (0, 100]")
+	errTxIDAndUTXOID          = errors.New("exactly one of txID and utxoID must be provided")
 )
 
 // Service defines the base service for the asset vm
@@ -77,6 +91,17 @@ func (service *Service) IssueTx(r *http.Request, args *api.FormattedTx, reply *a
 // GetTxStatusReply defines the GetTxStatus replies returned from the API
 type GetTxStatusReply struct {
 	Status choices.Status `json:"status"`
+	// Why the tx was rejected, if it was rejected and the reason is known
+	Reason *RejectionReason `json:"reason,omitempty"`
+}
+
+// RejectionReason describes why a tx was rejected
+type RejectionReason struct {
+	// PrecludedByConflict if a conflicting tx was accepted, or
+	// DependencyRejected if a tx this tx depends on was rejected
+	Cause string `json:"cause"`
+	// The accepted conflicting tx, or the rejected dependency
+	TxID ids.ID `json:"txID"`
 }
 
 // GetTxStatus returns the status of the specified transaction
@@ -87,12 +112,211 @@ func (service *Service) GetTxStatus(r *http.Request, args *api.JSONTxID, reply *
 		return errNilTxID
 	}
 
-	tx := UniqueTx{
-		vm:   service.vm,
-		txID: args.TxID,
+	reply.Status = service.vm.snapshotStatus(args.TxID)
+	if reply.Status != choices.Rejected {
+		return nil
+	}
+	reason, ok, err := service.vm.snapshot.Rejection(args.TxID)
+	if err != nil {
+		return fmt.Errorf("problem reading the rejection of %s: %w", args.TxID, err)
+	}
+	if ok {
+		reply.Reason = &RejectionReason{
+			Cause: reason.Cause.String(),
+			TxID:  reason.TxID,
+		}
+	}
+	return nil
+}
+
+// GetTxStatusesArgs are arguments for passing into GetTxStatuses requests
+type GetTxStatusesArgs struct {
+	TxIDs []ids.ID `json:"txIDs"`
+}
+
+// GetTxStatusesReply defines the GetTxStatuses replies returned from the API
+type GetTxStatusesReply struct {
+	Statuses []choices.Status `json:"statuses"`
+}
+
+// GetTxStatuses returns the status of each of the specified transactions, in
+// the order they were specified
+func (service *Service) GetTxStatuses(r *http.Request, args *GetTxStatusesArgs, reply *GetTxStatusesReply) error {
+	service.vm.ctx.Log.Info("AVM: GetTxStatuses called with %d txIDs", len(args.TxIDs))
+
+	if len(args.TxIDs) > maxGetTxStatusesTxIDs {
+		return errTooManyTxIDs
+	}
+
+	reply.Statuses = make([]choices.Status, len(args.TxIDs))
+	for i, txID := range args.TxIDs {
+		if txID == ids.Empty {
+			return errNilTxID
+		}
+		reply.Statuses[i] = service.vm.snapshotStatus(txID)
+	}
+	return nil
+}
+
+// GetAPIVersionReply defines the GetAPIVersion replies returned from the API
+type GetAPIVersionReply struct {
+	Version json.Uint32 `json:"version"`
+}
+
+// GetAPIVersion returns the version of this API
+func (service *Service) GetAPIVersion(r *http.Request, _ *struct{}, reply *GetAPIVersionReply) error {
+	service.vm.ctx.Log.Info("AVM: GetAPIVersion called")
+
+	reply.Version = APIVersion
+	return nil
+}
+
+// EstimateFeeArgs are arguments for passing into EstimateFee requests
+type EstimateFeeArgs struct {
+	// Percentile of recent batches of txs to target, in (0, 100]. Higher
+	// percentiles give higher fees during bursts of txs. Defaults to 50.
+	Percentile json.Float32 `json:"percentile"`
+	// True if the tx creates an asset
+	CreatesAsset bool `json:"createsAsset"`
+}
+
+// EstimateFeeReply defines the EstimateFee replies returned from the API
+type EstimateFeeReply struct {
+	// Suggested fee. Fees are currently flat, so this is the minimum fee, as
+	// any fee paid above it is burned.
+	Fee json.Uint64 `json:"fee"`
+	// Minimum fee
+	BaseFee json.Uint64 `json:"baseFee"`
+	// Congestion, in [0, 1], of recent tx issuance. Doesn't affect the fee
+	// until fees depend on demand.
+	Congestion json.Float32 `json:"congestion"`
+}
+
+// EstimateFee suggests the fee a tx should pay, and reports how congested tx
+// issuance has recently been
+func (service *Service) EstimateFee(_ *http.Request, args *EstimateFeeArgs, reply *EstimateFeeReply) error {
+	service.vm.ctx.Log.Info("AVM: EstimateFee called with percentile %f", args.Percentile)
+
+	percentile := float64(args.Percentile)
+	switch {
+	case percentile == 0:
+		percentile = defaultFeePercentile
+	case percentile < 0 || percentile > 100:
+		return fmt.Errorf("%w: %f", errInvalidPercentile, percentile)
+	}
+
+	baseFee := service.vm.txFee
+	if args.CreatesAsset {
+		baseFee = service.vm.creationTxFee
+	}
+	reply.Fee = json.Uint64(baseFee)
+	reply.BaseFee = json.Uint64(baseFee)
+	reply.Congestion = json.Float32(service.vm.congestion(percentile))
+	return nil
+}
+
+// MemoIndex is a match of a memo search. Used for pagination.
+type MemoIndex struct {
+	Memo string `json:"memo"`
+	TxID ids.ID `json:"txID"`
+}
+
+// SearchMemosArgs are arguments for passing into SearchMemos requests
+type SearchMemosArgs struct {
+	// Memo to search for
+	Memo string `json:"memo"`
+	// True if txs whose memo starts with [Memo] match
+	Prefix bool `json:"prefix"`
+	// Maximum number of matches to return. If 0 or greater than the node's
+	// maximum, the node's maximum is used.
+	Limit json.Uint32 `json:"limit"`
+	// If specified, only the matches after [StartIndex] are returned
+	StartIndex *MemoIndex `json:"startIndex"`
+}
+
+// SearchMemosReply defines the SearchMemos replies returned from the API
+type SearchMemosReply struct {
+	// Number of matches returned
+	NumFetched json.Uint64 `json:"numFetched"`
+	// Accepted txs whose memo matched
+	Matches []MemoIndex `json:"matches"`
+	// The last match that was returned. Used for pagination. To get the rest
+	// of the matches, call SearchMemos again and set [StartIndex] to this
+	// value.
+	EndIndex MemoIndex `json:"endIndex"`
+}
+
+// SearchMemos returns the accepted txs whose memo matches [args.Memo]. Memos
+// are matched byte for byte, and memos longer than 64 bytes aren't indexed.
+// Requires the node to index memos.
+func (service *Service) SearchMemos(_ *http.Request, args *SearchMemosArgs, reply *SearchMemosReply) error {
+	service.vm.ctx.Log.Info("AVM: SearchMemos called with prefix %t", args.Prefix)
+
+	var (
+		startMemo []byte
+		startTxID ids.ID
+	)
+	if args.StartIndex != nil {
+		startMemo = []byte(args.StartIndex.Memo)
+		startTxID = args.StartIndex.TxID
+	}
+	matches, err := service.vm.SearchMemos([]byte(args.Memo), args.Prefix, startMemo, startTxID, int(args.Limit))
+	if err != nil {
+		return fmt.Errorf("problem searching memos: %w", err)
 	}
 
-	reply.Status = tx.Status()
+	reply.Matches = make([]MemoIndex, len(matches))
+	for i, match := range matches {
+		reply.Matches[i] = MemoIndex{
+			Memo: string(match.Memo),
+			TxID: match.TxID,
+		}
+	}
+	reply.NumFetched = json.Uint64(len(matches))
+	if len(matches) > 0 {
+		reply.EndIndex = reply.Matches[len(matches)-1]
+	}
+	return nil
+}
+
+// GetConflictsArgs are arguments for passing into GetConflicts requests.
+// Exactly one of [TxID] and [UTXOID] must be provided.
+type GetConflictsArgs struct {
+	TxID   ids.ID       `json:"txID"`
+	UTXOID *avax.UTXOID `json:"utxoID"`
+}
+
+// GetConflictsReply defines the GetConflicts replies returned from the API
+type GetConflictsReply struct {
+	// True if any processing tx conflicts
+	Conflicting bool `json:"conflicting"`
+	// The processing txs that conflict
+	TxIDs []ids.ID `json:"txIDs"`
+}
+
+// GetConflicts returns the txs that are processing in consensus and spend a
+// UTXO that the specified tx spends, or that spend the specified UTXO. A tx
+// with conflicts may be rejected, so this can be used to gauge the risk of
+// relying on a tx before it is accepted.
+func (service *Service) GetConflicts(_ *http.Request, args *GetConflictsArgs, reply *GetConflictsReply) error {
+	service.vm.ctx.Log.Info("AVM: GetConflicts called")
+
+	switch {
+	case (args.TxID == ids.Empty) == (args.UTXOID == nil):
+		return errTxIDAndUTXOID
+	case args.UTXOID != nil:
+		reply.TxIDs = service.vm.processing.Spenders(args.UTXOID.InputID())
+	default:
+		conflicts, err := service.vm.Conflicts(args.TxID)
+		if err != nil {
+			return fmt.Errorf("problem finding the conflicts of %s: %w", args.TxID, err)
+		}
+		reply.TxIDs = conflicts
+	}
+	if reply.TxIDs == nil {
+		reply.TxIDs = []ids.ID{}
+	}
+	reply.Conflicting = len(reply.TxIDs) > 0
 	return nil
 }
 
@@ -354,50 +578,25 @@ func (service *Service) GetAllBalances(r *http.Request, args *GetAllBalancesArgs
 	if err != nil {
 		return fmt.Errorf("problem parsing address '%s': %w", args.Address, err)
 	}
-	addrSet := ids.ShortSet{}
-	addrSet.Add(address)
 
-	utxos, _, _, err := service.vm.GetUTXOs(addrSet, ids.ShortEmpty, ids.Empty, -1, false)
+	addrBalances, err := service.vm.getAddressBalances(address)
 	if err != nil {
 		return fmt.Errorf("couldn't get address's UTXOs: %w", err)
 	}
+	balances := addrBalances.Balances(args.IncludePartial) // key: ID of an asset. value: balance of that asset
 
-	now := service.vm.Clock().Unix()
-	assetIDs := ids.Set{}               // IDs of assets the address has a non-zero balance of
-	balances := make(map[ids.ID]uint64) // key: ID (as bytes). value: balance of that asset
-	for _, utxo := range utxos {
-		// TODO make this not specific to *secp256k1fx.TransferOutput
-		transferable, ok := utxo.Out.(*secp256k1fx.TransferOutput)
-		if !ok {
-			continue
-		}
-		owners := transferable.OutputOwners
-		if !args.IncludePartial && (len(owners.Addrs) != 1 || owners.Locktime > now) {
-			continue
-		}
-		assetID := utxo.AssetID()
-		assetIDs.Add(assetID)
-		balance := balances[assetID] // 0 if key doesn't exist
-		balance, err := safemath.Add64(transferable.Amount(), balance)
-		if err != nil {
-			balances[assetID] = math.MaxUint64
-		} else {
-			balances[assetID] = balance
-		}
-	}
-
-	reply.Balances = make([]Balance, assetIDs.Len())
+	reply.Balances = make([]Balance, len(balances))
 	i := 0
-	for assetID := range assetIDs {
+	for assetID, balance := range balances {
 		if alias, err := service.vm.PrimaryAlias(assetID); err == nil {
 			reply.Balances[i] = Balance{
 				AssetID: alias,
-				Balance: json.Uint64(balances[assetID]),
+				Balance: json.Uint64(balance),
 			}
 		} else {
 			reply.Balances[i] = Balance{
 				AssetID: assetID.String(),
-				Balance: json.Uint64(balances[assetID]),
+				Balance: json.Uint64(balance),
 			}
 		}
 		i++
@@ -712,7 +911,7 @@ func (service *Service) CreateAddress(r *http.Request, args *api.UserPass, reply
 	}
 	defer db.Close()
 
-	user := userState{vm: service.vm}
+	user := userState{vm: service.vm, username: args.Username}
 
 	addresses, _ := user.Addresses(db)
 	if len(addresses) >= maxKeystoreAddresses {
@@ -756,7 +955,7 @@ func (service *Service) ListAddresses(_ *http.Request, args *api.UserPass, respo
 
 	response.Addresses = []string{}
 
-	user := userState{vm: service.vm}
+	user := userState{vm: service.vm, username: args.Username}
 	addresses, err := user.Addresses(db)
 	if err != nil {
 		// An error fetching the addresses may just mean that the user has no
@@ -804,7 +1003,7 @@ func (service *Service) ExportKey(r *http.Request, args *ExportKeyArgs, reply *E
 	}
 	defer db.Close()
 
-	user := userState{vm: service.vm}
+	user := userState{vm: service.vm, username: args.Username}
 
 	sk, err := user.Key(db, addr)
 	if err != nil {
@@ -840,7 +1039,7 @@ func (service *Service) ImportKey(r *http.Request, args *ImportKeyArgs, reply *a
 	}
 	defer db.Close()
 
-	user := userState{vm: service.vm}
+	user := userState{vm: service.vm, username: args.Username}
 
 	addresses, _ := user.Addresses(db)
 	if len(addresses) >= maxKeystoreAddresses {
@@ -1500,9 +1699,11 @@ func (service *Service) Import(_ *http.Request, args *ImportArgs, reply *api.JSO
 		}
 	}
 
-	// Because we ensured that we had enough inputs for the fee, we can
-	// safely just remove it without concern for underflow.
-	amountsSpent[service.vm.ctx.AVAXAssetID] -= service.vm.txFee
+	amountAfterFee, err := safemath.Sub64(amountsSpent[service.vm.ctx.AVAXAssetID], service.vm.txFee)
+	if err != nil {
+		return fmt.Errorf("problem calculating the amount after the fee: %w", err)
+	}
+	amountsSpent[service.vm.ctx.AVAXAssetID] = amountAfterFee
 
 	keys = append(keys, importKeys...)
 
@@ -5,6 +5,7 @@ package avm
 
 import (
 	"bytes"
+	"errors"
 	"fmt"
 	"math/rand"
 	"strings"
@@ -16,6 +17,7 @@ import (
 	"github.com/ava-labs/avalanchego/chains/atomic"
 	"github.com/ava-labs/avalanchego/ids"
 	"github.com/ava-labs/avalanchego/snow/choices"
+	"github.com/ava-labs/avalanchego/snow/consensus/snowstorm"
 	"github.com/ava-labs/avalanchego/utils/constants"
 	"github.com/ava-labs/avalanchego/utils/crypto"
 	"github.com/ava-labs/avalanchego/utils/formatting"
@@ -78,6 +80,15 @@ func setupWithKeys(t *testing.T) ([]byte, *VM, *Service, *atomic.Memory) {
 	return genesisBytes, vm, s, m
 }
 
+// commitState commits the changes written directly to [vm]'s state, so that
+// API reads observe them
+func commitState(tb testing.TB, vm *VM) {
+	if err := vm.db.Commit(); err != nil {
+		tb.Fatal(err)
+	}
+	vm.advanceSnapshot()
+}
+
 // Sample from a set of addresses and return them raw and formatted as strings.
 // The size of the sample is between 1 and len(addrs)
 // If len(addrs) == 0, returns nil
@@ -232,6 +243,124 @@ func TestServiceGetTxStatus(t *testing.T) {
 			expected.String(), statusReply.Status.String(),
 		)
 	}
+	if statusReply.Reason != nil {
+		t.Fatal("Expected a processing tx to not have a rejection reason")
+	}
+
+	// The reason a tx was rejected is reported along with its status
+	conflictID := ids.GenerateTestID()
+	uniqueTx, err := vm.Get(tx.ID())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := uniqueTx.(*UniqueTx).RecordRejection(snowstorm.Rejection{
+		Cause: snowstorm.PrecludedByConflict,
+		TxID:  conflictID,
+	}); err != nil {
+		t.Fatal(err)
+	}
+	if err := uniqueTx.Reject(); err != nil {
+		t.Fatal(err)
+	}
+	statusReply = &GetTxStatusReply{}
+	if err := s.GetTxStatus(nil, statusArgs, statusReply); err != nil {
+		t.Fatal(err)
+	}
+	if expected := choices.Rejected; expected != statusReply.Status {
+		t.Fatalf(
+			"Expected a rejected tx to have status %q, got %q",
+			expected.String(), statusReply.Status.String(),
+		)
+	}
+	expectedReason := &RejectionReason{Cause: "PrecludedByConflict", TxID: conflictID}
+	if reason := statusReply.Reason; reason == nil || *reason != *expectedReason {
+		t.Fatalf("Expected rejection reason %v, got %v", expectedReason, reason)
+	}
+}
+
+func TestServiceGetTxStatuses(t *testing.T) {
+	genesisBytes, vm, s, _ := setup(t)
+	defer func() {
+		if err := vm.Shutdown(); err != nil {
+			t.Fatal(err)
+		}
+		vm.ctx.Lock.Unlock()
+	}()
+
+	tx := NewTx(t, genesisBytes, vm)
+	txStr, err := formatting.Encode(formatting.Hex, tx.Bytes())
+	if err != nil {
+		t.Fatal(err)
+	}
+	txReply := &api.JSONTxID{}
+	if err := s.IssueTx(nil, &api.FormattedTx{Tx: txStr, Encoding: formatting.Hex}, txReply); err != nil {
+		t.Fatal(err)
+	}
+
+	statusesArgs := &GetTxStatusesArgs{TxIDs: []ids.ID{ids.GenerateTestID(), tx.ID()}}
+	statusesReply := &GetTxStatusesReply{}
+	if err := s.GetTxStatuses(nil, statusesArgs, statusesReply); err != nil {
+		t.Fatal(err)
+	}
+	if len(statusesReply.Statuses) != 2 {
+		t.Fatalf("Expected 2 statuses, got %d", len(statusesReply.Statuses))
+	}
+	if statusesReply.Statuses[0] != choices.Unknown {
+		t.Fatalf("Expected an unknown tx to have status %q, got %q", choices.Unknown, statusesReply.Statuses[0])
+	}
+	if statusesReply.Statuses[1] != choices.Processing {
+		t.Fatalf("Expected an issued tx to have status %q, got %q", choices.Processing, statusesReply.Statuses[1])
+	}
+
+	statusesArgs.TxIDs = []ids.ID{ids.Empty}
+	if err := s.GetTxStatuses(nil, statusesArgs, &GetTxStatusesReply{}); err == nil {
+		t.Fatal("Expected empty transaction to return an error")
+	}
+
+	statusesArgs.TxIDs = make([]ids.ID, maxGetTxStatusesTxIDs+1)
+	if err := s.GetTxStatuses(nil, statusesArgs, &GetTxStatusesReply{}); err != errTooManyTxIDs {
+		t.Fatalf("Expected too many tx IDs to return %q, got %v", errTooManyTxIDs, err)
+	}
+}
+
+func TestServiceEstimateFee(t *testing.T) {
+	_, vm, s, _ := setup(t)
+	defer func() {
+		if err := vm.Shutdown(); err != nil {
+			t.Fatal(err)
+		}
+		vm.ctx.Lock.Unlock()
+	}()
+
+	// Without any recent batches, the minimum fee is suggested
+	reply := &EstimateFeeReply{}
+	err := s.EstimateFee(nil, &EstimateFeeArgs{}, reply)
+	assert.NoError(t, err)
+	assert.Equal(t, vm.txFee, uint64(reply.Fee))
+	assert.Equal(t, vm.txFee, uint64(reply.BaseFee))
+	assert.Zero(t, float32(reply.Congestion))
+
+	// Three quarters of the recent batches were full
+	vm.feeStats.Observe(0)
+	for i := 0; i < 3; i++ {
+		vm.feeStats.Observe(batchSize)
+	}
+
+	err = s.EstimateFee(nil, &EstimateFeeArgs{Percentile: 25}, reply)
+	assert.NoError(t, err)
+	assert.Equal(t, vm.txFee, uint64(reply.Fee))
+	assert.Zero(t, float32(reply.Congestion))
+
+	// Fees are flat, so congestion doesn't raise the suggested fee, as the
+	// excess would be burned
+	err = s.EstimateFee(nil, &EstimateFeeArgs{Percentile: 50, CreatesAsset: true}, reply)
+	assert.NoError(t, err)
+	assert.Equal(t, vm.creationTxFee, uint64(reply.Fee))
+	assert.Equal(t, vm.creationTxFee, uint64(reply.BaseFee))
+	assert.Equal(t, float32(1), float32(reply.Congestion))
+
+	err = s.EstimateFee(nil, &EstimateFeeArgs{Percentile: 101}, reply)
+	assert.True(t, errors.Is(err, errInvalidPercentile))
 }
 
 // Test the GetBalance method when argument Strict is true
@@ -270,6 +399,7 @@ func TestServiceGetBalanceStrict(t *testing.T) {
 	// Insert the UTXO
 	err = vm.state.FundUTXO(twoOfTwoUTXO)
 	assert.NoError(t, err)
+	commitState(t, vm)
 
 	// Check the balance with IncludePartial set to true
 	balanceArgs := &GetBalanceArgs{
@@ -315,6 +445,7 @@ func TestServiceGetBalanceStrict(t *testing.T) {
 	// Insert the UTXO
 	err = vm.state.FundUTXO(oneOfTwoUTXO)
 	assert.NoError(t, err)
+	commitState(t, vm)
 
 	// Check the balance with IncludePartial set to true
 	balanceArgs = &GetBalanceArgs{
@@ -362,6 +493,7 @@ func TestServiceGetBalanceStrict(t *testing.T) {
 	// Insert the UTXO
 	err = vm.state.FundUTXO(futureUTXO)
 	assert.NoError(t, err)
+	commitState(t, vm)
 
 	// Check the balance with IncludePartial set to true
 	balanceArgs = &GetBalanceArgs{
@@ -423,6 +555,7 @@ func TestServiceGetAllBalances(t *testing.T) {
 	// Insert the UTXO
 	err = vm.state.FundUTXO(twoOfTwoUTXO)
 	assert.NoError(t, err)
+	commitState(t, vm)
 
 	// Check the balance with IncludePartial set to true
 	balanceArgs := &GetAllBalancesArgs{
@@ -465,6 +598,7 @@ func TestServiceGetAllBalances(t *testing.T) {
 	// Insert the UTXO
 	err = vm.state.FundUTXO(oneOfTwoUTXO)
 	assert.NoError(t, err)
+	commitState(t, vm)
 
 	// Check the balance with IncludePartial set to true
 	balanceArgs = &GetAllBalancesArgs{
@@ -510,6 +644,7 @@ func TestServiceGetAllBalances(t *testing.T) {
 	// Insert the UTXO
 	err = vm.state.FundUTXO(futureUTXO)
 	assert.NoError(t, err)
+	commitState(t, vm)
 
 	// Check the balance with IncludePartial set to true
 	balanceArgs = &GetAllBalancesArgs{
@@ -553,6 +688,7 @@ func TestServiceGetAllBalances(t *testing.T) {
 	// Insert the UTXO
 	err = vm.state.FundUTXO(otherAssetUTXO)
 	assert.NoError(t, err)
+	commitState(t, vm)
 
 	// Check the balance with IncludePartial set to true
 	balanceArgs = &GetAllBalancesArgs{
@@ -581,6 +717,68 @@ func TestServiceGetAllBalances(t *testing.T) {
 	// The balance should include the UTXO since it is partly owned by [addr]
 	assert.Len(t, reply.Balances, 0)
 }
+
+func TestServiceGetAllBalancesCache(t *testing.T) {
+	_, vm, s, _ := setup(t)
+	defer func() {
+		if err := vm.Shutdown(); err != nil {
+			t.Fatal(err)
+		}
+		vm.ctx.Lock.Unlock()
+	}()
+
+	assetID := ids.GenerateTestID()
+	addr := ids.GenerateTestShortID()
+	addrStr, err := vm.FormatLocalAddress(addr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	now := vm.clock.Time()
+	// A UTXO owned by [addr] that unlocks in the future
+	lockedUTXO := &avax.UTXO{
+		UTXOID: avax.UTXOID{
+			TxID:        ids.GenerateTestID(),
+			OutputIndex: 0,
+		},
+		Asset: avax.Asset{ID: assetID},
+		Out: &secp256k1fx.TransferOutput{
+			Amt: 1337,
+			OutputOwners: secp256k1fx.OutputOwners{
+				Locktime:  uint64(now.Add(time.Hour).Unix()),
+				Threshold: 1,
+				Addrs:     []ids.ShortID{addr},
+			},
+		},
+	}
+	err = vm.state.FundUTXO(lockedUTXO)
+	assert.NoError(t, err)
+	commitState(t, vm)
+
+	balanceArgs := &GetAllBalancesArgs{
+		JSONAddress: api.JSONAddress{Address: addrStr},
+	}
+	reply := &GetAllBalancesReply{}
+	err = s.GetAllBalances(nil, balanceArgs, reply)
+	assert.NoError(t, err)
+	assert.Len(t, reply.Balances, 0)
+
+	// The cached balance is stale once the UTXO unlocks
+	vm.clock.Set(now.Add(time.Hour))
+	reply = &GetAllBalancesReply{}
+	err = s.GetAllBalances(nil, balanceArgs, reply)
+	assert.NoError(t, err)
+	assert.Len(t, reply.Balances, 1)
+	assert.Equal(t, uint64(1337), uint64(reply.Balances[0].Balance))
+
+	// The cached balance is stale once the UTXO is spent
+	err = vm.state.SpendUTXO(lockedUTXO.InputID())
+	assert.NoError(t, err)
+	commitState(t, vm)
+	reply = &GetAllBalancesReply{}
+	err = s.GetAllBalances(nil, balanceArgs, reply)
+	assert.NoError(t, err)
+	assert.Len(t, reply.Balances, 0)
+}
 func TestServiceGetTx(t *testing.T) {
 	genesisBytes, vm, s, _ := setup(t)
 	defer func() {
@@ -668,6 +866,7 @@ func TestServiceGetUTXOs(t *testing.T) {
 			t.Fatal(err)
 		}
 	}
+	commitState(t, vm)
 
 	sm := m.NewSharedMemory(platformChainID)
 
@@ -1530,6 +1729,52 @@ func TestCreateAndListAddresses(t *testing.T) {
 	t.Fatalf("Failed to find newly created address among %d addresses", len(listReply.Addresses))
 }
 
+func TestListAddressesAfterUserDeleted(t *testing.T) {
+	_, vm, s, _ := setup(t)
+	defer func() {
+		if err := vm.Shutdown(); err != nil {
+			t.Fatal(err)
+		}
+		vm.ctx.Lock.Unlock()
+	}()
+
+	ks, err := keystore.CreateTestKeystore()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := ks.AddUser(username, password); err != nil {
+		t.Fatal(err)
+	}
+	vm.ctx.Keystore = ks.NewBlockchainKeyStore(chainID)
+
+	userArgs := &api.UserPass{
+		Username: username,
+		Password: password,
+	}
+	if err := s.CreateAddress(nil, userArgs, &api.JSONAddress{}); err != nil {
+		t.Fatalf("Failed to create address: %s", err)
+	}
+	listReply := &api.JSONAddresses{}
+	if err := s.ListAddresses(nil, userArgs, listReply); err != nil {
+		t.Fatalf("Failed to list addresses: %s", err)
+	}
+	assert.Len(t, listReply.Addresses, 1)
+
+	// The addresses cached for the user shouldn't be returned once the user
+	// is deleted and re-created
+	if err := ks.DeleteUser(nil, userArgs, &api.SuccessResponse{}); err != nil {
+		t.Fatal(err)
+	}
+	if err := ks.AddUser(username, password); err != nil {
+		t.Fatal(err)
+	}
+	listReply = &api.JSONAddresses{}
+	if err := s.ListAddresses(nil, userArgs, listReply); err != nil {
+		t.Fatalf("Failed to list addresses: %s", err)
+	}
+	assert.Empty(t, listReply.Addresses)
+}
+
 func TestImportAVAX(t *testing.T) {
 	genesisBytes, vm, s, m := setupWithKeys(t)
 	defer func() {
@@ -0,0 +1,239 @@
+// (c) 2019-2020, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package avm
+
+import (
+	"errors"
+	"sync"
+
+	"github.com/ava-labs/avalanchego/chains/atomic"
+	"github.com/ava-labs/avalanchego/database"
+	"github.com/ava-labs/avalanchego/ids"
+	"github.com/ava-labs/avalanchego/utils/logging"
+)
+
+var (
+	errPutAndRemove = errors.New("atomic operations can't both put and remove")
+)
+
+// atomicOperations are the shared memory operations that a tx performs with
+// a single peer chain when it's accepted. Either elements are put into the
+// peer chain's side of shared memory, or they're removed from this chain's
+// side, but not both, so that the operations can be applied atomically.
+type atomicOperations struct {
+	PeerChainID ids.ID
+	Puts        []*atomic.Element
+	Removes     [][]byte
+}
+
+// Apply the operations to [sharedMemory], atomically with [batches]
+func (ops *atomicOperations) Apply(sharedMemory atomic.SharedMemory, batches ...database.Batch) error {
+	switch {
+	case len(ops.Puts) > 0 && len(ops.Removes) > 0:
+		return errPutAndRemove
+	case len(ops.Removes) > 0:
+		return sharedMemory.Remove(ops.PeerChainID, ops.Removes, batches...)
+	default:
+		return sharedMemory.Put(ops.PeerChainID, ops.Puts, batches...)
+	}
+}
+
+// sideEffectIntent is the atomic operations of the accepted tx with index
+// [index] that haven't been applied yet
+type sideEffectIntent struct {
+	index uint64
+	ops   *atomicOperations
+}
+
+// sideEffectExecutor applies the atomic operations of accepted txs in the
+// background, in the order that the txs were accepted, so that slow shared
+// memory operations don't block acceptance.
+//
+// An intent is recorded in the VM's database atomically with the acceptance
+// of a tx that has atomic operations, and deleted atomically with the
+// application of those operations. Intents that remain after a restart are
+// replayed on startup.
+type sideEffectExecutor struct {
+	log          logging.Logger
+	sharedMemory atomic.SharedMemory
+	// The intents that haven't been applied yet, keyed by accepted tx index.
+	// This must have the same underlying database as shared memory.
+	intentDB database.Database
+
+	lock sync.Mutex
+	cond *sync.Cond
+	// Intents that haven't been applied yet, in the order they were added
+	pending []sideEffectIntent
+	// Peer chain ID --> IDs of UTXOs in this chain's side of shared memory
+	// that will be removed by a pending intent
+	pendingRemovals map[ids.ID]ids.Set
+	closed          bool
+	// Closed once the executor has stopped
+	done chan struct{}
+}
+
+func newSideEffectExecutor(
+	log logging.Logger,
+	sharedMemory atomic.SharedMemory,
+	intentDB database.Database,
+) *sideEffectExecutor {
+	e := &sideEffectExecutor{
+		log:             log,
+		sharedMemory:    sharedMemory,
+		intentDB:        intentDB,
+		pendingRemovals: make(map[ids.ID]ids.Set),
+		done:            make(chan struct{}),
+	}
+	e.cond = sync.NewCond(&e.lock)
+	return e
+}
+
+// Add an intent, whose record has already been written, to be applied in the
+// background
+func (e *sideEffectExecutor) Add(index uint64, ops *atomicOperations) {
+	e.lock.Lock()
+	defer e.lock.Unlock()
+
+	e.pending = append(e.pending, sideEffectIntent{
+		index: index,
+		ops:   ops,
+	})
+	if len(ops.Removes) > 0 {
+		removals := e.pendingRemovals[ops.PeerChainID]
+		for _, key := range ops.Removes {
+			if utxoID, err := ids.ToID(key); err == nil {
+				removals.Add(utxoID)
+			}
+		}
+		e.pendingRemovals[ops.PeerChainID] = removals
+	}
+	e.cond.Signal()
+}
+
+// PendingRemoval returns true if the UTXO [utxoID], in this chain's side of
+// shared memory with [peerChainID], will be removed by an intent that hasn't
+// been applied yet
+func (e *sideEffectExecutor) PendingRemoval(peerChainID, utxoID ids.ID) bool {
+	e.lock.Lock()
+	defer e.lock.Unlock()
+
+	removals := e.pendingRemovals[peerChainID]
+	return removals.Contains(utxoID)
+}
+
+// Wait until every intent that has been added has been applied, or the
+// executor has stopped
+func (e *sideEffectExecutor) Wait() {
+	e.lock.Lock()
+	defer e.lock.Unlock()
+
+	for len(e.pending) > 0 && !e.closed {
+		e.cond.Wait()
+	}
+}
+
+// Dispatch applies intents as they're added, until Stop is called
+func (e *sideEffectExecutor) Dispatch() {
+	defer close(e.done)
+
+	for {
+		e.lock.Lock()
+		for len(e.pending) == 0 && !e.closed {
+			e.cond.Wait()
+		}
+		if e.closed {
+			e.lock.Unlock()
+			return
+		}
+		intent := e.pending[0]
+		e.lock.Unlock()
+
+		err := e.Apply(intent)
+		if err != nil {
+			// The intent is left in the database, so it will be retried on
+			// restart. Its removals are still considered pending, so the
+			// UTXOs can't be spent again in the meantime.
+			e.log.Error("couldn't apply the atomic operations of accepted tx %d: %s", intent.index, err)
+		}
+
+		e.lock.Lock()
+		e.pending = e.pending[1:]
+		if err == nil {
+			e.clearRemovals(intent.ops)
+		}
+		e.cond.Broadcast()
+		e.lock.Unlock()
+	}
+}
+
+// Stop the executor once the intent that's being applied, if any, finishes.
+// Intents that haven't been applied will be replayed on restart.
+func (e *sideEffectExecutor) Stop() {
+	e.lock.Lock()
+	e.closed = true
+	e.cond.Broadcast()
+	e.lock.Unlock()
+
+	<-e.done
+}
+
+// Apply the atomic operations of [intent] and delete its record atomically
+func (e *sideEffectExecutor) Apply(intent sideEffectIntent) error {
+	batch := e.intentDB.NewBatch()
+	if err := batch.Delete(uint64ToBytes(intent.index)); err != nil {
+		return err
+	}
+	return intent.ops.Apply(e.sharedMemory, batch)
+}
+
+// clearRemovals marks the removals of [ops] as no longer pending. Assumes the
+// lock is held.
+func (e *sideEffectExecutor) clearRemovals(ops *atomicOperations) {
+	removals, ok := e.pendingRemovals[ops.PeerChainID]
+	if !ok {
+		return
+	}
+	for _, key := range ops.Removes {
+		if utxoID, err := ids.ToID(key); err == nil {
+			removals.Remove(utxoID)
+		}
+	}
+	if removals.Len() == 0 {
+		delete(e.pendingRemovals, ops.PeerChainID)
+	}
+}
+
+// replaySideEffects applies the atomic operations of the accepted txs whose
+// intents were recorded, but not applied, before the VM last shut down
+func (vm *VM) replaySideEffects() error {
+	indices, txIDs, err := vm.state.SideEffectIntents()
+	if err != nil {
+		return err
+	}
+	if len(indices) == 0 {
+		return nil
+	}
+
+	vm.ctx.Log.Info("replaying the atomic operations of %d accepted txs", len(indices))
+	for i, index := range indices {
+		tx, err := vm.state.Tx(txIDs[i])
+		if err != nil {
+			return err
+		}
+		ops, err := tx.AtomicOperations(vm)
+		if err != nil {
+			return err
+		}
+		if ops == nil {
+			if err := vm.sideEffects.intentDB.Delete(uint64ToBytes(index)); err != nil {
+				return err
+			}
+			continue
+		}
+		if err := vm.sideEffects.Apply(sideEffectIntent{index: index, ops: ops}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
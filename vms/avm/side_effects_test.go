@@ -0,0 +1,240 @@
+// (c) 2019-2020, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package avm
+
+import (
+	"math"
+	"testing"
+
+	"github.com/ava-labs/avalanchego/chains/atomic"
+	"github.com/ava-labs/avalanchego/database"
+	"github.com/ava-labs/avalanchego/database/memdb"
+	"github.com/ava-labs/avalanchego/database/prefixdb"
+	"github.com/ava-labs/avalanchego/ids"
+	"github.com/ava-labs/avalanchego/snow"
+	"github.com/ava-labs/avalanchego/snow/engine/common"
+	"github.com/ava-labs/avalanchego/utils/crypto"
+	"github.com/ava-labs/avalanchego/utils/logging"
+	"github.com/ava-labs/avalanchego/vms/components/avax"
+	"github.com/ava-labs/avalanchego/vms/secp256k1fx"
+)
+
+// newAsyncSideEffectsVM returns a bootstrapped VM, stored in [baseDB], that
+// applies atomic operations to [m] in the background. The returned context is
+// locked.
+func newAsyncSideEffectsVM(t *testing.T, m *atomic.Memory, baseDB database.Database) (*VM, *snow.Context) {
+	genesisBytes := BuildGenesisTest(t)
+
+	ctx := NewContext(t)
+	ctx.SharedMemory = m.NewSharedMemory(chainID)
+
+	ctx.Lock.Lock()
+	vm := &VM{asyncSideEffects: true}
+	if err := vm.Initialize(
+		ctx,
+		prefixdb.New([]byte{1}, baseDB),
+		genesisBytes,
+		make(chan common.Message, 1),
+		[]*common.Fx{{
+			ID: ids.Empty,
+			Fx: &secp256k1fx.Fx{},
+		}},
+	); err != nil {
+		t.Fatal(err)
+	}
+	if err := vm.Bootstrapping(); err != nil {
+		t.Fatal(err)
+	}
+	if err := vm.Bootstrapped(); err != nil {
+		t.Fatal(err)
+	}
+	return vm, ctx
+}
+
+// acceptExportTx accepts a tx that exports the genesis funds of keys[0] to the
+// P-chain
+func acceptExportTx(t *testing.T, vm *VM) {
+	genesisTx := GetAVAXTxFromGenesisTest(BuildGenesisTest(t), t)
+	avaxID := genesisTx.ID()
+	key := keys[0]
+
+	tx := &Tx{UnsignedTx: &ExportTx{
+		BaseTx: BaseTx{BaseTx: avax.BaseTx{
+			NetworkID:    networkID,
+			BlockchainID: chainID,
+			Ins: []*avax.TransferableInput{{
+				UTXOID: avax.UTXOID{
+					TxID:        avaxID,
+					OutputIndex: 2,
+				},
+				Asset: avax.Asset{ID: avaxID},
+				In: &secp256k1fx.TransferInput{
+					Amt:   startBalance,
+					Input: secp256k1fx.Input{SigIndices: []uint32{0}},
+				},
+			}},
+		}},
+		DestinationChain: platformChainID,
+		ExportedOuts: []*avax.TransferableOutput{{
+			Asset: avax.Asset{ID: avaxID},
+			Out: &secp256k1fx.TransferOutput{
+				Amt: startBalance - vm.txFee,
+				OutputOwners: secp256k1fx.OutputOwners{
+					Threshold: 1,
+					Addrs:     []ids.ShortID{key.PublicKey().Address()},
+				},
+			},
+		}},
+	}}
+	if err := tx.SignSECP256K1Fx(vm.codec, [][]*crypto.PrivateKeySECP256K1R{{key}}); err != nil {
+		t.Fatal(err)
+	}
+
+	parsedTx, err := vm.Parse(tx.Bytes())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := parsedTx.Verify(); err != nil {
+		t.Fatal(err)
+	}
+	if err := parsedTx.Accept(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// numExportedUTXOs returns the number of UTXOs that keys[0] has in the
+// P-chain's side of shared memory
+func numExportedUTXOs(t *testing.T, m *atomic.Memory) int {
+	utxoBytes, _, _, err := m.NewSharedMemory(platformChainID).Indexed(
+		chainID,
+		[][]byte{keys[0].PublicKey().Address().Bytes()},
+		nil,
+		nil,
+		math.MaxInt32,
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return len(utxoBytes)
+}
+
+func TestAsyncSideEffects(t *testing.T) {
+	baseDB := memdb.New()
+	m := &atomic.Memory{}
+	if err := m.Initialize(logging.NoLog{}, prefixdb.New([]byte{0}, baseDB)); err != nil {
+		t.Fatal(err)
+	}
+
+	vm, ctx := newAsyncSideEffectsVM(t, m, baseDB)
+	defer func() {
+		if err := vm.Shutdown(); err != nil {
+			t.Fatal(err)
+		}
+		ctx.Lock.Unlock()
+	}()
+
+	acceptExportTx(t, vm)
+	vm.sideEffects.Wait()
+
+	if numUTXOs := numExportedUTXOs(t, m); numUTXOs != 1 {
+		t.Fatalf("wrong number of exported utxos %d", numUTXOs)
+	}
+	if indices, _, err := vm.state.SideEffectIntents(); err != nil {
+		t.Fatal(err)
+	} else if len(indices) != 0 {
+		t.Fatalf("%d intents should have been removed once applied", len(indices))
+	}
+}
+
+func TestReplaySideEffects(t *testing.T) {
+	baseDB := memdb.New()
+	m := &atomic.Memory{}
+	if err := m.Initialize(logging.NoLog{}, prefixdb.New([]byte{0}, baseDB)); err != nil {
+		t.Fatal(err)
+	}
+
+	// Stop applying atomic operations before the tx is accepted, as if the
+	// node shut down before they were applied
+	vm, ctx := newAsyncSideEffectsVM(t, m, baseDB)
+	vm.sideEffects.Stop()
+	acceptExportTx(t, vm)
+	ctx.Lock.Unlock()
+
+	if numUTXOs := numExportedUTXOs(t, m); numUTXOs != 0 {
+		t.Fatalf("shouldn't have applied the atomic operations, but exported %d utxos", numUTXOs)
+	}
+	if indices, _, err := vm.state.SideEffectIntents(); err != nil {
+		t.Fatal(err)
+	} else if len(indices) != 1 {
+		t.Fatalf("should have recorded 1 intent, but recorded %d", len(indices))
+	}
+
+	vm, ctx = newAsyncSideEffectsVM(t, m, baseDB)
+	defer func() {
+		if err := vm.Shutdown(); err != nil {
+			t.Fatal(err)
+		}
+		ctx.Lock.Unlock()
+	}()
+
+	if numUTXOs := numExportedUTXOs(t, m); numUTXOs != 1 {
+		t.Fatalf("should have replayed the atomic operations, but exported %d utxos", numUTXOs)
+	}
+	if indices, _, err := vm.state.SideEffectIntents(); err != nil {
+		t.Fatal(err)
+	} else if len(indices) != 0 {
+		t.Fatalf("%d intents should have been removed once replayed", len(indices))
+	}
+}
+
+func TestSideEffectExecutorPendingRemoval(t *testing.T) {
+	baseDB := memdb.New()
+	m := &atomic.Memory{}
+	if err := m.Initialize(logging.NoLog{}, prefixdb.New([]byte{0}, baseDB)); err != nil {
+		t.Fatal(err)
+	}
+	intentDB := prefixdb.New([]byte{1}, baseDB)
+
+	// Put a UTXO into this chain's side of shared memory
+	utxoID := ids.GenerateTestID()
+	err := m.NewSharedMemory(platformChainID).Put(chainID, []*atomic.Element{{
+		Key:   utxoID[:],
+		Value: []byte{1},
+	}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	e := newSideEffectExecutor(logging.NoLog{}, m.NewSharedMemory(chainID), intentDB)
+	if err := intentDB.Put(uint64ToBytes(0), utxoID[:]); err != nil {
+		t.Fatal(err)
+	}
+	e.Add(0, &atomicOperations{
+		PeerChainID: platformChainID,
+		Removes:     [][]byte{utxoID[:]},
+	})
+
+	if !e.PendingRemoval(platformChainID, utxoID) {
+		t.Fatalf("removal should be pending before it's applied")
+	}
+	if e.PendingRemoval(ids.GenerateTestID(), utxoID) {
+		t.Fatalf("removal should only be pending for its peer chain")
+	}
+
+	go e.Dispatch()
+	e.Wait()
+	e.Stop()
+
+	if e.PendingRemoval(platformChainID, utxoID) {
+		t.Fatalf("removal shouldn't be pending after it's applied")
+	}
+	if _, err := m.NewSharedMemory(chainID).Get(platformChainID, [][]byte{utxoID[:]}); err == nil {
+		t.Fatalf("utxo should have been removed from shared memory")
+	}
+	if has, err := intentDB.Has(uint64ToBytes(0)); err != nil {
+		t.Fatal(err)
+	} else if has {
+		t.Fatalf("intent should have been deleted with its application")
+	}
+}
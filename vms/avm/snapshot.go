@@ -0,0 +1,58 @@
+// (c) 2019-2020, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package avm
+
+import (
+	"github.com/ava-labs/avalanchego/cache"
+	"github.com/ava-labs/avalanchego/ids"
+	"github.com/ava-labs/avalanchego/snow/choices"
+	"github.com/ava-labs/avalanchego/vms/components/avax"
+)
+
+const snapshotCacheSize = 4096
+
+// newSnapshot returns a read-only view of the committed state of the VM.
+//
+// Accepting a tx writes several keys to the versiondb [vm.db], which are only
+// written to [vm.baseDB], as a single batch, once the whole acceptance is
+// committed. Reading [vm.baseDB] therefore never observes a partially applied
+// acceptance. The snapshot's caches must be advanced, by advanceSnapshot, at
+// each acceptance boundary.
+func (vm *VM) newSnapshot() *prefixedState {
+	return &prefixedState{
+		state: &state{State: avax.State{
+			Cache:        &cache.LRU{Size: snapshotCacheSize},
+			DB:           vm.baseDB,
+			GenesisCodec: vm.genesisCodec,
+			Codec:        vm.codec,
+		}},
+
+		// The prefixed IDs don't depend on the state, so they're shared
+		tx:       vm.state.tx,
+		utxo:     vm.state.utxo,
+		txStatus: vm.state.txStatus,
+
+		balances: &cache.LRU{Size: balancesCacheSize},
+	}
+}
+
+// advanceSnapshot is called once changes to the state have been committed, so
+// that reads of the snapshot reflect them.
+func (vm *VM) advanceSnapshot() {
+	vm.snapshot.state.Cache.Flush()
+	for addr := range vm.state.staleBalances {
+		vm.snapshot.balances.Evict(addr)
+	}
+	vm.state.staleBalances.Clear()
+}
+
+// snapshotStatus returns the status of [txID] as of the last acceptance
+// boundary
+func (vm *VM) snapshotStatus(txID ids.ID) choices.Status {
+	status, err := vm.snapshot.Status(txID)
+	if err != nil {
+		return choices.Unknown
+	}
+	return status
+}
@@ -0,0 +1,85 @@
+// (c) 2019-2020, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package avm
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/ava-labs/avalanchego/api"
+	"github.com/ava-labs/avalanchego/ids"
+	"github.com/ava-labs/avalanchego/snow/choices"
+	"github.com/ava-labs/avalanchego/vms/components/avax"
+	"github.com/ava-labs/avalanchego/vms/secp256k1fx"
+)
+
+func TestSnapshotIgnoresUncommittedState(t *testing.T) {
+	assert := assert.New(t)
+
+	_, vm, s, _ := setup(t)
+	defer func() {
+		assert.NoError(vm.Shutdown())
+		vm.ctx.Lock.Unlock()
+	}()
+
+	addr := ids.GenerateTestShortID()
+	addrStr, err := vm.FormatLocalAddress(addr)
+	assert.NoError(err)
+	utxo := &avax.UTXO{
+		UTXOID: avax.UTXOID{TxID: ids.GenerateTestID()},
+		Asset:  avax.Asset{ID: vm.ctx.AVAXAssetID},
+		Out: &secp256k1fx.TransferOutput{
+			Amt: 1337,
+			OutputOwners: secp256k1fx.OutputOwners{
+				Threshold: 1,
+				Addrs:     []ids.ShortID{addr},
+			},
+		},
+	}
+	txID := ids.GenerateTestID()
+
+	getState := func() (int, uint64, choices.Status) {
+		utxosReply := &api.GetUTXOsReply{}
+		assert.NoError(s.GetUTXOs(nil, &api.GetUTXOsArgs{Addresses: []string{addrStr}}, utxosReply))
+		balanceReply := &GetBalanceReply{}
+		assert.NoError(s.GetBalance(nil, &GetBalanceArgs{Address: addrStr, AssetID: vm.ctx.AVAXAssetID.String()}, balanceReply))
+		statusReply := &GetTxStatusReply{}
+		assert.NoError(s.GetTxStatus(nil, &api.JSONTxID{TxID: txID}, statusReply))
+		return len(utxosReply.UTXOs), uint64(balanceReply.Balance), statusReply.Status
+	}
+
+	// Changes that are aborted are never observed
+	assert.NoError(vm.state.FundUTXO(utxo))
+	assert.NoError(vm.state.SetStatus(txID, choices.Accepted))
+	numUTXOs, balance, status := getState()
+	assert.Equal(0, numUTXOs)
+	assert.Zero(balance)
+	assert.Equal(choices.Unknown, status)
+	vm.db.Abort()
+	vm.advanceSnapshot()
+	numUTXOs, balance, status = getState()
+	assert.Equal(0, numUTXOs)
+	assert.Zero(balance)
+	assert.Equal(choices.Unknown, status)
+
+	// Changes are observed once they're committed
+	assert.NoError(vm.state.FundUTXO(utxo))
+	assert.NoError(vm.state.SetStatus(txID, choices.Accepted))
+	commitState(t, vm)
+	numUTXOs, balance, status = getState()
+	assert.Equal(1, numUTXOs)
+	assert.Equal(uint64(1337), balance)
+	assert.Equal(choices.Accepted, status)
+
+	// Spending the UTXO isn't observed until it's committed
+	assert.NoError(vm.state.SpendUTXO(utxo.InputID()))
+	numUTXOs, balance, _ = getState()
+	assert.Equal(1, numUTXOs)
+	assert.Equal(uint64(1337), balance)
+	commitState(t, vm)
+	numUTXOs, balance, _ = getState()
+	assert.Equal(0, numUTXOs)
+	assert.Zero(balance)
+}
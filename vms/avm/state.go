@@ -12,7 +12,10 @@ import (
 )
 
 var (
-	errCacheTypeMismatch = errors.New("type returned from cache doesn't match the expected type")
+	errCacheTypeMismatch    = errors.New("type returned from cache doesn't match the expected type")
+	errWrongUInt64Length    = errors.New("stored uint64 has the wrong length")
+	errWrongMemoKeyLength   = errors.New("stored memo index key has the wrong length")
+	errWrongRejectionLength = errors.New("stored rejection has the wrong length")
 )
 
 func uniqueID(id ids.ID, prefix uint64, cacher cache.Cacher) ids.ID {
@@ -7,15 +7,10 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
-	"math"
 	"net/http"
 
-	"github.com/ava-labs/avalanchego/codec"
-	"github.com/ava-labs/avalanchego/codec/linearcodec"
-	"github.com/ava-labs/avalanchego/codec/reflectcodec"
 	"github.com/ava-labs/avalanchego/ids"
 	"github.com/ava-labs/avalanchego/utils/formatting"
-	"github.com/ava-labs/avalanchego/utils/wrappers"
 	"github.com/ava-labs/avalanchego/vms/components/avax"
 	"github.com/ava-labs/avalanchego/vms/secp256k1fx"
 
@@ -59,25 +54,9 @@ type BuildGenesisReply struct {
 // BuildGenesis returns the UTXOs such that at least one address in [args.Addresses] is
 // referenced in the UTXO.
 func (ss *StaticService) BuildGenesis(_ *http.Request, args *BuildGenesisArgs, reply *BuildGenesisReply) error {
-	errs := wrappers.Errs{}
-
-	c := linearcodec.New(reflectcodec.DefaultTagName, 1<<20)
-	manager := codec.NewManager(math.MaxUint32)
-	errs.Add(
-		c.RegisterType(&BaseTx{}),
-		c.RegisterType(&CreateAssetTx{}),
-		c.RegisterType(&OperationTx{}),
-		c.RegisterType(&ImportTx{}),
-		c.RegisterType(&ExportTx{}),
-		c.RegisterType(&secp256k1fx.TransferInput{}),
-		c.RegisterType(&secp256k1fx.MintOutput{}),
-		c.RegisterType(&secp256k1fx.TransferOutput{}),
-		c.RegisterType(&secp256k1fx.MintOperation{}),
-		c.RegisterType(&secp256k1fx.Credential{}),
-		manager.RegisterCodec(codecVersion, c),
-	)
-	if errs.Errored() {
-		return errs.Err
+	manager, err := newGenesisCodec()
+	if err != nil {
+		return err
 	}
 
 	g := Genesis{}
@@ -116,11 +95,7 @@ func (ss *StaticService) BuildGenesis(_ *http.Request, args *BuildGenesisArgs, r
 						if err := json.Unmarshal(b, &holder); err != nil {
 							return fmt.Errorf("problem unmarshaling holder: %w", err)
 						}
-						_, addrbuff, err := formatting.ParseBech32(holder.Address)
-						if err != nil {
-							return fmt.Errorf("problem parsing holder address: %w", err)
-						}
-						addr, err := ids.ToShortID(addrbuff)
+						addr, err := ids.ShortFromBech32("", holder.Address)
 						if err != nil {
 							return fmt.Errorf("problem parsing holder address: %w", err)
 						}
@@ -149,11 +124,7 @@ func (ss *StaticService) BuildGenesis(_ *http.Request, args *BuildGenesisArgs, r
 							},
 						}
 						for _, address := range owners.Minters {
-							_, addrbuff, err := formatting.ParseBech32(address)
-							if err != nil {
-								return fmt.Errorf("problem parsing minters address: %w", err)
-							}
-							addr, err := ids.ToShortID(addrbuff)
+							addr, err := ids.ShortFromBech32("", address)
 							if err != nil {
 								return fmt.Errorf("problem parsing minters address: %w", err)
 							}
@@ -173,11 +144,10 @@ func (ss *StaticService) BuildGenesis(_ *http.Request, args *BuildGenesisArgs, r
 		asset.Sort()
 		g.Txs = append(g.Txs, &asset)
 	}
-	g.Sort()
 
-	b, err := manager.Marshal(codecVersion, &g)
+	b, err := MarshalGenesis(&g)
 	if err != nil {
-		return fmt.Errorf("problem marshaling genesis: %w", err)
+		return err
 	}
 
 	reply.Bytes, err = formatting.Encode(args.Encoding, b)
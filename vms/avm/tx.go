@@ -41,6 +41,9 @@ type UnsignedTx interface {
 		numFxs int,
 	) error
 	SemanticVerify(vm *VM, tx UnsignedTx, creds []verify.Verifiable) error
+	// AtomicOperations returns the shared memory operations performed when
+	// the tx is accepted, or nil if there are none
+	AtomicOperations(vm *VM) (*atomicOperations, error)
 	ExecuteWithSideEffects(vm *VM, batch database.Batch) error
 }
 
@@ -100,6 +103,20 @@ func (t *Tx) SemanticVerify(vm *VM, tx UnsignedTx) error {
 	return t.UnsignedTx.SemanticVerify(vm, tx, t.Creds)
 }
 
+// ParseTx parses [txBytes] with [c] and initializes the tx
+func ParseTx(c codec.Manager, txBytes []byte) (*Tx, error) {
+	tx := &Tx{}
+	if _, err := c.Unmarshal(txBytes, tx); err != nil {
+		return nil, err
+	}
+	unsignedBytes, err := c.Marshal(codecVersion, &tx.UnsignedTx)
+	if err != nil {
+		return nil, err
+	}
+	tx.Initialize(unsignedBytes, txBytes)
+	return tx, nil
+}
+
 // SignSECP256K1Fx ...
 func (t *Tx) SignSECP256K1Fx(c codec.Manager, signers [][]*crypto.PrivateKeySECP256K1R) error {
 	unsignedBytes, err := c.Marshal(codecVersion, &t.UnsignedTx)
@@ -0,0 +1,79 @@
+// (c) 2019-2020, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+// Package txbuilder builds, hashes, and signs AVM txs without a node, so that
+// wallets can produce the bytes the AVM expects without copying its codec.
+package txbuilder
+
+import (
+	"math"
+
+	"github.com/ava-labs/avalanchego/codec"
+	"github.com/ava-labs/avalanchego/codec/linearcodec"
+	"github.com/ava-labs/avalanchego/utils/logging"
+	"github.com/ava-labs/avalanchego/utils/timer"
+	"github.com/ava-labs/avalanchego/utils/wrappers"
+	"github.com/ava-labs/avalanchego/vms/avm"
+	"github.com/ava-labs/avalanchego/vms/nftfx"
+	"github.com/ava-labs/avalanchego/vms/propertyfx"
+	"github.com/ava-labs/avalanchego/vms/secp256k1fx"
+)
+
+// The tx types of the AVM, so that wallets only need to import this package
+type (
+	Tx            = avm.Tx
+	UnsignedTx    = avm.UnsignedTx
+	BaseTx        = avm.BaseTx
+	CreateAssetTx = avm.CreateAssetTx
+	OperationTx   = avm.OperationTx
+	ImportTx      = avm.ImportTx
+	ExportTx      = avm.ExportTx
+	InitialState  = avm.InitialState
+	Operation     = avm.Operation
+)
+
+var (
+	_ secp256k1fx.VM = &fxVM{}
+)
+
+// NewCodec returns the codec of an AVM chain that runs [fxs], in the order the
+// chain was created with. The types of an fx are identified by its position,
+// so the order must match the chain's.
+func NewCodec(fxs ...avm.Fx) (codec.Manager, error) {
+	c := linearcodec.NewDefault()
+	manager := codec.NewManager(math.MaxInt32)
+	errs := wrappers.Errs{}
+	errs.Add(
+		c.RegisterType(&avm.BaseTx{}),
+		c.RegisterType(&avm.CreateAssetTx{}),
+		c.RegisterType(&avm.OperationTx{}),
+		c.RegisterType(&avm.ImportTx{}),
+		c.RegisterType(&avm.ExportTx{}),
+	)
+
+	vm := &fxVM{registry: c}
+	for _, fx := range fxs {
+		if errs.Errored() {
+			break
+		}
+		errs.Add(fx.Initialize(vm))
+	}
+	errs.Add(manager.RegisterCodec(avm.CodecVersion, c))
+	return manager, errs.Err
+}
+
+// NewXChainCodec returns the codec of the X-Chain, which runs the secp256k1,
+// nft, and property fxs
+func NewXChainCodec() (codec.Manager, error) {
+	return NewCodec(&secp256k1fx.Fx{}, &nftfx.Fx{}, &propertyfx.Fx{})
+}
+
+// fxVM is the minimal VM that fxs register their types with
+type fxVM struct {
+	clock    timer.Clock
+	registry codec.Registry
+}
+
+func (vm *fxVM) CodecRegistry() codec.Registry { return vm.registry }
+func (vm *fxVM) Clock() *timer.Clock           { return &vm.clock }
+func (vm *fxVM) Logger() logging.Logger        { return logging.NoLog{} }
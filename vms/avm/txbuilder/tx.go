@@ -0,0 +1,46 @@
+// (c) 2019-2020, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package txbuilder
+
+import (
+	"github.com/ava-labs/avalanchego/codec"
+	"github.com/ava-labs/avalanchego/utils/crypto"
+	"github.com/ava-labs/avalanchego/utils/hashing"
+	"github.com/ava-labs/avalanchego/vms/avm"
+)
+
+// UnsignedBytes returns the bytes of [utx] that its credentials sign
+func UnsignedBytes(c codec.Manager, utx UnsignedTx) ([]byte, error) {
+	return c.Marshal(avm.CodecVersion, &utx)
+}
+
+// UnsignedHash returns the hash of [utx] that its credentials sign, for
+// signers that sign hashes rather than messages, such as hardware wallets
+func UnsignedHash(c codec.Manager, utx UnsignedTx) ([]byte, error) {
+	unsignedBytes, err := UnsignedBytes(c, utx)
+	if err != nil {
+		return nil, err
+	}
+	return hashing.ComputeHash256(unsignedBytes), nil
+}
+
+// SignSECP256K1Fx returns [utx] signed by [signers]. The i-th credential is
+// signed by the keys in signers[i], so there must be one set of keys per input
+// and operation of [utx], in order.
+func SignSECP256K1Fx(c codec.Manager, utx UnsignedTx, signers [][]*crypto.PrivateKeySECP256K1R) (*Tx, error) {
+	tx := &Tx{UnsignedTx: utx}
+	return tx, tx.SignSECP256K1Fx(c, signers)
+}
+
+// SignNFTFx returns [utx] signed by [signers], with nft fx credentials
+func SignNFTFx(c codec.Manager, utx UnsignedTx, signers [][]*crypto.PrivateKeySECP256K1R) (*Tx, error) {
+	tx := &Tx{UnsignedTx: utx}
+	return tx, tx.SignNFTFx(c, signers)
+}
+
+// Parse returns the tx encoded by [txBytes]. Only the canonical encoding of a
+// tx is accepted, as it's the only one the AVM accepts.
+func Parse(c codec.Manager, txBytes []byte) (*Tx, error) {
+	return avm.ParseTx(c, txBytes)
+}
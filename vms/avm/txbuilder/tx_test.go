@@ -0,0 +1,161 @@
+// (c) 2019-2020, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package txbuilder
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/ava-labs/avalanchego/codec"
+	"github.com/ava-labs/avalanchego/codec/linearcodec"
+	"github.com/ava-labs/avalanchego/ids"
+	"github.com/ava-labs/avalanchego/utils/crypto"
+	"github.com/ava-labs/avalanchego/utils/wrappers"
+	"github.com/ava-labs/avalanchego/vms/avm"
+	"github.com/ava-labs/avalanchego/vms/components/avax"
+	"github.com/ava-labs/avalanchego/vms/secp256k1fx"
+)
+
+func newKey(t *testing.T) *crypto.PrivateKeySECP256K1R {
+	factory := crypto.FactorySECP256K1R{}
+	key, err := factory.NewPrivateKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return key.(*crypto.PrivateKeySECP256K1R)
+}
+
+func newBaseTx(key *crypto.PrivateKeySECP256K1R) *BaseTx {
+	assetID := ids.GenerateTestID()
+	return &BaseTx{BaseTx: avax.BaseTx{
+		NetworkID:    10,
+		BlockchainID: ids.GenerateTestID(),
+		Outs: []*avax.TransferableOutput{{
+			Asset: avax.Asset{ID: assetID},
+			Out: &secp256k1fx.TransferOutput{
+				Amt: 12345,
+				OutputOwners: secp256k1fx.OutputOwners{
+					Threshold: 1,
+					Addrs:     []ids.ShortID{key.PublicKey().Address()},
+				},
+			},
+		}},
+		Ins: []*avax.TransferableInput{{
+			UTXOID: avax.UTXOID{
+				TxID:        ids.GenerateTestID(),
+				OutputIndex: 1,
+			},
+			Asset: avax.Asset{ID: assetID},
+			In: &secp256k1fx.TransferInput{
+				Amt: 54321,
+				Input: secp256k1fx.Input{
+					SigIndices: []uint32{0},
+				},
+			},
+		}},
+	}}
+}
+
+func TestSignAndParse(t *testing.T) {
+	c, err := NewXChainCodec()
+	if err != nil {
+		t.Fatal(err)
+	}
+	key := newKey(t)
+	utx := newBaseTx(key)
+
+	hash, err := UnsignedHash(c, utx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tx, err := SignSECP256K1Fx(c, utx, [][]*crypto.PrivateKeySECP256K1R{{key}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	parsed, err := Parse(c, tx.Bytes())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if parsed.ID() != tx.ID() {
+		t.Fatalf("parsed tx has ID %s but signed tx has ID %s", parsed.ID(), tx.ID())
+	}
+	if !bytes.Equal(parsed.UnsignedBytes(), tx.UnsignedBytes()) {
+		t.Fatalf("parsed tx has different unsigned bytes")
+	}
+
+	creds := parsed.Credentials()
+	if len(creds) != 1 {
+		t.Fatalf("expected 1 credential but got %d", len(creds))
+	}
+	cred, ok := creds[0].(*secp256k1fx.Credential)
+	if !ok {
+		t.Fatalf("expected a secp256k1fx credential but got %T", creds[0])
+	}
+	factory := crypto.FactorySECP256K1R{}
+	pk, err := factory.RecoverHashPublicKey(hash, cred.Sigs[0][:])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if pk.Address() != key.PublicKey().Address() {
+		t.Fatalf("credential should have been signed by the key")
+	}
+}
+
+func TestParseRejectsNonCanonicalTx(t *testing.T) {
+	c, err := NewXChainCodec()
+	if err != nil {
+		t.Fatal(err)
+	}
+	key := newKey(t)
+	tx, err := SignSECP256K1Fx(c, newBaseTx(key), [][]*crypto.PrivateKeySECP256K1R{{key}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Parse(c, append(tx.Bytes(), 0)); err == nil {
+		t.Fatalf("should have failed to parse a tx with trailing bytes")
+	}
+}
+
+// The types of the X-Chain are identified by the order the AVM and its fxs
+// register them in, which the codec must reproduce
+func TestXChainCodecMatchesVM(t *testing.T) {
+	c := linearcodec.NewDefault()
+	expected := codec.NewDefaultManager()
+	errs := wrappers.Errs{}
+	errs.Add(
+		c.RegisterType(&avm.BaseTx{}),
+		c.RegisterType(&avm.CreateAssetTx{}),
+		c.RegisterType(&avm.OperationTx{}),
+		c.RegisterType(&avm.ImportTx{}),
+		c.RegisterType(&avm.ExportTx{}),
+		c.RegisterType(&secp256k1fx.TransferInput{}),
+		c.RegisterType(&secp256k1fx.MintOutput{}),
+		c.RegisterType(&secp256k1fx.TransferOutput{}),
+		c.RegisterType(&secp256k1fx.MintOperation{}),
+		c.RegisterType(&secp256k1fx.Credential{}),
+		expected.RegisterCodec(avm.CodecVersion, c),
+	)
+	if errs.Errored() {
+		t.Fatal(errs.Err)
+	}
+
+	m, err := NewXChainCodec()
+	if err != nil {
+		t.Fatal(err)
+	}
+	key := newKey(t)
+	tx := &Tx{UnsignedTx: newBaseTx(key)}
+	expectedBytes, err := expected.Marshal(avm.CodecVersion, tx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	txBytes, err := m.Marshal(avm.CodecVersion, tx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(expectedBytes, txBytes) {
+		t.Fatalf("codec should encode txs as the VM does")
+	}
+}
@@ -11,6 +11,8 @@ import (
 	"github.com/ava-labs/avalanchego/snow/choices"
 	"github.com/ava-labs/avalanchego/snow/consensus/snowstorm"
 	"github.com/ava-labs/avalanchego/vms/components/avax"
+
+	safemath "github.com/ava-labs/avalanchego/utils/math"
 )
 
 var (
@@ -145,20 +147,68 @@ func (tx *UniqueTx) Accept() error {
 	}
 
 	txID := tx.ID()
+	index, err := tx.vm.state.AcceptedTxCount()
+	if err != nil {
+		tx.vm.ctx.Log.Error("Failed to index accepted tx %s due to %s", txID, err)
+		return err
+	}
+	if err := tx.vm.state.AddAcceptedTx(txID); err != nil {
+		tx.vm.ctx.Log.Error("Failed to index accepted tx %s due to %s", txID, err)
+		return err
+	}
+	if err := tx.vm.indexMemo(txID, tx.UnsignedTx); err != nil {
+		tx.vm.ctx.Log.Error("Failed to index the memo of accepted tx %s due to %s", txID, err)
+		return err
+	}
+	pauses, err := tx.vm.applyAssetPauses(tx.UnsignedTx)
+	if err != nil {
+		tx.vm.ctx.Log.Error("Failed to record the asset pauses of accepted tx %s due to %s", txID, err)
+		return err
+	}
+
+	// If the atomic operations are applied in the background, an intent to
+	// apply them is committed along with the acceptance
+	var ops *atomicOperations
+	if tx.vm.asyncSideEffects {
+		ops, err = tx.AtomicOperations(tx.vm)
+		if err != nil {
+			tx.vm.ctx.Log.Error("Failed to calculate atomic operations for %s due to %s", txID, err)
+			return err
+		}
+		if ops != nil {
+			if err := tx.vm.state.AddSideEffectIntent(index, txID); err != nil {
+				tx.vm.ctx.Log.Error("Failed to record atomic operations for %s due to %s", txID, err)
+				return err
+			}
+		}
+	}
+
 	commitBatch, err := tx.vm.db.CommitBatch()
 	if err != nil {
 		tx.vm.ctx.Log.Error("Failed to calculate CommitBatch for %s due to %s", txID, err)
 		return err
 	}
 
-	if err := tx.ExecuteWithSideEffects(tx.vm, commitBatch); err != nil {
+	if ops == nil {
+		err = tx.ExecuteWithSideEffects(tx.vm, commitBatch)
+	} else {
+		err = commitBatch.Write()
+	}
+	if err != nil {
 		tx.vm.ctx.Log.Error("Failed to commit accept %s due to %s", txID, err)
 		return err
 	}
+	tx.vm.advanceSnapshot()
+	tx.vm.invalidateVerifications()
+	tx.vm.processing.Remove(txID)
+	if ops != nil {
+		tx.vm.sideEffects.Add(index, ops)
+	}
 
 	tx.vm.ctx.Log.Verbo("Accepted Tx: %s", txID)
 
 	tx.vm.pubsub.Publish("accepted", txID)
+	tx.vm.publishAssetPauses(pauses)
 	tx.vm.walletService.decided(txID)
 
 	tx.deps = nil // Needed to prevent a memory leak
@@ -166,6 +216,16 @@ func (tx *UniqueTx) Accept() error {
 	return nil
 }
 
+// RecordRejection implements the snowstorm.RejectionTx interface. The reason is
+// committed along with the rejection.
+func (tx *UniqueTx) RecordRejection(reason snowstorm.Rejection) error {
+	if err := tx.vm.state.SetRejection(tx.ID(), reason); err != nil {
+		tx.vm.ctx.Log.Error("Failed to record the rejection of tx %s due to %s", tx.txID, err)
+		return err
+	}
+	return nil
+}
+
 // Reject is called when the transaction was finalized as rejected by consensus
 func (tx *UniqueTx) Reject() error {
 	defer tx.vm.db.Abort()
@@ -182,6 +242,9 @@ func (tx *UniqueTx) Reject() error {
 		tx.vm.ctx.Log.Error("Failed to commit reject %s due to %s", tx.txID, err)
 		return err
 	}
+	tx.vm.advanceSnapshot()
+	tx.vm.invalidateVerifications()
+	tx.vm.processing.Remove(txID)
 
 	tx.vm.pubsub.Publish("rejected", txID)
 	tx.vm.walletService.decided(txID)
@@ -268,6 +331,62 @@ func (tx *UniqueTx) UTXOs() []*avax.UTXO {
 	return tx.utxos
 }
 
+// Fee implements the snowstorm.FeeTx interface. The fee is the amount of AVAX
+// that the tx consumes but doesn't produce. Returns 0 if the tx is unknown or
+// its amounts overflow.
+func (tx *UniqueTx) Fee() uint64 {
+	tx.refresh()
+	if tx.Tx == nil {
+		return 0
+	}
+
+	var (
+		ins  []*avax.TransferableInput
+		outs []*avax.TransferableOutput
+	)
+	switch utx := tx.UnsignedTx.(type) {
+	case *BaseTx:
+		ins, outs = utx.Ins, utx.Outs
+	case *CreateAssetTx:
+		ins, outs = utx.Ins, utx.Outs
+	case *OperationTx:
+		ins, outs = utx.Ins, utx.Outs
+	case *ImportTx:
+		ins = append(append(ins, utx.Ins...), utx.ImportedIns...)
+		outs = utx.Outs
+	case *ExportTx:
+		ins = utx.Ins
+		outs = append(append(outs, utx.Outs...), utx.ExportedOuts...)
+	default:
+		return 0
+	}
+
+	avaxAssetID := tx.vm.ctx.AVAXAssetID
+	consumed, produced := uint64(0), uint64(0)
+	for _, in := range ins {
+		if in.AssetID() != avaxAssetID {
+			continue
+		}
+		var err error
+		if consumed, err = safemath.Add64(consumed, in.Input().Amount()); err != nil {
+			return 0
+		}
+	}
+	for _, out := range outs {
+		if out.AssetID() != avaxAssetID {
+			continue
+		}
+		var err error
+		if produced, err = safemath.Add64(produced, out.Output().Amount()); err != nil {
+			return 0
+		}
+	}
+	if produced >= consumed {
+		return 0
+	}
+	return consumed - produced
+}
+
 // Bytes returns the binary representation of this transaction
 func (tx *UniqueTx) Bytes() []byte {
 	tx.refresh()
@@ -294,6 +413,7 @@ func (tx *UniqueTx) Verify() error {
 	}
 
 	tx.verifiedState = true
+	tx.vm.trackProcessing(tx)
 	tx.vm.pubsub.Publish("verified", tx.ID())
 	return nil
 }
@@ -332,5 +452,15 @@ func (tx *UniqueTx) SemanticVerify() error {
 		return tx.validity
 	}
 
-	return tx.Tx.SemanticVerify(tx.vm, tx.UnsignedTx)
+	if !cacheableVerification(tx.UnsignedTx) {
+		return tx.Tx.SemanticVerify(tx.vm, tx.UnsignedTx)
+	}
+
+	txID := tx.ID()
+	if err, ok := tx.vm.cachedVerification(txID); ok {
+		return err
+	}
+	err := tx.Tx.SemanticVerify(tx.vm, tx.UnsignedTx)
+	tx.vm.cacheVerification(txID, err)
+	return err
 }
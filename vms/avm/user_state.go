@@ -14,7 +14,12 @@ import (
 
 var addresses = ids.Empty
 
-type userState struct{ vm *VM }
+type userState struct {
+	vm *VM
+	// Name of the keystore user whose database is used. If empty, the user's
+	// addresses aren't cached.
+	username string
+}
 
 // SetAddresses ...
 func (s *userState) SetAddresses(db database.Database, addrs []ids.ShortID) error {
@@ -22,11 +27,22 @@ func (s *userState) SetAddresses(db database.Database, addrs []ids.ShortID) erro
 	if err != nil {
 		return err
 	}
-	return db.Put(addresses[:], bytes)
+	if err := db.Put(addresses[:], bytes); err != nil {
+		return err
+	}
+	if s.username != "" {
+		// Copy [addrs] so that the caller can't modify the cached addresses
+		s.vm.userAddresses.Put(s.username, append([]ids.ShortID(nil), addrs...))
+	}
+	return nil
 }
 
 // Addresses ...
 func (s *userState) Addresses(db database.Database) ([]ids.ShortID, error) {
+	if addrs, ok := s.cachedAddresses(db); ok {
+		return addrs, nil
+	}
+
 	bytes, err := db.Get(addresses[:])
 	if err != nil {
 		return nil, err
@@ -35,9 +51,41 @@ func (s *userState) Addresses(db database.Database) ([]ids.ShortID, error) {
 	if _, err := s.vm.codec.Unmarshal(bytes, &addresses); err != nil {
 		return nil, err
 	}
+	if s.username != "" {
+		s.vm.userAddresses.Put(s.username, addresses)
+	}
 	return addresses, nil
 }
 
+// cachedAddresses returns the cached addresses of the user. The user may have
+// been deleted, or re-imported, through the keystore since the addresses were
+// cached, so the cached addresses are only used if [db] still holds the
+// address list and the key of the newest address. Checking for keys doesn't
+// require decrypting them.
+func (s *userState) cachedAddresses(db database.Database) ([]ids.ShortID, bool) {
+	if s.username == "" {
+		return nil, false
+	}
+	addrsIntf, ok := s.vm.userAddresses.Get(s.username)
+	if !ok {
+		return nil, false
+	}
+	addrs := addrsIntf.([]ids.ShortID)
+
+	keys := [][]byte{addresses[:]}
+	if len(addrs) > 0 {
+		keys = append(keys, addrs[len(addrs)-1].Bytes())
+	}
+	for _, key := range keys {
+		if has, err := db.Has(key); err != nil || !has {
+			s.vm.userAddresses.Evict(s.username)
+			return nil, false
+		}
+	}
+	// Copy [addrs] so that the caller can't modify the cached addresses
+	return append([]ids.ShortID(nil), addrs...), true
+}
+
 // Keychain returns a Keychain for the user database
 // If [addresses] is non-empty it fetches only the keys
 // in addresses. If any key is missing, an error is returned.
@@ -0,0 +1,65 @@
+// (c) 2019-2020, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package avm
+
+import (
+	"github.com/ava-labs/avalanchego/ids"
+)
+
+const (
+	verificationCacheSize = 8192
+)
+
+// verification is the result of semantically verifying a tx
+type verification struct {
+	// The state version and unix time that the tx was verified at
+	stateVersion uint64
+	time         uint64
+	err          error
+}
+
+// invalidateVerifications records that the state txs are verified against has
+// changed, so that previous verification results are no longer used.
+//
+// Must be called whenever a tx is accepted or rejected, a tx becomes
+// processing, or the VM finishes bootstrapping.
+func (vm *VM) invalidateVerifications() { vm.stateVersion++ }
+
+// cacheableVerification returns true if the result of verifying [utx] only
+// depends on the state version and the time. Import txs also depend on shared
+// memory, which other chains change.
+func cacheableVerification(utx UnsignedTx) bool {
+	_, isImport := utx.(*ImportTx)
+	return !isImport
+}
+
+// cachedVerification returns the result of verifying the tx [txID], if it was
+// verified at the current state version. Failures are only reused within the
+// second they happened in, as time locks may expire without the state version
+// changing.
+func (vm *VM) cachedVerification(txID ids.ID) (error, bool) {
+	verificationIntf, ok := vm.verifications.Get(txID)
+	if !ok {
+		vm.numVerificationCacheMisses.Inc()
+		return nil, false
+	}
+	verification := verificationIntf.(*verification)
+	if verification.stateVersion != vm.stateVersion ||
+		(verification.err != nil && verification.time != vm.clock.Unix()) {
+		vm.numVerificationCacheMisses.Inc()
+		return nil, false
+	}
+	vm.numVerificationCacheHits.Inc()
+	return verification.err, true
+}
+
+// cacheVerification records that verifying the tx [txID] at the current state
+// version resulted in [err]
+func (vm *VM) cacheVerification(txID ids.ID, err error) {
+	vm.verifications.Put(txID, &verification{
+		stateVersion: vm.stateVersion,
+		time:         vm.clock.Unix(),
+		err:          err,
+	})
+}
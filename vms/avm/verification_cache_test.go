@@ -0,0 +1,63 @@
+// (c) 2019-2020, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package avm
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/ava-labs/avalanchego/cache"
+	"github.com/ava-labs/avalanchego/ids"
+)
+
+func TestVerificationCache(t *testing.T) {
+	vm := &VM{
+		verifications: &cache.LRU{Size: verificationCacheSize},
+	}
+	assert.NoError(t, vm.metrics.Initialize("", prometheus.NewRegistry()))
+	vm.clock.Set(time.Unix(1000, 0))
+
+	validTxID := ids.ID{1}
+	invalidTxID := ids.ID{2}
+	errInvalid := errors.New("invalid")
+
+	_, ok := vm.cachedVerification(validTxID)
+	assert.False(t, ok)
+
+	vm.cacheVerification(validTxID, nil)
+	vm.cacheVerification(invalidTxID, errInvalid)
+
+	err, ok := vm.cachedVerification(validTxID)
+	assert.True(t, ok)
+	assert.NoError(t, err)
+	err, ok = vm.cachedVerification(invalidTxID)
+	assert.True(t, ok)
+	assert.Equal(t, errInvalid, err)
+
+	// failures may be due to time locks, so they're not reused once time passes
+	vm.clock.Set(time.Unix(1001, 0))
+	_, ok = vm.cachedVerification(validTxID)
+	assert.True(t, ok)
+	_, ok = vm.cachedVerification(invalidTxID)
+	assert.False(t, ok)
+
+	// nothing is reused once the state changes
+	vm.invalidateVerifications()
+	_, ok = vm.cachedVerification(validTxID)
+	assert.False(t, ok)
+
+	assert.Equal(t, 3.0, testutil.ToFloat64(vm.numVerificationCacheHits))
+	assert.Equal(t, 3.0, testutil.ToFloat64(vm.numVerificationCacheMisses))
+}
+
+func TestImportTxVerificationNotCached(t *testing.T) {
+	assert.False(t, cacheableVerification(&ImportTx{}))
+	assert.True(t, cacheableVerification(&BaseTx{}))
+	assert.True(t, cacheableVerification(&OperationTx{}))
+}
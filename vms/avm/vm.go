@@ -19,6 +19,7 @@ import (
 	"github.com/ava-labs/avalanchego/codec/linearcodec"
 	"github.com/ava-labs/avalanchego/codec/reflectcodec"
 	"github.com/ava-labs/avalanchego/database"
+	"github.com/ava-labs/avalanchego/database/prefixdb"
 	"github.com/ava-labs/avalanchego/database/versiondb"
 	"github.com/ava-labs/avalanchego/ids"
 	"github.com/ava-labs/avalanchego/snow"
@@ -28,7 +29,6 @@ import (
 	"github.com/ava-labs/avalanchego/snow/engine/common"
 	"github.com/ava-labs/avalanchego/utils/constants"
 	"github.com/ava-labs/avalanchego/utils/crypto"
-	"github.com/ava-labs/avalanchego/utils/formatting"
 	"github.com/ava-labs/avalanchego/utils/logging"
 	"github.com/ava-labs/avalanchego/utils/timer"
 	"github.com/ava-labs/avalanchego/utils/wrappers"
@@ -48,11 +48,16 @@ const (
 	idCacheSize        = 30000
 	txCacheSize        = 30000
 	assetToFxCacheSize = 1024
+	balancesCacheSize  = 4096
+	usersCacheSize     = 1024
 	maxUTXOsToFetch    = 1024
 
-	codecVersion = 0
+	codecVersion = CodecVersion
 )
 
+// CodecVersion is the version of the codec txs are encoded with
+const CodecVersion = 0
+
 var (
 	errIncompatibleFx            = errors.New("incompatible feature extension")
 	errUnknownFx                 = errors.New("unknown feature extension")
@@ -60,6 +65,9 @@ var (
 	errWrongBlockchainID         = errors.New("wrong blockchain ID")
 	errBootstrapping             = errors.New("chain is currently bootstrapping")
 	errInsufficientFunds         = errors.New("insufficient funds")
+	errTxTooLarge                = errors.New("tx exceeds the maximum size")
+	errTooManyInputs             = errors.New("tx exceeds the maximum number of inputs")
+	errTooManyOutputs            = errors.New("tx exceeds the maximum number of outputs")
 
 	_ vertex.DAGVM = &VM{}
 )
@@ -84,6 +92,10 @@ type VM struct {
 	// State management
 	state *prefixedState
 
+	// Read-only view of the committed state, which API reads are served from
+	// so that they never observe a partially applied acceptance
+	snapshot *prefixedState
+
 	// Set to true once this VM is marked as `Bootstrapped` by the engine
 	bootstrapped bool
 
@@ -92,15 +104,51 @@ type VM struct {
 	// fee that must be burned by every non-state creating transaction
 	txFee uint64
 
+	// Limits on the shape of transactions. A value of 0 means no limit.
+	maxTxSize    int
+	maxTxInputs  int
+	maxTxOutputs int
+
+	// Should the status and UTXO indexes be rebuilt from the accepted txs on
+	// startup
+	reindex bool
+
+	// Should the memos of accepted txs be indexed
+	indexMemos bool
+
+	// Should the shared memory operations of accepted txs be applied in the
+	// background
+	asyncSideEffects bool
+	sideEffects      *sideEffectExecutor
+
 	// Asset ID --> Bit set with fx IDs the asset supports
 	assetToFxCache *cache.LRU
 
+	// Username --> the user's addresses
+	userAddresses *cache.LRU
+
+	// Tx ID --> *verification. Results are only reused while the state is at
+	// the [stateVersion] they were verified at.
+	verifications *cache.LRU
+	stateVersion  uint64
+
 	// Transaction issuing
 	timer        *timer.Timer
 	batchTimeout time.Duration
 	txs          []snowstorm.Tx
 	toEngine     chan<- common.Message
 
+	// Txs held until the inputs they spend unlock
+	scheduleTimer *timer.Timer
+	scheduledTxs  map[ids.ID]*scheduledTx
+
+	// Txs issued into consensus that aren't yet decided, indexed by the UTXOs
+	// they spend
+	processing *processingTxs
+
+	// Fill of the recent batches of txs, used to estimate fees
+	feeStats feeStats
+
 	baseDB database.Database
 	db     *versiondb.Database
 
@@ -131,6 +179,8 @@ func (vm *VM) Initialize(
 	vm.typeToFxIndex = map[reflect.Type]int{}
 	vm.Aliaser.Initialize()
 	vm.assetToFxCache = &cache.LRU{Size: assetToFxCacheSize}
+	vm.userAddresses = &cache.LRU{Size: usersCacheSize}
+	vm.verifications = &cache.LRU{Size: verificationCacheSize}
 
 	vm.pubsub = cjson.NewPubSubServer(ctx)
 
@@ -147,6 +197,8 @@ func (vm *VM) Initialize(
 		vm.pubsub.Register("accepted"),
 		vm.pubsub.Register("rejected"),
 		vm.pubsub.Register("verified"),
+		vm.pubsub.Register(pausedChannel),
+		vm.pubsub.Register(unpausedChannel),
 
 		c.RegisterType(&BaseTx{}),
 		c.RegisterType(&CreateAssetTx{}),
@@ -203,6 +255,7 @@ func (vm *VM) Initialize(
 
 		uniqueTx: &cache.EvictableLRU{Size: txCacheSize},
 	}
+	vm.snapshot = vm.newSnapshot()
 
 	if err := vm.initAliases(genesisBytes); err != nil {
 		return err
@@ -214,6 +267,34 @@ func (vm *VM) Initialize(
 		}
 	}
 
+	vm.sideEffects = newSideEffectExecutor(
+		ctx.Log,
+		ctx.SharedMemory,
+		prefixdb.NewNested(sideEffectIntents[:], vm.baseDB),
+	)
+	if err := vm.replaySideEffects(); err != nil {
+		return fmt.Errorf("couldn't replay atomic operations: %w", err)
+	}
+	if vm.asyncSideEffects {
+		go ctx.Log.RecoverAndPanic(vm.sideEffects.Dispatch)
+	}
+
+	// Resume an interrupted reindex even if it wasn't requested this time, as
+	// the indexes may have been left partially cleared or rebuilt.
+	_, reindexing, err := vm.state.ReindexProgress()
+	if err != nil {
+		return err
+	}
+	clearing, err := vm.state.ReindexClearing()
+	if err != nil {
+		return err
+	}
+	if vm.reindex || reindexing || clearing {
+		if err := vm.reindexAcceptedTxs(); err != nil {
+			return fmt.Errorf("couldn't reindex accepted txs: %w", err)
+		}
+	}
+
 	vm.timer = timer.NewTimer(func() {
 		ctx.Lock.Lock()
 		defer ctx.Lock.Unlock()
@@ -223,11 +304,36 @@ func (vm *VM) Initialize(
 	go ctx.Log.RecoverAndPanic(vm.timer.Dispatch)
 	vm.batchTimeout = batchTimeout
 
+	vm.scheduleTimer = timer.NewTimer(func() {
+		ctx.Lock.Lock()
+		defer ctx.Lock.Unlock()
+
+		vm.promoteScheduledTxs()
+	})
+	go ctx.Log.RecoverAndPanic(vm.scheduleTimer.Dispatch)
+	vm.scheduledTxs = make(map[ids.ID]*scheduledTx)
+	vm.processing = newProcessingTxs()
+
 	vm.walletService.vm = vm
 	vm.walletService.pendingTxMap = make(map[ids.ID]*list.Element)
 	vm.walletService.pendingTxOrdering = list.New()
 
-	return vm.db.Commit()
+	if err := vm.db.Commit(); err != nil {
+		return err
+	}
+	vm.advanceSnapshot()
+	return nil
+}
+
+// FlushCaches implements the common.CacheFlusher interface. Unique txs aren't
+// flushed, as they may still be referenced by consensus.
+func (vm *VM) FlushCaches() {
+	vm.assetToFxCache.Flush()
+	vm.state.state.Cache.Flush()
+	vm.state.tx.Flush()
+	vm.state.utxo.Flush()
+	vm.state.txStatus.Flush()
+	vm.snapshot.state.Cache.Flush()
 }
 
 // Bootstrapping is called by the consensus engine when it starts bootstrapping
@@ -254,6 +360,8 @@ func (vm *VM) Bootstrapped() error {
 		}
 	}
 	vm.bootstrapped = true
+	// Some txs are only verified fully once the VM is bootstrapped
+	vm.invalidateVerifications()
 	return nil
 }
 
@@ -267,8 +375,13 @@ func (vm *VM) Shutdown() error {
 	// So, the lock must be released before stopping the timer.
 	vm.ctx.Lock.Unlock()
 	vm.timer.Stop()
+	vm.scheduleTimer.Stop()
 	vm.ctx.Lock.Lock()
 
+	if vm.asyncSideEffects {
+		vm.sideEffects.Stop()
+	}
+
 	return vm.baseDB.Close()
 }
 
@@ -296,6 +409,7 @@ func (vm *VM) CreateHandlers() (map[string]*common.HTTPHandler, error) {
 		"":        {Handler: rpcServer},
 		"/wallet": {Handler: walletServer},
 		"/pubsub": {LockOptions: common.NoLock, Handler: vm.pubsub},
+		"/events": {LockOptions: common.NoLock, Handler: vm.pubsub.EventStream()},
 	}, err
 }
 
@@ -321,6 +435,7 @@ func (vm *VM) Pending() []snowstorm.Tx {
 
 	txs := vm.txs
 	vm.txs = nil
+	vm.feeStats.Observe(len(txs))
 	return txs
 }
 
@@ -358,13 +473,40 @@ func (vm *VM) IssueTx(b []byte) (ids.ID, error) {
 	if !vm.bootstrapped {
 		return ids.ID{}, errBootstrapping
 	}
+	// Reject oversized txs before spending any effort parsing them
+	if vm.maxTxSize > 0 && len(b) > vm.maxTxSize {
+		return ids.ID{}, fmt.Errorf("%w: %d bytes > %d bytes", errTxTooLarge, len(b), vm.maxTxSize)
+	}
 	tx, err := vm.parseTx(b)
 	if err != nil {
 		return ids.ID{}, err
 	}
-	if err := tx.verifyWithoutCacheWrites(); err != nil {
+	if err := vm.verifyTxLimits(tx.Tx); err != nil {
+		return ids.ID{}, err
+	}
+	if err := vm.verifyNotPaused(tx.UnsignedTx); err != nil {
 		return ids.ID{}, err
 	}
+	if err := tx.verifyWithoutCacheWrites(); err != nil {
+		// Txs that are only invalid because they spend inputs that unlock in
+		// the near future are held until they unlock, rather than making the
+		// issuer retry them
+		if !errors.Is(err, secp256k1fx.ErrTimelocked) {
+			return ids.ID{}, err
+		}
+		unlockTime := vm.unlockTime(tx)
+		now := vm.clock.Unix()
+		if unlockTime <= now || unlockTime > now+uint64(maxScheduleHorizon/time.Second) {
+			return ids.ID{}, err
+		}
+		if err := vm.verifyAt(tx, unlockTime); err != nil {
+			return ids.ID{}, err
+		}
+		if err := vm.scheduleTx(tx, unlockTime); err != nil {
+			return ids.ID{}, err
+		}
+		return tx.ID(), nil
+	}
 	vm.issueTx(tx)
 	return tx.ID(), nil
 }
@@ -433,6 +575,7 @@ func (vm *VM) GetAtomicUTXOs(
 // Only returns UTXOs associated with addresses >= [startAddr].
 // For address [startAddr], only returns UTXOs whose IDs are greater than [startUTXOID].
 // Given a ![paginate] input all utxos will be fetched
+// UTXOs are read from the snapshot of the last acceptance boundary.
 // Returns:
 // * The fetched UTXOs
 // * The address associated with the last UTXO fetched
@@ -480,7 +623,7 @@ func (vm *VM) getPaginatedUTXOs(addrs ids.ShortSet,
 
 		// Get UTXOs associated with [addr]. [searchSize] is used here to ensure
 		// that no UTXOs are dropped due to duplicated fetching.
-		utxoIDs, err := vm.state.Funds(addr.Bytes(), start, searchSize)
+		utxoIDs, err := vm.snapshot.Funds(addr.Bytes(), start, searchSize)
 		if err != nil {
 			return nil, ids.ShortID{}, ids.ID{}, fmt.Errorf("couldn't get UTXOs for address %s: %w", addr, err)
 		}
@@ -492,7 +635,7 @@ func (vm *VM) getPaginatedUTXOs(addrs ids.ShortSet,
 				continue
 			}
 
-			utxo, err := vm.state.UTXO(utxoID)
+			utxo, err := vm.snapshot.UTXO(utxoID)
 			if err != nil {
 				return nil, ids.ShortID{}, ids.ID{}, fmt.Errorf("couldn't get UTXO %s: %w", utxoID, err)
 			}
@@ -537,7 +680,7 @@ func (vm *VM) getAllUniqueAddressUTXOs(addr ids.ShortID, seen *ids.Set, utxos *[
 	lastIndex := ids.Empty
 
 	for {
-		utxoIDs, err := vm.state.Funds(addr.Bytes(), lastIndex, maxUTXOsToFetch) // Get UTXOs associated with [addr]
+		utxoIDs, err := vm.snapshot.Funds(addr.Bytes(), lastIndex, maxUTXOsToFetch) // Get UTXOs associated with [addr]
 		if err != nil {
 			return ids.ID{}, err
 		}
@@ -553,7 +696,7 @@ func (vm *VM) getAllUniqueAddressUTXOs(addr ids.ShortID, seen *ids.Set, utxos *[
 				continue
 			}
 
-			utxo, err := vm.state.UTXO(utxoID)
+			utxo, err := vm.snapshot.UTXO(utxoID)
 			if err != nil {
 				return ids.ID{}, err
 			}
@@ -659,6 +802,9 @@ func (vm *VM) initState(genesisBytes []byte) error {
 		if err := vm.state.SetStatus(txID, choices.Accepted); err != nil {
 			return err
 		}
+		if err := vm.state.AddAcceptedTx(txID); err != nil {
+			return err
+		}
 		for _, utxo := range tx.UTXOs() {
 			if err := vm.state.FundUTXO(utxo); err != nil {
 				return err
@@ -666,6 +812,10 @@ func (vm *VM) initState(genesisBytes []byte) error {
 		}
 	}
 
+	// Every tx accepted by this database is in the accepted tx index
+	if err := vm.state.SetLegacyAcceptedTxs(nil); err != nil {
+		return err
+	}
 	return vm.state.SetDBInitialized(choices.Processing)
 }
 
@@ -687,30 +837,67 @@ func (vm *VM) parseTx(bytes []byte) (*UniqueTx, error) {
 	}
 
 	if tx.Status() == choices.Unknown {
-		if err := vm.state.SetTx(tx.ID(), tx.Tx); err != nil {
-			return nil, err
+		if err := vm.persistTx(tx); err != nil {
+			vm.forgetTx(tx)
+			return nil, &snowstorm.PersistenceError{Err: err}
 		}
-		if err := tx.setStatus(choices.Processing); err != nil {
-			return nil, err
-		}
-		return tx, vm.db.Commit()
+		// The new tx's outputs may be spent by txs that failed to verify
+		vm.invalidateVerifications()
 	}
 
 	return tx, nil
 }
 
+// persistTx saves the newly parsed [tx] as processing
+func (vm *VM) persistTx(tx *UniqueTx) error {
+	if err := vm.state.SetTx(tx.ID(), tx.Tx); err != nil {
+		return err
+	}
+	if err := tx.setStatus(choices.Processing); err != nil {
+		return err
+	}
+	return vm.db.Commit()
+}
+
+// forgetTx discards the partial writes of [tx] after it failed to be
+// persisted, so that it's persisted again the next time it's parsed
+func (vm *VM) forgetTx(tx *UniqueTx) {
+	vm.db.Abort()
+	tx.status = choices.Unknown
+	// The state caches are updated before the database is written to, so the
+	// tx is evicted from them. The deletes are only written to the aborted
+	// version of the database, so they can't fail.
+	_ = vm.state.SetTx(tx.ID(), nil)
+	_ = vm.state.SetStatus(tx.ID(), choices.Unknown)
+	vm.db.Abort()
+}
+
 func (vm *VM) parsePrivateTx(txBytes []byte) (*Tx, error) {
-	tx := &Tx{}
-	_, err := vm.codec.Unmarshal(txBytes, tx)
-	if err != nil {
-		return nil, err
+	return ParseTx(vm.codec, txBytes)
+}
+
+// verifyTxLimits returns an error if [tx] exceeds the configured size or
+// input/output limits. The limits are local policy, so they're only applied to
+// txs issued to this node. Txs received from the network are valid regardless
+// of them, so that nodes with different limits agree on the validity of txs.
+func (vm *VM) verifyTxLimits(tx *Tx) error {
+	if size := len(tx.Bytes()); vm.maxTxSize > 0 && size > vm.maxTxSize {
+		return fmt.Errorf("%w: %d bytes > %d bytes", errTxTooLarge, size, vm.maxTxSize)
 	}
-	unsignedBytes, err := vm.codec.Marshal(codecVersion, &tx.UnsignedTx)
-	if err != nil {
-		return nil, err
+	if numInputs := len(tx.InputUTXOs()); vm.maxTxInputs > 0 && numInputs > vm.maxTxInputs {
+		return fmt.Errorf("%w: %d > %d", errTooManyInputs, numInputs, vm.maxTxInputs)
 	}
-	tx.Initialize(unsignedBytes, txBytes)
-	return tx, nil
+	if vm.maxTxOutputs <= 0 {
+		return nil
+	}
+	numOutputs := len(tx.UTXOs())
+	if exportTx, ok := tx.UnsignedTx.(*ExportTx); ok {
+		numOutputs += len(exportTx.ExportedOuts)
+	}
+	if numOutputs > vm.maxTxOutputs {
+		return fmt.Errorf("%w: %d > %d", errTooManyOutputs, numOutputs, vm.maxTxOutputs)
+	}
+	return nil
 }
 
 func (vm *VM) issueTx(tx snowstorm.Tx) {
@@ -780,10 +967,8 @@ func (vm *VM) verifyFxUsage(fxID int, assetID ids.ID) bool {
 	}
 	fxIDs := ids.BitSet(0)
 	for _, state := range createAssetTx.States {
-		if state.FxID == uint32(fxID) {
-			// Cache that this asset supports this fx
-			fxIDs.Add(uint(fxID))
-		}
+		// Cache every fx this asset supports, as an asset may use several
+		fxIDs.Add(uint(state.FxID))
 	}
 	vm.assetToFxCache.Put(assetID, fxIDs)
 	return fxIDs.Contains(uint(fxID))
@@ -869,7 +1054,7 @@ func (vm *VM) LoadUser(
 	// error
 	defer db.Close()
 
-	user := userState{vm: vm}
+	user := userState{vm: vm, username: username}
 
 	kc, err := user.Keychain(db, addrsToUse)
 	if err != nil {
@@ -1238,7 +1423,7 @@ func (vm *VM) ParseLocalAddress(addrStr string) (ids.ShortID, error) {
 // ParseAddress takes in an address and produces the ID of the chain it's for
 // the ID of the address
 func (vm *VM) ParseAddress(addrStr string) (ids.ID, ids.ShortID, error) {
-	chainIDAlias, hrp, addrBytes, err := formatting.ParseAddress(addrStr)
+	chainIDAlias, hrp, addr, err := ids.ShortFromAddress(addrStr)
 	if err != nil {
 		return ids.ID{}, ids.ShortID{}, err
 	}
@@ -1253,11 +1438,6 @@ func (vm *VM) ParseAddress(addrStr string) (ids.ID, ids.ShortID, error) {
 		return ids.ID{}, ids.ShortID{}, fmt.Errorf("expected hrp %q but got %q",
 			expectedHRP, hrp)
 	}
-
-	addr, err := ids.ToShortID(addrBytes)
-	if err != nil {
-		return ids.ID{}, ids.ShortID{}, err
-	}
 	return chainID, addr, nil
 }
 
@@ -1273,8 +1453,7 @@ func (vm *VM) FormatAddress(chainID ids.ID, addr ids.ShortID) (string, error) {
 	if err != nil {
 		return "", err
 	}
-	hrp := constants.GetHRP(vm.ctx.NetworkID)
-	return formatting.FormatAddress(chainIDAlias, hrp, addr.Bytes())
+	return addr.Address(chainIDAlias, constants.GetHRP(vm.ctx.NetworkID))
 }
 
 // selectChangeAddr returns the change address to be used for [kc] when [changeAddr] is given
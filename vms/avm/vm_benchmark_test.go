@@ -114,6 +114,7 @@ func GetAllUTXOsBenchmark(b *testing.B, utxoCount int) {
 			b.Fatal(err)
 		}
 	}
+	commitState(b, vm)
 
 	addrsSet := ids.ShortSet{}
 	addrsSet.Add(addr)
@@ -15,6 +15,8 @@ import (
 	"github.com/ava-labs/avalanchego/database/prefixdb"
 	"github.com/ava-labs/avalanchego/ids"
 	"github.com/ava-labs/avalanchego/snow"
+	"github.com/ava-labs/avalanchego/snow/choices"
+	"github.com/ava-labs/avalanchego/snow/consensus/snowstorm"
 	"github.com/ava-labs/avalanchego/snow/engine/common"
 	"github.com/ava-labs/avalanchego/utils/crypto"
 	"github.com/ava-labs/avalanchego/utils/formatting"
@@ -605,6 +607,130 @@ func TestIssueTx(t *testing.T) {
 	}
 }
 
+func TestIssueTxTooLarge(t *testing.T) {
+	genesisBytes, _, vm, _ := GenesisVM(t)
+	ctx := vm.ctx
+	defer func() {
+		if err := vm.Shutdown(); err != nil {
+			t.Fatal(err)
+		}
+		ctx.Lock.Unlock()
+	}()
+
+	newTx := NewTx(t, genesisBytes, vm)
+	vm.maxTxSize = len(newTx.Bytes()) - 1
+
+	if _, err := vm.IssueTx(newTx.Bytes()); !errors.Is(err, errTxTooLarge) {
+		t.Fatalf("Expected %s, got %v", errTxTooLarge, err)
+	}
+	// The limit is local policy, so txs received from the network must still
+	// be valid
+	tx, err := vm.Parse(newTx.Bytes())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := tx.Verify(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestParseTxNonCanonical(t *testing.T) {
+	genesisBytes, _, vm, _ := GenesisVM(t)
+	ctx := vm.ctx
+	defer func() {
+		if err := vm.Shutdown(); err != nil {
+			t.Fatal(err)
+		}
+		ctx.Lock.Unlock()
+	}()
+
+	newTx := NewTx(t, genesisBytes, vm)
+	if _, err := vm.parsePrivateTx(newTx.Bytes()); err != nil {
+		t.Fatal(err)
+	}
+
+	// The ID of a tx is the hash of its bytes, so a tx must have only one
+	// encoding. The codec rejects padding, which would change the ID of the tx
+	// without changing the tx.
+	paddedBytes := append(append([]byte{}, newTx.Bytes()...), 0)
+	if _, err := vm.Parse(paddedBytes); err == nil {
+		t.Fatalf("Should have rejected a tx with trailing bytes")
+	}
+
+	// Syntactic verification rejects reordered outputs, which would change the
+	// ID of the tx without changing its effects
+	avaxTx := GetAVAXTxFromGenesisTest(genesisBytes, t)
+	outs := []*avax.TransferableOutput{
+		{
+			Asset: avax.Asset{ID: avaxTx.ID()},
+			Out: &secp256k1fx.TransferOutput{
+				Amt: 2,
+				OutputOwners: secp256k1fx.OutputOwners{
+					Threshold: 1,
+					Addrs:     []ids.ShortID{keys[0].PublicKey().Address()},
+				},
+			},
+		},
+		{
+			Asset: avax.Asset{ID: avaxTx.ID()},
+			Out: &secp256k1fx.TransferOutput{
+				Amt: 1,
+				OutputOwners: secp256k1fx.OutputOwners{
+					Threshold: 1,
+					Addrs:     []ids.ShortID{keys[0].PublicKey().Address()},
+				},
+			},
+		},
+	}
+	avax.SortTransferableOutputs(outs, vm.codec)
+	outs[0], outs[1] = outs[1], outs[0]
+
+	unsortedTx := &Tx{UnsignedTx: &BaseTx{BaseTx: avax.BaseTx{
+		NetworkID:    networkID,
+		BlockchainID: chainID,
+		Ins:          newTx.UnsignedTx.(*BaseTx).Ins,
+		Outs:         outs,
+	}}}
+	if err := unsortedTx.SignSECP256K1Fx(vm.codec, [][]*crypto.PrivateKeySECP256K1R{{keys[0]}}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := vm.Parse(unsortedTx.Bytes()); err == nil {
+		t.Fatalf("Should have rejected a tx with unsorted outputs")
+	}
+}
+
+func TestVerifyTxLimits(t *testing.T) {
+	genesisBytes, _, vm, _ := GenesisVM(t)
+	ctx := vm.ctx
+	defer func() {
+		if err := vm.Shutdown(); err != nil {
+			t.Fatal(err)
+		}
+		ctx.Lock.Unlock()
+	}()
+
+	newTx := NewTx(t, genesisBytes, vm)
+	if err := vm.verifyTxLimits(newTx); err != nil {
+		t.Fatal(err)
+	}
+
+	newTx.UnsignedTx.(*BaseTx).Ins = append(newTx.UnsignedTx.(*BaseTx).Ins, newTx.UnsignedTx.(*BaseTx).Ins[0])
+	vm.maxTxInputs = 1
+	if err := vm.verifyTxLimits(newTx); !errors.Is(err, errTooManyInputs) {
+		t.Fatalf("Expected %s, got %v", errTooManyInputs, err)
+	}
+
+	vm.maxTxInputs = 0
+	vm.maxTxOutputs = 1
+	newTx.UnsignedTx.(*BaseTx).Outs = []*avax.TransferableOutput{
+		{Asset: avax.Asset{ID: ids.Empty}, Out: &secp256k1fx.TransferOutput{Amt: 1}},
+		{Asset: avax.Asset{ID: ids.Empty}, Out: &secp256k1fx.TransferOutput{Amt: 1}},
+	}
+	if err := vm.verifyTxLimits(newTx); !errors.Is(err, errTooManyOutputs) {
+		t.Fatalf("Expected %s, got %v", errTooManyOutputs, err)
+	}
+}
+
 func TestGenesisGetUTXOs(t *testing.T) {
 	_, _, vm, _ := GenesisVM(t)
 	ctx := vm.ctx
@@ -1203,6 +1329,42 @@ func TestTxNotCached(t *testing.T) {
 	assert.True(t, *called, "should have called the DB")
 }
 
+func TestParseTxPersistenceError(t *testing.T) {
+	genesisBytes, _, vm, _ := GenesisVM(t)
+	ctx := vm.ctx
+	defer func() {
+		if err := vm.Shutdown(); err != nil {
+			t.Fatal(err)
+		}
+		ctx.Lock.Unlock()
+	}()
+
+	newTx := NewTx(t, genesisBytes, vm)
+	txBytes := newTx.Bytes()
+
+	baseDB := vm.state.state.DB
+	db := mockdb.New()
+	db.OnPut = func([]byte, []byte) error { return errors.New("disk failure") }
+	vm.state.state.DB = db
+
+	_, err := vm.Parse(txBytes)
+	var persistErr *snowstorm.PersistenceError
+	assert.True(t, errors.As(err, &persistErr), "should have failed to persist the tx")
+
+	vm.state.state.DB = baseDB
+
+	tx, err := vm.Parse(txBytes)
+	assert.NoError(t, err)
+	assert.Equal(t, choices.Processing, tx.Status(), "should have persisted the tx once the database recovered")
+
+	vm.state.uniqueTx.Flush()
+	vm.state.state.Cache.Flush()
+
+	tx, err = vm.Parse(txBytes)
+	assert.NoError(t, err)
+	assert.Equal(t, choices.Processing, tx.Status(), "should have read the persisted tx")
+}
+
 func TestTxVerifyAfterIssueTx(t *testing.T) {
 	genesisBytes, issuer, vm, _ := GenesisVM(t)
 	ctx := vm.ctx
@@ -1548,3 +1710,34 @@ func TestTxVerifyAfterVerifyAncestorTx(t *testing.T) {
 		t.Fatalf("Should have errored due to a missing UTXO")
 	}
 }
+
+func TestTxFee(t *testing.T) {
+	genesisBytes, _, vm, _ := GenesisVM(t)
+	ctx := vm.ctx
+	defer func() {
+		if err := vm.Shutdown(); err != nil {
+			t.Fatal(err)
+		}
+		ctx.Lock.Unlock()
+	}()
+
+	tx, err := vm.Parse(newTxWithOutput(t, genesisBytes, vm).Bytes())
+	if err != nil {
+		t.Fatal(err)
+	}
+	feeTx, ok := tx.(snowstorm.FeeTx)
+	if !ok {
+		t.Fatalf("tx should report its fee")
+	}
+	if fee := feeTx.Fee(); fee != vm.txFee {
+		t.Fatalf("tx should have paid %d but paid %d", vm.txFee, fee)
+	}
+
+	tx, err = vm.Parse(NewTx(t, genesisBytes, vm).Bytes())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if fee := tx.(snowstorm.FeeTx).Fee(); fee != startBalance {
+		t.Fatalf("tx without outputs should have paid %d but paid %d", startBalance, fee)
+	}
+}
@@ -29,12 +29,13 @@ type Block struct {
 	VM     *SnowmanVM
 }
 
-// Initialize sets [b.bytes] to [bytes], sets [b.id] to hash([b.bytes])
+// Initialize sets [b.bytes] to [bytes], sets [b.id] to hash([b.bytes]) under
+// the hash function of [vm]'s chain
 // Checks if [b]'s status is already stored in state. If so, [b] gets that status.
 // Otherwise [b]'s status is Unknown.
 func (b *Block) Initialize(bytes []byte, vm *SnowmanVM) {
 	b.VM = vm
-	b.Metadata.Initialize(bytes)
+	b.Metadata.Initialize(bytes, vm.Ctx.HashFunction())
 	b.SetStatus(choices.Unknown) // don't set status until it is queried
 }
 
@@ -6,8 +6,10 @@ import (
 	"github.com/ava-labs/avalanchego/database/memdb"
 	"github.com/ava-labs/avalanchego/database/versiondb"
 	"github.com/ava-labs/avalanchego/ids"
+	"github.com/ava-labs/avalanchego/snow"
 	"github.com/ava-labs/avalanchego/snow/choices"
 	"github.com/ava-labs/avalanchego/snow/consensus/snowman"
+	"github.com/ava-labs/avalanchego/utils/hashing"
 )
 
 func TestBlock(t *testing.T) {
@@ -52,3 +54,24 @@ func TestBlock(t *testing.T) {
 		t.Fatalf("status should be rejected but is %s", status)
 	}
 }
+
+func TestBlockIDUsesChainHasher(t *testing.T) {
+	bytes := []byte{1, 2, 3}
+
+	b := NewBlock(ids.ID{1}, 1)
+	b.Initialize(bytes, &SnowmanVM{})
+	if b.ID() != ids.ID(hashing.ComputeHash256Array(bytes)) {
+		t.Fatal("block should be identified by its default hash")
+	}
+
+	ctx := snow.DefaultContextTest()
+	ctx.Hasher = hashing.SHA3
+	sha3Block := NewBlock(ids.ID{1}, 1)
+	sha3Block.Initialize(bytes, &SnowmanVM{Ctx: ctx})
+	if sha3Block.ID() != ids.ID(hashing.SHA3.ComputeHash256Array(bytes)) {
+		t.Fatal("block should be identified by its SHA-3 hash")
+	}
+	if sha3Block.ID() == b.ID() {
+		t.Fatal("block shouldn't have the same ID under different hash functions")
+	}
+}
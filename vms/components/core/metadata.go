@@ -29,9 +29,9 @@ func (i *Metadata) Bytes() []byte { return i.bytes }
 func (i *Metadata) SetStatus(status choices.Status) { i.status = status }
 
 // Initialize sets [i.bytes] to [bytes], sets [i.id] to a hash of [i.bytes]
-// and sets [i.status] to choices.Processing
-func (i *Metadata) Initialize(bytes []byte) {
+// under [hasher] and sets [i.status] to choices.Processing
+func (i *Metadata) Initialize(bytes []byte, hasher hashing.Hasher) {
 	i.bytes = bytes
-	i.id = hashing.ComputeHash256Array(i.bytes)
+	i.id = hasher.ComputeHash256Array(i.bytes)
 	i.status = choices.Processing
 }
@@ -0,0 +1,17 @@
+package pausefx
+
+import (
+	"github.com/ava-labs/avalanchego/ids"
+	"github.com/ava-labs/avalanchego/snow"
+)
+
+// ID that this Fx uses when labeled
+var (
+	ID = ids.ID{'p', 'a', 'u', 's', 'e', 'f', 'x'}
+)
+
+// Factory ...
+type Factory struct{}
+
+// New ...
+func (f *Factory) New(*snow.Context) (interface{}, error) { return &Fx{}, nil }
@@ -0,0 +1,87 @@
+package pausefx
+
+import (
+	"errors"
+
+	"github.com/ava-labs/avalanchego/utils/wrappers"
+	"github.com/ava-labs/avalanchego/vms/components/verify"
+	"github.com/ava-labs/avalanchego/vms/secp256k1fx"
+)
+
+var (
+	errWrongTxType         = errors.New("wrong tx type")
+	errWrongUTXOType       = errors.New("wrong utxo type")
+	errWrongOperationType  = errors.New("wrong operation type")
+	errWrongCredentialType = errors.New("wrong credential type")
+	errWrongNumberOfUTXOs  = errors.New("wrong number of UTXOs for the operation")
+	errWrongPauseOutput    = errors.New("wrong pause output provided")
+	errCantTransfer        = errors.New("cant transfer with this fx")
+)
+
+// Fx lets the owners of an asset's PauseOutput pause and unpause transfers of
+// the asset. The fx only verifies the operations; enforcing the pause is left
+// to the VM.
+type Fx struct{ secp256k1fx.Fx }
+
+// Initialize ...
+func (fx *Fx) Initialize(vmIntf interface{}) error {
+	if err := fx.InitializeVM(vmIntf); err != nil {
+		return err
+	}
+
+	log := fx.VM.Logger()
+	log.Debug("initializing pause fx")
+
+	c := fx.VM.CodecRegistry()
+	errs := wrappers.Errs{}
+	errs.Add(
+		c.RegisterType(&PauseOutput{}),
+		c.RegisterType(&PauseOperation{}),
+		c.RegisterType(&Credential{}),
+	)
+	return errs.Err
+}
+
+// VerifyOperation ...
+func (fx *Fx) VerifyOperation(txIntf, opIntf, credIntf interface{}, utxosIntf []interface{}) error {
+	tx, ok := txIntf.(secp256k1fx.Tx)
+	switch {
+	case !ok:
+		return errWrongTxType
+	case len(utxosIntf) != 1:
+		return errWrongNumberOfUTXOs
+	}
+
+	cred, ok := credIntf.(*Credential)
+	if !ok {
+		return errWrongCredentialType
+	}
+
+	op, ok := opIntf.(*PauseOperation)
+	if !ok {
+		return errWrongOperationType
+	}
+	return fx.VerifyPauseOperation(tx, op, cred, utxosIntf[0])
+}
+
+// VerifyPauseOperation ...
+func (fx *Fx) VerifyPauseOperation(tx secp256k1fx.Tx, op *PauseOperation, cred *Credential, utxoIntf interface{}) error {
+	out, ok := utxoIntf.(*PauseOutput)
+	if !ok {
+		return errWrongUTXOType
+	}
+
+	if err := verify.All(op, cred, out); err != nil {
+		return err
+	}
+
+	switch {
+	case !out.OutputOwners.Equals(&op.PauseOutput.OutputOwners):
+		return errWrongPauseOutput
+	default:
+		return fx.Fx.VerifyCredentials(tx, &op.Input, &cred.Credential, &out.OutputOwners)
+	}
+}
+
+// VerifyTransfer ...
+func (fx *Fx) VerifyTransfer(_, _, _, _ interface{}) error { return errCantTransfer }
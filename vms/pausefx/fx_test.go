@@ -0,0 +1,148 @@
+package pausefx
+
+import (
+	"testing"
+	"time"
+
+	"github.com/ava-labs/avalanchego/codec/linearcodec"
+	"github.com/ava-labs/avalanchego/ids"
+	"github.com/ava-labs/avalanchego/utils/crypto"
+	"github.com/ava-labs/avalanchego/utils/hashing"
+	"github.com/ava-labs/avalanchego/utils/logging"
+	"github.com/ava-labs/avalanchego/vms/secp256k1fx"
+)
+
+var (
+	txBytes  = []byte{0, 1, 2, 3, 4, 5}
+	sigBytes = [crypto.SECP256K1RSigLen]byte{
+		0x0e, 0x33, 0x4e, 0xbc, 0x67, 0xa7, 0x3f, 0xe8,
+		0x24, 0x33, 0xac, 0xa3, 0x47, 0x88, 0xa6, 0x3d,
+		0x58, 0xe5, 0x8e, 0xf0, 0x3a, 0xd5, 0x84, 0xf1,
+		0xbc, 0xa3, 0xb2, 0xd2, 0x5d, 0x51, 0xd6, 0x9b,
+		0x0f, 0x28, 0x5d, 0xcd, 0x3f, 0x71, 0x17, 0x0a,
+		0xf9, 0xbf, 0x2d, 0xb1, 0x10, 0x26, 0x5c, 0xe9,
+		0xdc, 0xc3, 0x9d, 0x7a, 0x01, 0x50, 0x9d, 0xe8,
+		0x35, 0xbd, 0xcb, 0x29, 0x3a, 0xd1, 0x49, 0x32,
+		0x00,
+	}
+	addr = [hashing.AddrLen]byte{
+		0x01, 0x5c, 0xce, 0x6c, 0x55, 0xd6, 0xb5, 0x09,
+		0x84, 0x5c, 0x8c, 0x4e, 0x30, 0xbe, 0xd9, 0x8d,
+		0x39, 0x1a, 0xe7, 0xf0,
+	}
+)
+
+func newTestFx(t *testing.T) *Fx {
+	vm := secp256k1fx.TestVM{
+		Codec: linearcodec.NewDefault(),
+		Log:   logging.NoLog{},
+	}
+	date := time.Date(2019, time.January, 19, 16, 25, 17, 3, time.UTC)
+	vm.CLK.Set(date)
+
+	fx := &Fx{}
+	if err := fx.Initialize(&vm); err != nil {
+		t.Fatal(err)
+	}
+	return fx
+}
+
+func TestFxInitializeInvalid(t *testing.T) {
+	fx := Fx{}
+	err := fx.Initialize(nil)
+	if err == nil {
+		t.Fatalf("Should have returned an error")
+	}
+}
+
+func TestFxVerifyPauseOperation(t *testing.T) {
+	fx := newTestFx(t)
+	tx := &secp256k1fx.TestTx{
+		Bytes: txBytes,
+	}
+	cred := &Credential{Credential: secp256k1fx.Credential{
+		Sigs: [][crypto.SECP256K1RSigLen]byte{
+			sigBytes,
+		},
+	}}
+	owners := secp256k1fx.OutputOwners{
+		Threshold: 1,
+		Addrs: []ids.ShortID{
+			addr,
+		},
+	}
+	utxo := &PauseOutput{OutputOwners: owners}
+	op := &PauseOperation{
+		Input: secp256k1fx.Input{
+			SigIndices: []uint32{0},
+		},
+		Paused:      true,
+		PauseOutput: PauseOutput{OutputOwners: owners},
+	}
+
+	utxos := []interface{}{utxo}
+	if err := fx.VerifyOperation(tx, op, cred, utxos); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestFxVerifyPauseOperationWrongPauseOutput(t *testing.T) {
+	fx := newTestFx(t)
+	tx := &secp256k1fx.TestTx{
+		Bytes: txBytes,
+	}
+	cred := &Credential{Credential: secp256k1fx.Credential{
+		Sigs: [][crypto.SECP256K1RSigLen]byte{
+			sigBytes,
+		},
+	}}
+	utxo := &PauseOutput{OutputOwners: secp256k1fx.OutputOwners{
+		Threshold: 1,
+		Addrs: []ids.ShortID{
+			addr,
+		},
+	}}
+	op := &PauseOperation{
+		Input: secp256k1fx.Input{
+			SigIndices: []uint32{0},
+		},
+		Paused: true,
+		// The pause output would be handed to nobody
+		PauseOutput: PauseOutput{},
+	}
+
+	utxos := []interface{}{utxo}
+	if err := fx.VerifyOperation(tx, op, cred, utxos); err == nil {
+		t.Fatalf("VerifyOperation should have errored due to an invalid pause output")
+	}
+}
+
+func TestFxVerifyPauseOperationWrongUTXO(t *testing.T) {
+	fx := newTestFx(t)
+	tx := &secp256k1fx.TestTx{
+		Bytes: txBytes,
+	}
+	cred := &Credential{Credential: secp256k1fx.Credential{
+		Sigs: [][crypto.SECP256K1RSigLen]byte{
+			sigBytes,
+		},
+	}}
+	utxo := &secp256k1fx.MintOutput{}
+	op := &PauseOperation{
+		Input: secp256k1fx.Input{
+			SigIndices: []uint32{0},
+		},
+	}
+
+	utxos := []interface{}{utxo}
+	if err := fx.VerifyOperation(tx, op, cred, utxos); err == nil {
+		t.Fatalf("VerifyOperation should have errored due to an invalid utxo")
+	}
+}
+
+func TestFxVerifyTransfer(t *testing.T) {
+	fx := newTestFx(t)
+	if err := fx.VerifyTransfer(nil, nil, nil, nil); err == nil {
+		t.Fatalf("this Fx doesn't support transfers")
+	}
+}
@@ -0,0 +1,36 @@
+package pausefx
+
+import (
+	"errors"
+
+	"github.com/ava-labs/avalanchego/vms/components/verify"
+	"github.com/ava-labs/avalanchego/vms/secp256k1fx"
+)
+
+var (
+	errNilPauseOperation = errors.New("nil pause operation")
+)
+
+// PauseOperation pauses transfers of an asset if [Paused] is true, and
+// unpauses them otherwise. It spends a PauseOutput and re-creates it, so the
+// owners keep the right to change the pause state later.
+type PauseOperation struct {
+	Input       secp256k1fx.Input `serialize:"true" json:"input"`
+	Paused      bool              `serialize:"true" json:"paused"`
+	PauseOutput PauseOutput       `serialize:"true" json:"pauseOutput"`
+}
+
+// Outs ...
+func (op *PauseOperation) Outs() []verify.State {
+	return []verify.State{&op.PauseOutput}
+}
+
+// Verify ...
+func (op *PauseOperation) Verify() error {
+	switch {
+	case op == nil:
+		return errNilPauseOperation
+	default:
+		return verify.All(&op.Input, &op.PauseOutput)
+	}
+}
@@ -0,0 +1,11 @@
+package pausefx
+
+import (
+	"github.com/ava-labs/avalanchego/vms/secp256k1fx"
+)
+
+// PauseOutput is the right to pause and unpause transfers of the asset it's
+// an output of. An asset can only be paused if it was created with one.
+type PauseOutput struct {
+	secp256k1fx.OutputOwners `serialize:"true"`
+}
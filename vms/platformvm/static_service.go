@@ -174,15 +174,6 @@ func (g *Genesis) Initialize() error {
 	return nil
 }
 
-// beck32ToID takes bech32 address and produces a shortID
-func bech32ToID(address string) (ids.ShortID, error) {
-	_, addr, err := formatting.ParseBech32(address)
-	if err != nil {
-		return ids.ShortID{}, err
-	}
-	return ids.ToShortID(addr)
-}
-
 // BuildGenesis build the genesis state of the Platform Chain (and thereby the Avalanche network.)
 func (ss *StaticService) BuildGenesis(_ *http.Request, args *BuildGenesisArgs, reply *BuildGenesisReply) error {
 	// Specify the UTXOs on the Platform chain that exist at genesis.
@@ -191,7 +182,7 @@ func (ss *StaticService) BuildGenesis(_ *http.Request, args *BuildGenesisArgs, r
 		if apiUTXO.Amount == 0 {
 			return errUTXOHasNoValue
 		}
-		addrID, err := bech32ToID(apiUTXO.Address)
+		addrID, err := ids.ShortFromBech32("", apiUTXO.Address)
 		if err != nil {
 			return err
 		}
@@ -234,7 +225,7 @@ func (ss *StaticService) BuildGenesis(_ *http.Request, args *BuildGenesisArgs, r
 		stake := make([]*avax.TransferableOutput, len(validator.Staked))
 		sortAPIUTXOs(validator.Staked)
 		for i, apiUTXO := range validator.Staked {
-			addrID, err := bech32ToID(apiUTXO.Address)
+			addrID, err := ids.ShortFromBech32("", apiUTXO.Address)
 			if err != nil {
 				return err
 			}
@@ -281,7 +272,7 @@ func (ss *StaticService) BuildGenesis(_ *http.Request, args *BuildGenesisArgs, r
 			Threshold: uint32(validator.RewardOwner.Threshold),
 		}
 		for _, addrStr := range validator.RewardOwner.Addresses {
-			addrID, err := bech32ToID(addrStr)
+			addrID, err := ids.ShortFromBech32("", addrStr)
 			if err != nil {
 				return err
 			}
@@ -380,12 +371,12 @@ func (xa innerSortAPIUTXO) Less(i, j int) bool {
 		return false
 	}
 
-	iAddrID, err := bech32ToID(xa[i].Address)
+	iAddrID, err := ids.ShortFromBech32("", xa[i].Address)
 	if err != nil {
 		return false
 	}
 
-	jAddrID, err := bech32ToID(xa[j].Address)
+	jAddrID, err := ids.ShortFromBech32("", xa[j].Address)
 	if err != nil {
 		return false
 	}
@@ -2092,6 +2092,7 @@ func TestBootstrapPartiallyAccepted(t *testing.T) {
 		router.DefaultMaxNonStakerPendingMsgs,
 		router.DefaultStakerPortion,
 		router.DefaultStakerPortion,
+		nil,
 		"",
 		prometheus.NewRegistry(),
 		&router.Delay{},
@@ -28,7 +28,7 @@ var (
 	errWrongOwnerType                 = errors.New("wrong owner type")
 	errWrongNumberOfUTXOs             = errors.New("wrong number of utxos for the operation")
 	errWrongMintCreated               = errors.New("wrong mint output created from the operation")
-	errTimelocked                     = errors.New("output is time locked")
+	ErrTimelocked                     = errors.New("output is time locked")
 	errTooManySigners                 = errors.New("input has more signers than expected")
 	errTooFewSigners                  = errors.New("input has less signers than expected")
 	errInputOutputIndexOutOfBounds    = errors.New("input referenced a nonexistent address in the output")
@@ -178,7 +178,7 @@ func (fx *Fx) VerifyCredentials(tx Tx, in *Input, cred *Credential, out *OutputO
 	numSigs := len(in.SigIndices)
 	switch {
 	case out.Locktime > fx.VM.Clock().Unix():
-		return errTimelocked
+		return ErrTimelocked
 	case out.Threshold < uint32(numSigs):
 		return errTooManySigners
 	case out.Threshold > uint32(numSigs):